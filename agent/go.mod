@@ -5,6 +5,7 @@ go 1.25
 replace github.com/xKoRx/echo/sdk => ../sdk
 
 require (
+	github.com/stretchr/testify v1.10.0
 	github.com/xKoRx/echo/sdk v0.0.0-00010101000000-000000000000
 	go.opentelemetry.io/otel v1.33.0
 	google.golang.org/grpc v1.70.0
@@ -20,6 +21,7 @@ require (
 	github.com/cloudwego/iasm v0.2.0 // indirect
 	github.com/coreos/go-semver v0.3.0 // indirect
 	github.com/coreos/go-systemd/v22 v22.3.2 // indirect
+	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/gabriel-vasile/mimetype v1.4.3 // indirect
 	github.com/gin-contrib/sse v0.1.0 // indirect
 	github.com/gin-gonic/gin v1.10.0 // indirect
@@ -40,6 +42,7 @@ require (
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/pelletier/go-toml/v2 v2.2.2 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/twitchyliquid64/golang-asm v0.15.1 // indirect
 	github.com/ugorji/go/codec v1.2.12 // indirect
 	github.com/xKoRx/sdk v0.0.0-20251023121025-bd95eedfd59c // indirect
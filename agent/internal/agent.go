@@ -8,11 +8,15 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 
+	"github.com/xKoRx/echo/agent/internal/spool"
 	"github.com/xKoRx/echo/sdk/domain/handshake"
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
 	pb "github.com/xKoRx/echo/sdk/pb/v1"
 	"github.com/xKoRx/echo/sdk/telemetry"
 	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"github.com/xKoRx/echo/sdk/utils"
 )
 
 // Agent representa el servicio de Agent.
@@ -29,8 +33,26 @@ type Agent struct {
 	// gRPC
 	coreClient   *CoreClient // Cliente gRPC wrapper (usa sdk/grpc)
 	coreStream   pb.AgentService_StreamBidiClient
+	streamCancel context.CancelFunc
+	streamMu     sync.Mutex            // i8: serializa Send y el resume handshake
 	sendToCoreCh chan *pb.AgentMessage // Canal para serializar envíos al Core
 
+	// i8: Entrega confiable (seq, ack acumulativo y replay tras reconectar)
+	sessionID string
+	session   *grpcSDK.ReliableSession[*pb.AgentMessage]
+
+	// i8: Store-and-forward (cola en disco que se drena mientras el stream está arriba)
+	outbound     *spool.Queue
+	outboundSent uint64           // último index entregado a la sesión (solo sendToCore)
+	inFlight     outboundInFlight // index del spool por seq, se confirma con el ack del Core
+	streamUp     atomic.Bool
+	streamReady  chan struct{}
+
+	// i8: Configuración recibida del Core (ConfigUpdate)
+	heartbeatInterval atomic.Int64 // time.Duration vigente (0 = loop no iniciado)
+	configMu          sync.Mutex
+	configApplied     *pb.ConfigApplied // respuesta a la última versión procesada
+
 	// Named Pipes
 	pipeManager *PipeManager // Gestión de pipes (usa sdk/ipc)
 
@@ -93,6 +115,9 @@ func New(ctx context.Context) (*Agent, error) {
 	agent := &Agent{
 		config:           config,
 		sendToCoreCh:     make(chan *pb.AgentMessage, config.SendQueueSize),
+		sessionID:        utils.GenerateUUIDv7(),
+		session:          grpcSDK.NewReliableSession[*pb.AgentMessage](config.ReplayBufferSize),
+		streamReady:      make(chan struct{}, 1),
 		telemetry:        telClient,
 		echoMetrics:      echoMetrics,
 		ctx:              agentCtx,
@@ -101,15 +126,43 @@ func New(ctx context.Context) (*Agent, error) {
 		lastEvaluationID: make(map[string]string),
 	}
 
+	// i8: Abrir store-and-forward (recupera lo que quedó sin enviar antes del reinicio)
+	outbound, err := spool.Open(spool.Options{
+		Dir:         config.OutboundQueueDir,
+		MaxMessages: config.OutboundQueueMaxMessages,
+		MaxBytes:    config.OutboundQueueMaxBytes,
+		Fsync:       config.OutboundQueueFsync,
+		OnError: func(err error) {
+			agent.logWarn("Outbound queue error (i8)", map[string]interface{}{
+				"error": err.Error(),
+			})
+		},
+	})
+	if err != nil {
+		cancel()
+		_ = telClient.Shutdown(agentCtx)
+		return nil, fmt.Errorf("failed to open outbound queue: %w", err)
+	}
+	agent.outbound = outbound
+
+	if pending, bytes := outbound.Stats(); pending > 0 {
+		agent.logInfo("Outbound queue recovered pending messages (i8)", map[string]interface{}{
+			"pending": pending,
+			"bytes":   bytes,
+			"dir":     config.OutboundQueueDir,
+		})
+	}
+
 	return agent, nil
 }
 
 // Start inicia el Agent.
 //
 // Secuencia:
-//  1. Conectar a Core via gRPC
-//  2. Iniciar goroutines de stream (send/receive)
-//  3. Crear Named Pipes y esperar conexiones de EAs
+//  1. Crear PipeManager
+//  2. Conectar a Core via gRPC (resume handshake i8)
+//  3. Iniciar goroutines de stream (send/receive/heartbeat)
+//  4. Crear Named Pipes y esperar conexiones de EAs
 //
 // Bloquea hasta que ctx se cancele o haya error fatal.
 func (a *Agent) Start() error {
@@ -125,23 +178,30 @@ func (a *Agent) Start() error {
 		"version":      a.config.ServiceVersion,
 	})
 
-	// 1. Conectar a Core via gRPC
+	// 1. Crear PipeManager (antes del stream: la reconexión re-anuncia sus cuentas)
+	pipeManager, err := NewPipeManager(a.ctx, a.config, a.telemetry, a.echoMetrics)
+	if err != nil {
+		return fmt.Errorf("failed to create pipe manager: %w", err)
+	}
+	pipeManager.SetHandshakeCallback(a.markHandshakePending)
+	a.pipeManager = pipeManager
+
+	// 2. Conectar a Core via gRPC
 	if err := a.connectToCore(); err != nil {
 		return fmt.Errorf("failed to connect to core: %w", err)
 	}
 
-	// 2. Iniciar goroutines de gRPC stream
+	// 3. Iniciar goroutines de gRPC stream
 	a.wg.Add(2)
 	go a.sendToCore()
 	go a.receiveFromCore()
 
-	// 3. Iniciar PipeManager
-	pipeManager, err := NewPipeManager(a.ctx, a.config, a.telemetry, a.echoMetrics)
-	if err != nil {
-		return fmt.Errorf("failed to create pipe manager: %w", err)
+	// i8: Heartbeats con el estado de pipes y cola (liveness en el Core)
+	if a.config.HeartbeatInterval > 0 {
+		a.heartbeatInterval.Store(int64(a.config.HeartbeatInterval))
+		a.wg.Add(1)
+		go a.heartbeatLoop()
 	}
-	pipeManager.SetHandshakeCallback(a.markHandshakePending)
-	a.pipeManager = pipeManager
 
 	// 4. Iniciar gestión de pipes (bloquea hasta ctx.Done)
 	if err := a.pipeManager.Start(a.sendToCoreCh, a.config.AgentID); err != nil {
@@ -188,6 +248,13 @@ func (a *Agent) Shutdown() error {
 	// 5. Esperar goroutines
 	a.wg.Wait()
 
+	// i8: Cerrar store-and-forward (lo pendiente se drena en el próximo arranque)
+	if a.outbound != nil {
+		if err := a.outbound.Close(); err != nil {
+			a.logError("Failed to close outbound queue", err, nil)
+		}
+	}
+
 	// 6. Shutdown telemetría
 	shutdownCtx := context.Background()
 	if err := a.telemetry.Shutdown(shutdownCtx); err != nil {
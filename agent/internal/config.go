@@ -9,7 +9,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/xKoRx/echo/agent/internal/spool"
 	"github.com/xKoRx/echo/sdk/etcd"
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
 )
 
 // Config configuración del Agent (i1).
@@ -23,9 +25,22 @@ type Config struct {
 	CanonicalSymbols []string
 
 	// Pipes
-	PipePrefix     string   // agent/pipe_prefix
-	MasterAccounts []string // agent/master_accounts (comma separated)
-	SlaveAccounts  []string // agent/slave_accounts (comma separated)
+	PipePrefix         string   // agent/pipe_prefix
+	SocketDir          string   // agent/socket_dir (solo Linux/macOS: directorio de los Unix sockets)
+	PipeFramedProtobuf bool     // agent/pipe/framed_protobuf (i8: false = siempre JSON con los EAs)
+	MasterAccounts     []string // agent/master_accounts (comma separated)
+	SlaveAccounts      []string // agent/slave_accounts (comma separated)
+
+	// Descubrimiento dinámico de cuentas (i8): EAs no listados se anuncian en el pipe <pipe_prefix>rendezvous
+	DiscoveryEnabled       bool     // agent/discovery/enabled
+	DiscoveryAllowAccounts []string // agent/discovery/allow_accounts (comma separated, vacío = cualquiera)
+	DiscoveryDenyAccounts  []string // agent/discovery/deny_accounts (comma separated, prevalece sobre allow)
+
+	// Transporte por cuenta (i8): Named Pipe local (default) o TCP/TLS para EAs en otro host/VM
+	AccountTransports         map[string]*AccountTransport // agent/accounts/<account_id>/{transport,listen_addr,token}
+	TLSCertFile               string                       // agent/transport/tls_cert_file (vacío = TCP sin TLS)
+	TLSKeyFile                string                       // agent/transport/tls_key_file
+	TransportHandshakeTimeout time.Duration                // agent/transport/handshake_timeout_ms
 
 	// Agent
 	AgentID          string        // agent/agent_id (persistente para ownership)
@@ -35,11 +50,33 @@ type Config struct {
 	SendQueueSize    int           // agent/send_queue_size
 	ReconnectBackoff time.Duration // agent/reconnect_backoff_s
 
+	// Heartbeat hacia el Core (i8)
+	HeartbeatInterval time.Duration // agent/heartbeat_interval_ms (0 = deshabilitado)
+
 	// gRPC KeepAlive (RFC-003 sección 7)
 	KeepAliveTime       time.Duration // grpc/client_keepalive/time_s
 	KeepAliveTimeout    time.Duration // grpc/client_keepalive/timeout_s
 	PermitWithoutStream bool          // grpc/client_keepalive/permit_without_stream
 
+	// Autenticación hacia el Core (i8)
+	CoreTLSCAFile     string // grpc/tls/ca_file (habilita TLS; vacío + sin cert = insecure)
+	CoreTLSCertFile   string // grpc/tls/cert_file (certificado de cliente mTLS; CN = agent_id)
+	CoreTLSKeyFile    string // grpc/tls/key_file
+	CoreTLSServerName string // grpc/tls/server_name
+	CoreToken         string // grpc/token (agent-token emitido con echo-core-cli agents token)
+
+	// Entrega confiable sobre el stream (i8)
+	ReplayBufferSize int           // grpc/stream/replay_buffer_size (AgentMessages sin ack)
+	AckInterval      time.Duration // grpc/stream/ack_interval_ms
+	ResumeTimeout    time.Duration // grpc/stream/resume_timeout_ms (espera de StreamResumed)
+
+	// Store-and-forward de AgentMessages mientras el Core no responde (i8)
+	OutboundQueueDir         string        // agent/outbound_queue/dir
+	OutboundQueueMaxMessages int           // agent/outbound_queue/max_messages
+	OutboundQueueMaxBytes    int64         // agent/outbound_queue/max_bytes
+	OutboundQueueMaxAge      time.Duration // agent/outbound_queue/max_age_ms (más antiguos se envían como expired)
+	OutboundQueueFsync       bool          // agent/outbound_queue/fsync
+
 	// Handshake protocolo
 	ProtocolMinVersion  int
 	ProtocolMaxVersion  int
@@ -54,6 +91,27 @@ type Config struct {
 	LogLevel        string // agent/log_level (INFO, DEBUG, WARN, ERROR)
 }
 
+// Transportes de EA soportados por cuenta (i8).
+const (
+	TransportPipe = "pipe" // Named Pipe (Windows) o Unix socket (Linux/macOS)
+	TransportTCP  = "tcp"  // TCP, con TLS si agent/transport/tls_cert_file está configurado
+)
+
+// AccountTransport transporte de la conexión con el EA de una cuenta (i8).
+type AccountTransport struct {
+	Kind       string // TransportPipe | TransportTCP
+	ListenAddr string // host:port (solo TCP)
+	Token      string // token pre-compartido que el EA envía en el handshake (solo TCP)
+}
+
+// TransportFor retorna el transporte configurado para accountID (default: pipe).
+func (c *Config) TransportFor(accountID string) *AccountTransport {
+	if transport, ok := c.AccountTransports[accountID]; ok {
+		return transport
+	}
+	return &AccountTransport{Kind: TransportPipe}
+}
+
 // LoadConfig carga configuración desde ETCD.
 //
 // Environment se determina desde variable de entorno ENV (default: development).
@@ -95,24 +153,36 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 	// Crear config con defaults
 	cfg := &Config{
 		// Defaults (sobrescritos por ETCD si existen)
-		PipePrefix:          "echo_",
-		AgentID:             fmt.Sprintf("agent_%s", hostKey),
-		RetryEnabled:        true,
-		MaxRetries:          3,
-		FlushForce:          false,
-		SendQueueSize:       100,
-		ReconnectBackoff:    5 * time.Second,
-		KeepAliveTime:       60 * time.Second,
-		KeepAliveTimeout:    20 * time.Second,
-		PermitWithoutStream: false,
-		ServiceName:         "echo-agent",
-		ServiceVersion:      "1.0.0-i1",
-		Environment:         env,
-		LogLevel:            "INFO", // Default
-		CanonicalSymbols:    []string{"XAUUSD"},
-		ProtocolMinVersion:  1,
-		ProtocolMaxVersion:  2,
-		ProtocolAllowLegacy: true,
+		PipePrefix:                "echo_",
+		AgentID:                   fmt.Sprintf("agent_%s", hostKey),
+		RetryEnabled:              true,
+		MaxRetries:                3,
+		FlushForce:                false,
+		SendQueueSize:             100,
+		ReconnectBackoff:          5 * time.Second,
+		HeartbeatInterval:         5 * time.Second,
+		KeepAliveTime:             60 * time.Second,
+		KeepAliveTimeout:          20 * time.Second,
+		PermitWithoutStream:       false,
+		ReplayBufferSize:          grpcSDK.DefaultReplayBufferSize,
+		AckInterval:               grpcSDK.DefaultAckInterval,
+		ResumeTimeout:             10 * time.Second,
+		OutboundQueueDir:          "outbound_queue",
+		OutboundQueueMaxMessages:  spool.DefaultMaxMessages,
+		OutboundQueueMaxBytes:     spool.DefaultMaxBytes,
+		OutboundQueueMaxAge:       30 * time.Second,
+		OutboundQueueFsync:        true,
+		AccountTransports:         make(map[string]*AccountTransport),
+		TransportHandshakeTimeout: 5 * time.Second,
+		PipeFramedProtobuf:        true,
+		ServiceName:               "echo-agent",
+		ServiceVersion:            "1.0.0-i1",
+		Environment:               env,
+		LogLevel:                  "INFO", // Default
+		CanonicalSymbols:          []string{"XAUUSD"},
+		ProtocolMinVersion:        1,
+		ProtocolMaxVersion:        2,
+		ProtocolAllowLegacy:       true,
 	}
 	if val, err := etcdClient.GetVarWithDefault(ctx, "core/canonical_symbols", ""); err == nil && val != "" {
 		symbols := strings.Split(val, ",")
@@ -143,6 +213,14 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/pipe_prefix", ""); err == nil && val != "" {
 		cfg.PipePrefix = val
 	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/socket_dir", ""); err == nil && val != "" {
+		cfg.SocketDir = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/pipe/framed_protobuf", ""); err == nil && val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.PipeFramedProtobuf = enabled
+		}
+	}
 	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/agent_id", ""); err == nil && val != "" {
 		cfg.AgentID = val
 	}
@@ -171,6 +249,11 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 			cfg.ReconnectBackoff = time.Duration(seconds) * time.Second
 		}
 	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/heartbeat_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms >= 0 {
+			cfg.HeartbeatInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
 	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/master_accounts", ""); err == nil && val != "" {
 		cfg.MasterAccounts = strings.Split(val, ",")
 		// Trim spaces
@@ -186,6 +269,19 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 		}
 	}
 
+	// Cargar descubrimiento dinámico de cuentas (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/discovery/enabled", ""); err == nil && val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.DiscoveryEnabled = enabled
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/discovery/allow_accounts", ""); err == nil && val != "" {
+		cfg.DiscoveryAllowAccounts = splitAccountList(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/discovery/deny_accounts", ""); err == nil && val != "" {
+		cfg.DiscoveryDenyAccounts = splitAccountList(val)
+	}
+
 	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/protocol/min_version", ""); err == nil && val != "" {
 		if v, err := strconv.Atoi(val); err == nil {
 			cfg.ProtocolMinVersion = v
@@ -219,6 +315,93 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 		}
 	}
 
+	// Cargar autenticación hacia el Core (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/tls/ca_file", ""); err == nil && val != "" {
+		cfg.CoreTLSCAFile = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/tls/cert_file", ""); err == nil && val != "" {
+		cfg.CoreTLSCertFile = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/tls/key_file", ""); err == nil && val != "" {
+		cfg.CoreTLSKeyFile = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/tls/server_name", ""); err == nil && val != "" {
+		cfg.CoreTLSServerName = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/token", ""); err == nil && val != "" {
+		cfg.CoreToken = strings.TrimSpace(val)
+	}
+
+	// Cargar entrega confiable del stream (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/stream/replay_buffer_size", ""); err == nil && val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			cfg.ReplayBufferSize = size
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/stream/ack_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.AckInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/stream/resume_timeout_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.ResumeTimeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	// Cargar transporte por cuenta (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/transport/tls_cert_file", ""); err == nil && val != "" {
+		cfg.TLSCertFile = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/transport/tls_key_file", ""); err == nil && val != "" {
+		cfg.TLSKeyFile = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/transport/handshake_timeout_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.TransportHandshakeTimeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+	for _, accountID := range append(append([]string{}, cfg.MasterAccounts...), cfg.SlaveAccounts...) {
+		prefix := fmt.Sprintf("agent/accounts/%s/", accountID)
+		kind, err := etcdClient.GetVarWithDefault(ctx, prefix+"transport", "")
+		if err != nil || kind == "" {
+			continue
+		}
+		transport := &AccountTransport{Kind: strings.ToLower(strings.TrimSpace(kind))}
+		if val, err := etcdClient.GetVarWithDefault(ctx, prefix+"listen_addr", ""); err == nil {
+			transport.ListenAddr = strings.TrimSpace(val)
+		}
+		if val, err := etcdClient.GetVarWithDefault(ctx, prefix+"token", ""); err == nil {
+			transport.Token = strings.TrimSpace(val)
+		}
+		cfg.AccountTransports[accountID] = transport
+	}
+
+	// Cargar store-and-forward hacia el Core (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/outbound_queue/dir", ""); err == nil && val != "" {
+		cfg.OutboundQueueDir = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/outbound_queue/max_messages", ""); err == nil && val != "" {
+		if count, err := strconv.Atoi(val); err == nil && count > 0 {
+			cfg.OutboundQueueMaxMessages = count
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/outbound_queue/max_bytes", ""); err == nil && val != "" {
+		if size, err := strconv.ParseInt(val, 10, 64); err == nil && size > 0 {
+			cfg.OutboundQueueMaxBytes = size
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/outbound_queue/max_age_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.OutboundQueueMaxAge = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "agent/outbound_queue/fsync", ""); err == nil && val != "" {
+		if fsync, err := strconv.ParseBool(val); err == nil {
+			cfg.OutboundQueueFsync = fsync
+		}
+	}
+
 	// Cargar Telemetry
 	if val, err := etcdClient.GetVarWithDefault(ctx, "telemetry/service_name", ""); err == nil && val != "" {
 		cfg.ServiceName = val
@@ -239,9 +422,38 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 	if cfg.CoreAddress == "" {
 		return nil, fmt.Errorf("endpoints/core_addr not configured in ETCD")
 	}
-	if len(cfg.MasterAccounts) == 0 && len(cfg.SlaveAccounts) == 0 {
-		return nil, fmt.Errorf("agent/master_accounts or agent/slave_accounts not configured in ETCD")
+	if len(cfg.MasterAccounts) == 0 && len(cfg.SlaveAccounts) == 0 && !cfg.DiscoveryEnabled {
+		return nil, fmt.Errorf("agent/master_accounts or agent/slave_accounts not configured in ETCD (and agent/discovery/enabled is false)")
+	}
+	for accountID, transport := range cfg.AccountTransports {
+		switch transport.Kind {
+		case TransportPipe:
+		case TransportTCP:
+			if transport.ListenAddr == "" || transport.Token == "" {
+				return nil, fmt.Errorf("agent/accounts/%s: tcp transport requires listen_addr and token", accountID)
+			}
+		default:
+			return nil, fmt.Errorf("agent/accounts/%s/transport: unknown transport %q", accountID, transport.Kind)
+		}
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("agent/transport/tls_cert_file and agent/transport/tls_key_file must be set together")
+	}
+	if (cfg.CoreTLSCertFile == "") != (cfg.CoreTLSKeyFile == "") {
+		return nil, fmt.Errorf("grpc/tls/cert_file and grpc/tls/key_file must be set together")
 	}
 
 	return cfg, nil
 }
+
+// splitAccountList parsea una lista de cuentas separada por comas (sin vacíos).
+func splitAccountList(val string) []string {
+	parts := strings.Split(val, ",")
+	accounts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if account := strings.TrimSpace(part); account != "" {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
@@ -0,0 +1,193 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/utils"
+)
+
+// restartConfigKeys claves de ConfigUpdate válidas que solo aplican al reiniciar el Agent (i8).
+//
+// Definen canales, colas, transportes o el stream con el Core, que se crean en el arranque.
+var restartConfigKeys = map[string]bool{
+	"endpoints/core_addr":                  true,
+	"agent/agent_id":                       true,
+	"agent/pipe_prefix":                    true,
+	"agent/socket_dir":                     true,
+	"agent/pipe/framed_protobuf":           true,
+	"agent/flush_force":                    true,
+	"agent/send_queue_size":                true,
+	"agent/reconnect_backoff_s":            true,
+	"agent/discovery/enabled":              true,
+	"agent/protocol/min_version":           true,
+	"agent/protocol/max_version":           true,
+	"agent/protocol/allow_legacy":          true,
+	"agent/transport/tls_cert_file":        true,
+	"agent/transport/tls_key_file":         true,
+	"agent/transport/handshake_timeout_ms": true,
+	"agent/outbound_queue/dir":             true,
+	"agent/outbound_queue/max_messages":    true,
+	"agent/outbound_queue/max_bytes":       true,
+	"agent/outbound_queue/max_age_ms":      true,
+	"agent/outbound_queue/fsync":           true,
+	"grpc/stream/replay_buffer_size":       true,
+	"grpc/stream/ack_interval_ms":          true,
+	"grpc/stream/resume_timeout_ms":        true,
+	"grpc/token":                           true,
+}
+
+// restartConfigPrefixes prefijos de claves que solo aplican al reiniciar (i8).
+var restartConfigPrefixes = []string{
+	"agent/accounts/",        // transporte por cuenta
+	"grpc/client_keepalive/", // keepalive del cliente gRPC
+	"grpc/tls/",              // TLS/mTLS hacia el Core
+}
+
+// applyConfigUpdate aplica un ConfigUpdate del Core y responde con ConfigApplied (i8).
+//
+// Cada clave se aplica en caliente, se reporta como restart_required o se rechaza.
+// Una versión ya procesada (reenvío al reconectar) solo repite la respuesta.
+func (a *Agent) applyConfigUpdate(update *pb.ConfigUpdate) error {
+	a.configMu.Lock()
+	if a.configApplied != nil && a.configApplied.Version == update.Version {
+		applied := a.configApplied
+		a.configMu.Unlock()
+		return a.sendConfigApplied(applied)
+	}
+
+	keys := make([]string, 0, len(update.Config))
+	for key := range update.Config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	applied := &pb.ConfigApplied{Version: update.Version}
+	for _, key := range keys {
+		restart, err := a.applyConfigKey(key, update.Config[key])
+		switch {
+		case err != nil:
+			applied.Rejected = append(applied.Rejected, &pb.ConfigKeyRejection{Key: key, Reason: err.Error()})
+		case restart:
+			applied.RestartRequiredKeys = append(applied.RestartRequiredKeys, key)
+		default:
+			applied.AppliedKeys = append(applied.AppliedKeys, key)
+		}
+	}
+	a.configApplied = applied
+	a.configMu.Unlock()
+
+	a.logInfo("ConfigUpdate applied (i8)", map[string]interface{}{
+		"version":          update.Version,
+		"applied":          strings.Join(applied.AppliedKeys, ","),
+		"restart_required": strings.Join(applied.RestartRequiredKeys, ","),
+		"rejected":         len(applied.Rejected),
+	})
+	for _, rejection := range applied.Rejected {
+		a.logWarn("ConfigUpdate key rejected (i8)", map[string]interface{}{
+			"version": update.Version,
+			"key":     rejection.Key,
+			"reason":  rejection.Reason,
+		})
+	}
+
+	return a.sendConfigApplied(applied)
+}
+
+// applyConfigKey aplica una clave en caliente.
+//
+// Retorna restart=true si la clave es válida pero requiere reiniciar el Agent.
+func (a *Agent) applyConfigKey(key, value string) (restart bool, err error) {
+	value = strings.TrimSpace(value)
+
+	switch key {
+	case "agent/log_level":
+		level := strings.ToUpper(value)
+		if err := a.telemetry.SetLogLevel(level); err != nil {
+			return false, err
+		}
+		a.config.LogLevel = level
+		return false, nil
+
+	case "agent/heartbeat_interval_ms":
+		ms, err := strconv.Atoi(value)
+		if err != nil || ms < 0 {
+			return false, fmt.Errorf("invalid interval %q", value)
+		}
+		// Habilitar o deshabilitar el loop requiere reiniciar; cambiar el intervalo no
+		if ms == 0 || a.heartbeatInterval.Load() == 0 {
+			return true, nil
+		}
+		a.heartbeatInterval.Store(int64(time.Duration(ms) * time.Millisecond))
+		return false, nil
+
+	case "agent/master_accounts", "agent/slave_accounts":
+		role := strings.TrimSuffix(strings.TrimPrefix(key, "agent/"), "_accounts")
+		accounts := splitAccountList(value)
+		for _, accountID := range accounts {
+			if !accountIDPattern.MatchString(accountID) {
+				return false, fmt.Errorf("invalid account id %q", accountID)
+			}
+		}
+		if a.pipeManager == nil {
+			return true, nil
+		}
+		added, removed, err := a.pipeManager.UpdateRoster(role, accounts)
+		if err != nil {
+			return false, err
+		}
+		if len(added) > 0 {
+			a.logInfo("Account roster extended by ConfigUpdate (i8)", map[string]interface{}{
+				"role":  role,
+				"added": strings.Join(added, ","),
+			})
+		}
+		return len(removed) > 0, nil
+
+	case "agent/discovery/allow_accounts":
+		if a.pipeManager == nil {
+			return true, nil
+		}
+		a.pipeManager.SetDiscoveryLists(splitAccountList(value), nil)
+		return false, nil
+
+	case "agent/discovery/deny_accounts":
+		if a.pipeManager == nil {
+			return true, nil
+		}
+		a.pipeManager.SetDiscoveryLists(nil, splitAccountList(value))
+		return false, nil
+	}
+
+	if restartConfigKeys[key] {
+		return true, nil
+	}
+	for _, prefix := range restartConfigPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true, nil
+		}
+	}
+	return false, fmt.Errorf("unknown key")
+}
+
+// sendConfigApplied envía la respuesta al Core por el canal secuenciado.
+func (a *Agent) sendConfigApplied(applied *pb.ConfigApplied) error {
+	msg := &pb.AgentMessage{
+		AgentId:     a.config.AgentID,
+		TimestampMs: utils.NowUnixMilli(),
+		Payload: &pb.AgentMessage_ConfigApplied{
+			ConfigApplied: applied,
+		},
+	}
+
+	select {
+	case a.sendToCoreCh <- msg:
+		return nil
+	case <-a.ctx.Done():
+		return a.ctx.Err()
+	}
+}
@@ -0,0 +1,35 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyConfigKeyClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		value       string
+		wantRestart bool
+		wantErr     bool
+	}{
+		{name: "reinicio", key: "agent/send_queue_size", value: "100", wantRestart: true},
+		{name: "prefijo de reinicio", key: "grpc/tls/ca_file", value: "/etc/ca.pem", wantRestart: true},
+		{name: "intervalo inválido", key: "agent/heartbeat_interval_ms", value: "-1", wantErr: true},
+		{name: "cuenta inválida", key: "agent/slave_accounts", value: "1001,../x", wantErr: true},
+		// Nada en el Agent las usa: aceptarlas sería un ack falso
+		{name: "retry_enabled sin efecto", key: "agent/retry_enabled", value: "false", wantErr: true},
+		{name: "max_retries sin efecto", key: "agent/max_retries", value: "5", wantErr: true},
+		{name: "desconocida", key: "agent/unknown", value: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Agent{config: &Config{}}
+			restart, err := a.applyConfigKey(tt.key, tt.value)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantRestart, restart)
+		})
+	}
+}
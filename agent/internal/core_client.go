@@ -15,6 +15,7 @@ import (
 type CoreClient struct {
 	client    *grpcSDK.Client
 	svcClient pb.AgentServiceClient
+	token     string // agent-token (i8; vacío = sin token)
 }
 
 // NewCoreClient crea un nuevo cliente al Core (i1).
@@ -37,6 +38,17 @@ func NewCoreClient(ctx context.Context, agentConfig *Config) (*CoreClient, error
 		}
 	}
 
+	// i8: TLS/mTLS hacia el Core
+	if agentConfig.CoreTLSCAFile != "" || agentConfig.CoreTLSCertFile != "" {
+		config.Insecure = false
+		config.TLS = &grpcSDK.TLSConfig{
+			CAFile:     agentConfig.CoreTLSCAFile,
+			CertFile:   agentConfig.CoreTLSCertFile,
+			KeyFile:    agentConfig.CoreTLSKeyFile,
+			ServerName: agentConfig.CoreTLSServerName,
+		}
+	}
+
 	// Crear cliente usando SDK
 	grpcClient, err := grpcSDK.NewClient(ctx, config)
 	if err != nil {
@@ -49,6 +61,7 @@ func NewCoreClient(ctx context.Context, agentConfig *Config) (*CoreClient, error
 	return &CoreClient{
 		client:    grpcClient,
 		svcClient: svcClient,
+		token:     agentConfig.CoreToken,
 	}, nil
 }
 
@@ -57,11 +70,7 @@ func NewCoreClient(ctx context.Context, agentConfig *Config) (*CoreClient, error
 // Retorna el stream raw para que el Agent lo gestione.
 // Issue #C7: Envía agent-id en metadata gRPC para identificación única.
 func (c *CoreClient) StreamBidi(ctx context.Context, agentID string) (pb.AgentService_StreamBidiClient, error) {
-	// Agregar agent-id al contexto como metadata (Issue #C7)
-	md := metadata.New(map[string]string{
-		"agent-id": agentID,
-	})
-	ctx = metadata.NewOutgoingContext(ctx, md)
+	ctx = c.outgoingContext(ctx, agentID)
 
 	stream, err := c.svcClient.StreamBidi(ctx)
 	if err != nil {
@@ -77,7 +86,7 @@ func (c *CoreClient) Ping(ctx context.Context, agentID string) (*pb.PingResponse
 		AgentId: agentID, // i1: ID real del agent desde config
 	}
 
-	resp, err := c.svcClient.Ping(ctx, req)
+	resp, err := c.svcClient.Ping(c.outgoingContext(ctx, agentID), req)
 	if err != nil {
 		return nil, fmt.Errorf("ping failed: %w", err)
 	}
@@ -85,6 +94,17 @@ func (c *CoreClient) Ping(ctx context.Context, agentID string) (*pb.PingResponse
 	return resp, nil
 }
 
+// outgoingContext agrega agent-id (Issue #C7) y, si está configurado, agent-token (i8).
+func (c *CoreClient) outgoingContext(ctx context.Context, agentID string) context.Context {
+	md := metadata.New(map[string]string{
+		grpcSDK.AgentIDMetadataKey: agentID,
+	})
+	if c.token != "" {
+		md.Set(grpcSDK.AgentTokenMetadataKey, c.token)
+	}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
 // Close cierra la conexión.
 func (c *CoreClient) Close() error {
 	if c.client == nil {
@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/ipc"
+	"github.com/xKoRx/echo/sdk/utils"
+)
+
+// rendezvousPipeSuffix nombre del pipe de descubrimiento: <pipe_prefix>rendezvous.
+const rendezvousPipeSuffix = "rendezvous"
+
+// rendezvousReadTimeout espera del mensaje register tras conectar.
+//
+// El PipeServer atiende un cliente a la vez: un EA que conecta y no envía nada
+// demora a los demás hasta este timeout. El EA escribe register apenas conecta.
+const rendezvousReadTimeout = time.Second
+
+// Motivos de rechazo del rendezvous (payload.reason).
+const (
+	registerReasonInvalidMessage = "invalid_message"
+	registerReasonInvalidAccount = "invalid_account_id"
+	registerReasonInvalidRole    = "invalid_role"
+	registerReasonDenied         = "denied"
+	registerReasonPipeError      = "pipe_error"
+)
+
+// accountIDPattern caracteres admitidos en un account_id anunciado (forma parte del nombre del pipe).
+var accountIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// rendezvousPipeName retorna el nombre del pipe de descubrimiento.
+func (pm *PipeManager) rendezvousPipeName() string {
+	return pm.config.PipePrefix + rendezvousPipeSuffix
+}
+
+// startRendezvous crea el pipe de descubrimiento dinámico de cuentas (i8).
+func (pm *PipeManager) startRendezvous() error {
+	name := pm.rendezvousPipeName()
+	server, err := pm.newPipeServer(name, &AccountTransport{Kind: TransportPipe})
+	if err != nil {
+		return fmt.Errorf("failed to create rendezvous pipe %s: %w", name, err)
+	}
+
+	pm.pipesMu.Lock()
+	pm.rendezvous = server
+	pm.pipesMu.Unlock()
+
+	pm.discoveryMu.RLock()
+	allowCount, denyCount := len(pm.config.DiscoveryAllowAccounts), len(pm.config.DiscoveryDenyAccounts)
+	pm.discoveryMu.RUnlock()
+
+	pm.logInfo("Rendezvous pipe created (i8)", map[string]interface{}{
+		"pipe_name":      name,
+		"allow_accounts": allowCount,
+		"deny_accounts":  denyCount,
+	})
+
+	pm.wg.Add(1)
+	go func() {
+		defer pm.wg.Done()
+		pm.runRendezvous(server)
+	}()
+
+	return nil
+}
+
+// runRendezvous atiende el pipe de descubrimiento (i8).
+//
+// Cada conexión es una petición: el EA envía
+//
+//	{"type":"register","payload":{"account_id":"12345","role":"slave"}}
+//
+// y recibe register_result con el pipe dedicado de la cuenta (creado si no existía).
+// El Agent cierra la conexión tras responder; el EA se conecta luego al pipe
+// asignado, donde sigue el flujo normal (handshake, AccountConnected).
+func (pm *PipeManager) runRendezvous(server ipc.PipeServer) {
+	retryDelay := 100 * time.Millisecond
+	maxRetryDelay := 5 * time.Second
+
+	for {
+		if err := server.WaitForConnection(pm.ctx); err != nil {
+			if pm.ctx.Err() != nil {
+				return
+			}
+			pm.logError("Rendezvous accept failed", err, map[string]interface{}{
+				"pipe_name":   server.Name(),
+				"retry_delay": retryDelay.String(),
+			})
+			select {
+			case <-pm.ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			retryDelay *= 2
+			if retryDelay > maxRetryDelay {
+				retryDelay = maxRetryDelay
+			}
+			continue
+		}
+		retryDelay = 100 * time.Millisecond
+
+		pm.handleRendezvousRequest(server)
+
+		if err := server.DisconnectClient(); err != nil {
+			pm.logError("Failed to disconnect rendezvous client", err, map[string]interface{}{
+				"pipe_name": server.Name(),
+			})
+		}
+	}
+}
+
+// handleRendezvousRequest procesa un register y responde register_result.
+func (pm *PipeManager) handleRendezvousRequest(server ipc.PipeServer) {
+	msg, err := ipc.NewJSONReaderWithTimeout(server, rendezvousReadTimeout).ReadMessage()
+	if err != nil {
+		pm.logWarn("Rendezvous request not received", map[string]interface{}{
+			"pipe_name": server.Name(),
+			"error":     err.Error(),
+		})
+		return
+	}
+
+	payload, _ := msg["payload"].(map[string]interface{})
+	accountID := utils.ExtractString(payload, "account_id")
+	role := utils.ExtractString(payload, "role")
+
+	pipeName, reason := pm.registerAccount(utils.ExtractString(msg, "type"), accountID, role)
+
+	result := map[string]interface{}{
+		"account_id": accountID,
+		"role":       role,
+		"accepted":   reason == "",
+	}
+	if reason == "" {
+		result["pipe_name"] = pipeName
+	} else {
+		result["reason"] = reason
+		pm.logWarn("Rendezvous registration rejected (i8)", map[string]interface{}{
+			"account_id": accountID,
+			"role":       role,
+			"reason":     reason,
+		})
+	}
+
+	response := map[string]interface{}{
+		"type":         "register_result",
+		"timestamp_ms": utils.NowUnixMilli(),
+		"payload":      result,
+	}
+	if err := ipc.NewJSONWriter(server).WriteMessage(response); err != nil {
+		pm.logError("Failed to write rendezvous result", err, map[string]interface{}{
+			"account_id": accountID,
+		})
+	}
+}
+
+// registerAccount valida un anuncio y retorna el pipe dedicado de la cuenta.
+//
+// reason vacío = aceptado. El pipe se reutiliza si la cuenta ya tiene uno (lista
+// estática o registro anterior), de modo que un EA que se reinicia recibe el mismo.
+func (pm *PipeManager) registerAccount(msgType, accountID, role string) (pipeName, reason string) {
+	if msgType != "register" {
+		return "", registerReasonInvalidMessage
+	}
+	if !accountIDPattern.MatchString(accountID) {
+		return "", registerReasonInvalidAccount
+	}
+	if role != "master" && role != "slave" {
+		return "", registerReasonInvalidRole
+	}
+	if !pm.discoveryAllowed(accountID) {
+		return "", registerReasonDenied
+	}
+
+	pipeName = fmt.Sprintf("%s%s_%s", pm.config.PipePrefix, role, accountID)
+	if _, ok := pm.GetPipe(pipeName); ok {
+		return pipeName, ""
+	}
+
+	if err := pm.createPipe(pipeName, role, accountID); err != nil {
+		pm.logError("Failed to create discovered account pipe", err, map[string]interface{}{
+			"pipe_name":  pipeName,
+			"account_id": accountID,
+			"role":       role,
+		})
+		return "", registerReasonPipeError
+	}
+
+	pm.logInfo("Account discovered via rendezvous (i8)", map[string]interface{}{
+		"pipe_name":  pipeName,
+		"account_id": accountID,
+		"role":       role,
+	})
+	return pipeName, ""
+}
+
+// discoveryAllowed aplica agent/discovery/deny_accounts y allow_accounts.
+//
+// La deny list prevalece; una allow list vacía admite cualquier cuenta.
+func (pm *PipeManager) discoveryAllowed(accountID string) bool {
+	pm.discoveryMu.RLock()
+	defer pm.discoveryMu.RUnlock()
+
+	for _, denied := range pm.config.DiscoveryDenyAccounts {
+		if denied == accountID {
+			return false
+		}
+	}
+	if len(pm.config.DiscoveryAllowAccounts) == 0 {
+		return true
+	}
+	for _, allowed := range pm.config.DiscoveryAllowAccounts {
+		if allowed == accountID {
+			return true
+		}
+	}
+	return false
+}
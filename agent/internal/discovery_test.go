@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+)
+
+func newTestDiscoveryPipeManager(t *testing.T, allow, deny []string) *PipeManager {
+	t.Helper()
+
+	pm, err := NewPipeManager(context.Background(), &Config{
+		PipePrefix:             "echo_test_",
+		SocketDir:              t.TempDir(),
+		DiscoveryAllowAccounts: allow,
+		DiscoveryDenyAccounts:  deny,
+	}, &telemetry.Client{}, &metricbundle.EchoMetrics{})
+	require.NoError(t, err)
+	t.Cleanup(func() { pm.Close() })
+	return pm
+}
+
+func TestDiscoveryAllowedDenyPrecedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		allow   []string
+		deny    []string
+		account string
+		want    bool
+	}{
+		{name: "sin listas", account: "1001", want: true},
+		{name: "en allow", allow: []string{"1001"}, account: "1001", want: true},
+		{name: "fuera de allow", allow: []string{"1001"}, account: "1002", want: false},
+		{name: "en deny", deny: []string{"1001"}, account: "1001", want: false},
+		{name: "deny prevalece sobre allow", allow: []string{"1001"}, deny: []string{"1001"}, account: "1001", want: false},
+		{name: "deny no afecta a otras", deny: []string{"1001"}, account: "1002", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pm := newTestDiscoveryPipeManager(t, tt.allow, tt.deny)
+			assert.Equal(t, tt.want, pm.discoveryAllowed(tt.account))
+		})
+	}
+}
+
+func TestDiscoveryListsUpdatedByConfig(t *testing.T) {
+	pm := newTestDiscoveryPipeManager(t, nil, nil)
+
+	pm.SetDiscoveryLists(nil, []string{"1001"})
+	assert.False(t, pm.discoveryAllowed("1001"))
+	assert.True(t, pm.discoveryAllowed("1002"))
+
+	// nil conserva la lista vigente
+	pm.SetDiscoveryLists([]string{"1003"}, nil)
+	assert.False(t, pm.discoveryAllowed("1001"))
+	assert.False(t, pm.discoveryAllowed("1002"))
+	assert.True(t, pm.discoveryAllowed("1003"))
+}
+
+func TestRegisterAccountValidation(t *testing.T) {
+	pm := newTestDiscoveryPipeManager(t, nil, []string{"666"})
+
+	tests := []struct {
+		name    string
+		msgType string
+		account string
+		role    string
+		reason  string
+	}{
+		{name: "tipo desconocido", msgType: "hello", account: "1001", role: "slave", reason: registerReasonInvalidMessage},
+		{name: "account vacío", msgType: "register", account: "", role: "slave", reason: registerReasonInvalidAccount},
+		{name: "separador de ruta", msgType: "register", account: "../1001", role: "slave", reason: registerReasonInvalidAccount},
+		{name: "espacios", msgType: "register", account: "10 01", role: "slave", reason: registerReasonInvalidAccount},
+		{name: "demasiado largo", msgType: "register", account: strings.Repeat("1", 65), role: "slave", reason: registerReasonInvalidAccount},
+		{name: "rol inválido", msgType: "register", account: "1001", role: "observer", reason: registerReasonInvalidRole},
+		{name: "denegada", msgType: "register", account: "666", role: "slave", reason: registerReasonDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeName, reason := pm.registerAccount(tt.msgType, tt.account, tt.role)
+			assert.Equal(t, tt.reason, reason)
+			assert.Empty(t, pipeName)
+		})
+	}
+
+	pm.pipesMu.RLock()
+	defer pm.pipesMu.RUnlock()
+	assert.Empty(t, pm.pipes, "rejected registrations create no pipe")
+}
+
+func TestRegisterAccountReusesPipe(t *testing.T) {
+	pm := newTestDiscoveryPipeManager(t, nil, nil)
+
+	pipeName, reason := pm.registerAccount("register", "1001", "slave")
+	require.Empty(t, reason)
+	assert.Equal(t, "echo_test_slave_1001", pipeName)
+	first, ok := pm.GetPipe(pipeName)
+	require.True(t, ok)
+	assert.Equal(t, "1001", first.accountID)
+	assert.Equal(t, "slave", first.role)
+
+	// Un EA que se reinicia recibe el mismo pipe (y el mismo handler)
+	again, reason := pm.registerAccount("register", "1001", "slave")
+	require.Empty(t, reason)
+	assert.Equal(t, pipeName, again)
+	second, ok := pm.GetPipe(again)
+	require.True(t, ok)
+	assert.Same(t, first, second)
+
+	// El rol forma parte del nombre: la misma cuenta como master tiene su propio pipe
+	masterPipe, reason := pm.registerAccount("register", "1001", "master")
+	require.Empty(t, reason)
+	assert.Equal(t, "echo_test_master_1001", masterPipe)
+
+	pm.pipesMu.RLock()
+	defer pm.pipesMu.RUnlock()
+	assert.Len(t, pm.pipes, 2)
+}
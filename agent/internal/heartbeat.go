@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"sort"
+	"time"
+
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/utils"
+)
+
+// heartbeatLoop envía un AgentHeartbeat cada agent/heartbeat_interval_ms (i8).
+//
+// El Core usa los heartbeats para distinguir un Agent sano pero sin tráfico de un
+// stream half-open: tras varios intervalos sin recibirlos deja de rutear a sus cuentas.
+// Un intervalo nuevo recibido por ConfigUpdate aplica desde el siguiente tick.
+func (a *Agent) heartbeatLoop() {
+	defer a.wg.Done()
+
+	interval := a.currentHeartbeatInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.ctx.Done():
+			return
+		case <-ticker.C:
+			a.sendHeartbeat()
+			if current := a.currentHeartbeatInterval(); current != interval {
+				interval = current
+				ticker.Reset(interval)
+			}
+		}
+	}
+}
+
+// currentHeartbeatInterval retorna el intervalo vigente (config o último ConfigUpdate).
+func (a *Agent) currentHeartbeatInterval() time.Duration {
+	return time.Duration(a.heartbeatInterval.Load())
+}
+
+// sendHeartbeat envía el estado actual de pipes y cola al Core (i8).
+//
+// Va fuera de la secuencia y del store-and-forward: un heartbeat solo describe el
+// instante en que se envía, reenviarlo tras reconectar no aporta nada.
+func (a *Agent) sendHeartbeat() {
+	if !a.streamUp.Load() {
+		return
+	}
+
+	msg := &pb.AgentMessage{
+		AgentId:     a.config.AgentID,
+		TimestampMs: utils.NowUnixMilli(),
+		Payload: &pb.AgentMessage_Heartbeat{
+			Heartbeat: a.buildHeartbeat(),
+		},
+	}
+
+	a.streamMu.Lock()
+	err := a.coreStream.Send(msg)
+	a.streamMu.Unlock()
+	if err != nil {
+		// receiveFromCore detecta la caída del stream y reconecta
+		a.logDebug("Failed to send heartbeat to Core (i8)", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// buildHeartbeat arma el AgentHeartbeat con el estado de cada pipe y del store-and-forward.
+func (a *Agent) buildHeartbeat() *pb.AgentHeartbeat {
+	pending, bytes := a.outbound.Stats()
+	pendingCommands := int32(pending)
+
+	heartbeat := &pb.AgentHeartbeat{
+		AgentId:         a.config.AgentID,
+		TimestampMs:     utils.NowUnixMilli(),
+		PendingCommands: &pendingCommands,
+		IntervalMs:      a.currentHeartbeatInterval().Milliseconds(),
+		PendingBytes:    bytes,
+	}
+
+	if a.pipeManager == nil {
+		return heartbeat
+	}
+
+	heartbeat.Pipes = a.pipeManager.PipeStatuses()
+	for _, pipe := range heartbeat.Pipes {
+		if pipe.Connected {
+			heartbeat.ConnectedClients = append(heartbeat.ConnectedClients, pipe.AccountId)
+		}
+	}
+	return heartbeat
+}
+
+// PipeStatuses retorna el estado de los pipes de cuentas ordenado por nombre (i8).
+func (pm *PipeManager) PipeStatuses() []*pb.PipeStatus {
+	pm.pipesMu.RLock()
+	handlers := make([]*PipeHandler, 0, len(pm.pipes))
+	for _, handler := range pm.pipes {
+		handlers = append(handlers, handler)
+	}
+	pm.pipesMu.RUnlock()
+
+	sort.Slice(handlers, func(i, j int) bool {
+		return handlers[i].name < handlers[j].name
+	})
+
+	statuses := make([]*pb.PipeStatus, 0, len(handlers))
+	for _, handler := range handlers {
+		statuses = append(statuses, &pb.PipeStatus{
+			PipeName:  handler.name,
+			AccountId: handler.accountID,
+			Role:      handler.role,
+			Connected: handler.isConnected(),
+			Codec:     handler.codecName(),
+		})
+	}
+	return statuses
+}
@@ -0,0 +1,246 @@
+package internal
+
+import (
+	"errors"
+	"sync"
+
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/utils"
+)
+
+// enqueueOutbound persiste un AgentMessage en el store-and-forward (i8).
+//
+// Registra T1AgentRecvMs de los TradeIntents al llegar, de modo que el Core mide
+// la edad real de la señal aunque se entregue tras una desconexión. Si la cola en
+// disco falla, el mensaje se envía directo (solo lo cubre el replay en memoria).
+func (a *Agent) enqueueOutbound(msg *pb.AgentMessage) {
+	if intent := msg.GetTradeIntent(); intent != nil {
+		if intent.Timestamps == nil {
+			intent.Timestamps = &pb.TimestampMetadata{}
+		}
+		if intent.Timestamps.T1AgentRecvMs == 0 {
+			intent.Timestamps.T1AgentRecvMs = utils.NowUnixMilli()
+		}
+	}
+
+	entry, evicted, err := a.outbound.Enqueue(msg)
+	if err != nil {
+		a.logError("Failed to persist outbound message, sending without store-and-forward (i8)", err, nil)
+		_ = a.sendOutbound(msg)
+		return
+	}
+
+	if evicted > 0 {
+		pending, bytes := a.outbound.Stats()
+		a.logWarn("Outbound queue full, oldest messages dropped (i8)", map[string]interface{}{
+			"evicted": evicted,
+			"pending": pending,
+			"bytes":   bytes,
+			"index":   entry.Index,
+		})
+	}
+}
+
+// drainOutbound envía en orden los mensajes pendientes del store-and-forward (i8).
+//
+// Se detiene si el stream está caído; lo pendiente se drena al reconectar. Los
+// mensajes sensibles al tiempo más antiguos que OutboundQueueMaxAge se envían
+// marcados como expired para que el Core no los procese como señales en tiempo real.
+//
+// El cursor en disco no avanza aquí: cada mensaje queda en el spool hasta que el
+// ack acumulativo del Core cubre su seq (ackOutbound). Si el envío falla, la sesión
+// lo reenvía al reanudar; si el Agent se reinicia antes, se envía de nuevo desde disco.
+// Con el buffer de replay lleno el drenado se pausa hasta el próximo ack.
+func (a *Agent) drainOutbound() {
+	drained := 0
+	expired := 0
+	defer func() {
+		if drained > 1 || expired > 0 {
+			pending, _ := a.outbound.Stats()
+			a.logInfo("Outbound queue drained (i8)", map[string]interface{}{
+				"drained": drained,
+				"expired": expired,
+				"pending": pending,
+			})
+		}
+	}()
+
+	for a.streamUp.Load() && a.ctx.Err() == nil {
+		entry := a.outbound.Next(a.outboundSent)
+		if entry == nil {
+			return
+		}
+
+		msg := entry.Message
+		queuedMs := utils.NowUnixMilli() - entry.ArrivalMs
+		if queuedMs < 0 {
+			queuedMs = 0
+		}
+		msg.QueuedMs = queuedMs
+		msg.Expired = isTimeSensitive(msg) && queuedMs > a.config.OutboundQueueMaxAge.Milliseconds()
+
+		// Registrar antes de enviar: sendSequenced asigna el seq bajo streamMu
+		a.inFlight.add(msg, entry.Index)
+		err := a.sendOutbound(msg)
+		if errors.Is(err, grpcSDK.ErrReplayBufferFull) {
+			a.inFlight.remove(msg)
+			return
+		}
+
+		// Enviado, o retenido por la sesión para el replay al reanudar
+		a.outboundSent = entry.Index
+		drained++
+		if msg.Expired {
+			expired++
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// ackOutbound libera los AgentMessages con seq <= ackSeq y confirma en disco los
+// mensajes del store-and-forward que cubre el ack (i8).
+//
+// Retorna la cantidad de mensajes liberados de la sesión.
+func (a *Agent) ackOutbound(ackSeq uint64) int {
+	released := a.session.Ack(ackSeq)
+	if released > 0 && a.streamUp.Load() {
+		// Reanudar el drenado si estaba pausado por el buffer de replay lleno
+		select {
+		case a.streamReady <- struct{}{}:
+		default:
+		}
+	}
+
+	index, ok := a.inFlight.ack(ackSeq)
+	if !ok {
+		return released
+	}
+	if err := a.outbound.Commit(index); err != nil {
+		a.logError("Failed to commit outbound queue cursor (i8)", err, map[string]interface{}{
+			"index":   index,
+			"ack_seq": ackSeq,
+		})
+	}
+	return released
+}
+
+// sendOutbound envía msg por el stream; si falla, deja de drenar hasta reconectar (i8).
+//
+// Con el buffer de replay lleno retorna grpcSDK.ErrReplayBufferFull sin enviar.
+func (a *Agent) sendOutbound(msg *pb.AgentMessage) error {
+	if err := a.sendSequenced(msg); err != nil {
+		if errors.Is(err, grpcSDK.ErrReplayBufferFull) {
+			pending, _ := a.session.Stats()
+			a.logDebug("Replay buffer full, waiting for Core ack (i8)", map[string]interface{}{
+				"pending": pending,
+			})
+			return err
+		}
+		a.streamUp.Store(false)
+		a.logWarn("Failed to send to Core, will replay on resume (i8)", map[string]interface{}{
+			"seq":   msg.Seq,
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	if msg.Expired {
+		a.logWarn("Expired AgentMessage sent to Core (i8)", map[string]interface{}{
+			"seq":       msg.Seq,
+			"queued_ms": msg.QueuedMs,
+			"max_age":   a.config.OutboundQueueMaxAge.String(),
+		})
+	}
+
+	// Log según tipo de mensaje
+	a.logSentMessage(msg)
+	return nil
+}
+
+// markStreamUp habilita el drenado del store-and-forward tras abrir el stream (i8).
+func (a *Agent) markStreamUp() {
+	a.streamUp.Store(true)
+	select {
+	case a.streamReady <- struct{}{}:
+	default:
+		// Ya hay un drenado pendiente
+	}
+}
+
+// isTimeSensitive indica si el payload pierde validez con la edad (i8).
+//
+// Los TradeIntents y quotes vencidos no deben ejecutarse ni usarse como precio
+// actual; cierres, resultados de ejecución y reportes de cuenta se procesan siempre.
+func isTimeSensitive(msg *pb.AgentMessage) bool {
+	switch msg.Payload.(type) {
+	case *pb.AgentMessage_TradeIntent, *pb.AgentMessage_SymbolQuoteSnapshot:
+		return true
+	default:
+		return false
+	}
+}
+
+// outboundInFlight relaciona los mensajes del spool enviados con su seq de sesión (i8).
+//
+// Se mantiene en orden de envío, que coincide con el orden de index y de seq. Una
+// sesión nueva reenvía lo pendiente con seq nuevos; assign actualiza el seq.
+type outboundInFlight struct {
+	mu      sync.Mutex
+	entries []outboundInFlightEntry
+}
+
+type outboundInFlightEntry struct {
+	msg   *pb.AgentMessage
+	index uint64
+	seq   uint64 // 0 hasta que la sesión asigna seq
+}
+
+func (f *outboundInFlight) add(msg *pb.AgentMessage, index uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, outboundInFlightEntry{msg: msg, index: index})
+}
+
+// remove descarta msg si la sesión no lo aceptó (buffer de replay lleno).
+func (f *outboundInFlight) remove(msg *pb.AgentMessage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.entries) - 1; i >= 0; i-- {
+		if f.entries[i].msg == msg {
+			f.entries = append(f.entries[:i], f.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// assign registra el seq asignado a msg si proviene del spool.
+func (f *outboundInFlight) assign(msg *pb.AgentMessage, seq uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.entries {
+		if f.entries[i].msg == msg {
+			f.entries[i].seq = seq
+			return
+		}
+	}
+}
+
+// ack descarta los mensajes con seq <= ackSeq y retorna el mayor index cubierto.
+func (f *outboundInFlight) ack(ackSeq uint64) (uint64, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	acked := 0
+	for acked < len(f.entries) && f.entries[acked].seq != 0 && f.entries[acked].seq <= ackSeq {
+		acked++
+	}
+	if acked == 0 {
+		return 0, false
+	}
+	index := f.entries[acked-1].index
+	f.entries = f.entries[acked:]
+	return index, true
+}
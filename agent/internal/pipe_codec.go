@@ -0,0 +1,294 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/ipc"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codecs del pipe EA ↔ Agent (i8).
+const (
+	pipeCodecJSON   = "json"
+	pipeCodecFramed = "framed_protobuf"
+)
+
+// resetCodec vuelve a JSON al iniciar una sesión; el EA renegocia en su handshake.
+func (h *PipeHandler) resetCodec() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.framed = false
+}
+
+// isFramed indica si la sesión actual negoció el codec framed protobuf.
+func (h *PipeHandler) isFramed() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.framed
+}
+
+// codecName retorna el codec de escritura de la sesión actual (para logs).
+func (h *PipeHandler) codecName() string {
+	if h.isFramed() {
+		return pipeCodecFramed
+	}
+	return pipeCodecJSON
+}
+
+// enableFramedCodec confirma al EA el codec framed protobuf (i8).
+//
+// La confirmación (type "codec_selected") es la última línea JSON que escribe el
+// Agent en la sesión; el EA pasa a enviar frames al recibirla. Lo que el EA envíe
+// en JSON mientras tanto se sigue aceptando: el lector detecta el codec por mensaje.
+func (h *PipeHandler) enableFramedCodec() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return fmt.Errorf("pipe is closed")
+	}
+	if h.framed {
+		// Re-handshake en la misma sesión
+		return nil
+	}
+
+	msg := map[string]interface{}{
+		"type":         "codec_selected",
+		"timestamp_ms": utils.NowUnixMilli(),
+		"payload": map[string]interface{}{
+			"codec": pipeCodecFramed,
+		},
+	}
+	if err := ipc.NewJSONWriter(h.server).WriteMessage(msg); err != nil {
+		return fmt.Errorf("failed to write codec_selected: %w", err)
+	}
+	h.framed = true
+
+	h.logInfo("Pipe codec negotiated (i8)", map[string]interface{}{
+		"pipe_name":  h.name,
+		"account_id": h.accountID,
+		"codec":      pipeCodecFramed,
+	})
+	return nil
+}
+
+// handleFrame procesa un frame protobuf del EA (i8).
+//
+// Aplica las mismas validaciones y el mismo reenvío al Core que el path JSON,
+// sin el map intermedio.
+func (h *PipeHandler) handleFrame(data []byte) error {
+	msg := &pb.PipeMessage{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("failed to decode pipe frame: %w", err)
+	}
+
+	switch payload := msg.Payload.(type) {
+	case *pb.PipeMessage_Ping:
+		return h.handleFramedPing(payload.Ping)
+
+	case *pb.PipeMessage_StateSnapshot:
+		return h.forwardStateSnapshot(payload.StateSnapshot)
+
+	case *pb.PipeMessage_SymbolSpecReport:
+		return h.handleFramedSymbolSpecReport(payload.SymbolSpecReport)
+
+	case *pb.PipeMessage_SymbolQuoteSnapshot:
+		return h.forwardQuoteSnapshot(payload.SymbolQuoteSnapshot)
+
+	case *pb.PipeMessage_TradeIntent:
+		if h.role == "master" {
+			return h.handleFramedTradeIntent(payload.TradeIntent, msg.TimestampMs)
+		}
+
+	case *pb.PipeMessage_TradeClose:
+		if h.role == "master" {
+			return h.handleFramedTradeClose(payload.TradeClose, msg.TimestampMs)
+		}
+
+	case *pb.PipeMessage_TradeModify:
+		if h.role == "master" {
+			return h.handleFramedTradeModify(payload.TradeModify, msg.TimestampMs)
+		}
+
+	case *pb.PipeMessage_ExecutionResult:
+		if h.role == "slave" {
+			return h.handleFramedExecutionResult(payload.ExecutionResult)
+		}
+	}
+
+	h.logWarn("Unexpected framed message", map[string]interface{}{
+		"payload_type": fmt.Sprintf("%T", msg.Payload),
+		"role":         h.role,
+		"pipe_name":    h.name,
+	})
+	return nil
+}
+
+// handleFramedTradeIntent valida un TradeIntent recibido como frame.
+func (h *PipeHandler) handleFramedTradeIntent(intent *pb.TradeIntent, frameTimestampMs int64) error {
+	// Issue #M1: t1 al recibir del pipe, igual que en JSON
+	t1 := utils.NowUnixMilli()
+
+	if intent.TimestampMs == 0 {
+		intent.TimestampMs = frameTimestampMs
+	}
+	if intent.Timestamps == nil {
+		intent.Timestamps = &pb.TimestampMetadata{}
+	}
+
+	// Mismo default que domain.JSONToTradeIntentWithWhitelist
+	whitelist := h.canonicalSymbols
+	if len(whitelist) == 0 {
+		whitelist = []string{"XAUUSD"}
+	}
+	if err := domain.ValidateTradeIntent(intent, whitelist); err != nil {
+		return fmt.Errorf("failed to parse trade_intent: validation failed: %w", err)
+	}
+
+	return h.forwardTradeIntent(intent, t1)
+}
+
+// handleFramedTradeClose valida un TradeClose recibido como frame.
+func (h *PipeHandler) handleFramedTradeClose(tradeClose *pb.TradeClose, frameTimestampMs int64) error {
+	if tradeClose.TimestampMs == 0 {
+		tradeClose.TimestampMs = frameTimestampMs
+	}
+	if err := domain.ValidateTradeClose(tradeClose); err != nil {
+		return fmt.Errorf("failed to parse trade_close: validation failed: %w", err)
+	}
+
+	return h.forwardTradeClose(tradeClose)
+}
+
+// handleFramedTradeModify valida un TradeModify recibido como frame (i8).
+func (h *PipeHandler) handleFramedTradeModify(modify *pb.TradeModify, frameTimestampMs int64) error {
+	if modify.TimestampMs == 0 {
+		modify.TimestampMs = frameTimestampMs
+	}
+	if err := domain.ValidateTradeModify(modify); err != nil {
+		return fmt.Errorf("failed to parse trade_modify: validation failed: %w", err)
+	}
+
+	return h.forwardTradeModify(modify)
+}
+
+// handleFramedExecutionResult valida un ExecutionResult (ejecución o cierre) recibido como frame.
+func (h *PipeHandler) handleFramedExecutionResult(result *pb.ExecutionResult) error {
+	if err := domain.ValidateExecutionResult(result); err != nil {
+		return fmt.Errorf("failed to parse execution_result: validation failed: %w", err)
+	}
+
+	return h.forwardExecutionResult(result)
+}
+
+// handleFramedSymbolSpecReport aplica los filtros del reporte de especificaciones recibido como frame.
+func (h *PipeHandler) handleFramedSymbolSpecReport(report *pb.SymbolSpecReport) error {
+	accountID := report.AccountId
+	if accountID == "" {
+		accountID = h.accountID
+	}
+
+	reportedAt := report.ReportedAtMs
+	if reportedAt <= 0 {
+		reportedAt = nowUnixMilli()
+	}
+
+	if h.isStaleSpecReport(accountID, reportedAt) {
+		return nil
+	}
+
+	if len(report.Symbols) == 0 {
+		h.logWarn("SymbolSpecReport without symbols", map[string]interface{}{
+			"account_id": accountID,
+		})
+		h.echoMetrics.RecordAgentSpecsFiltered(h.ctx, accountID, "empty_payload",
+			attribute.Int64("reported_at_ms", reportedAt),
+		)
+		return nil
+	}
+
+	return h.forwardSymbolSpecReport(accountID, reportedAt, report.Symbols)
+}
+
+// handleFramedPing responde un PipePing.
+func (h *PipeHandler) handleFramedPing(ping *pb.PipePing) error {
+	if ping == nil || ping.Id == "" {
+		h.logWarn("Ping without id", map[string]interface{}{
+			"pipe_name": h.name,
+		})
+		return nil
+	}
+
+	return h.writeFramedPong(ping.Id, ping.TimestampMs)
+}
+
+// writeFramedPong escribe un PipePong (i8).
+func (h *PipeHandler) writeFramedPong(pingID string, echoMs int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return fmt.Errorf("pipe is closed")
+	}
+
+	nowMs := utils.NowUnixMilli()
+	pong := &pb.PipeMessage{
+		TimestampMs: nowMs,
+		Payload: &pb.PipeMessage_Pong{
+			Pong: &pb.PipePong{
+				Id:          pingID,
+				TimestampMs: nowMs,
+				EchoMs:      echoMs,
+			},
+		},
+	}
+	if err := h.writeFrameLocked(pong); err != nil {
+		h.logError("Failed to send pong", err, map[string]interface{}{
+			"ping_id": pingID,
+		})
+		return err
+	}
+
+	h.logDebug("Pong sent", map[string]interface{}{
+		"ping_id":  pingID,
+		"echo_ms":  echoMs,
+		"rtt_calc": nowMs - echoMs,
+	})
+	return nil
+}
+
+// writeOrderFrameLocked escribe una orden del Core como frame. Requiere h.mu.
+func (h *PipeHandler) writeOrderFrameLocked(msg interface{}) error {
+	pipeMsg := &pb.PipeMessage{
+		TimestampMs: utils.NowUnixMilli(),
+	}
+
+	switch m := msg.(type) {
+	case *pb.ExecuteOrder:
+		pipeMsg.Payload = &pb.PipeMessage_ExecuteOrder{ExecuteOrder: m}
+	case *pb.CloseOrder:
+		pipeMsg.Payload = &pb.PipeMessage_CloseOrder{CloseOrder: m}
+	default:
+		return fmt.Errorf("unsupported message type: %T", msg)
+	}
+
+	return h.writeFrameLocked(pipeMsg)
+}
+
+// writeFrameLocked serializa y escribe msg como frame. Requiere h.mu.
+func (h *PipeHandler) writeFrameLocked(msg *pb.PipeMessage) error {
+	data, err := proto.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pipe message: %w", err)
+	}
+
+	if err := ipc.NewFrameWriter(h.server).WriteFrame(data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+
+	return nil
+}
@@ -1,10 +1,9 @@
-//go:build windows
-// +build windows
-
 package internal
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -36,6 +35,9 @@ func preview(b []byte, max int) string {
 
 // PipeManager gestiona los Named Pipes y conexiones de EAs.
 //
+// En Linux/macOS (terminales bajo Wine, CI) los pipes son Unix domain sockets en
+// agent/socket_dir; el resto del flujo es idéntico.
+//
 // Responsabilidades:
 //   - Crear Named Pipes (1 por EA)
 //   - Esperar conexiones de EAs
@@ -50,6 +52,13 @@ type PipeManager struct {
 	// Pipes activos
 	pipes   map[string]*PipeHandler // key: pipe name (ej: "echo_master_12345")
 	pipesMu sync.RWMutex
+	started bool // i8: Start ya leyó el roster (UpdateRoster crea los pipes directamente)
+
+	// i8: Protege allow/deny de discovery (actualizables por ConfigUpdate)
+	discoveryMu sync.RWMutex
+
+	// i8: Pipe de descubrimiento dinámico de cuentas (nil = deshabilitado)
+	rendezvous ipc.PipeServer
 
 	// Lifecycle
 	ctx    context.Context
@@ -66,6 +75,9 @@ type PipeManager struct {
 	protocolMinVersion int
 	protocolMaxVersion int
 	allowLegacy        bool
+
+	// i8: TLS para cuentas con transporte TCP (nil = TCP plano)
+	tlsConfig *tls.Config
 }
 
 // NewPipeManager crea un nuevo PipeManager.
@@ -84,6 +96,19 @@ func NewPipeManager(ctx context.Context, config *Config, tel *telemetry.Client,
 		allowLegacy:        config.ProtocolAllowLegacy,
 	}
 
+	// i8: Certificado del listener TCP (compartido por todas las cuentas TCP)
+	if config.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			cancel()
+			return nil, fmt.Errorf("failed to load transport TLS certificate: %w", err)
+		}
+		pm.tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+
 	return pm, nil
 }
 
@@ -93,18 +118,27 @@ func (pm *PipeManager) SetHandshakeCallback(cb func(string)) {
 
 // Start inicia la gestión de pipes.
 //
-// Crea pipes para masters y slaves y espera conexiones.
+// Crea pipes para masters y slaves y espera conexiones. Con agent/discovery/enabled
+// abre además el pipe de rendezvous, donde EAs no listados obtienen su pipe (i8).
 func (pm *PipeManager) Start(sendToCoreCh chan *pb.AgentMessage, agentID string) error {
 	pm.sendToCoreCh = sendToCoreCh
 	pm.agentID = agentID // i2: Guardar agentID
 
+	// i8: Copia del roster; un ConfigUpdate posterior crea sus pipes con UpdateRoster
+	pm.pipesMu.Lock()
+	pm.started = true
+	masterAccounts := append([]string(nil), pm.config.MasterAccounts...)
+	slaveAccounts := append([]string(nil), pm.config.SlaveAccounts...)
+	pm.pipesMu.Unlock()
+
 	pm.logInfo("PipeManager starting", map[string]interface{}{
-		"master_accounts": len(pm.config.MasterAccounts),
-		"slave_accounts":  len(pm.config.SlaveAccounts),
+		"master_accounts": len(masterAccounts),
+		"slave_accounts":  len(slaveAccounts),
+		"discovery":       pm.config.DiscoveryEnabled,
 	})
 
 	// Crear pipes para masters
-	for _, accountID := range pm.config.MasterAccounts {
+	for _, accountID := range masterAccounts {
 		pipeName := fmt.Sprintf("%smaster_%s", pm.config.PipePrefix, accountID)
 		if err := pm.createPipe(pipeName, "master", accountID); err != nil {
 			return fmt.Errorf("failed to create master pipe %s: %w", pipeName, err)
@@ -112,15 +146,26 @@ func (pm *PipeManager) Start(sendToCoreCh chan *pb.AgentMessage, agentID string)
 	}
 
 	// Crear pipes para slaves
-	for _, accountID := range pm.config.SlaveAccounts {
+	for _, accountID := range slaveAccounts {
 		pipeName := fmt.Sprintf("%sslave_%s", pm.config.PipePrefix, accountID)
 		if err := pm.createPipe(pipeName, "slave", accountID); err != nil {
 			return fmt.Errorf("failed to create slave pipe %s: %w", pipeName, err)
 		}
 	}
 
+	// i8: Descubrimiento dinámico de cuentas
+	if pm.config.DiscoveryEnabled {
+		if err := pm.startRendezvous(); err != nil {
+			return err
+		}
+	}
+
+	pm.pipesMu.RLock()
+	totalPipes := len(pm.pipes)
+	pm.pipesMu.RUnlock()
+
 	pm.logInfo("PipeManager started", map[string]interface{}{
-		"total_pipes": len(pm.pipes),
+		"total_pipes": totalPipes,
 	})
 
 	// Esperar señal de cierre
@@ -128,18 +173,85 @@ func (pm *PipeManager) Start(sendToCoreCh chan *pb.AgentMessage, agentID string)
 	return nil
 }
 
+// UpdateRoster aplica la lista de cuentas de un rol recibida por ConfigUpdate (i8).
+//
+// Crea los pipes de las cuentas nuevas. Las cuentas que ya no figuran conservan su
+// pipe hasta reiniciar el Agent: se retornan en removed para reportarlo al Core.
+func (pm *PipeManager) UpdateRoster(role string, accounts []string) (added, removed []string, err error) {
+	pm.pipesMu.Lock()
+	current := &pm.config.MasterAccounts
+	if role == "slave" {
+		current = &pm.config.SlaveAccounts
+	}
+
+	wanted := make(map[string]bool, len(accounts))
+	for _, accountID := range accounts {
+		wanted[accountID] = true
+	}
+	existing := make(map[string]bool, len(*current))
+	for _, accountID := range *current {
+		existing[accountID] = true
+		if !wanted[accountID] {
+			removed = append(removed, accountID)
+		}
+	}
+	for _, accountID := range accounts {
+		if !existing[accountID] {
+			added = append(added, accountID)
+			existing[accountID] = true
+		}
+	}
+	*current = append(append([]string(nil), *current...), added...)
+	started := pm.started
+	pm.pipesMu.Unlock()
+
+	if !started {
+		return added, removed, nil // Start crea todos los pipes del roster
+	}
+
+	for _, accountID := range added {
+		pipeName := fmt.Sprintf("%s%s_%s", pm.config.PipePrefix, role, accountID)
+		if _, ok := pm.GetPipe(pipeName); ok {
+			continue // ya descubierta por el rendezvous
+		}
+		if err := pm.createPipe(pipeName, role, accountID); err != nil {
+			return added, removed, fmt.Errorf("failed to create %s pipe %s: %w", role, pipeName, err)
+		}
+	}
+	return added, removed, nil
+}
+
+// SetDiscoveryLists reemplaza allow/deny de discovery (i8: ConfigUpdate).
+//
+// Aplica a los próximos registros; los pipes ya asignados se conservan.
+func (pm *PipeManager) SetDiscoveryLists(allow, deny []string) {
+	pm.discoveryMu.Lock()
+	defer pm.discoveryMu.Unlock()
+	if allow != nil {
+		pm.config.DiscoveryAllowAccounts = allow
+	}
+	if deny != nil {
+		pm.config.DiscoveryDenyAccounts = deny
+	}
+}
+
 // createPipe crea un Named Pipe y arranca su handler.
 func (pm *PipeManager) createPipe(name, role, accountID string) error {
+	transport := pm.config.TransportFor(accountID)
+
 	// Crear pipe server usando SDK
-	pipeServer, err := ipc.NewWindowsPipeServer(name)
+	pipeServer, err := pm.newPipeServer(name, transport)
 	if err != nil {
 		return fmt.Errorf("failed to create pipe server: %w", err)
 	}
 
 	pm.logInfo("Pipe created", map[string]interface{}{
-		"pipe_name":  name,
-		"role":       role,
-		"account_id": accountID,
+		"pipe_name":   name,
+		"role":        role,
+		"account_id":  accountID,
+		"transport":   transport.Kind,
+		"listen_addr": transport.ListenAddr,
+		"tls":         transport.Kind == TransportTCP && pm.tlsConfig != nil,
 	})
 
 	// Crear handler
@@ -158,10 +270,17 @@ func (pm *PipeManager) createPipe(name, role, accountID string) error {
 		allowLegacy:        pm.allowLegacy,
 		protocolMinVersion: pm.protocolMinVersion,
 		protocolMaxVersion: pm.protocolMaxVersion,
+		allowFramed:        pm.config.PipeFramedProtobuf,
 	}
 
 	// Registrar pipe
 	pm.pipesMu.Lock()
+	if pm.ctx.Err() != nil {
+		// i8: Close en curso (registro desde el rendezvous)
+		pm.pipesMu.Unlock()
+		pipeServer.Close()
+		return pm.ctx.Err()
+	}
 	pm.pipes[name] = handler
 	pm.pipesMu.Unlock()
 
@@ -179,6 +298,26 @@ func (pm *PipeManager) createPipe(name, role, accountID string) error {
 	return nil
 }
 
+// newPipeServer crea el PipeServer del transporte de la cuenta (i8).
+//
+// Pipe: Named Pipe en Windows, Unix socket en el resto. TCP: listener por cuenta con
+// TLS opcional y token pre-compartido validado en el handshake.
+func (pm *PipeManager) newPipeServer(name string, transport *AccountTransport) (ipc.PipeServer, error) {
+	if transport.Kind == TransportTCP {
+		return ipc.NewTCPPipeServer(ipc.TCPServerConfig{
+			Name:             name,
+			Address:          transport.ListenAddr,
+			Token:            transport.Token,
+			TLSConfig:        pm.tlsConfig,
+			HandshakeTimeout: pm.config.TransportHandshakeTimeout,
+		})
+	}
+
+	pipeConfig := ipc.DefaultPipeConfig(name)
+	pipeConfig.SocketDir = pm.config.SocketDir
+	return ipc.NewPipeServerWithConfig(pipeConfig)
+}
+
 // GetPipe retorna un handler por nombre.
 //
 // Útil para enviar mensajes a un pipe específico (ej: ExecuteOrder a slave).
@@ -189,14 +328,46 @@ func (pm *PipeManager) GetPipe(name string) (*PipeHandler, bool) {
 	return handler, ok
 }
 
+// ReannounceAccounts reenvía AccountConnected de los EAs conectados (i8).
+//
+// Tras reconectar al Core el registry de ownership puede haberse limpiado.
+func (pm *PipeManager) ReannounceAccounts() {
+	pm.pipesMu.RLock()
+	handlers := make([]*PipeHandler, 0, len(pm.pipes))
+	for _, handler := range pm.pipes {
+		handlers = append(handlers, handler)
+	}
+	pm.pipesMu.RUnlock()
+
+	for _, handler := range handlers {
+		if handler.isConnected() && (handler.role == "slave" || handler.role == "master") {
+			handler.notifyAccountConnected()
+		}
+	}
+}
+
 // Close cierra todos los pipes.
 func (pm *PipeManager) Close() error {
 	pm.cancel()
 
+	// i8: Copiar bajo lock y cerrar fuera: el rendezvous puede estar registrando un pipe
 	pm.pipesMu.Lock()
-	defer pm.pipesMu.Unlock()
-
+	handlers := make(map[string]*PipeHandler, len(pm.pipes))
 	for name, handler := range pm.pipes {
+		handlers[name] = handler
+	}
+	rendezvous := pm.rendezvous
+	pm.pipesMu.Unlock()
+
+	if rendezvous != nil {
+		if err := rendezvous.Close(); err != nil {
+			pm.logError("Failed to close rendezvous pipe", err, map[string]interface{}{
+				"pipe_name": rendezvous.Name(),
+			})
+		}
+	}
+
+	for name, handler := range handlers {
 		if err := handler.Close(); err != nil {
 			pm.logError("Failed to close pipe", err, map[string]interface{}{
 				"pipe_name": name,
@@ -214,6 +385,12 @@ func (pm *PipeManager) logInfo(message string, fields map[string]interface{}) {
 	pm.telemetry.Info(pm.ctx, message, attrs...)
 }
 
+// logWarn loggea un mensaje WARN.
+func (pm *PipeManager) logWarn(message string, fields map[string]interface{}) {
+	attrs := mapToAttrs(fields)
+	pm.telemetry.Warn(pm.ctx, message, attrs...)
+}
+
 // logError loggea un mensaje ERROR.
 func (pm *PipeManager) logError(message string, err error, fields map[string]interface{}) {
 	attrs := mapToAttrs(fields)
@@ -231,6 +408,7 @@ type PipeHandler struct {
 
 	// Capacidades negociadas por handshake
 	supportsTickValue bool
+	framed            bool // i8: codec framed protobuf hacia el EA (protegido por mu)
 
 	// Telemetría
 	telemetry   *telemetry.Client
@@ -243,9 +421,10 @@ type PipeHandler struct {
 	agentID string
 
 	// Lifecycle
-	ctx    context.Context
-	closed bool
-	mu     sync.Mutex
+	ctx       context.Context
+	closed    bool
+	connected bool // i8: EA conectado (para re-anunciar la cuenta tras reconectar al Core)
+	mu        sync.Mutex
 
 	// Cache local para filtrar reportes repetidos
 	lastSpecReportMs int64
@@ -254,6 +433,7 @@ type PipeHandler struct {
 	protocolMinVersion int
 	protocolMaxVersion int
 	allowLegacy        bool
+	allowFramed        bool // i8: agent/pipe/framed_protobuf
 }
 
 // Run ejecuta el loop principal del handler.
@@ -320,6 +500,8 @@ func (h *PipeHandler) Run() error {
 			"role":      h.role,
 		})
 
+		h.setConnected(true)
+
 		// NEW i2: Notificar al Core que la cuenta se conectó
 		if h.role == "slave" || h.role == "master" {
 			h.notifyAccountConnected()
@@ -335,6 +517,8 @@ func (h *PipeHandler) Run() error {
 			"reason":    sessionErr,
 		})
 
+		h.setConnected(false)
+
 		// NEW i2: Notificar desconexión
 		if h.role == "slave" || h.role == "master" {
 			reason := "client_disconnected"
@@ -367,6 +551,14 @@ func (h *PipeHandler) Run() error {
 // - Error fatal de lectura
 // - Contexto cancelado
 func (h *PipeHandler) handleSession() error {
+	// i8: Cada conexión arranca en JSON hasta que el handshake negocie frames
+	h.resetCodec()
+
+	// Lector de toda la sesión (timeout de 1s para no bloquear indefinidamente).
+	// i8: Acepta líneas JSON y frames protobuf en el mismo stream.
+	reader := ipc.NewMessageReader(h.server)
+	reader.SetTimeout(1 * time.Second)
+
 	// Loop de lectura
 	for {
 		select {
@@ -375,23 +567,31 @@ func (h *PipeHandler) handleSession() error {
 		default:
 		}
 
-		// Leer una línea (timeout de 1s para no bloquear indefinidamente)
-		lr := ipc.NewLineReader(h.server)
-		lr.SetTimeout(1 * time.Second)
-		line, err := lr.ReadLine()
+		data, framed, err := reader.Next()
+
+		// i8: Frame protobuf, sin pasar por el map JSON
+		if err == nil && framed {
+			if err := h.handleFrame(data); err != nil {
+				h.logError("Failed to handle framed message", err, map[string]interface{}{
+					"pipe_name": h.name,
+					"role":      h.role,
+				})
+			}
+			continue
+		}
 
-		if err == nil && len(line) > 0 {
+		if err == nil && len(data) > 0 {
 			h.logDebug("Raw line received", map[string]interface{}{
 				"pipe_name": h.name,
-				"bytes":     len(line),
-				"preview":   preview(line, 160),
+				"bytes":     len(data),
+				"preview":   preview(data, 160),
 			})
 		}
 
 		// Parsear JSON
 		var msgMap map[string]interface{}
 		if err == nil {
-			msgMap, err = ipc.ParseJSONLine(line)
+			msgMap, err = ipc.ParseJSONLine(data)
 		}
 
 		if err != nil {
@@ -411,6 +611,15 @@ func (h *PipeHandler) handleSession() error {
 				continue
 			}
 
+			// i8: Stream desincronizado (frame o línea sobredimensionada): forzar reconexión
+			if errors.Is(err, ipc.ErrInvalidFrame) {
+				h.logError("Pipe stream out of sync, closing session", err, map[string]interface{}{
+					"pipe_name": h.name,
+					"role":      h.role,
+				})
+				return err
+			}
+
 			// Otros errores: loggear y continuar (pueden ser transitorios)
 			h.logError("Failed to read message", err, map[string]interface{}{
 				"pipe_name": h.name,
@@ -462,6 +671,9 @@ func (h *PipeHandler) handleMasterMessage(msgMap map[string]interface{}) error {
 	case "trade_close":
 		return h.handleTradeClose(msgMap)
 
+	case "trade_modify":
+		return h.handleTradeModify(msgMap)
+
 	default:
 		h.logWarn("Unknown message type from master", map[string]interface{}{
 			"type":      msgType,
@@ -482,6 +694,11 @@ func (h *PipeHandler) handleTradeIntent(msgMap map[string]interface{}) error {
 		return fmt.Errorf("failed to parse trade_intent: %w", err)
 	}
 
+	return h.forwardTradeIntent(protoIntent, t1)
+}
+
+// forwardTradeIntent registra t1 y envía un TradeIntent ya validado al Core.
+func (h *PipeHandler) forwardTradeIntent(protoIntent *pb.TradeIntent, t1 int64) error {
 	// Issue #M1: Popular timestamp t1 en el proto
 	if protoIntent.Timestamps != nil {
 		protoIntent.Timestamps.T1AgentRecvMs = t1
@@ -535,6 +752,11 @@ func (h *PipeHandler) handleTradeClose(msgMap map[string]interface{}) error {
 		return fmt.Errorf("failed to parse trade_close: %w", err)
 	}
 
+	return h.forwardTradeClose(protoClose)
+}
+
+// forwardTradeClose envía un TradeClose ya validado al Core.
+func (h *PipeHandler) forwardTradeClose(protoClose *pb.TradeClose) error {
 	h.logInfo("TradeClose received", map[string]interface{}{
 		"trade_id": protoClose.TradeId,
 		"ticket":   protoClose.Ticket,
@@ -559,6 +781,41 @@ func (h *PipeHandler) handleTradeClose(msgMap map[string]interface{}) error {
 	return nil
 }
 
+// handleTradeModify procesa un cambio de SL/TP del Master EA (i8).
+func (h *PipeHandler) handleTradeModify(msgMap map[string]interface{}) error {
+	protoModify, err := domain.JSONToTradeModify(msgMap)
+	if err != nil {
+		return fmt.Errorf("failed to parse trade_modify: %w", err)
+	}
+
+	return h.forwardTradeModify(protoModify)
+}
+
+// forwardTradeModify envía un TradeModify ya validado al Core (i8).
+func (h *PipeHandler) forwardTradeModify(protoModify *pb.TradeModify) error {
+	h.logInfo("TradeModify received (i8)", map[string]interface{}{
+		"trade_id": protoModify.TradeId,
+		"ticket":   protoModify.Ticket,
+	})
+
+	agentMsg := &pb.AgentMessage{
+		Payload: &pb.AgentMessage_TradeModify{
+			TradeModify: protoModify,
+		},
+	}
+
+	select {
+	case h.sendToCoreCh <- agentMsg:
+		h.logInfo("TradeModify forwarded to Core (i8)", map[string]interface{}{
+			"trade_id": protoModify.TradeId,
+		})
+	case <-h.ctx.Done():
+		return h.ctx.Err()
+	}
+
+	return nil
+}
+
 // handleSlaveMessage procesa mensajes del Slave EA.
 func (h *PipeHandler) handleSlaveMessage(msgMap map[string]interface{}) error {
 	msgType := utils.ExtractString(msgMap, "type")
@@ -602,6 +859,11 @@ func (h *PipeHandler) handleExecutionResult(msgMap map[string]interface{}) error
 		return fmt.Errorf("failed to parse execution_result: %w", err)
 	}
 
+	return h.forwardExecutionResult(protoResult)
+}
+
+// forwardExecutionResult envía un ExecutionResult ya validado al Core.
+func (h *PipeHandler) forwardExecutionResult(protoResult *pb.ExecutionResult) error {
 	h.logInfo("ExecutionResult received", map[string]interface{}{
 		"command_id": protoResult.CommandId,
 		"success":    protoResult.Success,
@@ -676,6 +938,11 @@ func (h *PipeHandler) handleStateSnapshot(msgMap map[string]interface{}) error {
 		return err
 	}
 
+	return h.forwardStateSnapshot(snapshot)
+}
+
+// forwardStateSnapshot normaliza el snapshot con los datos del handler y lo envía al Core.
+func (h *PipeHandler) forwardStateSnapshot(snapshot *pb.StateSnapshot) error {
 	if len(snapshot.Accounts) == 0 && h.accountID != "" {
 		// Algunos EAs no incluyen account_id dentro del payload; usar el del handler como fallback.
 		snapshot.Accounts = append(snapshot.Accounts, &pb.AccountInfo{
@@ -756,6 +1023,16 @@ func (h *PipeHandler) handleHandshake(msgMap map[string]interface{}) error {
 		return nil
 	}
 
+	// i8: Codec del pipe; sin la feature (o deshabilitado) se mantiene JSON
+	if h.allowFramed && normalized.Capabilities.Supports(ipc.FeatureFramedProtobuf) {
+		if err := h.enableFramedCodec(); err != nil {
+			h.logError("Failed to enable framed protobuf codec, keeping JSON", err, map[string]interface{}{
+				"account_id": accountID,
+				"pipe_role":  pipeRole,
+			})
+		}
+	}
+
 	h.supportsTickValue = normalized.Capabilities.Supports("spec_report/tickvalue")
 	if !h.supportsTickValue {
 		h.logWarn("Handshake missing spec_report/tickvalue capability", map[string]interface{}{
@@ -803,6 +1080,7 @@ func (h *PipeHandler) handleHandshake(msgMap map[string]interface{}) error {
 		"symbols_count":       len(normalized.Symbols),
 		"required_features":   strings.Join(normalized.RequiredFeatures, ","),
 		"supports_tick_value": h.supportsTickValue,
+		"codec":               h.codecName(),
 	})
 
 	agentMsg := &pb.AgentMessage{
@@ -842,22 +1120,8 @@ func (h *PipeHandler) handleSymbolSpecReport(msgMap map[string]interface{}) erro
 	if reportedAt <= 0 {
 		reportedAt = nowUnixMilli()
 	}
-	nowMs := nowUnixMilli()
-	specAgeMs := nowMs - reportedAt
-	if specAgeMs < 0 {
-		specAgeMs = 0
-	}
 
-	if reportedAt <= h.lastSpecReportMs {
-		h.logWarn("SymbolSpecReport ignored due to stale reported_at", map[string]interface{}{
-			"account_id":          accountID,
-			"reported_at_ms":      reportedAt,
-			"last_reported_at_ms": h.lastSpecReportMs,
-		})
-		h.echoMetrics.RecordAgentSpecsFiltered(h.ctx, accountID, "stale_report",
-			attribute.Int64("reported_at_ms", reportedAt),
-			attribute.Int64("last_reported_at_ms", h.lastSpecReportMs),
-		)
+	if h.isStaleSpecReport(accountID, reportedAt) {
 		return nil
 	}
 
@@ -872,7 +1136,7 @@ func (h *PipeHandler) handleSymbolSpecReport(msgMap map[string]interface{}) erro
 		return nil
 	}
 
-	validSpecs := make([]*pb.SymbolSpecification, 0, len(symbolsRaw))
+	specs := make([]*pb.SymbolSpecification, 0, len(symbolsRaw))
 	for _, raw := range symbolsRaw {
 		symMap, ok := raw.(map[string]interface{})
 		if !ok {
@@ -906,6 +1170,44 @@ func (h *PipeHandler) handleSymbolSpecReport(msgMap map[string]interface{}) erro
 			spec.Sessions = parseSessionWindows(sessionsRaw)
 		}
 
+		specs = append(specs, spec)
+	}
+
+	return h.forwardSymbolSpecReport(accountID, reportedAt, specs)
+}
+
+// isStaleSpecReport descarta reportes no más nuevos que el último reenviado.
+func (h *PipeHandler) isStaleSpecReport(accountID string, reportedAt int64) bool {
+	if reportedAt > h.lastSpecReportMs {
+		return false
+	}
+
+	h.logWarn("SymbolSpecReport ignored due to stale reported_at", map[string]interface{}{
+		"account_id":          accountID,
+		"reported_at_ms":      reportedAt,
+		"last_reported_at_ms": h.lastSpecReportMs,
+	})
+	h.echoMetrics.RecordAgentSpecsFiltered(h.ctx, accountID, "stale_report",
+		attribute.Int64("reported_at_ms", reportedAt),
+		attribute.Int64("last_reported_at_ms", h.lastSpecReportMs),
+	)
+	return true
+}
+
+// forwardSymbolSpecReport valida las especificaciones y envía las válidas al Core.
+func (h *PipeHandler) forwardSymbolSpecReport(accountID string, reportedAt int64, specs []*pb.SymbolSpecification) error {
+	nowMs := nowUnixMilli()
+	specAgeMs := nowMs - reportedAt
+	if specAgeMs < 0 {
+		specAgeMs = 0
+	}
+
+	validSpecs := make([]*pb.SymbolSpecification, 0, len(specs))
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+
 		if h.supportsTickValue {
 			if spec.General == nil || spec.General.TickValue <= 0 {
 				h.logWarn("Symbol specification missing tick_value", map[string]interface{}{
@@ -987,26 +1289,29 @@ func (h *PipeHandler) handleQuoteSnapshot(msgMap map[string]interface{}) error {
 		return fmt.Errorf("quote_snapshot missing payload")
 	}
 
-	accountID := utils.ExtractString(payload, "account_id")
-	if accountID == "" {
-		accountID = h.accountID
-	}
-
-	timestamp := utils.ExtractInt64(payload, "timestamp_ms")
-	if timestamp <= 0 {
-		timestamp = nowUnixMilli()
-	}
-
 	snapshot := &pb.SymbolQuoteSnapshot{
-		AccountId:       accountID,
+		AccountId:       utils.ExtractString(payload, "account_id"),
 		CanonicalSymbol: utils.ExtractString(payload, "canonical_symbol"),
 		BrokerSymbol:    utils.ExtractString(payload, "broker_symbol"),
 		Bid:             utils.ExtractFloat64(payload, "bid"),
 		Ask:             utils.ExtractFloat64(payload, "ask"),
 		SpreadPoints:    utils.ExtractFloat64(payload, "spread_points"),
-		TimestampMs:     timestamp,
+		TimestampMs:     utils.ExtractInt64(payload, "timestamp_ms"),
 	}
 
+	return h.forwardQuoteSnapshot(snapshot)
+}
+
+// forwardQuoteSnapshot completa cuenta y timestamp del snapshot y lo envía al Core.
+func (h *PipeHandler) forwardQuoteSnapshot(snapshot *pb.SymbolQuoteSnapshot) error {
+	if snapshot.AccountId == "" {
+		snapshot.AccountId = h.accountID
+	}
+	if snapshot.TimestampMs <= 0 {
+		snapshot.TimestampMs = nowUnixMilli()
+	}
+	accountID := snapshot.AccountId
+
 	agentMsg := &pb.AgentMessage{
 		AgentId:     h.agentID,
 		TimestampMs: nowUnixMilli(),
@@ -1262,6 +1567,11 @@ func (h *PipeHandler) handlePing(msgMap map[string]interface{}) error {
 		return nil
 	}
 
+	// i8: Con codec framed el pong viaja como frame
+	if h.isFramed() {
+		return h.writeFramedPong(pingID, echoMs)
+	}
+
 	// Construir pong response
 	pongMsg := map[string]interface{}{
 		"type":         "pong",
@@ -1308,6 +1618,11 @@ func (h *PipeHandler) WriteMessage(msg interface{}) error {
 		return fmt.Errorf("pipe is closed")
 	}
 
+	// i8: Codec framed negociado en el handshake: sin pasar por el map JSON
+	if h.framed {
+		return h.writeOrderFrameLocked(msg)
+	}
+
 	// Crear JSONWriter usando SDK
 	writer := ipc.NewJSONWriter(h.server)
 
@@ -1349,6 +1664,15 @@ func (h *PipeHandler) WriteSymbolRegistrationResult(result *pb.SymbolRegistratio
 		return fmt.Errorf("pipe is closed")
 	}
 
+	if h.framed {
+		return h.writeFrameLocked(&pb.PipeMessage{
+			TimestampMs: utils.NowUnixMilli(),
+			Payload: &pb.PipeMessage_SymbolRegistrationResult{
+				SymbolRegistrationResult: result,
+			},
+		})
+	}
+
 	writer := ipc.NewJSONWriter(h.server)
 	marshaler := protojson.MarshalOptions{EmitUnpopulated: true}
 	data, err := marshaler.Marshal(result)
@@ -1432,6 +1756,18 @@ func (h *PipeHandler) logDebug(message string, fields map[string]interface{}) {
 	h.telemetry.Debug(h.ctx, message, attrs...)
 }
 
+func (h *PipeHandler) setConnected(connected bool) {
+	h.mu.Lock()
+	h.connected = connected
+	h.mu.Unlock()
+}
+
+func (h *PipeHandler) isConnected() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.connected
+}
+
 // NEW i2: notifyAccountConnected notifica al Core que una cuenta se conectó.
 func (h *PipeHandler) notifyAccountConnected() {
 	if h.agentID == "" || h.accountID == "" {
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/ipc"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"google.golang.org/protobuf/proto"
+)
+
+const testTradeID = "01890a5d-ac96-774b-bcce-b302099a8057"
+
+// newTestMasterPipeHandler arma el handler del pipe master de la cuenta 1001 sin
+// servidor: los mensajes reenviados al Core quedan en el canal retornado.
+func newTestMasterPipeHandler() (*PipeHandler, chan *pb.AgentMessage) {
+	sendToCoreCh := make(chan *pb.AgentMessage, 4)
+	return &PipeHandler{
+		name:         "echo_master_1001",
+		role:         "master",
+		accountID:    "1001",
+		telemetry:    &telemetry.Client{},
+		echoMetrics:  &metricbundle.EchoMetrics{},
+		sendToCoreCh: sendToCoreCh,
+		ctx:          context.Background(),
+	}, sendToCoreCh
+}
+
+// handleMasterLine procesa una línea JSON del pipe como lo hace handleSession.
+func handleMasterLine(t *testing.T, h *PipeHandler, line string) error {
+	t.Helper()
+
+	msgMap, err := ipc.ParseJSONLine([]byte(line))
+	require.NoError(t, err)
+	return h.handleMasterMessage(msgMap)
+}
+
+func TestMasterTradeModifyForwardedToCore(t *testing.T) {
+	h, sendToCoreCh := newTestMasterPipeHandler()
+
+	// Solo cambió el SL: el TP no viaja
+	err := handleMasterLine(t, h, `{"type":"trade_modify","timestamp_ms":1700000000000,"payload":{"trade_id":"`+testTradeID+`","new_stop_loss":1995.5,"ticket":42}}`)
+	require.NoError(t, err)
+	require.Len(t, sendToCoreCh, 1)
+
+	modify := (<-sendToCoreCh).GetTradeModify()
+	require.NotNil(t, modify)
+	assert.Equal(t, testTradeID, modify.TradeId)
+	assert.Equal(t, int32(42), modify.Ticket)
+	assert.Equal(t, int64(1700000000000), modify.TimestampMs)
+	require.NotNil(t, modify.NewStopLoss)
+	assert.Equal(t, 1995.5, *modify.NewStopLoss)
+	assert.Nil(t, modify.NewTakeProfit)
+
+	// TP removido (0) es un cambio válido
+	err = handleMasterLine(t, h, `{"type":"trade_modify","timestamp_ms":1700000000001,"payload":{"trade_id":"`+testTradeID+`","new_take_profit":0,"ticket":42}}`)
+	require.NoError(t, err)
+	modify = (<-sendToCoreCh).GetTradeModify()
+	require.NotNil(t, modify)
+	require.NotNil(t, modify.NewTakeProfit)
+	assert.Zero(t, *modify.NewTakeProfit)
+
+	// Sin niveles no se reenvía
+	err = handleMasterLine(t, h, `{"type":"trade_modify","timestamp_ms":1700000000002,"payload":{"trade_id":"`+testTradeID+`","ticket":42}}`)
+	assert.Error(t, err)
+	assert.Empty(t, sendToCoreCh)
+}
+
+func TestMasterFramedTradeModifyForwardedToCore(t *testing.T) {
+	h, sendToCoreCh := newTestMasterPipeHandler()
+
+	takeProfit := 2050.0
+	data, err := proto.Marshal(&pb.PipeMessage{
+		TimestampMs: 1700000000000,
+		Payload: &pb.PipeMessage_TradeModify{TradeModify: &pb.TradeModify{
+			TradeId:       testTradeID,
+			Ticket:        42,
+			NewTakeProfit: &takeProfit,
+		}},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, h.handleFrame(data))
+	require.Len(t, sendToCoreCh, 1)
+
+	modify := (<-sendToCoreCh).GetTradeModify()
+	require.NotNil(t, modify)
+	assert.Equal(t, int64(1700000000000), modify.TimestampMs, "timestamp del frame si el payload no lo trae")
+	assert.Equal(t, takeProfit, modify.GetNewTakeProfit())
+
+	// Un slave no reporta modifies
+	h.role = "slave"
+	require.NoError(t, h.handleFrame(data))
+	assert.Empty(t, sendToCoreCh)
+}
+
+func TestMasterPartialTradeCloseForwardsVolumes(t *testing.T) {
+	h, sendToCoreCh := newTestMasterPipeHandler()
+
+	err := handleMasterLine(t, h, `{"type":"trade_close","timestamp_ms":1700000000000,"payload":{"trade_id":"`+testTradeID+`","client_id":"master_1001","account_id":"1001","ticket":42,"symbol":"XAUUSD","magic_number":7,"close_price":2001.5,"closed_volume":0.40,"remaining_volume":0.60}}`)
+	require.NoError(t, err)
+	require.Len(t, sendToCoreCh, 1)
+
+	tradeClose := (<-sendToCoreCh).GetTradeClose()
+	require.NotNil(t, tradeClose)
+	require.NotNil(t, tradeClose.ClosedVolume)
+	require.NotNil(t, tradeClose.RemainingVolume)
+	assert.Equal(t, 0.4, *tradeClose.ClosedVolume)
+	assert.Equal(t, 0.6, *tradeClose.RemainingVolume)
+
+	// EAs previos no envían volúmenes: cierre total
+	err = handleMasterLine(t, h, `{"type":"trade_close","timestamp_ms":1700000000000,"payload":{"trade_id":"`+testTradeID+`","ticket":42,"symbol":"XAUUSD","magic_number":7,"close_price":2001.5}}`)
+	require.NoError(t, err)
+	tradeClose = (<-sendToCoreCh).GetTradeClose()
+	require.NotNil(t, tradeClose)
+	assert.Nil(t, tradeClose.ClosedVolume)
+	assert.Nil(t, tradeClose.RemainingVolume)
+}
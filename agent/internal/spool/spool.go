@@ -0,0 +1,485 @@
+// Package spool implementa el store-and-forward del Agent hacia el Core (i8).
+//
+// Los AgentMessages salientes se persisten antes de enviarse: si el stream gRPC está
+// caído se acumulan en disco y se drenan en orden al reconectar, sobreviviendo a un
+// reinicio del Agent.
+//
+// Formato en disco:
+//   - Segmentos "spool-<index inicial, 20 dígitos>.log" con registros length-delimited
+//     (varint + pb.OutboundRecord), en orden de index.
+//   - Archivo "cursor" con el último index confirmado (Commit, tras el ack del Core);
+//     se reemplaza de forma atómica (tmp + rename). Los segmentos totalmente
+//     confirmados se eliminan.
+//
+// La cola es acotada por MaxMessages y MaxBytes: al superarlos se descartan los
+// mensajes más antiguos. Un registro truncado al final (crash) se descarta al abrir.
+package spool
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	segmentPrefix = "spool-"
+	segmentSuffix = ".log"
+	cursorFile    = "cursor"
+
+	// maxRecordBytes limita el tamaño de un registro al leer (protección ante corrupción).
+	maxRecordBytes = 16 << 20
+
+	// DefaultSegmentMaxBytes tamaño de rotación por defecto (4 MiB).
+	DefaultSegmentMaxBytes int64 = 4 << 20
+
+	// DefaultMaxMessages mensajes pendientes retenidos por defecto.
+	DefaultMaxMessages = 10000
+
+	// DefaultMaxBytes tamaño pendiente retenido por defecto (64 MiB).
+	DefaultMaxBytes int64 = 64 << 20
+)
+
+// Options configura la Queue.
+type Options struct {
+	Dir             string
+	SegmentMaxBytes int64 // <= 0 usa DefaultSegmentMaxBytes
+	MaxMessages     int   // <= 0 usa DefaultMaxMessages
+	MaxBytes        int64 // <= 0 usa DefaultMaxBytes
+
+	// Fsync fuerza fsync por registro y por commit (sobrevive a un crash del host).
+	// Sin Fsync cada registro se escribe al SO: sobrevive a un crash del proceso.
+	Fsync bool
+
+	// OnError recibe errores no fatales (segmentos corruptos al abrir, limpieza) (opcional).
+	OnError func(err error)
+}
+
+// Entry mensaje pendiente de envío.
+type Entry struct {
+	Index     uint64
+	ArrivalMs int64
+	Message   *pb.AgentMessage
+
+	size int64
+}
+
+// Queue cola FIFO persistente de AgentMessages. Es segura para uso concurrente.
+type Queue struct {
+	opts Options
+
+	mu        sync.Mutex
+	entries   []*Entry // pendientes en orden de index
+	bytes     int64
+	lastIndex uint64
+	committed uint64
+	segments  []segment // en orden de index; el último puede ser el activo
+	file      *os.File
+	buf       *bufio.Writer
+	size      int64
+	closed    bool
+	now       func() time.Time
+}
+
+type segment struct {
+	path       string
+	firstIndex uint64
+	lastIndex  uint64
+}
+
+// Open abre (o crea) la cola en opts.Dir y recupera los mensajes sin confirmar.
+func Open(opts Options) (*Queue, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("spool dir is required")
+	}
+	if opts.SegmentMaxBytes <= 0 {
+		opts.SegmentMaxBytes = DefaultSegmentMaxBytes
+	}
+	if opts.MaxMessages <= 0 {
+		opts.MaxMessages = DefaultMaxMessages
+	}
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create spool dir: %w", err)
+	}
+
+	q := &Queue{opts: opts, now: time.Now}
+
+	committed, err := readCursor(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	q.committed = committed
+	q.lastIndex = committed
+
+	paths, err := listSegments(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, seg := range paths {
+		if err := q.load(seg); err != nil {
+			return nil, err
+		}
+	}
+
+	// Los mensajes descartados por bounds en una ejecución anterior siguen en disco
+	q.enforceBounds()
+	q.removeConsumed()
+
+	return q, nil
+}
+
+// load lee un segmento y agrega sus registros sin confirmar.
+func (q *Queue) load(seg segment) error {
+	file, err := os.Open(seg.path)
+	if err != nil {
+		return fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReaderSize(file, 64<<10)
+	records := 0
+	for {
+		record := &pb.OutboundRecord{}
+		err := protodelim.UnmarshalOptions{MaxSize: maxRecordBytes}.UnmarshalFrom(reader, record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Registro truncado (crash durante la escritura) o corrupto: los previos son válidos
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				q.reportError(fmt.Errorf("failed to read spool record in %s: %w", filepath.Base(seg.path), err))
+			}
+			break
+		}
+
+		records++
+		seg.lastIndex = record.Index
+		if record.Index > q.lastIndex {
+			q.lastIndex = record.Index
+		}
+		if record.Index <= q.committed || record.Message == nil {
+			continue
+		}
+		entry := &Entry{
+			Index:     record.Index,
+			ArrivalMs: record.ArrivalMs,
+			Message:   record.Message,
+			size:      int64(proto.Size(record)),
+		}
+		q.entries = append(q.entries, entry)
+		q.bytes += entry.size
+	}
+
+	if records == 0 {
+		// Segmento sin registros válidos: se descarta para no reutilizar su nombre
+		file.Close()
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove empty spool segment: %w", err)
+		}
+		return nil
+	}
+
+	q.segments = append(q.segments, seg)
+	return nil
+}
+
+// Enqueue persiste msg al final de la cola.
+//
+// Retorna la entrada creada y la cantidad de mensajes antiguos descartados por
+// superar MaxMessages o MaxBytes.
+func (q *Queue) Enqueue(msg *pb.AgentMessage) (*Entry, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, 0, fmt.Errorf("spool is closed")
+	}
+
+	record := &pb.OutboundRecord{
+		Index:     q.lastIndex + 1,
+		ArrivalMs: q.now().UnixMilli(),
+		Message:   msg,
+	}
+
+	if q.file == nil {
+		if err := q.openSegment(record.Index); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	n, err := protodelim.MarshalTo(q.buf, record)
+	if err == nil {
+		err = q.buf.Flush()
+	}
+	if err == nil && q.opts.Fsync {
+		err = q.file.Sync()
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to append spool record %d: %w", record.Index, err)
+	}
+
+	q.lastIndex = record.Index
+	q.size += int64(n)
+	q.segments[len(q.segments)-1].lastIndex = record.Index
+
+	entry := &Entry{
+		Index:     record.Index,
+		ArrivalMs: record.ArrivalMs,
+		Message:   msg,
+		size:      int64(n),
+	}
+	q.entries = append(q.entries, entry)
+	q.bytes += entry.size
+
+	evicted := q.enforceBounds()
+
+	if q.size >= q.opts.SegmentMaxBytes {
+		if err := q.closeSegment(); err != nil {
+			q.reportError(err)
+		}
+	}
+	if evicted > 0 {
+		q.removeConsumed()
+	}
+
+	return entry, evicted, nil
+}
+
+// Peek retorna el mensaje pendiente más antiguo, o nil si la cola está vacía.
+func (q *Queue) Peek() *Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.entries) == 0 {
+		return nil
+	}
+	return q.entries[0]
+}
+
+// Next retorna el mensaje pendiente más antiguo con index > after, o nil si no hay.
+//
+// Permite seguir enviando mientras los anteriores esperan confirmación (Commit).
+func (q *Queue) Next(after uint64) *Entry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	i := sort.Search(len(q.entries), func(i int) bool { return q.entries[i].Index > after })
+	if i == len(q.entries) {
+		return nil
+	}
+	return q.entries[i]
+}
+
+// Commit confirma los mensajes con index <= index (con ack del Core).
+func (q *Queue) Commit(index uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if index <= q.committed {
+		return nil
+	}
+	q.release(index)
+
+	if err := q.writeCursor(); err != nil {
+		return err
+	}
+	q.removeConsumed()
+	return nil
+}
+
+// Stats retorna mensajes y bytes pendientes.
+func (q *Queue) Stats() (pending int, bytes int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries), q.bytes
+}
+
+// Close cierra el segmento activo. Los mensajes pendientes quedan en disco.
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	return q.closeSegment()
+}
+
+// release descarta de memoria los mensajes con index <= index. Debe llamarse con mu tomado.
+func (q *Queue) release(index uint64) {
+	released := 0
+	for released < len(q.entries) && q.entries[released].Index <= index {
+		q.bytes -= q.entries[released].size
+		released++
+	}
+	q.entries = q.entries[released:]
+	q.committed = index
+}
+
+// enforceBounds descarta los mensajes más antiguos mientras se superen los límites.
+//
+// El último mensaje nunca se descarta. Debe llamarse con mu tomado.
+func (q *Queue) enforceBounds() int {
+	evicted := 0
+	for len(q.entries) > 1 && (len(q.entries) > q.opts.MaxMessages || q.bytes > q.opts.MaxBytes) {
+		q.bytes -= q.entries[0].size
+		q.committed = q.entries[0].Index
+		q.entries = q.entries[1:]
+		evicted++
+	}
+	if evicted > 0 {
+		if err := q.writeCursor(); err != nil {
+			q.reportError(err)
+		}
+	}
+	return evicted
+}
+
+// ==========================================================================
+// Segmentos y cursor
+// ==========================================================================
+
+func (q *Queue) openSegment(firstIndex uint64) error {
+	path := filepath.Join(q.opts.Dir, segmentName(firstIndex))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open spool segment: %w", err)
+	}
+	q.file = file
+	q.buf = bufio.NewWriterSize(file, 64<<10)
+	q.size = 0
+	q.segments = append(q.segments, segment{path: path, firstIndex: firstIndex, lastIndex: firstIndex - 1})
+	return nil
+}
+
+// closeSegment cierra el segmento activo; el siguiente Enqueue abre uno nuevo.
+func (q *Queue) closeSegment() error {
+	if q.file == nil {
+		return nil
+	}
+	err := q.buf.Flush()
+	if syncErr := q.file.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := q.file.Close(); err == nil {
+		err = closeErr
+	}
+	q.file = nil
+	q.buf = nil
+	q.size = 0
+	if err != nil {
+		return fmt.Errorf("failed to close spool segment: %w", err)
+	}
+	return nil
+}
+
+// removeConsumed elimina los segmentos cerrados sin mensajes pendientes. Debe llamarse con mu tomado.
+func (q *Queue) removeConsumed() {
+	for len(q.segments) > 0 {
+		seg := q.segments[0]
+		active := q.file != nil && len(q.segments) == 1
+		if active || seg.lastIndex > q.committed {
+			return
+		}
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			q.reportError(fmt.Errorf("failed to remove spool segment: %w", err))
+			return
+		}
+		q.segments = q.segments[1:]
+	}
+}
+
+func (q *Queue) writeCursor() error {
+	path := filepath.Join(q.opts.Dir, cursorFile)
+	tmpPath := path + ".tmp"
+
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create spool cursor: %w", err)
+	}
+	_, err = file.WriteString(strconv.FormatUint(q.committed, 10))
+	if err == nil && q.opts.Fsync {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write spool cursor: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to finalize spool cursor: %w", err)
+	}
+	return nil
+}
+
+// reportError debe llamarse con mu tomado.
+func (q *Queue) reportError(err error) {
+	if q.opts.OnError != nil {
+		q.opts.OnError(err)
+	}
+}
+
+func readCursor(dir string) (uint64, error) {
+	data, err := os.ReadFile(filepath.Join(dir, cursorFile))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read spool cursor: %w", err)
+	}
+	committed, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid spool cursor: %w", err)
+	}
+	return committed, nil
+}
+
+func listSegments(dir string) ([]segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spool dir: %w", err)
+	}
+
+	segments := make([]segment, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		digits := strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix)
+		firstIndex, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment{path: filepath.Join(dir, name), firstIndex: firstIndex})
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].firstIndex < segments[j].firstIndex })
+	return segments, nil
+}
+
+func segmentName(firstIndex uint64) string {
+	return fmt.Sprintf("%s%020d%s", segmentPrefix, firstIndex, segmentSuffix)
+}
@@ -0,0 +1,168 @@
+package spool
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+func enqueueN(t *testing.T, q *Queue, n int) []*Entry {
+	t.Helper()
+	entries := make([]*Entry, 0, n)
+	for i := 0; i < n; i++ {
+		entry, _, err := q.Enqueue(&pb.AgentMessage{AgentId: "agent-1", TimestampMs: int64(i + 1)})
+		require.NoError(t, err)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func pendingIndexes(q *Queue) []uint64 {
+	var indexes []uint64
+	for entry := q.Next(0); entry != nil; entry = q.Next(entry.Index) {
+		indexes = append(indexes, entry.Index)
+	}
+	return indexes
+}
+
+func segmentFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, segmentPrefix+"*"+segmentSuffix))
+	require.NoError(t, err)
+	return files
+}
+
+func TestQueueReopenDiscardsTruncatedRecord(t *testing.T) {
+	dir := t.TempDir()
+
+	q, err := Open(Options{Dir: dir})
+	require.NoError(t, err)
+	entries := enqueueN(t, q, 3)
+	require.NoError(t, q.Close())
+
+	// Crash a mitad de escribir el último registro
+	files := segmentFiles(t, dir)
+	require.Len(t, files, 1)
+	info, err := os.Stat(files[0])
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(files[0], info.Size()-entries[2].size/2))
+
+	q, err = Open(Options{Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, pendingIndexes(q))
+	assert.Equal(t, int64(2), q.Next(1).Message.TimestampMs)
+
+	// El siguiente index continúa tras el último registro válido, en un segmento nuevo
+	entry, _, err := q.Enqueue(&pb.AgentMessage{AgentId: "agent-1", TimestampMs: 4})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), entry.Index)
+	require.NoError(t, q.Close())
+
+	q, err = Open(Options{Dir: dir})
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Equal(t, []uint64{1, 2, 3}, pendingIndexes(q))
+	assert.Equal(t, int64(4), q.Next(2).Message.TimestampMs)
+}
+
+func TestQueueCommitThenReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	// Un registro por segmento para verificar la limpieza de segmentos confirmados
+	q, err := Open(Options{Dir: dir, SegmentMaxBytes: 1})
+	require.NoError(t, err)
+	enqueueN(t, q, 3)
+	require.Len(t, segmentFiles(t, dir), 3)
+
+	// Next no consume: lo enviado sigue pendiente hasta el Commit
+	assert.Equal(t, uint64(2), q.Next(1).Index)
+	assert.Nil(t, q.Next(3))
+	pending, _ := q.Stats()
+	assert.Equal(t, 3, pending)
+
+	require.NoError(t, q.Commit(2))
+	require.NoError(t, q.Commit(1), "commit repetido o atrasado no retrocede el cursor")
+	assert.Len(t, segmentFiles(t, dir), 1)
+	require.NoError(t, q.Close())
+
+	q, err = Open(Options{Dir: dir, SegmentMaxBytes: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{3}, pendingIndexes(q))
+
+	entry, _, err := q.Enqueue(&pb.AgentMessage{AgentId: "agent-1"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), entry.Index)
+
+	require.NoError(t, q.Commit(4))
+	pending, bytes := q.Stats()
+	assert.Equal(t, 0, pending)
+	assert.Equal(t, int64(0), bytes)
+	require.NoError(t, q.Close())
+
+	q, err = Open(Options{Dir: dir})
+	require.NoError(t, err)
+	defer q.Close()
+	assert.Nil(t, q.Next(0))
+	assert.Empty(t, segmentFiles(t, dir))
+}
+
+func TestQueueEvictionPersistsAcrossReopen(t *testing.T) {
+	t.Run("max_messages", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := Open(Options{Dir: dir, MaxMessages: 2})
+		require.NoError(t, err)
+		enqueueN(t, q, 2)
+		_, evicted, err := q.Enqueue(&pb.AgentMessage{AgentId: "agent-1", TimestampMs: 3})
+		require.NoError(t, err)
+		assert.Equal(t, 1, evicted)
+		assert.Equal(t, []uint64{2, 3}, pendingIndexes(q))
+		require.NoError(t, q.Close())
+
+		// Con límites mayores lo descartado no reaparece
+		q, err = Open(Options{Dir: dir})
+		require.NoError(t, err)
+		defer q.Close()
+		assert.Equal(t, []uint64{2, 3}, pendingIndexes(q))
+	})
+
+	t.Run("max_bytes", func(t *testing.T) {
+		dir := t.TempDir()
+
+		// Cualquier mensaje supera el límite: solo se retiene el último
+		q, err := Open(Options{Dir: dir, MaxBytes: 1})
+		require.NoError(t, err)
+		enqueueN(t, q, 3)
+		assert.Equal(t, []uint64{3}, pendingIndexes(q))
+		require.NoError(t, q.Close())
+
+		q, err = Open(Options{Dir: dir})
+		require.NoError(t, err)
+		defer q.Close()
+		assert.Equal(t, []uint64{3}, pendingIndexes(q))
+		assert.Equal(t, int64(3), q.Next(0).Message.TimestampMs)
+	})
+
+	t.Run("bounds reducidos al reabrir", func(t *testing.T) {
+		dir := t.TempDir()
+
+		q, err := Open(Options{Dir: dir})
+		require.NoError(t, err)
+		enqueueN(t, q, 4)
+		require.NoError(t, q.Close())
+
+		q, err = Open(Options{Dir: dir, MaxMessages: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []uint64{4}, pendingIndexes(q))
+		require.NoError(t, q.Close())
+
+		q, err = Open(Options{Dir: dir})
+		require.NoError(t, err)
+		defer q.Close()
+		assert.Equal(t, []uint64{4}, pendingIndexes(q))
+	})
+}
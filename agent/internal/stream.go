@@ -1,10 +1,12 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/xKoRx/echo/sdk/domain/handshake"
 	pb "github.com/xKoRx/echo/sdk/pb/v1"
@@ -30,15 +32,38 @@ func (a *Agent) connectToCore() error {
 
 	a.coreClient = client
 
+	if err := a.openStream(); err != nil {
+		return err
+	}
+
+	a.logInfo("Connected to Core (i2)", nil)
+	return nil
+}
+
+// openStream abre el stream bidi y ejecuta el resume handshake (i8).
+//
+// Retiene streamMu durante todo el handshake: sendToCore espera a que termine el
+// replay, de modo que el Core recibe los seq en orden.
+func (a *Agent) openStream() error {
+	a.streamMu.Lock()
+	defer a.streamMu.Unlock()
+
 	// i1: Crear stream bidireccional con agent_id desde config
-	stream, err := client.StreamBidi(a.ctx, a.config.AgentID)
+	streamCtx, streamCancel := context.WithCancel(a.ctx)
+	stream, err := a.coreClient.StreamBidi(streamCtx, a.config.AgentID)
 	if err != nil {
+		streamCancel()
 		return fmt.Errorf("failed to create stream: %w", err)
 	}
 
+	if a.streamCancel != nil {
+		a.streamCancel() // Liberar el stream anterior
+	}
 	a.coreStream = stream
+	a.streamCancel = streamCancel
 	a.logInfo("Stream connected to Core (i1)", map[string]interface{}{
-		"agent_id": a.config.AgentID,
+		"agent_id":   a.config.AgentID,
+		"session_id": a.sessionID,
 	})
 
 	// NEW i2: Enviar AgentHello SOLO con metadata (sin owned_accounts)
@@ -46,18 +71,172 @@ func (a *Agent) connectToCore() error {
 		return fmt.Errorf("failed to send AgentHello: %w", err)
 	}
 
-	a.logInfo("Connected to Core (i2)", nil)
+	// i8: El Core responde al AgentHello con StreamResumed antes de cualquier otro mensaje
+	timer := time.AfterFunc(a.config.ResumeTimeout, streamCancel)
+	reply := &pb.CoreMessage{}
+	err = stream.RecvMsg(reply)
+	timer.Stop()
+	if err != nil {
+		return fmt.Errorf("failed to receive StreamResumed: %w", err)
+	}
+	resumed := reply.GetStreamResumed()
+	if resumed == nil {
+		return fmt.Errorf("unexpected first message from Core: %T", reply.Payload)
+	}
+
+	if err := a.applyStreamResumed(resumed); err != nil {
+		return err
+	}
+
+	// Habilitar el drenado del store-and-forward
+	a.markStreamUp()
+	return nil
+}
+
+// applyStreamResumed reenvía los AgentMessages sin ack según la respuesta del Core (i8).
+//
+// Debe llamarse con streamMu tomado.
+func (a *Agent) applyStreamResumed(resumed *pb.StreamResumed) error {
+	if resumed.Resumed {
+		released := a.ackOutbound(resumed.AckSeq)
+		replay := a.session.Unacked()
+		for _, msg := range replay {
+			if err := a.coreStream.Send(msg); err != nil {
+				return fmt.Errorf("failed to replay seq %d: %w", msg.Seq, err)
+			}
+		}
+		a.logInfo("Stream session resumed (i8)", map[string]interface{}{
+			"session_id": a.sessionID,
+			"ack_seq":    resumed.AckSeq,
+			"released":   released,
+			"replayed":   len(replay),
+		})
+		return nil
+	}
+
+	// Sesión nueva (primer stream o Core reiniciado): reenviar lo pendiente con seq nuevos
+	pending := a.session.Restart()
+	for _, msg := range pending {
+		if err := a.sendSequencedLocked(msg); err != nil {
+			return fmt.Errorf("failed to resend pending message: %w", err)
+		}
+	}
+	a.logInfo("Stream session started (i8)", map[string]interface{}{
+		"session_id": a.sessionID,
+		"resent":     len(pending),
+	})
 	return nil
 }
 
+// sendSequenced asigna seq a msg, lo retiene para replay y lo envía (i8).
+func (a *Agent) sendSequenced(msg *pb.AgentMessage) error {
+	a.streamMu.Lock()
+	defer a.streamMu.Unlock()
+	return a.sendSequencedLocked(msg)
+}
+
+// Retorna grpcSDK.ErrReplayBufferFull sin enviar si hay demasiados mensajes sin ack.
+func (a *Agent) sendSequencedLocked(msg *pb.AgentMessage) error {
+	seq, err := a.session.Track(msg, setAgentMessageSeq)
+	if err != nil {
+		return err
+	}
+	a.inFlight.assign(msg, seq)
+	return a.coreStream.Send(msg)
+}
+
+// setAgentMessageSeq escribe el seq asignado por la sesión de entrega confiable (i8).
+func setAgentMessageSeq(msg *pb.AgentMessage, seq uint64) {
+	msg.Seq = seq
+}
+
+// restartStreamSession descarta la sesión de entrega confiable y cierra el stream (i8).
+//
+// Con un session_id nuevo el Core no reanuda: ambos lados reinician la secuencia y
+// el Agent reenvía sus AgentMessages sin ack al recibir StreamResumed.
+func (a *Agent) restartStreamSession() {
+	a.streamMu.Lock()
+	defer a.streamMu.Unlock()
+
+	a.sessionID = utils.GenerateUUIDv7()
+	a.streamUp.Store(false)
+	if a.streamCancel != nil {
+		a.streamCancel()
+	}
+}
+
+// sendAck envía el ack acumulativo de CoreMessages si hubo recepciones (i8).
+func (a *Agent) sendAck() {
+	ackSeq, due := a.session.AckDue()
+	if !due {
+		return
+	}
+
+	msg := &pb.AgentMessage{
+		AgentId:     a.config.AgentID,
+		TimestampMs: utils.NowUnixMilli(),
+		Payload: &pb.AgentMessage_Ack{
+			Ack: &pb.Ack{Success: true, AckSeq: ackSeq},
+		},
+	}
+
+	a.streamMu.Lock()
+	err := a.coreStream.Send(msg)
+	a.streamMu.Unlock()
+	if err != nil {
+		// El resume_ack_seq del próximo AgentHello cubre el ack perdido
+		a.logDebug("Failed to send ack to Core (i8)", map[string]interface{}{
+			"ack_seq": ackSeq,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// reconnectToCore reabre el stream con backoff hasta reanudar la sesión (i8).
+//
+// Retorna false si el Agent se está deteniendo.
+func (a *Agent) reconnectToCore() bool {
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-a.ctx.Done():
+			return false
+		case <-time.After(a.config.ReconnectBackoff):
+		}
+
+		if err := a.openStream(); err != nil {
+			a.logWarn("Reconnect to Core failed (i8)", map[string]interface{}{
+				"attempt": attempt,
+				"error":   err.Error(),
+			})
+			continue
+		}
+
+		a.logInfo("Reconnected to Core (i8)", map[string]interface{}{
+			"attempt": attempt,
+		})
+
+		// El Core pudo haber limpiado el ownership de cuentas al caer el stream anterior
+		if a.pipeManager != nil {
+			a.pipeManager.ReannounceAccounts()
+		}
+		return true
+	}
+}
+
 // sendToCore goroutine que envía mensajes al Core.
 //
-// Lee del canal sendToCoreCh y envía por el stream.
+// Lee del canal sendToCoreCh y envía por el stream. i8: cada mensaje se persiste
+// primero en el store-and-forward y se drena mientras el stream está arriba; lleva
+// seq y queda retenido hasta el ack del Core, de modo que un fallo de envío se
+// recupera con el replay al reconectar.
 func (a *Agent) sendToCore() {
 	defer a.wg.Done()
 
 	a.logInfo("Send loop started", nil)
 
+	ackTicker := time.NewTicker(a.config.AckInterval)
+	defer ackTicker.Stop()
+
 	for {
 		select {
 		case msg, ok := <-a.sendToCoreCh:
@@ -66,14 +245,14 @@ func (a *Agent) sendToCore() {
 				return
 			}
 
-			if err := a.coreStream.Send(msg); err != nil {
-				a.logError("Failed to send to Core", err, nil)
-				// TODO i0: solo loggear, i1+: reconectar
-				continue
-			}
+			a.enqueueOutbound(msg)
+			a.drainOutbound()
+
+		case <-a.streamReady:
+			a.drainOutbound()
 
-			// Log según tipo de mensaje
-			a.logSentMessage(msg)
+		case <-ackTicker.C:
+			a.sendAck()
 
 		case <-a.ctx.Done():
 			a.logInfo("Send loop stopped", nil)
@@ -98,12 +277,51 @@ func (a *Agent) receiveFromCore() {
 		default:
 		}
 
-		// Recibir mensaje del stream
+		// Recibir mensaje del stream (solo este loop reemplaza coreStream tras Start)
 		msg := &pb.CoreMessage{}
 		if err := a.coreStream.RecvMsg(msg); err != nil {
+			if a.ctx.Err() != nil {
+				a.logInfo("Receive loop stopped", nil)
+				return
+			}
 			a.logError("Failed to receive from Core", err, nil)
-			// TODO i0: solo loggear, i1+: reconectar
-			return
+			// i8: retener lo saliente en disco, reconectar y reanudar la sesión
+			a.streamUp.Store(false)
+			if !a.reconnectToCore() {
+				return
+			}
+			continue
+		}
+
+		// i8: Ack acumulativo de AgentMessages y dedupe de replays por seq
+		if ack := msg.GetAck(); ack != nil {
+			a.ackOutbound(ack.AckSeq)
+			continue
+		}
+		if msg.GetStreamResumed() != nil {
+			continue // Solo válido durante el handshake
+		}
+		if msg.Seq > 0 {
+			accepted, err := a.session.Accept(msg.Seq)
+			if err != nil {
+				// Se perdieron CoreMessages: reconectar con una sesión nueva (sin resume)
+				a.logWarn("CoreMessage sequence gap, restarting stream session (i8)", map[string]interface{}{
+					"seq":           msg.Seq,
+					"last_received": a.session.LastReceived(),
+					"error":         err.Error(),
+				})
+				a.restartStreamSession()
+				if !a.reconnectToCore() {
+					return
+				}
+				continue
+			}
+			if !accepted {
+				a.logDebug("Duplicate CoreMessage discarded (i8)", map[string]interface{}{
+					"seq": msg.Seq,
+				})
+				continue
+			}
 		}
 
 		// Issue #M3: Agregar timestamp t4 (Agent recv from Core)
@@ -140,6 +358,9 @@ func (a *Agent) routeCoreMessage(msg *pb.CoreMessage) error {
 	case *pb.CoreMessage_SymbolRegistrationResult:
 		return a.routeSymbolRegistrationResult(payload.SymbolRegistrationResult)
 
+	case *pb.CoreMessage_ConfigUpdate:
+		return a.applyConfigUpdate(payload.ConfigUpdate)
+
 	default:
 		a.logWarn("Unknown message type from Core", map[string]interface{}{
 			"type": fmt.Sprintf("%T", payload),
@@ -418,6 +639,8 @@ func (a *Agent) sendAgentHello() error {
 		Os:       runtime.GOOS,
 		Symbols:  make(map[string]*pb.SymbolInfo), // TODO i3: reportar símbolos
 		// NO incluye owned_accounts ni connected_clients (deprecated i2)
+		SessionId:    a.sessionID,
+		ResumeAckSeq: a.session.LastReceived(),
 	}
 
 	msg := &pb.AgentMessage{
@@ -2,14 +2,22 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/xKoRx/echo/core/internal"
+	"github.com/xKoRx/echo/core/internal/journal"
+	"github.com/xKoRx/echo/core/internal/migrations"
+	"github.com/xKoRx/echo/sdk/domain"
 	"github.com/xKoRx/echo/sdk/domain/handshake"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -23,6 +31,20 @@ func main() {
 	switch command {
 	case "handshake":
 		runHandshake(os.Args[2:])
+	case "risk":
+		runRisk(os.Args[2:])
+	case "db":
+		runDB(os.Args[2:])
+	case "journal":
+		runJournal(os.Args[2:])
+	case "agents":
+		runAgents(os.Args[2:])
+	case "accounts":
+		runAccounts(os.Args[2:])
+	case "policy":
+		runPolicy(os.Args[2:])
+	case "symbols":
+		runSymbols(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "comando desconocido: %s\n", command)
 		printUsage()
@@ -35,9 +57,61 @@ func printUsage() {
 
 Uso:
   echo-core-cli handshake evaluate --account <id> [--timeout 15s] [--no-send] [--json]
+  echo-core-cli risk simulate --account <id> --strategy <id> --symbol <sym> --side buy|sell --entry <precio> --stop <precio>
+                              [--currency USD] [--ignore-staleness] [--timeout 15s] [--json]
+  echo-core-cli db status [--timeout 30s]
+  echo-core-cli db migrate [--dry-run] [--verbose] [--baseline <version>] [--timeout 5m]
+  echo-core-cli journal list --dir <dir>
+  echo-core-cli journal dump <segmento>
+  echo-core-cli journal replay [--dir <dir> | <segmento>...] [--storage memory|sqlite] [--sqlite-path <archivo>]
+                               [--out <dir>] [--etcd-config] [--timeout 10m] [--json]
+  echo-core-cli agents list [--stale] [--timeout 30s] [--json]
+  echo-core-cli agents token --agent <id> [--ttl 720h] [--timeout 30s]
+  echo-core-cli accounts owners [--conflicts] [--timeout 30s] [--json]
+  echo-core-cli accounts takeover --account <id> --agent <id> [--timeout 30s]
+  echo-core-cli policy list [--account <id>] [--timeout 30s] [--json]
+  echo-core-cli policy get --account <id> --strategy <id> [--history] [--timeout 30s] [--json]
+  echo-core-cli policy set --account <id> --strategy <id> --type FIXED_LOT --lot <lote>
+                           [--effective-from <RFC3339>] [--valid-until <RFC3339>] [--author <autor>] [--reason <motivo>]
+  echo-core-cli policy set --account <id> --strategy <id> --type FIXED_RISK --amount <monto> --currency <ISO>
+                           [--min-lot <lote>] [--max-lot <lote>] [--commission-per-lot <monto>] [--commission-rate <tasa>]
+                           [--effective-from <RFC3339>] [--valid-until <RFC3339>] [--author <autor>] [--reason <motivo>]
+  echo-core-cli policy delete --account <id> --strategy <id> [--author <autor>] [--reason <motivo>]
+  echo-core-cli policy import --file <archivo|-> [--format yaml|csv] [--author <autor>] [--reason <motivo>] [--dry-run]
+  echo-core-cli policy export [--file <archivo>] [--format yaml|csv] [--account <id>]
+  echo-core-cli symbols mappings --account <id> [--timeout 30s] [--json]
+  echo-core-cli symbols specs --account <id> [--timeout 30s] [--json]
+  echo-core-cli symbols pin --account <id> --canonical <sym> --broker <sym> [--author <autor>] [--reason <motivo>]
+  echo-core-cli symbols unpin --account <id> --canonical <sym>
+  echo-core-cli symbols tradeable --account <id> [--timeout 30s] [--json]
+  echo-core-cli symbols proposals --account <id> [--timeout 30s] [--json]
+  echo-core-cli symbols diff --canonical <sym> --left <id> --right <id> [--timeout 30s] [--json]
 
 Comandos:
   handshake evaluate   Fuerza la re-evaluación de handshake para una cuenta.
+  risk simulate        Simula el dimensionamiento de lote con specs, quotes y política persistidos.
+  db status            Muestra la versión del schema y las migraciones pendientes.
+  db migrate           Aplica las migraciones pendientes (--dry-run solo las lista).
+  journal list         Lista los segmentos del journal de mensajes.
+  journal dump         Imprime los registros de un segmento (JSON por línea).
+  journal replay       Reproduce segmentos a través del Router contra una base en memoria o scratch.
+  agents list          Muestra el último heartbeat de cada Agent y el estado de sus pipes.
+  agents token         Emite un agent-token firmado con core/security/agent_token_secret.
+  accounts owners      Muestra el Agent owner de cada cuenta y los Agents en cuarentena.
+  accounts takeover    Fuerza el cambio de owner de una cuenta aunque el actual siga vivo.
+  policy list          Lista la política de riesgo vigente de cada cuenta × estrategia.
+  policy get           Muestra la política vigente de un par y opcionalmente su historial.
+  policy set           Valida y agrega una revisión FIXED_LOT o FIXED_RISK; Core invalida su caché.
+  policy delete        Retira la política vigente agregando una revisión que expira ahora.
+  policy import        Valida y aplica políticas desde YAML o CSV (omite las idénticas a la vigente).
+  policy export        Exporta las políticas vigentes a YAML o CSV (re-importable).
+  symbols mappings     Lista los mapeos canonical ⇄ broker_symbol de una cuenta y sus overrides.
+  symbols specs        Lista las especificaciones de símbolos reportadas por una cuenta.
+  symbols pin          Fija un broker_symbol para un canónico; prevalece sobre los reportes del EA.
+  symbols unpin        Elimina un override; el EA vuelve a definir el mapeo en su próximo reporte.
+  symbols tradeable    Muestra qué canónicos puede operar una cuenta y por qué no los demás.
+  symbols proposals    Lista los mapeos sugeridos por el matcher para símbolos sin canónico.
+  symbols diff         Compara la especificación de un canónico entre dos cuentas.
 `
 	fmt.Fprintln(os.Stderr, usage)
 }
@@ -144,3 +218,1506 @@ func printEvaluationText(evaluation *handshake.Evaluation) {
 
 	fmt.Println(strings.Join(lines, "\n"))
 }
+
+func runRisk(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "simulate":
+		riskSimulate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando risk desconocido: %s\n", subcommand)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func riskSimulate(args []string) {
+	fs := flag.NewFlagSet("risk simulate", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta slave (account_id) a simular")
+	strategyID := fs.String("strategy", "", "Estrategia (strategy_id) de la política")
+	symbol := fs.String("symbol", "", "Símbolo canónico (ej: XAUUSD)")
+	side := fs.String("side", "", "Dirección de la orden: buy | sell")
+	entry := fs.Float64("entry", 0, "Precio de entrada")
+	stop := fs.Float64("stop", 0, "Precio de stop loss")
+	currency := fs.String("currency", "", "Divisa de la cuenta si no hay snapshot (default: divisa de la política)")
+	ignoreStaleness := fs.Bool("ignore-staleness", false, "Ignorar la edad máxima de quotes y specs persistidos")
+	timeout := fs.Duration("timeout", 15*time.Second, "Timeout para la simulación")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en formato JSON")
+	fs.Parse(args)
+
+	if *accountID == "" || *strategyID == "" || *symbol == "" {
+		fmt.Fprintln(os.Stderr, "--account, --strategy y --symbol son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *entry <= 0 || *stop <= 0 {
+		fmt.Fprintln(os.Stderr, "--entry y --stop son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	orderSide, err := parseOrderSide(*side)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	core, err := internal.New(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error inicializando core: %v\n", err)
+		os.Exit(1)
+	}
+	defer core.Shutdown()
+
+	result, err := core.SimulateRiskSizing(ctx, internal.RiskSimulationRequest{
+		AccountID:       *accountID,
+		StrategyID:      *strategyID,
+		Symbol:          *symbol,
+		Side:            orderSide,
+		Entry:           *entry,
+		StopLoss:        *stop,
+		AccountCurrency: *currency,
+		IgnoreStaleness: *ignoreStaleness,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error simulando riesgo: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		printSimulationJSON(result)
+		return
+	}
+
+	printSimulationText(result)
+}
+
+func parseOrderSide(value string) (pb.OrderSide, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "buy":
+		return pb.OrderSide_ORDER_SIDE_BUY, nil
+	case "sell":
+		return pb.OrderSide_ORDER_SIDE_SELL, nil
+	default:
+		return pb.OrderSide_ORDER_SIDE_UNSPECIFIED, fmt.Errorf("--side debe ser buy o sell (recibido %q)", value)
+	}
+}
+
+func printSimulationJSON(result *internal.RiskSimulationResult) {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error serializando resultado: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
+
+func printSimulationText(result *internal.RiskSimulationResult) {
+	symbol := result.CanonicalSymbol
+	if result.BrokerSymbol != "" {
+		symbol = fmt.Sprintf("%s (%s)", result.CanonicalSymbol, result.BrokerSymbol)
+	}
+	lines := []string{
+		fmt.Sprintf("Cuenta: %s  Estrategia: %s", result.AccountID, result.StrategyID),
+		fmt.Sprintf("Símbolo: %s  Side: %s  Entry: %g  Stop: %g", symbol, strings.ToUpper(result.Side), result.Entry, result.StopLoss),
+	}
+	if result.PolicyType != "" {
+		lines = append(lines, fmt.Sprintf("Política: %s v%d", result.PolicyType, result.PolicyVersion))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Decisión: %s (%s)", strings.ToUpper(result.Decision), result.Reason),
+	)
+	if result.Error != "" {
+		lines = append(lines, fmt.Sprintf("Error: %s", result.Error))
+	}
+	if result.RequestedLot > 0 {
+		lines = append(lines, fmt.Sprintf("Lote solicitado: %.4f", result.RequestedLot))
+	}
+	lines = append(lines,
+		fmt.Sprintf("Lote: %.4f", result.Lot),
+		fmt.Sprintf("Pérdida esperada: %.2f", result.ExpectedLoss),
+		"Comisiones:",
+		fmt.Sprintf("  - fija por lote: %.4f", result.CommissionFixedPerLot),
+		fmt.Sprintf("  - tasa: %.6f", result.CommissionRate),
+		fmt.Sprintf("  - total por lote: %.4f", result.CommissionPerLot),
+		fmt.Sprintf("  - total: %.4f", result.CommissionTotal),
+	)
+	if result.VolumeGuardDecision != "" {
+		lines = append(lines, fmt.Sprintf("Volume guard: %s", result.VolumeGuardDecision))
+	}
+	if result.AccountCurrency != "" {
+		lines = append(lines, fmt.Sprintf("Divisa cuenta: %s (%s)", result.AccountCurrency, result.AccountCurrencySource))
+	}
+	if result.QuoteAgeMs > 0 || result.SpecAgeMs > 0 {
+		lines = append(lines, fmt.Sprintf("Edad quote: %s  Edad spec: %s",
+			(time.Duration(result.QuoteAgeMs)*time.Millisecond).String(),
+			(time.Duration(result.SpecAgeMs)*time.Millisecond).String(),
+		))
+	}
+
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func runDB(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "status":
+		dbStatus(args[1:])
+	case "migrate":
+		dbMigrate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando db desconocido: %s\n", subcommand)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func dbStatus(args []string) {
+	fs := flag.NewFlagSet("db status", flag.ExitOnError)
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	runner, err := internal.OpenMigrationRunner(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error abriendo base de datos: %v\n", err)
+		os.Exit(1)
+	}
+	defer runner.Close()
+
+	status, err := runner.Status(ctx)
+	if status != nil {
+		printMigrationStatus(status)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error verificando schema: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func dbMigrate(args []string) {
+	fs := flag.NewFlagSet("db migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "Listar las migraciones pendientes sin aplicarlas")
+	verbose := fs.Bool("verbose", false, "Con --dry-run, imprimir el SQL de cada migración")
+	baseline := fs.Int("baseline", 0, "Registrar como aplicadas (sin ejecutar) las migraciones hasta esta versión")
+	timeout := fs.Duration("timeout", 5*time.Minute, "Timeout de la migración")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	runner, err := internal.OpenMigrationRunner(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error abriendo base de datos: %v\n", err)
+		os.Exit(1)
+	}
+	defer runner.Close()
+
+	if *baseline > 0 {
+		if *dryRun {
+			fmt.Printf("Baseline: se registrarían las versiones 1..%d sin ejecutarlas\n", *baseline)
+			return
+		}
+		recorded, err := runner.Baseline(ctx, *baseline)
+		for _, m := range recorded {
+			fmt.Printf("  registrada %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error registrando baseline: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Baseline registrado en versión %d\n", *baseline)
+		return
+	}
+
+	if *dryRun {
+		status, err := runner.Status(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error verificando schema: %v\n", err)
+			os.Exit(1)
+		}
+		if len(status.Pending) == 0 {
+			fmt.Printf("Schema al día (versión %d)\n", status.CurrentVersion)
+			return
+		}
+		fmt.Printf("Dry-run: %d migraciones pendientes (versión %d → %d)\n", len(status.Pending), status.CurrentVersion, status.LatestVersion)
+		for _, m := range status.Pending {
+			fmt.Printf("  %04d_%s\n", m.Version, m.Name)
+			if *verbose {
+				fmt.Println(m.SQL)
+			}
+		}
+		return
+	}
+
+	applied, err := runner.Migrate(ctx)
+	for _, m := range applied {
+		fmt.Printf("  aplicada %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error migrando schema: %v\n", err)
+		os.Exit(1)
+	}
+	if len(applied) == 0 {
+		fmt.Println("Schema al día, sin migraciones pendientes")
+		return
+	}
+	fmt.Printf("%d migraciones aplicadas\n", len(applied))
+}
+
+func printMigrationStatus(status *migrations.Status) {
+	lines := []string{
+		fmt.Sprintf("Dialecto: %s", status.Dialect),
+		fmt.Sprintf("Versión actual: %d  Última embebida: %d", status.CurrentVersion, status.LatestVersion),
+	}
+	if len(status.Applied) > 0 {
+		lines = append(lines, "Aplicadas:")
+		for _, m := range status.Applied {
+			lines = append(lines, fmt.Sprintf("  %04d_%s (%s)", m.Version, m.Name, m.AppliedAt.Format(time.RFC3339)))
+		}
+	}
+	if len(status.Pending) > 0 {
+		lines = append(lines, "Pendientes:")
+		for _, m := range status.Pending {
+			lines = append(lines, fmt.Sprintf("  %04d_%s", m.Version, m.Name))
+		}
+	}
+
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func runJournal(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "list":
+		journalList(args[1:])
+	case "dump":
+		journalDump(args[1:])
+	case "replay":
+		journalReplay(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando journal desconocido: %s\n", subcommand)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func journalList(args []string) {
+	fs := flag.NewFlagSet("journal list", flag.ExitOnError)
+	dir := fs.String("dir", "", "Directorio del journal (core/journal/dir)")
+	fs.Parse(args)
+
+	if *dir == "" {
+		fmt.Fprintln(os.Stderr, "--dir es requerido")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	segments, err := journal.ListSegments(*dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error listando segmentos: %v\n", err)
+		os.Exit(1)
+	}
+	for _, segment := range segments {
+		size := int64(0)
+		if info, err := os.Stat(segment.Path); err == nil {
+			size = info.Size()
+		}
+		fmt.Printf("%s  first_seq=%d  bytes=%d  compressed=%t\n", segment.Path, segment.FirstSeq, size, segment.Compressed)
+	}
+}
+
+func journalDump(args []string) {
+	fs := flag.NewFlagSet("journal dump", flag.ExitOnError)
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "se requiere exactamente un segmento")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	reader, err := journal.OpenSegment(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error abriendo segmento: %v\n", err)
+		os.Exit(1)
+	}
+	defer reader.Close()
+
+	options := protojson.MarshalOptions{}
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error leyendo segmento: %v\n", err)
+			os.Exit(1)
+		}
+		data, err := options.Marshal(record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error serializando registro %d: %v\n", record.Seq, err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+	}
+	if reader.Truncated {
+		fmt.Fprintln(os.Stderr, "aviso: el segmento termina con un registro incompleto")
+	}
+}
+
+func journalReplay(args []string) {
+	fs := flag.NewFlagSet("journal replay", flag.ExitOnError)
+	dir := fs.String("dir", "", "Reproducir todos los segmentos del directorio")
+	storage := fs.String("storage", internal.StorageBackendMemory, "Base del replay: memory | sqlite (scratch)")
+	sqlitePath := fs.String("sqlite-path", "", "Archivo SQLite scratch (default: temporal)")
+	out := fs.String("out", "", "Directorio donde escribir el journal reproducido")
+	etcdConfig := fs.Bool("etcd-config", false, "Usar la config actual de ETCD en lugar de la registrada en el journal")
+	timeout := fs.Duration("timeout", 10*time.Minute, "Timeout del replay")
+	jsonOutput := fs.Bool("json", false, "Imprimir el reporte en formato JSON")
+	fs.Parse(args)
+
+	segments := fs.Args()
+	if *dir != "" {
+		listed, err := journal.ListSegments(*dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error listando segmentos: %v\n", err)
+			os.Exit(1)
+		}
+		for _, segment := range listed {
+			segments = append(segments, segment.Path)
+		}
+	}
+	if len(segments) == 0 {
+		fmt.Fprintln(os.Stderr, "se requiere --dir o al menos un segmento")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	// Sin --etcd-config se usa la config registrada: el replay no depende del ETCD actual
+	var config *internal.Config
+	if *etcdConfig {
+		loaded, err := internal.LoadConfig(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error cargando config de ETCD: %v\n", err)
+			os.Exit(1)
+		}
+		config = loaded
+	}
+
+	report, err := internal.Replay(ctx, internal.ReplayOptions{
+		Segments:   segments,
+		Storage:    *storage,
+		SQLitePath: *sqlitePath,
+		OutputDir:  *out,
+		Config:     config,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error reproduciendo journal: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error serializando reporte: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	lines := []string{
+		fmt.Sprintf("Segmentos: %d  Storage: %s  Config: %s  Duración: %s", len(report.Segments), report.Storage, report.ConfigSource, report.Duration),
+		fmt.Sprintf("Seq: %d..%d  Registros: %d", report.FirstSeq, report.LastSeq, report.Records),
+		fmt.Sprintf("Inbound reproducidos: %d", report.Inbound),
+		fmt.Sprintf("Outbound registrados: %d  Outbound reproducidos: %d", report.RecordedOutbound, report.ReplayedOutbound),
+	}
+	lines = append(lines, formatCounts("Inbound por tipo:", report.InboundByType)...)
+	lines = append(lines, formatCounts("Outbound registrados por tipo:", report.RecordedOutboundByType)...)
+	lines = append(lines, formatCounts("Outbound reproducidos por tipo:", report.ReplayedOutboundByType)...)
+	if len(report.Agents) > 0 {
+		lines = append(lines, fmt.Sprintf("Agents: %s", strings.Join(report.Agents, ", ")))
+	}
+	if report.Truncated {
+		lines = append(lines, "Aviso: algún segmento termina con un registro incompleto")
+	}
+	if report.ConfigChanged {
+		lines = append(lines, "Aviso: la config cambió entre segmentos; se usó la del primero")
+	}
+
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func runAgents(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "list":
+		agentsList(args[1:])
+	case "token":
+		agentsToken(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando agents desconocido: %s\n", subcommand)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func agentsList(args []string) {
+	fs := flag.NewFlagSet("agents list", flag.ExitOnError)
+	staleOnly := fs.Bool("stale", false, "Mostrar solo Agents o pipes que no están ALIVE")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	agents, err := internal.ListAgentLiveness(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error consultando liveness: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *staleOnly {
+		filtered := agents[:0]
+		for _, agent := range agents {
+			if !isAgentAlive(agent) {
+				filtered = append(filtered, agent)
+			}
+		}
+		agents = filtered
+	}
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(agents, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error serializando resultado: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	printAgentsText(agents, time.Now())
+}
+
+func agentsToken(args []string) {
+	fs := flag.NewFlagSet("agents token", flag.ExitOnError)
+	agentID := fs.String("agent", "", "Agent (agent_id) al que se emite el token")
+	ttl := fs.Duration("ttl", 30*24*time.Hour, "Vigencia del token")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	fs.Parse(args)
+
+	if *agentID == "" {
+		fmt.Fprintln(os.Stderr, "--agent es requerido")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	token, expiresAt, err := internal.MintAgentToken(ctx, *agentID, *ttl)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error emitiendo token: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "token para %s válido hasta %s (configurar en agent/grpc/token)\n", *agentID, expiresAt.Format(time.RFC3339))
+	fmt.Println(token)
+}
+
+// isAgentAlive indica si el Agent y todos sus pipes están ALIVE.
+func isAgentAlive(agent *domain.AgentLiveness) bool {
+	if agent.Status != domain.LivenessStatusAlive {
+		return false
+	}
+	for _, account := range agent.Accounts {
+		if account.Status != domain.LivenessStatusAlive {
+			return false
+		}
+	}
+	return true
+}
+
+func printAgentsText(agents []*domain.AgentLiveness, now time.Time) {
+	if len(agents) == 0 {
+		fmt.Println("Sin Agents registrados")
+		return
+	}
+
+	lines := make([]string, 0, len(agents)*4)
+	for _, agent := range agents {
+		lines = append(lines,
+			fmt.Sprintf("%s  %s  last_seen=%s  pending_commands=%d  pending_bytes=%d",
+				agent.AgentID, agent.Status, formatLastSeen(agent.LastSeenAtMs, now), agent.PendingCommands, agent.PendingBytes),
+		)
+		if agent.StaleSinceMs > 0 && agent.Status == domain.LivenessStatusStale {
+			lines = append(lines, fmt.Sprintf("  stale desde %s", formatLastSeen(agent.StaleSinceMs, now)))
+		}
+		for _, account := range agent.Accounts {
+			lines = append(lines,
+				fmt.Sprintf("  - %s  account=%s  role=%s  %s  codec=%s  last_seen=%s",
+					account.PipeName, account.AccountID, account.PipeRole, account.Status, account.Codec, formatLastSeen(account.LastSeenAtMs, now)),
+			)
+		}
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+// formatLastSeen formatea un timestamp en ms como RFC3339 junto a su antigüedad.
+func formatLastSeen(ms int64, now time.Time) string {
+	if ms <= 0 {
+		return "nunca"
+	}
+	seen := time.UnixMilli(ms)
+	return fmt.Sprintf("%s (hace %s)", seen.UTC().Format(time.RFC3339), now.Sub(seen).Truncate(time.Second))
+}
+
+func formatCounts(title string, counts map[string]int) []string {
+	if len(counts) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := []string{title}
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("  - %s: %d", key, counts[key]))
+	}
+	return lines
+}
+
+func runAccounts(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "owners":
+		accountsOwners(args[1:])
+	case "takeover":
+		accountsTakeover(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando accounts desconocido: %s\n", subcommand)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func accountsOwners(args []string) {
+	fs := flag.NewFlagSet("accounts owners", flag.ExitOnError)
+	conflictsOnly := fs.Bool("conflicts", false, "Mostrar solo cuentas con un Agent en cuarentena o takeover pendiente")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	accounts, err := internal.ListAccountOwnership(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error consultando ownership: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *conflictsOnly {
+		filtered := accounts[:0]
+		for _, account := range accounts {
+			if account.ClaimantAgentID != "" || account.ForcedAgentID != "" {
+				filtered = append(filtered, account)
+			}
+		}
+		accounts = filtered
+	}
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(accounts, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error serializando resultado: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	printAccountOwnersText(accounts, time.Now())
+}
+
+func printAccountOwnersText(accounts []*domain.AccountOwnership, now time.Time) {
+	if len(accounts) == 0 {
+		fmt.Println("Sin cuentas registradas")
+		return
+	}
+
+	lines := make([]string, 0, len(accounts)*2)
+	for _, account := range accounts {
+		lines = append(lines,
+			fmt.Sprintf("%s  agent=%s  role=%s  %s  desde=%s",
+				account.AccountID, account.AgentID, account.PipeRole, account.Status, formatLastSeen(account.RegisteredAtMs, now)),
+		)
+		if account.ClaimantAgentID != "" {
+			lines = append(lines, fmt.Sprintf("  conflicto: %s en cuarentena desde %s", account.ClaimantAgentID, formatLastSeen(account.ConflictAtMs, now)))
+		}
+		if account.ForcedAgentID != "" {
+			lines = append(lines, fmt.Sprintf("  takeover pendiente hacia %s", account.ForcedAgentID))
+		}
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func accountsTakeover(args []string) {
+	fs := flag.NewFlagSet("accounts takeover", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta (account_id) a reasignar")
+	agentID := fs.String("agent", "", "Agent (agent_id) que pasa a ser owner")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la operación")
+	fs.Parse(args)
+
+	if *accountID == "" || *agentID == "" {
+		fmt.Fprintln(os.Stderr, "--account y --agent son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := internal.RequestAccountTakeover(ctx, *accountID, *agentID); err != nil {
+		fmt.Fprintf(os.Stderr, "error solicitando takeover: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Takeover de %s hacia %s solicitado; el Core lo aplica en core/ownership/check_interval_ms\n", *accountID, *agentID)
+}
+
+func runPolicy(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "list":
+		policyList(args[1:])
+	case "get":
+		policyGet(args[1:])
+	case "set":
+		policySet(args[1:])
+	case "delete":
+		policyDelete(args[1:])
+	case "import":
+		policyImport(args[1:])
+	case "export":
+		policyExport(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando policy desconocido: %s\n", subcommand)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+// optionalFloat flag numérico que distingue "no indicado" de cero.
+type optionalFloat struct {
+	value *float64
+}
+
+func (f *optionalFloat) String() string {
+	if f == nil || f.value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f.value, 'f', -1, 64)
+}
+
+func (f *optionalFloat) Set(raw string) error {
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return err
+	}
+	f.value = &value
+	return nil
+}
+
+// optionalTime flag RFC3339 opcional.
+type optionalTime struct {
+	value *time.Time
+}
+
+func (t *optionalTime) String() string {
+	if t == nil || t.value == nil {
+		return ""
+	}
+	return t.value.Format(time.RFC3339)
+}
+
+func (t *optionalTime) Set(raw string) error {
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return err
+	}
+	t.value = &value
+	return nil
+}
+
+// defaultPolicyAuthor autor por defecto de las revisiones (cli:$USER).
+func defaultPolicyAuthor() string {
+	if user := os.Getenv("USER"); user != "" {
+		return "cli:" + user
+	}
+	return ""
+}
+
+func policyList(args []string) {
+	fs := flag.NewFlagSet("policy list", flag.ExitOnError)
+	accountID := fs.String("account", "", "Mostrar solo las políticas de esta cuenta")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	records, err := loadPolicyRecords(ctx, *accountID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error consultando políticas: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		printJSON(records)
+		return
+	}
+
+	if len(records) == 0 {
+		fmt.Println("Sin políticas vigentes")
+		return
+	}
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		lines = append(lines, formatPolicyRecord(record))
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func policyGet(args []string) {
+	fs := flag.NewFlagSet("policy get", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta slave (account_id)")
+	strategyID := fs.String("strategy", "", "Estrategia (strategy_id)")
+	withHistory := fs.Bool("history", false, "Incluir el historial completo de revisiones")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	if *accountID == "" || *strategyID == "" {
+		fmt.Fprintln(os.Stderr, "--account y --strategy son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	policy, history, err := internal.GetRiskPolicy(ctx, *accountID, *strategyID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error consultando política: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		result := struct {
+			Policy  *internal.RiskPolicyRecord `json:"policy"`
+			History []policyRevisionView       `json:"history,omitempty"`
+		}{}
+		if policy != nil {
+			record := internal.NewRiskPolicyRecord(policy)
+			result.Policy = &record
+		}
+		if *withHistory {
+			for _, revision := range history {
+				result.History = append(result.History, newPolicyRevisionView(revision))
+			}
+		}
+		printJSON(result)
+		return
+	}
+
+	if policy == nil {
+		fmt.Printf("%s/%s sin política vigente\n", *accountID, *strategyID)
+	} else {
+		fmt.Println(formatPolicyRecord(internal.NewRiskPolicyRecord(policy)))
+	}
+	if *withHistory {
+		for _, revision := range history {
+			view := newPolicyRevisionView(revision)
+			fmt.Printf("  v%d  %s  autor=%s  registrada=%s  %s\n",
+				view.Version, formatPolicyParams(view.RiskPolicyRecord), view.Author, view.RecordedAt.UTC().Format(time.RFC3339), view.Reason)
+		}
+	}
+}
+
+// policyRevisionView revisión del historial para salida JSON/texto.
+type policyRevisionView struct {
+	internal.RiskPolicyRecord
+	Author     string    `json:"author"`
+	Reason     string    `json:"reason,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+func newPolicyRevisionView(revision *domain.RiskPolicyRevision) policyRevisionView {
+	return policyRevisionView{
+		RiskPolicyRecord: internal.NewRiskPolicyRecord(&revision.Policy),
+		Author:           revision.Author,
+		Reason:           revision.Reason,
+		RecordedAt:       revision.RecordedAt,
+	}
+}
+
+func policySet(args []string) {
+	fs := flag.NewFlagSet("policy set", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta slave (account_id)")
+	strategyID := fs.String("strategy", "", "Estrategia (strategy_id)")
+	policyType := fs.String("type", "", "Tipo de política: FIXED_LOT | FIXED_RISK")
+	var lot, amount, minLot, maxLot, commissionPerLot, commissionRate optionalFloat
+	fs.Var(&lot, "lot", "FIXED_LOT: lote fijo")
+	fs.Var(&amount, "amount", "FIXED_RISK: riesgo por operación en la divisa de la cuenta")
+	currency := fs.String("currency", "", "FIXED_RISK: divisa ISO 4217 del riesgo (ej: USD)")
+	fs.Var(&minLot, "min-lot", "FIXED_RISK: lote mínimo (override de la spec del broker)")
+	fs.Var(&maxLot, "max-lot", "FIXED_RISK: lote máximo (override de la spec del broker)")
+	fs.Var(&commissionPerLot, "commission-per-lot", "FIXED_RISK: comisión por lote incluida en la pérdida esperada")
+	fs.Var(&commissionRate, "commission-rate", "FIXED_RISK: comisión proporcional incluida en la pérdida esperada")
+	var effectiveFrom, validUntil optionalTime
+	fs.Var(&effectiveFrom, "effective-from", "Activación programada (RFC3339); por defecto inmediata")
+	fs.Var(&validUntil, "valid-until", "Expiración de la revisión (RFC3339)")
+	author := fs.String("author", defaultPolicyAuthor(), "Autor de la revisión")
+	reason := fs.String("reason", "", "Motivo del cambio")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la operación")
+	fs.Parse(args)
+
+	if *accountID == "" || *strategyID == "" || *policyType == "" {
+		fmt.Fprintln(os.Stderr, "--account, --strategy y --type son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	record := internal.RiskPolicyRecord{
+		AccountID:        *accountID,
+		StrategyID:       *strategyID,
+		Type:             *policyType,
+		LotSize:          lot.value,
+		Amount:           amount.value,
+		Currency:         *currency,
+		MinLotOverride:   minLot.value,
+		MaxLotOverride:   maxLot.value,
+		CommissionPerLot: commissionPerLot.value,
+		CommissionRate:   commissionRate.value,
+		EffectiveFrom:    effectiveFrom.value,
+		ValidUntil:       validUntil.value,
+	}
+	revision, err := record.Revision(*author, *reason, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "política inválida: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	results, err := internal.ApplyRiskPolicies(ctx, []*domain.RiskPolicyRevision{revision})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error guardando política: %v\n", err)
+		os.Exit(1)
+	}
+	printPolicyApplyResults(results)
+}
+
+func policyDelete(args []string) {
+	fs := flag.NewFlagSet("policy delete", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta slave (account_id)")
+	strategyID := fs.String("strategy", "", "Estrategia (strategy_id)")
+	author := fs.String("author", defaultPolicyAuthor(), "Autor de la revisión")
+	reason := fs.String("reason", "", "Motivo del retiro")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la operación")
+	fs.Parse(args)
+
+	if *accountID == "" || *strategyID == "" {
+		fmt.Fprintln(os.Stderr, "--account y --strategy son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	version, err := internal.DeleteRiskPolicy(ctx, *accountID, *strategyID, *author, *reason)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error retirando política: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s/%s retirada (v%d); Core invalida su caché de inmediato\n", *accountID, *strategyID, version)
+}
+
+func policyImport(args []string) {
+	fs := flag.NewFlagSet("policy import", flag.ExitOnError)
+	file := fs.String("file", "", "Archivo a importar (- para stdin)")
+	format := fs.String("format", "", "Formato: yaml | csv (default: por extensión)")
+	author := fs.String("author", defaultPolicyAuthor(), "Autor de las revisiones")
+	reason := fs.String("reason", "", "Motivo del import")
+	dryRun := fs.Bool("dry-run", false, "Solo validar el archivo, sin escribir")
+	timeout := fs.Duration("timeout", 2*time.Minute, "Timeout de la operación")
+	fs.Parse(args)
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, "--file es requerido")
+		fs.Usage()
+		os.Exit(1)
+	}
+	policyFormat, err := internal.ParseRiskPolicyFormat(*format, *file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	input := io.Reader(os.Stdin)
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error abriendo %s: %v\n", *file, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	records, err := internal.DecodeRiskPolicies(input, policyFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error leyendo %s: %v\n", *file, err)
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	revisions := make([]*domain.RiskPolicyRevision, 0, len(records))
+	for _, record := range records {
+		revision, err := record.Revision(*author, *reason, now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "política inválida: %v\n", err)
+			os.Exit(1)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := internal.ValidateRiskPolicyRevisions(revisions); err != nil {
+		fmt.Fprintf(os.Stderr, "política inválida: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *dryRun {
+		fmt.Printf("%d políticas válidas (dry-run, sin cambios)\n", len(revisions))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	results, err := internal.ApplyRiskPolicies(ctx, revisions)
+	printPolicyApplyResults(results)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error importando políticas: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func policyExport(args []string) {
+	fs := flag.NewFlagSet("policy export", flag.ExitOnError)
+	file := fs.String("file", "", "Archivo de salida (default: stdout)")
+	format := fs.String("format", "", "Formato: yaml | csv (default: por extensión o yaml)")
+	accountID := fs.String("account", "", "Exportar solo las políticas de esta cuenta")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	fs.Parse(args)
+
+	if *format == "" && *file == "" {
+		*format = string(internal.RiskPolicyFormatYAML)
+	}
+	policyFormat, err := internal.ParseRiskPolicyFormat(*format, *file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	records, err := loadPolicyRecords(ctx, *accountID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error consultando políticas: %v\n", err)
+		os.Exit(1)
+	}
+
+	output := io.Writer(os.Stdout)
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error creando %s: %v\n", *file, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		output = f
+	}
+
+	if err := internal.EncodeRiskPolicies(output, policyFormat, records); err != nil {
+		fmt.Fprintf(os.Stderr, "error exportando políticas: %v\n", err)
+		os.Exit(1)
+	}
+	if *file != "" {
+		fmt.Fprintf(os.Stderr, "%d políticas exportadas a %s\n", len(records), *file)
+	}
+}
+
+// loadPolicyRecords lista las políticas vigentes, opcionalmente de una sola cuenta.
+func loadPolicyRecords(ctx context.Context, accountID string) ([]internal.RiskPolicyRecord, error) {
+	policies, err := internal.ListRiskPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]internal.RiskPolicyRecord, 0, len(policies))
+	for _, policy := range policies {
+		if accountID != "" && policy.AccountID != accountID {
+			continue
+		}
+		records = append(records, internal.NewRiskPolicyRecord(policy))
+	}
+	return records, nil
+}
+
+func printPolicyApplyResults(results []internal.RiskPolicyApplyResult) {
+	written := 0
+	for _, result := range results {
+		if result.Unchanged {
+			fmt.Printf("%s/%s sin cambios\n", result.AccountID, result.StrategyID)
+			continue
+		}
+		written++
+		fmt.Printf("%s/%s guardada (v%d)\n", result.AccountID, result.StrategyID, result.Version)
+	}
+	if written > 0 {
+		fmt.Fprintln(os.Stderr, "Core invalida su caché de políticas de inmediato (NOTIFY en PostgreSQL, core/risk/policy_poll_interval_ms en SQLite)")
+	}
+}
+
+func formatPolicyRecord(record internal.RiskPolicyRecord) string {
+	line := fmt.Sprintf("%s/%s  v%d  %s", record.AccountID, record.StrategyID, record.Version, formatPolicyParams(record))
+	if record.EffectiveFrom != nil {
+		line += "  desde=" + record.EffectiveFrom.UTC().Format(time.RFC3339)
+	}
+	if record.ValidUntil != nil {
+		line += "  hasta=" + record.ValidUntil.UTC().Format(time.RFC3339)
+	}
+	return line
+}
+
+// formatPolicyParams formatea tipo y parámetros de dimensionamiento.
+func formatPolicyParams(record internal.RiskPolicyRecord) string {
+	parts := []string{record.Type}
+	add := func(name string, value *float64) {
+		if value != nil {
+			parts = append(parts, name+"="+strconv.FormatFloat(*value, 'f', -1, 64))
+		}
+	}
+	add("lot", record.LotSize)
+	add("amount", record.Amount)
+	if record.Currency != "" {
+		parts = append(parts, "currency="+record.Currency)
+	}
+	add("min_lot", record.MinLotOverride)
+	add("max_lot", record.MaxLotOverride)
+	add("commission_per_lot", record.CommissionPerLot)
+	add("commission_rate", record.CommissionRate)
+	return strings.Join(parts, " ")
+}
+
+func runSymbols(args []string) {
+	if len(args) == 0 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	subcommand := args[0]
+	switch subcommand {
+	case "mappings":
+		symbolsMappings(args[1:])
+	case "specs":
+		symbolsSpecs(args[1:])
+	case "pin":
+		symbolsPin(args[1:])
+	case "unpin":
+		symbolsUnpin(args[1:])
+	case "tradeable":
+		symbolsTradeable(args[1:])
+	case "proposals":
+		symbolsProposals(args[1:])
+	case "diff":
+		symbolsDiff(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando symbols desconocido: %s\n", subcommand)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func symbolsMappings(args []string) {
+	fs := flag.NewFlagSet("symbols mappings", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta (account_id)")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	if *accountID == "" {
+		fmt.Fprintln(os.Stderr, "--account es requerido")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	result, err := internal.ListSymbolMappings(ctx, *accountID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error consultando mapeos: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		printJSON(result)
+		return
+	}
+
+	if len(result.Mappings) == 0 && len(result.Overrides) == 0 {
+		fmt.Printf("Sin mapeos para %s\n", *accountID)
+		return
+	}
+	overrides := make(map[string]*domain.SymbolMappingOverride, len(result.Overrides))
+	for _, override := range result.Overrides {
+		overrides[override.CanonicalSymbol] = override
+	}
+	lines := make([]string, 0, len(result.Mappings)+len(result.Overrides))
+	for _, mapping := range result.Mappings {
+		line := fmt.Sprintf("%s → %s  digits=%d  lots=%s..%s/%s  stop_level=%d",
+			mapping.CanonicalSymbol, mapping.BrokerSymbol, mapping.Digits,
+			formatFloat(mapping.MinLot), formatFloat(mapping.MaxLot), formatFloat(mapping.LotStep), mapping.StopLevel)
+		if mapping.ContractSize != nil {
+			line += "  contract=" + formatFloat(*mapping.ContractSize)
+		}
+		if mapping.Source != "" {
+			line += fmt.Sprintf("  source=%s(%s)", mapping.Source, formatFloat(mapping.Confidence))
+		}
+		if override, ok := overrides[mapping.CanonicalSymbol]; ok {
+			line += "  " + formatSymbolOverride(override)
+			delete(overrides, mapping.CanonicalSymbol)
+		}
+		lines = append(lines, line)
+	}
+	for _, override := range result.Overrides {
+		if _, pending := overrides[override.CanonicalSymbol]; pending {
+			lines = append(lines, fmt.Sprintf("%s → %s  sin mapeo persistido  %s", override.CanonicalSymbol, override.BrokerSymbol, formatSymbolOverride(override)))
+		}
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func formatSymbolOverride(override *domain.SymbolMappingOverride) string {
+	text := fmt.Sprintf("[pin %s por %s", time.UnixMilli(override.UpdatedAtMs).UTC().Format(time.RFC3339), override.UpdatedBy)
+	if override.Reason != "" {
+		text += ": " + override.Reason
+	}
+	return text + "]"
+}
+
+func symbolsSpecs(args []string) {
+	fs := flag.NewFlagSet("symbols specs", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta (account_id)")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	if *accountID == "" {
+		fmt.Fprintln(os.Stderr, "--account es requerido")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	specs, err := internal.ListSymbolSpecs(ctx, *accountID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error consultando especificaciones: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		printJSON(specs)
+		return
+	}
+
+	if len(specs) == 0 {
+		fmt.Printf("Sin especificaciones para %s\n", *accountID)
+		return
+	}
+	now := time.Now()
+	lines := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		lines = append(lines, fmt.Sprintf("%s (%s)  digits=%d  contract=%s  tick_value=%s  stop_level=%d  volume=%s..%s/%s  margin=%s  reportado=%s",
+			spec.CanonicalSymbol, spec.BrokerSymbol, spec.Digits, formatFloat(spec.ContractSize), formatFloat(spec.TickValue), spec.StopLevel,
+			formatFloat(spec.MinVolume), formatFloat(spec.MaxVolume), formatFloat(spec.VolumeStep), spec.MarginCurrency,
+			formatLastSeen(spec.ReportedAtMs, now)))
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func symbolsProposals(args []string) {
+	fs := flag.NewFlagSet("symbols proposals", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta (account_id)")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	if *accountID == "" {
+		fmt.Fprintln(os.Stderr, "--account es requerido")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	proposals, err := internal.ListSymbolProposals(ctx, *accountID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error consultando propuestas: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		printJSON(proposals)
+		return
+	}
+
+	if len(proposals) == 0 {
+		fmt.Printf("Sin propuestas para %s\n", *accountID)
+		return
+	}
+	now := time.Now()
+	lines := make([]string, 0, len(proposals))
+	for _, proposal := range proposals {
+		line := fmt.Sprintf("%s → %s  confianza=%s  regla=%s  propuesto=%s",
+			proposal.BrokerSymbol, proposal.CanonicalSymbol, formatFloat(proposal.Confidence), proposal.Rule,
+			formatLastSeen(proposal.ProposedAtMs, now))
+		if len(proposal.Checks) > 0 {
+			line += "  [" + strings.Join(proposal.Checks, ", ") + "]"
+		}
+		lines = append(lines, line)
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+	fmt.Printf("Aceptar: echo-core-cli symbols pin --account %s --canonical <sym> --broker <sym>\n", *accountID)
+}
+
+func symbolsPin(args []string) {
+	fs := flag.NewFlagSet("symbols pin", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta (account_id)")
+	canonical := fs.String("canonical", "", "Símbolo canónico (debe estar en la whitelist)")
+	broker := fs.String("broker", "", "Símbolo del broker a usar para el canónico")
+	author := fs.String("author", defaultPolicyAuthor(), "Autor del override")
+	reason := fs.String("reason", "", "Motivo del override")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la operación")
+	fs.Parse(args)
+
+	if *accountID == "" || *canonical == "" || *broker == "" {
+		fmt.Fprintln(os.Stderr, "--account, --canonical y --broker son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	mapping, err := internal.PinSymbolMapping(ctx, &domain.SymbolMappingOverride{
+		AccountID:       *accountID,
+		CanonicalSymbol: *canonical,
+		BrokerSymbol:    *broker,
+		Reason:          *reason,
+		UpdatedBy:       *author,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error fijando mapeo: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s: %s → %s fijado\n", *accountID, mapping.CanonicalSymbol, mapping.BrokerSymbol)
+	if mapping.MinLot == 0 {
+		fmt.Fprintf(os.Stderr, "aviso: el EA no reportó %s; el mapeo queda sin parámetros de volumen hasta su próximo reporte\n", mapping.BrokerSymbol)
+	}
+	fmt.Fprintln(os.Stderr, "Core en ejecución invalida el caché de la cuenta y relee el mapeo (NOTIFY en postgres, polling en sqlite)")
+}
+
+func symbolsUnpin(args []string) {
+	fs := flag.NewFlagSet("symbols unpin", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta (account_id)")
+	canonical := fs.String("canonical", "", "Símbolo canónico")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la operación")
+	fs.Parse(args)
+
+	if *accountID == "" || *canonical == "" {
+		fmt.Fprintln(os.Stderr, "--account y --canonical son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	deleted, err := internal.UnpinSymbolMapping(ctx, *accountID, *canonical)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error eliminando override: %v\n", err)
+		os.Exit(1)
+	}
+	if !deleted {
+		fmt.Fprintf(os.Stderr, "%s no tiene override para %s\n", *accountID, *canonical)
+		os.Exit(1)
+	}
+	fmt.Printf("Override de %s/%s eliminado; el EA vuelve a definir el mapeo en su próximo reporte\n", *accountID, *canonical)
+}
+
+func symbolsTradeable(args []string) {
+	fs := flag.NewFlagSet("symbols tradeable", flag.ExitOnError)
+	accountID := fs.String("account", "", "Cuenta (account_id)")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	if *accountID == "" {
+		fmt.Fprintln(os.Stderr, "--account es requerido")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	result, err := internal.ListTradeableSymbols(ctx, *accountID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error evaluando símbolos: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		printJSON(result)
+		return
+	}
+
+	status := result.HandshakeStatus
+	if status == "" {
+		status = "sin evaluar"
+	}
+	lines := []string{fmt.Sprintf("Cuenta %s  handshake=%s", result.AccountID, status)}
+	for _, symbol := range result.Symbols {
+		line := fmt.Sprintf("  %-12s", symbol.CanonicalSymbol)
+		if symbol.Tradeable {
+			line += "  OK  " + symbol.BrokerSymbol
+			if symbol.HandshakeStatus == "WARNING" {
+				line += "  (WARNING)"
+			}
+		} else {
+			line += "  --  " + symbol.Reason
+		}
+		if symbol.Pinned {
+			line += "  [pin]"
+		}
+		lines = append(lines, line)
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func symbolsDiff(args []string) {
+	fs := flag.NewFlagSet("symbols diff", flag.ExitOnError)
+	canonical := fs.String("canonical", "", "Símbolo canónico a comparar")
+	left := fs.String("left", "", "Primera cuenta (account_id)")
+	right := fs.String("right", "", "Segunda cuenta (account_id)")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout de la consulta")
+	jsonOutput := fs.Bool("json", false, "Imprimir el resultado en JSON")
+	fs.Parse(args)
+
+	if *canonical == "" || *left == "" || *right == "" {
+		fmt.Fprintln(os.Stderr, "--canonical, --left y --right son requeridos")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	diff, err := internal.DiffSymbolSpecs(ctx, *canonical, *left, *right)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error comparando especificaciones: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOutput {
+		printJSON(diff)
+		return
+	}
+
+	lines := []string{fmt.Sprintf("%s  %s vs %s", diff.CanonicalSymbol, diff.Left.AccountID, diff.Right.AccountID)}
+	for _, field := range diff.Fields {
+		marker := " "
+		if !field.Equal {
+			marker = "≠"
+		}
+		lines = append(lines, fmt.Sprintf("  %s %-16s %-14s %s", marker, field.Field, field.Left, field.Right))
+	}
+	fmt.Println(strings.Join(lines, "\n"))
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func printJSON(value interface{}) {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error serializando resultado: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(data))
+}
@@ -0,0 +1,269 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ownershipManager persiste el ownership de cuentas y resuelve sus conflictos (i8).
+//
+// AccountRegistry decide en memoria; el manager:
+//
+//   - Al arrancar restaura el ownership persistido (cuentas DETACHED con takeover_grace
+//     para que su owner reconecte antes que un Agent mal configurado).
+//   - Cada CheckInterval aplica los takeovers pedidos con echo-core-cli accounts takeover,
+//     promueve claimants cuyo owner dejó de estar vivo y persiste los cambios.
+type ownershipManager struct {
+	registry    *AccountRegistry
+	repo        domain.AccountOwnershipRepository
+	config      OwnershipConfig
+	telemetry   *telemetry.Client
+	echoMetrics *metricbundle.EchoMetrics
+
+	// onChanged se invoca cuando una cuenta cambia de owner (Kick del outbox)
+	onChanged func()
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newOwnershipManager(
+	parentCtx context.Context,
+	registry *AccountRegistry,
+	repo domain.AccountOwnershipRepository,
+	config OwnershipConfig,
+	telemetryClient *telemetry.Client,
+	echoMetrics *metricbundle.EchoMetrics,
+	onChanged func(),
+) *ownershipManager {
+	ctx, cancel := context.WithCancel(parentCtx)
+	return &ownershipManager{
+		registry:    registry,
+		repo:        repo,
+		config:      config,
+		telemetry:   telemetryClient,
+		echoMetrics: echoMetrics,
+		onChanged:   onChanged,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start restaura el ownership persistido y arranca el worker.
+//
+// Debe llamarse antes de aceptar streams de Agents.
+func (m *ownershipManager) Start() {
+	m.restore(m.ctx)
+
+	m.wg.Add(1)
+	go m.worker()
+}
+
+// Stop detiene el worker y persiste los cambios pendientes.
+func (m *ownershipManager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m.flush(ctx)
+}
+
+func (m *ownershipManager) worker() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+			m.applyTakeovers(m.ctx)
+			m.resolveClaims()
+			m.flush(m.ctx)
+		}
+	}
+}
+
+// restore carga el ownership persistido en el registry.
+func (m *ownershipManager) restore(ctx context.Context) {
+	if m.repo == nil {
+		return
+	}
+
+	accounts, err := m.repo.List(ctx)
+	if err != nil {
+		m.telemetry.Warn(ctx, "Failed to restore account ownership (i8)",
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+	restored := m.registry.Restore(accounts)
+	m.telemetry.Info(ctx, "Account ownership restored (i8)",
+		attribute.Int("accounts", restored),
+		attribute.String("takeover_grace", m.config.TakeoverGrace.String()),
+	)
+}
+
+// applyTakeovers aplica los takeovers pedidos por operadores.
+func (m *ownershipManager) applyTakeovers(ctx context.Context) {
+	if m.repo == nil {
+		return
+	}
+
+	accounts, err := m.repo.List(ctx)
+	if err != nil {
+		m.telemetry.Warn(ctx, "Failed to load forced takeovers (i8)",
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+
+	changed := false
+	for _, ownership := range accounts {
+		if ownership.ForcedAgentID == "" {
+			continue
+		}
+		result := m.registry.ForceOwner(ownership.AccountID, ownership.ForcedAgentID)
+		reportOwnershipResult(ctx, m.telemetry, m.echoMetrics, result)
+		changed = true
+
+		if err := m.repo.ClearTakeover(ctx, ownership.AccountID, ownership.ForcedAgentID); err != nil {
+			m.telemetry.Warn(ctx, "Failed to clear forced takeover (i8)",
+				attribute.String("account_id", ownership.AccountID),
+				attribute.String("error", err.Error()),
+			)
+		}
+	}
+	if changed && m.onChanged != nil {
+		m.onChanged()
+	}
+}
+
+// resolveClaims promueve claimants cuyo owner superó takeover_grace sin estar vivo.
+func (m *ownershipManager) resolveClaims() {
+	results := m.registry.ResolveClaims()
+	for _, result := range results {
+		reportOwnershipResult(m.ctx, m.telemetry, m.echoMetrics, result)
+	}
+	if len(results) > 0 && m.onChanged != nil {
+		m.onChanged()
+	}
+}
+
+// flush persiste los cambios de ownership desde la última escritura.
+func (m *ownershipManager) flush(ctx context.Context) {
+	if m.repo == nil {
+		return
+	}
+
+	upserts, deletes := m.registry.DrainOwnershipChanges()
+	for _, ownership := range upserts {
+		if err := m.repo.Upsert(ctx, ownership); err != nil {
+			m.telemetry.Warn(ctx, "Failed to persist account ownership (i8)",
+				attribute.String("account_id", ownership.AccountID),
+				attribute.String("error", err.Error()),
+			)
+			m.registry.MarkOwnershipDirty(ownership.AccountID)
+		}
+	}
+	for _, accountID := range deletes {
+		if err := m.repo.Delete(ctx, accountID); err != nil {
+			m.telemetry.Warn(ctx, "Failed to delete account ownership (i8)",
+				attribute.String("account_id", accountID),
+				attribute.String("error", err.Error()),
+			)
+			m.registry.MarkOwnershipDirty(accountID)
+		}
+	}
+}
+
+// reportOwnershipResult emite la alerta (log + métrica) de un conflicto o takeover.
+//
+// Los registros sin conflicto (registered/renewed) no se reportan.
+func reportOwnershipResult(ctx context.Context, tel *telemetry.Client, echoMetrics *metricbundle.EchoMetrics, result RegistrationResult) {
+	attrs := []attribute.KeyValue{
+		attribute.String("account_id", result.AccountID),
+		attribute.String("agent_id", result.AgentID),
+		attribute.String("previous_agent_id", result.PreviousAgentID),
+	}
+
+	switch result.Outcome {
+	case RegistrationQuarantined:
+		tel.Warn(ctx, "Account ownership conflict: claimant quarantined, owner still live (i8)", attrs...)
+	case RegistrationTakeover:
+		tel.Warn(ctx, "Account ownership taken over from inactive owner (i8)", attrs...)
+	case RegistrationForced:
+		tel.Warn(ctx, "Account ownership forced by operator (i8)", attrs...)
+	default:
+		return
+	}
+	echoMetrics.RecordAccountOwnershipEvent(ctx, string(result.Outcome),
+		attribute.String("account_id", result.AccountID),
+	)
+}
+
+// ListAccountOwnership carga la configuración desde ETCD y retorna el ownership
+// persistido de cada cuenta (i8).
+//
+// Lo usa echo-core-cli accounts sin inicializar Core.
+func ListAccountOwnership(ctx context.Context) ([]*domain.AccountOwnership, error) {
+	config, err := LoadConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config from ETCD: %w", err)
+	}
+	if config.StorageBackend == StorageBackendMemory {
+		return nil, fmt.Errorf("storage/backend %q does not persist account ownership", config.StorageBackend)
+	}
+
+	db, factory, err := newRepositoryFactory(ctx, config, time.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(db)
+
+	accounts, err := factory.AccountOwnershipRepository().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list account ownership: %w", err)
+	}
+	return accounts, nil
+}
+
+// RequestAccountTakeover pide al Core asignar accountID a agentID (i8).
+//
+// El Core aplica el pedido en su próximo core/ownership/check_interval_ms, aunque
+// el owner actual siga vivo.
+func RequestAccountTakeover(ctx context.Context, accountID, agentID string) error {
+	if accountID == "" || agentID == "" {
+		return fmt.Errorf("account_id and agent_id are required")
+	}
+
+	config, err := LoadConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load config from ETCD: %w", err)
+	}
+	if config.StorageBackend == StorageBackendMemory {
+		return fmt.Errorf("storage/backend %q does not persist account ownership", config.StorageBackend)
+	}
+
+	db, factory, err := newRepositoryFactory(ctx, config, time.Now)
+	if err != nil {
+		return err
+	}
+	defer closeDB(db)
+
+	if err := factory.AccountOwnershipRepository().RequestTakeover(ctx, accountID, agentID, time.Now().UnixMilli()); err != nil {
+		return fmt.Errorf("failed to request takeover: %w", err)
+	}
+	return nil
+}
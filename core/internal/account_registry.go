@@ -4,6 +4,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/xKoRx/echo/sdk/domain"
 	"github.com/xKoRx/echo/sdk/telemetry"
 	"go.opentelemetry.io/otel/attribute"
 )
@@ -11,19 +12,29 @@ import (
 // AccountRegistry mantiene el mapeo de cuentas a Agents (estado OPERACIONAL).
 //
 // Thread-safe. Operaciones:
-//   - RegisterAccount: registra UNA cuenta para un Agent (i2 dinámico).
+//   - RegisterAccount: registra UNA cuenta para un Agent (i2 dinámico); un segundo
+//     Agent queda en cuarentena mientras el owner siga vivo (i8).
 //   - UnregisterAccount: desregistra UNA cuenta (i2 dinámico).
-//   - UnregisterAgent: elimina TODAS las cuentas de un Agent (cleanup al desconectar).
+//   - UnregisterAgent: marca DETACHED las cuentas de un Agent al desconectar (i8).
+//   - ResolveClaims / ForceOwner: takeover tras takeover_grace o por operador (i8).
 //   - GetOwner: retorna el Agent propietario de una cuenta.
 //   - GetAccountsByAgent: retorna todas las cuentas de un Agent (diagnóstico).
+//   - MarkAgentStale / ApplyHeartbeat: excluyen del routing (sin desregistrar) las
+//     cuentas de un Agent sin heartbeat o con el EA desconectado (i8).
 type AccountRegistry struct {
 	// account_id → OwnershipRecord
 	accountToOwner map[string]*OwnershipRecord
 	// agent_id → []account_id (índice inverso para cleanup)
 	agentToAccounts map[string][]string
 
+	// i8: cuentas con cambios pendientes de persistir (ownershipManager)
+	dirty map[string]struct{}
+	// i8: tiempo que un owner sin stream/heartbeat conserva la cuenta frente a otro Agent
+	takeoverGrace time.Duration
+
 	mu        sync.RWMutex
 	telemetry *telemetry.Client
+	now       func() time.Time
 }
 
 // OwnershipRecord registra ownership de una cuenta (i2).
@@ -31,53 +42,100 @@ type OwnershipRecord struct {
 	AgentID      string
 	AccountID    string
 	RegisteredAt time.Time
-	LastSeenAt   time.Time // actualizado en cada heartbeat (opcional i3+)
+	LastSeenAt   time.Time // actualizado en cada heartbeat (i8)
 	PipeRole     string
+	Stale        bool      // i8: sin heartbeat o EA desconectado; GetOwner la excluye del routing
+	StaleSince   time.Time // i8: cero si no está stale
+
+	Detached   bool      // i8: stream del owner cerrado (o Core reiniciado); GetOwner la excluye
+	DetachedAt time.Time // i8: cero si el stream está abierto
+
+	ClaimantAgentID string    // i8: Agent en cuarentena que reclamó la cuenta ("" sin conflicto)
+	ConflictAt      time.Time // i8: primer reclamo del claimant actual
+}
+
+// RegistrationOutcome resultado de un cambio de ownership (i8).
+type RegistrationOutcome string
+
+const (
+	RegistrationRegistered  RegistrationOutcome = "registered"  // cuenta sin owner
+	RegistrationRenewed     RegistrationOutcome = "renewed"     // mismo Agent (reconexión)
+	RegistrationQuarantined RegistrationOutcome = "quarantined" // owner vivo: el claimant queda en cuarentena
+	RegistrationTakeover    RegistrationOutcome = "takeover"    // owner sin stream/heartbeat más de takeover_grace
+	RegistrationForced      RegistrationOutcome = "forced"      // takeover pedido por operador
+)
+
+// RegistrationResult describe el resultado de RegisterAccount, ResolveClaims y ForceOwner (i8).
+type RegistrationResult struct {
+	AccountID       string
+	AgentID         string // Agent que reclamó la cuenta
+	Outcome         RegistrationOutcome
+	PreviousAgentID string // owner anterior (takeover/forced) u owner vigente (quarantined)
 }
 
 // NewAccountRegistry crea un nuevo registry.
-func NewAccountRegistry(tel *telemetry.Client) *AccountRegistry {
+//
+// takeoverGrace: tiempo que un owner sin stream o sin heartbeat conserva la cuenta
+// frente a otro Agent que la reclama (i8).
+func NewAccountRegistry(tel *telemetry.Client, takeoverGrace time.Duration) *AccountRegistry {
 	return &AccountRegistry{
 		accountToOwner:  make(map[string]*OwnershipRecord),
 		agentToAccounts: make(map[string][]string),
+		dirty:           make(map[string]struct{}),
+		takeoverGrace:   takeoverGrace,
 		telemetry:       tel,
+		now:             time.Now,
 	}
 }
 
 // RegisterAccount registra UNA cuenta para un Agent (i2 dinámico).
 //
-// Si la cuenta ya está registrada a OTRO Agent, sobreescribe (last-write-wins).
-// Log WARNING si hay cambio de ownership.
-func (r *AccountRegistry) RegisterAccount(agentID string, accountID string, pipeRole string) {
+// i8: si la cuenta pertenece a OTRO Agent, el owner la conserva mientras siga vivo
+// (stream abierto y heartbeats) o dentro de takeoverGrace: el claimant queda en
+// cuarentena y ResolveClaims lo promueve cuando el owner deja de estar vivo.
+func (r *AccountRegistry) RegisterAccount(agentID string, accountID string, pipeRole string) RegistrationResult {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	now := time.Now()
+	now := r.now()
+	result := RegistrationResult{AccountID: accountID, AgentID: agentID}
 
 	// Verificar si ya existe
 	if existing, exists := r.accountToOwner[accountID]; exists {
 		if existing.AgentID != agentID {
-			// Conflicto de ownership: la cuenta estaba en otro Agent
-			r.telemetry.Warn(nil, "Account ownership conflict (i2)",
-				attribute.String("account_id", accountID),
-				attribute.String("previous_agent", existing.AgentID),
-				attribute.String("new_agent", agentID),
-			)
-
-			// Limpiar del agente anterior
-			r.removeAccountFromAgent(existing.AgentID, accountID)
-		} else {
-			// Mismo agente, actualizar timestamp (re-registro, posible reconexión)
-			existing.LastSeenAt = now
-			if pipeRole != "" {
-				existing.PipeRole = pipeRole
+			result.PreviousAgentID = existing.AgentID
+			if !r.canTakeOver(existing, now) {
+				// i8: owner vivo, el claimant queda en cuarentena
+				if existing.ClaimantAgentID != agentID {
+					existing.ClaimantAgentID = agentID
+					existing.ConflictAt = now
+					r.dirty[accountID] = struct{}{}
+				}
+				result.Outcome = RegistrationQuarantined
+				return result
 			}
-			r.telemetry.Info(nil, "Account re-registered to same Agent (i2)",
-				attribute.String("account_id", accountID),
-				attribute.String("agent_id", agentID),
-			)
-			return
+
+			r.assignOwner(existing, agentID, pipeRole, now)
+			result.Outcome = RegistrationTakeover
+			return result
+		}
+
+		// Mismo agente, actualizar timestamp (re-registro, posible reconexión)
+		existing.LastSeenAt = now
+		existing.Stale = false
+		existing.StaleSince = time.Time{}
+		existing.Detached = false
+		existing.DetachedAt = time.Time{}
+		if pipeRole != "" {
+			existing.PipeRole = pipeRole
 		}
+		r.dirty[accountID] = struct{}{}
+		r.telemetry.Info(nil, "Account re-registered to same Agent (i2)",
+			attribute.String("account_id", accountID),
+			attribute.String("agent_id", agentID),
+		)
+		result.Outcome = RegistrationRenewed
+		return result
 	}
 
 	// Registrar nueva cuenta
@@ -88,6 +146,7 @@ func (r *AccountRegistry) RegisterAccount(agentID string, accountID string, pipe
 		LastSeenAt:   now,
 		PipeRole:     pipeRole,
 	}
+	r.dirty[accountID] = struct{}{}
 
 	// Añadir a índice inverso
 	r.agentToAccounts[agentID] = append(r.agentToAccounts[agentID], accountID)
@@ -97,12 +156,16 @@ func (r *AccountRegistry) RegisterAccount(agentID string, accountID string, pipe
 		attribute.String("account_id", accountID),
 		attribute.String("pipe_role", pipeRole),
 	)
+	result.Outcome = RegistrationRegistered
+	return result
 }
 
 // UnregisterAccount desregistra UNA cuenta (i2 dinámico).
 //
-// Se llama cuando el Slave EA se desconecta del Agent.
-func (r *AccountRegistry) UnregisterAccount(accountID string) {
+// Se llama cuando el EA se desconecta del Agent. i8: solo el owner libera la cuenta
+// (released=true); si había un claimant en cuarentena, la cuenta pasa a él (promoted).
+// Un claimant que se desconecta solo retira su reclamo.
+func (r *AccountRegistry) UnregisterAccount(agentID string, accountID string) (released bool, promoted *RegistrationResult) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -111,13 +174,32 @@ func (r *AccountRegistry) UnregisterAccount(accountID string) {
 		r.telemetry.Warn(nil, "Attempted to unregister non-existent account (i2)",
 			attribute.String("account_id", accountID),
 		)
-		return
+		return false, nil
 	}
 
-	agentID := record.AgentID
+	if record.AgentID != agentID {
+		if record.ClaimantAgentID == agentID {
+			record.ClaimantAgentID = ""
+			record.ConflictAt = time.Time{}
+			r.dirty[accountID] = struct{}{}
+		}
+		return false, nil
+	}
+
+	// i8: el claimant en cuarentena tiene el EA conectado; hereda la cuenta
+	if claimant := record.ClaimantAgentID; claimant != "" {
+		r.assignOwner(record, claimant, record.PipeRole, r.now())
+		return true, &RegistrationResult{
+			AccountID:       accountID,
+			AgentID:         claimant,
+			Outcome:         RegistrationTakeover,
+			PreviousAgentID: agentID,
+		}
+	}
 
 	// Eliminar del mapa principal
 	delete(r.accountToOwner, accountID)
+	r.dirty[accountID] = struct{}{}
 
 	// Eliminar del índice inverso
 	r.removeAccountFromAgent(agentID, accountID)
@@ -126,45 +208,247 @@ func (r *AccountRegistry) UnregisterAccount(accountID string) {
 		attribute.String("agent_id", agentID),
 		attribute.String("account_id", accountID),
 	)
+	return true, nil
 }
 
-// UnregisterAgent elimina TODAS las cuentas de un Agent (i2 cleanup).
+// UnregisterAgent marca DETACHED las cuentas de un Agent (cleanup al desconectar).
 //
-// Se llama al desconectar el Agent del Core.
-func (r *AccountRegistry) UnregisterAgent(agentID string) {
+// i8: las cuentas conservan su owner (otro Agent puede tomarlas tras takeoverGrace)
+// y se retiran los reclamos del Agent en cuarentena. Retorna las cuentas detached.
+func (r *AccountRegistry) UnregisterAgent(agentID string) []string {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	accounts, exists := r.agentToAccounts[agentID]
+	now := r.now()
+	for accountID, record := range r.accountToOwner {
+		if record.ClaimantAgentID == agentID {
+			record.ClaimantAgentID = ""
+			record.ConflictAt = time.Time{}
+			r.dirty[accountID] = struct{}{}
+		}
+	}
+
+	var detached []string
+	for _, acc := range r.agentToAccounts[agentID] {
+		record := r.accountToOwner[acc]
+		if record == nil || record.Detached {
+			continue
+		}
+		record.Detached = true
+		record.DetachedAt = now
+		r.dirty[acc] = struct{}{}
+		detached = append(detached, acc)
+	}
+
+	if len(detached) > 0 {
+		r.telemetry.Info(nil, "Agent unregistered, accounts detached (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.Int("accounts_count", len(detached)),
+		)
+	}
+	return detached
+}
+
+// ResolveClaims promueve los claimants en cuarentena cuyo owner dejó de estar vivo
+// hace más de takeoverGrace (i8).
+func (r *AccountRegistry) ResolveClaims() []RegistrationResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	var results []RegistrationResult
+	for accountID, record := range r.accountToOwner {
+		claimant := record.ClaimantAgentID
+		if claimant == "" || !r.canTakeOver(record, now) {
+			continue
+		}
+		previous := record.AgentID
+		r.assignOwner(record, claimant, record.PipeRole, now)
+		results = append(results, RegistrationResult{
+			AccountID:       accountID,
+			AgentID:         claimant,
+			Outcome:         RegistrationTakeover,
+			PreviousAgentID: previous,
+		})
+	}
+	return results
+}
+
+// ForceOwner asigna la cuenta a agentID por pedido de un operador (i8).
+//
+// Si agentID era el claimant en cuarentena su stream está abierto y la cuenta se
+// rutea de inmediato; si no, queda DETACHED hasta su AccountConnected.
+func (r *AccountRegistry) ForceOwner(accountID string, agentID string) RegistrationResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	result := RegistrationResult{AccountID: accountID, AgentID: agentID, Outcome: RegistrationForced}
+
+	record, exists := r.accountToOwner[accountID]
 	if !exists {
-		return
+		record = &OwnershipRecord{AccountID: accountID}
+		r.accountToOwner[accountID] = record
 	}
+	result.PreviousAgentID = record.AgentID
+	attached := record.ClaimantAgentID == agentID || (record.AgentID == agentID && !record.Detached)
 
-	for _, acc := range accounts {
-		delete(r.accountToOwner, acc)
+	if record.AgentID != agentID {
+		r.assignOwner(record, agentID, record.PipeRole, now)
 	}
-	delete(r.agentToAccounts, agentID)
+	if !attached {
+		record.Detached = true
+		record.DetachedAt = now
+	}
+	r.dirty[accountID] = struct{}{}
+	return result
+}
 
-	r.telemetry.Info(nil, "Agent unregistered, all accounts released (i2)",
-		attribute.String("agent_id", agentID),
-		attribute.Int("accounts_count", len(accounts)),
-	)
+// Restore carga el ownership persistido al arrancar el Core (i8).
+//
+// Las cuentas quedan DETACHED desde now: su owner tiene takeoverGrace para reconectar
+// antes de que otro Agent pueda tomarlas. Los reclamos previos se descartan.
+func (r *AccountRegistry) Restore(accounts []*domain.AccountOwnership) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	restored := 0
+	for _, ownership := range accounts {
+		if ownership == nil || ownership.AccountID == "" || ownership.AgentID == "" {
+			continue
+		}
+		if _, exists := r.accountToOwner[ownership.AccountID]; exists {
+			continue
+		}
+		r.accountToOwner[ownership.AccountID] = &OwnershipRecord{
+			AgentID:      ownership.AgentID,
+			AccountID:    ownership.AccountID,
+			RegisteredAt: time.UnixMilli(ownership.RegisteredAtMs),
+			LastSeenAt:   time.UnixMilli(ownership.UpdatedAtMs),
+			PipeRole:     ownership.PipeRole,
+			Detached:     true,
+			DetachedAt:   now,
+		}
+		r.agentToAccounts[ownership.AgentID] = append(r.agentToAccounts[ownership.AgentID], ownership.AccountID)
+		r.dirty[ownership.AccountID] = struct{}{}
+		restored++
+	}
+	return restored
+}
+
+// ConflictingOwner indica si accountID pertenece a un Agent distinto de agentID (i8).
+//
+// Los mensajes de un claimant en cuarentena no deben procesarse para esa cuenta.
+func (r *AccountRegistry) ConflictingOwner(agentID string, accountID string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	record, found := r.accountToOwner[accountID]
+	if !found || record.AgentID == agentID {
+		return "", false
+	}
+	return record.AgentID, true
+}
+
+// DrainOwnershipChanges retorna los cambios pendientes de persistir (i8).
+//
+// upserts contiene el estado actual de las cuentas modificadas; deletes las liberadas.
+func (r *AccountRegistry) DrainOwnershipChanges() (upserts []*domain.AccountOwnership, deletes []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	nowMs := r.now().UnixMilli()
+	for accountID := range r.dirty {
+		record, exists := r.accountToOwner[accountID]
+		if !exists {
+			deletes = append(deletes, accountID)
+			continue
+		}
+		upserts = append(upserts, record.toDomain(nowMs))
+	}
+	r.dirty = make(map[string]struct{})
+	return upserts, deletes
+}
+
+// MarkOwnershipDirty reintenta la persistencia de una cuenta en el próximo flush (i8).
+func (r *AccountRegistry) MarkOwnershipDirty(accountID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dirty[accountID] = struct{}{}
 }
 
 // GetOwner retorna el Agent propietario de una cuenta.
 //
 // Retorna ("", false) si la cuenta no está registrada (o se desconectó).
+// i8: también si está stale o detached; GetRecord sigue exponiendo el owner para diagnóstico.
 func (r *AccountRegistry) GetOwner(accountID string) (string, bool) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	record, found := r.accountToOwner[accountID]
-	if !found {
+	if !found || record.Stale || record.Detached {
 		return "", false
 	}
 	return record.AgentID, true
 }
 
+// MarkAgentStale excluye del routing todas las cuentas de un Agent sin heartbeat (i8).
+//
+// Las cuentas siguen registradas: el siguiente heartbeat o AccountConnected las
+// restaura. Retorna las cuentas que pasaron a stale.
+func (r *AccountRegistry) MarkAgentStale(agentID string, now time.Time) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var staled []string
+	for _, acc := range r.agentToAccounts[agentID] {
+		record := r.accountToOwner[acc]
+		if record == nil || record.Stale {
+			continue
+		}
+		record.Stale = true
+		record.StaleSince = now
+		staled = append(staled, acc)
+	}
+	return staled
+}
+
+// ApplyHeartbeat actualiza las cuentas de un Agent con su heartbeat (i8).
+//
+// connected indica por account_id si el EA está conectado a su pipe. Las cuentas
+// no reportadas se consideran vivas (el Agent no informa estado por pipe).
+// Retorna las cuentas que pasaron a stale (EA desconectado) y las restauradas.
+func (r *AccountRegistry) ApplyHeartbeat(agentID string, connected map[string]bool, now time.Time) (staled []string, restored []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, acc := range r.agentToAccounts[agentID] {
+		record := r.accountToOwner[acc]
+		if record == nil {
+			continue
+		}
+
+		alive, reported := connected[acc]
+		if reported && !alive {
+			if !record.Stale {
+				record.Stale = true
+				record.StaleSince = now
+				staled = append(staled, acc)
+			}
+			continue
+		}
+
+		record.LastSeenAt = now
+		if record.Stale {
+			record.Stale = false
+			record.StaleSince = time.Time{}
+			restored = append(restored, acc)
+		}
+	}
+	return staled, restored
+}
+
 // GetRecord retorna una copia del OwnershipRecord para la cuenta.
 func (r *AccountRegistry) GetRecord(accountID string) (OwnershipRecord, bool) {
 	r.mu.RLock()
@@ -197,6 +481,65 @@ func (r *AccountRegistry) GetStats() (totalAccounts int, totalAgents int) {
 	return len(r.accountToOwner), len(r.agentToAccounts)
 }
 
+// canTakeOver indica si otro Agent puede tomar la cuenta: el owner lleva más de
+// takeoverGrace sin stream (detached) o sin heartbeat (stale).
+//
+// DEBE llamarse con lock ya adquirido.
+func (r *AccountRegistry) canTakeOver(record *OwnershipRecord, now time.Time) bool {
+	var since time.Time
+	switch {
+	case record.Detached:
+		since = record.DetachedAt
+	case record.Stale:
+		since = record.StaleSince
+	default:
+		return false
+	}
+	return now.Sub(since) >= r.takeoverGrace
+}
+
+// assignOwner transfiere la cuenta a agentID (owner activo) y limpia el conflicto.
+//
+// DEBE llamarse con lock ya adquirido.
+func (r *AccountRegistry) assignOwner(record *OwnershipRecord, agentID string, pipeRole string, now time.Time) {
+	if record.AgentID != "" {
+		r.removeAccountFromAgent(record.AgentID, record.AccountID)
+	}
+	record.AgentID = agentID
+	record.PipeRole = pipeRole
+	record.RegisteredAt = now
+	record.LastSeenAt = now
+	record.Stale = false
+	record.StaleSince = time.Time{}
+	record.Detached = false
+	record.DetachedAt = time.Time{}
+	record.ClaimantAgentID = ""
+	record.ConflictAt = time.Time{}
+	r.agentToAccounts[agentID] = append(r.agentToAccounts[agentID], record.AccountID)
+	r.dirty[record.AccountID] = struct{}{}
+}
+
+// toDomain convierte el registro al modelo persistido (i8).
+func (record *OwnershipRecord) toDomain(nowMs int64) *domain.AccountOwnership {
+	status := domain.OwnershipStatusActive
+	if record.Detached {
+		status = domain.OwnershipStatusDetached
+	}
+	ownership := &domain.AccountOwnership{
+		AccountID:       record.AccountID,
+		AgentID:         record.AgentID,
+		PipeRole:        record.PipeRole,
+		Status:          status,
+		RegisteredAtMs:  record.RegisteredAt.UnixMilli(),
+		UpdatedAtMs:     nowMs,
+		ClaimantAgentID: record.ClaimantAgentID,
+	}
+	if !record.ConflictAt.IsZero() {
+		ownership.ConflictAtMs = record.ConflictAt.UnixMilli()
+	}
+	return ownership
+}
+
 // removeAccountFromAgent elimina una cuenta del índice inverso (helper interno).
 //
 // DEBE llamarse con lock ya adquirido.
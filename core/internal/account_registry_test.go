@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/telemetry"
+)
+
+func newTestAccountRegistry(grace time.Duration) (*AccountRegistry, *time.Time) {
+	now := time.Unix(1700000000, 0)
+	registry := NewAccountRegistry(&telemetry.Client{}, grace)
+	registry.now = func() time.Time { return now }
+	return registry, &now
+}
+
+func TestAccountRegistryQuarantinesClaimantWhileOwnerLive(t *testing.T) {
+	registry, _ := newTestAccountRegistry(30 * time.Second)
+
+	assert.Equal(t, RegistrationRegistered, registry.RegisterAccount("agent-1", "s1", "slave").Outcome)
+	assert.Equal(t, RegistrationRenewed, registry.RegisterAccount("agent-1", "s1", "slave").Outcome)
+
+	result := registry.RegisterAccount("agent-2", "s1", "slave")
+	assert.Equal(t, RegistrationQuarantined, result.Outcome)
+	assert.Equal(t, "agent-1", result.PreviousAgentID)
+
+	owner, ok := registry.GetOwner("s1")
+	require.True(t, ok)
+	assert.Equal(t, "agent-1", owner)
+	record, _ := registry.GetRecord("s1")
+	assert.Equal(t, "agent-2", record.ClaimantAgentID)
+
+	owner, conflict := registry.ConflictingOwner("agent-2", "s1")
+	assert.True(t, conflict)
+	assert.Equal(t, "agent-1", owner)
+	_, conflict = registry.ConflictingOwner("agent-1", "s1")
+	assert.False(t, conflict)
+
+	// Owner vivo: el claimant no se promueve
+	assert.Empty(t, registry.ResolveClaims())
+
+	// El claimant que se desconecta solo retira su reclamo
+	released, promoted := registry.UnregisterAccount("agent-2", "s1")
+	assert.False(t, released)
+	assert.Nil(t, promoted)
+	record, _ = registry.GetRecord("s1")
+	assert.Empty(t, record.ClaimantAgentID)
+}
+
+func TestAccountRegistryTakeoverAfterGrace(t *testing.T) {
+	registry, now := newTestAccountRegistry(30 * time.Second)
+
+	registry.RegisterAccount("agent-1", "s1", "slave")
+	registry.RegisterAccount("agent-1", "s2", "slave")
+	assert.ElementsMatch(t, []string{"s1", "s2"}, registry.UnregisterAgent("agent-1"))
+
+	_, ok := registry.GetOwner("s1")
+	assert.False(t, ok, "detached accounts are not routed")
+
+	// Dentro del grace el owner conserva la cuenta
+	*now = now.Add(10 * time.Second)
+	assert.Equal(t, RegistrationQuarantined, registry.RegisterAccount("agent-2", "s1", "slave").Outcome)
+
+	// El owner reconecta a tiempo
+	assert.Equal(t, RegistrationRenewed, registry.RegisterAccount("agent-1", "s2", "slave").Outcome)
+	owner, ok := registry.GetOwner("s2")
+	require.True(t, ok)
+	assert.Equal(t, "agent-1", owner)
+
+	// Vencido el grace el claimant en cuarentena se promueve
+	*now = now.Add(30 * time.Second)
+	results := registry.ResolveClaims()
+	require.Len(t, results, 1)
+	assert.Equal(t, RegistrationTakeover, results[0].Outcome)
+	assert.Equal(t, "agent-2", results[0].AgentID)
+	assert.Equal(t, "agent-1", results[0].PreviousAgentID)
+
+	owner, ok = registry.GetOwner("s1")
+	require.True(t, ok)
+	assert.Equal(t, "agent-2", owner)
+	assert.Equal(t, []string{"s2"}, registry.GetAccountsByAgent("agent-1"))
+}
+
+func TestAccountRegistryOwnerReleasePromotesClaimant(t *testing.T) {
+	registry, _ := newTestAccountRegistry(time.Minute)
+
+	registry.RegisterAccount("agent-1", "s1", "slave")
+	registry.RegisterAccount("agent-2", "s1", "slave")
+
+	released, promoted := registry.UnregisterAccount("agent-1", "s1")
+	assert.True(t, released)
+	require.NotNil(t, promoted)
+	assert.Equal(t, "agent-2", promoted.AgentID)
+
+	owner, ok := registry.GetOwner("s1")
+	require.True(t, ok)
+	assert.Equal(t, "agent-2", owner)
+
+	released, promoted = registry.UnregisterAccount("agent-2", "s1")
+	assert.True(t, released)
+	assert.Nil(t, promoted)
+	_, found := registry.GetRecord("s1")
+	assert.False(t, found)
+}
+
+func TestAccountRegistryForceOwner(t *testing.T) {
+	registry, _ := newTestAccountRegistry(time.Hour)
+
+	registry.RegisterAccount("agent-1", "s1", "slave")
+	registry.RegisterAccount("agent-2", "s1", "slave")
+
+	// El claimant tiene stream abierto: se rutea de inmediato
+	result := registry.ForceOwner("s1", "agent-2")
+	assert.Equal(t, RegistrationForced, result.Outcome)
+	assert.Equal(t, "agent-1", result.PreviousAgentID)
+	owner, ok := registry.GetOwner("s1")
+	require.True(t, ok)
+	assert.Equal(t, "agent-2", owner)
+
+	// Un Agent que aún no reportó la cuenta queda DETACHED hasta su AccountConnected
+	registry.ForceOwner("s2", "agent-3")
+	_, ok = registry.GetOwner("s2")
+	assert.False(t, ok)
+	assert.Equal(t, RegistrationRenewed, registry.RegisterAccount("agent-3", "s2", "slave").Outcome)
+	owner, ok = registry.GetOwner("s2")
+	require.True(t, ok)
+	assert.Equal(t, "agent-3", owner)
+}
+
+func TestAccountRegistryRestoreAndDrain(t *testing.T) {
+	registry, now := newTestAccountRegistry(30 * time.Second)
+
+	restored := registry.Restore([]*domain.AccountOwnership{
+		{AccountID: "s1", AgentID: "agent-1", PipeRole: "slave", Status: domain.OwnershipStatusActive, RegisteredAtMs: 1000, UpdatedAtMs: 2000},
+		{AccountID: "", AgentID: "agent-1"},
+	})
+	assert.Equal(t, 1, restored)
+
+	// Tras un reinicio el owner persistido tiene prioridad durante el grace
+	assert.Equal(t, RegistrationQuarantined, registry.RegisterAccount("agent-2", "s1", "slave").Outcome)
+
+	upserts, deletes := registry.DrainOwnershipChanges()
+	assert.Empty(t, deletes)
+	require.Len(t, upserts, 1)
+	assert.Equal(t, domain.OwnershipStatusDetached, upserts[0].Status)
+	assert.Equal(t, "agent-1", upserts[0].AgentID)
+	assert.Equal(t, "agent-2", upserts[0].ClaimantAgentID)
+	assert.Equal(t, now.UnixMilli(), upserts[0].ConflictAtMs)
+	assert.Equal(t, int64(1000), upserts[0].RegisteredAtMs)
+
+	upserts, deletes = registry.DrainOwnershipChanges()
+	assert.Empty(t, upserts)
+	assert.Empty(t, deletes)
+
+	// Liberar la cuenta la elimina del store
+	registry.RegisterAccount("agent-1", "s1", "slave")
+	registry.UnregisterAccount("agent-2", "s1")
+	registry.UnregisterAccount("agent-1", "s1")
+	upserts, deletes = registry.DrainOwnershipChanges()
+	assert.Empty(t, upserts)
+	assert.Equal(t, []string{"s1"}, deletes)
+}
+
+func TestAccountRegistryStaleAccountsExcludedFromRouting(t *testing.T) {
+	registry, now := newTestAccountRegistry(30 * time.Second)
+	registry.RegisterAccount("agent-1", "s1", "slave")
+	registry.RegisterAccount("agent-1", "s2", "slave")
+	registry.RegisterAccount("agent-2", "s3", "slave")
+
+	// Sin heartbeat: todas las cuentas del Agent quedan fuera del routing
+	assert.ElementsMatch(t, []string{"s1", "s2"}, registry.MarkAgentStale("agent-1", *now))
+	assert.Empty(t, registry.MarkAgentStale("agent-1", *now), "already stale")
+	_, ok := registry.GetOwner("s1")
+	assert.False(t, ok)
+	_, ok = registry.GetOwner("s2")
+	assert.False(t, ok)
+	owner, ok := registry.GetOwner("s3")
+	require.True(t, ok)
+	assert.Equal(t, "agent-2", owner)
+
+	// GetRecord sigue exponiendo el owner para diagnóstico
+	record, found := registry.GetRecord("s1")
+	require.True(t, found)
+	assert.Equal(t, "agent-1", record.AgentID)
+	assert.True(t, record.Stale)
+	assert.Equal(t, *now, record.StaleSince)
+
+	// El siguiente heartbeat restaura las cuentas vivas; s2 reporta el EA desconectado
+	*now = now.Add(5 * time.Second)
+	staled, restored := registry.ApplyHeartbeat("agent-1", map[string]bool{"s1": true, "s2": false}, *now)
+	assert.Empty(t, staled)
+	assert.Equal(t, []string{"s1"}, restored)
+	owner, ok = registry.GetOwner("s1")
+	require.True(t, ok)
+	assert.Equal(t, "agent-1", owner)
+	_, ok = registry.GetOwner("s2")
+	assert.False(t, ok)
+
+	// Una cuenta no reportada se considera viva
+	staled, restored = registry.ApplyHeartbeat("agent-1", map[string]bool{"s1": false}, *now)
+	assert.Equal(t, []string{"s1"}, staled)
+	assert.Equal(t, []string{"s2"}, restored)
+	_, ok = registry.GetOwner("s1")
+	assert.False(t, ok)
+	_, ok = registry.GetOwner("s2")
+	assert.True(t, ok)
+}
@@ -0,0 +1,557 @@
+package internal
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultAdminTradesLimit = 50
+	maxAdminTradesLimit     = 500
+)
+
+// adminService implementa AdminService: consultas y acciones operacionales (i8).
+//
+// Se registra en el mismo grpc.Server que AgentService, por lo que hereda TLS/mTLS
+// y los interceptors de identidad. unaryInterceptor y streamInterceptor agregan la
+// autorización de core/security/admin_identities, auditoría y métricas.
+type adminService struct {
+	pb.UnimplementedAdminServiceServer
+
+	core *Core
+}
+
+func newAdminService(core *Core) *adminService {
+	return &adminService{core: core}
+}
+
+// unaryInterceptor autoriza y audita las llamadas a AdminService.
+//
+// Debe encadenarse después de AgentIdentityUnaryServerInterceptor. Las llamadas a
+// otros servicios pasan sin cambios.
+func (s *adminService) unaryInterceptor() grpc.UnaryServerInterceptor {
+	prefix := "/" + pb.AdminService_ServiceDesc.ServiceName + "/"
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !strings.HasPrefix(info.FullMethod, prefix) {
+			return handler(ctx, req)
+		}
+		method := strings.TrimPrefix(info.FullMethod, prefix)
+		identity := adminIdentity(ctx)
+		if err := s.authorize(method, identity); err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(ctx, req)
+		s.audit(method, identity, err)
+		return resp, err
+	}
+}
+
+// streamInterceptor equivalente a unaryInterceptor para los RPC streaming (WatchEvents).
+//
+// Debe encadenarse después de AgentIdentityStreamServerInterceptor.
+func (s *adminService) streamInterceptor() grpc.StreamServerInterceptor {
+	prefix := "/" + pb.AdminService_ServiceDesc.ServiceName + "/"
+
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasPrefix(info.FullMethod, prefix) {
+			return handler(srv, ss)
+		}
+		method := strings.TrimPrefix(info.FullMethod, prefix)
+		identity := adminIdentity(ss.Context())
+		if err := s.authorize(method, identity); err != nil {
+			return err
+		}
+
+		err := handler(srv, ss)
+		s.audit(method, identity, err)
+		return err
+	}
+}
+
+// authorize aplica core/security/admin_identities.
+func (s *adminService) authorize(method, identity string) error {
+	if s.core.config.Security.AdminAllowed(identity) {
+		return nil
+	}
+	s.core.telemetry.Warn(s.core.ctx, "Admin request denied (i8)",
+		attribute.String("method", method),
+		attribute.String("identity", identity),
+	)
+	s.core.echoMetrics.RecordAdminRequest(s.core.ctx, method, "denied")
+	return status.Error(codes.PermissionDenied, "identity not allowed to use AdminService")
+}
+
+// audit registra log y métrica de una llamada autorizada.
+func (s *adminService) audit(method, identity string, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	s.core.telemetry.Info(s.core.ctx, "Admin request (i8)",
+		attribute.String("method", method),
+		attribute.String("identity", identity),
+		attribute.String("result", result),
+	)
+	s.core.echoMetrics.RecordAdminRequest(s.core.ctx, method, result)
+}
+
+// adminIdentity retorna la identidad autenticada de la llamada ("" sin identidad).
+func adminIdentity(ctx context.Context) string {
+	if identity, ok := grpcSDK.IdentityFromContext(ctx); ok {
+		return identity.AgentID
+	}
+	return ""
+}
+
+// ListAgents lista los Agents con stream abierto y las cuentas que poseen.
+func (s *adminService) ListAgents(ctx context.Context, req *pb.ListAgentsRequest) (*pb.ListAgentsResponse, error) {
+	agents := s.core.GetAgents()
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].AgentID < agents[j].AgentID
+	})
+
+	resp := &pb.ListAgentsResponse{Agents: make([]*pb.AdminAgent, 0, len(agents))}
+	for _, agent := range agents {
+		adminAgent := &pb.AdminAgent{
+			AgentId:         agent.AgentID,
+			ConnectedAtMs:   agent.createdAt.UnixMilli(),
+			ReliableSession: agent.session != nil,
+		}
+		accounts := s.core.accountRegistry.GetAccountsByAgent(agent.AgentID)
+		sort.Strings(accounts)
+		for _, accountID := range accounts {
+			if record, ok := s.core.accountRegistry.GetRecord(accountID); ok {
+				adminAgent.Accounts = append(adminAgent.Accounts, ownershipRecordToProto(record))
+			}
+		}
+		resp.Agents = append(resp.Agents, adminAgent)
+	}
+	return resp, nil
+}
+
+// GetAccountState retorna ownership, copia, estado y status de handshake de una cuenta.
+func (s *adminService) GetAccountState(ctx context.Context, req *pb.GetAccountStateRequest) (*pb.AccountState, error) {
+	if req.AccountId == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+
+	state := &pb.AccountState{
+		AccountId:       req.AccountId,
+		Copy:            copyControlToProto(s.core.copyControl.Get(req.AccountId)),
+		HandshakeStatus: s.core.handshakeRegistry.Status(req.AccountId).ToProto(),
+	}
+	if record, ok := s.core.accountRegistry.GetRecord(req.AccountId); ok {
+		state.Ownership = ownershipRecordToProto(record)
+	}
+	if info, ok := s.core.accountStateService.Get(req.AccountId); ok {
+		state.Info = info
+	}
+	for _, slaveAccountID := range s.core.config.SlaveAccounts {
+		if slaveAccountID == req.AccountId {
+			state.ConfiguredSlave = true
+			break
+		}
+	}
+	return state, nil
+}
+
+// ListSymbolMappings retorna los mapeos canónico → broker de la cuenta.
+func (s *adminService) ListSymbolMappings(ctx context.Context, req *pb.ListSymbolMappingsRequest) (*pb.ListSymbolMappingsResponse, error) {
+	if req.AccountId == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+
+	mappings, err := s.core.symbolResolver.ListMappings(ctx, req.AccountId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list symbol mappings: %v", err)
+	}
+	sort.Slice(mappings, func(i, j int) bool {
+		return mappings[i].CanonicalSymbol < mappings[j].CanonicalSymbol
+	})
+
+	resp := &pb.ListSymbolMappingsResponse{Mappings: make([]*pb.SymbolMapping, 0, len(mappings))}
+	for _, mapping := range mappings {
+		resp.Mappings = append(resp.Mappings, &pb.SymbolMapping{
+			CanonicalSymbol: mapping.CanonicalSymbol,
+			BrokerSymbol:    mapping.BrokerSymbol,
+			Digits:          mapping.Digits,
+			Point:           mapping.Point,
+			TickSize:        mapping.TickSize,
+			MinLot:          mapping.MinLot,
+			MaxLot:          mapping.MaxLot,
+			LotStep:         mapping.LotStep,
+			StopLevel:       mapping.StopLevel,
+			ContractSize:    mapping.ContractSize,
+			BaseCurrency:    mapping.BaseCurrency,
+			ProfitCurrency:  mapping.ProfitCurrency,
+			Source:          string(mapping.Source),
+			Confidence:      mapping.Confidence,
+		})
+	}
+	return resp, nil
+}
+
+// GetSymbolSpecs retorna las especificaciones en caché de la cuenta.
+func (s *adminService) GetSymbolSpecs(ctx context.Context, req *pb.GetSymbolSpecsRequest) (*pb.GetSymbolSpecsResponse, error) {
+	symbols, err := s.accountSymbols(ctx, req.AccountId, req.Symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetSymbolSpecsResponse{}
+	for _, canonical := range symbols {
+		spec, _, ok := s.core.symbolSpecService.GetSpecification(ctx, req.AccountId, canonical)
+		if !ok {
+			resp.Missing = append(resp.Missing, canonical)
+			continue
+		}
+		resp.Specs = append(resp.Specs, spec)
+	}
+	return resp, nil
+}
+
+// GetSymbolQuotes retorna los últimos quotes en caché de la cuenta.
+func (s *adminService) GetSymbolQuotes(ctx context.Context, req *pb.GetSymbolQuotesRequest) (*pb.GetSymbolQuotesResponse, error) {
+	symbols, err := s.accountSymbols(ctx, req.AccountId, req.Symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &pb.GetSymbolQuotesResponse{}
+	for _, canonical := range symbols {
+		quote, ok := s.core.symbolQuoteService.Get(req.AccountId, canonical)
+		if !ok {
+			resp.Missing = append(resp.Missing, canonical)
+			continue
+		}
+		resp.Quotes = append(resp.Quotes, quote)
+	}
+	return resp, nil
+}
+
+// accountSymbols resuelve los canónicos pedidos (vacío = todos los mapeados de la cuenta).
+func (s *adminService) accountSymbols(ctx context.Context, accountID string, requested []string) ([]string, error) {
+	if accountID == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+	if len(requested) > 0 {
+		symbols := make([]string, 0, len(requested))
+		for _, symbol := range requested {
+			if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+				symbols = append(symbols, symbol)
+			}
+		}
+		return symbols, nil
+	}
+
+	mappings, err := s.core.symbolResolver.ListMappings(ctx, accountID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list symbol mappings: %v", err)
+	}
+	symbols := make([]string, 0, len(mappings))
+	for _, mapping := range mappings {
+		symbols = append(symbols, mapping.CanonicalSymbol)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+// GetHandshakeStatus retorna la última evaluación de handshake de la cuenta.
+func (s *adminService) GetHandshakeStatus(ctx context.Context, req *pb.GetHandshakeStatusRequest) (*pb.SymbolRegistrationResult, error) {
+	if req.AccountId == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+
+	evaluation, ok := s.core.handshakeRegistry.Get(req.AccountId)
+	if !ok || evaluation == nil {
+		return nil, status.Errorf(codes.NotFound, "no handshake evaluation for account %s", req.AccountId)
+	}
+	return evaluation.ToProtoResult(), nil
+}
+
+// ListTrades retorna trades paginados con sus ejecuciones.
+func (s *adminService) ListTrades(ctx context.Context, req *pb.ListTradesRequest) (*pb.ListTradesResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultAdminTradesLimit
+	}
+	if limit > maxAdminTradesLimit {
+		limit = maxAdminTradesLimit
+	}
+	if req.Offset < 0 {
+		return nil, status.Error(codes.InvalidArgument, "offset must be >= 0")
+	}
+
+	tradeRepo := s.core.repoFactory.TradeRepository()
+	var (
+		trades []*domain.Trade
+		err    error
+	)
+	if req.Status != "" {
+		orderStatus := domain.OrderStatus(strings.ToUpper(req.Status))
+		switch orderStatus {
+		case domain.OrderStatusPending, domain.OrderStatusSent, domain.OrderStatusFilled,
+			domain.OrderStatusRejected, domain.OrderStatusCancelled:
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown trade status %q", req.Status)
+		}
+		trades, err = tradeRepo.ListByStatus(ctx, orderStatus, limit, int(req.Offset))
+	} else {
+		trades, err = tradeRepo.List(ctx, limit, int(req.Offset))
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list trades: %v", err)
+	}
+
+	resp := &pb.ListTradesResponse{Trades: make([]*pb.TradeDetail, 0, len(trades))}
+	for _, trade := range trades {
+		detail, err := s.tradeDetail(ctx, trade)
+		if err != nil {
+			return nil, err
+		}
+		resp.Trades = append(resp.Trades, detail)
+	}
+	return resp, nil
+}
+
+// GetTrade retorna un trade con sus ejecuciones.
+func (s *adminService) GetTrade(ctx context.Context, req *pb.GetTradeRequest) (*pb.TradeDetail, error) {
+	trade, err := s.loadTrade(ctx, req.TradeId)
+	if err != nil {
+		return nil, err
+	}
+	return s.tradeDetail(ctx, trade)
+}
+
+// loadTrade obtiene el trade (trade_id normalizado a minúsculas como en el Router).
+func (s *adminService) loadTrade(ctx context.Context, tradeID string) (*domain.Trade, error) {
+	if tradeID == "" {
+		return nil, status.Error(codes.InvalidArgument, "trade_id is required")
+	}
+
+	trade, err := s.core.repoFactory.TradeRepository().GetByID(ctx, strings.ToLower(tradeID))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load trade: %v", err)
+	}
+	if trade == nil {
+		return nil, status.Errorf(codes.NotFound, "trade %s not found", tradeID)
+	}
+	return trade, nil
+}
+
+func (s *adminService) tradeDetail(ctx context.Context, trade *domain.Trade) (*pb.TradeDetail, error) {
+	executions, err := s.core.repoFactory.ExecutionRepository().GetByTradeID(ctx, trade.TradeID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load executions of trade %s: %v", trade.TradeID, err)
+	}
+
+	detail := &pb.TradeDetail{
+		TradeId:         trade.TradeID,
+		MasterAccountId: trade.MasterAccountID,
+		MasterTicket:    trade.MasterTicket,
+		MagicNumber:     trade.MagicNumber,
+		Symbol:          trade.Symbol,
+		Side:            string(trade.Side),
+		LotSize:         trade.LotSize,
+		Price:           trade.Price,
+		StopLoss:        trade.StopLoss,
+		TakeProfit:      trade.TakeProfit,
+		Status:          string(trade.Status),
+		Attempt:         trade.Attempt,
+		OpenedAtMs:      trade.OpenedAtMs,
+		CreatedAtMs:     unixMilliOrZero(trade.CreatedAt),
+		UpdatedAtMs:     unixMilliOrZero(trade.UpdatedAt),
+		Executions:      make([]*pb.TradeExecution, 0, len(executions)),
+	}
+	for _, execution := range executions {
+		detail.Executions = append(detail.Executions, &pb.TradeExecution{
+			ExecutionId:    execution.ExecutionID,
+			SlaveAccountId: execution.SlaveAccountID,
+			AgentId:        execution.AgentID,
+			SlaveTicket:    execution.SlaveTicket,
+			ExecutedPrice:  execution.ExecutedPrice,
+			Success:        execution.Success,
+			ErrorCode:      execution.ErrorCode,
+			ErrorMessage:   execution.ErrorMessage,
+			StrategyId:     execution.StrategyID,
+			RiskPolicyType: execution.RiskPolicyType,
+			CreatedAtMs:    unixMilliOrZero(execution.CreatedAt),
+		})
+	}
+	return detail, nil
+}
+
+// EvaluateHandshake fuerza la re-evaluación del handshake de la cuenta.
+func (s *adminService) EvaluateHandshake(ctx context.Context, req *pb.EvaluateHandshakeRequest) (*pb.SymbolRegistrationResult, error) {
+	if req.AccountId == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+
+	evaluation, err := s.core.EvaluateHandshakeForAccount(ctx, req.AccountId, req.Send)
+	if err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "failed to evaluate handshake: %v", err)
+	}
+	if evaluation == nil {
+		return nil, status.Errorf(codes.NotFound, "no handshake data for account %s", req.AccountId)
+	}
+	return evaluation.ToProtoResult(), nil
+}
+
+// InvalidateAccountCaches limpia las cachés por cuenta del Core.
+//
+// Los datos se recargan desde la BD en el próximo uso; el ownership y la última
+// evaluación de handshake no se tocan (usar EvaluateHandshake).
+func (s *adminService) InvalidateAccountCaches(ctx context.Context, req *pb.InvalidateAccountCachesRequest) (*pb.InvalidateAccountCachesResponse, error) {
+	if req.AccountId == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+
+	resp := &pb.InvalidateAccountCachesResponse{}
+	if err := s.core.symbolResolver.InvalidateAccount(ctx, req.AccountId); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to invalidate symbol mappings: %v", err)
+	}
+	resp.Invalidated = append(resp.Invalidated, "symbol_mappings")
+	if s.core.symbolSpecService != nil {
+		s.core.symbolSpecService.Invalidate(req.AccountId)
+		resp.Invalidated = append(resp.Invalidated, "symbol_specs")
+	}
+	if s.core.symbolQuoteService != nil {
+		s.core.symbolQuoteService.Invalidate(req.AccountId)
+		resp.Invalidated = append(resp.Invalidated, "symbol_quotes")
+	}
+	if s.core.accountStateService != nil {
+		s.core.accountStateService.Invalidate(req.AccountId)
+		resp.Invalidated = append(resp.Invalidated, "account_state")
+	}
+	if s.core.riskPolicyService != nil {
+		s.core.riskPolicyService.Invalidate(req.AccountId, "")
+		resp.Invalidated = append(resp.Invalidated, "risk_policies")
+	}
+	return resp, nil
+}
+
+// SetCopyEnabled habilita o deshabilita la copia de una cuenta.
+func (s *adminService) SetCopyEnabled(ctx context.Context, req *pb.SetCopyEnabledRequest) (*pb.AccountCopyState, error) {
+	if req.AccountId == "" {
+		return nil, status.Error(codes.InvalidArgument, "account_id is required")
+	}
+
+	control, err := s.core.copyControl.Set(ctx, req.AccountId, req.Enabled, req.Reason, adminIdentity(ctx))
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set copy enabled: %v", err)
+	}
+	s.core.telemetry.Warn(s.core.ctx, "Account copy toggled by operator (i8)",
+		attribute.String("account_id", control.AccountID),
+		attribute.Bool("copy_enabled", control.CopyEnabled),
+		attribute.String("reason", control.Reason),
+		attribute.String("updated_by", control.UpdatedBy),
+	)
+	return copyControlToProto(control), nil
+}
+
+// CloseTrade envía CloseOrder del trade a los slaves indicados.
+func (s *adminService) CloseTrade(ctx context.Context, req *pb.CloseTradeRequest) (*pb.CloseTradeResponse, error) {
+	if _, err := s.loadTrade(ctx, req.TradeId); err != nil {
+		return nil, err
+	}
+
+	targets, err := s.core.router.CloseTradeOnSlaves(ctx, req.TradeId, req.SlaveAccountIds)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to close trade: %v", err)
+	}
+
+	resp := &pb.CloseTradeResponse{Targets: make([]*pb.CloseTradeTarget, 0, len(targets))}
+	for _, target := range targets {
+		resp.Targets = append(resp.Targets, &pb.CloseTradeTarget{
+			SlaveAccountId: target.SlaveAccountID,
+			Ticket:         target.Ticket,
+			CommandId:      target.CommandID,
+			Sent:           target.Sent,
+			Reason:         target.Reason,
+		})
+	}
+	return resp, nil
+}
+
+func ownershipRecordToProto(record OwnershipRecord) *pb.AccountOwnershipState {
+	return &pb.AccountOwnershipState{
+		AccountId:       record.AccountID,
+		AgentId:         record.AgentID,
+		PipeRole:        record.PipeRole,
+		RegisteredAtMs:  unixMilliOrZero(record.RegisteredAt),
+		LastSeenAtMs:    unixMilliOrZero(record.LastSeenAt),
+		Stale:           record.Stale,
+		Detached:        record.Detached,
+		ClaimantAgentId: record.ClaimantAgentID,
+		ConflictAtMs:    unixMilliOrZero(record.ConflictAt),
+	}
+}
+
+func copyControlToProto(control domain.AccountCopyControl) *pb.AccountCopyState {
+	return &pb.AccountCopyState{
+		AccountId:   control.AccountID,
+		CopyEnabled: control.CopyEnabled,
+		Reason:      control.Reason,
+		UpdatedBy:   control.UpdatedBy,
+		UpdatedAtMs: control.UpdatedAtMs,
+	}
+}
+
+// unixMilliOrZero convierte t a epoch ms (0 si t es cero).
+func unixMilliOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// WatchEvents envía los CoreEvents que coinciden con los filtros hasta que el cliente corta.
+//
+// El buffer por suscriptor es core/events/subscriber_buffer: si el cliente no consume a
+// tiempo se descartan los eventos más antiguos y CoreEvent.dropped informa cuántos.
+func (s *adminService) WatchEvents(req *pb.WatchEventsRequest, stream pb.AdminService_WatchEventsServer) error {
+	sub, err := s.core.events.Subscribe(req)
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	defer sub.Close()
+
+	ctx := stream.Context()
+	s.core.telemetry.Info(s.core.ctx, "WatchEvents subscriber connected (i8)",
+		attribute.String("identity", adminIdentity(ctx)),
+		attribute.StringSlice("account_ids", req.AccountIds),
+		attribute.StringSlice("strategy_ids", req.StrategyIds),
+		attribute.StringSlice("symbols", req.Symbols),
+	)
+
+	for {
+		event, dropped, err := sub.Next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return status.Error(codes.Unavailable, err.Error())
+		}
+		if dropped.Count > 0 {
+			// El evento se comparte entre suscriptores: el contador va en una copia
+			event = proto.Clone(event).(*pb.CoreEvent)
+			event.Dropped = dropped.Count
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/core/internal/repository"
+	"github.com/xKoRx/echo/sdk/domain"
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"go.opentelemetry.io/otel/metric/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testAdminTokenSecret = []byte("admin-test-secret")
+
+// newTestAdminCore arma un Core con repositorios en memoria, el slave "s1" del
+// agent-1 conectado (SendCh con buffer) y el mapeo XAUUSD -> XAUUSD.m para s1.
+func newTestAdminCore(t *testing.T, security SecurityConfig) (*Core, *AgentConnection) {
+	t.Helper()
+
+	echoMetrics, err := metricbundle.NewEchoMetrics(noop.NewMeterProvider().Meter("test"))
+	require.NoError(t, err)
+	tel := &telemetry.Client{}
+	factory := repository.NewMemoryFactory(0)
+
+	resolver := NewAccountSymbolResolver(context.Background(), nil, tel, echoMetrics, 1)
+	resolver.cache["s1"] = map[string]*domain.AccountSymbolInfo{
+		"XAUUSD": {CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.m"},
+	}
+
+	conn := &AgentConnection{AgentID: "agent-1", SendCh: make(chan *pb.CoreMessage, 4)}
+	core := &Core{
+		config:          &Config{Security: security},
+		agents:          map[string]*AgentConnection{"agent-1": conn},
+		accountRegistry: NewAccountRegistry(tel, 0),
+		repoFactory:     factory,
+		correlationSvc:  factory.CorrelationService(),
+		copyControl:     newCopyControl(factory.AccountCopyControlRepository()),
+		symbolResolver:  resolver,
+		telemetry:       tel,
+		echoMetrics:     echoMetrics,
+		ctx:             context.Background(),
+	}
+	core.router = NewRouter(core)
+	core.accountRegistry.RegisterAccount("agent-1", "s1", "slave")
+	return core, conn
+}
+
+// adminContext autentica identity con un agent-token como lo hace el interceptor del servidor.
+func adminContext(t *testing.T, identity string) context.Context {
+	t.Helper()
+
+	token, err := grpcSDK.SignAgentToken(testAdminTokenSecret, identity, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcSDK.AgentTokenMetadataKey, token))
+
+	var authCtx context.Context
+	interceptor := grpcSDK.AgentIdentityUnaryServerInterceptor(grpcSDK.IdentityConfig{Require: true, TokenSecret: testAdminTokenSecret})
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/Auth"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		authCtx = ctx
+		return nil, nil
+	})
+	require.NoError(t, err)
+	return authCtx
+}
+
+func TestSecurityConfigAdminAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		security SecurityConfig
+		identity string
+		want     bool
+	}{
+		{name: "sin identidad exigida ni lista", security: SecurityConfig{}, identity: "", want: true},
+		{name: "identidad exigida sin lista", security: SecurityConfig{RequireAgentIdentity: true}, identity: "agent-1", want: false},
+		{name: "identidad en la lista", security: SecurityConfig{RequireAgentIdentity: true, AdminIdentities: []string{"ops"}}, identity: "ops", want: true},
+		{name: "Agent fuera de la lista", security: SecurityConfig{RequireAgentIdentity: true, AdminIdentities: []string{"ops"}}, identity: "agent-1", want: false},
+		{name: "sin identidad con lista", security: SecurityConfig{AdminIdentities: []string{"ops"}}, identity: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.security.AdminAllowed(tt.identity))
+		})
+	}
+}
+
+func TestAdminServiceInterceptorDeniesUnauthorizedIdentity(t *testing.T) {
+	core, _ := newTestAdminCore(t, SecurityConfig{RequireAgentIdentity: true, AdminIdentities: []string{"ops"}})
+	interceptor := newAdminService(core).unaryInterceptor()
+	adminMethod := &grpc.UnaryServerInfo{FullMethod: "/" + pb.AdminService_ServiceDesc.ServiceName + "/ListAgents"}
+
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		return &pb.ListAgentsResponse{}, nil
+	}
+
+	// Un Agent autenticado no es admin
+	_, err := interceptor(adminContext(t, "agent-1"), &pb.ListAgentsRequest{}, adminMethod, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = interceptor(context.Background(), &pb.ListAgentsRequest{}, adminMethod, handler)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Zero(t, calls)
+
+	_, err = interceptor(adminContext(t, "ops"), &pb.ListAgentsRequest{}, adminMethod, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	// Otros servicios no pasan por la autorización admin
+	_, err = interceptor(adminContext(t, "agent-1"), nil, &grpc.UnaryServerInfo{FullMethod: "/" + pb.AgentService_ServiceDesc.ServiceName + "/Ping"}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestAdminServiceSetCopyEnabled(t *testing.T) {
+	core, _ := newTestAdminCore(t, SecurityConfig{RequireAgentIdentity: true, AdminIdentities: []string{"ops"}})
+	svc := newAdminService(core)
+	ctx := adminContext(t, "ops")
+
+	_, err := svc.SetCopyEnabled(ctx, &pb.SetCopyEnabledRequest{Enabled: false})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	state, err := svc.SetCopyEnabled(ctx, &pb.SetCopyEnabledRequest{AccountId: "s1", Enabled: false, Reason: "broker maintenance"})
+	require.NoError(t, err)
+	assert.Equal(t, "s1", state.AccountId)
+	assert.False(t, state.CopyEnabled)
+	assert.Equal(t, "broker maintenance", state.Reason)
+	assert.Equal(t, "ops", state.UpdatedBy)
+	assert.False(t, core.copyControl.Enabled("s1"))
+
+	state, err = svc.SetCopyEnabled(ctx, &pb.SetCopyEnabledRequest{AccountId: "s1", Enabled: true})
+	require.NoError(t, err)
+	assert.True(t, state.CopyEnabled)
+	assert.True(t, core.copyControl.Enabled("s1"))
+}
+
+func TestAdminServiceCloseTrade(t *testing.T) {
+	ctx := context.Background()
+	core, conn := newTestAdminCore(t, SecurityConfig{})
+	svc := newAdminService(core)
+
+	require.NoError(t, core.repoFactory.TradeRepository().Create(ctx, &domain.Trade{TradeID: "t1", Symbol: "XAUUSD", MagicNumber: 7}))
+	require.NoError(t, core.repoFactory.ExecutionRepository().Create(ctx, &domain.Execution{ExecutionID: "e1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+
+	_, err := svc.CloseTrade(ctx, &pb.CloseTradeRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = svc.CloseTrade(ctx, &pb.CloseTradeRequest{TradeId: "t9"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// trade_id se normaliza; s2 no tiene ticket y se omite sin enviar
+	resp, err := svc.CloseTrade(ctx, &pb.CloseTradeRequest{TradeId: "T1", SlaveAccountIds: []string{"s1", "s2"}})
+	require.NoError(t, err)
+	require.Len(t, resp.Targets, 2)
+	assert.Equal(t, "s1", resp.Targets[0].SlaveAccountId)
+	assert.Equal(t, int32(555), resp.Targets[0].Ticket)
+	assert.True(t, resp.Targets[0].Sent)
+	assert.NotEmpty(t, resp.Targets[0].CommandId)
+	assert.Equal(t, "s2", resp.Targets[1].SlaveAccountId)
+	assert.False(t, resp.Targets[1].Sent)
+	assert.NotEmpty(t, resp.Targets[1].Reason)
+
+	require.Len(t, conn.SendCh, 1)
+	closeOrder := (<-conn.SendCh).GetCloseOrder()
+	require.NotNil(t, closeOrder)
+	assert.Equal(t, resp.Targets[0].CommandId, closeOrder.CommandId)
+	assert.Equal(t, "t1", closeOrder.TradeId)
+	assert.Equal(t, "s1", closeOrder.TargetAccountId)
+	assert.Equal(t, int32(555), closeOrder.Ticket)
+	assert.Equal(t, "XAUUSD.m", closeOrder.Symbol)
+	assert.Equal(t, int64(7), closeOrder.MagicNumber)
+
+	// El CloseResult del slave se correlaciona con el comando manual
+	cmdCtx := core.router.getCommandContext(closeOrder.CommandId)
+	require.NotNil(t, cmdCtx)
+	assert.Equal(t, "close_order", cmdCtx.CommandType)
+	assert.Equal(t, "s1", cmdCtx.SlaveAccountID)
+}
@@ -0,0 +1,313 @@
+package internal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/etcd"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// agentConfigPrefix prefijo ETCD de la configuración de Agents publicada por el Core (i8).
+const agentConfigPrefix = "core/agent_config/"
+
+// agentConfigDefaultScope scope de core/agent_config/ que aplica a todos los Agents.
+const agentConfigDefaultScope = "_default"
+
+// agentConfigSource lee las claves de core/agent_config/ (etcd.Client en producción).
+type agentConfigSource interface {
+	GetVarsWithPrefix(ctx context.Context, prefix string) (map[string]string, error)
+}
+
+// agentConfigPublisher publica la configuración de cada Agent con ConfigUpdate (i8).
+//
+// El Core es la fuente de verdad de la configuración por Agent:
+//
+//   - Al conectar un Agent se le envía su configuración completa (PushTo).
+//   - Cada PollInterval se releen las claves y se reenvía a los Agents cuya versión cambió.
+//   - El Agent aplica lo que puede en caliente y responde ConfigApplied con la versión,
+//     las claves que requieren reinicio y las rechazadas.
+//
+// La versión es un hash del contenido: es estable entre reinicios del Core y el
+// Agent ignora (solo re-confirma) una versión ya procesada.
+type agentConfigPublisher struct {
+	source      agentConfigSource
+	closeSource func() error
+	env         string
+	config      AgentConfigPushConfig
+	telemetry   *telemetry.Client
+	echoMetrics *metricbundle.EchoMetrics
+
+	// send encola el ConfigUpdate en el stream del Agent; agents lista los conectados
+	send   func(agentID string, update *pb.ConfigUpdate) error
+	agents func() []string
+
+	mu      sync.Mutex
+	entries map[string]string // claves bajo core/agent_config/ (sin el prefijo)
+	sent    map[string]string // agent_id → versión enviada en el stream actual
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newAgentConfigPublisher(
+	parentCtx context.Context,
+	env string,
+	config AgentConfigPushConfig,
+	send func(agentID string, update *pb.ConfigUpdate) error,
+	agents func() []string,
+	telemetryClient *telemetry.Client,
+	echoMetrics *metricbundle.EchoMetrics,
+) *agentConfigPublisher {
+	ctx, cancel := context.WithCancel(parentCtx)
+	return &agentConfigPublisher{
+		env:         env,
+		config:      config,
+		telemetry:   telemetryClient,
+		echoMetrics: echoMetrics,
+		send:        send,
+		agents:      agents,
+		entries:     make(map[string]string),
+		sent:        make(map[string]string),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start abre ETCD, carga la configuración y arranca la detección de cambios.
+//
+// Si ETCD no responde el Core sigue operando: los Agents usan su configuración local.
+func (p *agentConfigPublisher) Start() {
+	if p.source == nil {
+		client, err := etcd.New(
+			etcd.WithApp("echo"),
+			etcd.WithEnv(p.env),
+		)
+		if err != nil {
+			p.telemetry.Warn(p.ctx, "Agent config publisher disabled: ETCD unavailable (i8)",
+				attribute.String("error", err.Error()),
+			)
+			return
+		}
+		p.source = client
+		p.closeSource = client.Close
+	}
+
+	if _, err := p.reload(); err != nil {
+		p.telemetry.Warn(p.ctx, "Failed to load agent config from ETCD (i8)",
+			attribute.String("error", err.Error()),
+		)
+	}
+
+	p.wg.Add(1)
+	go p.worker()
+}
+
+func (p *agentConfigPublisher) Stop() {
+	p.cancel()
+	p.wg.Wait()
+	if p.closeSource != nil {
+		_ = p.closeSource()
+	}
+}
+
+func (p *agentConfigPublisher) worker() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := p.reload()
+			if err != nil {
+				p.telemetry.Warn(p.ctx, "Failed to reload agent config from ETCD (i8)",
+					attribute.String("error", err.Error()),
+				)
+				continue
+			}
+			if changed {
+				p.pushChanged()
+			}
+		}
+	}
+}
+
+// reload relee core/agent_config/ y retorna true si el contenido cambió.
+func (p *agentConfigPublisher) reload() (bool, error) {
+	if p.source == nil {
+		return false, nil
+	}
+
+	vars, err := p.source.GetVarsWithPrefix(p.ctx, agentConfigPrefix)
+	if err != nil {
+		return false, err
+	}
+	entries := make(map[string]string, len(vars))
+	for key, value := range vars {
+		entries[strings.TrimPrefix(key, agentConfigPrefix)] = value
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if agentConfigVersion(entries) == agentConfigVersion(p.entries) {
+		return false, nil
+	}
+	p.entries = entries
+	return true, nil
+}
+
+// PushTo envía la configuración vigente a un Agent recién conectado.
+func (p *agentConfigPublisher) PushTo(agentID string) {
+	p.push(agentID, true)
+}
+
+// pushChanged reenvía la configuración a los Agents cuya versión cambió.
+func (p *agentConfigPublisher) pushChanged() {
+	for _, agentID := range p.agents() {
+		p.push(agentID, false)
+	}
+}
+
+func (p *agentConfigPublisher) push(agentID string, force bool) {
+	p.mu.Lock()
+	config := resolveAgentConfig(p.entries, agentID)
+	version := agentConfigVersion(config)
+	previous, wasSent := p.sent[agentID]
+	p.mu.Unlock()
+
+	if len(config) == 0 && !wasSent {
+		return // Sin configuración central: el Agent usa la local
+	}
+	if !force && wasSent && previous == version {
+		return
+	}
+
+	update := &pb.ConfigUpdate{Version: version, Config: config}
+	if err := p.send(agentID, update); err != nil {
+		p.telemetry.Warn(p.ctx, "Failed to send ConfigUpdate (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.String("version", version),
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+
+	p.mu.Lock()
+	p.sent[agentID] = version
+	p.mu.Unlock()
+
+	p.telemetry.Info(p.ctx, "ConfigUpdate sent to agent (i8)",
+		attribute.String("agent_id", agentID),
+		attribute.String("version", version),
+		attribute.Int("keys", len(config)),
+	)
+	p.echoMetrics.RecordAgentConfigUpdate(p.ctx, "sent",
+		attribute.String("agent_id", agentID),
+	)
+}
+
+// HandleApplied registra el ConfigApplied de un Agent.
+func (p *agentConfigPublisher) HandleApplied(agentID string, applied *pb.ConfigApplied) {
+	p.mu.Lock()
+	current := p.sent[agentID]
+	p.mu.Unlock()
+
+	attrs := []attribute.KeyValue{
+		attribute.String("agent_id", agentID),
+		attribute.String("version", applied.Version),
+		attribute.StringSlice("applied_keys", applied.AppliedKeys),
+	}
+	if current != "" && current != applied.Version {
+		// Respuesta a una versión anterior: la vigente ya está en camino
+		attrs = append(attrs, attribute.String("current_version", current))
+	}
+
+	result := "applied"
+	switch {
+	case len(applied.Rejected) > 0:
+		result = "rejected"
+		rejected := make([]string, 0, len(applied.Rejected))
+		for _, rejection := range applied.Rejected {
+			rejected = append(rejected, rejection.Key+": "+rejection.Reason)
+		}
+		attrs = append(attrs,
+			attribute.StringSlice("restart_required_keys", applied.RestartRequiredKeys),
+			attribute.StringSlice("rejected", rejected),
+		)
+		p.telemetry.Warn(p.ctx, "Agent rejected config keys (i8)", attrs...)
+	case len(applied.RestartRequiredKeys) > 0:
+		result = "restart_required"
+		attrs = append(attrs, attribute.StringSlice("restart_required_keys", applied.RestartRequiredKeys))
+		p.telemetry.Warn(p.ctx, "Agent config requires restart (i8)", attrs...)
+	default:
+		p.telemetry.Info(p.ctx, "Agent config applied (i8)", attrs...)
+	}
+
+	p.echoMetrics.RecordAgentConfigUpdate(p.ctx, result,
+		attribute.String("agent_id", agentID),
+	)
+}
+
+// Disconnected olvida la versión enviada: el próximo stream recibe la configuración completa.
+func (p *agentConfigPublisher) Disconnected(agentID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.sent, agentID)
+}
+
+// resolveAgentConfig combina _default con las claves propias del Agent (prevalecen).
+//
+// entries usa claves relativas a core/agent_config/: "<scope>/<clave del Agent>".
+func resolveAgentConfig(entries map[string]string, agentID string) map[string]string {
+	config := make(map[string]string)
+	overrides := make(map[string]string)
+	for entry, value := range entries {
+		scope, key, ok := strings.Cut(entry, "/")
+		if !ok || key == "" {
+			continue
+		}
+		switch scope {
+		case agentConfigDefaultScope:
+			config[key] = value
+		case agentID:
+			overrides[key] = value
+		}
+	}
+	for key, value := range overrides {
+		config[key] = value
+	}
+	return config
+}
+
+// agentConfigVersion hash estable del contenido ("" si no hay claves).
+func agentConfigVersion(config map[string]string) string {
+	if len(config) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", key, config[key])
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16]
+}
@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+)
+
+type stubAgentConfigSource struct {
+	vars map[string]string
+}
+
+func (s *stubAgentConfigSource) GetVarsWithPrefix(ctx context.Context, prefix string) (map[string]string, error) {
+	out := make(map[string]string, len(s.vars))
+	for key, value := range s.vars {
+		out[key] = value
+	}
+	return out, nil
+}
+
+func TestResolveAgentConfigOverridesDefaults(t *testing.T) {
+	entries := map[string]string{
+		"_default/agent/log_level":   "INFO",
+		"_default/agent/max_retries": "3",
+		"agent_a/agent/log_level":    "DEBUG",
+		"agent_b/agent/max_retries":  "5",
+		"malformed":                  "x",
+	}
+
+	config := resolveAgentConfig(entries, "agent_a")
+	assert.Equal(t, map[string]string{
+		"agent/log_level":   "DEBUG",
+		"agent/max_retries": "3",
+	}, config)
+
+	assert.Empty(t, resolveAgentConfig(map[string]string{"agent_b/agent/log_level": "WARN"}, "agent_a"))
+}
+
+func TestAgentConfigVersionIsStable(t *testing.T) {
+	first := agentConfigVersion(map[string]string{"agent/log_level": "DEBUG", "agent/max_retries": "3"})
+	second := agentConfigVersion(map[string]string{"agent/max_retries": "3", "agent/log_level": "DEBUG"})
+	changed := agentConfigVersion(map[string]string{"agent/log_level": "INFO", "agent/max_retries": "3"})
+
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, changed)
+	assert.Empty(t, agentConfigVersion(nil))
+}
+
+func TestAgentConfigPublisherPushesOnlyChangedAgents(t *testing.T) {
+	source := &stubAgentConfigSource{vars: map[string]string{
+		"core/agent_config/_default/agent/log_level": "INFO",
+		"core/agent_config/agent_b/agent/log_level":  "DEBUG",
+	}}
+
+	sent := make(map[string][]*pb.ConfigUpdate)
+	send := func(agentID string, update *pb.ConfigUpdate) error {
+		sent[agentID] = append(sent[agentID], update)
+		return nil
+	}
+	agents := func() []string { return []string{"agent_a", "agent_b"} }
+
+	publisher := newAgentConfigPublisher(context.Background(), "test", AgentConfigPushConfig{Enabled: true, PollInterval: time.Second},
+		send, agents, &telemetry.Client{}, &metricbundle.EchoMetrics{})
+	publisher.source = source
+
+	changed, err := publisher.reload()
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	publisher.PushTo("agent_a")
+	publisher.PushTo("agent_b")
+	require.Len(t, sent["agent_a"], 1)
+	require.Len(t, sent["agent_b"], 1)
+	assert.Equal(t, "INFO", sent["agent_a"][0].Config["agent/log_level"])
+	assert.Equal(t, "DEBUG", sent["agent_b"][0].Config["agent/log_level"])
+
+	// Solo cambia la configuración propia de agent_b
+	source.vars["core/agent_config/agent_b/agent/log_level"] = "WARN"
+	changed, err = publisher.reload()
+	require.NoError(t, err)
+	require.True(t, changed)
+	publisher.pushChanged()
+
+	assert.Len(t, sent["agent_a"], 1)
+	require.Len(t, sent["agent_b"], 2)
+	assert.Equal(t, "WARN", sent["agent_b"][1].Config["agent/log_level"])
+	assert.NotEqual(t, sent["agent_b"][0].Version, sent["agent_b"][1].Version)
+
+	// Sin cambios no hay reenvío
+	changed, err = publisher.reload()
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
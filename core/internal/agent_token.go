@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
+)
+
+// MintAgentToken firma un agent-token para agentID con el secreto configurado en ETCD (i8).
+//
+// Usado por echo-core-cli; el Agent lo envía en agent/grpc/token cuando no usa mTLS.
+func MintAgentToken(ctx context.Context, agentID string, ttl time.Duration) (string, time.Time, error) {
+	if ttl <= 0 {
+		return "", time.Time{}, fmt.Errorf("ttl must be positive")
+	}
+
+	config, err := LoadConfig(ctx)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to load config from ETCD: %w", err)
+	}
+	if config.Security.AgentTokenSecret == "" {
+		return "", time.Time{}, fmt.Errorf("core/security/agent_token_secret is not configured")
+	}
+
+	expiresAt := time.Now().Add(ttl).UTC()
+	token, err := grpcSDK.SignAgentToken([]byte(config.Security.AgentTokenSecret), agentID, expiresAt)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign agent token: %w", err)
+	}
+	return token, expiresAt, nil
+}
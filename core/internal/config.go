@@ -3,8 +3,10 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/xKoRx/echo/sdk/domain"
 	"github.com/xKoRx/echo/sdk/domain/handshake"
 	"github.com/xKoRx/echo/sdk/etcd"
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
 )
 
 // Config configuración del Core (i1).
@@ -41,6 +44,26 @@ type Config struct {
 	VolumeGuard      *domain.VolumeGuardPolicy
 	Risk             RiskConfig
 	Protocol         ProtocolConfig
+	Journal          JournalConfig
+	Outbox           OutboxConfig
+	Liveness         LivenessConfig
+	Ownership        OwnershipConfig
+	AgentConfig      AgentConfigPushConfig
+	Security         SecurityConfig
+	Stream           StreamConfig
+	Events           EventsConfig
+	HTTPGateway      HTTPGatewayConfig
+	Notifier         NotifierConfig
+	SymbolMatcher    SymbolMatcherConfig
+
+	// SymbolOverridePollInterval frecuencia con que Core detecta overrides escritos por
+	// echo-core-cli symbols cuando storage/backend = sqlite (i8). PostgreSQL usa NOTIFY.
+	SymbolOverridePollInterval time.Duration // core/symbols/override_poll_interval_ms
+
+	// Storage
+	StorageBackend string // storage/backend ("postgres"|"memory"|"sqlite")
+	SQLitePath     string // storage/sqlite/path
+	MigrateOnStart bool   // storage/migrate_on_start (aplica migraciones pendientes al iniciar)
 
 	// PostgreSQL
 	PostgresHost        string // postgres/host
@@ -57,13 +80,26 @@ type Config struct {
 	ServiceVersion string // telemetry/service_version
 	Environment    string // telemetry/environment
 	LogLevel       string // core/log_level (DEBUG|INFO|WARN|ERROR)
+
+	// Namespace ETCD (/echo/{EtcdEnv}/), para componentes que releen claves en runtime
+	EtcdEnv string // ENV
 }
 
+// Backends de persistencia soportados por storage/backend.
+const (
+	StorageBackendPostgres = "postgres"
+	StorageBackendMemory   = "memory" // Sin persistencia: tests y desarrollo local
+	StorageBackendSQLite   = "sqlite" // Archivo local: despliegues single-host
+)
+
 // RiskConfig agrupa configuración del servicio de políticas de riesgo.
 type RiskConfig struct {
 	MissingPolicy string
 	CacheTTL      time.Duration
-	Engine        FixedRiskEngineConfig
+	// PolicyPollInterval frecuencia con que Core detecta revisiones escritas por otros
+	// procesos (echo-core-cli) cuando storage/backend = sqlite (i8). PostgreSQL usa NOTIFY.
+	PolicyPollInterval time.Duration // core/risk/policy_poll_interval_ms
+	Engine             FixedRiskEngineConfig
 }
 
 // ProtocolConfig agrupa configuración de versionado de handshake.
@@ -76,6 +112,153 @@ type ProtocolConfig struct {
 	VersionRange     *handshake.VersionRange
 }
 
+// JournalConfig agrupa configuración del journal durable de mensajes.
+type JournalConfig struct {
+	Enabled         bool   // core/journal/enabled
+	Dir             string // core/journal/dir
+	SegmentMaxBytes int64  // core/journal/segment_max_bytes
+	MaxSegments     int    // core/journal/max_segments
+	Compress        bool   // core/journal/compress
+}
+
+// OutboxConfig agrupa configuración del outbox transaccional de comandos (i8).
+type OutboxConfig struct {
+	PollInterval   time.Duration // core/outbox/poll_interval_ms
+	MaxAge         time.Duration // core/outbox/max_age_ms (comandos más antiguos se expiran)
+	RedeliverAfter time.Duration // core/outbox/redeliver_after_ms (reentrega si no hay ack)
+	BatchSize      int           // core/outbox/batch_size
+	Retention      time.Duration // core/outbox/retention_minutes (purga de ACKED/EXPIRED)
+}
+
+// LivenessConfig agrupa configuración del monitor de heartbeats de Agents (i8).
+type LivenessConfig struct {
+	CheckInterval   time.Duration // core/liveness/check_interval_ms
+	MissedBeats     int           // core/liveness/missed_beats (intervalos sin heartbeat antes de marcar stale)
+	DefaultInterval time.Duration // core/liveness/default_interval_ms (si el Agent no reporta interval_ms)
+	PersistInterval time.Duration // core/liveness/persist_interval_ms (escritura del último estado)
+	Retention       time.Duration // core/liveness/retention_hours (purga de Agents sin heartbeat)
+}
+
+// OwnershipConfig agrupa configuración de conflictos de ownership de cuentas (i8).
+type OwnershipConfig struct {
+	TakeoverGrace time.Duration // core/ownership/takeover_grace_ms (owner sin stream/heartbeat conserva la cuenta)
+	CheckInterval time.Duration // core/ownership/check_interval_ms (takeovers pendientes y persistencia)
+}
+
+// AgentConfigPushConfig agrupa configuración de ConfigUpdate hacia los Agents (i8).
+//
+// La configuración de cada Agent vive en core/agent_config/_default/<clave> y
+// core/agent_config/<agent_id>/<clave> (prevalece), con las claves del Agent
+// (ej. core/agent_config/_default/agent/log_level).
+type AgentConfigPushConfig struct {
+	Enabled      bool          // core/agent_config/enabled
+	PollInterval time.Duration // core/agent_config/poll_interval_ms (detección de cambios)
+}
+
+// SecurityConfig agrupa autenticación de Agents en el canal gRPC (i8).
+type SecurityConfig struct {
+	TLSCertFile          string              // core/security/tls_cert_file (vacío = sin TLS)
+	TLSKeyFile           string              // core/security/tls_key_file
+	ClientCAFile         string              // core/security/client_ca_file (mTLS: exige certificado a los Agents)
+	RequireAgentIdentity bool                // core/security/require_agent_identity (rechaza Agents sin certificado ni token)
+	AgentTokenSecret     string              // core/security/agent_token_secret (firma de agent-token; vacío = deshabilitado)
+	AllowlistStrict      bool                // core/security/allowlist_strict (Agents sin entrada no pueden operar cuentas)
+	AgentAccounts        map[string][]string // core/security/agent_accounts/<agent_id> (comma separated, "*" = todas)
+	AdminIdentities      []string            // core/security/admin_identities (comma separated; vacío = sin admin si se exige identidad)
+}
+
+// AdminAllowed indica si la identidad autenticada puede usar AdminService.
+//
+// Deny by default: con RequireAgentIdentity solo las AdminIdentities pueden usarlo
+// (una identidad de Agent válida no basta). Sin identidad exigida (despliegue local
+// sin TLS ni tokens) y sin AdminIdentities se admite cualquier llamada.
+func (s SecurityConfig) AdminAllowed(identity string) bool {
+	if len(s.AdminIdentities) == 0 {
+		return !s.RequireAgentIdentity
+	}
+	for _, allowed := range s.AdminIdentities {
+		if identity != "" && allowed == identity {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountAllowed indica si agentID puede operar accountID según el allowlist.
+//
+// Sin entrada para el Agent decide AllowlistStrict (false = cualquier cuenta).
+func (s SecurityConfig) AccountAllowed(agentID, accountID string) bool {
+	accounts, ok := s.AgentAccounts[agentID]
+	if !ok {
+		return !s.AllowlistStrict
+	}
+	for _, allowed := range accounts {
+		if allowed == "*" || allowed == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// StreamConfig agrupa configuración de entrega confiable sobre el stream bidi (i8).
+type StreamConfig struct {
+	ReplayBufferSize int           // grpc/stream/replay_buffer_size (CoreMessages sin ack por sesión)
+	AckInterval      time.Duration // grpc/stream/ack_interval_ms
+}
+
+// EventsConfig agrupa configuración del stream de eventos WatchEvents (i8).
+type EventsConfig struct {
+	SubscriberBuffer int // core/events/subscriber_buffer (eventos en cola por suscriptor; se descartan los más antiguos)
+	MaxSubscribers   int // core/events/max_subscribers (streams WatchEvents simultáneos)
+}
+
+// HTTPGatewayConfig agrupa el gateway HTTP/JSON de la API admin (i8).
+//
+// Usa el certificado de core/security/tls_cert_file si está configurado.
+type HTTPGatewayConfig struct {
+	Enabled bool     // core/http/enabled
+	Addr    string   // core/http/addr
+	APIKeys []string // core/http/api_keys (comma separated; header X-API-Key o Authorization: Bearer)
+}
+
+// NotifierConfig agrupa los webhooks de alertas del Core (i8).
+type NotifierConfig struct {
+	Enabled        bool           // core/notifier/enabled
+	Workers        int            // core/notifier/workers (envíos concurrentes)
+	QueueSize      int            // core/notifier/queue_size (notificaciones pendientes; llena = se descartan)
+	Timeout        time.Duration  // core/notifier/timeout_ms (por intento)
+	MaxAttempts    int            // core/notifier/max_attempts (agotados = dead letter)
+	InitialBackoff time.Duration  // core/notifier/initial_backoff_ms (se duplica por intento)
+	MaxBackoff     time.Duration  // core/notifier/max_backoff_ms
+	Rules          []NotifierRule // core/notifier/rules/<name> (JSON), ordenadas por nombre
+}
+
+// SymbolMatcherConfig agrupa el matcher de símbolos sin mapeo reportados por el EA (i8).
+type SymbolMatcherConfig struct {
+	Enabled              bool              // core/symbols/matcher/enabled
+	Prefixes             []string          // core/symbols/matcher/prefixes (comma separated)
+	Suffixes             []string          // core/symbols/matcher/suffixes (comma separated)
+	Aliases              map[string]string // core/symbols/matcher/aliases (BROKER=CANONICAL, comma separated)
+	AutoAcceptConfidence float64           // core/symbols/matcher/auto_accept_confidence (>= se persiste como mapeo)
+	MinConfidence        float64           // core/symbols/matcher/min_confidence (>= queda como propuesta)
+}
+
+// NotifierRule regla de core/notifier/rules/<name> (i8). Filtros vacíos no restringen.
+type NotifierRule struct {
+	Name              string            `json:"-"`
+	Event             string            `json:"event"` // Tipo de CoreEvent: risk_rejected, execution_result, handshake_changed, agent_disconnected, ...
+	Accounts          []string          `json:"accounts,omitempty"`
+	Strategies        []string          `json:"strategies,omitempty"`
+	Symbols           []string          `json:"symbols,omitempty"`
+	Success           *bool             `json:"success,omitempty"`             // execution_result y close_result
+	HandshakeStatuses []string          `json:"handshake_statuses,omitempty"`  // handshake_changed: ACCEPTED, WARNING, REJECTED
+	MinSlippagePoints float64           `json:"min_slippage_points,omitempty"` // execution_result: solo fills con slippage >= N points
+	URLs              []string          `json:"urls"`
+	Headers           map[string]string `json:"headers,omitempty"`
+	Template          string            `json:"template,omitempty"`       // text/template que debe renderizar JSON; vacío = payload por defecto
+	MaxPerMinute      int               `json:"max_per_minute,omitempty"` // 0 = sin límite
+}
+
 // FixedRiskEngineConfig agrupa la configuración del motor FixedRisk.
 type FixedRiskEngineConfig struct {
 	QuoteMaxAge              time.Duration
@@ -125,6 +308,9 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 		SymbolWhitelist:     []string{"XAUUSD"}, // Deprecated i3, mantener para compatibilidad
 		CanonicalSymbols:    []string{"XAUUSD"}, // NEW i3
 		UnknownAction:       "warn",             // NEW i3 (default: warn para rollout seguro)
+		StorageBackend:      StorageBackendPostgres,
+		SQLitePath:          "echo-core.db",
+		MigrateOnStart:      true,
 		PostgresPort:        5432,
 		PostgresSchema:      "echo",
 		PostgresPoolMaxConn: 10,
@@ -133,6 +319,7 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 		ServiceVersion:      "1.0.0-i1",
 		Environment:         env,
 		LogLevel:            "INFO",
+		EtcdEnv:             env,
 		VolumeGuard: &domain.VolumeGuardPolicy{
 			OnMissingSpec:  domain.VolumeGuardMissingSpecReject,
 			MaxSpecAge:     10 * time.Second,
@@ -140,8 +327,9 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 			DefaultLot:     0.10,
 		},
 		Risk: RiskConfig{
-			MissingPolicy: "reject",
-			CacheTTL:      5 * time.Second,
+			MissingPolicy:      "reject",
+			CacheTTL:           5 * time.Second,
+			PolicyPollInterval: time.Second,
 			Engine: FixedRiskEngineConfig{
 				QuoteMaxAge:              750 * time.Millisecond,
 				MinDistancePoints:        5,
@@ -158,6 +346,66 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 			RequiredFeatures: []string{},
 			RetryInterval:    5 * time.Minute,
 		},
+		Journal: JournalConfig{
+			Enabled:         false,
+			Dir:             "journal",
+			SegmentMaxBytes: 64 << 20,
+			MaxSegments:     50,
+			Compress:        true,
+		},
+		Outbox: OutboxConfig{
+			PollInterval:   500 * time.Millisecond,
+			MaxAge:         30 * time.Second,
+			RedeliverAfter: 5 * time.Second,
+			BatchSize:      100,
+			Retention:      24 * time.Hour,
+		},
+		Liveness: LivenessConfig{
+			CheckInterval:   time.Second,
+			MissedBeats:     3,
+			DefaultInterval: 5 * time.Second,
+			PersistInterval: 5 * time.Second,
+			Retention:       7 * 24 * time.Hour,
+		},
+		Ownership: OwnershipConfig{
+			TakeoverGrace: 30 * time.Second,
+			CheckInterval: 5 * time.Second,
+		},
+		AgentConfig: AgentConfigPushConfig{
+			Enabled:      true,
+			PollInterval: 5 * time.Second,
+		},
+		Security: SecurityConfig{
+			AgentAccounts: make(map[string][]string),
+		},
+		Stream: StreamConfig{
+			ReplayBufferSize: grpcSDK.DefaultReplayBufferSize,
+			AckInterval:      grpcSDK.DefaultAckInterval,
+		},
+		Events: EventsConfig{
+			SubscriberBuffer: 1024,
+			MaxSubscribers:   32,
+		},
+		HTTPGateway: HTTPGatewayConfig{
+			Addr: ":8081",
+		},
+		Notifier: NotifierConfig{
+			Workers:        2,
+			QueueSize:      256,
+			Timeout:        5 * time.Second,
+			MaxAttempts:    5,
+			InitialBackoff: 500 * time.Millisecond,
+			MaxBackoff:     30 * time.Second,
+		},
+		SymbolOverridePollInterval: time.Second,
+		SymbolMatcher: SymbolMatcherConfig{
+			Enabled:              true,
+			Prefixes:             []string{"#", "_", "."},
+			Suffixes:             []string{".m", ".pro", "pro", ".ecn", "ecn", ".raw", "raw", ".i", ".c", "m", "micro", ".std", "-ecn", "+"},
+			Aliases:              map[string]string{"GOLD": "XAUUSD", "SILVER": "XAGUSD", "US30": "DJ30"},
+			AutoAcceptConfidence: 0.9,
+			MinConfidence:        0.5,
+		},
 	}
 
 	// Cargar endpoints
@@ -268,6 +516,60 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 			cfg.UnknownAction = val
 		}
 	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/symbols/override_poll_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.SymbolOverridePollInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	// Cargar matcher de símbolos (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/symbols/matcher/enabled", ""); err == nil && val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.SymbolMatcher.Enabled = enabled
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/symbols/matcher/prefixes", ""); err == nil && val != "" {
+		cfg.SymbolMatcher.Prefixes = nil
+		for _, part := range strings.Split(val, ",") {
+			if affix := strings.TrimSpace(part); affix != "" {
+				cfg.SymbolMatcher.Prefixes = append(cfg.SymbolMatcher.Prefixes, affix)
+			}
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/symbols/matcher/suffixes", ""); err == nil && val != "" {
+		cfg.SymbolMatcher.Suffixes = nil
+		for _, part := range strings.Split(val, ",") {
+			if affix := strings.TrimSpace(part); affix != "" {
+				cfg.SymbolMatcher.Suffixes = append(cfg.SymbolMatcher.Suffixes, affix)
+			}
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/symbols/matcher/aliases", ""); err == nil && val != "" {
+		aliases := make(map[string]string)
+		for _, part := range strings.Split(val, ",") {
+			if strings.TrimSpace(part) == "" {
+				continue
+			}
+			broker, canonical, ok := strings.Cut(part, "=")
+			broker = strings.ToUpper(strings.TrimSpace(broker))
+			canonical = strings.ToUpper(strings.TrimSpace(canonical))
+			if !ok || broker == "" || canonical == "" {
+				return nil, fmt.Errorf("invalid core/symbols/matcher/aliases entry: %s", part)
+			}
+			aliases[broker] = canonical
+		}
+		cfg.SymbolMatcher.Aliases = aliases
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/symbols/matcher/auto_accept_confidence", ""); err == nil && val != "" {
+		if confidence, err := strconv.ParseFloat(val, 64); err == nil {
+			cfg.SymbolMatcher.AutoAcceptConfidence = confidence
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/symbols/matcher/min_confidence", ""); err == nil && val != "" {
+		if confidence, err := strconv.ParseFloat(val, 64); err == nil {
+			cfg.SymbolMatcher.MinConfidence = confidence
+		}
+	}
 
 	if val, err := etcdClient.GetVarWithDefault(ctx, "core/slave_accounts", ""); err == nil && val != "" {
 		cfg.SlaveAccounts = strings.Split(val, ",")
@@ -291,6 +593,11 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 			cfg.Risk.CacheTTL = time.Duration(ms) * time.Millisecond
 		}
 	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/risk/policy_poll_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Risk.PolicyPollInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
 	if val, err := etcdClient.GetVarWithDefault(ctx, "core/risk/quote_max_age_ms", ""); err == nil && val != "" {
 		if ms, err := strconv.Atoi(val); err == nil {
 			cfg.Risk.Engine.QuoteMaxAge = time.Duration(ms) * time.Millisecond
@@ -376,6 +683,270 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 	cfg.Protocol.RequiredFeatures = requiredFeatures
 	cfg.Protocol.VersionRange = versionRange
 
+	// Cargar backend de persistencia
+	if val, err := etcdClient.GetVarWithDefault(ctx, "storage/backend", ""); err == nil && val != "" {
+		switch backend := strings.ToLower(strings.TrimSpace(val)); backend {
+		case StorageBackendPostgres, StorageBackendMemory, StorageBackendSQLite:
+			cfg.StorageBackend = backend
+		default:
+			return nil, fmt.Errorf("unsupported storage/backend: %s", val)
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "storage/sqlite/path", ""); err == nil && val != "" {
+		cfg.SQLitePath = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "storage/migrate_on_start", ""); err == nil && val != "" {
+		if enabled, err := strconv.ParseBool(strings.TrimSpace(val)); err == nil {
+			cfg.MigrateOnStart = enabled
+		}
+	}
+
+	// Cargar journal de mensajes
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/journal/enabled", ""); err == nil && val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.Journal.Enabled = enabled
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/journal/dir", ""); err == nil && val != "" {
+		cfg.Journal.Dir = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/journal/segment_max_bytes", ""); err == nil && val != "" {
+		if size, err := strconv.ParseInt(val, 10, 64); err == nil && size > 0 {
+			cfg.Journal.SegmentMaxBytes = size
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/journal/max_segments", ""); err == nil && val != "" {
+		if count, err := strconv.Atoi(val); err == nil && count > 0 {
+			cfg.Journal.MaxSegments = count
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/journal/compress", ""); err == nil && val != "" {
+		if compress, err := strconv.ParseBool(val); err == nil {
+			cfg.Journal.Compress = compress
+		}
+	}
+
+	// Cargar outbox de comandos (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/outbox/poll_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Outbox.PollInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/outbox/max_age_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Outbox.MaxAge = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/outbox/redeliver_after_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Outbox.RedeliverAfter = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/outbox/batch_size", ""); err == nil && val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			cfg.Outbox.BatchSize = size
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/outbox/retention_minutes", ""); err == nil && val != "" {
+		if minutes, err := strconv.Atoi(val); err == nil && minutes > 0 {
+			cfg.Outbox.Retention = time.Duration(minutes) * time.Minute
+		}
+	}
+
+	// Cargar monitor de heartbeats (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/liveness/check_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Liveness.CheckInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/liveness/missed_beats", ""); err == nil && val != "" {
+		if beats, err := strconv.Atoi(val); err == nil && beats > 0 {
+			cfg.Liveness.MissedBeats = beats
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/liveness/default_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Liveness.DefaultInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/liveness/persist_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Liveness.PersistInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/liveness/retention_hours", ""); err == nil && val != "" {
+		if hours, err := strconv.Atoi(val); err == nil && hours > 0 {
+			cfg.Liveness.Retention = time.Duration(hours) * time.Hour
+		}
+	}
+
+	// Cargar ownership de cuentas (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/ownership/takeover_grace_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms >= 0 {
+			cfg.Ownership.TakeoverGrace = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/ownership/check_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Ownership.CheckInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	// Cargar ConfigUpdate hacia los Agents (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/agent_config/enabled", ""); err == nil && val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.AgentConfig.Enabled = enabled
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/agent_config/poll_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.AgentConfig.PollInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	// Cargar autenticación de Agents (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/security/tls_cert_file", ""); err == nil && val != "" {
+		cfg.Security.TLSCertFile = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/security/tls_key_file", ""); err == nil && val != "" {
+		cfg.Security.TLSKeyFile = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/security/client_ca_file", ""); err == nil && val != "" {
+		cfg.Security.ClientCAFile = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/security/require_agent_identity", ""); err == nil && val != "" {
+		if require, err := strconv.ParseBool(val); err == nil {
+			cfg.Security.RequireAgentIdentity = require
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/security/agent_token_secret", ""); err == nil && val != "" {
+		cfg.Security.AgentTokenSecret = strings.TrimSpace(val)
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/security/allowlist_strict", ""); err == nil && val != "" {
+		if strict, err := strconv.ParseBool(val); err == nil {
+			cfg.Security.AllowlistStrict = strict
+		}
+	}
+	const agentAccountsPrefix = "core/security/agent_accounts/"
+	if vars, err := etcdClient.GetVarsWithPrefix(ctx, agentAccountsPrefix); err == nil {
+		for key, val := range vars {
+			agentID := strings.TrimPrefix(key, agentAccountsPrefix)
+			if agentID == "" {
+				continue
+			}
+			accounts := []string{}
+			for _, part := range strings.Split(val, ",") {
+				if account := strings.TrimSpace(part); account != "" {
+					accounts = append(accounts, account)
+				}
+			}
+			cfg.Security.AgentAccounts[agentID] = accounts
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/security/admin_identities", ""); err == nil && val != "" {
+		for _, part := range strings.Split(val, ",") {
+			if identity := strings.TrimSpace(part); identity != "" {
+				cfg.Security.AdminIdentities = append(cfg.Security.AdminIdentities, identity)
+			}
+		}
+	}
+
+	// Cargar entrega confiable del stream (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/stream/replay_buffer_size", ""); err == nil && val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			cfg.Stream.ReplayBufferSize = size
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "grpc/stream/ack_interval_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Stream.AckInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	// Cargar stream de eventos WatchEvents (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/events/subscriber_buffer", ""); err == nil && val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			cfg.Events.SubscriberBuffer = size
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/events/max_subscribers", ""); err == nil && val != "" {
+		if max, err := strconv.Atoi(val); err == nil && max > 0 {
+			cfg.Events.MaxSubscribers = max
+		}
+	}
+
+	// Cargar gateway HTTP/JSON (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/http/enabled", ""); err == nil && val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.HTTPGateway.Enabled = enabled
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/http/addr", ""); err == nil && val != "" {
+		cfg.HTTPGateway.Addr = val
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/http/api_keys", ""); err == nil && val != "" {
+		for _, part := range strings.Split(val, ",") {
+			if key := strings.TrimSpace(part); key != "" {
+				cfg.HTTPGateway.APIKeys = append(cfg.HTTPGateway.APIKeys, key)
+			}
+		}
+	}
+
+	// Cargar notifier de webhooks (i8)
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/notifier/enabled", ""); err == nil && val != "" {
+		if enabled, err := strconv.ParseBool(val); err == nil {
+			cfg.Notifier.Enabled = enabled
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/notifier/workers", ""); err == nil && val != "" {
+		if workers, err := strconv.Atoi(val); err == nil && workers > 0 {
+			cfg.Notifier.Workers = workers
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/notifier/queue_size", ""); err == nil && val != "" {
+		if size, err := strconv.Atoi(val); err == nil && size > 0 {
+			cfg.Notifier.QueueSize = size
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/notifier/timeout_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Notifier.Timeout = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/notifier/max_attempts", ""); err == nil && val != "" {
+		if attempts, err := strconv.Atoi(val); err == nil && attempts > 0 {
+			cfg.Notifier.MaxAttempts = attempts
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/notifier/initial_backoff_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Notifier.InitialBackoff = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := etcdClient.GetVarWithDefault(ctx, "core/notifier/max_backoff_ms", ""); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms > 0 {
+			cfg.Notifier.MaxBackoff = time.Duration(ms) * time.Millisecond
+		}
+	}
+	const notifierRulesPrefix = "core/notifier/rules/"
+	if vars, err := etcdClient.GetVarsWithPrefix(ctx, notifierRulesPrefix); err == nil {
+		for key, val := range vars {
+			name := strings.TrimPrefix(key, notifierRulesPrefix)
+			if name == "" {
+				continue
+			}
+			var rule NotifierRule
+			if err := json.Unmarshal([]byte(val), &rule); err != nil {
+				return nil, fmt.Errorf("invalid notifier rule %s: %w", key, err)
+			}
+			rule.Name = name
+			cfg.Notifier.Rules = append(cfg.Notifier.Rules, rule)
+		}
+		sort.Slice(cfg.Notifier.Rules, func(i, j int) bool {
+			return cfg.Notifier.Rules[i].Name < cfg.Notifier.Rules[j].Name
+		})
+	}
+
 	// Cargar PostgreSQL
 	if val, err := etcdClient.GetVarWithDefault(ctx, "postgres/host", ""); err == nil && val != "" {
 		cfg.PostgresHost = val
@@ -429,14 +1000,16 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 	}
 
 	// Validar configuración mínima requerida
-	if cfg.PostgresHost == "" {
-		return nil, fmt.Errorf("postgres/host not configured in ETCD")
-	}
-	if cfg.PostgresDatabase == "" {
-		return nil, fmt.Errorf("postgres/database not configured in ETCD")
-	}
-	if cfg.PostgresUser == "" {
-		return nil, fmt.Errorf("postgres/user not configured in ETCD")
+	if cfg.StorageBackend == StorageBackendPostgres {
+		if cfg.PostgresHost == "" {
+			return nil, fmt.Errorf("postgres/host not configured in ETCD")
+		}
+		if cfg.PostgresDatabase == "" {
+			return nil, fmt.Errorf("postgres/database not configured in ETCD")
+		}
+		if cfg.PostgresUser == "" {
+			return nil, fmt.Errorf("postgres/user not configured in ETCD")
+		}
 	}
 	if len(cfg.SlaveAccounts) == 0 {
 		return nil, fmt.Errorf("core/slave_accounts not configured in ETCD")
@@ -465,6 +1038,25 @@ func LoadConfig(ctx context.Context) (*Config, error) {
 	if cfg.Protocol.RequiredFeatures == nil {
 		cfg.Protocol.RequiredFeatures = []string{}
 	}
+	if (cfg.Security.TLSCertFile == "") != (cfg.Security.TLSKeyFile == "") {
+		return nil, fmt.Errorf("core/security/tls_cert_file and core/security/tls_key_file must be set together")
+	}
+	if cfg.Security.ClientCAFile != "" && cfg.Security.TLSCertFile == "" {
+		return nil, fmt.Errorf("core/security/client_ca_file requires core/security/tls_cert_file")
+	}
+	if cfg.HTTPGateway.Enabled && len(cfg.HTTPGateway.APIKeys) == 0 {
+		return nil, fmt.Errorf("core/http/enabled requires core/http/api_keys")
+	}
+	if matcher := cfg.SymbolMatcher; matcher.MinConfidence < 0 || matcher.AutoAcceptConfidence > 1 || matcher.MinConfidence > matcher.AutoAcceptConfidence {
+		return nil, fmt.Errorf("core/symbols/matcher thresholds must satisfy 0 <= min_confidence <= auto_accept_confidence <= 1")
+	}
+	if cfg.Notifier.Enabled {
+		for _, rule := range cfg.Notifier.Rules {
+			if err := rule.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid notifier rule core/notifier/rules/%s: %w", rule.Name, err)
+			}
+		}
+	}
 
 	return cfg, nil
 }
@@ -487,3 +1079,30 @@ func (c *Config) PostgresConnStr() string {
 		c.PostgresDatabase,
 	)
 }
+
+// journalSnapshot serializa la config para el registro CONFIG del journal.
+//
+// Omite secretos (password de PostgreSQL, agent_token_secret, API keys y reglas de
+// webhooks con sus headers): el replay no los usa.
+func (c *Config) journalSnapshot() ([]byte, error) {
+	snapshot := *c
+	snapshot.PostgresPassword = ""
+	snapshot.Security.AgentTokenSecret = ""
+	snapshot.HTTPGateway.APIKeys = nil
+	snapshot.Notifier.Rules = nil
+
+	data, err := json.Marshal(&snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// configFromJournalSnapshot reconstruye la config de un registro CONFIG del journal.
+func configFromJournalSnapshot(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config snapshot: %w", err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// killSwitchAccountID cuenta reservada del kill switch global (i8).
+//
+// Se persiste como una fila más de account_copy_control: con copy_enabled=false
+// ninguna cuenta copia, sin importar su estado individual.
+const killSwitchAccountID = "*"
+
+// copyControl mantiene en memoria qué cuentas tienen la copia deshabilitada (i8).
+//
+// El Router lo consulta en el hot path (Enabled); AdminService lo modifica con Set,
+// que persiste antes de aplicar para que el estado sobreviva a un reinicio.
+type copyControl struct {
+	repo domain.AccountCopyControlRepository
+
+	mu       sync.RWMutex
+	accounts map[string]*domain.AccountCopyControl // key: account_id
+
+	now func() time.Time
+}
+
+func newCopyControl(repo domain.AccountCopyControlRepository) *copyControl {
+	return &copyControl{
+		repo:     repo,
+		accounts: make(map[string]*domain.AccountCopyControl),
+		now:      time.Now,
+	}
+}
+
+// Load carga el estado persistido. Debe llamarse antes de arrancar el Router.
+func (c *copyControl) Load(ctx context.Context) (int, error) {
+	if c.repo == nil {
+		return 0, nil
+	}
+
+	controls, err := c.repo.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load copy control: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	disabled := 0
+	for _, control := range controls {
+		c.accounts[control.AccountID] = control
+		if !control.CopyEnabled {
+			disabled++
+		}
+	}
+	return disabled, nil
+}
+
+// Enabled indica si accountID copia (true si no tiene estado explícito).
+//
+// Con el kill switch activado retorna false para todas las cuentas.
+func (c *copyControl) Enabled(accountID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if global, ok := c.accounts[killSwitchAccountID]; ok && !global.CopyEnabled {
+		return false
+	}
+	control, ok := c.accounts[accountID]
+	return !ok || control.CopyEnabled
+}
+
+// KillSwitch retorna el estado del kill switch global (CopyEnabled=false = activado).
+func (c *copyControl) KillSwitch() domain.AccountCopyControl {
+	return c.Get(killSwitchAccountID)
+}
+
+// Get retorna el estado de accountID; sin estado explícito la copia está habilitada.
+func (c *copyControl) Get(accountID string) domain.AccountCopyControl {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if control, ok := c.accounts[accountID]; ok {
+		return *control
+	}
+	return domain.AccountCopyControl{AccountID: accountID, CopyEnabled: true}
+}
+
+// Set persiste y aplica el estado de copia de accountID.
+func (c *copyControl) Set(ctx context.Context, accountID string, enabled bool, reason, updatedBy string) (domain.AccountCopyControl, error) {
+	if accountID == "" {
+		return domain.AccountCopyControl{}, fmt.Errorf("account_id is required")
+	}
+
+	control := &domain.AccountCopyControl{
+		AccountID:   accountID,
+		CopyEnabled: enabled,
+		Reason:      reason,
+		UpdatedBy:   updatedBy,
+		UpdatedAtMs: c.now().UnixMilli(),
+	}
+	if c.repo != nil {
+		if err := c.repo.Upsert(ctx, control); err != nil {
+			return domain.AccountCopyControl{}, err
+		}
+	}
+
+	c.mu.Lock()
+	c.accounts[accountID] = control
+	c.mu.Unlock()
+	return *control, nil
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/core/internal/repository"
+)
+
+func TestCopyControlPersistsAndReloads(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryFactory(0).AccountCopyControlRepository()
+
+	control := newCopyControl(repo)
+	control.now = func() time.Time { return time.UnixMilli(1700000000000) }
+
+	// Sin estado explícito la copia está habilitada
+	assert.True(t, control.Enabled("s1"))
+	assert.True(t, control.Get("s1").CopyEnabled)
+
+	_, err := control.Set(ctx, "", false, "", "")
+	assert.Error(t, err)
+
+	updated, err := control.Set(ctx, "s1", false, "broker maintenance", "ops")
+	require.NoError(t, err)
+	assert.False(t, updated.CopyEnabled)
+	assert.Equal(t, int64(1700000000000), updated.UpdatedAtMs)
+	assert.False(t, control.Enabled("s1"))
+	assert.True(t, control.Enabled("s2"))
+
+	// Tras un reinicio el estado se restaura desde el repositorio
+	reloaded := newCopyControl(repo)
+	disabled, err := reloaded.Load(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, disabled)
+	assert.False(t, reloaded.Enabled("s1"))
+	assert.Equal(t, "ops", reloaded.Get("s1").UpdatedBy)
+
+	_, err = reloaded.Set(ctx, "s1", true, "", "ops")
+	require.NoError(t, err)
+	assert.True(t, reloaded.Enabled("s1"))
+}
+
+func TestCopyControlKillSwitch(t *testing.T) {
+	ctx := context.Background()
+	control := newCopyControl(repository.NewMemoryFactory(0).AccountCopyControlRepository())
+
+	assert.True(t, control.KillSwitch().CopyEnabled)
+
+	_, err := control.Set(ctx, killSwitchAccountID, false, "incident", "ops")
+	require.NoError(t, err)
+	assert.False(t, control.KillSwitch().CopyEnabled)
+	assert.False(t, control.Enabled("s1"), "kill switch disables every account")
+
+	_, err = control.Set(ctx, killSwitchAccountID, true, "", "ops")
+	require.NoError(t, err)
+	assert.True(t, control.Enabled("s1"))
+}
@@ -9,14 +9,17 @@ import (
 	"database/sql"
 	"fmt"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/xKoRx/echo/core/internal/journal"
 	"github.com/xKoRx/echo/core/internal/repository"
 	"github.com/xKoRx/echo/core/internal/riskengine"
 	"github.com/xKoRx/echo/core/internal/volumeguard"
 	"github.com/xKoRx/echo/sdk/domain"
 	"github.com/xKoRx/echo/sdk/domain/handshake"
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
 	pb "github.com/xKoRx/echo/sdk/pb/v1"
 	"github.com/xKoRx/echo/sdk/telemetry"
 	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
@@ -24,6 +27,7 @@ import (
 	"github.com/xKoRx/echo/sdk/utils"
 	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
 )
@@ -46,6 +50,9 @@ type Core struct {
 	// Config (cargada desde ETCD)
 	config *Config
 
+	// Hora e IDs generados (el replay del journal los reemplaza; nil = sistema)
+	clock coreClock
+
 	// gRPC Server
 	grpcServer *grpc.Server
 	listener   net.Listener
@@ -54,6 +61,10 @@ type Core struct {
 	agents   map[string]*AgentConnection // key: agent_id
 	agentsMu sync.RWMutex
 
+	// i8: Sesiones de entrega confiable (sobreviven a los resets del stream)
+	streamSessions   map[string]*streamSession // key: agent_id
+	streamSessionsMu sync.Mutex
+
 	// i2: Registry de ownership de cuentas (estado operacional en memoria)
 	accountRegistry *AccountRegistry
 
@@ -79,9 +90,39 @@ type Core struct {
 	accountStateService *AccountStateService
 	riskEngine          *riskengine.FixedRiskEngine
 
+	// Matcher de símbolos reportados sin canónico (nil si core/symbols/matcher/enabled=false)
+	symbolMatcher *symbolMatcher
+
 	// Router/Processor
 	router *Router
 
+	// Outbox transaccional de comandos (i8)
+	outboxDispatcher *outboxDispatcher
+
+	// Liveness de Agents según heartbeats (i8)
+	livenessMonitor *livenessMonitor
+
+	// Ownership persistido de cuentas y conflictos entre Agents (i8)
+	ownershipManager *ownershipManager
+
+	// Configuración de Agents publicada con ConfigUpdate (nil si está deshabilitada)
+	agentConfigPublisher *agentConfigPublisher
+
+	// Copia habilitada/deshabilitada por cuenta (i8, AdminService)
+	copyControl *copyControl
+
+	// Stream de eventos para WatchEvents (i8, AdminService)
+	events *eventHub
+
+	// API admin REST/JSON (nil si core/http/enabled=false)
+	httpGateway *httpGateway
+
+	// Webhooks de alertas (nil si core/notifier/enabled=false)
+	notifier *notifier
+
+	// Journal durable de mensajes inbound/outbound (nil si está deshabilitado)
+	journal *journal.Writer
+
 	// Telemetría
 	telemetry   *telemetry.Client
 	echoMetrics *metricbundle.EchoMetrics
@@ -109,6 +150,42 @@ func DefaultConfig() *Config {
 	return nil // Deprecated in i1
 }
 
+// coreClock fuente de hora e IDs del Core.
+//
+// En producción es la hora del sistema y UUIDv7; el replay del journal usa la hora de
+// llegada de cada registro e IDs determinísticos para reproducir el incidente.
+type coreClock interface {
+	Now() time.Time
+	NewID() string
+}
+
+// systemClock coreClock de producción.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+func (systemClock) NewID() string  { return utils.GenerateUUIDv7() }
+
+// now retorna la hora del Core.
+func (c *Core) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// nowMs retorna la hora del Core en Unix ms.
+func (c *Core) nowMs() int64 {
+	return c.now().UnixMilli()
+}
+
+// newID genera un ID de comando (UUIDv7 en producción).
+func (c *Core) newID() string {
+	if c.clock == nil {
+		return utils.GenerateUUIDv7()
+	}
+	return c.clock.NewID()
+}
+
 // AgentConnection representa una conexión de Agent al Core.
 type AgentConnection struct {
 	AgentID   string
@@ -117,6 +194,9 @@ type AgentConnection struct {
 	ctx       context.Context
 	cancel    context.CancelFunc
 	createdAt time.Time
+
+	// i8: nil si el Agent no negoció entrega confiable
+	session *streamSession
 }
 
 // New crea una nueva instancia de Core (i1).
@@ -135,37 +215,29 @@ type AgentConnection struct {
 //	}
 //	defer core.Shutdown()
 func New(ctx context.Context) (*Core, error) {
-	// Crear contexto cancelable
-	coreCtx, cancel := context.WithCancel(ctx)
-
 	// 1. Cargar configuración desde ETCD
-	config, err := LoadConfig(coreCtx)
+	config, err := LoadConfig(ctx)
 	if err != nil {
-		cancel()
 		return nil, fmt.Errorf("failed to load config from ETCD: %w", err)
 	}
 
-	// 2. Conectar a PostgreSQL
-	db, err := sql.Open("postgres", config.PostgresConnStr())
-	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("failed to open postgres connection: %w", err)
-	}
+	return newCore(ctx, config, systemClock{})
+}
 
-	// Verificar conexión
-	if err := db.PingContext(coreCtx); err != nil {
+// newCore construye el Core a partir de una configuración ya cargada.
+//
+// No arranca el servidor gRPC ni el router (ver Start); lo reutiliza el replay del journal
+// con un clock que reproduce la hora de cada registro.
+func newCore(ctx context.Context, config *Config, clock coreClock) (*Core, error) {
+	// Crear contexto cancelable
+	coreCtx, cancel := context.WithCancel(ctx)
+
+	// 2-3. Conectar persistencia y crear repository factory
+	db, repoFactory, err := newRepositoryFactory(coreCtx, config, clock.Now)
+	if err != nil {
 		cancel()
-		db.Close()
-		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+		return nil, err
 	}
-
-	// Configurar pool
-	db.SetMaxOpenConns(config.PostgresPoolMaxConn)
-	db.SetMaxIdleConns(config.PostgresPoolMinConn)
-	db.SetConnMaxLifetime(1 * time.Hour)
-
-	// 3. Crear repository factory
-	repoFactory := repository.NewPostgresFactory(db)
 	correlationSvc := repoFactory.CorrelationService()
 
 	// 4. Crear dedupe service persistente (i1)
@@ -193,7 +265,7 @@ func New(ctx context.Context) (*Core, error) {
 	)
 	if err != nil {
 		cancel()
-		db.Close()
+		closeDB(db)
 		return nil, fmt.Errorf("failed to initialize telemetry: %w", err)
 	}
 
@@ -202,7 +274,7 @@ func New(ctx context.Context) (*Core, error) {
 	if echoMetrics == nil {
 		cancel()
 		telClient.Shutdown(coreCtx)
-		db.Close()
+		closeDB(db)
 		return nil, fmt.Errorf("failed to get EchoMetrics bundle")
 	}
 
@@ -211,6 +283,45 @@ func New(ctx context.Context) (*Core, error) {
 		semconv.Echo.Component.String(semconv.ComponentValues.Core),
 	)
 
+	// Verificar/aplicar migraciones de schema antes de usar los repositorios
+	if err := applyMigrations(coreCtx, db, config, telClient); err != nil {
+		cancel()
+		telClient.Shutdown(coreCtx)
+		closeDB(db)
+		return nil, err
+	}
+
+	// Journal durable de mensajes (opcional)
+	var messageJournal *journal.Writer
+	if config.Journal.Enabled {
+		// Config vigente al inicio de cada segmento: el replay no depende de ETCD
+		snapshot, err := config.journalSnapshot()
+		if err != nil {
+			cancel()
+			telClient.Shutdown(coreCtx)
+			closeDB(db)
+			return nil, err
+		}
+		messageJournal, err = journal.Open(journal.Options{
+			Dir:             config.Journal.Dir,
+			SegmentMaxBytes: config.Journal.SegmentMaxBytes,
+			MaxSegments:     config.Journal.MaxSegments,
+			Compress:        config.Journal.Compress,
+			ConfigSnapshot:  snapshot,
+			OnError: func(err error) {
+				telClient.Warn(coreCtx, "Journal write failed",
+					attribute.String("error", err.Error()),
+				)
+			},
+		})
+		if err != nil {
+			cancel()
+			telClient.Shutdown(coreCtx)
+			closeDB(db)
+			return nil, fmt.Errorf("failed to open message journal: %w", err)
+		}
+	}
+
 	// 6. Crear validadores y resolvers de símbolos (i3)
 	unknownAction := UnknownActionWarn
 	if config.UnknownAction == "reject" {
@@ -220,26 +331,57 @@ func New(ctx context.Context) (*Core, error) {
 	symbolResolver := NewAccountSymbolResolver(coreCtx, repoFactory.SymbolRepository(), telClient, echoMetrics, 1000)
 	symbolResolver.Start() // Iniciar worker de persistencia async
 	symbolSpecService := NewSymbolSpecService(repoFactory.SymbolSpecRepository(), telClient, echoMetrics, config.CanonicalSymbols)
+	symbolSpecService.clock = clock.Now
 	symbolQuoteService := NewSymbolQuoteService(repoFactory.SymbolQuoteRepository(), telClient)
 	accountStateService := NewAccountStateService(telClient)
 	riskPolicySvc := NewRiskPolicyService(repoFactory.RiskPolicyRepository(), config.Risk.CacheTTL, telClient, echoMetrics)
-	volumeGuard := volumeguard.New(symbolSpecService, config.VolumeGuard, telClient, echoMetrics)
-	riskEngineCfg := riskengine.Config{
-		MaxQuoteAge:              config.Risk.Engine.QuoteMaxAge,
-		MinDistancePoints:        config.Risk.Engine.MinDistancePoints,
-		MaxRiskDrift:             config.Risk.Engine.MaxRiskDrift,
-		DefaultCurrency:          config.Risk.Engine.DefaultCurrency,
-		EnableCurrencyFallback:   config.Risk.Engine.EnableCurrencyFallback,
-		RejectOnMissingTickValue: config.Risk.Engine.RejectOnMissingTickValue,
-	}
+	volumeGuard := volumeguard.NewWithClock(symbolSpecService, config.VolumeGuard, telClient, echoMetrics, clock.Now)
+	riskEngineCfg := newRiskEngineConfig(config)
+	riskEngineCfg.Now = clock.Now
 	fixedRiskEngine := riskengine.NewFixedRiskEngine(symbolSpecService, symbolQuoteService, accountStateService, &symbolInfoAdapter{resolver: symbolResolver}, volumeGuard, riskEngineCfg, telClient, echoMetrics)
+	// LISTEN/NOTIFY solo aplica a PostgreSQL; memoria y SQLite notifican en proceso
+	listenConnStr := ""
+	if config.StorageBackend == StorageBackendPostgres {
+		listenConnStr = config.PostgresConnStr()
+	}
+	notifier, hasNotifier := repoFactory.(repository.ChangeNotifier)
+	if hasNotifier {
+		notifier.OnRiskPolicyChanged(riskPolicySvc.Invalidate)
+		// i8: overrides de echo-core-cli symbols pin/unpin
+		notifier.OnSymbolMappingChanged(func(accountID string) {
+			_ = symbolResolver.InvalidateAccount(coreCtx, accountID)
+		})
+	}
+	if err := symbolResolver.StartListener(listenConnStr); err != nil {
+		telClient.Warn(coreCtx, "Failed to start symbol mapping listener (i8)",
+			attribute.String("error", err.Error()),
+		)
+	}
 	if rs, ok := riskPolicySvc.(*riskPolicyService); ok {
-		if err := rs.StartListener(coreCtx, config.PostgresConnStr()); err != nil {
+		if err := rs.StartListener(coreCtx, listenConnStr); err != nil {
 			telClient.Warn(coreCtx, "Failed to start risk policy listener",
 				attribute.String("error", err.Error()),
 			)
 		}
 	}
+	// SQLite: revisiones y overrides escritos por echo-core-cli desde otro proceso (i8)
+	if sqliteFactory, ok := repoFactory.(*repository.SQLiteFactory); ok {
+		if err := sqliteFactory.WatchRiskPolicyHistory(coreCtx, config.Risk.PolicyPollInterval); err != nil {
+			telClient.Warn(coreCtx, "Failed to start risk policy history watcher (i8)",
+				attribute.String("error", err.Error()),
+			)
+		}
+		if err := sqliteFactory.WatchSymbolOverrideChanges(coreCtx, config.SymbolOverridePollInterval); err != nil {
+			telClient.Warn(coreCtx, "Failed to start symbol override watcher (i8)",
+				attribute.String("error", err.Error()),
+			)
+		}
+	}
+
+	var matcher *symbolMatcher
+	if config.SymbolMatcher.Enabled {
+		matcher = newSymbolMatcher(config.SymbolMatcher, config.CanonicalSymbols)
+	}
 
 	// Registrar carga inicial de símbolos canónicos desde ETCD
 	echoMetrics.RecordSymbolsLoaded(coreCtx, "etcd", len(config.CanonicalSymbols),
@@ -269,6 +411,7 @@ func New(ctx context.Context) (*Core, error) {
 	// 7. Crear Core
 	core := &Core{
 		config:              config,
+		clock:               clock,
 		db:                  db,
 		repoFactory:         repoFactory,
 		correlationSvc:      correlationSvc,
@@ -281,8 +424,11 @@ func New(ctx context.Context) (*Core, error) {
 		symbolQuoteService:  symbolQuoteService,
 		accountStateService: accountStateService,
 		riskEngine:          fixedRiskEngine,
+		symbolMatcher:       matcher,
 		agents:              make(map[string]*AgentConnection),
-		accountRegistry:     NewAccountRegistry(telClient), // NEW i2
+		streamSessions:      make(map[string]*streamSession),
+		accountRegistry:     NewAccountRegistry(telClient, config.Ownership.TakeoverGrace), // NEW i2
+		journal:             messageJournal,
 		telemetry:           telClient,
 		echoMetrics:         echoMetrics,
 		ctx:                 coreCtx,
@@ -314,7 +460,12 @@ func New(ctx context.Context) (*Core, error) {
 			core.handshakeReconciler.Notify(accountID)
 		})
 	}
-	if err := core.handshakeReconciler.StartListener(config.PostgresConnStr()); err != nil {
+	if hasNotifier {
+		notifier.OnHandshakeEvaluated(func(accountID, _ string) {
+			core.handshakeReconciler.Notify(accountID)
+		})
+	}
+	if err := core.handshakeReconciler.StartListener(listenConnStr); err != nil {
 		telClient.Warn(coreCtx, "Failed to start handshake listener",
 			attribute.String("error", err.Error()),
 		)
@@ -323,6 +474,46 @@ func New(ctx context.Context) (*Core, error) {
 	// 8. Crear router
 	core.router = NewRouter(core)
 
+	// 9. Dispatcher del outbox de comandos (i8)
+	core.outboxDispatcher = newOutboxDispatcher(coreCtx, core.router, repoFactory.OutboxRepository(), config.Outbox, telClient)
+
+	// 10. Monitor de heartbeats: al restaurar cuentas se entrega el outbox (i8)
+	core.livenessMonitor = newLivenessMonitor(coreCtx, core.accountRegistry, repoFactory.AgentLivenessRepository(), config.Liveness, telClient, echoMetrics, core.outboxDispatcher.Kick)
+
+	// 11. Ownership de cuentas: persistencia, takeovers y cuarentena (i8)
+	core.ownershipManager = newOwnershipManager(coreCtx, core.accountRegistry, repoFactory.AccountOwnershipRepository(), config.Ownership, telClient, echoMetrics, core.outboxDispatcher.Kick)
+
+	// 12. ConfigUpdate hacia los Agents desde core/agent_config/ (i8)
+	if config.AgentConfig.Enabled {
+		core.agentConfigPublisher = newAgentConfigPublisher(coreCtx, config.EtcdEnv, config.AgentConfig, core.sendConfigUpdate, core.connectedAgentIDs, telClient, echoMetrics)
+	}
+
+	// 13. Copia por cuenta (i8): se carga en Start, la modifica AdminService
+	core.copyControl = newCopyControl(repoFactory.AccountCopyControlRepository())
+
+	// 14. Eventos en vivo para WatchEvents (i8): sin suscriptores no se arman eventos
+	core.events = newEventHub(config.Events, echoMetrics)
+	handshakeRegistry.OnStatusChange(core.publishHandshakeChanged)
+
+	// 15. Gateway HTTP/JSON de la API admin (i8): arranca en Start
+	if config.HTTPGateway.Enabled {
+		core.httpGateway = newHTTPGateway(core, config.HTTPGateway)
+	}
+
+	// 16. Webhooks de alertas sobre los CoreEvents (i8)
+	if config.Notifier.Enabled {
+		core.notifier, err = newNotifier(coreCtx, config.Notifier, core.events, repoFactory.NotificationDeadLetterRepository(), telClient, echoMetrics)
+		if err != nil {
+			cancel()
+			if messageJournal != nil {
+				messageJournal.Close()
+			}
+			telClient.Shutdown(coreCtx)
+			closeDB(db)
+			return nil, fmt.Errorf("failed to create notifier: %w", err)
+		}
+	}
+
 	// Log de inicio
 	telClient.Info(coreCtx, "Core initialized (i3)",
 		attribute.Int("grpc_port", config.GRPCPort),
@@ -330,6 +521,7 @@ func New(ctx context.Context) (*Core, error) {
 		attribute.StringSlice("symbol_whitelist", config.SymbolWhitelist), // Deprecated pero mantenido
 		attribute.String("unknown_action", config.UnknownAction),
 		attribute.Float64("default_lot_size", config.DefaultLotSize),
+		attribute.String("storage_backend", config.StorageBackend),
 		attribute.String("postgres_host", config.PostgresHost),
 		attribute.String("postgres_database", config.PostgresDatabase),
 		attribute.String("log_level", config.LogLevel),
@@ -338,6 +530,63 @@ func New(ctx context.Context) (*Core, error) {
 	return core, nil
 }
 
+// newRepositoryFactory crea el factory de repositorios según storage/backend.
+//
+// Retorna db nil para el backend en memoria.
+func newRepositoryFactory(ctx context.Context, config *Config, now func() time.Time) (*sql.DB, domain.RepositoryFactory, error) {
+	db, err := openDatabase(ctx, config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch config.StorageBackend {
+	case StorageBackendMemory:
+		factory := repository.NewMemoryFactory(config.DedupeTTL)
+		factory.SetClock(now)
+		return nil, factory, nil
+	case StorageBackendSQLite:
+		factory := repository.NewSQLiteFactory(db, config.DedupeTTL)
+		factory.SetClock(now)
+		return db, factory, nil
+	default:
+		return db, repository.NewPostgresFactory(db), nil
+	}
+}
+
+// openDatabase abre la base configurada en storage/backend (nil para memoria).
+func openDatabase(ctx context.Context, config *Config) (*sql.DB, error) {
+	switch config.StorageBackend {
+	case StorageBackendMemory:
+		return nil, nil
+	case StorageBackendSQLite:
+		return repository.OpenSQLite(ctx, config.SQLitePath)
+	}
+
+	db, err := sql.Open("postgres", config.PostgresConnStr())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres connection: %w", err)
+	}
+
+	// Verificar conexión
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping postgres: %w", err)
+	}
+
+	// Configurar pool
+	db.SetMaxOpenConns(config.PostgresPoolMaxConn)
+	db.SetMaxIdleConns(config.PostgresPoolMinConn)
+	db.SetConnMaxLifetime(1 * time.Hour)
+
+	return db, nil
+}
+
+func closeDB(db *sql.DB) {
+	if db != nil {
+		db.Close()
+	}
+}
+
 // Start inicia el Core (servidor gRPC con KeepAlive, router, dedupe cleanup persistente).
 func (c *Core) Start() error {
 	c.mu.Lock()
@@ -347,6 +596,20 @@ func (c *Core) Start() error {
 	}
 	c.mu.Unlock()
 
+	// i8: Restaurar ownership persistido antes de aceptar Agents
+	if c.ownershipManager != nil {
+		c.ownershipManager.Start()
+	}
+
+	// i8: Cuentas con copia deshabilitada antes de procesar TradeIntents
+	disabled, err := c.copyControl.Load(c.ctx)
+	if err != nil {
+		return err
+	}
+	c.telemetry.Info(c.ctx, "Copy control loaded (i8)",
+		attribute.Int("disabled_accounts", disabled),
+	)
+
 	// Crear listener TCP
 	addr := fmt.Sprintf(":%d", c.config.GRPCPort)
 	lis, err := net.Listen("tcp", addr)
@@ -365,17 +628,66 @@ func (c *Core) Start() error {
 		PermitWithoutStream: false,                     // no pings sin stream activo
 	}
 
-	c.grpcServer = grpc.NewServer(
+	serverOpts := []grpc.ServerOption{
 		grpc.KeepaliveParams(kaParams),
 		grpc.KeepaliveEnforcementPolicy(kaPolicy),
 		// TODO i2: agregar interceptors de telemetría
+	}
+
+	// i8: TLS/mTLS e identidad de Agents
+	security := c.config.Security
+	if security.TLSCertFile != "" {
+		tlsConfig, err := (&grpcSDK.TLSConfig{
+			CertFile:     security.TLSCertFile,
+			KeyFile:      security.TLSKeyFile,
+			ClientCAFile: security.ClientCAFile,
+		}).ServerTLS()
+		if err != nil {
+			lis.Close()
+			return fmt.Errorf("failed to configure gRPC TLS: %w", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	identityConfig := grpcSDK.IdentityConfig{
+		Require:     security.RequireAgentIdentity,
+		TokenSecret: []byte(security.AgentTokenSecret),
+		OnReject: func(ctx context.Context, method, reason string) {
+			c.telemetry.Warn(c.ctx, "Agent identity rejected (i8)",
+				attribute.String("method", method),
+				attribute.String("reason", reason),
+			)
+			c.echoMetrics.RecordAgentAuthRejected(c.ctx, "identity")
+		},
+	}
+	adminSvc := newAdminService(c)
+	serverOpts = append(serverOpts,
+		grpc.ChainUnaryInterceptor(
+			grpcSDK.AgentIdentityUnaryServerInterceptor(identityConfig),
+			adminSvc.unaryInterceptor(),
+		),
+		grpc.ChainStreamInterceptor(
+			grpcSDK.AgentIdentityStreamServerInterceptor(identityConfig),
+			adminSvc.streamInterceptor(),
+		),
 	)
 
-	// Registrar servicio
+	c.grpcServer = grpc.NewServer(serverOpts...)
+
+	if security.RequireAgentIdentity && len(security.AdminIdentities) == 0 {
+		c.telemetry.Warn(c.ctx, "AdminService disabled: core/security/admin_identities is empty (i8)")
+	}
+
+	// Registrar servicios. i8: AdminService comparte TLS e interceptors de identidad
 	pb.RegisterAgentServiceServer(c.grpcServer, c)
+	pb.RegisterAdminServiceServer(c.grpcServer, adminSvc)
 
 	c.telemetry.Info(c.ctx, "gRPC server listening (i1)",
 		attribute.String("address", addr),
+		attribute.Bool("tls", security.TLSCertFile != ""),
+		attribute.Bool("mtls", security.ClientCAFile != ""),
+		attribute.Bool("require_agent_identity", security.RequireAgentIdentity),
+		attribute.Int("admin_identities", len(security.AdminIdentities)),
+		attribute.Int("event_subscriber_buffer", c.config.Events.SubscriberBuffer),
 		attribute.String("keepalive_time", c.config.KeepAliveTime.String()),
 		attribute.String("keepalive_timeout", c.config.KeepAliveTimeout.String()),
 		attribute.String("keepalive_min_time", c.config.KeepAliveMinTime.String()),
@@ -405,6 +717,33 @@ func (c *Core) Start() error {
 		c.handshakeReconciler.Start()
 	}
 
+	// Entregar comandos pendientes del outbox (i8)
+	if c.outboxDispatcher != nil {
+		c.outboxDispatcher.Start()
+	}
+
+	if c.livenessMonitor != nil {
+		c.livenessMonitor.Start()
+	}
+
+	if c.agentConfigPublisher != nil {
+		c.agentConfigPublisher.Start()
+	}
+
+	// i8: Webhooks de alertas
+	if c.notifier != nil {
+		if err := c.notifier.Start(); err != nil {
+			return err
+		}
+	}
+
+	// i8: API admin REST/JSON
+	if c.httpGateway != nil {
+		if err := c.httpGateway.Start(); err != nil {
+			return fmt.Errorf("failed to start HTTP gateway: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -414,8 +753,9 @@ func (c *Core) Start() error {
 func (c *Core) StreamBidi(stream pb.AgentService_StreamBidiServer) error {
 	ctx := stream.Context()
 
+	// i8: La identidad autenticada (certificado o agent-token) prevalece sobre el metadata
 	// Issue #C7: Extraer agent_id de metadata gRPC
-	agentID, err := extractAgentIDFromMetadata(ctx)
+	agentID, err := agentIDFromContext(ctx)
 	if err != nil {
 		c.telemetry.Warn(c.ctx, "Agent connected without agent-id metadata, using generated ID",
 			attribute.String("error", err.Error()),
@@ -428,6 +768,35 @@ func (c *Core) StreamBidi(stream pb.AgentService_StreamBidiServer) error {
 		attribute.String("agent_id", agentID),
 	)
 
+	// i8: Resume handshake. Un Agent i8 abre el stream con AgentHello + session_id
+	msg, err := stream.Recv()
+	if err != nil {
+		c.telemetry.Warn(c.ctx, "Agent disconnected before first message",
+			attribute.String("agent_id", agentID),
+			attribute.String("error", err.Error()),
+		)
+		return err
+	}
+	session, resumed := c.resumeStreamSession(agentID, msg.GetHello())
+	if resumed != nil {
+		if err := stream.Send(&pb.CoreMessage{
+			TimestampMs: utils.NowUnixMilli(),
+			Payload:     &pb.CoreMessage_StreamResumed{StreamResumed: resumed},
+		}); err != nil {
+			return err
+		}
+		// Replay antes de liberar el send loop: el Agent recibe los seq en orden.
+		// Una sesión nueva solo contiene lo heredado de la anterior, con seq nuevos.
+		for _, pending := range session.reliable.Unacked() {
+			if err := stream.Send(pending); err != nil {
+				return err
+			}
+			if c.journal != nil {
+				c.journal.RecordOutbound(agentID, pending)
+			}
+		}
+	}
+
 	// Crear conexión
 	agentCtx, agentCancel := context.WithCancel(ctx)
 	conn := &AgentConnection{
@@ -437,13 +806,22 @@ func (c *Core) StreamBidi(stream pb.AgentService_StreamBidiServer) error {
 		ctx:       agentCtx,
 		cancel:    agentCancel,
 		createdAt: time.Now(),
+		session:   session,
 	}
 
 	// Registrar agent
 	c.registerAgent(agentID, conn)
 	defer func() {
-		c.unregisterAgent(agentID)
-		c.accountRegistry.UnregisterAgent(agentID) // NEW i2: limpiar registry
+		// i8: si el Agent ya reconectó, el stream nuevo conserva registro y cuentas
+		if c.unregisterAgent(agentID, conn) {
+			c.accountRegistry.UnregisterAgent(agentID) // NEW i2: limpiar registry (i8: cuentas DETACHED)
+			if c.livenessMonitor != nil {
+				c.livenessMonitor.Disconnected(agentID)
+			}
+			if c.agentConfigPublisher != nil {
+				c.agentConfigPublisher.Disconnected(agentID)
+			}
+		}
 		agentCancel()
 		close(conn.SendCh)
 	}()
@@ -455,9 +833,26 @@ func (c *Core) StreamBidi(stream pb.AgentService_StreamBidiServer) error {
 		c.sendToAgentLoop(conn)
 	}()
 
-	// Goroutine de lectura (recibe AgentMessages del Agent)
+	// i8: Configuración central del Agent (en cada stream: el Agent re-confirma la versión vigente)
+	if c.agentConfigPublisher != nil {
+		c.agentConfigPublisher.PushTo(agentID)
+	}
+
+	// Goroutine de lectura (recibe AgentMessages del Agent, empezando por el primero ya leído)
 	for {
-		msg, err := stream.Recv()
+		if c.journal != nil {
+			c.journal.RecordInbound(agentID, msg)
+		}
+
+		accepted, err := c.acceptAgentMessage(conn, msg)
+		if err != nil {
+			return err
+		}
+		if accepted {
+			c.dispatchAgentMessage(agentCtx, agentID, msg, c.router.HandleAgentMessage)
+		}
+
+		msg, err = stream.Recv()
 		if err != nil {
 			c.telemetry.Warn(c.ctx, "Agent disconnected",
 				attribute.String("agent_id", agentID),
@@ -465,46 +860,158 @@ func (c *Core) StreamBidi(stream pb.AgentService_StreamBidiServer) error {
 			)
 			return err
 		}
+	}
+}
 
-		// NEW i2: Procesar AgentHello para logging (sin ownership)
-		if hello := msg.GetHello(); hello != nil {
-			c.handleAgentHello(agentID, hello)
-			continue // No enviar al router
-		}
+// dispatchAgentMessage procesa los mensajes de control en Core y delega el resto a route.
+//
+// route es Router.HandleAgentMessage en producción; el replay del journal lo procesa
+// de forma síncrona.
+func (c *Core) dispatchAgentMessage(ctx context.Context, agentID string, msg *pb.AgentMessage, route func(ctx context.Context, agentID string, msg *pb.AgentMessage)) {
+	// i8: Los acks son de transporte (StreamBidi ya los aplicó)
+	if msg.GetAck() != nil {
+		return
+	}
 
-		// NEW i2: Procesar AccountConnected
-		if accountConn := msg.GetAccountConnected(); accountConn != nil {
-			c.handleAccountConnected(agentID, accountConn)
-			continue
-		}
+	// NEW i2: Procesar AgentHello para logging (sin ownership)
+	if hello := msg.GetHello(); hello != nil {
+		c.handleAgentHello(agentID, hello)
+		return // No enviar al router
+	}
 
-		// NEW i2: Procesar AccountDisconnected
-		if accountDisconn := msg.GetAccountDisconnected(); accountDisconn != nil {
-			c.handleAccountDisconnected(agentID, accountDisconn)
-			continue
+	// i8: Respuesta del Agent a un ConfigUpdate
+	if applied := msg.GetConfigApplied(); applied != nil {
+		if c.agentConfigPublisher != nil {
+			c.agentConfigPublisher.HandleApplied(agentID, applied)
 		}
+		return
+	}
 
-		// NEW i3: Procesar AccountSymbolsReport
-		if symbolsReport := msg.GetAccountSymbolsReport(); symbolsReport != nil {
-			c.handleAccountSymbolsReport(agentCtx, agentID, symbolsReport)
-			continue
+	// i8: Los heartbeats solo alimentan el monitor de liveness
+	if heartbeat := msg.GetHeartbeat(); heartbeat != nil {
+		if c.livenessMonitor != nil {
+			c.livenessMonitor.Observe(agentID, heartbeat)
 		}
+		return
+	}
 
-		// NEW i3+: Procesar reportes de especificaciones
-		if specReport := msg.GetSymbolSpecReport(); specReport != nil {
-			c.handleSymbolSpecReport(agentCtx, agentID, specReport)
-			continue
-		}
+	// i8: Vencido en el store-and-forward del Agent: no es una señal en tiempo real
+	if msg.Expired {
+		c.handleExpiredAgentMessage(ctx, agentID, msg)
+		return
+	}
 
-		// NEW i3+: Procesar snapshots de precios
-		if quoteSnapshot := msg.GetSymbolQuoteSnapshot(); quoteSnapshot != nil {
-			c.handleSymbolQuoteSnapshot(agentCtx, agentID, quoteSnapshot)
-			continue
+	// i8: Allowlist agent → cuentas
+	if accountID := agentMessageAccountID(msg); accountID != "" && !c.config.Security.AccountAllowed(agentID, accountID) {
+		c.telemetry.Warn(c.ctx, "Agent message dropped: account not allowed for agent (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.String("account_id", accountID),
+			attribute.String("payload_type", fmt.Sprintf("%T", msg.Payload)),
+		)
+		c.echoMetrics.RecordAgentAuthRejected(ctx, "account_not_allowed",
+			attribute.String("agent_id", agentID),
+		)
+		return
+	}
+
+	// i8: Un claimant en cuarentena no opera la cuenta de otro Agent
+	// (AccountConnected/AccountDisconnected sí: resuelven el conflicto)
+	if accountID := agentMessageAccountID(msg); accountID != "" && msg.GetAccountConnected() == nil && msg.GetAccountDisconnected() == nil {
+		if owner, conflict := c.accountRegistry.ConflictingOwner(agentID, accountID); conflict {
+			c.telemetry.Warn(c.ctx, "Agent message dropped: account owned by another Agent (i8)",
+				attribute.String("agent_id", agentID),
+				attribute.String("account_id", accountID),
+				attribute.String("owner_agent_id", owner),
+				attribute.String("payload_type", fmt.Sprintf("%T", msg.Payload)),
+			)
+			c.echoMetrics.RecordAccountOwnershipEvent(ctx, "dropped",
+				attribute.String("account_id", accountID),
+			)
+			return
 		}
+	}
+
+	// NEW i2: Procesar AccountConnected
+	if accountConn := msg.GetAccountConnected(); accountConn != nil {
+		c.handleAccountConnected(agentID, accountConn)
+		return
+	}
+
+	// NEW i2: Procesar AccountDisconnected
+	if accountDisconn := msg.GetAccountDisconnected(); accountDisconn != nil {
+		c.handleAccountDisconnected(agentID, accountDisconn)
+		return
+	}
+
+	// NEW i3: Procesar AccountSymbolsReport
+	if symbolsReport := msg.GetAccountSymbolsReport(); symbolsReport != nil {
+		c.handleAccountSymbolsReport(ctx, agentID, symbolsReport)
+		return
+	}
+
+	// NEW i3+: Procesar reportes de especificaciones
+	if specReport := msg.GetSymbolSpecReport(); specReport != nil {
+		c.handleSymbolSpecReport(ctx, agentID, specReport)
+		return
+	}
+
+	// NEW i3+: Procesar snapshots de precios
+	if quoteSnapshot := msg.GetSymbolQuoteSnapshot(); quoteSnapshot != nil {
+		c.handleSymbolQuoteSnapshot(ctx, agentID, quoteSnapshot)
+		return
+	}
+
+	// Enviar al router para procesamiento
+	route(ctx, agentID, msg)
+}
 
-		// Enviar al router para procesamiento
-		c.router.HandleAgentMessage(agentCtx, agentID, msg)
+// agentMessageAccountID retorna la cuenta a la que se refiere un AgentMessage ("" si no aplica).
+//
+// ExecutionResult y TradeModify no traen account_id: el Router resuelve la cuenta
+// (command context / trade) y aplica los mismos filtros (Router.authorizeAccountReport).
+func agentMessageAccountID(msg *pb.AgentMessage) string {
+	switch {
+	case msg.GetTradeIntent() != nil:
+		return msg.GetTradeIntent().ClientId
+	case msg.GetTradeClose() != nil:
+		return msg.GetTradeClose().AccountId
+	case msg.GetAccountConnected() != nil:
+		return msg.GetAccountConnected().AccountId
+	case msg.GetAccountDisconnected() != nil:
+		return msg.GetAccountDisconnected().AccountId
+	case msg.GetAccountSymbolsReport() != nil:
+		return msg.GetAccountSymbolsReport().AccountId
+	case msg.GetSymbolSpecReport() != nil:
+		return msg.GetSymbolSpecReport().AccountId
+	case msg.GetSymbolQuoteSnapshot() != nil:
+		return msg.GetSymbolQuoteSnapshot().AccountId
+	}
+	return ""
+}
+
+// handleExpiredAgentMessage descarta un AgentMessage que el Agent retuvo más allá
+// de agent/outbound_queue/max_age_ms mientras el Core no estaba disponible (i8).
+func (c *Core) handleExpiredAgentMessage(ctx context.Context, agentID string, msg *pb.AgentMessage) {
+	attrs := []attribute.KeyValue{
+		attribute.String("agent_id", agentID),
+		attribute.String("payload_type", fmt.Sprintf("%T", msg.Payload)),
+		attribute.Int64("queued_ms", msg.QueuedMs),
+	}
+	if intent := msg.GetTradeIntent(); intent != nil {
+		attrs = append(attrs,
+			attribute.String("trade_id", strings.ToLower(intent.TradeId)),
+			attribute.String("client_id", intent.ClientId),
+			attribute.String("symbol", intent.Symbol),
+		)
+		if intent.Timestamps != nil && intent.Timestamps.T1AgentRecvMs > 0 {
+			attrs = append(attrs, attribute.Int64("age_ms", utils.NowUnixMilli()-intent.Timestamps.T1AgentRecvMs))
+		}
 	}
+
+	c.telemetry.Warn(ctx, "Expired AgentMessage discarded (i8)", attrs...)
+	c.echoMetrics.RecordRoutingMode(ctx, "store_and_forward", "expired",
+		attribute.String("agent_id", agentID),
+	)
 }
 
 func (c *Core) sendSymbolRegistrationResult(ctx context.Context, agentID string, result *pb.SymbolRegistrationResult) error {
@@ -518,7 +1025,7 @@ func (c *Core) sendSymbolRegistrationResult(ctx context.Context, agentID string,
 	}
 
 	msg := &pb.CoreMessage{
-		TimestampMs: utils.NowUnixMilli(),
+		TimestampMs: c.nowMs(),
 		Payload: &pb.CoreMessage_SymbolRegistrationResult{
 			SymbolRegistrationResult: result,
 		},
@@ -534,6 +1041,28 @@ func (c *Core) sendSymbolRegistrationResult(ctx context.Context, agentID string,
 	}
 }
 
+// sendConfigUpdate encola un ConfigUpdate en el stream del Agent (i8).
+func (c *Core) sendConfigUpdate(agentID string, update *pb.ConfigUpdate) error {
+	conn, ok := c.GetAgent(agentID)
+	if !ok {
+		return fmt.Errorf("agent %s no conectado", agentID)
+	}
+
+	msg := &pb.CoreMessage{
+		TimestampMs: c.nowMs(),
+		Payload: &pb.CoreMessage_ConfigUpdate{
+			ConfigUpdate: update,
+		},
+	}
+
+	select {
+	case conn.SendCh <- msg:
+		return nil
+	default:
+		return fmt.Errorf("canal de envío lleno para agent %s", agentID)
+	}
+}
+
 // Ping implementa AgentServiceServer.
 func (c *Core) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
 	return &pb.PingResponse{
@@ -543,20 +1072,52 @@ func (c *Core) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse,
 }
 
 // sendToAgentLoop envía mensajes al Agent desde el canal.
+//
+// i8: asigna seq a cada CoreMessage y envía periódicamente el ack acumulativo.
 func (c *Core) sendToAgentLoop(conn *AgentConnection) {
+	ackTicker := time.NewTicker(c.streamAckInterval())
+	defer ackTicker.Stop()
+
+	// i8: con el buffer de replay lleno el mensaje se retiene y no se toman más de
+	// SendCh hasta que un ack del Agent libere lugar (se reintenta en cada tick)
+	var blocked *pb.CoreMessage
 	for {
+		sendCh := conn.SendCh
+		if blocked != nil {
+			sendCh = nil
+		}
+
+		var err error
 		select {
-		case msg, ok := <-conn.SendCh:
+		case <-ackTicker.C:
+			if ack := c.streamAck(conn); ack != nil {
+				if err := conn.Stream.Send(ack); err != nil {
+					c.telemetry.Error(c.ctx, "Failed to send ack to agent", err,
+						attribute.String("agent_id", conn.AgentID),
+						attribute.String("error", err.Error()),
+					)
+					return
+				}
+			}
+			if blocked != nil {
+				if blocked, err = c.sendSequencedToAgent(conn, blocked); err != nil {
+					return
+				}
+			}
+
+		case msg, ok := <-sendCh:
 			if !ok {
 				return // Canal cerrado
 			}
-
-			if err := conn.Stream.Send(msg); err != nil {
-				c.telemetry.Error(c.ctx, "Failed to send to agent", err,
+			if blocked, err = c.sendSequencedToAgent(conn, msg); err != nil {
+				return
+			}
+			if blocked != nil {
+				pending, _ := conn.session.reliable.Stats()
+				c.telemetry.Warn(c.ctx, "Replay buffer full, holding CoreMessages until agent ack (i8)",
 					attribute.String("agent_id", conn.AgentID),
-					attribute.String("error", err.Error()),
+					attribute.Int("pending", pending),
 				)
-				return
 			}
 
 		case <-conn.ctx.Done():
@@ -565,6 +1126,29 @@ func (c *Core) sendToAgentLoop(conn *AgentConnection) {
 	}
 }
 
+// sendSequencedToAgent asigna seq a msg y lo envía al Agent (i8).
+//
+// Retorna msg sin enviarlo si el buffer de replay está lleno, para reintentarlo
+// tras el próximo ack. Un mensaje con seq queda retenido para replay aunque falle
+// el envío; el error indica que el stream se cayó.
+func (c *Core) sendSequencedToAgent(conn *AgentConnection, msg *pb.CoreMessage) (*pb.CoreMessage, error) {
+	sequenced, err := c.sequenceCoreMessage(conn, msg)
+	if err != nil {
+		return msg, nil // grpcSDK.ErrReplayBufferFull
+	}
+	if err := conn.Stream.Send(sequenced); err != nil {
+		c.telemetry.Error(c.ctx, "Failed to send to agent", err,
+			attribute.String("agent_id", conn.AgentID),
+			attribute.String("error", err.Error()),
+		)
+		return nil, err
+	}
+	if c.journal != nil {
+		c.journal.RecordOutbound(conn.AgentID, sequenced)
+	}
+	return nil, nil
+}
+
 // registerAgent registra un nuevo agent conectado.
 func (c *Core) registerAgent(agentID string, conn *AgentConnection) {
 	c.agentsMu.Lock()
@@ -575,18 +1159,27 @@ func (c *Core) registerAgent(agentID string, conn *AgentConnection) {
 		attribute.String("agent_id", agentID),
 		attribute.Int("total_agents", len(c.agents)),
 	)
+	c.publishAgentConnection(conn, true, len(c.agents))
 }
 
 // unregisterAgent elimina un agent desconectado.
-func (c *Core) unregisterAgent(agentID string) {
+//
+// i8: No-op si conn ya fue reemplazada por un stream nuevo del mismo Agent.
+// Retorna true si se eliminó.
+func (c *Core) unregisterAgent(agentID string, conn *AgentConnection) bool {
 	c.agentsMu.Lock()
 	defer c.agentsMu.Unlock()
+	if current, ok := c.agents[agentID]; !ok || current != conn {
+		return false
+	}
 	delete(c.agents, agentID)
 
 	c.telemetry.Info(c.ctx, "Agent unregistered",
 		attribute.String("agent_id", agentID),
 		attribute.Int("total_agents", len(c.agents)),
 	)
+	c.publishAgentConnection(conn, false, len(c.agents))
+	return true
 }
 
 // GetAgents retorna lista de agents conectados (para routing).
@@ -601,6 +1194,18 @@ func (c *Core) GetAgents() []*AgentConnection {
 	return agents
 }
 
+// connectedAgentIDs retorna los agent_id con stream activo (i8).
+func (c *Core) connectedAgentIDs() []string {
+	c.agentsMu.RLock()
+	defer c.agentsMu.RUnlock()
+
+	agentIDs := make([]string, 0, len(c.agents))
+	for agentID := range c.agents {
+		agentIDs = append(agentIDs, agentID)
+	}
+	return agentIDs
+}
+
 // GetAgent retorna un Agent específico por ID (i2 helper).
 func (c *Core) GetAgent(agentID string) (*AgentConnection, bool) {
 	c.agentsMu.RLock()
@@ -665,6 +1270,22 @@ func (c *Core) Shutdown() error {
 		c.handshakeReconciler.Stop()
 	}
 
+	if c.outboxDispatcher != nil {
+		c.outboxDispatcher.Stop()
+	}
+
+	if c.livenessMonitor != nil {
+		c.livenessMonitor.Stop()
+	}
+
+	if c.ownershipManager != nil {
+		c.ownershipManager.Stop()
+	}
+
+	if c.agentConfigPublisher != nil {
+		c.agentConfigPublisher.Stop()
+	}
+
 	// i3: Detener symbol resolver
 	if c.symbolResolver != nil {
 		c.symbolResolver.Stop()
@@ -682,6 +1303,21 @@ func (c *Core) Shutdown() error {
 	}
 	c.agentsMu.Unlock()
 
+	// i8: API admin REST/JSON
+	if c.httpGateway != nil {
+		c.httpGateway.Stop()
+	}
+
+	// i8: Webhooks pendientes quedan como dead letter
+	if c.notifier != nil {
+		c.notifier.Stop()
+	}
+
+	// i8: cerrar streams WatchEvents (GracefulStop espera a los streams abiertos)
+	if c.events != nil {
+		c.events.Close()
+	}
+
 	// Detener servidor gRPC
 	if c.grpcServer != nil {
 		c.grpcServer.GracefulStop()
@@ -690,12 +1326,23 @@ func (c *Core) Shutdown() error {
 	// Esperar goroutines
 	c.wg.Wait()
 
-	// Cerrar conexión PostgreSQL (i1)
+	// Cerrar journal (flush + fsync del segmento actual)
+	if c.journal != nil {
+		if err := c.journal.Close(); err != nil {
+			c.telemetry.Error(c.ctx, "Failed to close message journal", err)
+		}
+	}
+
+	// Cerrar conexión a la base de datos (i1)
 	if c.db != nil {
 		if err := c.db.Close(); err != nil {
-			c.telemetry.Error(c.ctx, "Failed to close PostgreSQL connection", err)
+			c.telemetry.Error(c.ctx, "Failed to close database connection", err,
+				attribute.String("storage_backend", c.config.StorageBackend),
+			)
 		} else {
-			c.telemetry.Info(c.ctx, "PostgreSQL connection closed")
+			c.telemetry.Info(c.ctx, "Database connection closed",
+				attribute.String("storage_backend", c.config.StorageBackend),
+			)
 		}
 	}
 
@@ -726,6 +1373,16 @@ func (c *Core) EvaluateHandshakeForAccount(ctx context.Context, accountID string
 	return c.handshakeReconciler.EvaluateNow(ctx, accountID, send)
 }
 
+// agentIDFromContext resuelve el agent_id del stream (i8).
+//
+// Usa la identidad autenticada por los interceptors; sin ella, el agent-id declarado.
+func agentIDFromContext(ctx context.Context) (string, error) {
+	if identity, ok := grpcSDK.IdentityFromContext(ctx); ok {
+		return identity.AgentID, nil
+	}
+	return extractAgentIDFromMetadata(ctx)
+}
+
 // extractAgentIDFromMetadata extrae agent-id de los metadatos gRPC.
 //
 // Issue #C7: El Agent debe enviar su ID en metadata para evitar colisiones.
@@ -784,7 +1441,17 @@ func (c *Core) handleAccountConnected(agentID string, msg *pb.AccountConnected)
 	)
 
 	// Registrar cuenta en registry
-	c.accountRegistry.RegisterAccount(agentID, accountID, clientType)
+	// i8: si otro Agent vivo es owner, este queda en cuarentena (no se rutea)
+	result := c.accountRegistry.RegisterAccount(agentID, accountID, clientType)
+	reportOwnershipResult(c.ctx, c.telemetry, c.echoMetrics, result)
+	if result.Outcome == RegistrationQuarantined {
+		return
+	}
+
+	// i8: Entregar comandos del outbox que esperaban a esta cuenta
+	if c.outboxDispatcher != nil {
+		c.outboxDispatcher.Kick()
+	}
 
 	// Métrica: número de cuentas registradas
 	totalAccounts, totalAgents := c.accountRegistry.GetStats()
@@ -819,7 +1486,19 @@ func (c *Core) handleAccountDisconnected(agentID string, msg *pb.AccountDisconne
 	)
 
 	// Desregistrar cuenta del registry
-	c.accountRegistry.UnregisterAccount(accountID)
+	// i8: solo el owner la libera; un claimant en cuarentena retira su reclamo
+	released, promoted := c.accountRegistry.UnregisterAccount(agentID, accountID)
+	if !released {
+		return
+	}
+	if promoted != nil {
+		// Misma cuenta de broker: símbolos, specs y políticas en caché siguen vigentes
+		reportOwnershipResult(c.ctx, c.telemetry, c.echoMetrics, *promoted)
+		if c.outboxDispatcher != nil {
+			c.outboxDispatcher.Kick()
+		}
+		return
+	}
 	if c.handshakeRegistry != nil {
 		c.handshakeRegistry.Clear(accountID)
 	}
@@ -876,8 +1555,9 @@ func (c *Core) handleAccountSymbolsReport(ctx context.Context, agentID string, r
 	ctx, span := c.telemetry.StartSpan(ctx, "core.handle_account_symbols_report")
 	defer span.End()
 
-	// Convertir proto mappings a domain mappings
+	// Convertir proto mappings a domain mappings; sin canonical_symbol quedan para el matcher (i8)
 	mappings := make([]*domain.SymbolMapping, 0, len(report.Symbols))
+	var unmapped []*domain.SymbolMapping
 	for _, protoMapping := range report.Symbols {
 		if protoMapping == nil {
 			continue
@@ -892,13 +1572,60 @@ func (c *Core) handleAccountSymbolsReport(ctx context.Context, agentID string, r
 			MaxLot:          protoMapping.MaxLot,
 			LotStep:         protoMapping.LotStep,
 			StopLevel:       protoMapping.StopLevel,
+			BaseCurrency:    protoMapping.BaseCurrency,
+			ProfitCurrency:  protoMapping.ProfitCurrency,
+			Source:          domain.SymbolMappingSourceReport,
+			Confidence:      1,
 		}
 		if protoMapping.ContractSize != nil {
 			mapping.ContractSize = protoMapping.ContractSize
 		}
+		if mapping.CanonicalSymbol == "" {
+			unmapped = append(unmapped, mapping)
+			continue
+		}
 		mappings = append(mappings, mapping)
 	}
 
+	// Overrides fijados por operadores (echo-core-cli symbols pin) prevalecen sobre el EA (i8)
+	overrides, err := c.repoFactory.SymbolOverrideRepository().ListByAccount(ctx, accountID)
+	if err != nil {
+		c.telemetry.Warn(ctx, "Failed to load symbol overrides, using reported mappings (i8)",
+			attribute.String("account_id", accountID),
+			attribute.String("error", err.Error()),
+		)
+	}
+
+	// Matcher por reglas para los símbolos sin canónico (i8)
+	if c.symbolMatcher != nil {
+		match := c.symbolMatcher.Match(accountID, unmapped, mappings, overrides, func(canonical string) *domain.AccountSymbolInfo {
+			return c.symbolResolver.ReferenceMapping(canonical, accountID)
+		}, report.ReportedAtMs)
+		mappings = append(mappings, match.Accepted...)
+		if err := c.repoFactory.SymbolProposalRepository().ReplaceForAccount(ctx, accountID, match.Proposals); err != nil {
+			c.telemetry.Warn(ctx, "Failed to persist symbol match proposals (i8)",
+				attribute.String("account_id", accountID),
+				attribute.String("error", err.Error()),
+			)
+		}
+		if len(unmapped) > 0 {
+			c.telemetry.Info(ctx, "Unmapped symbols matched (i8)",
+				attribute.String("account_id", accountID),
+				attribute.Int("unmapped_count", len(unmapped)),
+				attribute.Int("accepted_count", len(match.Accepted)),
+				attribute.Int("proposals_count", len(match.Proposals)),
+			)
+		}
+	}
+
+	if len(overrides) > 0 {
+		mappings = overrideSymbolMappings(mappings, unmapped, overrides)
+		c.telemetry.Info(ctx, "Symbol overrides applied (i8)",
+			attribute.String("account_id", accountID),
+			attribute.Int("overrides_count", len(overrides)),
+		)
+	}
+
 	// Upsert mappings en resolver (actualiza caché y encola persistencia async)
 	if err := c.symbolResolver.UpsertMappings(ctx, accountID, mappings, report.ReportedAtMs); err != nil {
 		c.telemetry.RecordError(ctx, err)
@@ -1039,6 +1766,18 @@ func (c *Core) handleSymbolQuoteSnapshot(ctx context.Context, agentID string, sn
 	)
 }
 
+// newRiskEngineConfig traduce la configuración de Core al formato del motor FixedRisk.
+func newRiskEngineConfig(config *Config) riskengine.Config {
+	return riskengine.Config{
+		MaxQuoteAge:              config.Risk.Engine.QuoteMaxAge,
+		MinDistancePoints:        config.Risk.Engine.MinDistancePoints,
+		MaxRiskDrift:             config.Risk.Engine.MaxRiskDrift,
+		DefaultCurrency:          config.Risk.Engine.DefaultCurrency,
+		EnableCurrencyFallback:   config.Risk.Engine.EnableCurrencyFallback,
+		RejectOnMissingTickValue: config.Risk.Engine.RejectOnMissingTickValue,
+	}
+}
+
 type symbolInfoAdapter struct {
 	resolver *AccountSymbolResolver
 }
@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+)
+
+// eventHub distribuye CoreEvents a los suscriptores de WatchEvents (i8).
+//
+// Publish nunca bloquea: cada suscriptor tiene un buffer acotado y, si está lleno,
+// se descarta su evento más antiguo. Un dashboard lento pierde eventos, el Router no espera.
+type eventHub struct {
+	metrics        *metricbundle.EchoMetrics
+	bufferSize     int
+	maxSubscribers int
+
+	seq    atomic.Uint64
+	active atomic.Int32 // suscriptores abiertos (fast path de Active)
+
+	mu          sync.RWMutex
+	subscribers map[uint64]*eventSubscription
+	nextID      uint64
+	closed      bool
+
+	now func() time.Time
+}
+
+func newEventHub(config EventsConfig, metrics *metricbundle.EchoMetrics) *eventHub {
+	bufferSize := config.SubscriberBuffer
+	if bufferSize <= 0 {
+		bufferSize = 1024
+	}
+	return &eventHub{
+		metrics:        metrics,
+		bufferSize:     bufferSize,
+		maxSubscribers: config.MaxSubscribers,
+		subscribers:    make(map[uint64]*eventSubscription),
+		now:            time.Now,
+	}
+}
+
+// Active indica si hay suscriptores. Los publishers lo consultan antes de armar el evento.
+func (h *eventHub) Active() bool {
+	return h != nil && h.active.Load() > 0
+}
+
+// Subscribe abre una suscripción con los filtros de req. Close la libera.
+func (h *eventHub) Subscribe(req *pb.WatchEventsRequest) (*eventSubscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, fmt.Errorf("event hub closed")
+	}
+	if h.maxSubscribers > 0 && len(h.subscribers) >= h.maxSubscribers {
+		return nil, fmt.Errorf("max event subscribers reached (%d)", h.maxSubscribers)
+	}
+
+	h.nextID++
+	sub := &eventSubscription{
+		id:     h.nextID,
+		hub:    h,
+		filter: newEventFilter(req),
+		events: make(chan *pb.CoreEvent, h.bufferSize),
+		done:   make(chan struct{}),
+	}
+	h.subscribers[sub.id] = sub
+	h.active.Store(int32(len(h.subscribers)))
+	return sub, nil
+}
+
+// Publish asigna secuencia y timestamp y encola el evento en los suscriptores cuyo filtro coincide.
+//
+// El evento se comparte entre suscriptores: no debe modificarse tras publicarlo.
+func (h *eventHub) Publish(event *pb.CoreEvent) {
+	if !h.Active() || event == nil {
+		return
+	}
+
+	event.Seq = h.seq.Add(1)
+	if event.TimestampMs == 0 {
+		event.TimestampMs = h.now().UnixMilli()
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, sub := range h.subscribers {
+		if !sub.filter.matches(event) {
+			continue
+		}
+		if dropped := sub.push(event); dropped != nil && h.metrics != nil {
+			h.metrics.RecordEventsDropped(context.Background(), 1, eventTypeString(dropped.Type))
+		}
+	}
+}
+
+// Close cierra todas las suscripciones para que los streams WatchEvents terminen
+// (GracefulStop espera a los streams abiertos).
+func (h *eventHub) Close() {
+	h.mu.Lock()
+	h.closed = true
+	subscribers := make([]*eventSubscription, 0, len(h.subscribers))
+	for _, sub := range h.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	h.mu.Unlock()
+
+	for _, sub := range subscribers {
+		sub.Close()
+	}
+}
+
+func (h *eventHub) unsubscribe(id uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.subscribers, id)
+	h.active.Store(int32(len(h.subscribers)))
+}
+
+// eventSubscription buffer acotado de un suscriptor de WatchEvents.
+type eventSubscription struct {
+	id     uint64
+	hub    *eventHub
+	filter eventFilter
+
+	// pushMu serializa a los publishers para que descartar y encolar sea atómico
+	pushMu  sync.Mutex
+	events  chan *pb.CoreEvent
+	dropped eventDrops // descartados desde el último evento entregado (pushMu)
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// eventDrops resume los eventos descartados de una suscripción entre dos entregas.
+type eventDrops struct {
+	Count    uint64
+	FirstSeq uint64 // seq del primer descartado (0 si Count == 0)
+	LastSeq  uint64 // seq del último descartado
+}
+
+// record suma event a los descartes.
+func (d *eventDrops) record(event *pb.CoreEvent) {
+	if d.Count == 0 {
+		d.FirstSeq = event.Seq
+	}
+	d.Count++
+	d.LastSeq = event.Seq
+}
+
+// push encola event descartando el más antiguo si el buffer está lleno.
+//
+// Retorna el evento descartado (nil si no hubo descarte).
+func (s *eventSubscription) push(event *pb.CoreEvent) *pb.CoreEvent {
+	s.pushMu.Lock()
+	defer s.pushMu.Unlock()
+
+	select {
+	case s.events <- event:
+		return nil
+	default:
+	}
+
+	var dropped *pb.CoreEvent
+	select {
+	case dropped = <-s.events:
+		s.dropped.record(dropped)
+	default:
+		// El consumidor vació el buffer entre ambos selects
+	}
+	// Solo los publishers encolan y están serializados: hay lugar
+	s.events <- event
+	return dropped
+}
+
+// Next bloquea hasta el próximo evento. Retorna también los eventos descartados desde el anterior.
+func (s *eventSubscription) Next(ctx context.Context) (*pb.CoreEvent, eventDrops, error) {
+	select {
+	case event := <-s.events:
+		s.pushMu.Lock()
+		dropped := s.dropped
+		s.dropped = eventDrops{}
+		s.pushMu.Unlock()
+		return event, dropped, nil
+	case <-s.done:
+		return nil, eventDrops{}, fmt.Errorf("event subscription closed")
+	case <-ctx.Done():
+		return nil, eventDrops{}, ctx.Err()
+	}
+}
+
+// Close libera la suscripción. Es idempotente.
+func (s *eventSubscription) Close() {
+	s.closeOnce.Do(func() {
+		s.hub.unsubscribe(s.id)
+		close(s.done)
+	})
+}
+
+// eventFilter filtros de WatchEventsRequest (set vacío = sin restricción).
+type eventFilter struct {
+	accounts   map[string]struct{}
+	strategies map[string]struct{}
+	symbols    map[string]struct{}
+	types      map[pb.CoreEventType]struct{}
+}
+
+func newEventFilter(req *pb.WatchEventsRequest) eventFilter {
+	filter := eventFilter{
+		accounts:   stringSet(req.GetAccountIds()),
+		strategies: stringSet(req.GetStrategyIds()),
+		symbols:    stringSet(req.GetSymbols()),
+	}
+	if len(req.GetTypes()) > 0 {
+		filter.types = make(map[pb.CoreEventType]struct{}, len(req.GetTypes()))
+		for _, eventType := range req.GetTypes() {
+			filter.types[eventType] = struct{}{}
+		}
+	}
+	return filter
+}
+
+func (f eventFilter) matches(event *pb.CoreEvent) bool {
+	if f.types != nil {
+		if _, ok := f.types[event.Type]; !ok {
+			return false
+		}
+	}
+	return setContains(f.accounts, event.AccountId) &&
+		setContains(f.strategies, event.StrategyId) &&
+		setContains(f.symbols, event.Symbol)
+}
+
+func stringSet(values []string) map[string]struct{} {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		if value != "" {
+			set[value] = struct{}{}
+		}
+	}
+	return set
+}
+
+// setContains true si el filtro está vacío o contiene value.
+func setContains(set map[string]struct{}, value string) bool {
+	if set == nil {
+		return true
+	}
+	_, ok := set[value]
+	return ok
+}
+
+// eventTypeString nombre corto del tipo de evento para métricas y logs.
+func eventTypeString(eventType pb.CoreEventType) string {
+	switch eventType {
+	case pb.CoreEventType_CORE_EVENT_TYPE_INTENT_RECEIVED:
+		return "intent_received"
+	case pb.CoreEventType_CORE_EVENT_TYPE_ORDER_DISPATCHED:
+		return "order_dispatched"
+	case pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT:
+		return "execution_result"
+	case pb.CoreEventType_CORE_EVENT_TYPE_CLOSE_RECEIVED:
+		return "close_received"
+	case pb.CoreEventType_CORE_EVENT_TYPE_CLOSE_RESULT:
+		return "close_result"
+	case pb.CoreEventType_CORE_EVENT_TYPE_RISK_REJECTED:
+		return "risk_rejected"
+	case pb.CoreEventType_CORE_EVENT_TYPE_HANDSHAKE_CHANGED:
+		return "handshake_changed"
+	case pb.CoreEventType_CORE_EVENT_TYPE_AGENT_CONNECTED:
+		return "agent_connected"
+	case pb.CoreEventType_CORE_EVENT_TYPE_AGENT_DISCONNECTED:
+		return "agent_disconnected"
+	default:
+		return "unspecified"
+	}
+}
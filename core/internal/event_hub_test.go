@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+func TestEventHubFilters(t *testing.T) {
+	hub := newEventHub(EventsConfig{SubscriberBuffer: 8}, nil)
+	assert.False(t, hub.Active())
+
+	sub, err := hub.Subscribe(&pb.WatchEventsRequest{
+		AccountIds: []string{"s1"},
+		Types:      []pb.CoreEventType{pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT},
+	})
+	require.NoError(t, err)
+	assert.True(t, hub.Active())
+
+	hub.Publish(&pb.CoreEvent{Type: pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT, AccountId: "s2"})
+	hub.Publish(&pb.CoreEvent{Type: pb.CoreEventType_CORE_EVENT_TYPE_ORDER_DISPATCHED, AccountId: "s1"})
+	hub.Publish(&pb.CoreEvent{Type: pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT, AccountId: "s1", TradeId: "t1"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	event, dropped, err := sub.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "t1", event.TradeId)
+	assert.Equal(t, uint64(3), event.Seq, "seq is global across subscribers")
+	assert.NotZero(t, event.TimestampMs)
+	assert.Zero(t, dropped)
+
+	sub.Close()
+	sub.Close()
+	assert.False(t, hub.Active())
+}
+
+func TestEventHubDropsOldestWhenSubscriberIsSlow(t *testing.T) {
+	hub := newEventHub(EventsConfig{SubscriberBuffer: 2, MaxSubscribers: 1}, nil)
+
+	slow, err := hub.Subscribe(&pb.WatchEventsRequest{})
+	require.NoError(t, err)
+	_, err = hub.Subscribe(&pb.WatchEventsRequest{})
+	assert.Error(t, err, "max subscribers reached")
+
+	// Publish nunca bloquea aunque nadie consuma
+	for _, tradeID := range []string{"t1", "t2", "t3", "t4"} {
+		hub.Publish(&pb.CoreEvent{Type: pb.CoreEventType_CORE_EVENT_TYPE_INTENT_RECEIVED, TradeId: tradeID})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	event, dropped, err := slow.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "t3", event.TradeId)
+	assert.Equal(t, eventDrops{Count: 2, FirstSeq: 1, LastSeq: 2}, dropped)
+
+	event, dropped, err = slow.Next(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "t4", event.TradeId)
+	assert.Zero(t, dropped.Count)
+
+	// Close del hub termina los streams abiertos
+	hub.Close()
+	_, _, err = slow.Next(ctx)
+	assert.Error(t, err)
+	_, err = hub.Subscribe(&pb.WatchEventsRequest{})
+	assert.Error(t, err)
+}
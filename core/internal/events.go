@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"math"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/domain/handshake"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+// Publicación de CoreEvents para WatchEvents (i8).
+//
+// Cada helper retorna de inmediato sin suscriptores, así el hot path del Router
+// no arma eventos que nadie consume.
+
+func (r *Router) publishIntentReceived(agentID, strategyID string, intent *pb.TradeIntent) {
+	if !r.core.events.Active() {
+		return
+	}
+	r.core.events.Publish(&pb.CoreEvent{
+		Type:       pb.CoreEventType_CORE_EVENT_TYPE_INTENT_RECEIVED,
+		AccountId:  intent.ClientId,
+		StrategyId: strategyID,
+		Symbol:     intent.Symbol,
+		TradeId:    intent.TradeId,
+		AgentId:    agentID,
+		Payload: &pb.CoreEvent_IntentReceived{IntentReceived: &pb.IntentReceivedEvent{
+			Ticket:      intent.Ticket,
+			Side:        orderSideToString(intent.Side),
+			LotSize:     intent.LotSize,
+			Price:       intent.Price,
+			MagicNumber: intent.MagicNumber,
+		}},
+	})
+}
+
+// publishOrderDispatched mode: selective, outbox_pending, fallback_broadcast, outbox, outbox_redelivery.
+func (r *Router) publishOrderDispatched(agentID, mode string, sent bool, order *pb.ExecuteOrder) {
+	if !r.core.events.Active() {
+		return
+	}
+	event := &pb.CoreEvent{
+		Type:      pb.CoreEventType_CORE_EVENT_TYPE_ORDER_DISPATCHED,
+		AccountId: order.TargetAccountId,
+		Symbol:    order.Symbol,
+		TradeId:   order.TradeId,
+		AgentId:   agentID,
+		Payload: &pb.CoreEvent_OrderDispatched{OrderDispatched: &pb.OrderDispatchedEvent{
+			CommandId:    order.CommandId,
+			Mode:         mode,
+			Sent:         sent,
+			BrokerSymbol: order.Symbol,
+			LotSize:      order.LotSize,
+		}},
+	}
+	if cmdCtx := r.getCommandContext(order.CommandId); cmdCtx != nil {
+		event.StrategyId = cmdCtx.StrategyID
+		if cmdCtx.Symbol != "" {
+			event.Symbol = cmdCtx.Symbol
+		}
+	}
+	r.core.events.Publish(event)
+}
+
+func (r *Router) publishExecutionResult(agentID, tradeID, slaveAccountID string, cmdCtx *CommandContext, result *pb.ExecutionResult, errorCode string, timestamps map[string]int64) {
+	if !r.core.events.Active() {
+		return
+	}
+	payload := &pb.ExecutionResultEvent{
+		CommandId:     result.CommandId,
+		Success:       result.Success,
+		Ticket:        result.Ticket,
+		ErrorCode:     errorCode,
+		ExecutedPrice: result.ExecutedPrice,
+	}
+	// Mismo criterio que la métrica E2E: sin t0 o t7 las latencias no son confiables
+	if result.Timestamps != nil && result.Timestamps.T0MasterEaMs > 0 && result.Timestamps.T7OrderFilledMs > 0 {
+		if latency := domain.CalculateLatency(timestamps); latency != nil {
+			payload.Latency = &pb.LatencyBreakdown{
+				MasterToAgentMs: latency.MasterToAgentMs,
+				AgentToCoreMs:   latency.AgentToCoreMs,
+				CoreProcessMs:   latency.CoreProcessMs,
+				CoreToAgentMs:   latency.CoreToAgentMs,
+				AgentToSlaveMs:  latency.AgentToSlaveMs,
+				SlaveProcessMs:  latency.SlaveProcessMs,
+				OrderFillMs:     latency.OrderFillMs,
+				E2EMs:           latency.E2EMs,
+			}
+		}
+	}
+
+	event := &pb.CoreEvent{
+		Type:      pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT,
+		AccountId: slaveAccountID,
+		TradeId:   tradeID,
+		AgentId:   agentID,
+		Payload:   &pb.CoreEvent_ExecutionResult{ExecutionResult: payload},
+	}
+	if cmdCtx != nil {
+		event.StrategyId = cmdCtx.StrategyID
+		event.Symbol = cmdCtx.Symbol
+		if slippage, ok := slippagePoints(cmdCtx, result); ok {
+			payload.SlippagePoints = &slippage
+		}
+	}
+	r.core.events.Publish(event)
+}
+
+// slippagePoints diferencia absoluta entre el fill del slave y el precio del master, en points.
+func slippagePoints(cmdCtx *CommandContext, result *pb.ExecutionResult) (float64, bool) {
+	if !result.Success || result.ExecutedPrice == nil || cmdCtx.ReferencePrice <= 0 || cmdCtx.Point <= 0 {
+		return 0, false
+	}
+	return math.Abs(result.GetExecutedPrice()-cmdCtx.ReferencePrice) / cmdCtx.Point, true
+}
+
+func (r *Router) publishCloseReceived(agentID string, close *pb.TradeClose) {
+	if !r.core.events.Active() {
+		return
+	}
+	r.core.events.Publish(&pb.CoreEvent{
+		Type:      pb.CoreEventType_CORE_EVENT_TYPE_CLOSE_RECEIVED,
+		AccountId: close.ClientId,
+		Symbol:    close.Symbol,
+		TradeId:   close.TradeId,
+		AgentId:   agentID,
+		Payload: &pb.CoreEvent_CloseReceived{CloseReceived: &pb.CloseReceivedEvent{
+			Ticket:      close.Ticket,
+			ClosePrice:  close.ClosePrice,
+			MagicNumber: close.MagicNumber,
+		}},
+	})
+}
+
+func (r *Router) publishCloseResult(agentID string, cmdCtx *CommandContext, result *pb.ExecutionResult, errorCode string) {
+	if !r.core.events.Active() {
+		return
+	}
+	r.core.events.Publish(&pb.CoreEvent{
+		Type:       pb.CoreEventType_CORE_EVENT_TYPE_CLOSE_RESULT,
+		AccountId:  cmdCtx.SlaveAccountID,
+		StrategyId: cmdCtx.StrategyID,
+		Symbol:     cmdCtx.Symbol,
+		TradeId:    cmdCtx.TradeID,
+		AgentId:    agentID,
+		Payload: &pb.CoreEvent_CloseResult{CloseResult: &pb.CloseResultEvent{
+			CommandId:       result.CommandId,
+			Success:         result.Success,
+			Ticket:          result.Ticket,
+			ErrorCode:       errorCode,
+			ClosePrice:      result.ExecutedPrice,
+			Profit:          result.Profit,
+			ClosedVolume:    result.ClosedVolume,
+			RemainingVolume: result.RemainingVolume,
+		}},
+	})
+}
+
+func (r *Router) publishRiskRejected(intent *pb.TradeIntent, slaveAccountID, strategyID, reason string, policyType domain.RiskPolicyType) {
+	if !r.core.events.Active() {
+		return
+	}
+	r.core.events.Publish(&pb.CoreEvent{
+		Type:       pb.CoreEventType_CORE_EVENT_TYPE_RISK_REJECTED,
+		AccountId:  slaveAccountID,
+		StrategyId: strategyID,
+		Symbol:     intent.Symbol,
+		TradeId:    intent.TradeId,
+		Payload: &pb.CoreEvent_RiskRejected{RiskRejected: &pb.RiskRejectedEvent{
+			Reason:     reason,
+			PolicyType: string(policyType),
+		}},
+	})
+}
+
+// publishHandshakeChanged se registra en HandshakeRegistry.OnStatusChange.
+func (c *Core) publishHandshakeChanged(previous handshake.RegistrationStatus, current *handshake.Evaluation) {
+	if !c.events.Active() {
+		return
+	}
+	c.events.Publish(&pb.CoreEvent{
+		Type:      pb.CoreEventType_CORE_EVENT_TYPE_HANDSHAKE_CHANGED,
+		AccountId: current.AccountID,
+		AgentId:   current.AgentID,
+		Payload: &pb.CoreEvent_HandshakeChanged{HandshakeChanged: &pb.HandshakeChangedEvent{
+			PreviousStatus: previous.ToProto(),
+			Status:         current.Status.ToProto(),
+			PipeRole:       current.PipeRole,
+		}},
+	})
+}
+
+func (c *Core) publishAgentConnection(conn *AgentConnection, connected bool, totalAgents int) {
+	if !c.events.Active() {
+		return
+	}
+	eventType := pb.CoreEventType_CORE_EVENT_TYPE_AGENT_DISCONNECTED
+	if connected {
+		eventType = pb.CoreEventType_CORE_EVENT_TYPE_AGENT_CONNECTED
+	}
+	c.events.Publish(&pb.CoreEvent{
+		Type:    eventType,
+		AgentId: conn.AgentID,
+		Payload: &pb.CoreEvent_AgentConnection{AgentConnection: &pb.AgentConnectionEvent{
+			ReliableSession: conn.session != nil,
+			TotalAgents:     int32(totalAgents),
+		}},
+	})
+}
@@ -165,7 +165,9 @@ func (r *handshakeReconciler) process(ctx context.Context, accountID string, sen
 		return nil, fmt.Errorf("obtener mappings: %w", err)
 	}
 
-	agentID, _ := r.accountRegistry.GetOwner(accountID)
+	// i8: GetRecord incluye cuentas stale (GetOwner solo las ruteables)
+	ownership, _ := r.accountRegistry.GetRecord(accountID)
+	agentID := ownership.AgentID
 	pipeRole := evalSnapshot.PipeRole
 
 	newEvaluation, persisted, err := r.evaluator.EvaluateWithPrevious(ctx, accountID, agentID, pipeRole, r.coreVersion, metadata, mappings, evalSnapshot)
@@ -10,6 +10,9 @@ import (
 type HandshakeRegistry struct {
 	mu          sync.RWMutex
 	evaluations map[string]*handshake.Evaluation
+
+	// i8: notificado fuera del lock cuando cambia el status de una cuenta (WatchEvents)
+	onStatusChange func(previous handshake.RegistrationStatus, current *handshake.Evaluation)
 }
 
 // NewHandshakeRegistry crea un registro vacío.
@@ -25,7 +28,23 @@ func (r *HandshakeRegistry) Set(evaluation *handshake.Evaluation) {
 		return
 	}
 	r.mu.Lock()
+	previous := handshake.RegistrationStatusUnspecified
+	if prev, ok := r.evaluations[evaluation.AccountID]; ok && prev != nil {
+		previous = prev.Status
+	}
 	r.evaluations[evaluation.AccountID] = evaluation
+	onStatusChange := r.onStatusChange
+	r.mu.Unlock()
+
+	if onStatusChange != nil && previous != evaluation.Status {
+		onStatusChange(previous, evaluation)
+	}
+}
+
+// OnStatusChange registra fn para cambios de status (i8). Reemplaza al anterior.
+func (r *HandshakeRegistry) OnStatusChange(fn func(previous handshake.RegistrationStatus, current *handshake.Evaluation)) {
+	r.mu.Lock()
+	r.onStatusChange = fn
 	r.mu.Unlock()
 }
 
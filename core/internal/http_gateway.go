@@ -0,0 +1,646 @@
+package internal
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	httpGatewayPrefix          = "/api/v1"
+	httpGatewayShutdownTimeout = 5 * time.Second
+	httpGatewayMaxBodyBytes    = 64 << 10
+)
+
+// httpGateway expone la API admin como REST/JSON (i8).
+//
+// Las consultas reutilizan adminService y los repositorios de domain; no hay SQL propio.
+// Autenticación por API key (header X-API-Key o Authorization: Bearer <key>) de
+// core/http/api_keys. Cada request queda auditado igual que AdminService.
+type httpGateway struct {
+	core   *Core
+	admin  *adminService
+	config HTTPGatewayConfig
+
+	server *http.Server
+	wg     sync.WaitGroup
+}
+
+func newHTTPGateway(core *Core, config HTTPGatewayConfig) *httpGateway {
+	return &httpGateway{
+		core:   core,
+		admin:  newAdminService(core),
+		config: config,
+	}
+}
+
+// Start abre el listener y sirve en background. Usa TLS si core/security/tls_cert_file está configurado.
+func (g *httpGateway) Start() error {
+	lis, err := net.Listen("tcp", g.config.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", g.config.Addr, err)
+	}
+
+	security := g.core.config.Security
+	tls := security.TLSCertFile != ""
+	if tls {
+		tlsConfig, err := (&grpcSDK.TLSConfig{
+			CertFile: security.TLSCertFile,
+			KeyFile:  security.TLSKeyFile,
+		}).ServerTLS()
+		if err != nil {
+			lis.Close()
+			return fmt.Errorf("failed to configure HTTP gateway TLS: %w", err)
+		}
+		g.server = &http.Server{Handler: g.handler(), TLSConfig: tlsConfig, ReadHeaderTimeout: 10 * time.Second}
+	} else {
+		g.server = &http.Server{Handler: g.handler(), ReadHeaderTimeout: 10 * time.Second}
+	}
+
+	g.core.telemetry.Info(g.core.ctx, "HTTP gateway listening (i8)",
+		attribute.String("address", g.config.Addr),
+		attribute.Bool("tls", tls),
+		attribute.Int("api_keys", len(g.config.APIKeys)),
+	)
+
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		var err error
+		if tls {
+			err = g.server.ServeTLS(lis, "", "")
+		} else {
+			err = g.server.Serve(lis)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			g.core.telemetry.Error(g.core.ctx, "HTTP gateway failed", err)
+		}
+	}()
+	return nil
+}
+
+// Stop cierra el servidor esperando hasta httpGatewayShutdownTimeout a los requests en curso.
+func (g *httpGateway) Stop() {
+	if g.server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), httpGatewayShutdownTimeout)
+	defer cancel()
+	if err := g.server.Shutdown(ctx); err != nil {
+		g.server.Close()
+	}
+	g.wg.Wait()
+}
+
+func (g *httpGateway) handler() http.Handler {
+	mux := http.NewServeMux()
+	routes := []struct {
+		pattern string
+		handle  func(*http.Request) (interface{}, error)
+	}{
+		{"GET /agents", g.listAgents},
+		{"GET /agents/liveness", g.listAgentLiveness},
+		{"GET /accounts", g.listAccounts},
+		{"GET /accounts/{account_id}", g.getAccount},
+		{"PUT /accounts/{account_id}/copy", g.setCopyEnabled},
+		{"GET /accounts/{account_id}/symbols", g.listSymbolMappings},
+		{"GET /trades", g.listTrades},
+		{"GET /trades/{trade_id}", g.getTrade},
+		{"POST /trades/{trade_id}/close", g.closeTrade},
+		{"GET /executions", g.listExecutions},
+		{"GET /policies/{account_id}/{strategy_id}", g.getPolicy},
+		{"GET /kill-switch", g.getKillSwitch},
+		{"PUT /kill-switch", g.setKillSwitch},
+	}
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route.pattern, " ")
+		pattern := method + " " + httpGatewayPrefix + path
+		mux.Handle(pattern, g.authenticate(route.pattern, route.handle))
+	}
+	return mux
+}
+
+type httpIdentityKey struct{}
+
+// httpIdentity retorna la identidad de la API key del request ("" sin autenticar).
+func httpIdentity(ctx context.Context) string {
+	identity, _ := ctx.Value(httpIdentityKey{}).(string)
+	return identity
+}
+
+// authenticate valida la API key, ejecuta handle y audita el resultado.
+func (g *httpGateway) authenticate(route string, handle func(*http.Request) (interface{}, error)) http.Handler {
+	method := "http " + route
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, ok := matchAPIKey(g.config.APIKeys, requestAPIKey(r))
+		if !ok {
+			g.core.telemetry.Warn(g.core.ctx, "HTTP gateway request denied (i8)",
+				attribute.String("method", method),
+				attribute.String("remote_addr", r.RemoteAddr),
+			)
+			g.core.echoMetrics.RecordAdminRequest(g.core.ctx, method, "denied")
+			writeHTTPError(w, http.StatusUnauthorized, "missing or invalid API key")
+			return
+		}
+
+		r = r.WithContext(context.WithValue(r.Context(), httpIdentityKey{}, identity))
+		result, err := handle(r)
+		g.admin.audit(method, identity, err)
+		if err != nil {
+			writeHTTPError(w, httpStatusFromError(err), status.Convert(err).Message())
+			return
+		}
+		writeHTTPJSON(w, http.StatusOK, result)
+	})
+}
+
+// requestAPIKey extrae la key de X-API-Key o de Authorization: Bearer.
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// matchAPIKey compara en tiempo constante contra todas las keys.
+//
+// La identidad es "http:key<n>" (índice 1-based en core/http/api_keys) para no loguear la key.
+func matchAPIKey(keys []string, candidate string) (string, bool) {
+	if candidate == "" {
+		return "", false
+	}
+	identity := ""
+	for i, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(candidate)) == 1 && identity == "" {
+			identity = fmt.Sprintf("http:key%d", i+1)
+		}
+	}
+	return identity, identity != ""
+}
+
+// httpStatusFromError traduce los status gRPC de adminService a HTTP.
+func httpStatusFromError(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.FailedPrecondition:
+		return http.StatusConflict
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeHTTPError(w http.ResponseWriter, code int, message string) {
+	writeHTTPJSON(w, code, map[string]string{"error": message})
+}
+
+// writeHTTPJSON serializa mensajes proto con protojson (nombres snake_case) y el resto con encoding/json.
+func writeHTTPJSON(w http.ResponseWriter, code int, value interface{}) {
+	var (
+		body []byte
+		err  error
+	)
+	if message, ok := value.(proto.Message); ok {
+		body, err = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(message)
+	} else {
+		body, err = json.Marshal(value)
+	}
+	if err != nil {
+		code = http.StatusInternalServerError
+		body = []byte(`{"error":"failed to encode response"}`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(body)
+	w.Write([]byte("\n"))
+}
+
+func decodeHTTPBody(r *http.Request, dst interface{}) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, httpGatewayMaxBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid JSON body: %v", err)
+	}
+	return nil
+}
+
+// httpPage envoltorio de los listados paginados. NextOffset se omite en la última página.
+type httpPage struct {
+	Items      interface{} `json:"items"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+	NextOffset *int        `json:"next_offset,omitempty"`
+}
+
+func newHTTPPage(items interface{}, count, limit, offset int) httpPage {
+	page := httpPage{Items: items, Limit: limit, Offset: offset}
+	if count >= limit {
+		next := offset + count
+		page.NextOffset = &next
+	}
+	return page
+}
+
+// parsePagination lee limit (default 50, máximo 500) y offset.
+func parsePagination(r *http.Request) (int, int, error) {
+	limit := defaultAdminTradesLimit
+	offset := 0
+	query := r.URL.Query()
+	if val := query.Get("limit"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed <= 0 {
+			return 0, 0, status.Errorf(codes.InvalidArgument, "invalid limit %q", val)
+		}
+		limit = parsed
+	}
+	if limit > maxAdminTradesLimit {
+		limit = maxAdminTradesLimit
+	}
+	if val := query.Get("offset"); val != "" {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed < 0 {
+			return 0, 0, status.Errorf(codes.InvalidArgument, "invalid offset %q", val)
+		}
+		offset = parsed
+	}
+	return limit, offset, nil
+}
+
+// parseTimeRange lee from/to como RFC3339 o epoch ms. Retorna 0 si no se informaron.
+func parseTimeRange(r *http.Request) (int64, int64, error) {
+	query := r.URL.Query()
+	fromMs, err := parseTimeParam("from", query.Get("from"))
+	if err != nil {
+		return 0, 0, err
+	}
+	toMs, err := parseTimeParam("to", query.Get("to"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if fromMs > 0 && toMs > 0 && fromMs >= toMs {
+		return 0, 0, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+	return fromMs, toMs, nil
+}
+
+func parseTimeParam(name, val string) (int64, error) {
+	if val == "" {
+		return 0, nil
+	}
+	if ms, err := strconv.ParseInt(val, 10, 64); err == nil && ms > 0 {
+		return ms, nil
+	}
+	t, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid %s %q (RFC3339 or epoch ms)", name, val)
+	}
+	return t.UnixMilli(), nil
+}
+
+func (g *httpGateway) listAgents(r *http.Request) (interface{}, error) {
+	return g.admin.ListAgents(r.Context(), &pb.ListAgentsRequest{})
+}
+
+func (g *httpGateway) listAgentLiveness(r *http.Request) (interface{}, error) {
+	agents, err := g.core.repoFactory.AgentLivenessRepository().List(r.Context())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list agent liveness: %v", err)
+	}
+	return map[string]interface{}{"agents": agents}, nil
+}
+
+// httpAccount ownership persistido de la cuenta con su estado de copia.
+type httpAccount struct {
+	*domain.AccountOwnership
+	Copy domain.AccountCopyControl `json:"copy"`
+}
+
+func (g *httpGateway) listAccounts(r *http.Request) (interface{}, error) {
+	ownerships, err := g.core.repoFactory.AccountOwnershipRepository().List(r.Context())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list accounts: %v", err)
+	}
+	accounts := make([]httpAccount, 0, len(ownerships))
+	for _, ownership := range ownerships {
+		accounts = append(accounts, httpAccount{
+			AccountOwnership: ownership,
+			Copy:             g.core.copyControl.Get(ownership.AccountID),
+		})
+	}
+	return map[string]interface{}{"accounts": accounts}, nil
+}
+
+func (g *httpGateway) getAccount(r *http.Request) (interface{}, error) {
+	return g.admin.GetAccountState(r.Context(), &pb.GetAccountStateRequest{AccountId: r.PathValue("account_id")})
+}
+
+func (g *httpGateway) listSymbolMappings(r *http.Request) (interface{}, error) {
+	return g.admin.ListSymbolMappings(r.Context(), &pb.ListSymbolMappingsRequest{AccountId: r.PathValue("account_id")})
+}
+
+type httpSetCopyRequest struct {
+	Enabled *bool  `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+func (g *httpGateway) setCopyEnabled(r *http.Request) (interface{}, error) {
+	accountID := r.PathValue("account_id")
+	if accountID == killSwitchAccountID {
+		return nil, status.Error(codes.InvalidArgument, "use /kill-switch to stop all copying")
+	}
+	var body httpSetCopyRequest
+	if err := decodeHTTPBody(r, &body); err != nil {
+		return nil, err
+	}
+	if body.Enabled == nil {
+		return nil, status.Error(codes.InvalidArgument, "enabled is required")
+	}
+	return g.setCopy(r.Context(), accountID, *body.Enabled, body.Reason)
+}
+
+// setCopy equivalente a AdminService.SetCopyEnabled con la identidad HTTP como autor.
+func (g *httpGateway) setCopy(ctx context.Context, accountID string, enabled bool, reason string) (domain.AccountCopyControl, error) {
+	control, err := g.core.copyControl.Set(ctx, accountID, enabled, reason, httpIdentity(ctx))
+	if err != nil {
+		return control, status.Errorf(codes.Internal, "failed to set copy enabled: %v", err)
+	}
+	g.core.telemetry.Warn(g.core.ctx, "Account copy toggled by operator (i8)",
+		attribute.String("account_id", control.AccountID),
+		attribute.Bool("copy_enabled", control.CopyEnabled),
+		attribute.String("reason", control.Reason),
+		attribute.String("updated_by", control.UpdatedBy),
+	)
+	return control, nil
+}
+
+func (g *httpGateway) listTrades(r *http.Request) (interface{}, error) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return nil, err
+	}
+	fromMs, toMs, err := parseTimeRange(r)
+	if err != nil {
+		return nil, err
+	}
+	query := r.URL.Query()
+	filter := domain.TradeFilter{
+		MasterAccountID: query.Get("master_account_id"),
+		Symbol:          strings.ToUpper(query.Get("symbol")),
+		CreatedFromMs:   fromMs,
+		CreatedToMs:     toMs,
+	}
+	if val := query.Get("status"); val != "" {
+		filter.Status = domain.OrderStatus(strings.ToUpper(val))
+		switch filter.Status {
+		case domain.OrderStatusPending, domain.OrderStatusSent, domain.OrderStatusFilled,
+			domain.OrderStatusRejected, domain.OrderStatusCancelled:
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "unknown trade status %q", val)
+		}
+	}
+
+	trades, err := g.core.repoFactory.TradeRepository().ListFiltered(r.Context(), filter, limit, offset)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list trades: %v", err)
+	}
+	return newHTTPPage(trades, len(trades), limit, offset), nil
+}
+
+// httpTradeDetail trade con sus ejecuciones y cierres.
+type httpTradeDetail struct {
+	Trade      *domain.Trade       `json:"trade"`
+	Executions []*domain.Execution `json:"executions"`
+	Closes     []*domain.Close     `json:"closes"`
+}
+
+func (g *httpGateway) getTrade(r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	trade, err := g.admin.loadTrade(ctx, r.PathValue("trade_id"))
+	if err != nil {
+		return nil, err
+	}
+	executions, err := g.core.repoFactory.ExecutionRepository().GetByTradeID(ctx, trade.TradeID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load executions of trade %s: %v", trade.TradeID, err)
+	}
+	closes, err := g.core.repoFactory.CloseRepository().GetByTradeID(ctx, trade.TradeID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load closes of trade %s: %v", trade.TradeID, err)
+	}
+	return httpTradeDetail{Trade: trade, Executions: executions, Closes: closes}, nil
+}
+
+type httpCloseTradeRequest struct {
+	SlaveAccountIDs []string `json:"slave_account_ids"`
+}
+
+func (g *httpGateway) closeTrade(r *http.Request) (interface{}, error) {
+	var body httpCloseTradeRequest
+	if r.ContentLength != 0 {
+		if err := decodeHTTPBody(r, &body); err != nil {
+			return nil, err
+		}
+	}
+	return g.admin.CloseTrade(r.Context(), &pb.CloseTradeRequest{
+		TradeId:         r.PathValue("trade_id"),
+		SlaveAccountIds: body.SlaveAccountIDs,
+	})
+}
+
+func (g *httpGateway) listExecutions(r *http.Request) (interface{}, error) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		return nil, err
+	}
+	fromMs, toMs, err := parseTimeRange(r)
+	if err != nil {
+		return nil, err
+	}
+	query := r.URL.Query()
+	filter := domain.ExecutionFilter{
+		TradeID:        strings.ToLower(query.Get("trade_id")),
+		SlaveAccountID: query.Get("slave_account_id"),
+		CreatedFromMs:  fromMs,
+		CreatedToMs:    toMs,
+	}
+	if val := query.Get("success"); val != "" {
+		success, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid success %q", val)
+		}
+		filter.Success = &success
+	}
+
+	executions, err := g.core.repoFactory.ExecutionRepository().ListFiltered(r.Context(), filter, limit, offset)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list executions: %v", err)
+	}
+	return newHTTPPage(executions, len(executions), limit, offset), nil
+}
+
+// httpRiskPolicy vista JSON de domain.RiskPolicy.
+type httpRiskPolicy struct {
+	AccountID       string               `json:"account_id"`
+	StrategyID      string               `json:"strategy_id"`
+	Type            string               `json:"type"`
+	FixedLot        *httpFixedLotConfig  `json:"fixed_lot,omitempty"`
+	FixedRisk       *httpFixedRiskConfig `json:"fixed_risk,omitempty"`
+	Version         int64                `json:"version"`
+	UpdatedAtMs     int64                `json:"updated_at_ms"`
+	EffectiveFromMs int64                `json:"effective_from_ms"`
+	ValidUntilMs    int64                `json:"valid_until_ms,omitempty"`
+}
+
+type httpFixedLotConfig struct {
+	LotSize float64 `json:"lot_size"`
+}
+
+type httpFixedRiskConfig struct {
+	Amount           float64  `json:"amount"`
+	Currency         string   `json:"currency"`
+	MinLotOverride   *float64 `json:"min_lot_override,omitempty"`
+	MaxLotOverride   *float64 `json:"max_lot_override,omitempty"`
+	CommissionPerLot *float64 `json:"commission_per_lot,omitempty"`
+	CommissionRate   *float64 `json:"commission_rate,omitempty"`
+}
+
+// httpRiskPolicyRevision vista JSON de domain.RiskPolicyRevision.
+type httpRiskPolicyRevision struct {
+	Policy       httpRiskPolicy `json:"policy"`
+	Author       string         `json:"author"`
+	Reason       string         `json:"reason"`
+	RecordedAtMs int64          `json:"recorded_at_ms"`
+}
+
+func riskPolicyToHTTP(policy *domain.RiskPolicy) httpRiskPolicy {
+	view := httpRiskPolicy{
+		AccountID:       policy.AccountID,
+		StrategyID:      policy.StrategyID,
+		Type:            string(policy.Type),
+		Version:         policy.Version,
+		UpdatedAtMs:     unixMilliOrZero(policy.UpdatedAt),
+		EffectiveFromMs: unixMilliOrZero(policy.EffectiveFrom),
+	}
+	if policy.FixedLot != nil {
+		view.FixedLot = &httpFixedLotConfig{LotSize: policy.FixedLot.LotSize}
+	}
+	if risk := policy.FixedRisk; risk != nil {
+		view.FixedRisk = &httpFixedRiskConfig{
+			Amount:           risk.Amount,
+			Currency:         risk.Currency,
+			MinLotOverride:   risk.MinLotOverride,
+			MaxLotOverride:   risk.MaxLotOverride,
+			CommissionPerLot: risk.CommissionPerLot,
+			CommissionRate:   risk.CommissionRate,
+		}
+	}
+	if policy.ValidUntil != nil {
+		view.ValidUntilMs = unixMilliOrZero(*policy.ValidUntil)
+	}
+	return view
+}
+
+func (g *httpGateway) getPolicy(r *http.Request) (interface{}, error) {
+	ctx := r.Context()
+	accountID := r.PathValue("account_id")
+	strategyID := r.PathValue("strategy_id")
+	repo := g.core.repoFactory.RiskPolicyRepository()
+
+	current, err := repo.Get(ctx, accountID, strategyID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load risk policy: %v", err)
+	}
+	history, err := repo.ListHistory(ctx, accountID, strategyID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to load risk policy history: %v", err)
+	}
+	if current == nil && len(history) == 0 {
+		return nil, status.Errorf(codes.NotFound, "no risk policy for account %s strategy %s", accountID, strategyID)
+	}
+
+	resp := struct {
+		Current *httpRiskPolicy          `json:"current"`
+		History []httpRiskPolicyRevision `json:"history"`
+	}{History: make([]httpRiskPolicyRevision, 0, len(history))}
+	if current != nil {
+		view := riskPolicyToHTTP(current)
+		resp.Current = &view
+	}
+	for _, revision := range history {
+		resp.History = append(resp.History, httpRiskPolicyRevision{
+			Policy:       riskPolicyToHTTP(&revision.Policy),
+			Author:       revision.Author,
+			Reason:       revision.Reason,
+			RecordedAtMs: unixMilliOrZero(revision.RecordedAt),
+		})
+	}
+	return resp, nil
+}
+
+// httpKillSwitch estado del kill switch global (fila "*" de account_copy_control).
+type httpKillSwitch struct {
+	Engaged     bool   `json:"engaged"`
+	Reason      string `json:"reason"`
+	UpdatedBy   string `json:"updated_by"`
+	UpdatedAtMs int64  `json:"updated_at_ms"`
+}
+
+func killSwitchToHTTP(control domain.AccountCopyControl) httpKillSwitch {
+	return httpKillSwitch{
+		Engaged:     !control.CopyEnabled,
+		Reason:      control.Reason,
+		UpdatedBy:   control.UpdatedBy,
+		UpdatedAtMs: control.UpdatedAtMs,
+	}
+}
+
+func (g *httpGateway) getKillSwitch(r *http.Request) (interface{}, error) {
+	return killSwitchToHTTP(g.core.copyControl.KillSwitch()), nil
+}
+
+type httpSetKillSwitchRequest struct {
+	Engaged *bool  `json:"engaged"`
+	Reason  string `json:"reason"`
+}
+
+func (g *httpGateway) setKillSwitch(r *http.Request) (interface{}, error) {
+	var body httpSetKillSwitchRequest
+	if err := decodeHTTPBody(r, &body); err != nil {
+		return nil, err
+	}
+	if body.Engaged == nil {
+		return nil, status.Error(codes.InvalidArgument, "engaged is required")
+	}
+	control, err := g.setCopy(r.Context(), killSwitchAccountID, !*body.Engaged, body.Reason)
+	if err != nil {
+		return nil, err
+	}
+	return killSwitchToHTTP(control), nil
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPGatewayAPIKey(t *testing.T) {
+	keys := []string{"alpha", "beta"}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/agents", nil)
+	req.Header.Set("Authorization", "Bearer beta")
+	identity, ok := matchAPIKey(keys, requestAPIKey(req))
+	require.True(t, ok)
+	assert.Equal(t, "http:key2", identity)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/agents", nil)
+	req.Header.Set("X-API-Key", "alpha")
+	identity, ok = matchAPIKey(keys, requestAPIKey(req))
+	require.True(t, ok)
+	assert.Equal(t, "http:key1", identity)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/agents", nil)
+	req.Header.Set("Authorization", "Basic alpha")
+	_, ok = matchAPIKey(keys, requestAPIKey(req))
+	assert.False(t, ok, "only bearer scheme is accepted")
+
+	_, ok = matchAPIKey(keys, "gamma")
+	assert.False(t, ok)
+	_, ok = matchAPIKey(nil, "")
+	assert.False(t, ok)
+}
+
+func TestHTTPGatewayPaginationAndTimeRange(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/trades?limit=1000&offset=20&from=2025-01-01T00:00:00Z&to=1735693200000", nil)
+	limit, offset, err := parsePagination(req)
+	require.NoError(t, err)
+	assert.Equal(t, maxAdminTradesLimit, limit)
+	assert.Equal(t, 20, offset)
+
+	fromMs, toMs, err := parseTimeRange(req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1735689600000), fromMs)
+	assert.Equal(t, int64(1735693200000), toMs)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/trades", nil)
+	limit, offset, err = parsePagination(req)
+	require.NoError(t, err)
+	assert.Equal(t, defaultAdminTradesLimit, limit)
+	assert.Zero(t, offset)
+
+	for _, query := range []string{"limit=0", "limit=abc", "offset=-1"} {
+		_, _, err = parsePagination(httptest.NewRequest(http.MethodGet, "/api/v1/trades?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, httpStatusFromError(err), query)
+	}
+	for _, query := range []string{"from=yesterday", "from=1735693200000&to=1735689600000"} {
+		_, _, err = parseTimeRange(httptest.NewRequest(http.MethodGet, "/api/v1/trades?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, httpStatusFromError(err), query)
+	}
+}
+
+func TestHTTPGatewayPageAndErrors(t *testing.T) {
+	page := newHTTPPage([]string{"a", "b"}, 2, 2, 4)
+	require.NotNil(t, page.NextOffset)
+	assert.Equal(t, 6, *page.NextOffset)
+	assert.Nil(t, newHTTPPage([]string{"a"}, 1, 2, 4).NextOffset, "last page has no next_offset")
+
+	assert.Equal(t, http.StatusNotFound, httpStatusFromError(status.Error(codes.NotFound, "missing")))
+	assert.Equal(t, http.StatusConflict, httpStatusFromError(status.Error(codes.FailedPrecondition, "busy")))
+	assert.Equal(t, http.StatusInternalServerError, httpStatusFromError(assert.AnError))
+
+	rec := httptest.NewRecorder()
+	writeHTTPError(rec, http.StatusUnauthorized, "missing or invalid API key")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"error":"missing or invalid API key"}`, rec.Body.String())
+}
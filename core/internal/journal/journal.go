@@ -0,0 +1,443 @@
+// Package journal implementa el journal durable de mensajes del Core.
+//
+// Cada AgentMessage recibido y cada CoreMessage enviado se registra como
+// pb.JournalRecord con una secuencia monotónica, hora de llegada y agent_id.
+//
+// Formato en disco:
+//   - Segmentos "journal-<seq inicial, 20 dígitos>.log" con registros length-delimited
+//     (varint + protobuf), en orden de seq.
+//   - Al superar SegmentMaxBytes el segmento se rota; los segmentos cerrados se
+//     comprimen a ".log.gz" en background si Compress está activo.
+//   - MaxSegments limita los segmentos retenidos (los más antiguos se eliminan).
+//
+// La secuencia continúa entre reinicios: al abrir se lee el último segmento para
+// recuperar el último seq. Un registro truncado al final (crash) se descarta.
+//
+// Con Options.ConfigSnapshot cada segmento nuevo abre con un registro CONFIG, de modo
+// que cualquier segmento retenido alcanza para reproducirlo.
+package journal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+const (
+	segmentPrefix = "journal-"
+	segmentSuffix = ".log"
+	gzipSuffix    = ".gz"
+
+	// DefaultSegmentMaxBytes tamaño de rotación por defecto (64 MiB).
+	DefaultSegmentMaxBytes int64 = 64 << 20
+
+	// DefaultMaxSegments retención por defecto.
+	DefaultMaxSegments = 50
+)
+
+// Options configura el Writer.
+type Options struct {
+	Dir             string
+	SegmentMaxBytes int64 // <= 0 usa DefaultSegmentMaxBytes
+	MaxSegments     int   // <= 0 usa DefaultMaxSegments
+	Compress        bool  // Comprimir segmentos cerrados con gzip
+
+	// ConfigSnapshot se escribe como registro CONFIG al inicio de cada segmento
+	// (opcional). El replay lo usa en lugar de la config actual.
+	ConfigSnapshot []byte
+
+	// Now hora asignada a cada registro (arrival_ms); nil usa time.Now.
+	Now func() time.Time
+
+	// OnError recibe errores de escritura/rotación/compresión (opcional).
+	// El journal nunca bloquea ni interrumpe el flujo de mensajes.
+	OnError func(err error)
+}
+
+// Writer agrega registros al journal. Es seguro para uso concurrente.
+type Writer struct {
+	opts Options
+
+	mu     sync.Mutex
+	seq    uint64
+	file   *os.File
+	buf    *bufio.Writer
+	size   int64
+	closed bool
+	now    func() time.Time
+	compWG sync.WaitGroup
+}
+
+// Open abre (o crea) el journal en opts.Dir y recupera la última secuencia.
+func Open(opts Options) (*Writer, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("journal dir is required")
+	}
+	if opts.SegmentMaxBytes <= 0 {
+		opts.SegmentMaxBytes = DefaultSegmentMaxBytes
+	}
+	if opts.MaxSegments <= 0 {
+		opts.MaxSegments = DefaultMaxSegments
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create journal dir: %w", err)
+	}
+
+	w := &Writer{opts: opts, now: opts.Now}
+	if w.now == nil {
+		w.now = time.Now
+	}
+
+	segments, err := ListSegments(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) > 0 {
+		tail := segments[len(segments)-1]
+		last, err := lastSeq(tail)
+		if err != nil {
+			return nil, err
+		}
+		w.seq = last
+		if last < tail.FirstSeq {
+			// Segmento sin registros válidos: se descarta para no reutilizar su nombre
+			if err := os.Remove(tail.Path); err != nil {
+				return nil, fmt.Errorf("failed to remove empty journal segment: %w", err)
+			}
+			segments = segments[:len(segments)-1]
+		}
+	}
+
+	// Comprimir segmentos que quedaron sin comprimir (rotación interrumpida)
+	if opts.Compress {
+		for _, segment := range segments {
+			if !segment.Compressed {
+				w.compressAsync(segment.Path)
+			}
+		}
+	}
+
+	return w, nil
+}
+
+// Seq retorna la última secuencia asignada.
+func (w *Writer) Seq() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.seq
+}
+
+// RecordInbound registra un AgentMessage recibido del Agent.
+func (w *Writer) RecordInbound(agentID string, msg *pb.AgentMessage) {
+	w.append(&pb.JournalRecord{
+		AgentId:   agentID,
+		Direction: pb.JournalDirection_JOURNAL_DIRECTION_INBOUND,
+		Message:   &pb.JournalRecord_AgentMessage{AgentMessage: msg},
+	})
+}
+
+// RecordOutbound registra un CoreMessage enviado al Agent.
+func (w *Writer) RecordOutbound(agentID string, msg *pb.CoreMessage) {
+	w.append(&pb.JournalRecord{
+		AgentId:   agentID,
+		Direction: pb.JournalDirection_JOURNAL_DIRECTION_OUTBOUND,
+		Message:   &pb.JournalRecord_CoreMessage{CoreMessage: msg},
+	})
+}
+
+func (w *Writer) append(record *pb.JournalRecord) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return
+	}
+
+	if w.file == nil {
+		if err := w.openSegment(w.seq + 1); err != nil {
+			w.reportError(err)
+			return
+		}
+		if w.size == 0 && len(w.opts.ConfigSnapshot) > 0 {
+			if err := w.write(&pb.JournalRecord{
+				Direction: pb.JournalDirection_JOURNAL_DIRECTION_CONFIG,
+				Message:   &pb.JournalRecord_CoreConfig{CoreConfig: w.opts.ConfigSnapshot},
+			}); err != nil {
+				w.reportError(err)
+				return
+			}
+		}
+	}
+
+	if err := w.write(record); err != nil {
+		w.reportError(err)
+		return
+	}
+
+	if w.size >= w.opts.SegmentMaxBytes {
+		if err := w.rotate(); err != nil {
+			w.reportError(err)
+		}
+	}
+}
+
+// write asigna seq y hora al registro y lo agrega al segmento abierto.
+func (w *Writer) write(record *pb.JournalRecord) error {
+	record.Seq = w.seq + 1
+	record.ArrivalMs = w.now().UnixMilli()
+
+	n, err := protodelim.MarshalTo(w.buf, record)
+	if err == nil {
+		// Flush por registro: sobrevive a un crash del proceso (no del host)
+		err = w.buf.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to append journal record %d: %w", record.Seq, err)
+	}
+
+	w.seq = record.Seq
+	w.size += int64(n)
+	return nil
+}
+
+func (w *Writer) openSegment(firstSeq uint64) error {
+	path := filepath.Join(w.opts.Dir, segmentName(firstSeq))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open journal segment: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat journal segment: %w", err)
+	}
+	w.file = file
+	w.buf = bufio.NewWriterSize(file, 64<<10)
+	w.size = info.Size()
+	return nil
+}
+
+// rotate cierra el segmento actual; el siguiente append abre uno nuevo.
+func (w *Writer) rotate() error {
+	path, err := w.closeSegment()
+	if err != nil {
+		return err
+	}
+	if w.opts.Compress && path != "" {
+		w.compressAsync(path)
+	}
+	return w.enforceRetention()
+}
+
+func (w *Writer) closeSegment() (string, error) {
+	if w.file == nil {
+		return "", nil
+	}
+	path := w.file.Name()
+	err := w.buf.Flush()
+	if syncErr := w.file.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.file = nil
+	w.buf = nil
+	w.size = 0
+	if err != nil {
+		return path, fmt.Errorf("failed to close journal segment: %w", err)
+	}
+	return path, nil
+}
+
+func (w *Writer) enforceRetention() error {
+	segments, err := ListSegments(w.opts.Dir)
+	if err != nil {
+		return err
+	}
+	for len(segments) > w.opts.MaxSegments {
+		if err := os.Remove(segments[0].Path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove journal segment: %w", err)
+		}
+		segments = segments[1:]
+	}
+	return nil
+}
+
+// compressAsync comprime path a path.gz y elimina el original.
+func (w *Writer) compressAsync(path string) {
+	w.compWG.Add(1)
+	go func() {
+		defer w.compWG.Done()
+		if err := compressFile(path); err != nil {
+			w.mu.Lock()
+			w.reportError(err)
+			w.mu.Unlock()
+		}
+	}()
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open journal segment for compression: %w", err)
+	}
+	defer src.Close()
+
+	tmpPath := path + gzipSuffix + ".tmp"
+	dst, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to create compressed journal segment: %w", err)
+	}
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if syncErr := dst.Sync(); err == nil {
+		err = syncErr
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to compress journal segment %s: %w", filepath.Base(path), err)
+	}
+
+	if err := os.Rename(tmpPath, path+gzipSuffix); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to finalize compressed journal segment: %w", err)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove compressed journal segment source: %w", err)
+	}
+	return nil
+}
+
+// reportError debe llamarse con w.mu tomado.
+func (w *Writer) reportError(err error) {
+	if w.opts.OnError != nil {
+		w.opts.OnError(err)
+	}
+}
+
+// Sync fuerza flush + fsync del segmento actual.
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to flush journal: %w", err)
+	}
+	return w.file.Sync()
+}
+
+// Close cierra el segmento actual y espera compresiones en curso.
+//
+// El segmento abierto no se comprime: al reabrir se continúa escribiendo en uno nuevo
+// y el anterior se comprime en Open.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	_, err := w.closeSegment()
+	w.mu.Unlock()
+
+	w.compWG.Wait()
+	return err
+}
+
+// ==========================================================================
+// Segmentos
+// ==========================================================================
+
+// Segment describe un archivo de segmento del journal.
+type Segment struct {
+	Path       string
+	FirstSeq   uint64
+	Compressed bool
+}
+
+// ListSegments retorna los segmentos de dir ordenados por seq inicial.
+func ListSegments(dir string) ([]Segment, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal dir: %w", err)
+	}
+
+	segments := make([]Segment, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		segment, ok := parseSegmentName(entry.Name())
+		if !ok {
+			continue
+		}
+		segment.Path = filepath.Join(dir, entry.Name())
+		segments = append(segments, segment)
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].FirstSeq < segments[j].FirstSeq })
+	return segments, nil
+}
+
+func segmentName(firstSeq uint64) string {
+	return fmt.Sprintf("%s%020d%s", segmentPrefix, firstSeq, segmentSuffix)
+}
+
+func parseSegmentName(name string) (Segment, bool) {
+	compressed := strings.HasSuffix(name, segmentSuffix+gzipSuffix)
+	base := strings.TrimSuffix(name, gzipSuffix)
+	if !strings.HasPrefix(base, segmentPrefix) || !strings.HasSuffix(base, segmentSuffix) {
+		return Segment{}, false
+	}
+	digits := strings.TrimSuffix(strings.TrimPrefix(base, segmentPrefix), segmentSuffix)
+	firstSeq, err := strconv.ParseUint(digits, 10, 64)
+	if err != nil {
+		return Segment{}, false
+	}
+	return Segment{FirstSeq: firstSeq, Compressed: compressed}, true
+}
+
+// lastSeq lee un segmento completo y retorna el último seq válido.
+func lastSeq(segment Segment) (uint64, error) {
+	reader, err := OpenSegment(segment.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	var last uint64
+	if segment.FirstSeq > 0 {
+		last = segment.FirstSeq - 1
+	}
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return last, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		last = record.Seq
+	}
+}
@@ -0,0 +1,153 @@
+package journal
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+func readAll(t *testing.T, dir string) []*pb.JournalRecord {
+	t.Helper()
+	segments, err := ListSegments(dir)
+	require.NoError(t, err)
+
+	var records []*pb.JournalRecord
+	for _, segment := range segments {
+		segmentRecords, err := ReadSegment(segment.Path)
+		require.NoError(t, err)
+		records = append(records, segmentRecords...)
+	}
+	return records
+}
+
+func TestWriterRotatesCompressesAndResumesSeq(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Open(Options{Dir: dir, SegmentMaxBytes: 64, Compress: true})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		w.RecordInbound("agent-1", &pb.AgentMessage{AgentId: "agent-1", TimestampMs: int64(i)})
+		w.RecordOutbound("agent-1", &pb.CoreMessage{TimestampMs: int64(i)})
+	}
+	require.NoError(t, w.Close())
+
+	segments, err := ListSegments(dir)
+	require.NoError(t, err)
+	require.Greater(t, len(segments), 1)
+	assert.True(t, segments[0].Compressed)
+
+	records := readAll(t, dir)
+	require.Len(t, records, 20)
+	for i, record := range records {
+		assert.Equal(t, uint64(i+1), record.Seq)
+		assert.Equal(t, "agent-1", record.AgentId)
+	}
+	assert.Equal(t, pb.JournalDirection_JOURNAL_DIRECTION_INBOUND, records[0].Direction)
+	assert.NotNil(t, records[0].GetAgentMessage())
+	assert.Equal(t, pb.JournalDirection_JOURNAL_DIRECTION_OUTBOUND, records[1].Direction)
+	assert.NotNil(t, records[1].GetCoreMessage())
+
+	// Reabrir continúa la secuencia
+	w, err = Open(Options{Dir: dir, SegmentMaxBytes: 64, Compress: true})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), w.Seq())
+	w.RecordInbound("agent-2", &pb.AgentMessage{AgentId: "agent-2"})
+	require.NoError(t, w.Close())
+
+	records = readAll(t, dir)
+	require.Len(t, records, 21)
+	assert.Equal(t, uint64(21), records[20].Seq)
+}
+
+func TestWriterRetention(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Open(Options{Dir: dir, SegmentMaxBytes: 1, MaxSegments: 3})
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		w.RecordInbound("agent-1", &pb.AgentMessage{AgentId: "agent-1"})
+	}
+	require.NoError(t, w.Close())
+
+	segments, err := ListSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 3)
+	assert.Equal(t, uint64(8), segments[0].FirstSeq)
+}
+
+func TestReaderToleratesTruncatedTail(t *testing.T) {
+	dir := t.TempDir()
+	w, err := Open(Options{Dir: dir})
+	require.NoError(t, err)
+	w.RecordInbound("agent-1", &pb.AgentMessage{AgentId: "agent-1"})
+	w.RecordInbound("agent-1", &pb.AgentMessage{AgentId: "agent-1"})
+	require.NoError(t, w.Close())
+
+	segments, err := ListSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+
+	// Simular crash a mitad de un registro
+	f, err := os.OpenFile(segments[0].Path, os.O_WRONLY|os.O_APPEND, 0o644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0x20, 0x08})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	reader, err := OpenSegment(segments[0].Path)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	count := 0
+	for {
+		_, err := reader.Next()
+		if err != nil {
+			break
+		}
+		count++
+	}
+	assert.Equal(t, 2, count)
+	assert.True(t, reader.Truncated)
+
+	// La secuencia se recupera ignorando el registro truncado
+	w, err = Open(Options{Dir: dir})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), w.Seq())
+	require.NoError(t, w.Close())
+}
+
+func TestWriterConfigSnapshotPerSegment(t *testing.T) {
+	dir := t.TempDir()
+	now := time.UnixMilli(1_700_000_000_000)
+	w, err := Open(Options{
+		Dir:             dir,
+		SegmentMaxBytes: 1,
+		ConfigSnapshot:  []byte(`{"CanonicalSymbols":["XAUUSD"]}`),
+		Now:             func() time.Time { return now },
+	})
+	require.NoError(t, err)
+
+	w.RecordInbound("agent-1", &pb.AgentMessage{AgentId: "agent-1"})
+	w.RecordInbound("agent-1", &pb.AgentMessage{AgentId: "agent-1"})
+	require.NoError(t, w.Close())
+
+	// Cada segmento abre con su registro CONFIG y la secuencia sigue siendo continua
+	segments, err := ListSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+	for _, segment := range segments {
+		records, err := ReadSegment(segment.Path)
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, pb.JournalDirection_JOURNAL_DIRECTION_CONFIG, records[0].Direction)
+		assert.JSONEq(t, `{"CanonicalSymbols":["XAUUSD"]}`, string(records[0].GetCoreConfig()))
+		assert.Equal(t, segment.FirstSeq, records[0].Seq)
+		assert.Equal(t, pb.JournalDirection_JOURNAL_DIRECTION_INBOUND, records[1].Direction)
+		assert.Equal(t, now.UnixMilli(), records[1].ArrivalMs)
+	}
+	assert.Equal(t, uint64(3), segments[1].FirstSeq)
+}
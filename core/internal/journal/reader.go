@@ -0,0 +1,97 @@
+package journal
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"google.golang.org/protobuf/encoding/protodelim"
+)
+
+// maxRecordBytes limita el tamaño de un registro al leer (protección ante corrupción).
+const maxRecordBytes = 16 << 20
+
+// Reader lee registros de un segmento (comprimido o no).
+type Reader struct {
+	file *os.File
+	gz   *gzip.Reader
+	buf  *bufio.Reader
+
+	// Truncated indica que el segmento terminó con un registro incompleto
+	// (crash durante la escritura); los registros previos son válidos.
+	Truncated bool
+}
+
+// OpenSegment abre un segmento para lectura.
+func OpenSegment(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal segment: %w", err)
+	}
+
+	reader := &Reader{file: file}
+	var src io.Reader = file
+	if strings.HasSuffix(path, gzipSuffix) {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to open compressed journal segment: %w", err)
+		}
+		reader.gz = gz
+		src = gz
+	}
+	reader.buf = bufio.NewReaderSize(src, 64<<10)
+
+	return reader, nil
+}
+
+// Next retorna el siguiente registro o io.EOF al final del segmento.
+func (r *Reader) Next() (*pb.JournalRecord, error) {
+	record := &pb.JournalRecord{}
+	err := protodelim.UnmarshalOptions{MaxSize: maxRecordBytes}.UnmarshalFrom(r.buf, record)
+	if err == nil {
+		return record, nil
+	}
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		r.Truncated = true
+		return nil, io.EOF
+	}
+	return nil, fmt.Errorf("failed to read journal record: %w", err)
+}
+
+// Close libera el segmento.
+func (r *Reader) Close() error {
+	if r.gz != nil {
+		r.gz.Close()
+	}
+	return r.file.Close()
+}
+
+// ReadSegment lee todos los registros de un segmento.
+func ReadSegment(path string) ([]*pb.JournalRecord, error) {
+	reader, err := OpenSegment(path)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var records []*pb.JournalRecord
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
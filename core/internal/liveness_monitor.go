@@ -0,0 +1,408 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// livenessPurgeInterval frecuencia de la purga de Agents sin heartbeat.
+const livenessPurgeInterval = time.Hour
+
+// livenessMonitor detecta Agents sin heartbeat y excluye sus cuentas del routing (i8).
+//
+// Un stream half-open no se distingue de un Agent sano pero sin tráfico: ambos
+// mantienen el registro de cuentas. El monitor lo resuelve con los AgentHeartbeat:
+//
+//   - Un Agent se vigila desde su primer heartbeat (Agents que no los envían no se marcan).
+//   - Tras MissedBeats intervalos sin heartbeat el Agent pasa a STALE y sus cuentas
+//     dejan de resolverse en AccountRegistry.GetOwner: los ExecuteOrders quedan en el outbox.
+//   - Las cuentas cuyo pipe el heartbeat reporta desconectado se excluyen igual.
+//   - El siguiente heartbeat restaura el routing y dispara la entrega del outbox.
+//
+// El último estado se persiste cada PersistInterval para echo-core-cli agents.
+type livenessMonitor struct {
+	registry    *AccountRegistry
+	repo        domain.AgentLivenessRepository
+	config      LivenessConfig
+	telemetry   *telemetry.Client
+	echoMetrics *metricbundle.EchoMetrics
+
+	// onRestored se invoca al volver a rutear cuentas (Kick del outbox)
+	onRestored func()
+
+	mu     sync.Mutex
+	agents map[string]*agentLivenessState // key: agent_id
+
+	now    func() time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// agentLivenessState estado en memoria de un Agent vigilado.
+type agentLivenessState struct {
+	record       domain.AgentLiveness
+	interval     time.Duration
+	dirty        bool // pendiente de persistir
+	disconnected bool // stream cerrado: se descarta tras persistir
+}
+
+func newLivenessMonitor(
+	parentCtx context.Context,
+	registry *AccountRegistry,
+	repo domain.AgentLivenessRepository,
+	config LivenessConfig,
+	telemetryClient *telemetry.Client,
+	echoMetrics *metricbundle.EchoMetrics,
+	onRestored func(),
+) *livenessMonitor {
+	ctx, cancel := context.WithCancel(parentCtx)
+	return &livenessMonitor{
+		registry:    registry,
+		repo:        repo,
+		config:      config,
+		telemetry:   telemetryClient,
+		echoMetrics: echoMetrics,
+		onRestored:  onRestored,
+		agents:      make(map[string]*agentLivenessState),
+		now:         time.Now,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+func (m *livenessMonitor) Start() {
+	m.wg.Add(1)
+	go m.worker()
+}
+
+// Stop detiene el monitor y persiste el último estado.
+func (m *livenessMonitor) Stop() {
+	m.cancel()
+	m.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	m.flush(ctx)
+}
+
+func (m *livenessMonitor) worker() {
+	defer m.wg.Done()
+
+	checkTicker := time.NewTicker(m.config.CheckInterval)
+	defer checkTicker.Stop()
+	persistTicker := time.NewTicker(m.config.PersistInterval)
+	defer persistTicker.Stop()
+	purgeTicker := time.NewTicker(livenessPurgeInterval)
+	defer purgeTicker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-checkTicker.C:
+			m.check()
+		case <-persistTicker.C:
+			m.flush(m.ctx)
+		case <-purgeTicker.C:
+			m.purge(m.ctx)
+		}
+	}
+}
+
+// Observe registra un AgentHeartbeat recibido por el stream del Agent.
+func (m *livenessMonitor) Observe(agentID string, heartbeat *pb.AgentHeartbeat) {
+	now := m.now()
+	nowMs := now.UnixMilli()
+
+	interval := time.Duration(heartbeat.IntervalMs) * time.Millisecond
+	if interval <= 0 {
+		interval = m.config.DefaultInterval
+	}
+
+	m.mu.Lock()
+	state, ok := m.agents[agentID]
+	if !ok {
+		state = &agentLivenessState{}
+		m.agents[agentID] = state
+	}
+	wasStale := state.record.Status == domain.LivenessStatusStale
+
+	previousSeen := make(map[string]int64, len(state.record.Accounts))
+	for _, account := range state.record.Accounts {
+		previousSeen[account.PipeName] = account.LastSeenAtMs
+	}
+
+	state.interval = interval
+	state.dirty = true
+	state.disconnected = false
+	state.record = domain.AgentLiveness{
+		AgentID:             agentID,
+		Status:              domain.LivenessStatusAlive,
+		LastSeenAtMs:        nowMs,
+		HeartbeatIntervalMs: interval.Milliseconds(),
+		PendingCommands:     int(heartbeat.GetPendingCommands()),
+		PendingBytes:        heartbeat.PendingBytes,
+		UpdatedAtMs:         nowMs,
+	}
+
+	// Estado por cuenta: con pipes reportados vale el flag connected; sin ellos
+	// (Agents sin PipeStatus) solo se conocen las cuentas conectadas.
+	connected := make(map[string]bool, len(heartbeat.Pipes))
+	for _, pipe := range heartbeat.Pipes {
+		account := &domain.AccountLiveness{
+			AgentID:      agentID,
+			PipeName:     pipe.PipeName,
+			AccountID:    pipe.AccountId,
+			PipeRole:     pipe.Role,
+			Status:       domain.LivenessStatusDisconnected,
+			Codec:        pipe.Codec,
+			LastSeenAtMs: previousSeen[pipe.PipeName],
+		}
+		if pipe.Connected {
+			account.Status = domain.LivenessStatusAlive
+			account.LastSeenAtMs = nowMs
+		}
+		state.record.Accounts = append(state.record.Accounts, account)
+		connected[pipe.AccountId] = connected[pipe.AccountId] || pipe.Connected
+	}
+	if len(heartbeat.Pipes) == 0 {
+		for _, accountID := range heartbeat.ConnectedClients {
+			connected[accountID] = true
+		}
+	}
+	m.mu.Unlock()
+
+	staled, restored := m.registry.ApplyHeartbeat(agentID, connected, now)
+
+	m.echoMetrics.RecordAgentHeartbeat(m.ctx, agentID,
+		attribute.Int("pending_commands", int(heartbeat.GetPendingCommands())),
+	)
+
+	if wasStale {
+		m.telemetry.Info(m.ctx, "Agent heartbeat resumed, accounts routable again (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.StringSlice("restored_accounts", restored),
+		)
+		m.echoMetrics.RecordAgentLivenessTransition(m.ctx, "alive", "agent",
+			attribute.String("agent_id", agentID),
+		)
+	}
+	for _, accountID := range restored {
+		m.echoMetrics.RecordAgentLivenessTransition(m.ctx, "alive", "account",
+			attribute.String("agent_id", agentID),
+			attribute.String("account_id", accountID),
+		)
+	}
+	for _, accountID := range staled {
+		m.telemetry.Warn(m.ctx, "Account pipe disconnected per heartbeat, excluded from routing (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.String("account_id", accountID),
+		)
+		m.echoMetrics.RecordAgentLivenessTransition(m.ctx, "stale", "account",
+			attribute.String("agent_id", agentID),
+			attribute.String("account_id", accountID),
+		)
+	}
+
+	if len(restored) > 0 && m.onRestored != nil {
+		m.onRestored()
+	}
+}
+
+// Disconnected registra el cierre del stream del Agent (su registro ya se limpió).
+func (m *livenessMonitor) Disconnected(agentID string) {
+	nowMs := m.now().UnixMilli()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.agents[agentID]
+	if !ok {
+		return
+	}
+	state.record.Status = domain.LivenessStatusDisconnected
+	state.record.UpdatedAtMs = nowMs
+	for _, account := range state.record.Accounts {
+		account.Status = domain.LivenessStatusDisconnected
+	}
+	state.dirty = true
+	state.disconnected = true
+}
+
+// check marca STALE a los Agents sin heartbeat tras MissedBeats intervalos.
+func (m *livenessMonitor) check() {
+	now := m.now()
+	nowMs := now.UnixMilli()
+
+	type staleAgent struct {
+		agentID string
+		ageMs   int64
+	}
+	var staleAgents []staleAgent
+
+	m.mu.Lock()
+	for agentID, state := range m.agents {
+		if state.disconnected {
+			continue
+		}
+
+		ageMs := nowMs - state.record.LastSeenAtMs
+		m.echoMetrics.RecordAgentLastSeenAge(m.ctx, float64(ageMs),
+			attribute.String("agent_id", agentID),
+			attribute.String("status", string(state.record.Status)),
+		)
+
+		if state.record.Status == domain.LivenessStatusStale {
+			continue
+		}
+		if ageMs <= (state.interval * time.Duration(m.config.MissedBeats)).Milliseconds() {
+			continue
+		}
+
+		state.record.Status = domain.LivenessStatusStale
+		state.record.StaleSinceMs = nowMs
+		state.record.UpdatedAtMs = nowMs
+		for _, account := range state.record.Accounts {
+			if account.Status == domain.LivenessStatusAlive {
+				account.Status = domain.LivenessStatusStale
+			}
+		}
+		state.dirty = true
+		staleAgents = append(staleAgents, staleAgent{agentID: agentID, ageMs: ageMs})
+	}
+	m.mu.Unlock()
+
+	for _, stale := range staleAgents {
+		accounts := m.registry.MarkAgentStale(stale.agentID, now)
+
+		m.telemetry.Warn(m.ctx, "Agent missed heartbeats, accounts excluded from routing (i8)",
+			attribute.String("agent_id", stale.agentID),
+			attribute.Int64("last_seen_age_ms", stale.ageMs),
+			attribute.Int("missed_beats", m.config.MissedBeats),
+			attribute.StringSlice("stale_accounts", accounts),
+		)
+		m.echoMetrics.RecordAgentLivenessTransition(m.ctx, "stale", "agent",
+			attribute.String("agent_id", stale.agentID),
+		)
+		for _, accountID := range accounts {
+			m.echoMetrics.RecordAgentLivenessTransition(m.ctx, "stale", "account",
+				attribute.String("agent_id", stale.agentID),
+				attribute.String("account_id", accountID),
+			)
+		}
+	}
+}
+
+// flush persiste el estado de los Agents modificados desde la última escritura.
+func (m *livenessMonitor) flush(ctx context.Context) {
+	if m.repo == nil {
+		return
+	}
+
+	m.mu.Lock()
+	pending := make([]*domain.AgentLiveness, 0, len(m.agents))
+	for agentID, state := range m.agents {
+		if !state.dirty {
+			continue
+		}
+		pending = append(pending, copyAgentLiveness(&state.record))
+		state.dirty = false
+		if state.disconnected {
+			delete(m.agents, agentID)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, liveness := range pending {
+		if err := m.repo.Upsert(ctx, liveness); err != nil {
+			m.telemetry.Warn(ctx, "Failed to persist agent liveness (i8)",
+				attribute.String("agent_id", liveness.AgentID),
+				attribute.String("error", err.Error()),
+			)
+			m.markDirty(liveness.AgentID)
+		}
+	}
+}
+
+// markDirty reintenta la persistencia de un Agent en el próximo flush.
+func (m *livenessMonitor) markDirty(agentID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if state, ok := m.agents[agentID]; ok {
+		state.dirty = true
+	}
+}
+
+// purge elimina los Agents sin heartbeat desde hace más de Retention.
+func (m *livenessMonitor) purge(ctx context.Context) {
+	if m.repo == nil {
+		return
+	}
+
+	cutoffMs := m.now().Add(-m.config.Retention).UnixMilli()
+	deleted, err := m.repo.PurgeOlderThan(ctx, cutoffMs)
+	if err != nil {
+		m.telemetry.Warn(ctx, "Agent liveness purge failed (i8)",
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+	if deleted > 0 {
+		m.telemetry.Debug(ctx, "Agent liveness purge completed (i8)",
+			attribute.Int("deleted", deleted),
+		)
+	}
+}
+
+// copyAgentLiveness copia el registro para persistirlo fuera del lock.
+func copyAgentLiveness(record *domain.AgentLiveness) *domain.AgentLiveness {
+	copied := *record
+	copied.Accounts = make([]*domain.AccountLiveness, 0, len(record.Accounts))
+	for _, account := range record.Accounts {
+		accountCopy := *account
+		copied.Accounts = append(copied.Accounts, &accountCopy)
+	}
+	return &copied
+}
+
+// ListAgentLiveness carga la configuración desde ETCD y retorna el último estado
+// persistido de cada Agent (i8).
+//
+// Lo usa echo-core-cli agents sin inicializar Core.
+//
+// Example:
+//
+//	agents, err := internal.ListAgentLiveness(ctx)
+//	if err != nil {
+//	    return err
+//	}
+func ListAgentLiveness(ctx context.Context) ([]*domain.AgentLiveness, error) {
+	config, err := LoadConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config from ETCD: %w", err)
+	}
+	if config.StorageBackend == StorageBackendMemory {
+		return nil, fmt.Errorf("storage/backend %q does not persist agent liveness", config.StorageBackend)
+	}
+
+	db, factory, err := newRepositoryFactory(ctx, config, time.Now)
+	if err != nil {
+		return nil, err
+	}
+	defer closeDB(db)
+
+	agents, err := factory.AgentLivenessRepository().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list agent liveness: %w", err)
+	}
+	return agents, nil
+}
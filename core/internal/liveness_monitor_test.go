@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/core/internal/repository"
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+)
+
+// newTestLivenessMonitor arma un monitor con reloj controlado (MissedBeats 3, intervalo
+// por defecto 1s) y agent-1 dueño de s1 y s2. restoredCalls cuenta los Kick del outbox.
+func newTestLivenessMonitor(t *testing.T) (*livenessMonitor, *AccountRegistry, domain.AgentLivenessRepository, *time.Time, *int) {
+	t.Helper()
+
+	registry, now := newTestAccountRegistry(30 * time.Second)
+	registry.RegisterAccount("agent-1", "s1", "slave")
+	registry.RegisterAccount("agent-1", "s2", "slave")
+
+	repo := repository.NewMemoryFactory(0).AgentLivenessRepository()
+	restoredCalls := 0
+	monitor := newLivenessMonitor(context.Background(), registry, repo, LivenessConfig{
+		MissedBeats:     3,
+		DefaultInterval: time.Second,
+	}, &telemetry.Client{}, &metricbundle.EchoMetrics{}, func() { restoredCalls++ })
+	monitor.now = func() time.Time { return *now }
+	return monitor, registry, repo, now, &restoredCalls
+}
+
+func heartbeatWithPipes(connected map[string]bool) *pb.AgentHeartbeat {
+	heartbeat := &pb.AgentHeartbeat{AgentId: "agent-1", IntervalMs: 1000}
+	for _, accountID := range []string{"s1", "s2"} {
+		isConnected, ok := connected[accountID]
+		if !ok {
+			continue
+		}
+		heartbeat.Pipes = append(heartbeat.Pipes, &pb.PipeStatus{
+			PipeName:  "echo_slave_" + accountID,
+			AccountId: accountID,
+			Role:      "slave",
+			Connected: isConnected,
+		})
+	}
+	return heartbeat
+}
+
+func TestLivenessMonitorMarksStaleAfterMissedBeats(t *testing.T) {
+	ctx := context.Background()
+	monitor, registry, repo, now, restoredCalls := newTestLivenessMonitor(t)
+	registry.RegisterAccount("agent-2", "s3", "slave")
+
+	monitor.Observe("agent-1", heartbeatWithPipes(map[string]bool{"s1": true, "s2": true}))
+
+	// Exactamente MissedBeats intervalos sin heartbeat todavía es alive
+	*now = now.Add(3 * time.Second)
+	monitor.check()
+	_, ok := registry.GetOwner("s1")
+	assert.True(t, ok)
+
+	*now = now.Add(time.Millisecond)
+	monitor.check()
+	_, ok = registry.GetOwner("s1")
+	assert.False(t, ok)
+	_, ok = registry.GetOwner("s2")
+	assert.False(t, ok)
+	// agent-2 nunca envió heartbeat: no se vigila
+	owner, ok := registry.GetOwner("s3")
+	require.True(t, ok)
+	assert.Equal(t, "agent-2", owner)
+
+	monitor.flush(ctx)
+	agents, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+	assert.Equal(t, domain.LivenessStatusStale, agents[0].Status)
+	assert.Equal(t, now.UnixMilli(), agents[0].StaleSinceMs)
+	require.Len(t, agents[0].Accounts, 2)
+	assert.Equal(t, domain.LivenessStatusStale, agents[0].Accounts[0].Status)
+
+	// El siguiente heartbeat restaura el routing y dispara la entrega del outbox
+	assert.Zero(t, *restoredCalls)
+	*now = now.Add(10 * time.Second)
+	monitor.Observe("agent-1", heartbeatWithPipes(map[string]bool{"s1": true, "s2": true}))
+	owner, ok = registry.GetOwner("s1")
+	require.True(t, ok)
+	assert.Equal(t, "agent-1", owner)
+	_, ok = registry.GetOwner("s2")
+	assert.True(t, ok)
+	assert.Equal(t, 1, *restoredCalls)
+
+	monitor.flush(ctx)
+	agents, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+	assert.Equal(t, domain.LivenessStatusAlive, agents[0].Status)
+	assert.Equal(t, now.UnixMilli(), agents[0].LastSeenAtMs)
+}
+
+func TestLivenessMonitorExcludesDisconnectedPipes(t *testing.T) {
+	monitor, registry, _, now, restoredCalls := newTestLivenessMonitor(t)
+
+	// El EA de s2 se desconectó de su pipe aunque el Agent siga vivo
+	monitor.Observe("agent-1", heartbeatWithPipes(map[string]bool{"s1": true, "s2": false}))
+	_, ok := registry.GetOwner("s1")
+	assert.True(t, ok)
+	_, ok = registry.GetOwner("s2")
+	assert.False(t, ok)
+	assert.Zero(t, *restoredCalls)
+
+	*now = now.Add(time.Second)
+	monitor.Observe("agent-1", heartbeatWithPipes(map[string]bool{"s1": true, "s2": true}))
+	_, ok = registry.GetOwner("s2")
+	assert.True(t, ok)
+	assert.Equal(t, 1, *restoredCalls)
+
+	// Un stream cerrado no se marca stale: su registro ya se limpió
+	monitor.Disconnected("agent-1")
+	*now = now.Add(time.Minute)
+	monitor.check()
+	_, ok = registry.GetOwner("s1")
+	assert.True(t, ok)
+}
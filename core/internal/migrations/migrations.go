@@ -0,0 +1,456 @@
+// Package migrations contiene las migraciones de schema embebidas en Core y el runner
+// que las aplica, registrando cada versión aplicada en schema_migrations.
+//
+// Convenciones de archivos (<dialecto>/NNNN_nombre.sql):
+//   - NNNN es la versión; debe ser única y creciente por dialecto.
+//   - Si el archivo tiene marcadores "-- +migrate Up" / "-- +migrate Down", solo se
+//     aplica la sección Up.
+//   - Las líneas "BEGIN;" / "COMMIT;" de nivel superior se ignoran: el runner ejecuta
+//     cada migración y su registro en una única transacción.
+//
+// Las migraciones aplicadas son inmutables: cambios al schema requieren un archivo nuevo.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// Dialect identifica el motor de base de datos y su directorio de migraciones.
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+var (
+	// ErrSchemaTooNew indica que la base tiene migraciones posteriores a las embebidas
+	// en este binario (Core desactualizado respecto del schema).
+	ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+	// ErrBaselineRequired indica una base con schema pre-existente (aplicado a mano)
+	// sin historial de migraciones.
+	ErrBaselineRequired = errors.New("database has schema but no migration history; run echo-core-cli db migrate --baseline <version>")
+
+	// ErrPendingMigrations indica migraciones sin aplicar cuando no se permite migrar.
+	ErrPendingMigrations = errors.New("database schema has pending migrations; run echo-core-cli db migrate")
+)
+
+// Migration es una migración embebida.
+type Migration struct {
+	Version  int
+	Name     string
+	Checksum string // sha256 del archivo completo
+	SQL      string // Sección Up ejecutable
+}
+
+// AppliedMigration es una fila de schema_migrations.
+type AppliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Status resume el estado del schema respecto de las migraciones embebidas.
+type Status struct {
+	Dialect        Dialect
+	CurrentVersion int // Última versión aplicada (0 = ninguna)
+	LatestVersion  int // Última versión embebida
+	Applied        []AppliedMigration
+	Pending        []Migration
+}
+
+// dialectSpec agrupa las diferencias SQL entre motores.
+type dialectSpec struct {
+	table        string
+	createTable  string
+	insert       string
+	legacyProbe  string // true si existe schema aplicado fuera del runner
+	lock, unlock string // Lock de sesión para runners concurrentes (opcional)
+}
+
+// migrationLockKey es la clave de pg_advisory_lock del runner ("echo" en ASCII).
+const migrationLockKey = 0x6563686f
+
+var dialects = map[Dialect]dialectSpec{
+	DialectPostgres: {
+		table: "echo.schema_migrations",
+		createTable: `
+			CREATE SCHEMA IF NOT EXISTS echo;
+			CREATE TABLE IF NOT EXISTS echo.schema_migrations (
+				version    INTEGER PRIMARY KEY,
+				name       TEXT NOT NULL,
+				checksum   TEXT NOT NULL,
+				applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+			)`,
+		insert:      `INSERT INTO echo.schema_migrations (version, name, checksum, applied_at) VALUES ($1, $2, $3, $4)`,
+		legacyProbe: `SELECT to_regclass('echo.trades') IS NOT NULL`,
+		lock:        fmt.Sprintf(`SELECT pg_advisory_lock(%d)`, migrationLockKey),
+		unlock:      fmt.Sprintf(`SELECT pg_advisory_unlock(%d)`, migrationLockKey),
+	},
+	DialectSQLite: {
+		table: "schema_migrations",
+		createTable: `
+			CREATE TABLE IF NOT EXISTS schema_migrations (
+				version    INTEGER PRIMARY KEY,
+				name       TEXT NOT NULL,
+				checksum   TEXT NOT NULL,
+				applied_at INTEGER NOT NULL
+			)`,
+		insert:      `INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+		legacyProbe: `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'trades')`,
+	},
+}
+
+// Load retorna las migraciones embebidas del dialecto, ordenadas por versión.
+func Load(dialect Dialect) ([]Migration, error) {
+	if _, ok := dialects[dialect]; !ok {
+		return nil, fmt.Errorf("unsupported migration dialect: %s", dialect)
+	}
+
+	entries, err := fs.ReadDir(files, string(dialect))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		version, name, err := parseFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		if prev, dup := seen[version]; dup {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, prev, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		raw, err := fs.ReadFile(files, path.Join(string(dialect), entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		sum := sha256.Sum256(raw)
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     name,
+			Checksum: hex.EncodeToString(sum[:]),
+			SQL:      upSection(string(raw)),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parseFileName separa "0003_i3_symbols.sql" en (3, "i3_symbols").
+func parseFileName(file string) (int, string, error) {
+	base := strings.TrimSuffix(file, ".sql")
+	prefix, name, ok := strings.Cut(base, "_")
+	if !ok || name == "" {
+		return 0, "", fmt.Errorf("invalid migration file name %q (expected NNNN_name.sql)", file)
+	}
+	version, err := strconv.Atoi(prefix)
+	if err != nil || version <= 0 {
+		return 0, "", fmt.Errorf("invalid migration version in %q", file)
+	}
+	return version, name, nil
+}
+
+// upSection extrae la sección Up y descarta BEGIN;/COMMIT; de nivel superior.
+func upSection(raw string) string {
+	lines := strings.Split(raw, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.EqualFold(trimmed, "-- +migrate Up") {
+			out = out[:0]
+			continue
+		}
+		if strings.EqualFold(trimmed, "-- +migrate Down") {
+			break
+		}
+		if strings.EqualFold(trimmed, "BEGIN;") || strings.EqualFold(trimmed, "COMMIT;") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n")) + "\n"
+}
+
+// Runner aplica migraciones embebidas sobre una base.
+type Runner struct {
+	db         *sql.DB
+	dialect    Dialect
+	spec       dialectSpec
+	migrations []Migration
+	now        func() time.Time
+}
+
+// NewRunner crea un runner para db con las migraciones embebidas del dialecto.
+//
+// Example:
+//
+//	runner, err := migrations.NewRunner(db, migrations.DialectPostgres)
+//	if err != nil {
+//	    return err
+//	}
+//	applied, err := runner.Migrate(ctx)
+func NewRunner(db *sql.DB, dialect Dialect) (*Runner, error) {
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Runner{
+		db:         db,
+		dialect:    dialect,
+		spec:       dialects[dialect],
+		migrations: migrations,
+		now:        time.Now,
+	}, nil
+}
+
+// Migrations retorna las migraciones embebidas.
+func (r *Runner) Migrations() []Migration {
+	return r.migrations
+}
+
+// Status compara las migraciones aplicadas con las embebidas.
+//
+// Retorna ErrSchemaTooNew si la base tiene versiones posteriores a las embebidas y
+// error si una migración aplicada fue modificada o no existe en el binario.
+func (r *Runner) Status(ctx context.Context) (*Status, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Close()
+
+	if err := r.ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	return r.status(ctx, conn)
+}
+
+// Migrate aplica las migraciones pendientes en orden y retorna las aplicadas.
+//
+// Cada migración corre en su propia transacción junto con su registro en
+// schema_migrations; un fallo deja la base en la última versión completa.
+func (r *Runner) Migrate(ctx context.Context) ([]Migration, error) {
+	conn, err := r.lockedConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.release(conn)
+
+	status, err := r.status(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(status.Applied) == 0 && len(status.Pending) > 0 {
+		var legacy bool
+		if err := conn.QueryRowContext(ctx, r.spec.legacyProbe).Scan(&legacy); err != nil {
+			return nil, fmt.Errorf("failed to probe existing schema: %w", err)
+		}
+		if legacy {
+			return nil, ErrBaselineRequired
+		}
+	}
+
+	applied := make([]Migration, 0, len(status.Pending))
+	for _, m := range status.Pending {
+		if err := r.apply(ctx, conn, m); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// Baseline registra como aplicadas (sin ejecutarlas) las migraciones hasta version.
+//
+// Uso: adoptar bases creadas a mano con setup.sql + migraciones iN antes del runner.
+func (r *Runner) Baseline(ctx context.Context, version int) ([]Migration, error) {
+	if version <= 0 || version > r.latestVersion() {
+		return nil, fmt.Errorf("invalid baseline version %d (latest embedded: %d)", version, r.latestVersion())
+	}
+
+	conn, err := r.lockedConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.release(conn)
+
+	status, err := r.status(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if len(status.Applied) > 0 {
+		return nil, fmt.Errorf("migration history already exists (current version %d)", status.CurrentVersion)
+	}
+
+	recorded := make([]Migration, 0, version)
+	for _, m := range r.migrations {
+		if m.Version > version {
+			break
+		}
+		if _, err := conn.ExecContext(ctx, r.spec.insert, m.Version, m.Name, m.Checksum, r.appliedAtArg()); err != nil {
+			return recorded, fmt.Errorf("failed to record baseline migration %d: %w", m.Version, err)
+		}
+		recorded = append(recorded, m)
+	}
+	return recorded, nil
+}
+
+func (r *Runner) apply(ctx context.Context, conn *sql.Conn, m Migration) (err error) {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if _, err = tx.ExecContext(ctx, m.SQL); err != nil {
+		return fmt.Errorf("failed to apply migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+	if _, err = tx.ExecContext(ctx, r.spec.insert, m.Version, m.Name, m.Checksum, r.appliedAtArg()); err != nil {
+		return fmt.Errorf("failed to record migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit migration %04d_%s: %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+func (r *Runner) status(ctx context.Context, conn *sql.Conn) (*Status, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, name, checksum, applied_at FROM `+r.spec.table+` ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	status := &Status{Dialect: r.dialect, LatestVersion: r.latestVersion()}
+	for rows.Next() {
+		var (
+			applied   AppliedMigration
+			appliedAt interface{}
+		)
+		if err := rows.Scan(&applied.Version, &applied.Name, &applied.Checksum, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied.AppliedAt = toTime(appliedAt)
+		status.Applied = append(status.Applied, applied)
+		status.CurrentVersion = applied.Version
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	if status.CurrentVersion > status.LatestVersion {
+		return status, fmt.Errorf("%w: database at version %d, binary supports up to %d",
+			ErrSchemaTooNew, status.CurrentVersion, status.LatestVersion)
+	}
+
+	embedded := make(map[int]Migration, len(r.migrations))
+	for _, m := range r.migrations {
+		embedded[m.Version] = m
+	}
+	appliedSet := make(map[int]struct{}, len(status.Applied))
+	for _, applied := range status.Applied {
+		m, ok := embedded[applied.Version]
+		if !ok {
+			return status, fmt.Errorf("applied migration %d (%s) is not embedded in this binary", applied.Version, applied.Name)
+		}
+		if m.Checksum != applied.Checksum {
+			return status, fmt.Errorf("migration %04d_%s was modified after being applied (checksum mismatch)", m.Version, m.Name)
+		}
+		appliedSet[applied.Version] = struct{}{}
+	}
+	for _, m := range r.migrations {
+		if _, ok := appliedSet[m.Version]; !ok {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+
+	return status, nil
+}
+
+func (r *Runner) ensureTable(ctx context.Context, conn *sql.Conn) error {
+	if _, err := conn.ExecContext(ctx, r.spec.createTable); err != nil {
+		return fmt.Errorf("failed to create %s: %w", r.spec.table, err)
+	}
+	return nil
+}
+
+// lockedConn reserva una conexión, toma el lock del runner y asegura la tabla de control.
+func (r *Runner) lockedConn(ctx context.Context) (*sql.Conn, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	if r.spec.lock != "" {
+		if _, err := conn.ExecContext(ctx, r.spec.lock); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+	}
+	if err := r.ensureTable(ctx, conn); err != nil {
+		r.release(conn)
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (r *Runner) release(conn *sql.Conn) {
+	if r.spec.unlock != "" {
+		_, _ = conn.ExecContext(context.Background(), r.spec.unlock)
+	}
+	conn.Close()
+}
+
+func (r *Runner) latestVersion() int {
+	if len(r.migrations) == 0 {
+		return 0
+	}
+	return r.migrations[len(r.migrations)-1].Version
+}
+
+// appliedAtArg retorna applied_at en el tipo de columna del dialecto.
+func (r *Runner) appliedAtArg() interface{} {
+	if r.dialect == DialectSQLite {
+		return r.now().UnixMilli()
+	}
+	return r.now()
+}
+
+func toTime(value interface{}) time.Time {
+	switch v := value.(type) {
+	case time.Time:
+		return v
+	case int64:
+		return time.UnixMilli(v)
+	default:
+		return time.Time{}
+	}
+}
@@ -0,0 +1,127 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func TestLoadOrdersVersionsAndStripsDownSection(t *testing.T) {
+	for _, dialect := range []Dialect{DialectPostgres, DialectSQLite} {
+		migrations, err := Load(dialect)
+		require.NoError(t, err, dialect)
+		require.NotEmpty(t, migrations, dialect)
+
+		for i, m := range migrations {
+			assert.Equal(t, i+1, m.Version, "%s: versions must be contiguous", dialect)
+			assert.NotEmpty(t, m.Checksum)
+			for _, line := range strings.Split(m.SQL, "\n") {
+				trimmed := strings.TrimSpace(line)
+				assert.NotEqual(t, "BEGIN;", trimmed, "%s %d", dialect, m.Version)
+				assert.NotEqual(t, "COMMIT;", trimmed, "%s %d", dialect, m.Version)
+			}
+		}
+	}
+
+	postgres, err := Load(DialectPostgres)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(postgres), 8)
+	i7 := postgres[7]
+	assert.Equal(t, "i7_risk_policy_history", i7.Name)
+	assert.Contains(t, i7.SQL, "CREATE TABLE IF NOT EXISTS echo.account_strategy_risk_policy_history")
+	assert.NotContains(t, i7.SQL, "DROP TABLE IF EXISTS echo.account_strategy_risk_policy_history")
+}
+
+func TestUpSectionWithoutMarkers(t *testing.T) {
+	sql := upSection("BEGIN;\nCREATE TABLE t (id INT);\nCOMMIT;\n")
+	assert.Equal(t, "CREATE TABLE t (id INT);\n", sql)
+}
+
+func TestParseFileName(t *testing.T) {
+	version, name, err := parseFileName("0003_i3_symbols.sql")
+	require.NoError(t, err)
+	assert.Equal(t, 3, version)
+	assert.Equal(t, "i3_symbols", name)
+
+	_, _, err = parseFileName("i3_symbols.sql")
+	assert.Error(t, err)
+	_, _, err = parseFileName("0003.sql")
+	assert.Error(t, err)
+}
+
+func openTestSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "echo.db"))
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestRunnerMigrateIsIdempotent(t *testing.T) {
+	ctx := context.Background()
+	runner, err := NewRunner(openTestSQLite(t), DialectSQLite)
+	require.NoError(t, err)
+
+	status, err := runner.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, status.CurrentVersion)
+	assert.Len(t, status.Pending, len(runner.Migrations()))
+
+	applied, err := runner.Migrate(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(runner.Migrations()))
+
+	applied, err = runner.Migrate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	status, err = runner.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, status.LatestVersion, status.CurrentVersion)
+	assert.Empty(t, status.Pending)
+}
+
+func TestRunnerRefusesNewerSchema(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	runner, err := NewRunner(db, DialectSQLite)
+	require.NoError(t, err)
+	_, err = runner.Migrate(ctx)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (999, 'future', 'x', 0)`)
+	require.NoError(t, err)
+
+	_, err = runner.Status(ctx)
+	assert.ErrorIs(t, err, ErrSchemaTooNew)
+	_, err = runner.Migrate(ctx)
+	assert.ErrorIs(t, err, ErrSchemaTooNew)
+}
+
+func TestRunnerRequiresBaselineForLegacySchema(t *testing.T) {
+	ctx := context.Background()
+	db := openTestSQLite(t)
+	_, err := db.ExecContext(ctx, `CREATE TABLE trades (trade_id TEXT PRIMARY KEY)`)
+	require.NoError(t, err)
+
+	runner, err := NewRunner(db, DialectSQLite)
+	require.NoError(t, err)
+
+	_, err = runner.Migrate(ctx)
+	assert.ErrorIs(t, err, ErrBaselineRequired)
+
+	recorded, err := runner.Baseline(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, recorded, 1)
+
+	status, err := runner.Status(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.CurrentVersion)
+}
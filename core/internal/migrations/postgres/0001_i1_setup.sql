@@ -7,7 +7,7 @@
 -- IP Postgres: 192.168.31.220
 -- 
 -- Uso:
---   psql -h 192.168.31.220 -U postgres -d echo < setup.sql
+--   Aplicada por Core al iniciar o con `echo-core-cli db migrate`.
 --
 -- Variables de control:
 --   DROP_IF_EXISTS: si es true, hace DROP SCHEMA CASCADE antes de crear
@@ -7,7 +7,7 @@
 -- RFC: RFC-004-iteracion-3-catalogo-simbolos.md
 -- 
 -- Uso:
---   psql -h <host> -U postgres -d echo < migrations/i3_symbols.sql
+--   Aplicada por Core al iniciar o con `echo-core-cli db migrate`.
 -- ============================================================================
 
 -- ============================================================================
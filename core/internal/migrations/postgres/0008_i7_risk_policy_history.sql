@@ -0,0 +1,99 @@
+-- Iteración 7: historial append-only de políticas de riesgo, activación programada
+-- y trazabilidad de la versión de política que dimensionó cada ejecución.
+
+-- +migrate Up
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS echo.account_strategy_risk_policy_history (
+	account_id      TEXT        NOT NULL,
+	strategy_id     TEXT        NOT NULL,
+	version         BIGINT      NOT NULL,
+	risk_type       TEXT        NOT NULL,
+	lot_size        DOUBLE PRECISION,
+	config          JSONB       NOT NULL DEFAULT '{}'::jsonb,
+	risk_currency   TEXT,
+	risk_amount     DOUBLE PRECISION,
+	effective_from  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	valid_until     TIMESTAMPTZ,
+	author          TEXT        NOT NULL,
+	reason          TEXT        NOT NULL DEFAULT '',
+	recorded_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+	PRIMARY KEY (account_id, strategy_id, version),
+	CONSTRAINT chk_risk_policy_history_window
+		CHECK (valid_until IS NULL OR valid_until > effective_from),
+	CONSTRAINT chk_risk_policy_history_fixed_risk
+		CHECK (
+			risk_type <> 'FIXED_RISK'
+			OR (
+				(config ? 'amount')
+				AND (config ? 'currency')
+			)
+		)
+);
+
+-- Resolución de la versión vigente: última effective_from <= NOW()
+CREATE INDEX IF NOT EXISTS idx_risk_policy_history_effective
+	ON echo.account_strategy_risk_policy_history (account_id, strategy_id, effective_from DESC, version DESC);
+
+COMMENT ON TABLE echo.account_strategy_risk_policy_history IS 'Historial append-only de políticas de riesgo (Iteración 7)';
+COMMENT ON COLUMN echo.account_strategy_risk_policy_history.effective_from IS 'Instante de activación; versiones futuras quedan programadas';
+
+-- Append-only: prohibir UPDATE/DELETE sobre el historial
+CREATE OR REPLACE FUNCTION echo.reject_risk_policy_history_mutation() RETURNS TRIGGER AS $$
+BEGIN
+	RAISE EXCEPTION 'echo.account_strategy_risk_policy_history es append-only (%)', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_risk_policy_history_immutable ON echo.account_strategy_risk_policy_history;
+CREATE TRIGGER trg_risk_policy_history_immutable
+	BEFORE UPDATE OR DELETE ON echo.account_strategy_risk_policy_history
+	FOR EACH ROW
+	EXECUTE FUNCTION echo.reject_risk_policy_history_mutation();
+
+-- Reutiliza la notificación existente para invalidar caché en Core
+DROP TRIGGER IF EXISTS trg_risk_policy_history_changed ON echo.account_strategy_risk_policy_history;
+CREATE TRIGGER trg_risk_policy_history_changed
+	AFTER INSERT ON echo.account_strategy_risk_policy_history
+	FOR EACH ROW
+	EXECUTE FUNCTION echo.notify_risk_policy_changed();
+
+-- Backfill: la fila actual de cada cuenta × estrategia pasa a ser la primera revisión
+INSERT INTO echo.account_strategy_risk_policy_history (
+	account_id, strategy_id, version, risk_type, lot_size, config,
+	risk_currency, risk_amount, effective_from, valid_until, author, reason
+)
+SELECT account_id, strategy_id, version, risk_type, lot_size, config,
+       risk_currency, risk_amount, updated_at, valid_until, 'migration:i7', 'backfill'
+FROM echo.account_strategy_risk_policy
+WHERE valid_until IS NULL OR valid_until > updated_at
+ON CONFLICT (account_id, strategy_id, version) DO NOTHING;
+
+-- Trazabilidad de la política aplicada en cada ejecución
+ALTER TABLE echo.executions
+	ADD COLUMN IF NOT EXISTS strategy_id TEXT,
+	ADD COLUMN IF NOT EXISTS risk_policy_type TEXT,
+	ADD COLUMN IF NOT EXISTS risk_policy_version BIGINT;
+
+CREATE INDEX IF NOT EXISTS idx_executions_risk_policy
+	ON echo.executions (slave_account_id, strategy_id, risk_policy_version)
+	WHERE risk_policy_version IS NOT NULL;
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP INDEX IF EXISTS echo.idx_executions_risk_policy;
+
+ALTER TABLE echo.executions
+	DROP COLUMN IF EXISTS strategy_id,
+	DROP COLUMN IF EXISTS risk_policy_type,
+	DROP COLUMN IF EXISTS risk_policy_version;
+
+DROP TRIGGER IF EXISTS trg_risk_policy_history_changed ON echo.account_strategy_risk_policy_history;
+DROP TRIGGER IF EXISTS trg_risk_policy_history_immutable ON echo.account_strategy_risk_policy_history;
+DROP FUNCTION IF EXISTS echo.reject_risk_policy_history_mutation();
+DROP TABLE IF EXISTS echo.account_strategy_risk_policy_history;
+
+COMMIT;
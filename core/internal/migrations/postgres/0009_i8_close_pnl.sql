@@ -0,0 +1,37 @@
+-- Iteración 8: P&L y volúmenes de cierre reportados por el slave.
+-- Expand phase: columnas nullable; cierres previos quedan con NULL (no reportado).
+
+-- +migrate Up
+BEGIN;
+
+ALTER TABLE echo.closes
+    ADD COLUMN IF NOT EXISTS profit DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS commission DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS swap DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS closed_volume DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS remaining_volume DOUBLE PRECISION;
+
+-- Ciclo de vida por trade×slave
+CREATE INDEX IF NOT EXISTS idx_closes_trade_slave
+    ON echo.closes(trade_id, slave_account_id, closed_at_ms);
+
+COMMENT ON COLUMN echo.closes.profit IS 'Profit bruto del volumen cerrado, moneda de la cuenta (Iteración 8)';
+COMMENT ON COLUMN echo.closes.commission IS 'Comisión con signo reportada por el slave (Iteración 8)';
+COMMENT ON COLUMN echo.closes.swap IS 'Swap con signo reportado por el slave (Iteración 8)';
+COMMENT ON COLUMN echo.closes.remaining_volume IS 'Lotes abiertos tras el cierre; > 0 = cierre parcial (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP INDEX IF EXISTS echo.idx_closes_trade_slave;
+
+ALTER TABLE echo.closes
+    DROP COLUMN IF EXISTS profit,
+    DROP COLUMN IF EXISTS commission,
+    DROP COLUMN IF EXISTS swap,
+    DROP COLUMN IF EXISTS closed_volume,
+    DROP COLUMN IF EXISTS remaining_volume;
+
+COMMIT;
@@ -0,0 +1,51 @@
+-- Iteración 8: outbox transaccional de comandos hacia Agents.
+-- Los comandos se insertan en la misma transacción que echo.trades y echo.dedupe;
+-- el dispatcher de Core los entrega cuando el Agent owner está conectado.
+
+-- +migrate Up
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS echo.command_outbox (
+    command_id          TEXT PRIMARY KEY,               -- UUID del comando
+    trade_id            TEXT NOT NULL,                  -- FK a trades
+    target_account_id   TEXT NOT NULL,                  -- Cuenta slave destino
+    command_type        TEXT NOT NULL,                  -- execute_order
+    payload             BYTEA NOT NULL,                 -- Comando protobuf serializado
+    status              TEXT NOT NULL DEFAULT 'PENDING',
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    last_agent_id       TEXT NOT NULL DEFAULT '',
+    created_at_ms       BIGINT NOT NULL,
+    dispatched_at_ms    BIGINT NOT NULL DEFAULT 0,
+    completed_at_ms     BIGINT NOT NULL DEFAULT 0,
+
+    CONSTRAINT chk_command_outbox_status
+        CHECK (status IN ('PENDING', 'DISPATCHED', 'ACKED', 'EXPIRED')),
+    CONSTRAINT fk_command_outbox_trade
+        FOREIGN KEY (trade_id)
+        REFERENCES echo.trades(trade_id)
+        ON DELETE CASCADE
+);
+
+-- Entregas pendientes (dispatcher)
+CREATE INDEX IF NOT EXISTS idx_command_outbox_deliverable
+    ON echo.command_outbox(created_at_ms)
+    WHERE status IN ('PENDING', 'DISPATCHED');
+
+-- Purga de comandos completados
+CREATE INDEX IF NOT EXISTS idx_command_outbox_completed
+    ON echo.command_outbox(completed_at_ms)
+    WHERE status IN ('ACKED', 'EXPIRED');
+
+CREATE INDEX IF NOT EXISTS idx_command_outbox_trade_id
+    ON echo.command_outbox(trade_id);
+
+COMMENT ON TABLE echo.command_outbox IS 'Outbox transaccional de comandos hacia Agents (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TABLE IF EXISTS echo.command_outbox;
+
+COMMIT;
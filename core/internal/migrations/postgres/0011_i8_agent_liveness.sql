@@ -0,0 +1,58 @@
+-- Iteración 8: liveness de Agents y cuentas según heartbeats.
+-- El monitor de Core escribe el último estado de cada Agent; echo-core-cli agents
+-- lo consulta sin conectarse al Core.
+
+-- +migrate Up
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS echo.agent_liveness (
+    agent_id                TEXT PRIMARY KEY,
+    status                  TEXT NOT NULL,
+    last_seen_at_ms         BIGINT NOT NULL,               -- Último heartbeat recibido
+    heartbeat_interval_ms   BIGINT NOT NULL DEFAULT 0,     -- Intervalo reportado por el Agent
+    pending_commands        INTEGER NOT NULL DEFAULT 0,    -- Store-and-forward del Agent
+    pending_bytes           BIGINT NOT NULL DEFAULT 0,
+    stale_since_ms          BIGINT NOT NULL DEFAULT 0,
+    updated_at_ms           BIGINT NOT NULL,
+
+    CONSTRAINT chk_agent_liveness_status
+        CHECK (status IN ('ALIVE', 'STALE', 'DISCONNECTED'))
+);
+
+CREATE TABLE IF NOT EXISTS echo.account_liveness (
+    agent_id            TEXT NOT NULL,
+    pipe_name           TEXT NOT NULL,
+    account_id          TEXT NOT NULL,
+    pipe_role           TEXT NOT NULL DEFAULT '',
+    status              TEXT NOT NULL,
+    codec               TEXT NOT NULL DEFAULT '',
+    last_seen_at_ms     BIGINT NOT NULL DEFAULT 0,       -- Último heartbeat con el EA conectado
+
+    PRIMARY KEY (agent_id, pipe_name),
+    CONSTRAINT chk_account_liveness_status
+        CHECK (status IN ('ALIVE', 'STALE', 'DISCONNECTED')),
+    CONSTRAINT fk_account_liveness_agent
+        FOREIGN KEY (agent_id)
+        REFERENCES echo.agent_liveness(agent_id)
+        ON DELETE CASCADE
+);
+
+-- Purga de Agents sin heartbeat
+CREATE INDEX IF NOT EXISTS idx_agent_liveness_last_seen
+    ON echo.agent_liveness(last_seen_at_ms);
+
+CREATE INDEX IF NOT EXISTS idx_account_liveness_account_id
+    ON echo.account_liveness(account_id);
+
+COMMENT ON TABLE echo.agent_liveness IS 'Último heartbeat de cada Agent (Iteración 8)';
+COMMENT ON TABLE echo.account_liveness IS 'Estado de los pipes de cuenta según el último heartbeat (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TABLE IF EXISTS echo.account_liveness;
+DROP TABLE IF EXISTS echo.agent_liveness;
+
+COMMIT;
@@ -0,0 +1,35 @@
+-- Iteración 8: ownership persistido de cuentas.
+-- El AccountRegistry del Core escribe el owner de cada cuenta y los conflictos
+-- (Agent en cuarentena); echo-core-cli lo consulta y pide takeovers forzados.
+
+-- +migrate Up
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS echo.account_ownership (
+    account_id          TEXT PRIMARY KEY,
+    agent_id            TEXT NOT NULL,
+    pipe_role           TEXT NOT NULL DEFAULT '',
+    status              TEXT NOT NULL,
+    registered_at_ms    BIGINT NOT NULL,                -- Alta del owner actual
+    updated_at_ms       BIGINT NOT NULL,
+    claimant_agent_id   TEXT NOT NULL DEFAULT '',       -- Agent en cuarentena ('' sin conflicto)
+    conflict_at_ms      BIGINT NOT NULL DEFAULT 0,
+    forced_agent_id     TEXT NOT NULL DEFAULT '',       -- Takeover pedido por operador
+
+    CONSTRAINT chk_account_ownership_status
+        CHECK (status IN ('ACTIVE', 'DETACHED'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_ownership_agent_id
+    ON echo.account_ownership(agent_id);
+
+COMMENT ON TABLE echo.account_ownership IS 'Agent owner de cada cuenta y conflictos de ownership (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TABLE IF EXISTS echo.account_ownership;
+
+COMMIT;
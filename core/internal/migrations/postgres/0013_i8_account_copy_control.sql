@@ -0,0 +1,25 @@
+-- Iteración 8: habilitación de copia por cuenta.
+-- AdminService (SetCopyEnabled) la escribe; el Core la carga al arrancar.
+-- Una cuenta sin fila copia normalmente.
+
+-- +migrate Up
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS echo.account_copy_control (
+    account_id      TEXT PRIMARY KEY,
+    copy_enabled    BOOLEAN NOT NULL,
+    reason          TEXT NOT NULL DEFAULT '',
+    updated_by      TEXT NOT NULL DEFAULT '',       -- Identidad autenticada del operador
+    updated_at_ms   BIGINT NOT NULL
+);
+
+COMMENT ON TABLE echo.account_copy_control IS 'Copia habilitada/deshabilitada por cuenta (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TABLE IF EXISTS echo.account_copy_control;
+
+COMMIT;
@@ -0,0 +1,32 @@
+-- Iteración 8: webhooks del notifier que agotaron sus reintentos.
+-- El payload queda renderizado para poder reenviarlo manualmente.
+
+-- +migrate Up
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS echo.notification_dead_letters (
+    id              TEXT PRIMARY KEY,               -- UUIDv7
+    rule            TEXT NOT NULL,                  -- core/notifier/rules/<rule>
+    url             TEXT NOT NULL,
+    event_type      TEXT NOT NULL,
+    event_seq       BIGINT NOT NULL DEFAULT 0,      -- CoreEvent.seq
+    payload         TEXT NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_status     INTEGER NOT NULL DEFAULT 0,     -- Último HTTP status (0 = error de red)
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at_ms   BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_dead_letters_created
+    ON echo.notification_dead_letters(created_at_ms DESC);
+
+COMMENT ON TABLE echo.notification_dead_letters IS 'Webhooks no entregados del notifier (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TABLE IF EXISTS echo.notification_dead_letters;
+
+COMMIT;
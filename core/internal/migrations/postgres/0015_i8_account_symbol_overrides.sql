@@ -0,0 +1,27 @@
+-- Iteración 8: overrides manuales de mapeo de símbolos por cuenta.
+-- echo-core-cli symbols pin/unpin los escribe; el Core los aplica sobre cada
+-- AccountSymbolsReport, por lo que sobreviven a los reportes del EA.
+
+-- +migrate Up
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS echo.account_symbol_overrides (
+    account_id        TEXT NOT NULL,
+    canonical_symbol  TEXT NOT NULL,
+    broker_symbol     TEXT NOT NULL,
+    reason            TEXT NOT NULL DEFAULT '',
+    updated_by        TEXT NOT NULL DEFAULT '',     -- Identidad del operador
+    updated_at_ms     BIGINT NOT NULL,
+    PRIMARY KEY (account_id, canonical_symbol)
+);
+
+COMMENT ON TABLE echo.account_symbol_overrides IS 'Mapeo canonical → broker_symbol fijado por un operador (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TABLE IF EXISTS echo.account_symbol_overrides;
+
+COMMIT;
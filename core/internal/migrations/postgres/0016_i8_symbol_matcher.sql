@@ -0,0 +1,44 @@
+-- Iteración 8: matcher de símbolos por reglas en el Core.
+-- account_symbol_map registra origen y confianza de cada mapeo y el fingerprint de
+-- divisas; los mapeos previos quedan como reportados por el EA (confianza 1).
+-- account_symbol_proposals guarda los matches por debajo del umbral de auto-aceptación.
+
+-- +migrate Up
+BEGIN;
+
+ALTER TABLE echo.account_symbol_map
+    ADD COLUMN IF NOT EXISTS base_currency TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS profit_currency TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS source TEXT NOT NULL DEFAULT 'ea_report',
+    ADD COLUMN IF NOT EXISTS confidence DOUBLE PRECISION NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN echo.account_symbol_map.source IS 'Origen del mapeo: ea_report | override | matcher (Iteración 8)';
+COMMENT ON COLUMN echo.account_symbol_map.confidence IS 'Confianza del mapeo [0, 1]; < 1 solo para source = matcher (Iteración 8)';
+
+CREATE TABLE IF NOT EXISTS echo.account_symbol_proposals (
+    account_id        TEXT NOT NULL,
+    broker_symbol     TEXT NOT NULL,
+    canonical_symbol  TEXT NOT NULL,
+    confidence        DOUBLE PRECISION NOT NULL,
+    rule              TEXT NOT NULL,                -- exact | strip | alias
+    checks            JSONB NOT NULL DEFAULT '[]',  -- Resultado del fingerprint
+    proposed_at_ms    BIGINT NOT NULL,
+    PRIMARY KEY (account_id, broker_symbol)
+);
+
+COMMENT ON TABLE echo.account_symbol_proposals IS 'Mapeos sugeridos por el matcher pendientes de un operador (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TABLE IF EXISTS echo.account_symbol_proposals;
+
+ALTER TABLE echo.account_symbol_map
+    DROP COLUMN IF EXISTS base_currency,
+    DROP COLUMN IF EXISTS profit_currency,
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS confidence;
+
+COMMIT;
@@ -0,0 +1,32 @@
+-- Iteración 8: cambios de SL/TP del master en el ciclo de vida del trade.
+-- Un registro por TradeModify recibido; los niveles NULL no fueron reportados.
+
+-- +migrate Up
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS echo.trade_modifies (
+    modify_id         TEXT PRIMARY KEY,                -- UUID generado por el Core
+    trade_id          TEXT NOT NULL,                   -- FK a trades
+    master_ticket     INTEGER NOT NULL,                -- Ticket modificado en el master
+    new_stop_loss     DOUBLE PRECISION,                -- NULL = sin cambio reportado
+    new_take_profit   DOUBLE PRECISION,                -- NULL = sin cambio reportado
+    modified_at_ms    BIGINT NOT NULL,                 -- timestamp_ms del TradeModify
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT fk_trade_modifies_trade
+        FOREIGN KEY (trade_id) REFERENCES echo.trades(trade_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_trade_modifies_trade
+    ON echo.trade_modifies(trade_id, modified_at_ms);
+
+COMMENT ON TABLE echo.trade_modifies IS 'Cambios de SL/TP reportados por el master (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TABLE IF EXISTS echo.trade_modifies;
+
+COMMIT;
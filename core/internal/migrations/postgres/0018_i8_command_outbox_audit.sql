@@ -0,0 +1,33 @@
+-- Iteración 8: datos de auditoría del ExecuteOrder en el outbox.
+-- Tras un reinicio el Core reconstruye el CommandContext desde el outbox: estrategia,
+-- política de riesgo, símbolo canónico y precio del master (slippage) viajan en la fila.
+-- Comandos previos quedan con defaults (sin política ni referencia de slippage).
+
+-- +migrate Up
+BEGIN;
+
+ALTER TABLE echo.command_outbox
+    ADD COLUMN IF NOT EXISTS strategy_id TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS risk_policy_type TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS risk_policy_version BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS symbol TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS reference_price DOUBLE PRECISION NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS point DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN echo.command_outbox.risk_policy_version IS 'Versión de la política que dimensionó la orden; 0 = sin política (Iteración 8)';
+COMMENT ON COLUMN echo.command_outbox.reference_price IS 'Precio del master para slippage; 0 = sin referencia (Iteración 8)';
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+ALTER TABLE echo.command_outbox
+    DROP COLUMN IF EXISTS strategy_id,
+    DROP COLUMN IF EXISTS risk_policy_type,
+    DROP COLUMN IF EXISTS risk_policy_version,
+    DROP COLUMN IF EXISTS symbol,
+    DROP COLUMN IF EXISTS reference_price,
+    DROP COLUMN IF EXISTS point;
+
+COMMIT;
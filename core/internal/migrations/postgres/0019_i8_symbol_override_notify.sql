@@ -0,0 +1,32 @@
+-- Iteración 8: notificar cambios de overrides de símbolos al Core en ejecución.
+-- echo-core-cli symbols pin/unpin escribe desde otro proceso; el Core invalida el
+-- caché de mapeos de la cuenta al recibir echo_symbol_mapping_updated.
+
+-- +migrate Up
+BEGIN;
+
+CREATE OR REPLACE FUNCTION echo.notify_symbol_override_changed() RETURNS TRIGGER AS $$
+BEGIN
+	PERFORM pg_notify('echo_symbol_mapping_updated', COALESCE(NEW.account_id, OLD.account_id, ''));
+	IF TG_OP = 'DELETE' THEN
+		RETURN OLD;
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_symbol_override_changed ON echo.account_symbol_overrides;
+CREATE TRIGGER trg_symbol_override_changed
+	AFTER INSERT OR UPDATE OR DELETE ON echo.account_symbol_overrides
+	FOR EACH ROW
+	EXECUTE FUNCTION echo.notify_symbol_override_changed();
+
+COMMIT;
+
+-- +migrate Down
+BEGIN;
+
+DROP TRIGGER IF EXISTS trg_symbol_override_changed ON echo.account_symbol_overrides;
+DROP FUNCTION IF EXISTS echo.notify_symbol_override_changed();
+
+COMMIT;
@@ -0,0 +1,262 @@
+-- ============================================================================
+-- Echo - SQLite Schema (despliegues single-host)
+-- ============================================================================
+-- Port de las migraciones postgres 0001..0008 (i1, i3..i7).
+--
+-- Diferencias con PostgreSQL:
+--   - Sin schema echo: las tablas viven en el archivo de la base.
+--   - Timestamps como INTEGER (Unix ms UTC) asignados por Core.
+--   - JSONB → TEXT con JSON validado por CHECK (json_valid).
+--   - ENUMs → TEXT con CHECK.
+--   - LISTEN/NOTIFY se reemplaza por notificaciones en proceso (repository.ChangeNotifier).
+--   - cleanup_dedupe_ttl() se ejecuta como DELETE desde Core.
+-- ============================================================================
+
+-- ============================================================================
+-- TABLA: trades (i1)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS trades (
+    trade_id            TEXT PRIMARY KEY,
+    source_master_id    TEXT NOT NULL,
+    master_account_id   TEXT NOT NULL,
+    master_ticket       INTEGER NOT NULL,
+    magic_number        INTEGER NOT NULL,
+    symbol              TEXT NOT NULL,
+    side                TEXT NOT NULL CHECK (side IN ('BUY', 'SELL')),
+    lot_size            REAL NOT NULL,
+    price               REAL NOT NULL,
+    stop_loss           REAL,
+    take_profit         REAL,
+    comment             TEXT,
+    status              TEXT NOT NULL DEFAULT 'PENDING'
+                        CHECK (status IN ('PENDING', 'SENT', 'FILLED', 'REJECTED', 'CANCELLED')),
+    attempt             INTEGER NOT NULL DEFAULT 0,
+    opened_at_ms        INTEGER NOT NULL,
+    created_at          INTEGER NOT NULL,
+    updated_at          INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_trades_master_account ON trades(master_account_id);
+CREATE INDEX IF NOT EXISTS idx_trades_master_ticket ON trades(master_ticket);
+CREATE INDEX IF NOT EXISTS idx_trades_magic_number ON trades(magic_number);
+CREATE INDEX IF NOT EXISTS idx_trades_symbol ON trades(symbol);
+CREATE INDEX IF NOT EXISTS idx_trades_status ON trades(status);
+CREATE INDEX IF NOT EXISTS idx_trades_created_at ON trades(created_at DESC);
+
+-- ============================================================================
+-- TABLA: executions (i1 + i7)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS executions (
+    execution_id        TEXT PRIMARY KEY,
+    trade_id            TEXT NOT NULL REFERENCES trades(trade_id) ON DELETE CASCADE,
+    slave_account_id    TEXT NOT NULL,
+    agent_id            TEXT NOT NULL,
+    slave_ticket        INTEGER NOT NULL,
+    executed_price      REAL,
+    success             INTEGER NOT NULL CHECK (success IN (0, 1)),
+    error_code          TEXT NOT NULL DEFAULT 'NONE',
+    error_message       TEXT NOT NULL DEFAULT '',
+    timestamps_ms       TEXT NOT NULL CHECK (json_valid(timestamps_ms)),
+    strategy_id         TEXT,
+    risk_policy_type    TEXT,
+    risk_policy_version INTEGER,
+    created_at          INTEGER NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ux_execution_trade_slave_ticket
+    ON executions (trade_id, slave_account_id, slave_ticket)
+    WHERE slave_ticket != 0;
+
+CREATE INDEX IF NOT EXISTS idx_executions_trade_id ON executions(trade_id);
+CREATE INDEX IF NOT EXISTS idx_executions_slave_account ON executions(slave_account_id);
+CREATE INDEX IF NOT EXISTS idx_executions_success ON executions(success);
+CREATE INDEX IF NOT EXISTS idx_executions_created_at ON executions(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_executions_risk_policy
+    ON executions (slave_account_id, strategy_id, risk_policy_version)
+    WHERE risk_policy_version IS NOT NULL;
+
+-- ============================================================================
+-- TABLA: dedupe (i1)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS dedupe (
+    trade_id            TEXT PRIMARY KEY,
+    status              TEXT NOT NULL
+                        CHECK (status IN ('PENDING', 'SENT', 'FILLED', 'REJECTED', 'CANCELLED')),
+    created_at          INTEGER NOT NULL,
+    updated_at          INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_dedupe_updated_at ON dedupe(updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_dedupe_status ON dedupe(status);
+
+-- ============================================================================
+-- TABLA: closes (i1)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS closes (
+    close_id            TEXT PRIMARY KEY,
+    trade_id            TEXT NOT NULL REFERENCES trades(trade_id) ON DELETE CASCADE,
+    slave_account_id    TEXT NOT NULL,
+    slave_ticket        INTEGER NOT NULL,
+    close_price         REAL,
+    success             INTEGER NOT NULL CHECK (success IN (0, 1)),
+    error_code          TEXT NOT NULL DEFAULT 'NONE',
+    error_message       TEXT NOT NULL DEFAULT '',
+    closed_at_ms        INTEGER NOT NULL,
+    created_at          INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_closes_trade_id ON closes(trade_id);
+CREATE INDEX IF NOT EXISTS idx_closes_slave_account ON closes(slave_account_id);
+CREATE INDEX IF NOT EXISTS idx_closes_slave_ticket ON closes(slave_ticket);
+CREATE INDEX IF NOT EXISTS idx_closes_created_at ON closes(created_at DESC);
+
+-- ============================================================================
+-- TABLA: account_symbol_map (i3)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS account_symbol_map (
+    account_id       TEXT NOT NULL,
+    canonical_symbol TEXT NOT NULL,
+    broker_symbol    TEXT NOT NULL,
+    digits           INTEGER,
+    point            REAL,
+    tick_size        REAL,
+    min_lot          REAL,
+    max_lot          REAL,
+    lot_step         REAL,
+    stop_level       INTEGER,
+    contract_size    REAL,
+    reported_at_ms   INTEGER NOT NULL DEFAULT 0,
+    updated_at       INTEGER NOT NULL,
+    PRIMARY KEY (account_id, canonical_symbol)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_symbol_map_canonical ON account_symbol_map(canonical_symbol);
+CREATE INDEX IF NOT EXISTS idx_account_symbol_map_broker ON account_symbol_map(account_id, broker_symbol);
+
+-- ============================================================================
+-- TABLAS: account_symbol_spec / symbol_quote_latest (i3 + i4)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS account_symbol_spec (
+    account_id        TEXT    NOT NULL,
+    canonical_symbol  TEXT    NOT NULL,
+    broker_symbol     TEXT    NOT NULL,
+    payload           TEXT    NOT NULL CHECK (json_valid(payload)),
+    reported_at_ms    INTEGER NOT NULL,
+    updated_at        INTEGER NOT NULL,
+    PRIMARY KEY (account_id, canonical_symbol)
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_symbol_spec_reported_at
+    ON account_symbol_spec (account_id, reported_at_ms DESC);
+CREATE INDEX IF NOT EXISTS idx_account_symbol_spec_updated_at
+    ON account_symbol_spec (updated_at DESC);
+
+CREATE TABLE IF NOT EXISTS symbol_quote_latest (
+    account_id       TEXT    NOT NULL,
+    canonical_symbol TEXT    NOT NULL,
+    broker_symbol    TEXT    NOT NULL,
+    bid              REAL    NOT NULL,
+    ask              REAL    NOT NULL,
+    spread_points    REAL    NOT NULL,
+    timestamp_ms     INTEGER NOT NULL,
+    updated_at       INTEGER NOT NULL,
+    PRIMARY KEY (account_id, canonical_symbol)
+);
+
+CREATE INDEX IF NOT EXISTS idx_symbol_quote_latest_timestamp
+    ON symbol_quote_latest (timestamp_ms DESC);
+
+-- ============================================================================
+-- TABLA: account_strategy_risk_policy (i4 + i6, legacy)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS account_strategy_risk_policy (
+    account_id    TEXT    NOT NULL,
+    strategy_id   TEXT    NOT NULL,
+    risk_type     TEXT    NOT NULL,
+    lot_size      REAL,
+    config        TEXT    NOT NULL DEFAULT '{}' CHECK (json_valid(config)),
+    risk_currency TEXT,
+    risk_amount   REAL,
+    version       INTEGER NOT NULL DEFAULT 1,
+    updated_at    INTEGER NOT NULL,
+    valid_until   INTEGER,
+    PRIMARY KEY (account_id, strategy_id),
+    CONSTRAINT chk_fixed_risk_config CHECK (
+        risk_type <> 'FIXED_RISK'
+        OR (json_type(config, '$.amount') IS NOT NULL AND json_type(config, '$.currency') IS NOT NULL)
+    )
+);
+
+-- ============================================================================
+-- TABLA: account_strategy_risk_policy_history (i7, append-only)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS account_strategy_risk_policy_history (
+    account_id      TEXT    NOT NULL,
+    strategy_id     TEXT    NOT NULL,
+    version         INTEGER NOT NULL,
+    risk_type       TEXT    NOT NULL,
+    lot_size        REAL,
+    config          TEXT    NOT NULL DEFAULT '{}' CHECK (json_valid(config)),
+    risk_currency   TEXT,
+    risk_amount     REAL,
+    effective_from  INTEGER NOT NULL,
+    valid_until     INTEGER,
+    author          TEXT    NOT NULL,
+    reason          TEXT    NOT NULL DEFAULT '',
+    recorded_at     INTEGER NOT NULL,
+    PRIMARY KEY (account_id, strategy_id, version),
+    CONSTRAINT chk_risk_policy_history_window
+        CHECK (valid_until IS NULL OR valid_until > effective_from),
+    CONSTRAINT chk_risk_policy_history_fixed_risk CHECK (
+        risk_type <> 'FIXED_RISK'
+        OR (json_type(config, '$.amount') IS NOT NULL AND json_type(config, '$.currency') IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_risk_policy_history_effective
+    ON account_strategy_risk_policy_history (account_id, strategy_id, effective_from DESC, version DESC);
+
+CREATE TRIGGER IF NOT EXISTS trg_risk_policy_history_no_update
+    BEFORE UPDATE ON account_strategy_risk_policy_history
+BEGIN
+    SELECT RAISE(ABORT, 'account_strategy_risk_policy_history es append-only (UPDATE)');
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_risk_policy_history_no_delete
+    BEFORE DELETE ON account_strategy_risk_policy_history
+BEGIN
+    SELECT RAISE(ABORT, 'account_strategy_risk_policy_history es append-only (DELETE)');
+END;
+
+-- ============================================================================
+-- TABLAS: handshake (i5)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS account_symbol_registration_eval (
+    evaluation_id     TEXT PRIMARY KEY,
+    account_id        TEXT NOT NULL,
+    pipe_role         TEXT NOT NULL,
+    status            TEXT NOT NULL,
+    protocol_version  INTEGER NOT NULL,
+    client_semver     TEXT NOT NULL,
+    global_errors     TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(global_errors)),
+    global_warnings   TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(global_warnings)),
+    required_features TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(required_features)),
+    optional_features TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(optional_features)),
+    capabilities      TEXT NOT NULL DEFAULT '{}' CHECK (json_valid(capabilities)),
+    evaluated_at      INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_symbol_registration_eval_account
+    ON account_symbol_registration_eval (account_id, evaluated_at DESC);
+
+CREATE TABLE IF NOT EXISTS account_symbol_registration (
+    evaluation_id    TEXT NOT NULL REFERENCES account_symbol_registration_eval(evaluation_id) ON DELETE CASCADE,
+    canonical_symbol TEXT NOT NULL,
+    broker_symbol    TEXT NOT NULL,
+    status           TEXT NOT NULL,
+    warnings         TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(warnings)),
+    errors           TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(errors)),
+    spec_age_ms      INTEGER NOT NULL,
+    evaluated_at     INTEGER NOT NULL,
+    PRIMARY KEY (evaluation_id, canonical_symbol)
+);
@@ -0,0 +1,10 @@
+-- Iteración 8: P&L y volúmenes de cierre reportados por el slave (port de postgres 0009).
+
+-- +migrate Up
+ALTER TABLE closes ADD COLUMN profit REAL;
+ALTER TABLE closes ADD COLUMN commission REAL;
+ALTER TABLE closes ADD COLUMN swap REAL;
+ALTER TABLE closes ADD COLUMN closed_volume REAL;
+ALTER TABLE closes ADD COLUMN remaining_volume REAL;
+
+CREATE INDEX IF NOT EXISTS idx_closes_trade_slave ON closes(trade_id, slave_account_id, closed_at_ms);
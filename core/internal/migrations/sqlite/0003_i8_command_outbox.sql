@@ -0,0 +1,23 @@
+-- Iteración 8: outbox transaccional de comandos hacia Agents (port de postgres 0010).
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS command_outbox (
+    command_id          TEXT PRIMARY KEY,
+    trade_id            TEXT NOT NULL REFERENCES trades(trade_id) ON DELETE CASCADE,
+    target_account_id   TEXT NOT NULL,
+    command_type        TEXT NOT NULL,
+    payload             BLOB NOT NULL,
+    status              TEXT NOT NULL DEFAULT 'PENDING'
+                        CHECK (status IN ('PENDING', 'DISPATCHED', 'ACKED', 'EXPIRED')),
+    attempts            INTEGER NOT NULL DEFAULT 0,
+    last_agent_id       TEXT NOT NULL DEFAULT '',
+    created_at_ms       INTEGER NOT NULL,
+    dispatched_at_ms    INTEGER NOT NULL DEFAULT 0,
+    completed_at_ms     INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_command_outbox_deliverable
+    ON command_outbox(created_at_ms) WHERE status IN ('PENDING', 'DISPATCHED');
+CREATE INDEX IF NOT EXISTS idx_command_outbox_completed
+    ON command_outbox(completed_at_ms) WHERE status IN ('ACKED', 'EXPIRED');
+CREATE INDEX IF NOT EXISTS idx_command_outbox_trade_id ON command_outbox(trade_id);
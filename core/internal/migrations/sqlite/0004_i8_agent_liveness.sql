@@ -0,0 +1,27 @@
+-- Iteración 8: liveness de Agents y cuentas según heartbeats (port de postgres 0011).
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS agent_liveness (
+    agent_id                TEXT PRIMARY KEY,
+    status                  TEXT NOT NULL CHECK (status IN ('ALIVE', 'STALE', 'DISCONNECTED')),
+    last_seen_at_ms         INTEGER NOT NULL,
+    heartbeat_interval_ms   INTEGER NOT NULL DEFAULT 0,
+    pending_commands        INTEGER NOT NULL DEFAULT 0,
+    pending_bytes           INTEGER NOT NULL DEFAULT 0,
+    stale_since_ms          INTEGER NOT NULL DEFAULT 0,
+    updated_at_ms           INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS account_liveness (
+    agent_id            TEXT NOT NULL REFERENCES agent_liveness(agent_id) ON DELETE CASCADE,
+    pipe_name           TEXT NOT NULL,
+    account_id          TEXT NOT NULL,
+    pipe_role           TEXT NOT NULL DEFAULT '',
+    status              TEXT NOT NULL CHECK (status IN ('ALIVE', 'STALE', 'DISCONNECTED')),
+    codec               TEXT NOT NULL DEFAULT '',
+    last_seen_at_ms     INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (agent_id, pipe_name)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_liveness_last_seen ON agent_liveness(last_seen_at_ms);
+CREATE INDEX IF NOT EXISTS idx_account_liveness_account_id ON account_liveness(account_id);
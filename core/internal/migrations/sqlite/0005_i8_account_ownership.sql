@@ -0,0 +1,16 @@
+-- Iteración 8: ownership persistido de cuentas (port de postgres 0012).
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS account_ownership (
+    account_id          TEXT PRIMARY KEY,
+    agent_id            TEXT NOT NULL,
+    pipe_role           TEXT NOT NULL DEFAULT '',
+    status              TEXT NOT NULL CHECK (status IN ('ACTIVE', 'DETACHED')),
+    registered_at_ms    INTEGER NOT NULL,
+    updated_at_ms       INTEGER NOT NULL,
+    claimant_agent_id   TEXT NOT NULL DEFAULT '',
+    conflict_at_ms      INTEGER NOT NULL DEFAULT 0,
+    forced_agent_id     TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_ownership_agent_id ON account_ownership(agent_id);
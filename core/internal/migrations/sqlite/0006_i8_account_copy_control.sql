@@ -0,0 +1,10 @@
+-- Iteración 8: habilitación de copia por cuenta (port de postgres 0013).
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS account_copy_control (
+    account_id      TEXT PRIMARY KEY,
+    copy_enabled    INTEGER NOT NULL CHECK (copy_enabled IN (0, 1)),
+    reason          TEXT NOT NULL DEFAULT '',
+    updated_by      TEXT NOT NULL DEFAULT '',
+    updated_at_ms   INTEGER NOT NULL
+);
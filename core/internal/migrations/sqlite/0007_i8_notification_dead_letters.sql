@@ -0,0 +1,17 @@
+-- Iteración 8: webhooks del notifier que agotaron sus reintentos (port de postgres 0014).
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS notification_dead_letters (
+    id              TEXT PRIMARY KEY,
+    rule            TEXT NOT NULL,
+    url             TEXT NOT NULL,
+    event_type      TEXT NOT NULL,
+    event_seq       INTEGER NOT NULL DEFAULT 0,
+    payload         TEXT NOT NULL,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    last_status     INTEGER NOT NULL DEFAULT 0,
+    last_error      TEXT NOT NULL DEFAULT '',
+    created_at_ms   INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_dead_letters_created ON notification_dead_letters(created_at_ms DESC);
@@ -0,0 +1,12 @@
+-- Iteración 8: overrides manuales de mapeo de símbolos (port de postgres 0015).
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS account_symbol_overrides (
+    account_id        TEXT NOT NULL,
+    canonical_symbol  TEXT NOT NULL,
+    broker_symbol     TEXT NOT NULL,
+    reason            TEXT NOT NULL DEFAULT '',
+    updated_by        TEXT NOT NULL DEFAULT '',
+    updated_at_ms     INTEGER NOT NULL,
+    PRIMARY KEY (account_id, canonical_symbol)
+);
@@ -0,0 +1,18 @@
+-- Iteración 8: matcher de símbolos por reglas en el Core (port de postgres 0016).
+
+-- +migrate Up
+ALTER TABLE account_symbol_map ADD COLUMN base_currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE account_symbol_map ADD COLUMN profit_currency TEXT NOT NULL DEFAULT '';
+ALTER TABLE account_symbol_map ADD COLUMN source TEXT NOT NULL DEFAULT 'ea_report';
+ALTER TABLE account_symbol_map ADD COLUMN confidence REAL NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS account_symbol_proposals (
+    account_id        TEXT NOT NULL,
+    broker_symbol     TEXT NOT NULL,
+    canonical_symbol  TEXT NOT NULL,
+    confidence        REAL NOT NULL,
+    rule              TEXT NOT NULL,
+    checks            TEXT NOT NULL DEFAULT '[]' CHECK (json_valid(checks)),
+    proposed_at_ms    INTEGER NOT NULL,
+    PRIMARY KEY (account_id, broker_symbol)
+);
@@ -0,0 +1,14 @@
+-- Iteración 8: cambios de SL/TP del master en el ciclo de vida del trade (port de postgres 0017).
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS trade_modifies (
+    modify_id         TEXT PRIMARY KEY,
+    trade_id          TEXT NOT NULL REFERENCES trades(trade_id) ON DELETE CASCADE,
+    master_ticket     INTEGER NOT NULL,
+    new_stop_loss     REAL,
+    new_take_profit   REAL,
+    modified_at_ms    INTEGER NOT NULL,
+    created_at        INTEGER NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_trade_modifies_trade ON trade_modifies(trade_id, modified_at_ms);
@@ -0,0 +1,9 @@
+-- Iteración 8: datos de auditoría del ExecuteOrder en el outbox (port de postgres 0018).
+
+-- +migrate Up
+ALTER TABLE command_outbox ADD COLUMN strategy_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE command_outbox ADD COLUMN risk_policy_type TEXT NOT NULL DEFAULT '';
+ALTER TABLE command_outbox ADD COLUMN risk_policy_version INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE command_outbox ADD COLUMN symbol TEXT NOT NULL DEFAULT '';
+ALTER TABLE command_outbox ADD COLUMN reference_price REAL NOT NULL DEFAULT 0;
+ALTER TABLE command_outbox ADD COLUMN point REAL NOT NULL DEFAULT 0;
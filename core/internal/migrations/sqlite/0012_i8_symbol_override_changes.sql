@@ -0,0 +1,30 @@
+-- Iteración 8: registro de cambios de overrides de símbolos (port de postgres 0019).
+-- Reemplaza cross-proceso a echo_symbol_mapping_updated: el Core lee las filas nuevas
+-- (rowid monótono) e invalida el caché de mapeos de la cuenta.
+
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS account_symbol_override_changes (
+    account_id        TEXT NOT NULL,
+    changed_at_ms     INTEGER NOT NULL
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_symbol_override_inserted
+    AFTER INSERT ON account_symbol_overrides
+BEGIN
+    INSERT INTO account_symbol_override_changes (account_id, changed_at_ms)
+    VALUES (NEW.account_id, CAST((julianday('now') - 2440587.5) * 86400000 AS INTEGER));
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_symbol_override_updated
+    AFTER UPDATE ON account_symbol_overrides
+BEGIN
+    INSERT INTO account_symbol_override_changes (account_id, changed_at_ms)
+    VALUES (NEW.account_id, CAST((julianday('now') - 2440587.5) * 86400000 AS INTEGER));
+END;
+
+CREATE TRIGGER IF NOT EXISTS trg_symbol_override_deleted
+    AFTER DELETE ON account_symbol_overrides
+BEGIN
+    INSERT INTO account_symbol_override_changes (account_id, changed_at_ms)
+    VALUES (OLD.account_id, CAST((julianday('now') - 2440587.5) * 86400000 AS INTEGER));
+END;
@@ -0,0 +1,612 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"github.com/xKoRx/echo/sdk/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// notifierMaxResponseBytes cuerpo de respuesta que se lee (y descarta) por intento.
+const notifierMaxResponseBytes = 64 << 10
+
+// notifier envía webhooks cuando un CoreEvent coincide con una regla (i8).
+//
+// Consume el eventHub como un suscriptor más de WatchEvents, así que no agrega
+// trabajo al Router. Cada regla tiene su rate limit (token bucket por minuto);
+// los envíos se reintentan con backoff exponencial en 5xx, 429 y errores de red.
+// Lo que no se entrega (reintentos agotados, 4xx, cola llena, shutdown) queda en
+// NotificationDeadLetterRepository con el payload ya renderizado. Si el notifier se
+// atrasa y el eventHub descarta eventos, un dead letter "events_dropped" registra
+// cuántos y su rango de seq.
+//
+// Los circuit breakers todavía no existen en el Core: cuando publiquen su
+// CoreEvent alcanza con declarar la regla correspondiente.
+type notifier struct {
+	config      NotifierConfig
+	rules       []*notifierRule
+	hub         *eventHub
+	repo        domain.NotificationDeadLetterRepository
+	client      *http.Client
+	telemetry   *telemetry.Client
+	echoMetrics *metricbundle.EchoMetrics
+
+	jobs chan *notification
+	sub  *eventSubscription
+
+	now    func() time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// notification un webhook pendiente de entregar a un URL.
+type notification struct {
+	rule      *notifierRule
+	url       string
+	eventType string
+	eventSeq  uint64
+	payload   []byte
+}
+
+func newNotifier(
+	parentCtx context.Context,
+	config NotifierConfig,
+	hub *eventHub,
+	repo domain.NotificationDeadLetterRepository,
+	telemetryClient *telemetry.Client,
+	echoMetrics *metricbundle.EchoMetrics,
+) (*notifier, error) {
+	rules := make([]*notifierRule, 0, len(config.Rules))
+	for _, rule := range config.Rules {
+		compiled, err := compileNotifierRule(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid notifier rule %s: %w", rule.Name, err)
+		}
+		rules = append(rules, compiled)
+	}
+
+	ctx, cancel := context.WithCancel(parentCtx)
+	return &notifier{
+		config:      config,
+		rules:       rules,
+		hub:         hub,
+		repo:        repo,
+		client:      &http.Client{Timeout: config.Timeout},
+		telemetry:   telemetryClient,
+		echoMetrics: echoMetrics,
+		jobs:        make(chan *notification, config.QueueSize),
+		now:         time.Now,
+		ctx:         ctx,
+		cancel:      cancel,
+	}, nil
+}
+
+// Start se suscribe a los tipos de evento de las reglas y arranca los workers.
+func (n *notifier) Start() error {
+	if len(n.rules) == 0 {
+		n.telemetry.Warn(n.ctx, "Notifier enabled without rules (i8)")
+		return nil
+	}
+
+	req := &pb.WatchEventsRequest{}
+	seen := make(map[pb.CoreEventType]struct{})
+	for _, rule := range n.rules {
+		if _, ok := seen[rule.eventType]; !ok {
+			seen[rule.eventType] = struct{}{}
+			req.Types = append(req.Types, rule.eventType)
+		}
+	}
+	sub, err := n.hub.Subscribe(req)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe notifier to events: %w", err)
+	}
+	n.sub = sub
+
+	n.wg.Add(1)
+	go n.dispatchLoop()
+	for i := 0; i < n.config.Workers; i++ {
+		n.wg.Add(1)
+		go n.worker()
+	}
+
+	n.telemetry.Info(n.ctx, "Notifier started (i8)",
+		attribute.Int("rules", len(n.rules)),
+		attribute.Int("workers", n.config.Workers),
+		attribute.Int("max_attempts", n.config.MaxAttempts),
+	)
+	return nil
+}
+
+// Stop corta la suscripción y los envíos en curso. Lo pendiente queda como dead letter.
+func (n *notifier) Stop() {
+	n.cancel()
+	if n.sub != nil {
+		n.sub.Close()
+	}
+	n.wg.Wait()
+
+	for {
+		select {
+		case job := <-n.jobs:
+			n.deadLetter(job, 0, 0, "core shutdown")
+		default:
+			return
+		}
+	}
+}
+
+func (n *notifier) dispatchLoop() {
+	defer n.wg.Done()
+
+	for {
+		event, dropped, err := n.sub.Next(n.ctx)
+		if err != nil {
+			return
+		}
+		if dropped.Count > 0 {
+			n.deadLetterDropped(dropped)
+		}
+		n.dispatch(event)
+	}
+}
+
+// dispatch renderiza y encola el evento para cada regla que coincide.
+func (n *notifier) dispatch(event *pb.CoreEvent) {
+	for _, rule := range n.rules {
+		if !rule.matches(event) {
+			continue
+		}
+		if !rule.limiter.allow(n.now()) {
+			n.echoMetrics.RecordNotifierDelivery(n.ctx, rule.config.Name, "rate_limited")
+			continue
+		}
+
+		eventType := eventTypeString(event.Type)
+		payload, err := rule.render(event)
+		for _, target := range rule.config.URLs {
+			job := &notification{rule: rule, url: target, eventType: eventType, eventSeq: event.Seq, payload: payload}
+			if err != nil {
+				n.deadLetter(job, 0, 0, err.Error())
+				continue
+			}
+			select {
+			case n.jobs <- job:
+			default:
+				n.echoMetrics.RecordNotifierDelivery(n.ctx, rule.config.Name, "dropped")
+				n.deadLetter(job, 0, 0, "notifier queue full")
+			}
+		}
+	}
+}
+
+func (n *notifier) worker() {
+	defer n.wg.Done()
+
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case job := <-n.jobs:
+			n.deliver(job)
+		}
+	}
+}
+
+// deliver envía job reintentando en 5xx, 429 y errores de red.
+func (n *notifier) deliver(job *notification) {
+	for attempt := 1; ; attempt++ {
+		status, err := n.post(job)
+		if err == nil {
+			n.echoMetrics.RecordNotifierDelivery(n.ctx, job.rule.config.Name, "delivered")
+			return
+		}
+
+		retryable := status == 0 || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+		if !retryable || attempt >= n.config.MaxAttempts {
+			n.deadLetter(job, attempt, status, err.Error())
+			return
+		}
+		n.echoMetrics.RecordNotifierDelivery(n.ctx, job.rule.config.Name, "retry")
+
+		timer := time.NewTimer(n.backoff(attempt))
+		select {
+		case <-n.ctx.Done():
+			timer.Stop()
+			n.deadLetter(job, attempt, status, "core shutdown: "+err.Error())
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// post envía el payload. Retorna el HTTP status (0 si no hubo respuesta) y error si no fue 2xx.
+func (n *notifier) post(job *notification) (int, error) {
+	req, err := http.NewRequestWithContext(n.ctx, http.MethodPost, job.url, bytes.NewReader(job.payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "echo-core-notifier")
+	for key, value := range job.rule.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, notifierMaxResponseBytes))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff InitialBackoff * 2^(attempt-1), acotado por MaxBackoff.
+func (n *notifier) backoff(attempt int) time.Duration {
+	delay := n.config.InitialBackoff
+	for i := 1; i < attempt && delay < n.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if n.config.MaxBackoff > 0 && delay > n.config.MaxBackoff {
+		delay = n.config.MaxBackoff
+	}
+	return delay
+}
+
+// deadLetter persiste un webhook no entregado.
+func (n *notifier) deadLetter(job *notification, attempts, status int, reason string) {
+	n.echoMetrics.RecordNotifierDelivery(n.ctx, job.rule.config.Name, "dead_letter")
+	n.telemetry.Warn(n.ctx, "Webhook not delivered (i8)",
+		attribute.String("rule", job.rule.config.Name),
+		attribute.String("url", job.url),
+		attribute.String("event_type", job.eventType),
+		attribute.Int("attempts", attempts),
+		attribute.Int("last_status", status),
+		attribute.String("error", reason),
+	)
+	if n.repo == nil {
+		return
+	}
+
+	// n.ctx puede estar cancelado (shutdown): el registro se escribe igual
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	letter := &domain.NotificationDeadLetter{
+		ID:          utils.GenerateUUIDv7(),
+		Rule:        job.rule.config.Name,
+		URL:         job.url,
+		EventType:   job.eventType,
+		EventSeq:    job.eventSeq,
+		Payload:     string(job.payload),
+		Attempts:    attempts,
+		LastStatus:  status,
+		LastError:   reason,
+		CreatedAtMs: n.now().UnixMilli(),
+	}
+	if err := n.repo.Create(ctx, letter); err != nil {
+		n.telemetry.Error(n.ctx, "Failed to persist notification dead letter", err,
+			attribute.String("rule", job.rule.config.Name),
+		)
+	}
+}
+
+// notifierDroppedEventType event_type del dead letter que resume eventos descartados.
+const notifierDroppedEventType = "events_dropped"
+
+// deadLetterDropped deja constancia de los eventos que el eventHub descartó porque el
+// notifier no los consumió a tiempo. No se sabe qué reglas habrían coincidido, así
+// que se registra un único dead letter (sin regla ni URL) con la cantidad y el rango de seq.
+func (n *notifier) deadLetterDropped(dropped eventDrops) {
+	n.echoMetrics.RecordNotifierDelivery(n.ctx, "", "events_dropped")
+	reason := fmt.Sprintf("notifier lagging: %d events dropped (seq %d-%d)", dropped.Count, dropped.FirstSeq, dropped.LastSeq)
+	n.telemetry.Warn(n.ctx, "Notifier lagging behind events (i8)",
+		attribute.Int64("dropped", int64(dropped.Count)),
+		attribute.Int64("first_seq", int64(dropped.FirstSeq)),
+		attribute.Int64("last_seq", int64(dropped.LastSeq)),
+	)
+	if n.repo == nil {
+		return
+	}
+
+	payload, _ := json.Marshal(map[string]uint64{
+		"dropped":   dropped.Count,
+		"first_seq": dropped.FirstSeq,
+		"last_seq":  dropped.LastSeq,
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	letter := &domain.NotificationDeadLetter{
+		ID:          utils.GenerateUUIDv7(),
+		EventType:   notifierDroppedEventType,
+		EventSeq:    dropped.LastSeq,
+		Payload:     string(payload),
+		LastError:   reason,
+		CreatedAtMs: n.now().UnixMilli(),
+	}
+	if err := n.repo.Create(ctx, letter); err != nil {
+		n.telemetry.Error(n.ctx, "Failed to persist notification dead letter", err,
+			attribute.String("event_type", notifierDroppedEventType),
+		)
+	}
+}
+
+// notifierRule regla compilada: filtros, template y rate limit.
+type notifierRule struct {
+	config     NotifierRule
+	eventType  pb.CoreEventType
+	accounts   map[string]struct{}
+	strategies map[string]struct{}
+	symbols    map[string]struct{}
+	handshake  map[string]struct{}
+	template   *template.Template
+	limiter    *ruleRateLimiter
+}
+
+// Validate verifica la regla sin compilarla en el notifier (LoadConfig).
+func (r NotifierRule) Validate() error {
+	_, err := compileNotifierRule(r)
+	return err
+}
+
+func compileNotifierRule(config NotifierRule) (*notifierRule, error) {
+	eventType, ok := coreEventTypeFromString(config.Event)
+	if !ok {
+		return nil, fmt.Errorf("unknown event %q", config.Event)
+	}
+	if len(config.URLs) == 0 {
+		return nil, fmt.Errorf("urls is required")
+	}
+	for _, target := range config.URLs {
+		parsed, err := url.Parse(target)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid url %q", target)
+		}
+	}
+	if config.MaxPerMinute < 0 {
+		return nil, fmt.Errorf("max_per_minute must be >= 0")
+	}
+	if config.Success != nil && eventType != pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT &&
+		eventType != pb.CoreEventType_CORE_EVENT_TYPE_CLOSE_RESULT {
+		return nil, fmt.Errorf("success only applies to execution_result and close_result")
+	}
+	if config.MinSlippagePoints < 0 {
+		return nil, fmt.Errorf("min_slippage_points must be >= 0")
+	}
+	if config.MinSlippagePoints > 0 && eventType != pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT {
+		return nil, fmt.Errorf("min_slippage_points only applies to execution_result")
+	}
+	if len(config.HandshakeStatuses) > 0 && eventType != pb.CoreEventType_CORE_EVENT_TYPE_HANDSHAKE_CHANGED {
+		return nil, fmt.Errorf("handshake_statuses only applies to handshake_changed")
+	}
+
+	rule := &notifierRule{
+		config:     config,
+		eventType:  eventType,
+		accounts:   stringSet(config.Accounts),
+		strategies: stringSet(config.Strategies),
+		symbols:    stringSet(config.Symbols),
+		limiter:    newRuleRateLimiter(config.MaxPerMinute),
+	}
+	if len(config.HandshakeStatuses) > 0 {
+		rule.handshake = make(map[string]struct{}, len(config.HandshakeStatuses))
+		for _, value := range config.HandshakeStatuses {
+			status := strings.ToUpper(strings.TrimSpace(value))
+			if _, ok := pb.SymbolRegistrationStatus_value[handshakeStatusPrefix+status]; !ok || status == "UNSPECIFIED" {
+				return nil, fmt.Errorf("unknown handshake status %q", value)
+			}
+			rule.handshake[status] = struct{}{}
+		}
+	}
+	if config.Template != "" {
+		tmpl, err := template.New(config.Name).Option("missingkey=error").Funcs(template.FuncMap{"json": notifierJSON}).Parse(config.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		rule.template = tmpl
+	}
+	return rule, nil
+}
+
+const handshakeStatusPrefix = "SYMBOL_REGISTRATION_STATUS_"
+
+func (r *notifierRule) matches(event *pb.CoreEvent) bool {
+	if event.Type != r.eventType ||
+		!setContains(r.accounts, event.AccountId) ||
+		!setContains(r.strategies, event.StrategyId) ||
+		!setContains(r.symbols, event.Symbol) {
+		return false
+	}
+
+	switch payload := event.Payload.(type) {
+	case *pb.CoreEvent_ExecutionResult:
+		if r.config.Success != nil && payload.ExecutionResult.GetSuccess() != *r.config.Success {
+			return false
+		}
+		if r.config.MinSlippagePoints > 0 {
+			slippage := payload.ExecutionResult.SlippagePoints
+			return slippage != nil && *slippage >= r.config.MinSlippagePoints
+		}
+	case *pb.CoreEvent_CloseResult:
+		if r.config.Success != nil && payload.CloseResult.GetSuccess() != *r.config.Success {
+			return false
+		}
+	case *pb.CoreEvent_HandshakeChanged:
+		if r.handshake != nil {
+			status := strings.TrimPrefix(payload.HandshakeChanged.GetStatus().String(), handshakeStatusPrefix)
+			return setContains(r.handshake, status)
+		}
+	}
+	return true
+}
+
+// notificationData datos disponibles en el template de la regla.
+type notificationData struct {
+	Rule        string
+	Type        string
+	Seq         uint64
+	TimestampMs int64
+	Time        string // RFC3339 UTC
+	AccountID   string
+	StrategyID  string
+	Symbol      string
+	TradeID     string
+	AgentID     string
+	Summary     string
+	Event       *pb.CoreEvent
+}
+
+// render arma el payload JSON: template de la regla o, sin template,
+// {"rule", "summary", "event"} con el CoreEvent completo.
+func (r *notifierRule) render(event *pb.CoreEvent) ([]byte, error) {
+	data := notificationData{
+		Rule:        r.config.Name,
+		Type:        eventTypeString(event.Type),
+		Seq:         event.Seq,
+		TimestampMs: event.TimestampMs,
+		Time:        time.UnixMilli(event.TimestampMs).UTC().Format(time.RFC3339),
+		AccountID:   event.AccountId,
+		StrategyID:  event.StrategyId,
+		Symbol:      event.Symbol,
+		TradeID:     event.TradeId,
+		AgentID:     event.AgentId,
+		Summary:     notificationSummary(event),
+		Event:       event,
+	}
+
+	if r.template == nil {
+		eventJSON, err := notifierJSON(event)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]interface{}{
+			"rule":    data.Rule,
+			"summary": data.Summary,
+			"event":   json.RawMessage(eventJSON),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := r.template.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return buf.Bytes(), fmt.Errorf("template rendered invalid JSON")
+	}
+	return buf.Bytes(), nil
+}
+
+// notifierJSON función "json" del template: serializa value escapado para JSON.
+func notifierJSON(value interface{}) (string, error) {
+	if message, ok := value.(proto.Message); ok {
+		encoded, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(message)
+		return string(encoded), err
+	}
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
+
+// notificationSummary texto legible del evento para chats y alertas.
+func notificationSummary(event *pb.CoreEvent) string {
+	switch payload := event.Payload.(type) {
+	case *pb.CoreEvent_RiskRejected:
+		return fmt.Sprintf("Copy of trade %s rejected for account %s: %s",
+			event.TradeId, event.AccountId, payload.RiskRejected.GetReason())
+	case *pb.CoreEvent_ExecutionResult:
+		result := payload.ExecutionResult
+		if !result.GetSuccess() {
+			return fmt.Sprintf("Execution of trade %s failed on account %s: %s",
+				event.TradeId, event.AccountId, result.GetErrorCode())
+		}
+		if result.SlippagePoints != nil {
+			return fmt.Sprintf("Trade %s filled on account %s with %.1f points of slippage",
+				event.TradeId, event.AccountId, result.GetSlippagePoints())
+		}
+		return fmt.Sprintf("Trade %s filled on account %s", event.TradeId, event.AccountId)
+	case *pb.CoreEvent_CloseResult:
+		if !payload.CloseResult.GetSuccess() {
+			return fmt.Sprintf("Close of trade %s failed on account %s: %s",
+				event.TradeId, event.AccountId, payload.CloseResult.GetErrorCode())
+		}
+		return fmt.Sprintf("Trade %s closed on account %s", event.TradeId, event.AccountId)
+	case *pb.CoreEvent_HandshakeChanged:
+		return fmt.Sprintf("Handshake of account %s changed from %s to %s",
+			event.AccountId,
+			strings.TrimPrefix(payload.HandshakeChanged.GetPreviousStatus().String(), handshakeStatusPrefix),
+			strings.TrimPrefix(payload.HandshakeChanged.GetStatus().String(), handshakeStatusPrefix))
+	case *pb.CoreEvent_AgentConnection:
+		state := "disconnected"
+		if event.Type == pb.CoreEventType_CORE_EVENT_TYPE_AGENT_CONNECTED {
+			state = "connected"
+		}
+		return fmt.Sprintf("Agent %s %s (%d agents connected)",
+			event.AgentId, state, payload.AgentConnection.GetTotalAgents())
+	default:
+		return fmt.Sprintf("%s account=%s trade=%s", eventTypeString(event.Type), event.AccountId, event.TradeId)
+	}
+}
+
+// coreEventTypeFromString inverso de eventTypeString (nombres de core/notifier/rules).
+func coreEventTypeFromString(name string) (pb.CoreEventType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for value := range pb.CoreEventType_name {
+		eventType := pb.CoreEventType(value)
+		if eventType != pb.CoreEventType_CORE_EVENT_TYPE_UNSPECIFIED && eventTypeString(eventType) == name {
+			return eventType, true
+		}
+	}
+	return pb.CoreEventType_CORE_EVENT_TYPE_UNSPECIFIED, false
+}
+
+// ruleRateLimiter token bucket de perMinute envíos por minuto (0 = sin límite).
+type ruleRateLimiter struct {
+	perMinute float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRuleRateLimiter(perMinute int) *ruleRateLimiter {
+	return &ruleRateLimiter{perMinute: float64(perMinute), tokens: float64(perMinute)}
+}
+
+func (l *ruleRateLimiter) allow(now time.Time) bool {
+	if l.perMinute <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens += now.Sub(l.last).Minutes() * l.perMinute
+		if l.tokens > l.perMinute {
+			l.tokens = l.perMinute
+		}
+	}
+	l.last = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
@@ -0,0 +1,246 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"google.golang.org/protobuf/proto"
+)
+
+type fakeDeadLetterRepo struct {
+	mu      sync.Mutex
+	letters []*domain.NotificationDeadLetter
+}
+
+func (r *fakeDeadLetterRepo) Create(ctx context.Context, letter *domain.NotificationDeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.letters = append(r.letters, letter)
+	return nil
+}
+
+func (r *fakeDeadLetterRepo) List(ctx context.Context, limit, offset int) ([]*domain.NotificationDeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*domain.NotificationDeadLetter(nil), r.letters...), nil
+}
+
+func newTestNotifier(t *testing.T, hub *eventHub, repo domain.NotificationDeadLetterRepository, rules ...NotifierRule) *notifier {
+	t.Helper()
+	n, err := newNotifier(context.Background(), NotifierConfig{
+		Workers:        1,
+		QueueSize:      16,
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Rules:          rules,
+	}, hub, repo, &telemetry.Client{}, &metricbundle.EchoMetrics{})
+	require.NoError(t, err)
+	require.NoError(t, n.Start())
+	return n
+}
+
+func TestNotifierPostsTemplatedPayloadForMatchingEvents(t *testing.T) {
+	bodies := make(chan []byte, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("X-Token"))
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	hub := newEventHub(EventsConfig{SubscriberBuffer: 16}, nil)
+	n := newTestNotifier(t, hub, &fakeDeadLetterRepo{}, NotifierRule{
+		Name:     "rejections",
+		Event:    "risk_rejected",
+		Accounts: []string{"s1"},
+		URLs:     []string{server.URL},
+		Headers:  map[string]string{"X-Token": "secret"},
+		Template: `{"text": {{json .Summary}}, "trade": {{json .TradeID}}}`,
+	})
+	defer n.Stop()
+
+	hub.Publish(&pb.CoreEvent{Type: pb.CoreEventType_CORE_EVENT_TYPE_RISK_REJECTED, AccountId: "s2", TradeId: "t0"})
+	hub.Publish(&pb.CoreEvent{Type: pb.CoreEventType_CORE_EVENT_TYPE_INTENT_RECEIVED, AccountId: "s1", TradeId: "t1"})
+	hub.Publish(&pb.CoreEvent{
+		Type:      pb.CoreEventType_CORE_EVENT_TYPE_RISK_REJECTED,
+		AccountId: "s1",
+		TradeId:   "t2",
+		Payload:   &pb.CoreEvent_RiskRejected{RiskRejected: &pb.RiskRejectedEvent{Reason: "volume_guard_reject"}},
+	})
+
+	select {
+	case body := <-bodies:
+		var payload map[string]string
+		require.NoError(t, json.Unmarshal(body, &payload))
+		assert.Equal(t, "t2", payload["trade"])
+		assert.Equal(t, `Copy of trade t2 rejected for account s1: volume_guard_reject`, payload["text"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook not delivered")
+	}
+	select {
+	case body := <-bodies:
+		t.Fatalf("unexpected webhook %s", body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestNotifierRetriesAndDeadLetters(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// El trade t1 se recupera al segundo intento; t2 nunca
+		var payload struct {
+			Event struct {
+				TradeID string `json:"trade_id"`
+			} `json:"event"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &payload)
+		if hits.Add(1) == 1 || payload.Event.TradeID == "t2" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	hub := newEventHub(EventsConfig{SubscriberBuffer: 16}, nil)
+	repo := &fakeDeadLetterRepo{}
+	n := newTestNotifier(t, hub, repo, NotifierRule{
+		Name:    "failed-executions",
+		Event:   "execution_result",
+		Success: proto.Bool(false),
+		URLs:    []string{server.URL},
+	})
+
+	hub.Publish(&pb.CoreEvent{
+		Type:    pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT,
+		TradeId: "t0",
+		Payload: &pb.CoreEvent_ExecutionResult{ExecutionResult: &pb.ExecutionResultEvent{Success: true}},
+	})
+	for _, tradeID := range []string{"t1", "t2"} {
+		hub.Publish(&pb.CoreEvent{
+			Type:    pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT,
+			TradeId: tradeID,
+			Payload: &pb.CoreEvent_ExecutionResult{ExecutionResult: &pb.ExecutionResultEvent{ErrorCode: "ERROR_CODE_NO_MONEY"}},
+		})
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		letters, _ := repo.List(context.Background(), 10, 0)
+		if len(letters) > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	n.Stop()
+
+	letters, err := repo.List(context.Background(), 10, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, "failed-executions", letters[0].Rule)
+	assert.Equal(t, "execution_result", letters[0].EventType)
+	assert.Equal(t, 3, letters[0].Attempts)
+	assert.Equal(t, http.StatusServiceUnavailable, letters[0].LastStatus)
+	assert.Contains(t, letters[0].Payload, `"trade_id":"t2"`)
+	assert.Equal(t, int32(5), hits.Load(), "t1: 2 attempts, t2: 3 attempts, t0 filtered")
+}
+
+func TestNotifierRuleFiltersAndRateLimit(t *testing.T) {
+	rule, err := compileNotifierRule(NotifierRule{
+		Name:              "slippage",
+		Event:             "execution_result",
+		MinSlippagePoints: 20,
+		URLs:              []string{"http://localhost/hook"},
+		MaxPerMinute:      2,
+	})
+	require.NoError(t, err)
+
+	fill := func(slippage *float64) *pb.CoreEvent {
+		return &pb.CoreEvent{
+			Type:    pb.CoreEventType_CORE_EVENT_TYPE_EXECUTION_RESULT,
+			Payload: &pb.CoreEvent_ExecutionResult{ExecutionResult: &pb.ExecutionResultEvent{Success: true, SlippagePoints: slippage}},
+		}
+	}
+	assert.True(t, rule.matches(fill(proto.Float64(25))))
+	assert.False(t, rule.matches(fill(proto.Float64(5))))
+	assert.False(t, rule.matches(fill(nil)))
+
+	now := time.Unix(1000, 0)
+	assert.True(t, rule.limiter.allow(now))
+	assert.True(t, rule.limiter.allow(now))
+	assert.False(t, rule.limiter.allow(now))
+	assert.True(t, rule.limiter.allow(now.Add(30*time.Second)), "one token refilled after 30s")
+
+	handshake, err := compileNotifierRule(NotifierRule{
+		Event:             "handshake_changed",
+		HandshakeStatuses: []string{"rejected"},
+		URLs:              []string{"https://hooks.example.com/x"},
+	})
+	require.NoError(t, err)
+	changed := func(status pb.SymbolRegistrationStatus) *pb.CoreEvent {
+		return &pb.CoreEvent{
+			Type:    pb.CoreEventType_CORE_EVENT_TYPE_HANDSHAKE_CHANGED,
+			Payload: &pb.CoreEvent_HandshakeChanged{HandshakeChanged: &pb.HandshakeChangedEvent{Status: status}},
+		}
+	}
+	assert.True(t, handshake.matches(changed(pb.SymbolRegistrationStatus_SYMBOL_REGISTRATION_STATUS_REJECTED)))
+	assert.False(t, handshake.matches(changed(pb.SymbolRegistrationStatus_SYMBOL_REGISTRATION_STATUS_WARNING)))
+
+	for _, invalid := range []NotifierRule{
+		{Event: "circuit_breaker", URLs: []string{"http://localhost/hook"}},
+		{Event: "agent_disconnected"},
+		{Event: "agent_disconnected", URLs: []string{"ftp://localhost/hook"}},
+		{Event: "agent_disconnected", URLs: []string{"http://localhost/hook"}, Success: proto.Bool(false)},
+		{Event: "risk_rejected", URLs: []string{"http://localhost/hook"}, MinSlippagePoints: 10},
+		{Event: "handshake_changed", URLs: []string{"http://localhost/hook"}, HandshakeStatuses: []string{"BROKEN"}},
+		{Event: "risk_rejected", URLs: []string{"http://localhost/hook"}, Template: `{{.Missing`},
+	} {
+		assert.Error(t, invalid.Validate(), "%+v", invalid)
+	}
+}
+
+func TestNotifierDeadLettersEventsDroppedByHub(t *testing.T) {
+	hub := newEventHub(EventsConfig{SubscriberBuffer: 2}, nil)
+	repo := &fakeDeadLetterRepo{}
+	n, err := newNotifier(context.Background(), NotifierConfig{QueueSize: 16, Rules: []NotifierRule{{
+		Name:  "intents",
+		Event: "intent_received",
+		URLs:  []string{"http://localhost/hook"},
+	}}}, hub, repo, &telemetry.Client{}, &metricbundle.EchoMetrics{})
+	require.NoError(t, err)
+
+	// Sin dispatchLoop el buffer de la suscripción se llena y el hub descarta t1 y t2
+	n.sub, err = hub.Subscribe(&pb.WatchEventsRequest{Types: []pb.CoreEventType{pb.CoreEventType_CORE_EVENT_TYPE_INTENT_RECEIVED}})
+	require.NoError(t, err)
+	for _, tradeID := range []string{"t1", "t2", "t3", "t4"} {
+		hub.Publish(&pb.CoreEvent{Type: pb.CoreEventType_CORE_EVENT_TYPE_INTENT_RECEIVED, TradeId: tradeID})
+	}
+
+	n.wg.Add(1)
+	go n.dispatchLoop()
+	require.Eventually(t, func() bool { return len(n.jobs) == 2 }, time.Second, 5*time.Millisecond)
+	n.Stop()
+
+	letters, err := repo.List(context.Background(), 10, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 3, "drop summary + 2 queued jobs at shutdown")
+	assert.Equal(t, notifierDroppedEventType, letters[0].EventType)
+	assert.Equal(t, uint64(2), letters[0].EventSeq)
+	assert.JSONEq(t, `{"dropped": 2, "first_seq": 1, "last_seq": 2}`, letters[0].Payload)
+	assert.Contains(t, letters[0].LastError, "seq 1-2")
+	assert.Equal(t, "core shutdown", letters[1].LastError)
+}
@@ -0,0 +1,200 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/proto"
+)
+
+// outboxDispatcher entrega los comandos pendientes del outbox transaccional (i8).
+//
+// handleTradeIntent persiste trade, dedupe y ExecuteOrders en una transacción. Los
+// comandos cuyo owner está conectado se insertan DISPATCHED y los entrega el router;
+// el dispatcher cubre el resto: owner desconectado, canal lleno o Core reiniciado
+// entre la persistencia y el envío.
+//
+//   - PENDING: se entrega cuando el Agent owner (AccountRegistry) está conectado.
+//   - DISPATCHED sin ack tras RedeliverAfter: se reentrega (el Slave EA deduplica por command_id).
+//   - Más antiguos que MaxAge: se expiran y no se reintentan.
+//   - ACKED/EXPIRED: se purgan tras Retention.
+type outboxDispatcher struct {
+	router    *Router
+	repo      domain.OutboxRepository
+	config    OutboxConfig
+	telemetry *telemetry.Client
+
+	kick chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newOutboxDispatcher(
+	parentCtx context.Context,
+	router *Router,
+	repo domain.OutboxRepository,
+	config OutboxConfig,
+	telemetryClient *telemetry.Client,
+) *outboxDispatcher {
+	ctx, cancel := context.WithCancel(parentCtx)
+	return &outboxDispatcher{
+		router:    router,
+		repo:      repo,
+		config:    config,
+		telemetry: telemetryClient,
+		kick:      make(chan struct{}, 1),
+		ctx:       ctx,
+		cancel:    cancel,
+	}
+}
+
+func (d *outboxDispatcher) Start() {
+	d.wg.Add(1)
+	go d.worker()
+}
+
+func (d *outboxDispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Kick fuerza un ciclo de entrega (ej: una cuenta acaba de conectarse).
+func (d *outboxDispatcher) Kick() {
+	select {
+	case d.kick <- struct{}{}:
+	default:
+		// Ya hay un ciclo pendiente
+	}
+}
+
+func (d *outboxDispatcher) worker() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	// Entregar lo que quedó pendiente antes del reinicio
+	d.runOnce(d.ctx)
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-ticker.C:
+			d.runOnce(d.ctx)
+		case <-d.kick:
+			d.runOnce(d.ctx)
+		}
+	}
+}
+
+// runOnce ejecuta un ciclo completo: expirar, entregar y purgar.
+func (d *outboxDispatcher) runOnce(ctx context.Context) {
+	nowMs := utils.NowUnixMilli()
+
+	d.expire(ctx, nowMs)
+	d.deliver(ctx, nowMs)
+
+	if deleted, err := d.repo.PurgeCompleted(ctx, nowMs-d.config.Retention.Milliseconds()); err != nil {
+		d.telemetry.Warn(ctx, "Outbox purge failed (i8)",
+			attribute.String("error", err.Error()),
+		)
+	} else if deleted > 0 {
+		d.telemetry.Debug(ctx, "Outbox purge completed (i8)",
+			attribute.Int("deleted", deleted),
+		)
+	}
+}
+
+func (d *outboxDispatcher) expire(ctx context.Context, nowMs int64) {
+	expired, err := d.repo.ExpireOlderThan(ctx, nowMs-d.config.MaxAge.Milliseconds(), nowMs)
+	if err != nil {
+		d.telemetry.Warn(ctx, "Outbox expiration failed (i8)",
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+
+	for _, command := range expired {
+		d.telemetry.Warn(ctx, "Outbox command expired without ack (i8)",
+			attribute.String("command_id", command.CommandID),
+			attribute.String("trade_id", command.TradeID),
+			attribute.String("target_account_id", command.TargetAccountID),
+			attribute.Int("attempts", command.Attempts),
+			attribute.String("last_agent_id", command.LastAgentID),
+		)
+		d.router.core.echoMetrics.RecordRoutingMode(ctx, "outbox", "expired",
+			attribute.String("target_account_id", command.TargetAccountID),
+			attribute.String("trade_id", command.TradeID),
+		)
+		d.router.deleteCommandContext(command.CommandID)
+	}
+}
+
+func (d *outboxDispatcher) deliver(ctx context.Context, nowMs int64) {
+	commands, err := d.repo.ListDeliverable(ctx, nowMs-d.config.RedeliverAfter.Milliseconds(), d.config.BatchSize)
+	if err != nil {
+		d.telemetry.Warn(ctx, "Outbox listing failed (i8)",
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+
+	for _, command := range commands {
+		if ctx.Err() != nil {
+			return
+		}
+		d.deliverCommand(ctx, command)
+	}
+}
+
+func (d *outboxDispatcher) deliverCommand(ctx context.Context, command *domain.OutboxCommand) {
+	order := &pb.ExecuteOrder{}
+	if err := proto.Unmarshal(command.Payload, order); err != nil {
+		d.telemetry.Error(ctx, "Failed to decode outbox command (i8)", err,
+			attribute.String("command_id", command.CommandID),
+		)
+		return
+	}
+
+	// Tras un reinicio el índice en memoria está vacío: reconstruirlo (con la auditoría
+	// persistida) para resolver el ExecutionResult
+	if d.router.getCommandContext(command.CommandID) == nil {
+		d.router.restoreCommandContext(command, order)
+	}
+
+	agentID, sent := d.router.deliverExecuteOrder(ctx, order)
+	if !sent {
+		return
+	}
+
+	mode := "outbox"
+	if command.Status == domain.OutboxStatusDispatched {
+		mode = "outbox_redelivery"
+	}
+	d.router.recordRoutingMetric(ctx, mode, true, order)
+	d.router.publishOrderDispatched(agentID, mode, true, order)
+
+	if err := d.repo.MarkDispatched(ctx, command.CommandID, agentID, utils.NowUnixMilli()); err != nil {
+		d.telemetry.Warn(ctx, "Failed to mark outbox command dispatched (i8)",
+			attribute.String("command_id", command.CommandID),
+			attribute.String("error", err.Error()),
+		)
+	}
+
+	d.telemetry.Info(ctx, "Outbox command delivered (i8)",
+		attribute.String("command_id", command.CommandID),
+		attribute.String("trade_id", command.TradeID),
+		attribute.String("agent_id", agentID),
+		attribute.String("previous_status", string(command.Status)),
+		attribute.Int("attempts", command.Attempts+1),
+	)
+}
@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestOutboxDispatcher arma el dispatcher sobre el outbox en memoria de newTestAdminCore.
+func newTestOutboxDispatcher(core *Core) *outboxDispatcher {
+	return newOutboxDispatcher(context.Background(), core.router, core.repoFactory.OutboxRepository(), OutboxConfig{
+		PollInterval:   time.Second,
+		MaxAge:         time.Hour,
+		RedeliverAfter: time.Hour,
+		BatchSize:      10,
+		Retention:      time.Hour,
+	}, core.telemetry)
+}
+
+// enqueueTestCommand persiste el trade y un ExecuteOrder PENDING con auditoría para la cuenta.
+func enqueueTestCommand(t *testing.T, core *Core, tradeID, commandID, accountID string, createdAtMs int64) {
+	t.Helper()
+
+	payload, err := proto.Marshal(&pb.ExecuteOrder{
+		CommandId:       commandID,
+		TradeId:         tradeID,
+		TargetAccountId: accountID,
+		Symbol:          "XAUUSD.m",
+		Timestamps:      &pb.TimestampMetadata{T0MasterEaMs: 100, T1AgentRecvMs: 110, T2CoreRecvMs: 120},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, core.repoFactory.OutboxRepository().EnqueueTrade(context.Background(),
+		&domain.Trade{TradeID: tradeID, Symbol: "XAUUSD"},
+		&domain.DedupeEntry{TradeID: tradeID, Status: domain.OrderStatusPending},
+		[]*domain.OutboxCommand{{
+			CommandID:         commandID,
+			TradeID:           tradeID,
+			TargetAccountID:   accountID,
+			CommandType:       "execute_order",
+			Payload:           payload,
+			CreatedAtMs:       createdAtMs,
+			StrategyID:        "scalper",
+			RiskPolicyType:    string(domain.RiskPolicyTypeFixedLot),
+			RiskPolicyVersion: 3,
+			Symbol:            "XAUUSD",
+			ReferencePrice:    2000.5,
+			Point:             0.01,
+		}}))
+}
+
+func getOutboxCommand(t *testing.T, core *Core, commandID string) *domain.OutboxCommand {
+	t.Helper()
+
+	command, err := core.repoFactory.OutboxRepository().GetByID(context.Background(), commandID)
+	require.NoError(t, err)
+	require.NotNil(t, command)
+	return command
+}
+
+func TestOutboxDispatcherDeliversPendingToOwner(t *testing.T) {
+	ctx := context.Background()
+	core, conn := newTestAdminCore(t, SecurityConfig{})
+	dispatcher := newTestOutboxDispatcher(core)
+	nowMs := utils.NowUnixMilli()
+	enqueueTestCommand(t, core, "t1", "c1", "s1", nowMs)
+	enqueueTestCommand(t, core, "t2", "c2", "s2", nowMs) // s2 sin owner
+
+	dispatcher.runOnce(ctx)
+
+	require.Len(t, conn.SendCh, 1)
+	order := (<-conn.SendCh).GetExecuteOrder()
+	require.NotNil(t, order)
+	assert.Equal(t, "c1", order.CommandId)
+	assert.NotZero(t, order.Timestamps.T3CoreSendMs)
+
+	delivered := getOutboxCommand(t, core, "c1")
+	assert.Equal(t, domain.OutboxStatusDispatched, delivered.Status)
+	assert.Equal(t, "agent-1", delivered.LastAgentID)
+	assert.Equal(t, 1, delivered.Attempts)
+	assert.Equal(t, domain.OutboxStatusPending, getOutboxCommand(t, core, "c2").Status)
+
+	// El contexto reconstruido conserva la auditoría del outbox
+	cmdCtx := core.router.getCommandContext("c1")
+	require.NotNil(t, cmdCtx)
+	assert.Equal(t, "s1", cmdCtx.SlaveAccountID)
+	assert.Equal(t, "scalper", cmdCtx.StrategyID)
+	assert.Equal(t, domain.RiskPolicyTypeFixedLot, cmdCtx.RiskPolicyType)
+	assert.Equal(t, int64(3), cmdCtx.RiskPolicyVersion)
+	assert.Equal(t, "XAUUSD", cmdCtx.Symbol)
+	assert.Equal(t, 2000.5, cmdCtx.ReferencePrice)
+	assert.Equal(t, 0.01, cmdCtx.Point)
+	require.NotNil(t, cmdCtx.MasterTimestamps)
+	assert.Equal(t, int64(100), cmdCtx.MasterTimestamps.T0MasterEaMs)
+}
+
+func TestOutboxDispatcherRedeliversWithoutAck(t *testing.T) {
+	ctx := context.Background()
+	core, conn := newTestAdminCore(t, SecurityConfig{})
+	dispatcher := newTestOutboxDispatcher(core)
+	enqueueTestCommand(t, core, "t1", "c1", "s1", utils.NowUnixMilli())
+
+	dispatcher.runOnce(ctx)
+	require.Len(t, conn.SendCh, 1)
+	<-conn.SendCh
+
+	// Dentro de RedeliverAfter no se reentrega
+	dispatcher.runOnce(ctx)
+	assert.Empty(t, conn.SendCh)
+
+	dispatcher.config.RedeliverAfter = 0
+	dispatcher.runOnce(ctx)
+	require.Len(t, conn.SendCh, 1)
+	assert.Equal(t, "c1", (<-conn.SendCh).GetExecuteOrder().GetCommandId())
+	assert.Equal(t, 2, getOutboxCommand(t, core, "c1").Attempts)
+
+	// Con ack no hay más reentregas
+	acked, err := core.repoFactory.OutboxRepository().MarkAcked(ctx, "c1", utils.NowUnixMilli())
+	require.NoError(t, err)
+	require.True(t, acked)
+	dispatcher.runOnce(ctx)
+	assert.Empty(t, conn.SendCh)
+}
+
+func TestOutboxDispatcherExpiresOldCommands(t *testing.T) {
+	ctx := context.Background()
+	core, conn := newTestAdminCore(t, SecurityConfig{})
+	dispatcher := newTestOutboxDispatcher(core)
+	enqueueTestCommand(t, core, "t1", "c1", "s1", utils.NowUnixMilli()-2*time.Hour.Milliseconds())
+	core.router.registerCommandContext("c1", "t1", "s1", "execute_order")
+
+	dispatcher.runOnce(ctx)
+
+	assert.Empty(t, conn.SendCh)
+	expired := getOutboxCommand(t, core, "c1")
+	assert.Equal(t, domain.OutboxStatusExpired, expired.Status)
+	assert.Zero(t, expired.Attempts)
+	assert.Nil(t, core.router.getCommandContext("c1"))
+}
+
+func TestNewOutboxCommandsClaimsConnectedOwner(t *testing.T) {
+	core, _ := newTestAdminCore(t, SecurityConfig{})
+	core.router.registerCommandContext("c1", "t1", "s1", "execute_order")
+	core.router.attachRiskPolicy("c1", "scalper", &domain.RiskPolicy{Type: domain.RiskPolicyTypeFixedLot, Version: 3})
+	core.router.attachSymbol("c1", "XAUUSD")
+	core.router.attachSlippageReference("c1", 2000.5, 0.01)
+
+	commands, err := core.router.newOutboxCommands([]*pb.ExecuteOrder{
+		{CommandId: "c1", TradeId: "t1", TargetAccountId: "s1"},
+		{CommandId: "c2", TradeId: "t1", TargetAccountId: "s2"},
+	}, 1000)
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+
+	// Owner conectado: la fila nace DISPATCHED y el dispatcher no la toma hasta RedeliverAfter
+	assert.Equal(t, domain.OutboxStatusDispatched, commands[0].Status)
+	assert.Equal(t, "agent-1", commands[0].LastAgentID)
+	assert.Equal(t, int64(1000), commands[0].DispatchedAtMs)
+	assert.Equal(t, 1, commands[0].Attempts)
+	assert.Equal(t, "scalper", commands[0].StrategyID)
+	assert.Equal(t, string(domain.RiskPolicyTypeFixedLot), commands[0].RiskPolicyType)
+	assert.Equal(t, int64(3), commands[0].RiskPolicyVersion)
+	assert.Equal(t, "XAUUSD", commands[0].Symbol)
+	assert.Equal(t, 2000.5, commands[0].ReferencePrice)
+	assert.Equal(t, 0.01, commands[0].Point)
+
+	assert.Equal(t, domain.OutboxStatusPending, commands[1].Status)
+	assert.Empty(t, commands[1].LastAgentID)
+}
+
+func TestRecoveredExecutionKeepsAudit(t *testing.T) {
+	ctx := context.Background()
+	core, _ := newTestAdminCore(t, SecurityConfig{})
+	enqueueTestCommand(t, core, "t1", "c1", "s1", utils.NowUnixMilli())
+
+	// Core reiniciado: el índice en memoria está vacío y el Agent solo reporta sus timestamps
+	price := 2000.6
+	core.router.handleExecutionResult(ctx, "agent-1", &pb.ExecutionResult{
+		CommandId:     "c1",
+		TradeId:       "T1",
+		Success:       true,
+		Ticket:        555,
+		ExecutedPrice: &price,
+		Timestamps:    &pb.TimestampMetadata{T4AgentRecvMs: 140, T7OrderFilledMs: 170},
+	})
+
+	execution, err := core.repoFactory.ExecutionRepository().GetByID(ctx, "c1")
+	require.NoError(t, err)
+	require.NotNil(t, execution)
+	assert.Equal(t, "s1", execution.SlaveAccountID)
+	assert.Equal(t, "scalper", execution.StrategyID)
+	assert.Equal(t, string(domain.RiskPolicyTypeFixedLot), execution.RiskPolicyType)
+	require.NotNil(t, execution.RiskPolicyVersion)
+	assert.Equal(t, int64(3), *execution.RiskPolicyVersion)
+	assert.Equal(t, int64(100), execution.TimestampsMs["t0"])
+	assert.Equal(t, int64(120), execution.TimestampsMs["t2"])
+	assert.Equal(t, int64(170), execution.TimestampsMs["t7"])
+
+	assert.Equal(t, domain.OutboxStatusAcked, getOutboxCommand(t, core, "c1").Status)
+}
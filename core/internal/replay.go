@@ -0,0 +1,381 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/core/internal/journal"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ReplayOptions configura el replay de segmentos del journal.
+type ReplayOptions struct {
+	// Segments a reproducir, en orden.
+	Segments []string
+
+	// Storage: StorageBackendMemory (default) o StorageBackendSQLite (base scratch).
+	Storage string
+
+	// SQLitePath base scratch para Storage=sqlite (default: archivo temporal).
+	// Nunca se usa la base productiva configurada en ETCD.
+	SQLitePath string
+
+	// OutputDir opcional: journal con los inbound reproducidos y los outbound generados.
+	OutputDir string
+
+	// Config explícita (ej. la actual de ETCD). nil usa el registro CONFIG del primer
+	// segmento, que es la config con la que el Core escribió el journal.
+	Config *Config
+}
+
+// ReplayReport resume un replay.
+type ReplayReport struct {
+	Segments               []string       `json:"segments"`
+	Storage                string         `json:"storage"`
+	FirstSeq               uint64         `json:"first_seq"`
+	LastSeq                uint64         `json:"last_seq"`
+	Records                int            `json:"records"`
+	Inbound                int            `json:"inbound"`
+	RecordedOutbound       int            `json:"recorded_outbound"`
+	ReplayedOutbound       int            `json:"replayed_outbound"`
+	InboundByType          map[string]int `json:"inbound_by_type"`
+	RecordedOutboundByType map[string]int `json:"recorded_outbound_by_type"`
+	ReplayedOutboundByType map[string]int `json:"replayed_outbound_by_type"`
+	Agents                 []string       `json:"agents"`
+	Truncated              bool           `json:"truncated"`      // Algún segmento terminaba con un registro incompleto
+	ConfigSource           string         `json:"config_source"`  // "journal" u "options"
+	ConfigChanged          bool           `json:"config_changed"` // Un segmento posterior registró otra config (reinicio del Core)
+	Duration               time.Duration  `json:"duration_ns"`
+}
+
+// Replay reproduce segmentos del journal a través del Router contra una base en
+// memoria o scratch, para reproducir incidentes offline.
+//
+// Los inbound se procesan en orden de seq y de forma síncrona (sin la cola del
+// Router), con Agents simulados que capturan los CoreMessage generados. El replay es
+// determinístico para unos mismos segmentos:
+//   - La config es la registrada en el journal (registro CONFIG), no la actual de ETCD.
+//   - El reloj del Core avanza con el arrival_ms de cada registro, de modo que la
+//     staleness de specs/quotes, el TTL de dedupe y los timestamps se evalúan con la
+//     hora del incidente.
+//   - Los command_id son UUIDv7 derivados de esa hora y un contador.
+//
+// La persistencia, telemetría remota, webhooks y el journal productivo se reemplazan.
+//
+// Example:
+//
+//	report, err := internal.Replay(ctx, internal.ReplayOptions{
+//	    Segments: []string{"journal/journal-00000000000000000001.log.gz"},
+//	})
+func Replay(ctx context.Context, opts ReplayOptions) (*ReplayReport, error) {
+	if len(opts.Segments) == 0 {
+		return nil, fmt.Errorf("at least one journal segment is required")
+	}
+
+	firstArrivalMs, snapshot, err := readReplayHeader(opts.Segments)
+	if err != nil {
+		return nil, err
+	}
+
+	configSource := "options"
+	recorded := opts.Config
+	if recorded == nil {
+		if snapshot == nil {
+			return nil, fmt.Errorf("journal segments have no config record; pass the config explicitly")
+		}
+		if recorded, err = configFromJournalSnapshot(snapshot); err != nil {
+			return nil, err
+		}
+		configSource = "journal"
+	}
+	config := *recorded
+
+	storage := opts.Storage
+	if storage == "" {
+		storage = StorageBackendMemory
+	}
+	switch storage {
+	case StorageBackendMemory:
+	case StorageBackendSQLite:
+		path := opts.SQLitePath
+		if path == "" {
+			tmpDir, err := os.MkdirTemp("", "echo-replay-")
+			if err != nil {
+				return nil, fmt.Errorf("failed to create scratch dir: %w", err)
+			}
+			defer os.RemoveAll(tmpDir)
+			path = filepath.Join(tmpDir, "replay.db")
+		}
+		config.SQLitePath = path
+	default:
+		return nil, fmt.Errorf("unsupported replay storage: %s (memory|sqlite)", storage)
+	}
+
+	// Aislar el replay del entorno productivo
+	config.StorageBackend = storage
+	config.MigrateOnStart = true
+	config.OTLPEndpoint = ""
+	config.MetricsEndpoint = ""
+	config.Environment = "replay"
+	config.Journal.Enabled = false
+	config.Notifier.Enabled = false
+	config.HTTPGateway.Enabled = false
+	config.AgentConfig.Enabled = false
+
+	clock := &replayClock{nowMs: firstArrivalMs}
+	core, err := newCore(ctx, &config, clock)
+	if err != nil {
+		return nil, err
+	}
+	defer core.Shutdown()
+
+	var output *journal.Writer
+	if opts.OutputDir != "" {
+		// El journal reproducido conserva la config y las horas del original: es reproducible
+		outputSnapshot, err := recorded.journalSnapshot()
+		if err != nil {
+			return nil, err
+		}
+		output, err = journal.Open(journal.Options{Dir: opts.OutputDir, ConfigSnapshot: outputSnapshot, Now: clock.Now})
+		if err != nil {
+			return nil, fmt.Errorf("failed to open replay output journal: %w", err)
+		}
+		defer output.Close()
+	}
+
+	report := &ReplayReport{
+		Segments:               opts.Segments,
+		Storage:                storage,
+		ConfigSource:           configSource,
+		InboundByType:          make(map[string]int),
+		RecordedOutboundByType: make(map[string]int),
+		ReplayedOutboundByType: make(map[string]int),
+	}
+	start := time.Now()
+
+	agents := make(map[string]*AgentConnection)
+	route := func(ctx context.Context, agentID string, msg *pb.AgentMessage) {
+		core.router.processMessage(&routerMessage{ctx: ctx, agentID: agentID, agentMsg: msg})
+	}
+
+	for _, path := range opts.Segments {
+		reader, err := journal.OpenSegment(path)
+		if err != nil {
+			return report, err
+		}
+
+		for {
+			if err := ctx.Err(); err != nil {
+				reader.Close()
+				return report, err
+			}
+
+			record, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				reader.Close()
+				return report, err
+			}
+
+			if report.Records == 0 {
+				report.FirstSeq = record.Seq
+			}
+			report.Records++
+			report.LastSeq = record.Seq
+
+			clock.advance(record.ArrivalMs)
+
+			switch record.Direction {
+			case pb.JournalDirection_JOURNAL_DIRECTION_CONFIG:
+				// El Core no se reconfigura a mitad del replay: solo se reporta
+				if configSource == "journal" && !bytes.Equal(record.GetCoreConfig(), snapshot) {
+					report.ConfigChanged = true
+				}
+
+			case pb.JournalDirection_JOURNAL_DIRECTION_INBOUND:
+				msg := record.GetAgentMessage()
+				if msg == nil {
+					continue
+				}
+				report.Inbound++
+				report.InboundByType[payloadName(msg.ProtoReflect(), "payload")]++
+
+				if _, ok := agents[record.AgentId]; !ok {
+					agents[record.AgentId] = core.registerReplayAgent(record.AgentId)
+				}
+				if output != nil {
+					output.RecordInbound(record.AgentId, msg)
+				}
+
+				core.dispatchAgentMessage(core.ctx, record.AgentId, msg, route)
+				for _, produced := range drainReplayAgents(agents) {
+					report.ReplayedOutbound++
+					report.ReplayedOutboundByType[payloadName(produced.msg.ProtoReflect(), "payload")]++
+					if output != nil {
+						output.RecordOutbound(produced.agentID, produced.msg)
+					}
+				}
+
+			case pb.JournalDirection_JOURNAL_DIRECTION_OUTBOUND:
+				if msg := record.GetCoreMessage(); msg != nil {
+					report.RecordedOutbound++
+					report.RecordedOutboundByType[payloadName(msg.ProtoReflect(), "payload")]++
+				}
+			}
+		}
+
+		report.Truncated = report.Truncated || reader.Truncated
+		reader.Close()
+	}
+
+	for agentID := range agents {
+		report.Agents = append(report.Agents, agentID)
+	}
+	sort.Strings(report.Agents)
+	report.Duration = time.Since(start)
+
+	return report, nil
+}
+
+// registerReplayAgent registra un Agent simulado cuyo SendCh captura los envíos.
+func (c *Core) registerReplayAgent(agentID string) *AgentConnection {
+	agentCtx, agentCancel := context.WithCancel(c.ctx)
+	conn := &AgentConnection{
+		AgentID:   agentID,
+		SendCh:    make(chan *pb.CoreMessage, 1000),
+		ctx:       agentCtx,
+		cancel:    agentCancel,
+		createdAt: c.now(),
+	}
+	c.registerAgent(agentID, conn)
+	return conn
+}
+
+// readReplayHeader retorna el arrival_ms del primer registro y el primer registro
+// CONFIG de los segmentos (nil si no hay, ej. journals anteriores al registro CONFIG).
+func readReplayHeader(segments []string) (int64, []byte, error) {
+	var firstArrivalMs int64
+	for _, path := range segments {
+		reader, err := journal.OpenSegment(path)
+		if err != nil {
+			return 0, nil, err
+		}
+		for {
+			record, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				reader.Close()
+				return 0, nil, err
+			}
+			if firstArrivalMs == 0 {
+				firstArrivalMs = record.ArrivalMs
+			}
+			if record.Direction == pb.JournalDirection_JOURNAL_DIRECTION_CONFIG {
+				reader.Close()
+				return firstArrivalMs, record.GetCoreConfig(), nil
+			}
+		}
+		reader.Close()
+	}
+	return firstArrivalMs, nil, nil
+}
+
+// replayClock reproduce la hora de llegada de los registros (coreClock del replay).
+//
+// La hora nunca retrocede: un arrival_ms menor al anterior (reloj del host ajustado)
+// mantiene la hora previa.
+type replayClock struct {
+	mu      sync.Mutex
+	nowMs   int64
+	counter uint64
+}
+
+func (c *replayClock) advance(arrivalMs int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if arrivalMs > c.nowMs {
+		c.nowMs = arrivalMs
+	}
+}
+
+func (c *replayClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.UnixMilli(c.nowMs)
+}
+
+// NewID genera un UUIDv7 con la hora del registro y un contador en lugar de bytes
+// aleatorios: el mismo journal produce los mismos command_id.
+func (c *replayClock) NewID() string {
+	c.mu.Lock()
+	c.counter++
+	ts, counter := c.nowMs, c.counter
+	c.mu.Unlock()
+
+	uuid := make([]byte, 16)
+	binary.BigEndian.PutUint64(uuid[0:8], uint64(ts<<16))
+	binary.BigEndian.PutUint64(uuid[8:16], counter)
+	uuid[6] = 0x70
+	uuid[8] = (uuid[8] & 0x3F) | 0x80
+
+	return fmt.Sprintf("%08x-%04x-%04x-%04x-%012x",
+		binary.BigEndian.Uint32(uuid[0:4]),
+		binary.BigEndian.Uint16(uuid[4:6]),
+		binary.BigEndian.Uint16(uuid[6:8]),
+		binary.BigEndian.Uint16(uuid[8:10]),
+		uuid[10:16],
+	)
+}
+
+type replayOutbound struct {
+	agentID string
+	msg     *pb.CoreMessage
+}
+
+// drainReplayAgents retorna los CoreMessage encolados, ordenados por agent_id.
+func drainReplayAgents(agents map[string]*AgentConnection) []replayOutbound {
+	agentIDs := make([]string, 0, len(agents))
+	for agentID := range agents {
+		agentIDs = append(agentIDs, agentID)
+	}
+	sort.Strings(agentIDs)
+
+	var produced []replayOutbound
+	for _, agentID := range agentIDs {
+		conn := agents[agentID]
+		for drained := false; !drained; {
+			select {
+			case msg := <-conn.SendCh:
+				produced = append(produced, replayOutbound{agentID: agentID, msg: msg})
+			default:
+				drained = true
+			}
+		}
+	}
+	return produced
+}
+
+// payloadName retorna el nombre del campo seteado en el oneof indicado.
+func payloadName(msg protoreflect.Message, oneof string) string {
+	descriptor := msg.Descriptor().Oneofs().ByName(protoreflect.Name(oneof))
+	if descriptor == nil {
+		return "unknown"
+	}
+	if field := msg.WhichOneof(descriptor); field != nil {
+		return string(field.Name())
+	}
+	return "empty"
+}
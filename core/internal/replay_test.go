@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/core/internal/journal"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+func TestReplayClockIsDeterministic(t *testing.T) {
+	run := func() (ids []string, times []int64) {
+		clock := &replayClock{}
+		for _, arrivalMs := range []int64{1_700_000_000_000, 1_700_000_000_500, 1_700_000_000_400} {
+			clock.advance(arrivalMs)
+			times = append(times, clock.Now().UnixMilli())
+			ids = append(ids, clock.NewID(), clock.NewID())
+		}
+		return ids, times
+	}
+
+	ids, times := run()
+	idsAgain, timesAgain := run()
+	assert.Equal(t, ids, idsAgain)
+	assert.Equal(t, times, timesAgain)
+
+	// La hora nunca retrocede y los IDs son UUIDv7 únicos
+	assert.Equal(t, []int64{1_700_000_000_000, 1_700_000_000_500, 1_700_000_000_500}, times)
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		require.Len(t, id, 36)
+		assert.Equal(t, byte('7'), id[14])
+		assert.False(t, seen[id])
+		seen[id] = true
+	}
+}
+
+func TestConfigJournalSnapshotOmitsSecrets(t *testing.T) {
+	config := &Config{
+		CanonicalSymbols: []string{"XAUUSD"},
+		DedupeTTL:        time.Hour,
+		PostgresPassword: "secret",
+		Security:         SecurityConfig{AgentTokenSecret: "token-secret", AllowlistStrict: true},
+		HTTPGateway:      HTTPGatewayConfig{Enabled: true, APIKeys: []string{"key"}},
+		Notifier:         NotifierConfig{Enabled: true, Rules: []NotifierRule{{Name: "ops", URLs: []string{"https://hooks"}}}},
+	}
+
+	data, err := config.journalSnapshot()
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.NotContains(t, string(data), "hooks")
+
+	restored, err := configFromJournalSnapshot(data)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"XAUUSD"}, restored.CanonicalSymbols)
+	assert.Equal(t, time.Hour, restored.DedupeTTL)
+	assert.True(t, restored.Security.AllowlistStrict)
+	assert.Empty(t, restored.HTTPGateway.APIKeys)
+
+	// El original no se modifica
+	assert.Equal(t, "secret", config.PostgresPassword)
+	assert.Len(t, config.Notifier.Rules, 1)
+}
+
+func TestReplayRequiresRecordedConfig(t *testing.T) {
+	dir := t.TempDir()
+	w, err := journal.Open(journal.Options{Dir: dir})
+	require.NoError(t, err)
+	w.RecordInbound("agent-1", &pb.AgentMessage{AgentId: "agent-1"})
+	require.NoError(t, w.Close())
+
+	segments, err := journal.ListSegments(dir)
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+
+	// Sin registro CONFIG no se cae a la config actual de ETCD
+	_, err = Replay(context.Background(), ReplayOptions{Segments: []string{segments[0].Path}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no config record")
+}
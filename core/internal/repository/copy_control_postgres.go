@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Habilitación de copia por cuenta (i8).
+
+const accountCopyControlColumns = `account_id, copy_enabled, reason, updated_by, updated_at_ms`
+
+type postgresAccountCopyControlRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresAccountCopyControlRepo) Upsert(ctx context.Context, control *domain.AccountCopyControl) error {
+	query := `
+		INSERT INTO echo.account_copy_control (` + accountCopyControlColumns + `)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id) DO UPDATE SET
+			copy_enabled = EXCLUDED.copy_enabled,
+			reason = EXCLUDED.reason,
+			updated_by = EXCLUDED.updated_by,
+			updated_at_ms = EXCLUDED.updated_at_ms
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		control.AccountID,
+		control.CopyEnabled,
+		control.Reason,
+		control.UpdatedBy,
+		control.UpdatedAtMs,
+	); err != nil {
+		return fmt.Errorf("failed to upsert copy control %s: %w", control.AccountID, err)
+	}
+	return nil
+}
+
+func (r *postgresAccountCopyControlRepo) List(ctx context.Context) ([]*domain.AccountCopyControl, error) {
+	return listAccountCopyControl(ctx, r.db,
+		`SELECT `+accountCopyControlColumns+` FROM echo.account_copy_control ORDER BY account_id ASC`,
+	)
+}
+
+// listAccountCopyControl es común a PostgreSQL y SQLite (copy_enabled INTEGER 0/1 escanea a bool).
+func listAccountCopyControl(ctx context.Context, db *sql.DB, query string) ([]*domain.AccountCopyControl, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query copy control: %w", err)
+	}
+	defer rows.Close()
+
+	var controls []*domain.AccountCopyControl
+	for rows.Next() {
+		var control domain.AccountCopyControl
+		if err := rows.Scan(
+			&control.AccountID,
+			&control.CopyEnabled,
+			&control.Reason,
+			&control.UpdatedBy,
+			&control.UpdatedAtMs,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan copy control: %w", err)
+		}
+		controls = append(controls, &control)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate copy control: %w", err)
+	}
+	return controls, nil
+}
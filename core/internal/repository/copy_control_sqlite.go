@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// sqliteAccountCopyControlRepo (i8)
+// ===========================================================================
+
+type sqliteAccountCopyControlRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteAccountCopyControlRepo) Upsert(ctx context.Context, control *domain.AccountCopyControl) error {
+	query := `
+		INSERT INTO account_copy_control (` + accountCopyControlColumns + `)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (account_id) DO UPDATE SET
+			copy_enabled = excluded.copy_enabled,
+			reason = excluded.reason,
+			updated_by = excluded.updated_by,
+			updated_at_ms = excluded.updated_at_ms
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		control.AccountID,
+		control.CopyEnabled,
+		control.Reason,
+		control.UpdatedBy,
+		control.UpdatedAtMs,
+	); err != nil {
+		return fmt.Errorf("failed to upsert copy control %s: %w", control.AccountID, err)
+	}
+	return nil
+}
+
+func (r *sqliteAccountCopyControlRepo) List(ctx context.Context) ([]*domain.AccountCopyControl, error) {
+	return listAccountCopyControl(ctx, r.db,
+		`SELECT `+accountCopyControlColumns+` FROM account_copy_control ORDER BY account_id ASC`,
+	)
+}
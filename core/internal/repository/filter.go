@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// whereBuilder arma la cláusula WHERE de los listados filtrados (i8).
+//
+// Las condiciones usan %s como placeholder: "$n" en PostgreSQL, "?" en SQLite.
+type whereBuilder struct {
+	postgres   bool
+	conditions []string
+	args       []interface{}
+}
+
+func (w *whereBuilder) add(condition string, arg interface{}) {
+	w.args = append(w.args, arg)
+	placeholder := "?"
+	if w.postgres {
+		placeholder = fmt.Sprintf("$%d", len(w.args))
+	}
+	w.conditions = append(w.conditions, fmt.Sprintf(condition, placeholder))
+}
+
+// createdRange agrega [fromMs, toMs) sobre created_at (TIMESTAMPTZ en PostgreSQL, ms en SQLite).
+func (w *whereBuilder) createdRange(fromMs, toMs int64) {
+	condition := "created_at %s %%s"
+	if w.postgres {
+		condition = "created_at %s to_timestamp(%%s / 1000.0)"
+	}
+	if fromMs > 0 {
+		w.add(fmt.Sprintf(condition, ">="), fromMs)
+	}
+	if toMs > 0 {
+		w.add(fmt.Sprintf(condition, "<"), toMs)
+	}
+}
+
+func (w *whereBuilder) clause() string {
+	if len(w.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(w.conditions, " AND ")
+}
+
+// limitOffset agrega LIMIT/OFFSET y retorna su fragmento SQL.
+func (w *whereBuilder) limitOffset(limit, offset int) string {
+	w.args = append(w.args, limit, offset)
+	if w.postgres {
+		return fmt.Sprintf("LIMIT $%d OFFSET $%d", len(w.args)-1, len(w.args))
+	}
+	return "LIMIT ? OFFSET ?"
+}
+
+func tradeFilterWhere(filter domain.TradeFilter, postgres bool) *whereBuilder {
+	where := &whereBuilder{postgres: postgres}
+	if filter.Status != "" {
+		where.add("status = %s", filter.Status)
+	}
+	if filter.MasterAccountID != "" {
+		where.add("master_account_id = %s", filter.MasterAccountID)
+	}
+	if filter.Symbol != "" {
+		where.add("symbol = %s", filter.Symbol)
+	}
+	where.createdRange(filter.CreatedFromMs, filter.CreatedToMs)
+	return where
+}
+
+func executionFilterWhere(filter domain.ExecutionFilter, postgres bool) *whereBuilder {
+	where := &whereBuilder{postgres: postgres}
+	if filter.TradeID != "" {
+		where.add("trade_id = %s", filter.TradeID)
+	}
+	if filter.SlaveAccountID != "" {
+		where.add("slave_account_id = %s", filter.SlaveAccountID)
+	}
+	if filter.Success != nil {
+		where.add("success = %s", *filter.Success)
+	}
+	where.createdRange(filter.CreatedFromMs, filter.CreatedToMs)
+	return where
+}
+
+// createdInRange aplica [fromMs, toMs) en memoria (0 = sin límite).
+func createdInRange(createdAt time.Time, fromMs, toMs int64) bool {
+	ms := createdAt.UnixMilli()
+	if fromMs > 0 && ms < fromMs {
+		return false
+	}
+	if toMs > 0 && ms >= toMs {
+		return false
+	}
+	return true
+}
+
+func matchTradeFilter(trade *domain.Trade, filter domain.TradeFilter) bool {
+	if filter.Status != "" && trade.Status != filter.Status {
+		return false
+	}
+	if filter.MasterAccountID != "" && trade.MasterAccountID != filter.MasterAccountID {
+		return false
+	}
+	if filter.Symbol != "" && trade.Symbol != filter.Symbol {
+		return false
+	}
+	return createdInRange(trade.CreatedAt, filter.CreatedFromMs, filter.CreatedToMs)
+}
+
+func matchExecutionFilter(exec *domain.Execution, filter domain.ExecutionFilter) bool {
+	if filter.TradeID != "" && exec.TradeID != filter.TradeID {
+		return false
+	}
+	if filter.SlaveAccountID != "" && exec.SlaveAccountID != filter.SlaveAccountID {
+		return false
+	}
+	if filter.Success != nil && exec.Success != *filter.Success {
+		return false
+	}
+	return createdInRange(exec.CreatedAt, filter.CreatedFromMs, filter.CreatedToMs)
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// tradeLifecycleRepo implementa domain.TradeLifecycleRepository (i8).
+//
+// Compone el ciclo de vida desde los repositorios de executions, modifies y closes,
+// por lo que es común a todos los backends.
+type tradeLifecycleRepo struct {
+	executionRepo domain.ExecutionRepository
+	modifyRepo    domain.ModifyRepository
+	closeRepo     domain.CloseRepository
+}
+
+// NewTradeLifecycleRepository crea el repositorio de ciclo de vida por trade×slave.
+func NewTradeLifecycleRepository(
+	executionRepo domain.ExecutionRepository,
+	modifyRepo domain.ModifyRepository,
+	closeRepo domain.CloseRepository,
+) domain.TradeLifecycleRepository {
+	return &tradeLifecycleRepo{
+		executionRepo: executionRepo,
+		modifyRepo:    modifyRepo,
+		closeRepo:     closeRepo,
+	}
+}
+
+// GetLifecycle obtiene el ciclo de vida de un trade en un slave.
+func (r *tradeLifecycleRepo) GetLifecycle(ctx context.Context, tradeID, slaveAccountID string) (*domain.TradeLifecycle, error) {
+	lifecycles, err := r.ListByTrade(ctx, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, lifecycle := range lifecycles {
+		if lifecycle.SlaveAccountID == slaveAccountID {
+			return lifecycle, nil
+		}
+	}
+	return nil, nil
+}
+
+// ListByTrade obtiene el ciclo de vida de un trade en cada slave.
+func (r *tradeLifecycleRepo) ListByTrade(ctx context.Context, tradeID string) ([]*domain.TradeLifecycle, error) {
+	executions, err := r.executionRepo.GetByTradeID(ctx, tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get executions for trade %s: %w", tradeID, err)
+	}
+	closes, err := r.closeRepo.GetByTradeID(ctx, tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get closes for trade %s: %w", tradeID, err)
+	}
+	modifies, err := r.modifyRepo.GetByTradeID(ctx, tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get modifies for trade %s: %w", tradeID, err)
+	}
+
+	bySlave := make(map[string]*domain.TradeLifecycle)
+	lifecycleFor := func(slaveAccountID string) *domain.TradeLifecycle {
+		lifecycle, ok := bySlave[slaveAccountID]
+		if !ok {
+			lifecycle = &domain.TradeLifecycle{TradeID: tradeID, SlaveAccountID: slaveAccountID}
+			bySlave[slaveAccountID] = lifecycle
+		}
+		return lifecycle
+	}
+
+	// Fill de apertura: la última ejecución exitosa; si ninguna lo fue, la última registrada
+	for _, exec := range executions {
+		lifecycle := lifecycleFor(exec.SlaveAccountID)
+		if lifecycle.Open == nil || exec.Success || !lifecycle.Open.Success {
+			lifecycle.Open = exec
+		}
+	}
+	for _, close := range closes {
+		lifecycle := lifecycleFor(close.SlaveAccountID)
+		lifecycle.Closes = append(lifecycle.Closes, close)
+	}
+
+	lifecycles := make([]*domain.TradeLifecycle, 0, len(bySlave))
+	for _, lifecycle := range bySlave {
+		// Los cambios de SL/TP son del trade del master: aplican a cada slave
+		lifecycle.Modifies = modifies
+		summarizeLifecycle(lifecycle)
+		lifecycles = append(lifecycles, lifecycle)
+	}
+	sort.Slice(lifecycles, func(i, j int) bool {
+		return lifecycles[i].SlaveAccountID < lifecycles[j].SlaveAccountID
+	})
+
+	return lifecycles, nil
+}
+
+// summarizeLifecycle ordena los cierres y calcula estado, acumulados y holding time.
+//
+// Un cierre exitoso sin remaining_volume (EAs previos a i8) se considera final.
+func summarizeLifecycle(lifecycle *domain.TradeLifecycle) {
+	sort.SliceStable(lifecycle.Closes, func(i, j int) bool {
+		return lifecycle.Closes[i].ClosedAtMs < lifecycle.Closes[j].ClosedAtMs
+	})
+
+	var final *domain.Close
+	partial := false
+	for _, close := range lifecycle.Closes {
+		if !close.Success {
+			continue
+		}
+		if close.ClosedVolume != nil {
+			lifecycle.ClosedVolume += *close.ClosedVolume
+		}
+		if close.Profit != nil {
+			lifecycle.Profit += *close.Profit
+		}
+		if close.Commission != nil {
+			lifecycle.Commission += *close.Commission
+		}
+		if close.Swap != nil {
+			lifecycle.Swap += *close.Swap
+		}
+		if close.IsPartial() {
+			partial = true
+		} else {
+			final = close
+		}
+	}
+
+	switch {
+	case final != nil:
+		lifecycle.Status = domain.TradeLifecycleStatusClosed
+	case partial:
+		lifecycle.Status = domain.TradeLifecycleStatusPartiallyClosed
+	case lifecycle.Open == nil:
+		lifecycle.Status = domain.TradeLifecycleStatusPending
+	case !lifecycle.Open.Success:
+		lifecycle.Status = domain.TradeLifecycleStatusRejected
+	default:
+		lifecycle.Status = domain.TradeLifecycleStatusOpen
+	}
+
+	if final != nil && lifecycle.Open != nil && !lifecycle.Open.CreatedAt.IsZero() {
+		if holding := final.ClosedAtMs - lifecycle.Open.CreatedAt.UnixMilli(); holding > 0 {
+			lifecycle.HoldingTimeMs = holding
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Liveness de Agents y cuentas según heartbeats (i8).
+//
+// Upsert reemplaza en una transacción la fila del Agent y las de sus pipes: la
+// tabla de cuentas refleja siempre el último heartbeat.
+
+const agentLivenessColumns = `
+	agent_id, status, last_seen_at_ms, heartbeat_interval_ms, pending_commands,
+	pending_bytes, stale_since_ms, updated_at_ms
+`
+
+const accountLivenessColumns = `
+	agent_id, pipe_name, account_id, pipe_role, status, codec, last_seen_at_ms
+`
+
+type postgresAgentLivenessRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresAgentLivenessRepo) Upsert(ctx context.Context, liveness *domain.AgentLiveness) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	query := `
+		INSERT INTO echo.agent_liveness (` + agentLivenessColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (agent_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			last_seen_at_ms = EXCLUDED.last_seen_at_ms,
+			heartbeat_interval_ms = EXCLUDED.heartbeat_interval_ms,
+			pending_commands = EXCLUDED.pending_commands,
+			pending_bytes = EXCLUDED.pending_bytes,
+			stale_since_ms = EXCLUDED.stale_since_ms,
+			updated_at_ms = EXCLUDED.updated_at_ms
+	`
+	if _, err = tx.ExecContext(ctx, query,
+		liveness.AgentID,
+		liveness.Status,
+		liveness.LastSeenAtMs,
+		liveness.HeartbeatIntervalMs,
+		liveness.PendingCommands,
+		liveness.PendingBytes,
+		liveness.StaleSinceMs,
+		liveness.UpdatedAtMs,
+	); err != nil {
+		return fmt.Errorf("failed to upsert agent liveness %s: %w", liveness.AgentID, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM echo.account_liveness WHERE agent_id = $1`, liveness.AgentID); err != nil {
+		return fmt.Errorf("failed to clear account liveness: %w", err)
+	}
+
+	insert := `
+		INSERT INTO echo.account_liveness (` + accountLivenessColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	for _, account := range liveness.Accounts {
+		if _, err = tx.ExecContext(ctx, insert,
+			liveness.AgentID,
+			account.PipeName,
+			account.AccountID,
+			account.PipeRole,
+			account.Status,
+			account.Codec,
+			account.LastSeenAtMs,
+		); err != nil {
+			return fmt.Errorf("failed to insert account liveness %s: %w", account.PipeName, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit liveness tx: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresAgentLivenessRepo) List(ctx context.Context) ([]*domain.AgentLiveness, error) {
+	return listAgentLiveness(ctx, r.db,
+		`SELECT `+agentLivenessColumns+` FROM echo.agent_liveness ORDER BY agent_id ASC`,
+		`SELECT `+accountLivenessColumns+` FROM echo.account_liveness ORDER BY agent_id ASC, pipe_name ASC`,
+	)
+}
+
+func (r *postgresAgentLivenessRepo) PurgeOlderThan(ctx context.Context, cutoffMs int64) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM echo.agent_liveness WHERE last_seen_at_ms < $1`, cutoffMs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge agent liveness: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// listAgentLiveness es común a PostgreSQL y SQLite (mismas columnas y tipos).
+func listAgentLiveness(ctx context.Context, db *sql.DB, agentsQuery, accountsQuery string) ([]*domain.AgentLiveness, error) {
+	rows, err := db.QueryContext(ctx, agentsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query agent liveness: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []*domain.AgentLiveness
+	byID := make(map[string]*domain.AgentLiveness)
+	for rows.Next() {
+		var liveness domain.AgentLiveness
+		if err := rows.Scan(
+			&liveness.AgentID,
+			&liveness.Status,
+			&liveness.LastSeenAtMs,
+			&liveness.HeartbeatIntervalMs,
+			&liveness.PendingCommands,
+			&liveness.PendingBytes,
+			&liveness.StaleSinceMs,
+			&liveness.UpdatedAtMs,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan agent liveness: %w", err)
+		}
+		agents = append(agents, &liveness)
+		byID[liveness.AgentID] = &liveness
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	accountRows, err := db.QueryContext(ctx, accountsQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account liveness: %w", err)
+	}
+	defer accountRows.Close()
+
+	for accountRows.Next() {
+		var account domain.AccountLiveness
+		if err := accountRows.Scan(
+			&account.AgentID,
+			&account.PipeName,
+			&account.AccountID,
+			&account.PipeRole,
+			&account.Status,
+			&account.Codec,
+			&account.LastSeenAtMs,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan account liveness: %w", err)
+		}
+		if agent, ok := byID[account.AgentID]; ok {
+			agent.Accounts = append(agent.Accounts, &account)
+		}
+	}
+	if err := accountRows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return agents, nil
+}
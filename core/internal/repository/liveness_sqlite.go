@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// sqliteAgentLivenessRepo (i8)
+// ===========================================================================
+
+type sqliteAgentLivenessRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteAgentLivenessRepo) Upsert(ctx context.Context, liveness *domain.AgentLiveness) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	query := `
+		INSERT INTO agent_liveness (` + agentLivenessColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (agent_id) DO UPDATE SET
+			status = excluded.status,
+			last_seen_at_ms = excluded.last_seen_at_ms,
+			heartbeat_interval_ms = excluded.heartbeat_interval_ms,
+			pending_commands = excluded.pending_commands,
+			pending_bytes = excluded.pending_bytes,
+			stale_since_ms = excluded.stale_since_ms,
+			updated_at_ms = excluded.updated_at_ms
+	`
+	if _, err = tx.ExecContext(ctx, query,
+		liveness.AgentID,
+		liveness.Status,
+		liveness.LastSeenAtMs,
+		liveness.HeartbeatIntervalMs,
+		liveness.PendingCommands,
+		liveness.PendingBytes,
+		liveness.StaleSinceMs,
+		liveness.UpdatedAtMs,
+	); err != nil {
+		return fmt.Errorf("failed to upsert agent liveness %s: %w", liveness.AgentID, err)
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM account_liveness WHERE agent_id = ?`, liveness.AgentID); err != nil {
+		return fmt.Errorf("failed to clear account liveness: %w", err)
+	}
+
+	insert := `
+		INSERT INTO account_liveness (` + accountLivenessColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, account := range liveness.Accounts {
+		if _, err = tx.ExecContext(ctx, insert,
+			liveness.AgentID,
+			account.PipeName,
+			account.AccountID,
+			account.PipeRole,
+			account.Status,
+			account.Codec,
+			account.LastSeenAtMs,
+		); err != nil {
+			return fmt.Errorf("failed to insert account liveness %s: %w", account.PipeName, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit liveness tx: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteAgentLivenessRepo) List(ctx context.Context) ([]*domain.AgentLiveness, error) {
+	return listAgentLiveness(ctx, r.db,
+		`SELECT `+agentLivenessColumns+` FROM agent_liveness ORDER BY agent_id ASC`,
+		`SELECT `+accountLivenessColumns+` FROM account_liveness ORDER BY agent_id ASC, pipe_name ASC`,
+	)
+}
+
+func (r *sqliteAgentLivenessRepo) PurgeOlderThan(ctx context.Context, cutoffMs int64) (int, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM agent_liveness WHERE last_seen_at_ms < ?`, cutoffMs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge agent liveness: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(deleted), nil
+}
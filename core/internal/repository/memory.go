@@ -0,0 +1,1710 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/domain/handshake"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+// DefaultMemoryDedupeTTL replica el intervalo de echo.cleanup_dedupe_ttl().
+const DefaultMemoryDedupeTTL = time.Hour
+
+// MemoryFactory implementa domain.RepositoryFactory sin dependencias externas.
+//
+// Replica la semántica de PostgresFactory (claves únicas, FKs a trades, guards de
+// reported_at_ms/timestamp_ms, historial append-only de políticas) para tests y
+// desarrollo local. El estado se pierde al terminar el proceso.
+type MemoryFactory struct {
+	*changeHub
+
+	mu sync.Mutex
+
+	dedupeTTL time.Duration
+	now       func() time.Time
+
+	// Repositorios inicializados lazy
+	trades          *memoryTradeStore
+	tradeRepo       domain.TradeRepository
+	executionRepo   domain.ExecutionRepository
+	dedupeRepo      domain.DedupeRepository
+	closeRepo       domain.CloseRepository
+	modifyRepo      domain.ModifyRepository
+	lifecycleRepo   domain.TradeLifecycleRepository
+	outboxRepo      domain.OutboxRepository
+	livenessRepo    domain.AgentLivenessRepository
+	ownershipRepo   domain.AccountOwnershipRepository
+	copyControlRepo domain.AccountCopyControlRepository
+	deadLetterRepo  domain.NotificationDeadLetterRepository
+	correlationSvc  domain.CorrelationService
+	symbolRepo      domain.SymbolRepository
+	symbolSpecRepo  domain.SymbolSpecRepository
+	symbolQuoteRepo domain.SymbolQuoteRepository
+	overrideRepo    domain.SymbolOverrideRepository
+	proposalRepo    domain.SymbolProposalRepository
+	riskPolicyRepo  domain.RiskPolicyRepository
+	handshakeRepo   domain.HandshakeEvaluationRepository
+}
+
+// NewMemoryFactory crea un factory de repositorios en memoria.
+//
+// dedupeTTL define la antigüedad mínima de entries terminales eliminadas por
+// CleanupTTL; <= 0 usa DefaultMemoryDedupeTTL.
+//
+// Uso:
+//
+//	factory := repository.NewMemoryFactory(0)
+//	tradeRepo := factory.TradeRepository()
+func NewMemoryFactory(dedupeTTL time.Duration) *MemoryFactory {
+	if dedupeTTL <= 0 {
+		dedupeTTL = DefaultMemoryDedupeTTL
+	}
+	return &MemoryFactory{
+		changeHub: &changeHub{},
+		dedupeTTL: dedupeTTL,
+		now:       time.Now,
+		trades:    &memoryTradeStore{byID: make(map[string]*domain.Trade)},
+	}
+}
+
+// SetClock reemplaza la hora usada por dedupe y vigencia de políticas.
+//
+// Lo usa el replay del journal; debe llamarse antes de pedir los repositorios.
+func (f *MemoryFactory) SetClock(now func() time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// TradeRepository retorna el repositorio de trades.
+func (f *MemoryFactory) TradeRepository() domain.TradeRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.tradeRepo == nil {
+		f.tradeRepo = &memoryTradeRepo{store: f.trades}
+	}
+	return f.tradeRepo
+}
+
+// ExecutionRepository retorna el repositorio de executions.
+func (f *MemoryFactory) ExecutionRepository() domain.ExecutionRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.executionRepo == nil {
+		f.executionRepo = &memoryExecutionRepo{
+			trades: f.trades,
+			byID:   make(map[string]*domain.Execution),
+		}
+	}
+	return f.executionRepo
+}
+
+// DedupeRepository retorna el repositorio de dedupe.
+func (f *MemoryFactory) DedupeRepository() domain.DedupeRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.dedupeRepo == nil {
+		f.dedupeRepo = &memoryDedupeRepo{
+			ttl:     f.dedupeTTL,
+			now:     f.now,
+			entries: make(map[string]*domain.DedupeEntry),
+		}
+	}
+	return f.dedupeRepo
+}
+
+// CloseRepository retorna el repositorio de closes.
+func (f *MemoryFactory) CloseRepository() domain.CloseRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closeRepo == nil {
+		f.closeRepo = &memoryCloseRepo{
+			trades: f.trades,
+			byID:   make(map[string]*domain.Close),
+		}
+	}
+	return f.closeRepo
+}
+
+// ModifyRepository retorna el repositorio de cambios de SL/TP (i8).
+func (f *MemoryFactory) ModifyRepository() domain.ModifyRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.modifyRepo == nil {
+		f.modifyRepo = &memoryModifyRepo{trades: f.trades}
+	}
+	return f.modifyRepo
+}
+
+// TradeLifecycleRepository retorna el repositorio de ciclo de vida por trade×slave (i8).
+func (f *MemoryFactory) TradeLifecycleRepository() domain.TradeLifecycleRepository {
+	execRepo := f.ExecutionRepository()
+	modifyRepo := f.ModifyRepository()
+	closeRepo := f.CloseRepository()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.lifecycleRepo == nil {
+		f.lifecycleRepo = NewTradeLifecycleRepository(execRepo, modifyRepo, closeRepo)
+	}
+	return f.lifecycleRepo
+}
+
+// OutboxRepository retorna el outbox transaccional de comandos (i8).
+func (f *MemoryFactory) OutboxRepository() domain.OutboxRepository {
+	dedupeRepo := f.DedupeRepository().(*memoryDedupeRepo)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.outboxRepo == nil {
+		f.outboxRepo = &memoryOutboxRepo{
+			trades: f.trades,
+			dedupe: dedupeRepo,
+			byID:   make(map[string]*domain.OutboxCommand),
+		}
+	}
+	return f.outboxRepo
+}
+
+// AgentLivenessRepository retorna el estado de liveness de Agents y cuentas (i8).
+func (f *MemoryFactory) AgentLivenessRepository() domain.AgentLivenessRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.livenessRepo == nil {
+		f.livenessRepo = &memoryAgentLivenessRepo{byID: make(map[string]*domain.AgentLiveness)}
+	}
+	return f.livenessRepo
+}
+
+// AccountOwnershipRepository retorna el ownership persistido de cuentas (i8).
+func (f *MemoryFactory) AccountOwnershipRepository() domain.AccountOwnershipRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ownershipRepo == nil {
+		f.ownershipRepo = &memoryAccountOwnershipRepo{byID: make(map[string]*domain.AccountOwnership)}
+	}
+	return f.ownershipRepo
+}
+
+// AccountCopyControlRepository retorna la habilitación de copia por cuenta (i8).
+func (f *MemoryFactory) AccountCopyControlRepository() domain.AccountCopyControlRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.copyControlRepo == nil {
+		f.copyControlRepo = &memoryAccountCopyControlRepo{byID: make(map[string]*domain.AccountCopyControl)}
+	}
+	return f.copyControlRepo
+}
+
+// NotificationDeadLetterRepository retorna los webhooks no entregados (i8).
+func (f *MemoryFactory) NotificationDeadLetterRepository() domain.NotificationDeadLetterRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.deadLetterRepo == nil {
+		f.deadLetterRepo = &memoryNotificationDeadLetterRepo{}
+	}
+	return f.deadLetterRepo
+}
+
+// CorrelationService retorna el servicio de correlación.
+func (f *MemoryFactory) CorrelationService() domain.CorrelationService {
+	execRepo := f.ExecutionRepository()
+	dedupeRepo := f.DedupeRepository()
+	closeRepo := f.CloseRepository()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.correlationSvc == nil {
+		f.correlationSvc = NewCorrelationService(execRepo, dedupeRepo, closeRepo)
+	}
+	return f.correlationSvc
+}
+
+// SymbolRepository retorna el repositorio de símbolos (i3).
+func (f *MemoryFactory) SymbolRepository() domain.SymbolRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.symbolRepoLocked()
+}
+
+// symbolRepoLocked inicializa el repositorio de mapeos; requiere f.mu tomado.
+func (f *MemoryFactory) symbolRepoLocked() domain.SymbolRepository {
+	if f.symbolRepo == nil {
+		f.symbolRepo = &memorySymbolRepo{accounts: make(map[string]map[string]*memorySymbolMapping)}
+	}
+	return f.symbolRepo
+}
+
+// SymbolSpecRepository retorna el repositorio de especificaciones de símbolos.
+func (f *MemoryFactory) SymbolSpecRepository() domain.SymbolSpecRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.symbolSpecRepo == nil {
+		f.symbolSpecRepo = &memorySymbolSpecRepo{accounts: make(map[string]map[string]*domain.AccountSymbolSpec)}
+	}
+	return f.symbolSpecRepo
+}
+
+// SymbolQuoteRepository retorna el repositorio de snapshots de precios.
+func (f *MemoryFactory) SymbolQuoteRepository() domain.SymbolQuoteRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.symbolQuoteRepo == nil {
+		f.symbolQuoteRepo = &memorySymbolQuoteRepo{latest: make(map[string]*pb.SymbolQuoteSnapshot)}
+	}
+	return f.symbolQuoteRepo
+}
+
+// SymbolOverrideRepository retorna los overrides manuales de mapeo de símbolos (i8).
+func (f *MemoryFactory) SymbolOverrideRepository() domain.SymbolOverrideRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.overrideRepo == nil {
+		f.overrideRepo = &memorySymbolOverrideRepo{
+			hub:       f.changeHub,
+			symbols:   f.symbolRepoLocked(),
+			byAccount: make(map[string]map[string]*domain.SymbolMappingOverride),
+		}
+	}
+	return f.overrideRepo
+}
+
+// SymbolProposalRepository retorna los mapeos sugeridos por el matcher de símbolos (i8).
+func (f *MemoryFactory) SymbolProposalRepository() domain.SymbolProposalRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.proposalRepo == nil {
+		f.proposalRepo = &memorySymbolProposalRepo{byAccount: make(map[string][]*domain.SymbolMatchProposal)}
+	}
+	return f.proposalRepo
+}
+
+// RiskPolicyRepository retorna el repositorio de políticas de riesgo.
+func (f *MemoryFactory) RiskPolicyRepository() domain.RiskPolicyRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.riskPolicyRepo == nil {
+		f.riskPolicyRepo = &memoryRiskPolicyRepo{
+			hub:     f.changeHub,
+			now:     f.now,
+			history: make(map[string][]*domain.RiskPolicyRevision),
+		}
+	}
+	return f.riskPolicyRepo
+}
+
+// HandshakeRepository retorna el repositorio de evaluaciones de handshake.
+func (f *MemoryFactory) HandshakeRepository() domain.HandshakeEvaluationRepository {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.handshakeRepo == nil {
+		f.handshakeRepo = &memoryHandshakeRepo{
+			hub:    f.changeHub,
+			latest: make(map[string]*handshake.Evaluation),
+		}
+	}
+	return f.handshakeRepo
+}
+
+// ===========================================================================
+// memoryTradeRepo
+// ===========================================================================
+
+// memoryTradeStore es compartido por trades, executions y closes para validar la FK trade_id.
+type memoryTradeStore struct {
+	mu    sync.RWMutex
+	byID  map[string]*domain.Trade
+	order []string // orden de inserción (created_at ASC)
+}
+
+func (s *memoryTradeStore) exists(tradeID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.byID[tradeID]
+	return ok
+}
+
+type memoryTradeRepo struct {
+	store *memoryTradeStore
+}
+
+func (r *memoryTradeRepo) Create(ctx context.Context, trade *domain.Trade) error {
+	if trade == nil {
+		return fmt.Errorf("failed to create trade: nil trade")
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.byID[trade.TradeID]; ok {
+		return fmt.Errorf("failed to create trade: duplicate trade_id %s", trade.TradeID)
+	}
+
+	stored := cloneTrade(trade)
+	now := time.Now()
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	r.store.byID[trade.TradeID] = stored
+	r.store.order = append(r.store.order, trade.TradeID)
+	return nil
+}
+
+func (r *memoryTradeRepo) GetByID(ctx context.Context, tradeID string) (*domain.Trade, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	trade, ok := r.store.byID[tradeID]
+	if !ok {
+		return nil, nil
+	}
+	return cloneTrade(trade), nil
+}
+
+func (r *memoryTradeRepo) GetByMasterTicket(ctx context.Context, masterAccountID string, masterTicket int32) (*domain.Trade, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	// Recorrer en orden inverso: el más reciente primero (created_at DESC)
+	for i := len(r.store.order) - 1; i >= 0; i-- {
+		trade := r.store.byID[r.store.order[i]]
+		if trade.MasterAccountID == masterAccountID && trade.MasterTicket == masterTicket {
+			return cloneTrade(trade), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryTradeRepo) UpdateStatus(ctx context.Context, tradeID string, status domain.OrderStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	trade, ok := r.store.byID[tradeID]
+	if !ok {
+		return fmt.Errorf("trade not found: %s", tradeID)
+	}
+	trade.Status = status
+	trade.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *memoryTradeRepo) List(ctx context.Context, limit, offset int) ([]*domain.Trade, error) {
+	return r.list(func(*domain.Trade) bool { return true }, limit, offset), nil
+}
+
+func (r *memoryTradeRepo) ListByStatus(ctx context.Context, status domain.OrderStatus, limit, offset int) ([]*domain.Trade, error) {
+	return r.list(func(t *domain.Trade) bool { return t.Status == status }, limit, offset), nil
+}
+
+func (r *memoryTradeRepo) ListFiltered(ctx context.Context, filter domain.TradeFilter, limit, offset int) ([]*domain.Trade, error) {
+	return r.list(func(t *domain.Trade) bool { return matchTradeFilter(t, filter) }, limit, offset), nil
+}
+
+func (r *memoryTradeRepo) list(match func(*domain.Trade) bool, limit, offset int) []*domain.Trade {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var matched []*domain.Trade
+	for i := len(r.store.order) - 1; i >= 0; i-- {
+		trade := r.store.byID[r.store.order[i]]
+		if match(trade) {
+			matched = append(matched, trade)
+		}
+	}
+
+	var trades []*domain.Trade
+	for _, trade := range paginate(matched, limit, offset) {
+		trades = append(trades, cloneTrade(trade))
+	}
+	return trades
+}
+
+// ===========================================================================
+// memoryExecutionRepo
+// ===========================================================================
+
+type memoryExecutionRepo struct {
+	trades *memoryTradeStore
+
+	mu    sync.RWMutex
+	byID  map[string]*domain.Execution
+	order []*domain.Execution // orden de inserción (created_at ASC)
+}
+
+func (r *memoryExecutionRepo) Create(ctx context.Context, exec *domain.Execution) error {
+	if exec == nil {
+		return fmt.Errorf("failed to create execution: nil execution")
+	}
+	if !r.trades.exists(exec.TradeID) {
+		return fmt.Errorf("failed to create execution: trade not found: %s", exec.TradeID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[exec.ExecutionID]; ok {
+		return fmt.Errorf("failed to create execution: duplicate execution_id %s", exec.ExecutionID)
+	}
+	// ux_execution_trade_slave_ticket: único solo para tickets != 0
+	if exec.SlaveTicket != 0 {
+		for _, existing := range r.order {
+			if existing.TradeID == exec.TradeID && existing.SlaveAccountID == exec.SlaveAccountID && existing.SlaveTicket == exec.SlaveTicket {
+				return fmt.Errorf("failed to create execution: duplicate ticket %d for trade %s slave %s", exec.SlaveTicket, exec.TradeID, exec.SlaveAccountID)
+			}
+		}
+	}
+
+	stored := cloneExecution(exec)
+	stored.CreatedAt = time.Now()
+	r.byID[stored.ExecutionID] = stored
+	r.order = append(r.order, stored)
+	return nil
+}
+
+func (r *memoryExecutionRepo) GetByID(ctx context.Context, executionID string) (*domain.Execution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	exec, ok := r.byID[executionID]
+	if !ok {
+		return nil, nil
+	}
+	return cloneExecution(exec), nil
+}
+
+func (r *memoryExecutionRepo) GetByTradeID(ctx context.Context, tradeID string) ([]*domain.Execution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var execs []*domain.Execution
+	for _, exec := range r.order {
+		if exec.TradeID == tradeID {
+			execs = append(execs, cloneExecution(exec))
+		}
+	}
+	return execs, nil
+}
+
+func (r *memoryExecutionRepo) GetByTradeAndSlave(ctx context.Context, tradeID, slaveAccountID string) (*domain.Execution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.order) - 1; i >= 0; i-- {
+		exec := r.order[i]
+		if exec.TradeID == tradeID && exec.SlaveAccountID == slaveAccountID {
+			return cloneExecution(exec), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryExecutionRepo) GetTicketByTradeAndSlave(ctx context.Context, tradeID, slaveAccountID string) (int32, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.order) - 1; i >= 0; i-- {
+		exec := r.order[i]
+		if exec.TradeID == tradeID && exec.SlaveAccountID == slaveAccountID && exec.Success && exec.SlaveTicket != 0 {
+			return exec.SlaveTicket, nil
+		}
+	}
+	return 0, nil
+}
+
+func (r *memoryExecutionRepo) List(ctx context.Context, limit, offset int) ([]*domain.Execution, error) {
+	return r.list(func(*domain.Execution) bool { return true }, limit, offset), nil
+}
+
+func (r *memoryExecutionRepo) ListBySuccess(ctx context.Context, success bool, limit, offset int) ([]*domain.Execution, error) {
+	return r.list(func(e *domain.Execution) bool { return e.Success == success }, limit, offset), nil
+}
+
+func (r *memoryExecutionRepo) ListFiltered(ctx context.Context, filter domain.ExecutionFilter, limit, offset int) ([]*domain.Execution, error) {
+	return r.list(func(e *domain.Execution) bool { return matchExecutionFilter(e, filter) }, limit, offset), nil
+}
+
+func (r *memoryExecutionRepo) list(match func(*domain.Execution) bool, limit, offset int) []*domain.Execution {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.Execution
+	for i := len(r.order) - 1; i >= 0; i-- {
+		if match(r.order[i]) {
+			matched = append(matched, r.order[i])
+		}
+	}
+
+	var execs []*domain.Execution
+	for _, exec := range paginate(matched, limit, offset) {
+		execs = append(execs, cloneExecution(exec))
+	}
+	return execs
+}
+
+// ===========================================================================
+// memoryDedupeRepo
+// ===========================================================================
+
+type memoryDedupeRepo struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]*domain.DedupeEntry
+}
+
+func (r *memoryDedupeRepo) Upsert(ctx context.Context, entry *domain.DedupeEntry) error {
+	if entry == nil {
+		return fmt.Errorf("failed to upsert dedupe entry: nil entry")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	if existing, ok := r.entries[entry.TradeID]; ok {
+		existing.Status = entry.Status
+		existing.UpdatedAt = now
+		return nil
+	}
+	r.entries[entry.TradeID] = &domain.DedupeEntry{
+		TradeID:   entry.TradeID,
+		Status:    entry.Status,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	return nil
+}
+
+func (r *memoryDedupeRepo) Get(ctx context.Context, tradeID string) (*domain.DedupeEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	entry, ok := r.entries[tradeID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+func (r *memoryDedupeRepo) Exists(ctx context.Context, tradeID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.entries[tradeID]
+	return ok, nil
+}
+
+func (r *memoryDedupeRepo) UpdateStatus(ctx context.Context, tradeID string, status domain.OrderStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, ok := r.entries[tradeID]
+	if !ok {
+		return fmt.Errorf("dedupe entry not found: %s", tradeID)
+	}
+	entry.Status = status
+	entry.UpdatedAt = r.now()
+	return nil
+}
+
+func (r *memoryDedupeRepo) CleanupTTL(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Misma regla que echo.cleanup_dedupe_ttl(): solo estados terminales vencidos
+	cutoff := r.now().Add(-r.ttl)
+	deleted := 0
+	for tradeID, entry := range r.entries {
+		if entry.Status.IsTerminal() && entry.UpdatedAt.Before(cutoff) {
+			delete(r.entries, tradeID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ===========================================================================
+// memoryCloseRepo
+// ===========================================================================
+
+type memoryCloseRepo struct {
+	trades *memoryTradeStore
+
+	mu    sync.RWMutex
+	byID  map[string]*domain.Close
+	order []*domain.Close // orden de inserción (created_at ASC)
+}
+
+func (r *memoryCloseRepo) Create(ctx context.Context, close *domain.Close) error {
+	if close == nil {
+		return fmt.Errorf("failed to create close: nil close")
+	}
+	if !r.trades.exists(close.TradeID) {
+		return fmt.Errorf("failed to create close: trade not found: %s", close.TradeID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.byID[close.CloseID]; ok {
+		return fmt.Errorf("failed to create close: duplicate close_id %s", close.CloseID)
+	}
+
+	stored := cloneClose(close)
+	stored.CreatedAt = time.Now()
+	r.byID[stored.CloseID] = stored
+	r.order = append(r.order, stored)
+	return nil
+}
+
+func (r *memoryCloseRepo) GetByID(ctx context.Context, closeID string) (*domain.Close, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	close, ok := r.byID[closeID]
+	if !ok {
+		return nil, nil
+	}
+	return cloneClose(close), nil
+}
+
+func (r *memoryCloseRepo) GetByTradeID(ctx context.Context, tradeID string) ([]*domain.Close, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var closes []*domain.Close
+	for _, close := range r.order {
+		if close.TradeID == tradeID {
+			closes = append(closes, cloneClose(close))
+		}
+	}
+	return closes, nil
+}
+
+func (r *memoryCloseRepo) GetByTradeAndSlave(ctx context.Context, tradeID, slaveAccountID string) (*domain.Close, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.order) - 1; i >= 0; i-- {
+		close := r.order[i]
+		if close.TradeID == tradeID && close.SlaveAccountID == slaveAccountID {
+			return cloneClose(close), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryCloseRepo) List(ctx context.Context, limit, offset int) ([]*domain.Close, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reversed := make([]*domain.Close, 0, len(r.order))
+	for i := len(r.order) - 1; i >= 0; i-- {
+		reversed = append(reversed, r.order[i])
+	}
+
+	var closes []*domain.Close
+	for _, close := range paginate(reversed, limit, offset) {
+		closes = append(closes, cloneClose(close))
+	}
+	return closes, nil
+}
+
+// ===========================================================================
+// memoryModifyRepo (i8)
+// ===========================================================================
+
+type memoryModifyRepo struct {
+	trades *memoryTradeStore
+
+	mu    sync.RWMutex
+	order []*domain.Modify // orden de inserción
+}
+
+func (r *memoryModifyRepo) Create(ctx context.Context, modify *domain.Modify) error {
+	if modify == nil {
+		return fmt.Errorf("failed to create modify: nil modify")
+	}
+	if !r.trades.exists(modify.TradeID) {
+		return fmt.Errorf("failed to create modify: trade not found: %s", modify.TradeID)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.order {
+		if existing.ModifyID == modify.ModifyID {
+			return fmt.Errorf("failed to create modify: duplicate modify_id %s", modify.ModifyID)
+		}
+	}
+
+	stored := cloneModify(modify)
+	stored.CreatedAt = time.Now()
+	r.order = append(r.order, stored)
+	return nil
+}
+
+func (r *memoryModifyRepo) GetByTradeID(ctx context.Context, tradeID string) ([]*domain.Modify, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var modifies []*domain.Modify
+	for _, modify := range r.order {
+		if modify.TradeID == tradeID {
+			modifies = append(modifies, cloneModify(modify))
+		}
+	}
+	sort.SliceStable(modifies, func(i, j int) bool {
+		return modifies[i].ModifiedAtMs < modifies[j].ModifiedAtMs
+	})
+	return modifies, nil
+}
+
+// ===========================================================================
+// memorySymbolRepo (i3)
+// ===========================================================================
+
+type memorySymbolMapping struct {
+	info         domain.AccountSymbolInfo
+	reportedAtMs int64
+}
+
+type memorySymbolRepo struct {
+	mu       sync.RWMutex
+	accounts map[string]map[string]*memorySymbolMapping // account_id → canonical → mapping
+}
+
+func (r *memorySymbolRepo) UpsertAccountMapping(ctx context.Context, accountID string, mappings []*domain.SymbolMapping, reportedAtMs int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	symbols, ok := r.accounts[accountID]
+	if !ok {
+		symbols = make(map[string]*memorySymbolMapping)
+		r.accounts[accountID] = symbols
+	}
+
+	for _, m := range mappings {
+		if m == nil {
+			continue
+		}
+		// Idempotencia temporal: ignorar reportes más antiguos que el persistido
+		if existing, ok := symbols[m.CanonicalSymbol]; ok && reportedAtMs < existing.reportedAtMs {
+			continue
+		}
+		info := m.ToAccountSymbolInfo()
+		info.ContractSize = cloneFloat64(m.ContractSize)
+		symbols[m.CanonicalSymbol] = &memorySymbolMapping{info: *info, reportedAtMs: reportedAtMs}
+	}
+
+	return nil
+}
+
+func (r *memorySymbolRepo) GetAccountMapping(ctx context.Context, accountID string) (map[string]*domain.AccountSymbolInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]*domain.AccountSymbolInfo)
+	for canonical, mapping := range r.accounts[accountID] {
+		info := mapping.info
+		info.ContractSize = cloneFloat64(mapping.info.ContractSize)
+		result[canonical] = &info
+	}
+	return result, nil
+}
+
+func (r *memorySymbolRepo) InvalidateAccount(ctx context.Context, accountID string) error {
+	r.mu.Lock()
+	delete(r.accounts, accountID)
+	r.mu.Unlock()
+	return nil
+}
+
+// ==========================================================================
+// memorySymbolSpecRepo
+// ==========================================================================
+
+type memorySymbolSpecRepo struct {
+	mu       sync.RWMutex
+	accounts map[string]map[string]*domain.AccountSymbolSpec // account_id → canonical → spec
+}
+
+func (r *memorySymbolSpecRepo) UpsertSpecifications(ctx context.Context, accountID string, specs []*pb.SymbolSpecification, reportedAtMs int64) error {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	symbols, ok := r.accounts[accountID]
+	if !ok {
+		symbols = make(map[string]*domain.AccountSymbolSpec)
+		r.accounts[accountID] = symbols
+	}
+
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+		if existing, ok := symbols[spec.CanonicalSymbol]; ok && reportedAtMs < existing.ReportedAtMs {
+			continue
+		}
+		symbols[spec.CanonicalSymbol] = &domain.AccountSymbolSpec{
+			CanonicalSymbol: spec.CanonicalSymbol,
+			Specification:   proto.Clone(spec).(*pb.SymbolSpecification),
+			ReportedAtMs:    reportedAtMs,
+		}
+	}
+
+	return nil
+}
+
+func (r *memorySymbolSpecRepo) GetSpecifications(ctx context.Context, accountID string) (map[string]*domain.AccountSymbolSpec, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make(map[string]*domain.AccountSymbolSpec)
+	for canonical, spec := range r.accounts[accountID] {
+		result[canonical] = &domain.AccountSymbolSpec{
+			CanonicalSymbol: spec.CanonicalSymbol,
+			Specification:   proto.Clone(spec.Specification).(*pb.SymbolSpecification),
+			ReportedAtMs:    spec.ReportedAtMs,
+		}
+	}
+	return result, nil
+}
+
+// ==========================================================================
+// memorySymbolQuoteRepo
+// ==========================================================================
+
+type memorySymbolQuoteRepo struct {
+	mu     sync.RWMutex
+	latest map[string]*pb.SymbolQuoteSnapshot // account_id|canonical → snapshot
+}
+
+func (r *memorySymbolQuoteRepo) InsertSnapshot(ctx context.Context, snapshot *pb.SymbolQuoteSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("snapshot is nil")
+	}
+
+	key := memoryKey(snapshot.AccountId, snapshot.CanonicalSymbol)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.latest[key]; ok && snapshot.TimestampMs < existing.TimestampMs {
+		return nil
+	}
+	r.latest[key] = proto.Clone(snapshot).(*pb.SymbolQuoteSnapshot)
+	return nil
+}
+
+func (r *memorySymbolQuoteRepo) GetLatestSnapshot(ctx context.Context, accountID, canonicalSymbol string) (*pb.SymbolQuoteSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshot, ok := r.latest[memoryKey(accountID, canonicalSymbol)]
+	if !ok {
+		return nil, nil
+	}
+	return proto.Clone(snapshot).(*pb.SymbolQuoteSnapshot), nil
+}
+
+// ==========================================================================
+// memoryRiskPolicyRepo
+// ==========================================================================
+
+type memoryRiskPolicyRepo struct {
+	hub *changeHub
+	now func() time.Time
+
+	mu      sync.RWMutex
+	history map[string][]*domain.RiskPolicyRevision // account_id|strategy_id → revisiones (version ASC)
+}
+
+func (r *memoryRiskPolicyRepo) Get(ctx context.Context, accountID, strategyID string) (*domain.RiskPolicy, error) {
+	at := r.now()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Vigente: mayor effective_from <= at (desempate por versión), igual que el historial PostgreSQL
+	var current *domain.RiskPolicyRevision
+	for _, revision := range r.history[memoryKey(accountID, strategyID)] {
+		if revision.Policy.EffectiveFrom.After(at) {
+			continue
+		}
+		if current == nil || !revision.Policy.EffectiveFrom.Before(current.Policy.EffectiveFrom) {
+			current = revision
+		}
+	}
+	if current == nil || !current.IsEffectiveAt(at) {
+		return nil, nil
+	}
+
+	policy := cloneRiskPolicyRevision(current).Policy
+	return &policy, nil
+}
+
+func (r *memoryRiskPolicyRepo) GetVersion(ctx context.Context, accountID, strategyID string, version int64) (*domain.RiskPolicyRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, revision := range r.history[memoryKey(accountID, strategyID)] {
+		if revision.Policy.Version == version {
+			return cloneRiskPolicyRevision(revision), nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRiskPolicyRepo) ListHistory(ctx context.Context, accountID, strategyID string) ([]*domain.RiskPolicyRevision, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var revisions []*domain.RiskPolicyRevision
+	for _, revision := range r.history[memoryKey(accountID, strategyID)] {
+		revisions = append(revisions, cloneRiskPolicyRevision(revision))
+	}
+	return revisions, nil
+}
+
+func (r *memoryRiskPolicyRepo) List(ctx context.Context) ([]*domain.RiskPolicy, error) {
+	r.mu.RLock()
+	pairs := make([][2]string, 0, len(r.history))
+	for _, revisions := range r.history {
+		if len(revisions) > 0 {
+			pairs = append(pairs, [2]string{revisions[0].Policy.AccountID, revisions[0].Policy.StrategyID})
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+
+	var policies []*domain.RiskPolicy
+	for _, pair := range pairs {
+		policy, err := r.Get(ctx, pair[0], pair[1])
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+func (r *memoryRiskPolicyRepo) AppendRevision(ctx context.Context, revision *domain.RiskPolicyRevision) (int64, error) {
+	if err := domain.ValidateRiskPolicyRevision(revision); err != nil {
+		return 0, err
+	}
+
+	policy := &revision.Policy
+	now := r.now()
+
+	r.mu.Lock()
+	key := memoryKey(policy.AccountID, policy.StrategyID)
+	version := int64(len(r.history[key])) + 1
+
+	stored := cloneRiskPolicyRevision(revision)
+	stored.Author = strings.TrimSpace(revision.Author)
+	stored.RecordedAt = now
+	stored.Policy.Version = version
+	stored.Policy.UpdatedAt = now
+	if stored.Policy.EffectiveFrom.IsZero() {
+		stored.Policy.EffectiveFrom = now
+	}
+	if stored.Policy.ValidUntil != nil && !stored.Policy.ValidUntil.After(stored.Policy.EffectiveFrom) {
+		r.mu.Unlock()
+		return 0, fmt.Errorf("failed to append risk policy revision: valid_until must be after effective_from")
+	}
+	r.history[key] = append(r.history[key], stored)
+	r.mu.Unlock()
+
+	policy.Version = version
+	policy.EffectiveFrom = stored.Policy.EffectiveFrom
+	policy.UpdatedAt = now
+	revision.RecordedAt = now
+
+	r.hub.riskPolicyChanged(policy.AccountID, policy.StrategyID)
+
+	return version, nil
+}
+
+// ==========================================================================
+// memoryHandshakeRepo
+// ==========================================================================
+
+type memoryHandshakeRepo struct {
+	hub *changeHub
+
+	mu     sync.RWMutex
+	latest map[string]*handshake.Evaluation // account_id → evaluación más reciente
+}
+
+func (r *memoryHandshakeRepo) CreateEvaluation(ctx context.Context, evaluation *handshake.Evaluation) error {
+	if evaluation == nil {
+		return fmt.Errorf("nil evaluation")
+	}
+
+	if evaluation.EvaluationID == "" {
+		evaluation.EvaluationID = utils.GenerateUUIDv7()
+	}
+	if evaluation.EvaluatedAtMs == 0 {
+		evaluation.EvaluatedAtMs = time.Now().UnixMilli()
+	}
+
+	r.mu.Lock()
+	// Solo se conserva la más reciente por cuenta (ORDER BY evaluated_at DESC LIMIT 1)
+	if existing, ok := r.latest[evaluation.AccountID]; !ok || evaluation.EvaluatedAtMs >= existing.EvaluatedAtMs {
+		r.latest[evaluation.AccountID] = cloneEvaluation(evaluation)
+	}
+	r.mu.Unlock()
+
+	r.hub.handshakeEvaluated(evaluation.AccountID, evaluation.EvaluationID)
+	return nil
+}
+
+func (r *memoryHandshakeRepo) GetLatestByAccount(ctx context.Context, accountID string) (*handshake.Evaluation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	evaluation, ok := r.latest[accountID]
+	if !ok {
+		return nil, nil
+	}
+	return cloneEvaluation(evaluation), nil
+}
+
+// ==========================================================================
+// memoryOutboxRepo (i8)
+// ==========================================================================
+
+// memoryOutboxRepo replica el outbox transaccional: EnqueueTrade valida trade_id y
+// command_id antes de escribir, de modo que un error no deja escrituras parciales.
+type memoryOutboxRepo struct {
+	trades *memoryTradeStore
+	dedupe *memoryDedupeRepo
+
+	mu    sync.Mutex
+	byID  map[string]*domain.OutboxCommand
+	order []*domain.OutboxCommand // orden de inserción (created_at_ms ASC)
+}
+
+func (r *memoryOutboxRepo) EnqueueTrade(ctx context.Context, trade *domain.Trade, dedupe *domain.DedupeEntry, commands []*domain.OutboxCommand) error {
+	if trade == nil || dedupe == nil {
+		return fmt.Errorf("failed to enqueue trade: nil trade or dedupe entry")
+	}
+
+	r.trades.mu.Lock()
+	defer r.trades.mu.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.trades.byID[trade.TradeID]; ok {
+		return fmt.Errorf("failed to create trade: duplicate trade_id %s", trade.TradeID)
+	}
+	seen := make(map[string]struct{}, len(commands))
+	for _, command := range commands {
+		if _, ok := r.byID[command.CommandID]; ok {
+			return fmt.Errorf("failed to enqueue command %s: duplicate command_id", command.CommandID)
+		}
+		if _, ok := seen[command.CommandID]; ok {
+			return fmt.Errorf("failed to enqueue command %s: duplicate command_id", command.CommandID)
+		}
+		seen[command.CommandID] = struct{}{}
+	}
+
+	stored := cloneTrade(trade)
+	now := time.Now()
+	stored.CreatedAt = now
+	stored.UpdatedAt = now
+	r.trades.byID[trade.TradeID] = stored
+	r.trades.order = append(r.trades.order, trade.TradeID)
+
+	if err := r.dedupe.Upsert(ctx, dedupe); err != nil {
+		return err
+	}
+
+	for _, command := range commands {
+		queued := cloneOutboxCommand(enqueuedOutboxCommand(command))
+		r.byID[queued.CommandID] = queued
+		r.order = append(r.order, queued)
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepo) GetByID(ctx context.Context, commandID string) (*domain.OutboxCommand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	command, ok := r.byID[commandID]
+	if !ok {
+		return nil, nil
+	}
+	return cloneOutboxCommand(command), nil
+}
+
+func (r *memoryOutboxRepo) ListDeliverable(ctx context.Context, redeliverBeforeMs int64, limit int) ([]*domain.OutboxCommand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deliverable []*domain.OutboxCommand
+	for _, command := range r.order {
+		switch {
+		case command.Status == domain.OutboxStatusPending:
+		case command.Status == domain.OutboxStatusDispatched && command.DispatchedAtMs <= redeliverBeforeMs:
+		default:
+			continue
+		}
+		deliverable = append(deliverable, cloneOutboxCommand(command))
+		if limit > 0 && len(deliverable) >= limit {
+			break
+		}
+	}
+	return deliverable, nil
+}
+
+func (r *memoryOutboxRepo) MarkDispatched(ctx context.Context, commandID, agentID string, dispatchedAtMs int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	command, ok := r.byID[commandID]
+	if !ok || command.Status.IsTerminal() {
+		return nil
+	}
+	command.Status = domain.OutboxStatusDispatched
+	command.Attempts++
+	command.LastAgentID = agentID
+	command.DispatchedAtMs = dispatchedAtMs
+	return nil
+}
+
+func (r *memoryOutboxRepo) MarkAcked(ctx context.Context, commandID string, ackedAtMs int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	command, ok := r.byID[commandID]
+	if !ok || command.Status == domain.OutboxStatusAcked {
+		return false, nil
+	}
+	command.Status = domain.OutboxStatusAcked
+	command.CompletedAtMs = ackedAtMs
+	return true, nil
+}
+
+func (r *memoryOutboxRepo) ExpireOlderThan(ctx context.Context, cutoffMs, expiredAtMs int64) ([]*domain.OutboxCommand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var expired []*domain.OutboxCommand
+	for _, command := range r.order {
+		if command.Status.IsTerminal() || command.CreatedAtMs >= cutoffMs {
+			continue
+		}
+		command.Status = domain.OutboxStatusExpired
+		command.CompletedAtMs = expiredAtMs
+		expired = append(expired, cloneOutboxCommand(command))
+	}
+	return expired, nil
+}
+
+func (r *memoryOutboxRepo) PurgeCompleted(ctx context.Context, cutoffMs int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kept := r.order[:0]
+	deleted := 0
+	for _, command := range r.order {
+		if command.Status.IsTerminal() && command.CompletedAtMs < cutoffMs {
+			delete(r.byID, command.CommandID)
+			deleted++
+			continue
+		}
+		kept = append(kept, command)
+	}
+	r.order = kept
+	return deleted, nil
+}
+
+// ==========================================================================
+// memoryAgentLivenessRepo (i8)
+// ==========================================================================
+
+type memoryAgentLivenessRepo struct {
+	mu   sync.Mutex
+	byID map[string]*domain.AgentLiveness
+}
+
+func (r *memoryAgentLivenessRepo) Upsert(ctx context.Context, liveness *domain.AgentLiveness) error {
+	if liveness == nil || liveness.AgentID == "" {
+		return fmt.Errorf("failed to upsert agent liveness: agent_id is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byID[liveness.AgentID] = cloneAgentLiveness(liveness)
+	return nil
+}
+
+func (r *memoryAgentLivenessRepo) List(ctx context.Context) ([]*domain.AgentLiveness, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	agents := make([]*domain.AgentLiveness, 0, len(r.byID))
+	for _, liveness := range r.byID {
+		agents = append(agents, cloneAgentLiveness(liveness))
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].AgentID < agents[j].AgentID
+	})
+	return agents, nil
+}
+
+func (r *memoryAgentLivenessRepo) PurgeOlderThan(ctx context.Context, cutoffMs int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deleted := 0
+	for agentID, liveness := range r.byID {
+		if liveness.LastSeenAtMs < cutoffMs {
+			delete(r.byID, agentID)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// ==========================================================================
+// memoryAccountOwnershipRepo (i8)
+// ==========================================================================
+
+type memoryAccountOwnershipRepo struct {
+	mu   sync.Mutex
+	byID map[string]*domain.AccountOwnership
+}
+
+func (r *memoryAccountOwnershipRepo) Upsert(ctx context.Context, ownership *domain.AccountOwnership) error {
+	if ownership == nil || ownership.AccountID == "" {
+		return fmt.Errorf("failed to upsert account ownership: account_id is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *ownership
+	copied.ForcedAgentID = ""
+	if existing, ok := r.byID[ownership.AccountID]; ok {
+		copied.ForcedAgentID = existing.ForcedAgentID
+	}
+	r.byID[ownership.AccountID] = &copied
+	return nil
+}
+
+func (r *memoryAccountOwnershipRepo) Delete(ctx context.Context, accountID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byID, accountID)
+	return nil
+}
+
+func (r *memoryAccountOwnershipRepo) List(ctx context.Context) ([]*domain.AccountOwnership, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	accounts := make([]*domain.AccountOwnership, 0, len(r.byID))
+	for _, ownership := range r.byID {
+		copied := *ownership
+		accounts = append(accounts, &copied)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].AccountID < accounts[j].AccountID
+	})
+	return accounts, nil
+}
+
+func (r *memoryAccountOwnershipRepo) RequestTakeover(ctx context.Context, accountID, agentID string, nowMs int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ownership, ok := r.byID[accountID]
+	if !ok {
+		ownership = &domain.AccountOwnership{
+			AccountID:      accountID,
+			AgentID:        agentID,
+			Status:         domain.OwnershipStatusDetached,
+			RegisteredAtMs: nowMs,
+		}
+		r.byID[accountID] = ownership
+	}
+	ownership.ForcedAgentID = agentID
+	ownership.UpdatedAtMs = nowMs
+	return nil
+}
+
+func (r *memoryAccountOwnershipRepo) ClearTakeover(ctx context.Context, accountID, agentID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if ownership, ok := r.byID[accountID]; ok && ownership.ForcedAgentID == agentID {
+		ownership.ForcedAgentID = ""
+	}
+	return nil
+}
+
+// ==========================================================================
+// memoryAccountCopyControlRepo (i8)
+// ==========================================================================
+
+type memoryAccountCopyControlRepo struct {
+	mu   sync.Mutex
+	byID map[string]*domain.AccountCopyControl
+}
+
+func (r *memoryAccountCopyControlRepo) Upsert(ctx context.Context, control *domain.AccountCopyControl) error {
+	if control == nil || control.AccountID == "" {
+		return fmt.Errorf("failed to upsert copy control: account_id is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *control
+	r.byID[control.AccountID] = &copied
+	return nil
+}
+
+func (r *memoryAccountCopyControlRepo) List(ctx context.Context) ([]*domain.AccountCopyControl, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	controls := make([]*domain.AccountCopyControl, 0, len(r.byID))
+	for _, control := range r.byID {
+		copied := *control
+		controls = append(controls, &copied)
+	}
+	sort.Slice(controls, func(i, j int) bool {
+		return controls[i].AccountID < controls[j].AccountID
+	})
+	return controls, nil
+}
+
+// ==========================================================================
+// memoryNotificationDeadLetterRepo (i8)
+// ==========================================================================
+
+type memoryNotificationDeadLetterRepo struct {
+	mu      sync.Mutex
+	letters []*domain.NotificationDeadLetter
+}
+
+func (r *memoryNotificationDeadLetterRepo) Create(ctx context.Context, letter *domain.NotificationDeadLetter) error {
+	if letter == nil || letter.ID == "" {
+		return fmt.Errorf("failed to insert notification dead letter: id is required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.letters {
+		if existing.ID == letter.ID {
+			return fmt.Errorf("failed to insert notification dead letter %s: already exists", letter.ID)
+		}
+	}
+	copied := *letter
+	r.letters = append(r.letters, &copied)
+	return nil
+}
+
+func (r *memoryNotificationDeadLetterRepo) List(ctx context.Context, limit, offset int) ([]*domain.NotificationDeadLetter, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	letters := make([]*domain.NotificationDeadLetter, 0, len(r.letters))
+	for _, letter := range r.letters {
+		copied := *letter
+		letters = append(letters, &copied)
+	}
+	sort.SliceStable(letters, func(i, j int) bool {
+		if letters[i].CreatedAtMs != letters[j].CreatedAtMs {
+			return letters[i].CreatedAtMs > letters[j].CreatedAtMs
+		}
+		return letters[i].ID > letters[j].ID
+	})
+	if offset >= len(letters) {
+		return []*domain.NotificationDeadLetter{}, nil
+	}
+	letters = letters[offset:]
+	if limit > 0 && limit < len(letters) {
+		letters = letters[:limit]
+	}
+	return letters, nil
+}
+
+// ==========================================================================
+// memorySymbolOverrideRepo (i8)
+// ==========================================================================
+
+type memorySymbolOverrideRepo struct {
+	hub     *changeHub
+	symbols domain.SymbolRepository // Pin escribe también el mapeo resultante
+
+	mu        sync.Mutex
+	byAccount map[string]map[string]*domain.SymbolMappingOverride // accountID → canonical → override
+}
+
+func (r *memorySymbolOverrideRepo) Upsert(ctx context.Context, override *domain.SymbolMappingOverride) error {
+	if err := r.upsert(override); err != nil {
+		return err
+	}
+	r.hub.symbolMappingChanged(override.AccountID)
+	return nil
+}
+
+func (r *memorySymbolOverrideRepo) Pin(ctx context.Context, override *domain.SymbolMappingOverride, mapping *domain.SymbolMapping) error {
+	if err := r.upsert(override); err != nil {
+		return err
+	}
+	if err := r.symbols.UpsertAccountMapping(ctx, override.AccountID, []*domain.SymbolMapping{mapping}, override.UpdatedAtMs); err != nil {
+		return fmt.Errorf("failed to persist pinned mapping: %w", err)
+	}
+	r.hub.symbolMappingChanged(override.AccountID)
+	return nil
+}
+
+func (r *memorySymbolOverrideRepo) upsert(override *domain.SymbolMappingOverride) error {
+	if override == nil || override.AccountID == "" || override.CanonicalSymbol == "" {
+		return fmt.Errorf("failed to upsert symbol override: account_id and canonical_symbol are required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byAccount[override.AccountID] == nil {
+		r.byAccount[override.AccountID] = make(map[string]*domain.SymbolMappingOverride)
+	}
+	copied := *override
+	r.byAccount[override.AccountID][override.CanonicalSymbol] = &copied
+	return nil
+}
+
+func (r *memorySymbolOverrideRepo) Delete(ctx context.Context, accountID, canonicalSymbol string) (bool, error) {
+	r.mu.Lock()
+	if _, ok := r.byAccount[accountID][canonicalSymbol]; !ok {
+		r.mu.Unlock()
+		return false, nil
+	}
+	delete(r.byAccount[accountID], canonicalSymbol)
+	r.mu.Unlock()
+
+	r.hub.symbolMappingChanged(accountID)
+	return true, nil
+}
+
+func (r *memorySymbolOverrideRepo) ListByAccount(ctx context.Context, accountID string) ([]*domain.SymbolMappingOverride, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	overrides := make([]*domain.SymbolMappingOverride, 0, len(r.byAccount[accountID]))
+	for _, override := range r.byAccount[accountID] {
+		copied := *override
+		overrides = append(overrides, &copied)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].CanonicalSymbol < overrides[j].CanonicalSymbol
+	})
+	return overrides, nil
+}
+
+// ==========================================================================
+// memorySymbolProposalRepo (i8)
+// ==========================================================================
+
+type memorySymbolProposalRepo struct {
+	mu        sync.Mutex
+	byAccount map[string][]*domain.SymbolMatchProposal
+}
+
+func (r *memorySymbolProposalRepo) ReplaceForAccount(ctx context.Context, accountID string, proposals []*domain.SymbolMatchProposal) error {
+	copied := make([]*domain.SymbolMatchProposal, 0, len(proposals))
+	for _, proposal := range proposals {
+		clone := *proposal
+		clone.AccountID = accountID
+		clone.Checks = append([]string(nil), proposal.Checks...)
+		copied = append(copied, &clone)
+	}
+	sort.Slice(copied, func(i, j int) bool {
+		return copied[i].BrokerSymbol < copied[j].BrokerSymbol
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(copied) == 0 {
+		delete(r.byAccount, accountID)
+		return nil
+	}
+	r.byAccount[accountID] = copied
+	return nil
+}
+
+func (r *memorySymbolProposalRepo) ListByAccount(ctx context.Context, accountID string) ([]*domain.SymbolMatchProposal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	proposals := make([]*domain.SymbolMatchProposal, 0, len(r.byAccount[accountID]))
+	for _, proposal := range r.byAccount[accountID] {
+		clone := *proposal
+		clone.Checks = append([]string(nil), proposal.Checks...)
+		proposals = append(proposals, &clone)
+	}
+	return proposals, nil
+}
+
+// ==========================================================================
+// Helpers
+// ==========================================================================
+
+func memoryKey(parts ...string) string {
+	return strings.Join(parts, "|")
+}
+
+// paginate aplica LIMIT/OFFSET sobre un slice ya ordenado. limit <= 0 retorna todo.
+func paginate[T any](items []T, limit, offset int) []T {
+	total := len(items)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total {
+		return nil
+	}
+	end := total
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return items[offset:end]
+}
+
+func cloneFloat64(value *float64) *float64 {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+func cloneTrade(trade *domain.Trade) *domain.Trade {
+	copied := *trade
+	copied.StopLoss = cloneFloat64(trade.StopLoss)
+	copied.TakeProfit = cloneFloat64(trade.TakeProfit)
+	if trade.Comment != nil {
+		comment := *trade.Comment
+		copied.Comment = &comment
+	}
+	return &copied
+}
+
+func cloneExecution(exec *domain.Execution) *domain.Execution {
+	copied := *exec
+	copied.ExecutedPrice = cloneFloat64(exec.ExecutedPrice)
+	if exec.TimestampsMs != nil {
+		copied.TimestampsMs = make(map[string]int64, len(exec.TimestampsMs))
+		for k, v := range exec.TimestampsMs {
+			copied.TimestampsMs[k] = v
+		}
+	}
+	if exec.RiskPolicyVersion != nil {
+		version := *exec.RiskPolicyVersion
+		copied.RiskPolicyVersion = &version
+	}
+	return &copied
+}
+
+func cloneClose(close *domain.Close) *domain.Close {
+	copied := *close
+	copied.ClosePrice = cloneFloat64(close.ClosePrice)
+	copied.Profit = cloneFloat64(close.Profit)
+	copied.Commission = cloneFloat64(close.Commission)
+	copied.Swap = cloneFloat64(close.Swap)
+	copied.ClosedVolume = cloneFloat64(close.ClosedVolume)
+	copied.RemainingVolume = cloneFloat64(close.RemainingVolume)
+	return &copied
+}
+
+func cloneModify(modify *domain.Modify) *domain.Modify {
+	copied := *modify
+	copied.NewStopLoss = cloneFloat64(modify.NewStopLoss)
+	copied.NewTakeProfit = cloneFloat64(modify.NewTakeProfit)
+	return &copied
+}
+
+func cloneOutboxCommand(command *domain.OutboxCommand) *domain.OutboxCommand {
+	copied := *command
+	copied.Payload = append([]byte(nil), command.Payload...)
+	return &copied
+}
+
+// cloneAgentLiveness copia el Agent y sus cuentas ordenadas por pipe_name (como las tablas SQL).
+func cloneAgentLiveness(liveness *domain.AgentLiveness) *domain.AgentLiveness {
+	copied := *liveness
+	copied.Accounts = make([]*domain.AccountLiveness, 0, len(liveness.Accounts))
+	for _, account := range liveness.Accounts {
+		accountCopy := *account
+		accountCopy.AgentID = liveness.AgentID
+		copied.Accounts = append(copied.Accounts, &accountCopy)
+	}
+	sort.Slice(copied.Accounts, func(i, j int) bool {
+		return copied.Accounts[i].PipeName < copied.Accounts[j].PipeName
+	})
+	return &copied
+}
+
+func cloneRiskPolicyRevision(revision *domain.RiskPolicyRevision) *domain.RiskPolicyRevision {
+	copied := *revision
+	if revision.Policy.FixedLot != nil {
+		fixedLot := *revision.Policy.FixedLot
+		copied.Policy.FixedLot = &fixedLot
+	}
+	if revision.Policy.FixedRisk != nil {
+		fixedRisk := *revision.Policy.FixedRisk
+		fixedRisk.MinLotOverride = cloneFloat64(fixedRisk.MinLotOverride)
+		fixedRisk.MaxLotOverride = cloneFloat64(fixedRisk.MaxLotOverride)
+		fixedRisk.CommissionPerLot = cloneFloat64(fixedRisk.CommissionPerLot)
+		fixedRisk.CommissionRate = cloneFloat64(fixedRisk.CommissionRate)
+		copied.Policy.FixedRisk = &fixedRisk
+	}
+	if revision.Policy.ValidUntil != nil {
+		validUntil := *revision.Policy.ValidUntil
+		copied.Policy.ValidUntil = &validUntil
+	}
+	return &copied
+}
+
+func cloneEvaluation(evaluation *handshake.Evaluation) *handshake.Evaluation {
+	copied := *evaluation
+	copied.Errors = append([]handshake.Issue(nil), evaluation.Errors...)
+	copied.Warnings = append([]handshake.Issue(nil), evaluation.Warnings...)
+	copied.Entries = append([]handshake.Entry(nil), evaluation.Entries...)
+	copied.RequiredFeatures = append([]string(nil), evaluation.RequiredFeatures...)
+	copied.OptionalFeatures = append([]string(nil), evaluation.OptionalFeatures...)
+	copied.Capabilities.Features = append([]string(nil), evaluation.Capabilities.Features...)
+	copied.Capabilities.Metrics = append([]string(nil), evaluation.Capabilities.Metrics...)
+	return &copied
+}
+
+var (
+	_ domain.RepositoryFactory = (*MemoryFactory)(nil)
+	_ domain.RepositoryFactory = (*PostgresFactory)(nil)
+	_ ChangeNotifier           = (*MemoryFactory)(nil)
+)
@@ -0,0 +1,523 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/domain/handshake"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+func TestMemoryTradeRepoDuplicateAndOrdering(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryFactory(0).TradeRepository()
+
+	require.NoError(t, repo.Create(ctx, &domain.Trade{TradeID: "t1", MasterAccountID: "m", MasterTicket: 10, Status: domain.OrderStatusPending}))
+	require.NoError(t, repo.Create(ctx, &domain.Trade{TradeID: "t2", MasterAccountID: "m", MasterTicket: 10, Status: domain.OrderStatusFilled}))
+	assert.Error(t, repo.Create(ctx, &domain.Trade{TradeID: "t1"}))
+
+	latest, err := repo.GetByMasterTicket(ctx, "m", 10)
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, "t2", latest.TradeID)
+
+	trades, err := repo.List(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, trades, 2)
+	assert.Equal(t, "t2", trades[0].TradeID)
+
+	filled, err := repo.ListByStatus(ctx, domain.OrderStatusFilled, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, filled, 1)
+
+	assert.Error(t, repo.UpdateStatus(ctx, "missing", domain.OrderStatusFilled))
+}
+
+func TestMemoryExecutionRepoConstraintsAndTicket(t *testing.T) {
+	ctx := context.Background()
+	factory := NewMemoryFactory(0)
+	execRepo := factory.ExecutionRepository()
+
+	// FK a trades
+	assert.Error(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e0", TradeID: "missing"}))
+
+	require.NoError(t, factory.TradeRepository().Create(ctx, &domain.Trade{TradeID: "t1"}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 0, Success: false}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e2", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 0, Success: false}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e3", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+
+	// ux_execution_trade_slave_ticket
+	assert.Error(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e4", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+
+	ticket, err := execRepo.GetTicketByTradeAndSlave(ctx, "t1", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(555), ticket)
+
+	tickets, err := factory.CorrelationService().GetTicketsByTrade(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int32{"s1": 555}, tickets)
+}
+
+func TestMemoryTradeLifecyclePartialAndFinalClose(t *testing.T) {
+	ctx := context.Background()
+	factory := NewMemoryFactory(0)
+	f := func(v float64) *float64 { return &v }
+
+	require.NoError(t, factory.TradeRepository().Create(ctx, &domain.Trade{TradeID: "t1"}))
+	execRepo := factory.ExecutionRepository()
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 100, Success: true}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e2", TradeID: "t1", SlaveAccountID: "s2", Success: false, ErrorCode: "ERR_NO_MONEY"}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e3", TradeID: "t1", SlaveAccountID: "s3", SlaveTicket: 300, Success: true}))
+
+	closeRepo := factory.CloseRepository()
+	opened, err := execRepo.GetByID(ctx, "e1")
+	require.NoError(t, err)
+	openedAtMs := opened.CreatedAt.UnixMilli()
+
+	// s1: cierre parcial, intento fallido y cierre final
+	require.NoError(t, closeRepo.Create(ctx, &domain.Close{CloseID: "c1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 100, Success: true,
+		ClosePrice: f(2001), ClosedVolume: f(0.4), RemainingVolume: f(0.6), Profit: f(40), Commission: f(-2), Swap: f(-0.5), ClosedAtMs: openedAtMs + 1000}))
+	require.NoError(t, closeRepo.Create(ctx, &domain.Close{CloseID: "c2", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 101, Success: false,
+		ErrorCode: "ERR_REQUOTE", ClosedAtMs: openedAtMs + 2000}))
+	require.NoError(t, closeRepo.Create(ctx, &domain.Close{CloseID: "c3", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 101, Success: true,
+		ClosePrice: f(2002), ClosedVolume: f(0.6), RemainingVolume: f(0), Profit: f(60), Commission: f(-3), Swap: f(-1), ClosedAtMs: openedAtMs + 5000}))
+	// s3: solo cierre parcial
+	require.NoError(t, closeRepo.Create(ctx, &domain.Close{CloseID: "c4", TradeID: "t1", SlaveAccountID: "s3", SlaveTicket: 300, Success: true,
+		ClosedVolume: f(0.1), RemainingVolume: f(0.9), Profit: f(-5), ClosedAtMs: openedAtMs + 1000}))
+	// Cambios de SL/TP del master (insertados fuera de orden)
+	modifyRepo := factory.ModifyRepository()
+	require.NoError(t, modifyRepo.Create(ctx, &domain.Modify{ModifyID: "m2", TradeID: "t1", MasterTicket: 10, NewTakeProfit: f(2050), ModifiedAtMs: openedAtMs + 3000}))
+	require.NoError(t, modifyRepo.Create(ctx, &domain.Modify{ModifyID: "m1", TradeID: "t1", MasterTicket: 10, NewStopLoss: f(1990), ModifiedAtMs: openedAtMs + 500}))
+	assert.Error(t, modifyRepo.Create(ctx, &domain.Modify{ModifyID: "m1", TradeID: "t1", ModifiedAtMs: openedAtMs}))
+	assert.Error(t, modifyRepo.Create(ctx, &domain.Modify{ModifyID: "m3", TradeID: "t9", ModifiedAtMs: openedAtMs}))
+
+	lifecycles, err := factory.TradeLifecycleRepository().ListByTrade(ctx, "t1")
+	require.NoError(t, err)
+	require.Len(t, lifecycles, 3)
+	assert.Equal(t, "s1", lifecycles[0].SlaveAccountID)
+	assert.Equal(t, "s2", lifecycles[1].SlaveAccountID)
+	assert.Equal(t, "s3", lifecycles[2].SlaveAccountID)
+
+	s1 := lifecycles[0]
+	assert.Equal(t, domain.TradeLifecycleStatusClosed, s1.Status)
+	require.NotNil(t, s1.Open)
+	assert.Equal(t, "e1", s1.Open.ExecutionID)
+	require.Len(t, s1.Closes, 3)
+	assert.Equal(t, []string{"c1", "c2", "c3"}, []string{s1.Closes[0].CloseID, s1.Closes[1].CloseID, s1.Closes[2].CloseID})
+	assert.True(t, s1.Closes[0].IsPartial())
+	assert.InDelta(t, 1.0, s1.ClosedVolume, 1e-9)
+	assert.InDelta(t, 100, s1.Profit, 1e-9)
+	assert.InDelta(t, -5, s1.Commission, 1e-9)
+	assert.InDelta(t, -1.5, s1.Swap, 1e-9)
+	assert.InDelta(t, 93.5, s1.NetProfit(), 1e-9)
+	assert.Equal(t, int64(5000), s1.HoldingTimeMs)
+	require.Len(t, s1.Modifies, 2)
+	assert.Equal(t, "m1", s1.Modifies[0].ModifyID)
+	require.NotNil(t, s1.Modifies[0].NewStopLoss)
+	assert.Equal(t, 1990.0, *s1.Modifies[0].NewStopLoss)
+	assert.Nil(t, s1.Modifies[0].NewTakeProfit)
+	assert.Equal(t, "m2", s1.Modifies[1].ModifyID)
+	assert.Equal(t, 2050.0, *s1.Modifies[1].NewTakeProfit)
+
+	assert.Equal(t, domain.TradeLifecycleStatusRejected, lifecycles[1].Status)
+	assert.Equal(t, domain.TradeLifecycleStatusPartiallyClosed, lifecycles[2].Status)
+	assert.Zero(t, lifecycles[2].HoldingTimeMs)
+
+	missing, err := factory.TradeLifecycleRepository().GetLifecycle(ctx, "t1", "s9")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestMemoryDedupeRepoTTL(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryFactory(time.Minute).DedupeRepository().(*memoryDedupeRepo)
+
+	now := time.Now()
+	repo.now = func() time.Time { return now }
+
+	require.NoError(t, repo.Upsert(ctx, &domain.DedupeEntry{TradeID: "done", Status: domain.OrderStatusFilled}))
+	require.NoError(t, repo.Upsert(ctx, &domain.DedupeEntry{TradeID: "open", Status: domain.OrderStatusPending}))
+	assert.EqualError(t, repo.UpdateStatus(ctx, "missing", domain.OrderStatusFilled), "dedupe entry not found: missing")
+
+	// Dentro del TTL no se elimina nada
+	deleted, err := repo.CleanupTTL(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, deleted)
+
+	// Vencido: solo se eliminan estados terminales
+	now = now.Add(2 * time.Minute)
+	deleted, err = repo.CleanupTTL(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	exists, err := repo.Exists(ctx, "open")
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestMemorySymbolReposIgnoreStaleReports(t *testing.T) {
+	ctx := context.Background()
+	factory := NewMemoryFactory(0)
+
+	symbols := factory.SymbolRepository()
+	require.NoError(t, symbols.UpsertAccountMapping(ctx, "acc", []*domain.SymbolMapping{{CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.m"}}, 200))
+	require.NoError(t, symbols.UpsertAccountMapping(ctx, "acc", []*domain.SymbolMapping{{CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD"}}, 100))
+	mapping, err := symbols.GetAccountMapping(ctx, "acc")
+	require.NoError(t, err)
+	assert.Equal(t, "XAUUSD.m", mapping["XAUUSD"].BrokerSymbol)
+
+	specs := factory.SymbolSpecRepository()
+	require.NoError(t, specs.UpsertSpecifications(ctx, "acc", []*pb.SymbolSpecification{{CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.m"}}, 200))
+	require.NoError(t, specs.UpsertSpecifications(ctx, "acc", []*pb.SymbolSpecification{{CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD"}}, 100))
+	stored, err := specs.GetSpecifications(ctx, "acc")
+	require.NoError(t, err)
+	assert.Equal(t, "XAUUSD.m", stored["XAUUSD"].Specification.BrokerSymbol)
+	assert.Equal(t, int64(200), stored["XAUUSD"].ReportedAtMs)
+
+	quotes := factory.SymbolQuoteRepository()
+	require.NoError(t, quotes.InsertSnapshot(ctx, &pb.SymbolQuoteSnapshot{AccountId: "acc", CanonicalSymbol: "XAUUSD", Bid: 2000, TimestampMs: 200}))
+	require.NoError(t, quotes.InsertSnapshot(ctx, &pb.SymbolQuoteSnapshot{AccountId: "acc", CanonicalSymbol: "XAUUSD", Bid: 1990, TimestampMs: 100}))
+	quote, err := quotes.GetLatestSnapshot(ctx, "acc", "XAUUSD")
+	require.NoError(t, err)
+	assert.Equal(t, 2000.0, quote.Bid)
+
+	missing, err := quotes.GetLatestSnapshot(ctx, "acc", "EURUSD")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestMemoryRiskPolicyRepoHistory(t *testing.T) {
+	ctx := context.Background()
+	factory := NewMemoryFactory(0)
+	repo := factory.RiskPolicyRepository()
+
+	var notified []string
+	factory.OnRiskPolicyChanged(func(accountID, strategyID string) {
+		notified = append(notified, accountID+":"+strategyID)
+	})
+
+	v1, err := repo.AppendRevision(ctx, &domain.RiskPolicyRevision{
+		Policy: domain.RiskPolicy{AccountID: "acc", StrategyID: "s", Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.1}},
+		Author: "ops",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), v1)
+
+	// Versión programada: no reemplaza la vigente hasta su activación
+	v2, err := repo.AppendRevision(ctx, &domain.RiskPolicyRevision{
+		Policy: domain.RiskPolicy{AccountID: "acc", StrategyID: "s", Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.5}, EffectiveFrom: time.Now().Add(time.Hour)},
+		Author: "ops",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v2)
+
+	current, err := repo.Get(ctx, "acc", "s")
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	assert.Equal(t, int64(1), current.Version)
+	assert.Equal(t, 0.1, current.FixedLot.LotSize)
+
+	scheduled, err := repo.GetVersion(ctx, "acc", "s", 2)
+	require.NoError(t, err)
+	require.NotNil(t, scheduled)
+	assert.Equal(t, 0.5, scheduled.Policy.FixedLot.LotSize)
+
+	history, err := repo.ListHistory(ctx, "acc", "s")
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, []string{"acc:s", "acc:s"}, notified)
+
+	none, err := repo.Get(ctx, "acc", "other")
+	require.NoError(t, err)
+	assert.Nil(t, none)
+
+	_, err = repo.AppendRevision(ctx, &domain.RiskPolicyRevision{
+		Policy: domain.RiskPolicy{AccountID: "acc", StrategyID: "b", Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.2}},
+		Author: "ops",
+	})
+	require.NoError(t, err)
+	policies, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, policies, 2)
+	assert.Equal(t, "b", policies[1].StrategyID)
+
+	// Retiro: misma effective_from, mayor versión y valid_until = now
+	retired := *current
+	until := time.Now()
+	retired.ValidUntil = &until
+	_, err = repo.AppendRevision(ctx, &domain.RiskPolicyRevision{Policy: retired, Author: "ops"})
+	require.NoError(t, err)
+	gone, err := repo.Get(ctx, "acc", "s")
+	require.NoError(t, err)
+	assert.Nil(t, gone)
+	policies, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, "b", policies[0].StrategyID)
+}
+
+func TestMemoryHandshakeRepoLatestByAccount(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryFactory(0).HandshakeRepository()
+
+	require.NoError(t, repo.CreateEvaluation(ctx, &handshake.Evaluation{AccountID: "acc", EvaluatedAtMs: 200, Status: handshake.RegistrationStatusAccepted}))
+	require.NoError(t, repo.CreateEvaluation(ctx, &handshake.Evaluation{AccountID: "acc", EvaluatedAtMs: 100, Status: handshake.RegistrationStatusRejected}))
+
+	latest, err := repo.GetLatestByAccount(ctx, "acc")
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, int64(200), latest.EvaluatedAtMs)
+	assert.NotEmpty(t, latest.EvaluationID)
+
+	missing, err := repo.GetLatestByAccount(ctx, "other")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestMemoryOutboxLifecycle(t *testing.T) {
+	ctx := context.Background()
+	factory := NewMemoryFactory(time.Hour)
+	outbox := factory.OutboxRepository()
+
+	trade := &domain.Trade{TradeID: "t1", MasterAccountID: "m", MasterTicket: 10, Status: domain.OrderStatusPending}
+	commands := []*domain.OutboxCommand{
+		{CommandID: "c1", TradeID: "t1", TargetAccountID: "s1", CommandType: "execute_order", Payload: []byte{1}, CreatedAtMs: 1000},
+		{CommandID: "c2", TradeID: "t1", TargetAccountID: "s2", CommandType: "execute_order", Payload: []byte{2}, CreatedAtMs: 1000},
+	}
+	require.NoError(t, outbox.EnqueueTrade(ctx, trade, &domain.DedupeEntry{TradeID: "t1", Status: domain.OrderStatusPending}, commands))
+
+	// Un trade duplicado no deja escrituras parciales
+	assert.Error(t, outbox.EnqueueTrade(ctx, trade, &domain.DedupeEntry{TradeID: "t1", Status: domain.OrderStatusRejected},
+		[]*domain.OutboxCommand{{CommandID: "c3", TradeID: "t1", CreatedAtMs: 1000}}))
+	missing, err := outbox.GetByID(ctx, "c3")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+	entry, err := factory.DedupeRepository().Get(ctx, "t1")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, domain.OrderStatusPending, entry.Status)
+
+	stored, err := factory.TradeRepository().GetByID(ctx, "t1")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+
+	deliverable, err := outbox.ListDeliverable(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, deliverable, 2)
+
+	require.NoError(t, outbox.MarkDispatched(ctx, "c1", "agent-1", 2000))
+	deliverable, err = outbox.ListDeliverable(ctx, 1999, 10)
+	require.NoError(t, err)
+	require.Len(t, deliverable, 1)
+	assert.Equal(t, "c2", deliverable[0].CommandID)
+
+	// Sin ack tras el umbral de reentrega vuelve a ser entregable
+	deliverable, err = outbox.ListDeliverable(ctx, 2000, 10)
+	require.NoError(t, err)
+	require.Len(t, deliverable, 2)
+	assert.Equal(t, domain.OutboxStatusDispatched, deliverable[0].Status)
+	assert.Equal(t, 1, deliverable[0].Attempts)
+	assert.Equal(t, "agent-1", deliverable[0].LastAgentID)
+
+	acked, err := outbox.MarkAcked(ctx, "c1", 3000)
+	require.NoError(t, err)
+	assert.True(t, acked)
+	acked, err = outbox.MarkAcked(ctx, "c1", 3001)
+	require.NoError(t, err)
+	assert.False(t, acked)
+
+	expired, err := outbox.ExpireOlderThan(ctx, 5000, 5000)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "c2", expired[0].CommandID)
+
+	// Un comando terminal no vuelve a despacharse
+	require.NoError(t, outbox.MarkDispatched(ctx, "c2", "agent-2", 6000))
+	command, err := outbox.GetByID(ctx, "c2")
+	require.NoError(t, err)
+	assert.Equal(t, domain.OutboxStatusExpired, command.Status)
+
+	deleted, err := outbox.PurgeCompleted(ctx, 4000)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	deleted, err = outbox.PurgeCompleted(ctx, 6000)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestMemoryAgentLivenessListAndPurge(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryFactory(0).AgentLivenessRepository()
+
+	assert.Error(t, repo.Upsert(ctx, &domain.AgentLiveness{}))
+
+	accounts := []*domain.AccountLiveness{
+		{PipeName: "echo_slave_s1", AccountID: "s1", PipeRole: "slave", Status: domain.LivenessStatusAlive, LastSeenAtMs: 2000},
+		{PipeName: "echo_master_m1", AccountID: "m1", PipeRole: "master", Status: domain.LivenessStatusDisconnected},
+	}
+	require.NoError(t, repo.Upsert(ctx, &domain.AgentLiveness{AgentID: "agent-2", Status: domain.LivenessStatusAlive, LastSeenAtMs: 2000, Accounts: accounts}))
+	require.NoError(t, repo.Upsert(ctx, &domain.AgentLiveness{AgentID: "agent-1", Status: domain.LivenessStatusStale, LastSeenAtMs: 500}))
+
+	// El repositorio guarda copias
+	accounts[0].Status = domain.LivenessStatusStale
+
+	agents, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, agents, 2)
+	assert.Equal(t, "agent-1", agents[0].AgentID)
+	require.Len(t, agents[1].Accounts, 2)
+	assert.Equal(t, "echo_master_m1", agents[1].Accounts[0].PipeName)
+	assert.Equal(t, "agent-2", agents[1].Accounts[1].AgentID)
+	assert.Equal(t, domain.LivenessStatusAlive, agents[1].Accounts[1].Status)
+
+	deleted, err := repo.PurgeOlderThan(ctx, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	agents, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+}
+
+func TestMemoryAccountOwnershipKeepsForcedTakeover(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryFactory(0).AccountOwnershipRepository()
+
+	assert.Error(t, repo.Upsert(ctx, &domain.AccountOwnership{}))
+
+	require.NoError(t, repo.Upsert(ctx, &domain.AccountOwnership{AccountID: "s2", AgentID: "agent-1", Status: domain.OwnershipStatusActive, ForcedAgentID: "ignored"}))
+	require.NoError(t, repo.RequestTakeover(ctx, "s2", "agent-2", 2000))
+	require.NoError(t, repo.RequestTakeover(ctx, "s1", "agent-3", 2000))
+	require.NoError(t, repo.Upsert(ctx, &domain.AccountOwnership{AccountID: "s2", AgentID: "agent-1", Status: domain.OwnershipStatusDetached}))
+
+	accounts, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "s1", accounts[0].AccountID)
+	assert.Equal(t, domain.OwnershipStatusDetached, accounts[0].Status)
+	assert.Equal(t, "agent-2", accounts[1].ForcedAgentID)
+	assert.Equal(t, domain.OwnershipStatusDetached, accounts[1].Status)
+
+	require.NoError(t, repo.ClearTakeover(ctx, "s2", "agent-2"))
+	require.NoError(t, repo.Delete(ctx, "s1"))
+	accounts, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "", accounts[0].ForcedAgentID)
+}
+
+func TestMemorySymbolOverrides(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryFactory(0).SymbolOverrideRepository()
+
+	assert.Error(t, repo.Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s1"}))
+
+	require.NoError(t, repo.Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD", UpdatedAtMs: 1000}))
+	require.NoError(t, repo.Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "EURUSD", BrokerSymbol: "EURUSD.m", UpdatedAtMs: 1000}))
+	require.NoError(t, repo.Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.pro", UpdatedAtMs: 2000}))
+
+	overrides, err := repo.ListByAccount(ctx, "s1")
+	require.NoError(t, err)
+	require.Len(t, overrides, 2)
+	assert.Equal(t, "EURUSD", overrides[0].CanonicalSymbol)
+	assert.Equal(t, "XAUUSD.pro", overrides[1].BrokerSymbol)
+
+	deleted, err := repo.Delete(ctx, "s1", "EURUSD")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.Delete(ctx, "s9", "EURUSD")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	overrides, err = repo.ListByAccount(ctx, "s1")
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+}
+
+func TestMemorySymbolPinNotifies(t *testing.T) {
+	ctx := context.Background()
+	factory := NewMemoryFactory(0)
+	repo := factory.SymbolOverrideRepository()
+
+	var notified []string
+	factory.OnSymbolMappingChanged(func(accountID string) {
+		notified = append(notified, accountID)
+	})
+
+	pinned := &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.pro", UpdatedAtMs: 2000}
+	require.NoError(t, repo.Pin(ctx, pinned, &domain.SymbolMapping{CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.pro", Source: domain.SymbolMappingSourceOverride}))
+
+	mappings, err := factory.SymbolRepository().GetAccountMapping(ctx, "s1")
+	require.NoError(t, err)
+	require.Contains(t, mappings, "XAUUSD")
+	assert.Equal(t, "XAUUSD.pro", mappings["XAUUSD"].BrokerSymbol)
+
+	// Override inválido: no escribe el mapeo ni notifica
+	assert.Error(t, repo.Pin(ctx, &domain.SymbolMappingOverride{AccountID: "s2"}, &domain.SymbolMapping{CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD"}))
+	mappings, err = factory.SymbolRepository().GetAccountMapping(ctx, "s2")
+	require.NoError(t, err)
+	assert.Empty(t, mappings)
+
+	deleted, err := repo.Delete(ctx, "s1", "XAUUSD")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	deleted, err = repo.Delete(ctx, "s1", "XAUUSD")
+	require.NoError(t, err)
+	require.False(t, deleted)
+	assert.Equal(t, []string{"s1", "s1"}, notified)
+}
+
+func TestMemorySymbolProposals(t *testing.T) {
+	ctx := context.Background()
+	repo := NewMemoryFactory(0).SymbolProposalRepository()
+
+	checks := []string{"digits ok"}
+	require.NoError(t, repo.ReplaceForAccount(ctx, "s1", []*domain.SymbolMatchProposal{
+		{BrokerSymbol: "GOLD", CanonicalSymbol: "XAUUSD", Confidence: 0.85, Rule: "alias", Checks: checks},
+		{BrokerSymbol: "EURUSD.x", CanonicalSymbol: "EURUSD", Confidence: 0.65, Rule: "strip"},
+	}))
+	checks[0] = "mutado"
+
+	proposals, err := repo.ListByAccount(ctx, "s1")
+	require.NoError(t, err)
+	require.Len(t, proposals, 2)
+	assert.Equal(t, "EURUSD.x", proposals[0].BrokerSymbol)
+	assert.Equal(t, "s1", proposals[1].AccountID)
+	assert.Equal(t, []string{"digits ok"}, proposals[1].Checks, "copia las propuestas recibidas")
+
+	require.NoError(t, repo.ReplaceForAccount(ctx, "s1", nil))
+	proposals, err = repo.ListByAccount(ctx, "s1")
+	require.NoError(t, err)
+	assert.Empty(t, proposals)
+}
+
+func TestMemoryListFiltered(t *testing.T) {
+	ctx := context.Background()
+	factory := NewMemoryFactory(0)
+	tradeRepo := factory.TradeRepository()
+	execRepo := factory.ExecutionRepository()
+
+	require.NoError(t, tradeRepo.Create(ctx, &domain.Trade{TradeID: "t1", MasterAccountID: "m1", Symbol: "XAUUSD", Status: domain.OrderStatusFilled}))
+	require.NoError(t, tradeRepo.Create(ctx, &domain.Trade{TradeID: "t2", MasterAccountID: "m2", Symbol: "EURUSD", Status: domain.OrderStatusPending}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e2", TradeID: "t1", SlaveAccountID: "s2", Success: false}))
+
+	trades, err := tradeRepo.ListFiltered(ctx, domain.TradeFilter{MasterAccountID: "m1", Symbol: "XAUUSD"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	assert.Equal(t, "t1", trades[0].TradeID)
+
+	now := time.Now()
+	trades, err = tradeRepo.ListFiltered(ctx, domain.TradeFilter{CreatedToMs: now.Add(-time.Hour).UnixMilli()}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, trades)
+
+	succeeded := true
+	execs, err := execRepo.ListFiltered(ctx, domain.ExecutionFilter{Success: &succeeded, CreatedFromMs: now.Add(-time.Hour).UnixMilli()}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, execs, 1)
+	assert.Equal(t, "e1", execs[0].ExecutionID)
+}
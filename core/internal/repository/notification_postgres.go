@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Webhooks no entregados del notifier (i8).
+
+const notificationDeadLetterColumns = `id, rule, url, event_type, event_seq, payload, attempts, last_status, last_error, created_at_ms`
+
+type postgresNotificationDeadLetterRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresNotificationDeadLetterRepo) Create(ctx context.Context, letter *domain.NotificationDeadLetter) error {
+	query := `
+		INSERT INTO echo.notification_dead_letters (` + notificationDeadLetterColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	return createNotificationDeadLetter(ctx, r.db, query, letter)
+}
+
+func (r *postgresNotificationDeadLetterRepo) List(ctx context.Context, limit, offset int) ([]*domain.NotificationDeadLetter, error) {
+	return listNotificationDeadLetters(ctx, r.db,
+		`SELECT `+notificationDeadLetterColumns+` FROM echo.notification_dead_letters
+		ORDER BY created_at_ms DESC, id DESC LIMIT $1 OFFSET $2`,
+		limit, offset,
+	)
+}
+
+// createNotificationDeadLetter es común a PostgreSQL y SQLite (solo cambian los placeholders).
+func createNotificationDeadLetter(ctx context.Context, db *sql.DB, query string, letter *domain.NotificationDeadLetter) error {
+	if _, err := db.ExecContext(ctx, query,
+		letter.ID,
+		letter.Rule,
+		letter.URL,
+		letter.EventType,
+		int64(letter.EventSeq),
+		letter.Payload,
+		letter.Attempts,
+		letter.LastStatus,
+		letter.LastError,
+		letter.CreatedAtMs,
+	); err != nil {
+		return fmt.Errorf("failed to insert notification dead letter %s: %w", letter.ID, err)
+	}
+	return nil
+}
+
+func listNotificationDeadLetters(ctx context.Context, db *sql.DB, query string, limit, offset int) ([]*domain.NotificationDeadLetter, error) {
+	rows, err := db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notification dead letters: %w", err)
+	}
+	defer rows.Close()
+
+	var letters []*domain.NotificationDeadLetter
+	for rows.Next() {
+		var (
+			letter   domain.NotificationDeadLetter
+			eventSeq int64
+		)
+		if err := rows.Scan(
+			&letter.ID,
+			&letter.Rule,
+			&letter.URL,
+			&letter.EventType,
+			&eventSeq,
+			&letter.Payload,
+			&letter.Attempts,
+			&letter.LastStatus,
+			&letter.LastError,
+			&letter.CreatedAtMs,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification dead letter: %w", err)
+		}
+		letter.EventSeq = uint64(eventSeq)
+		letters = append(letters, &letter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate notification dead letters: %w", err)
+	}
+	return letters, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// sqliteNotificationDeadLetterRepo (i8)
+// ===========================================================================
+
+type sqliteNotificationDeadLetterRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteNotificationDeadLetterRepo) Create(ctx context.Context, letter *domain.NotificationDeadLetter) error {
+	query := `
+		INSERT INTO notification_dead_letters (` + notificationDeadLetterColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	return createNotificationDeadLetter(ctx, r.db, query, letter)
+}
+
+func (r *sqliteNotificationDeadLetterRepo) List(ctx context.Context, limit, offset int) ([]*domain.NotificationDeadLetter, error) {
+	return listNotificationDeadLetters(ctx, r.db,
+		`SELECT `+notificationDeadLetterColumns+` FROM notification_dead_letters
+		ORDER BY created_at_ms DESC, id DESC LIMIT ? OFFSET ?`,
+		limit, offset,
+	)
+}
@@ -0,0 +1,74 @@
+package repository
+
+import "sync"
+
+// ChangeNotifier reemplaza en proceso los canales LISTEN/NOTIFY de PostgreSQL.
+//
+// Lo implementan los backends sin NOTIFY (memoria, SQLite). Los callbacks se invocan
+// tras confirmar la escritura y no deben bloquear.
+type ChangeNotifier interface {
+	// OnRiskPolicyChanged equivale al canal echo_risk_policy_updated.
+	OnRiskPolicyChanged(cb func(accountID, strategyID string))
+
+	// OnHandshakeEvaluated equivale al canal echo_handshake_result.
+	OnHandshakeEvaluated(cb func(accountID, evaluationID string))
+
+	// OnSymbolMappingChanged equivale al canal echo_symbol_mapping_updated (overrides i8).
+	OnSymbolMappingChanged(cb func(accountID string))
+}
+
+// changeHub almacena los callbacks registrados vía ChangeNotifier.
+type changeHub struct {
+	mu            sync.RWMutex
+	riskPolicy    func(accountID, strategyID string)
+	handshake     func(accountID, evaluationID string)
+	symbolMapping func(accountID string)
+}
+
+// OnRiskPolicyChanged registra el callback de cambios de política de riesgo.
+func (h *changeHub) OnRiskPolicyChanged(cb func(accountID, strategyID string)) {
+	h.mu.Lock()
+	h.riskPolicy = cb
+	h.mu.Unlock()
+}
+
+// OnHandshakeEvaluated registra el callback de nuevas evaluaciones de handshake.
+func (h *changeHub) OnHandshakeEvaluated(cb func(accountID, evaluationID string)) {
+	h.mu.Lock()
+	h.handshake = cb
+	h.mu.Unlock()
+}
+
+// OnSymbolMappingChanged registra el callback de cambios de overrides de símbolos.
+func (h *changeHub) OnSymbolMappingChanged(cb func(accountID string)) {
+	h.mu.Lock()
+	h.symbolMapping = cb
+	h.mu.Unlock()
+}
+
+func (h *changeHub) riskPolicyChanged(accountID, strategyID string) {
+	h.mu.RLock()
+	cb := h.riskPolicy
+	h.mu.RUnlock()
+	if cb != nil {
+		cb(accountID, strategyID)
+	}
+}
+
+func (h *changeHub) handshakeEvaluated(accountID, evaluationID string) {
+	h.mu.RLock()
+	cb := h.handshake
+	h.mu.RUnlock()
+	if cb != nil {
+		cb(accountID, evaluationID)
+	}
+}
+
+func (h *changeHub) symbolMappingChanged(accountID string) {
+	h.mu.RLock()
+	cb := h.symbolMapping
+	h.mu.RUnlock()
+	if cb != nil {
+		cb(accountID)
+	}
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Outbox transaccional de comandos hacia Agents (i8).
+//
+// EnqueueTrade escribe trade, dedupe y comandos en una transacción: si Core cae antes
+// de entregar, el dispatcher encuentra los comandos al reiniciar.
+
+const outboxColumns = `
+	command_id, trade_id, target_account_id, command_type, payload, status,
+	attempts, last_agent_id, created_at_ms, dispatched_at_ms, completed_at_ms,
+	strategy_id, risk_policy_type, risk_policy_version, symbol, reference_price, point
+`
+
+type postgresOutboxRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresOutboxRepo) EnqueueTrade(ctx context.Context, trade *domain.Trade, dedupe *domain.DedupeEntry, commands []*domain.OutboxCommand) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = (&postgresTradeRepo{db: tx}).Create(ctx, trade); err != nil {
+		return err
+	}
+	if err = (&postgresDedupeRepo{db: tx}).Upsert(ctx, dedupe); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO echo.command_outbox (
+			command_id, trade_id, target_account_id, command_type, payload, status,
+			attempts, last_agent_id, created_at_ms, dispatched_at_ms,
+			strategy_id, risk_policy_type, risk_policy_version, symbol, reference_price, point
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
+		)
+	`
+	for _, command := range commands {
+		queued := enqueuedOutboxCommand(command)
+		if _, err = tx.ExecContext(ctx, query,
+			queued.CommandID,
+			queued.TradeID,
+			queued.TargetAccountID,
+			queued.CommandType,
+			queued.Payload,
+			queued.Status,
+			queued.Attempts,
+			queued.LastAgentID,
+			queued.CreatedAtMs,
+			queued.DispatchedAtMs,
+			queued.StrategyID,
+			queued.RiskPolicyType,
+			queued.RiskPolicyVersion,
+			queued.Symbol,
+			queued.ReferencePrice,
+			queued.Point,
+		); err != nil {
+			return fmt.Errorf("failed to enqueue command %s: %w", command.CommandID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit outbox tx: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresOutboxRepo) GetByID(ctx context.Context, commandID string) (*domain.OutboxCommand, error) {
+	query := `SELECT ` + outboxColumns + ` FROM echo.command_outbox WHERE command_id = $1`
+	commands, err := r.queryCommands(ctx, query, commandID)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	return commands[0], nil
+}
+
+func (r *postgresOutboxRepo) ListDeliverable(ctx context.Context, redeliverBeforeMs int64, limit int) ([]*domain.OutboxCommand, error) {
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM echo.command_outbox
+		WHERE status = 'PENDING'
+		   OR (status = 'DISPATCHED' AND dispatched_at_ms <= $1)
+		ORDER BY created_at_ms ASC
+		LIMIT $2
+	`
+	return r.queryCommands(ctx, query, redeliverBeforeMs, limit)
+}
+
+func (r *postgresOutboxRepo) MarkDispatched(ctx context.Context, commandID, agentID string, dispatchedAtMs int64) error {
+	query := `
+		UPDATE echo.command_outbox
+		SET status = 'DISPATCHED', attempts = attempts + 1, last_agent_id = $2, dispatched_at_ms = $3
+		WHERE command_id = $1 AND status IN ('PENDING', 'DISPATCHED')
+	`
+	if _, err := r.db.ExecContext(ctx, query, commandID, agentID, dispatchedAtMs); err != nil {
+		return fmt.Errorf("failed to mark command dispatched: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresOutboxRepo) MarkAcked(ctx context.Context, commandID string, ackedAtMs int64) (bool, error) {
+	query := `
+		UPDATE echo.command_outbox
+		SET status = 'ACKED', completed_at_ms = $2
+		WHERE command_id = $1 AND status <> 'ACKED'
+	`
+	result, err := r.db.ExecContext(ctx, query, commandID, ackedAtMs)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark command acked: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return updated > 0, nil
+}
+
+func (r *postgresOutboxRepo) ExpireOlderThan(ctx context.Context, cutoffMs, expiredAtMs int64) ([]*domain.OutboxCommand, error) {
+	query := `
+		UPDATE echo.command_outbox
+		SET status = 'EXPIRED', completed_at_ms = $2
+		WHERE status IN ('PENDING', 'DISPATCHED') AND created_at_ms < $1
+		RETURNING ` + outboxColumns
+	return r.queryCommands(ctx, query, cutoffMs, expiredAtMs)
+}
+
+func (r *postgresOutboxRepo) PurgeCompleted(ctx context.Context, cutoffMs int64) (int, error) {
+	query := `
+		DELETE FROM echo.command_outbox
+		WHERE status IN ('ACKED', 'EXPIRED') AND completed_at_ms < $1
+	`
+	result, err := r.db.ExecContext(ctx, query, cutoffMs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(deleted), nil
+}
+
+func (r *postgresOutboxRepo) queryCommands(ctx context.Context, query string, args ...interface{}) ([]*domain.OutboxCommand, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var commands []*domain.OutboxCommand
+	for rows.Next() {
+		command, err := scanOutboxCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return commands, nil
+}
+
+// enqueuedOutboxCommand normaliza el estado inicial de un comando (común a los backends).
+//
+// Solo un comando entregado al encolar (DISPATCHED) conserva sus datos de entrega.
+func enqueuedOutboxCommand(command *domain.OutboxCommand) *domain.OutboxCommand {
+	queued := *command
+	queued.CompletedAtMs = 0
+	if queued.Status == domain.OutboxStatusDispatched {
+		return &queued
+	}
+	queued.Status = domain.OutboxStatusPending
+	queued.Attempts = 0
+	queued.LastAgentID = ""
+	queued.DispatchedAtMs = 0
+	return &queued
+}
+
+// scanOutboxCommand es común a PostgreSQL y SQLite (mismas columnas y tipos).
+func scanOutboxCommand(rows *sql.Rows) (*domain.OutboxCommand, error) {
+	var command domain.OutboxCommand
+	err := rows.Scan(
+		&command.CommandID,
+		&command.TradeID,
+		&command.TargetAccountID,
+		&command.CommandType,
+		&command.Payload,
+		&command.Status,
+		&command.Attempts,
+		&command.LastAgentID,
+		&command.CreatedAtMs,
+		&command.DispatchedAtMs,
+		&command.CompletedAtMs,
+		&command.StrategyID,
+		&command.RiskPolicyType,
+		&command.RiskPolicyVersion,
+		&command.Symbol,
+		&command.ReferencePrice,
+		&command.Point,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan outbox command: %w", err)
+	}
+	return &command, nil
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// sqliteOutboxRepo (i8)
+// ===========================================================================
+
+type sqliteOutboxRepo struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func (r *sqliteOutboxRepo) EnqueueTrade(ctx context.Context, trade *domain.Trade, dedupe *domain.DedupeEntry, commands []*domain.OutboxCommand) (err error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err = (&sqliteTradeRepo{db: tx}).Create(ctx, trade); err != nil {
+		return err
+	}
+	if err = (&sqliteDedupeRepo{db: tx, now: r.now}).Upsert(ctx, dedupe); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO command_outbox (
+			command_id, trade_id, target_account_id, command_type, payload, status,
+			attempts, last_agent_id, created_at_ms, dispatched_at_ms,
+			strategy_id, risk_policy_type, risk_policy_version, symbol, reference_price, point
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, command := range commands {
+		queued := enqueuedOutboxCommand(command)
+		if _, err = tx.ExecContext(ctx, query,
+			queued.CommandID,
+			queued.TradeID,
+			queued.TargetAccountID,
+			queued.CommandType,
+			queued.Payload,
+			queued.Status,
+			queued.Attempts,
+			queued.LastAgentID,
+			queued.CreatedAtMs,
+			queued.DispatchedAtMs,
+			queued.StrategyID,
+			queued.RiskPolicyType,
+			queued.RiskPolicyVersion,
+			queued.Symbol,
+			queued.ReferencePrice,
+			queued.Point,
+		); err != nil {
+			return fmt.Errorf("failed to enqueue command %s: %w", command.CommandID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit outbox tx: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteOutboxRepo) GetByID(ctx context.Context, commandID string) (*domain.OutboxCommand, error) {
+	query := `SELECT ` + outboxColumns + ` FROM command_outbox WHERE command_id = ?`
+	commands, err := r.queryCommands(ctx, query, commandID)
+	if err != nil {
+		return nil, err
+	}
+	if len(commands) == 0 {
+		return nil, nil
+	}
+	return commands[0], nil
+}
+
+func (r *sqliteOutboxRepo) ListDeliverable(ctx context.Context, redeliverBeforeMs int64, limit int) ([]*domain.OutboxCommand, error) {
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM command_outbox
+		WHERE status = 'PENDING'
+		   OR (status = 'DISPATCHED' AND dispatched_at_ms <= ?)
+		ORDER BY created_at_ms ASC, rowid ASC
+		LIMIT ?
+	`
+	return r.queryCommands(ctx, query, redeliverBeforeMs, limit)
+}
+
+func (r *sqliteOutboxRepo) MarkDispatched(ctx context.Context, commandID, agentID string, dispatchedAtMs int64) error {
+	query := `
+		UPDATE command_outbox
+		SET status = 'DISPATCHED', attempts = attempts + 1, last_agent_id = ?, dispatched_at_ms = ?
+		WHERE command_id = ? AND status IN ('PENDING', 'DISPATCHED')
+	`
+	if _, err := r.db.ExecContext(ctx, query, agentID, dispatchedAtMs, commandID); err != nil {
+		return fmt.Errorf("failed to mark command dispatched: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteOutboxRepo) MarkAcked(ctx context.Context, commandID string, ackedAtMs int64) (bool, error) {
+	query := `
+		UPDATE command_outbox
+		SET status = 'ACKED', completed_at_ms = ?
+		WHERE command_id = ? AND status <> 'ACKED'
+	`
+	result, err := r.db.ExecContext(ctx, query, ackedAtMs, commandID)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark command acked: %w", err)
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return updated > 0, nil
+}
+
+func (r *sqliteOutboxRepo) ExpireOlderThan(ctx context.Context, cutoffMs, expiredAtMs int64) ([]*domain.OutboxCommand, error) {
+	query := `
+		UPDATE command_outbox
+		SET status = 'EXPIRED', completed_at_ms = ?
+		WHERE status IN ('PENDING', 'DISPATCHED') AND created_at_ms < ?
+		RETURNING ` + outboxColumns
+	return r.queryCommands(ctx, query, expiredAtMs, cutoffMs)
+}
+
+func (r *sqliteOutboxRepo) PurgeCompleted(ctx context.Context, cutoffMs int64) (int, error) {
+	query := `
+		DELETE FROM command_outbox
+		WHERE status IN ('ACKED', 'EXPIRED') AND completed_at_ms < ?
+	`
+	result, err := r.db.ExecContext(ctx, query, cutoffMs)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge outbox: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(deleted), nil
+}
+
+func (r *sqliteOutboxRepo) queryCommands(ctx context.Context, query string, args ...interface{}) ([]*domain.OutboxCommand, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var commands []*domain.OutboxCommand
+	for rows.Next() {
+		command, err := scanOutboxCommand(rows)
+		if err != nil {
+			return nil, err
+		}
+		commands = append(commands, command)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return commands, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Ownership de cuentas (i8).
+//
+// Upsert nunca toca forced_agent_id: ese campo lo escribe echo-core-cli
+// (RequestTakeover) y lo limpia el Core al aplicarlo (ClearTakeover).
+
+const accountOwnershipColumns = `
+	account_id, agent_id, pipe_role, status, registered_at_ms, updated_at_ms,
+	claimant_agent_id, conflict_at_ms, forced_agent_id
+`
+
+type postgresAccountOwnershipRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresAccountOwnershipRepo) Upsert(ctx context.Context, ownership *domain.AccountOwnership) error {
+	query := `
+		INSERT INTO echo.account_ownership (` + accountOwnershipColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, '')
+		ON CONFLICT (account_id) DO UPDATE SET
+			agent_id = EXCLUDED.agent_id,
+			pipe_role = EXCLUDED.pipe_role,
+			status = EXCLUDED.status,
+			registered_at_ms = EXCLUDED.registered_at_ms,
+			updated_at_ms = EXCLUDED.updated_at_ms,
+			claimant_agent_id = EXCLUDED.claimant_agent_id,
+			conflict_at_ms = EXCLUDED.conflict_at_ms
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		ownership.AccountID,
+		ownership.AgentID,
+		ownership.PipeRole,
+		ownership.Status,
+		ownership.RegisteredAtMs,
+		ownership.UpdatedAtMs,
+		ownership.ClaimantAgentID,
+		ownership.ConflictAtMs,
+	); err != nil {
+		return fmt.Errorf("failed to upsert account ownership %s: %w", ownership.AccountID, err)
+	}
+	return nil
+}
+
+func (r *postgresAccountOwnershipRepo) Delete(ctx context.Context, accountID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM echo.account_ownership WHERE account_id = $1`, accountID); err != nil {
+		return fmt.Errorf("failed to delete account ownership %s: %w", accountID, err)
+	}
+	return nil
+}
+
+func (r *postgresAccountOwnershipRepo) List(ctx context.Context) ([]*domain.AccountOwnership, error) {
+	return listAccountOwnership(ctx, r.db,
+		`SELECT `+accountOwnershipColumns+` FROM echo.account_ownership ORDER BY account_id ASC`,
+	)
+}
+
+func (r *postgresAccountOwnershipRepo) RequestTakeover(ctx context.Context, accountID, agentID string, nowMs int64) error {
+	query := `
+		INSERT INTO echo.account_ownership (` + accountOwnershipColumns + `)
+		VALUES ($1, $2, '', 'DETACHED', $3, $3, '', 0, $2)
+		ON CONFLICT (account_id) DO UPDATE SET
+			forced_agent_id = EXCLUDED.forced_agent_id,
+			updated_at_ms = EXCLUDED.updated_at_ms
+	`
+	if _, err := r.db.ExecContext(ctx, query, accountID, agentID, nowMs); err != nil {
+		return fmt.Errorf("failed to request takeover of %s: %w", accountID, err)
+	}
+	return nil
+}
+
+func (r *postgresAccountOwnershipRepo) ClearTakeover(ctx context.Context, accountID, agentID string) error {
+	query := `UPDATE echo.account_ownership SET forced_agent_id = '' WHERE account_id = $1 AND forced_agent_id = $2`
+	if _, err := r.db.ExecContext(ctx, query, accountID, agentID); err != nil {
+		return fmt.Errorf("failed to clear takeover of %s: %w", accountID, err)
+	}
+	return nil
+}
+
+// listAccountOwnership es común a PostgreSQL y SQLite (mismas columnas y tipos).
+func listAccountOwnership(ctx context.Context, db *sql.DB, query string) ([]*domain.AccountOwnership, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account ownership: %w", err)
+	}
+	defer rows.Close()
+
+	var accounts []*domain.AccountOwnership
+	for rows.Next() {
+		var ownership domain.AccountOwnership
+		if err := rows.Scan(
+			&ownership.AccountID,
+			&ownership.AgentID,
+			&ownership.PipeRole,
+			&ownership.Status,
+			&ownership.RegisteredAtMs,
+			&ownership.UpdatedAtMs,
+			&ownership.ClaimantAgentID,
+			&ownership.ConflictAtMs,
+			&ownership.ForcedAgentID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan account ownership: %w", err)
+		}
+		accounts = append(accounts, &ownership)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate account ownership: %w", err)
+	}
+	return accounts, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// sqliteAccountOwnershipRepo (i8)
+// ===========================================================================
+
+type sqliteAccountOwnershipRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteAccountOwnershipRepo) Upsert(ctx context.Context, ownership *domain.AccountOwnership) error {
+	query := `
+		INSERT INTO account_ownership (` + accountOwnershipColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, '')
+		ON CONFLICT (account_id) DO UPDATE SET
+			agent_id = excluded.agent_id,
+			pipe_role = excluded.pipe_role,
+			status = excluded.status,
+			registered_at_ms = excluded.registered_at_ms,
+			updated_at_ms = excluded.updated_at_ms,
+			claimant_agent_id = excluded.claimant_agent_id,
+			conflict_at_ms = excluded.conflict_at_ms
+	`
+	if _, err := r.db.ExecContext(ctx, query,
+		ownership.AccountID,
+		ownership.AgentID,
+		ownership.PipeRole,
+		ownership.Status,
+		ownership.RegisteredAtMs,
+		ownership.UpdatedAtMs,
+		ownership.ClaimantAgentID,
+		ownership.ConflictAtMs,
+	); err != nil {
+		return fmt.Errorf("failed to upsert account ownership %s: %w", ownership.AccountID, err)
+	}
+	return nil
+}
+
+func (r *sqliteAccountOwnershipRepo) Delete(ctx context.Context, accountID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM account_ownership WHERE account_id = ?`, accountID); err != nil {
+		return fmt.Errorf("failed to delete account ownership %s: %w", accountID, err)
+	}
+	return nil
+}
+
+func (r *sqliteAccountOwnershipRepo) List(ctx context.Context) ([]*domain.AccountOwnership, error) {
+	return listAccountOwnership(ctx, r.db,
+		`SELECT `+accountOwnershipColumns+` FROM account_ownership ORDER BY account_id ASC`,
+	)
+}
+
+func (r *sqliteAccountOwnershipRepo) RequestTakeover(ctx context.Context, accountID, agentID string, nowMs int64) error {
+	query := `
+		INSERT INTO account_ownership (` + accountOwnershipColumns + `)
+		VALUES (?1, ?2, '', 'DETACHED', ?3, ?3, '', 0, ?2)
+		ON CONFLICT (account_id) DO UPDATE SET
+			forced_agent_id = excluded.forced_agent_id,
+			updated_at_ms = excluded.updated_at_ms
+	`
+	if _, err := r.db.ExecContext(ctx, query, accountID, agentID, nowMs); err != nil {
+		return fmt.Errorf("failed to request takeover of %s: %w", accountID, err)
+	}
+	return nil
+}
+
+func (r *sqliteAccountOwnershipRepo) ClearTakeover(ctx context.Context, accountID, agentID string) error {
+	query := `UPDATE account_ownership SET forced_agent_id = '' WHERE account_id = ? AND forced_agent_id = ?`
+	if _, err := r.db.ExecContext(ctx, query, accountID, agentID); err != nil {
+		return fmt.Errorf("failed to clear takeover of %s: %w", accountID, err)
+	}
+	return nil
+}
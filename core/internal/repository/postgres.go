@@ -15,6 +15,14 @@ import (
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+// sqlConn abstrae *sql.DB y *sql.Tx para reutilizar las sentencias de un repositorio
+// dentro de una transacción (outbox i8).
+type sqlConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 // PostgresFactory implementa domain.RepositoryFactory para PostgreSQL.
 type PostgresFactory struct {
 	db *sql.DB
@@ -24,10 +32,19 @@ type PostgresFactory struct {
 	executionRepo   domain.ExecutionRepository
 	dedupeRepo      domain.DedupeRepository
 	closeRepo       domain.CloseRepository
+	modifyRepo      domain.ModifyRepository
+	lifecycleRepo   domain.TradeLifecycleRepository
+	outboxRepo      domain.OutboxRepository
+	livenessRepo    domain.AgentLivenessRepository
+	ownershipRepo   domain.AccountOwnershipRepository
+	copyControlRepo domain.AccountCopyControlRepository
+	deadLetterRepo  domain.NotificationDeadLetterRepository
 	correlationSvc  domain.CorrelationService
 	symbolRepo      domain.SymbolRepository // NEW i3
 	symbolSpecRepo  domain.SymbolSpecRepository
 	symbolQuoteRepo domain.SymbolQuoteRepository
+	overrideRepo    domain.SymbolOverrideRepository
+	proposalRepo    domain.SymbolProposalRepository
 	riskPolicyRepo  domain.RiskPolicyRepository
 	handshakeRepo   domain.HandshakeEvaluationRepository
 }
@@ -77,6 +94,62 @@ func (f *PostgresFactory) CloseRepository() domain.CloseRepository {
 	return f.closeRepo
 }
 
+// ModifyRepository retorna el repositorio de cambios de SL/TP (i8).
+func (f *PostgresFactory) ModifyRepository() domain.ModifyRepository {
+	if f.modifyRepo == nil {
+		f.modifyRepo = &postgresModifyRepo{db: f.db}
+	}
+	return f.modifyRepo
+}
+
+// TradeLifecycleRepository retorna el repositorio de ciclo de vida por trade×slave (i8).
+func (f *PostgresFactory) TradeLifecycleRepository() domain.TradeLifecycleRepository {
+	if f.lifecycleRepo == nil {
+		f.lifecycleRepo = NewTradeLifecycleRepository(f.ExecutionRepository(), f.ModifyRepository(), f.CloseRepository())
+	}
+	return f.lifecycleRepo
+}
+
+// OutboxRepository retorna el outbox transaccional de comandos (i8).
+func (f *PostgresFactory) OutboxRepository() domain.OutboxRepository {
+	if f.outboxRepo == nil {
+		f.outboxRepo = &postgresOutboxRepo{db: f.db}
+	}
+	return f.outboxRepo
+}
+
+// AgentLivenessRepository retorna el estado de liveness de Agents y cuentas (i8).
+func (f *PostgresFactory) AgentLivenessRepository() domain.AgentLivenessRepository {
+	if f.livenessRepo == nil {
+		f.livenessRepo = &postgresAgentLivenessRepo{db: f.db}
+	}
+	return f.livenessRepo
+}
+
+// AccountOwnershipRepository retorna el ownership persistido de cuentas (i8).
+func (f *PostgresFactory) AccountOwnershipRepository() domain.AccountOwnershipRepository {
+	if f.ownershipRepo == nil {
+		f.ownershipRepo = &postgresAccountOwnershipRepo{db: f.db}
+	}
+	return f.ownershipRepo
+}
+
+// AccountCopyControlRepository retorna la habilitación de copia por cuenta (i8).
+func (f *PostgresFactory) AccountCopyControlRepository() domain.AccountCopyControlRepository {
+	if f.copyControlRepo == nil {
+		f.copyControlRepo = &postgresAccountCopyControlRepo{db: f.db}
+	}
+	return f.copyControlRepo
+}
+
+// NotificationDeadLetterRepository retorna los webhooks no entregados (i8).
+func (f *PostgresFactory) NotificationDeadLetterRepository() domain.NotificationDeadLetterRepository {
+	if f.deadLetterRepo == nil {
+		f.deadLetterRepo = &postgresNotificationDeadLetterRepo{db: f.db}
+	}
+	return f.deadLetterRepo
+}
+
 // CorrelationService retorna el servicio de correlación.
 func (f *PostgresFactory) CorrelationService() domain.CorrelationService {
 	if f.correlationSvc == nil {
@@ -113,6 +186,22 @@ func (f *PostgresFactory) SymbolQuoteRepository() domain.SymbolQuoteRepository {
 	return f.symbolQuoteRepo
 }
 
+// SymbolOverrideRepository retorna los overrides manuales de mapeo de símbolos (i8).
+func (f *PostgresFactory) SymbolOverrideRepository() domain.SymbolOverrideRepository {
+	if f.overrideRepo == nil {
+		f.overrideRepo = &postgresSymbolOverrideRepo{db: f.db}
+	}
+	return f.overrideRepo
+}
+
+// SymbolProposalRepository retorna los mapeos sugeridos por el matcher de símbolos (i8).
+func (f *PostgresFactory) SymbolProposalRepository() domain.SymbolProposalRepository {
+	if f.proposalRepo == nil {
+		f.proposalRepo = &postgresSymbolProposalRepo{db: f.db}
+	}
+	return f.proposalRepo
+}
+
 // RiskPolicyRepository retorna el repositorio de políticas de riesgo.
 func (f *PostgresFactory) RiskPolicyRepository() domain.RiskPolicyRepository {
 	if f.riskPolicyRepo == nil {
@@ -134,7 +223,7 @@ func (f *PostgresFactory) HandshakeRepository() domain.HandshakeEvaluationReposi
 // ===========================================================================
 
 type postgresTradeRepo struct {
-	db *sql.DB
+	db sqlConn
 }
 
 func (r *postgresTradeRepo) Create(ctx context.Context, trade *domain.Trade) error {
@@ -296,6 +385,20 @@ func (r *postgresTradeRepo) ListByStatus(ctx context.Context, status domain.Orde
 	return r.queryTrades(ctx, query, status, limit, offset)
 }
 
+func (r *postgresTradeRepo) ListFiltered(ctx context.Context, filter domain.TradeFilter, limit, offset int) ([]*domain.Trade, error) {
+	where := tradeFilterWhere(filter, true)
+	query := `
+		SELECT trade_id, source_master_id, master_account_id, master_ticket,
+		       magic_number, symbol, side, lot_size, price,
+		       stop_loss, take_profit, comment,
+		       status, attempt, opened_at_ms, created_at, updated_at
+		FROM echo.trades
+		` + where.clause() + `
+		ORDER BY created_at DESC
+		` + where.limitOffset(limit, offset)
+	return r.queryTrades(ctx, query, where.args...)
+}
+
 func (r *postgresTradeRepo) queryTrades(ctx context.Context, query string, args ...interface{}) ([]*domain.Trade, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -357,9 +460,9 @@ func (r *postgresExecutionRepo) Create(ctx context.Context, exec *domain.Executi
 		INSERT INTO echo.executions (
 			execution_id, trade_id, slave_account_id, agent_id,
 			slave_ticket, executed_price, success, error_code, error_message,
-			timestamps_ms
+			timestamps_ms, strategy_id, risk_policy_type, risk_policy_version
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 		)
 	`
 	_, err = r.db.ExecContext(ctx, query,
@@ -373,6 +476,9 @@ func (r *postgresExecutionRepo) Create(ctx context.Context, exec *domain.Executi
 		exec.ErrorCode,
 		exec.ErrorMessage,
 		timestampsJSON,
+		nullableString(exec.StrategyID),
+		nullableString(exec.RiskPolicyType),
+		exec.RiskPolicyVersion,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create execution: %w", err)
@@ -384,12 +490,13 @@ func (r *postgresExecutionRepo) GetByID(ctx context.Context, executionID string)
 	query := `
 		SELECT execution_id, trade_id, slave_account_id, agent_id,
 		       slave_ticket, executed_price, success, error_code, error_message,
-		       timestamps_ms, created_at
+		       timestamps_ms, strategy_id, risk_policy_type, risk_policy_version, created_at
 		FROM echo.executions
 		WHERE execution_id = $1
 	`
 	var exec domain.Execution
 	var timestampsJSON []byte
+	var strategyID, riskPolicyType sql.NullString
 	err := r.db.QueryRowContext(ctx, query, executionID).Scan(
 		&exec.ExecutionID,
 		&exec.TradeID,
@@ -401,6 +508,9 @@ func (r *postgresExecutionRepo) GetByID(ctx context.Context, executionID string)
 		&exec.ErrorCode,
 		&exec.ErrorMessage,
 		&timestampsJSON,
+		&strategyID,
+		&riskPolicyType,
+		&exec.RiskPolicyVersion,
 		&exec.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -414,6 +524,8 @@ func (r *postgresExecutionRepo) GetByID(ctx context.Context, executionID string)
 	if err := json.Unmarshal(timestampsJSON, &exec.TimestampsMs); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal timestamps: %w", err)
 	}
+	exec.StrategyID = strategyID.String
+	exec.RiskPolicyType = riskPolicyType.String
 
 	return &exec, nil
 }
@@ -422,7 +534,7 @@ func (r *postgresExecutionRepo) GetByTradeID(ctx context.Context, tradeID string
 	query := `
 		SELECT execution_id, trade_id, slave_account_id, agent_id,
 		       slave_ticket, executed_price, success, error_code, error_message,
-		       timestamps_ms, created_at
+		       timestamps_ms, strategy_id, risk_policy_type, risk_policy_version, created_at
 		FROM echo.executions
 		WHERE trade_id = $1
 		ORDER BY created_at ASC
@@ -434,7 +546,7 @@ func (r *postgresExecutionRepo) GetByTradeAndSlave(ctx context.Context, tradeID,
 	query := `
 		SELECT execution_id, trade_id, slave_account_id, agent_id,
 		       slave_ticket, executed_price, success, error_code, error_message,
-		       timestamps_ms, created_at
+		       timestamps_ms, strategy_id, risk_policy_type, risk_policy_version, created_at
 		FROM echo.executions
 		WHERE trade_id = $1 AND slave_account_id = $2
 		ORDER BY created_at DESC
@@ -473,7 +585,7 @@ func (r *postgresExecutionRepo) List(ctx context.Context, limit, offset int) ([]
 	query := `
 		SELECT execution_id, trade_id, slave_account_id, agent_id,
 		       slave_ticket, executed_price, success, error_code, error_message,
-		       timestamps_ms, created_at
+		       timestamps_ms, strategy_id, risk_policy_type, risk_policy_version, created_at
 		FROM echo.executions
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -485,7 +597,7 @@ func (r *postgresExecutionRepo) ListBySuccess(ctx context.Context, success bool,
 	query := `
 		SELECT execution_id, trade_id, slave_account_id, agent_id,
 		       slave_ticket, executed_price, success, error_code, error_message,
-		       timestamps_ms, created_at
+		       timestamps_ms, strategy_id, risk_policy_type, risk_policy_version, created_at
 		FROM echo.executions
 		WHERE success = $1
 		ORDER BY created_at DESC
@@ -494,6 +606,19 @@ func (r *postgresExecutionRepo) ListBySuccess(ctx context.Context, success bool,
 	return r.queryExecutions(ctx, query, success, limit, offset)
 }
 
+func (r *postgresExecutionRepo) ListFiltered(ctx context.Context, filter domain.ExecutionFilter, limit, offset int) ([]*domain.Execution, error) {
+	where := executionFilterWhere(filter, true)
+	query := `
+		SELECT execution_id, trade_id, slave_account_id, agent_id,
+		       slave_ticket, executed_price, success, error_code, error_message,
+		       timestamps_ms, strategy_id, risk_policy_type, risk_policy_version, created_at
+		FROM echo.executions
+		` + where.clause() + `
+		ORDER BY created_at DESC
+		` + where.limitOffset(limit, offset)
+	return r.queryExecutions(ctx, query, where.args...)
+}
+
 func (r *postgresExecutionRepo) queryExecutions(ctx context.Context, query string, args ...interface{}) ([]*domain.Execution, error) {
 	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -505,6 +630,7 @@ func (r *postgresExecutionRepo) queryExecutions(ctx context.Context, query strin
 	for rows.Next() {
 		var exec domain.Execution
 		var timestampsJSON []byte
+		var strategyID, riskPolicyType sql.NullString
 		err := rows.Scan(
 			&exec.ExecutionID,
 			&exec.TradeID,
@@ -516,6 +642,9 @@ func (r *postgresExecutionRepo) queryExecutions(ctx context.Context, query strin
 			&exec.ErrorCode,
 			&exec.ErrorMessage,
 			&timestampsJSON,
+			&strategyID,
+			&riskPolicyType,
+			&exec.RiskPolicyVersion,
 			&exec.CreatedAt,
 		)
 		if err != nil {
@@ -526,6 +655,8 @@ func (r *postgresExecutionRepo) queryExecutions(ctx context.Context, query strin
 		if err := json.Unmarshal(timestampsJSON, &exec.TimestampsMs); err != nil {
 			return nil, fmt.Errorf("failed to unmarshal timestamps: %w", err)
 		}
+		exec.StrategyID = strategyID.String
+		exec.RiskPolicyType = riskPolicyType.String
 
 		execs = append(execs, &exec)
 	}
@@ -542,7 +673,7 @@ func (r *postgresExecutionRepo) queryExecutions(ctx context.Context, query strin
 // ===========================================================================
 
 type postgresDedupeRepo struct {
-	db *sql.DB
+	db sqlConn
 }
 
 func (r *postgresDedupeRepo) Upsert(ctx context.Context, entry *domain.DedupeEntry) error {
@@ -634,9 +765,10 @@ func (r *postgresCloseRepo) Create(ctx context.Context, close *domain.Close) err
 	query := `
 		INSERT INTO echo.closes (
 			close_id, trade_id, slave_account_id, slave_ticket,
-			close_price, success, error_code, error_message, closed_at_ms
+			close_price, success, error_code, error_message,
+			profit, commission, swap, closed_volume, remaining_volume, closed_at_ms
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
 		)
 	`
 	_, err := r.db.ExecContext(ctx, query,
@@ -648,6 +780,11 @@ func (r *postgresCloseRepo) Create(ctx context.Context, close *domain.Close) err
 		close.Success,
 		close.ErrorCode,
 		close.ErrorMessage,
+		close.Profit,
+		close.Commission,
+		close.Swap,
+		close.ClosedVolume,
+		close.RemainingVolume,
 		close.ClosedAtMs,
 	)
 	if err != nil {
@@ -659,7 +796,9 @@ func (r *postgresCloseRepo) Create(ctx context.Context, close *domain.Close) err
 func (r *postgresCloseRepo) GetByID(ctx context.Context, closeID string) (*domain.Close, error) {
 	query := `
 		SELECT close_id, trade_id, slave_account_id, slave_ticket,
-		       close_price, success, error_code, error_message, closed_at_ms, created_at
+		       close_price, success, error_code, error_message,
+		       profit, commission, swap, closed_volume, remaining_volume,
+		       closed_at_ms, created_at
 		FROM echo.closes
 		WHERE close_id = $1
 	`
@@ -673,6 +812,11 @@ func (r *postgresCloseRepo) GetByID(ctx context.Context, closeID string) (*domai
 		&close.Success,
 		&close.ErrorCode,
 		&close.ErrorMessage,
+		&close.Profit,
+		&close.Commission,
+		&close.Swap,
+		&close.ClosedVolume,
+		&close.RemainingVolume,
 		&close.ClosedAtMs,
 		&close.CreatedAt,
 	)
@@ -688,7 +832,9 @@ func (r *postgresCloseRepo) GetByID(ctx context.Context, closeID string) (*domai
 func (r *postgresCloseRepo) GetByTradeID(ctx context.Context, tradeID string) ([]*domain.Close, error) {
 	query := `
 		SELECT close_id, trade_id, slave_account_id, slave_ticket,
-		       close_price, success, error_code, error_message, closed_at_ms, created_at
+		       close_price, success, error_code, error_message,
+		       profit, commission, swap, closed_volume, remaining_volume,
+		       closed_at_ms, created_at
 		FROM echo.closes
 		WHERE trade_id = $1
 		ORDER BY created_at ASC
@@ -699,7 +845,9 @@ func (r *postgresCloseRepo) GetByTradeID(ctx context.Context, tradeID string) ([
 func (r *postgresCloseRepo) GetByTradeAndSlave(ctx context.Context, tradeID, slaveAccountID string) (*domain.Close, error) {
 	query := `
 		SELECT close_id, trade_id, slave_account_id, slave_ticket,
-		       close_price, success, error_code, error_message, closed_at_ms, created_at
+		       close_price, success, error_code, error_message,
+		       profit, commission, swap, closed_volume, remaining_volume,
+		       closed_at_ms, created_at
 		FROM echo.closes
 		WHERE trade_id = $1 AND slave_account_id = $2
 		ORDER BY created_at DESC
@@ -718,7 +866,9 @@ func (r *postgresCloseRepo) GetByTradeAndSlave(ctx context.Context, tradeID, sla
 func (r *postgresCloseRepo) List(ctx context.Context, limit, offset int) ([]*domain.Close, error) {
 	query := `
 		SELECT close_id, trade_id, slave_account_id, slave_ticket,
-		       close_price, success, error_code, error_message, closed_at_ms, created_at
+		       close_price, success, error_code, error_message,
+		       profit, commission, swap, closed_volume, remaining_volume,
+		       closed_at_ms, created_at
 		FROM echo.closes
 		ORDER BY created_at DESC
 		LIMIT $1 OFFSET $2
@@ -745,6 +895,11 @@ func (r *postgresCloseRepo) queryCloses(ctx context.Context, query string, args
 			&close.Success,
 			&close.ErrorCode,
 			&close.ErrorMessage,
+			&close.Profit,
+			&close.Commission,
+			&close.Swap,
+			&close.ClosedVolume,
+			&close.RemainingVolume,
 			&close.ClosedAtMs,
 			&close.CreatedAt,
 		)
@@ -777,15 +932,34 @@ func (r *postgresSymbolRepo) UpsertAccountMapping(ctx context.Context, accountID
 	}
 	defer tx.Rollback()
 
+	if err := upsertPostgresSymbolMappings(ctx, tx, accountID, mappings, reportedAtMs); err != nil {
+		return err
+	}
+
+	// Commit transacción
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// upsertPostgresSymbolMappings escribe los mapeos dentro de tx (i8: compartido con
+// postgresSymbolOverrideRepo.Pin).
+func upsertPostgresSymbolMappings(ctx context.Context, tx *sql.Tx, accountID string, mappings []*domain.SymbolMapping, reportedAtMs int64) error {
 	// Upsert cada mapping con idempotencia temporal
 	for _, m := range mappings {
+		if m == nil {
+			continue
+		}
 		query := `
 			INSERT INTO echo.account_symbol_map (
 				account_id, canonical_symbol, broker_symbol,
 				digits, point, tick_size, min_lot, max_lot, lot_step, stop_level,
-				contract_size, reported_at_ms, updated_at
+				contract_size, base_currency, profit_currency, source, confidence,
+				reported_at_ms, updated_at
 			)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW())
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
 			ON CONFLICT (account_id, canonical_symbol)
 			DO UPDATE SET
 				broker_symbol = EXCLUDED.broker_symbol,
@@ -797,6 +971,10 @@ func (r *postgresSymbolRepo) UpsertAccountMapping(ctx context.Context, accountID
 				lot_step = EXCLUDED.lot_step,
 				stop_level = EXCLUDED.stop_level,
 				contract_size = EXCLUDED.contract_size,
+				base_currency = EXCLUDED.base_currency,
+				profit_currency = EXCLUDED.profit_currency,
+				source = EXCLUDED.source,
+				confidence = EXCLUDED.confidence,
 				reported_at_ms = EXCLUDED.reported_at_ms,
 				updated_at = NOW()
 			WHERE EXCLUDED.reported_at_ms >= echo.account_symbol_map.reported_at_ms
@@ -813,6 +991,10 @@ func (r *postgresSymbolRepo) UpsertAccountMapping(ctx context.Context, accountID
 			m.LotStep,
 			m.StopLevel,
 			m.ContractSize,
+			m.BaseCurrency,
+			m.ProfitCurrency,
+			string(m.Source),
+			m.Confidence,
 			reportedAtMs,
 		)
 		if err != nil {
@@ -820,11 +1002,6 @@ func (r *postgresSymbolRepo) UpsertAccountMapping(ctx context.Context, accountID
 		}
 	}
 
-	// Commit transacción
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
 	return nil
 }
 
@@ -932,6 +1109,22 @@ type postgresRiskPolicyRepo struct {
 }
 
 func (r *postgresRiskPolicyRepo) Get(ctx context.Context, accountID, strategyID string) (*domain.RiskPolicy, error) {
+	// i7: el historial es la fuente de verdad; la tabla legacy solo aplica a pares sin historial.
+	revision, hasHistory, err := r.getEffectiveRevision(ctx, accountID, strategyID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if hasHistory {
+		if revision == nil {
+			return nil, nil
+		}
+		return &revision.Policy, nil
+	}
+
+	return r.getLegacy(ctx, accountID, strategyID)
+}
+
+func (r *postgresRiskPolicyRepo) getLegacy(ctx context.Context, accountID, strategyID string) (*domain.RiskPolicy, error) {
 	query := `
 		SELECT risk_type, lot_size, config, risk_currency, risk_amount, version, updated_at, valid_until
 		FROM echo.account_strategy_risk_policy
@@ -959,21 +1152,34 @@ func (r *postgresRiskPolicyRepo) Get(ctx context.Context, accountID, strategyID
 	}
 
 	policy := &domain.RiskPolicy{
-		AccountID:  accountID,
-		StrategyID: strategyID,
-		Type:       domain.RiskPolicyType(riskType),
-		Version:    version.Int64,
-		UpdatedAt:  updatedAt,
+		AccountID:     accountID,
+		StrategyID:    strategyID,
+		Type:          domain.RiskPolicyType(riskType),
+		Version:       version.Int64,
+		UpdatedAt:     updatedAt,
+		EffectiveFrom: updatedAt,
 	}
 
 	if validUntil.Valid {
 		policy.ValidUntil = &validUntil.Time
 	}
 
+	if err := applyRiskPolicyConfig(policy, lotSize, configRaw, riskCurrency, riskAmount); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// applyRiskPolicyConfig completa FixedLot/FixedRisk a partir de las columnas persistidas.
+func applyRiskPolicyConfig(policy *domain.RiskPolicy, lotSize sql.NullFloat64, configRaw, riskCurrency sql.NullString, riskAmount sql.NullFloat64) error {
+	accountID := policy.AccountID
+	strategyID := policy.StrategyID
+
 	switch policy.Type {
 	case domain.RiskPolicyTypeFixedLot:
 		if !lotSize.Valid {
-			return nil, fmt.Errorf("risk policy FIXED_LOT missing lot_size (account=%s, strategy=%s)", accountID, strategyID)
+			return fmt.Errorf("risk policy FIXED_LOT missing lot_size (account=%s, strategy=%s)", accountID, strategyID)
 		}
 		policy.FixedLot = &domain.FixedLotConfig{LotSize: lotSize.Float64}
 	case domain.RiskPolicyTypeFixedRisk:
@@ -981,7 +1187,7 @@ func (r *postgresRiskPolicyRepo) Get(ctx context.Context, accountID, strategyID
 		if configRaw.Valid && strings.TrimSpace(configRaw.String) != "" {
 			parsed, err := domain.ParseFixedRiskConfig(json.RawMessage(configRaw.String))
 			if err != nil {
-				return nil, fmt.Errorf("failed to parse fixed risk config: %w", err)
+				return fmt.Errorf("failed to parse fixed risk config: %w", err)
 			}
 			cfg = parsed
 		} else if riskAmount.Valid && riskCurrency.Valid {
@@ -990,7 +1196,7 @@ func (r *postgresRiskPolicyRepo) Get(ctx context.Context, accountID, strategyID
 				Currency: strings.ToUpper(strings.TrimSpace(riskCurrency.String)),
 			}
 		} else {
-			return nil, fmt.Errorf("risk policy FIXED_RISK missing configuration (account=%s, strategy=%s)", accountID, strategyID)
+			return fmt.Errorf("risk policy FIXED_RISK missing configuration (account=%s, strategy=%s)", accountID, strategyID)
 		}
 
 		if cfg.Currency == "" && riskCurrency.Valid {
@@ -998,10 +1204,10 @@ func (r *postgresRiskPolicyRepo) Get(ctx context.Context, accountID, strategyID
 		}
 		policy.FixedRisk = cfg
 	default:
-		return nil, fmt.Errorf("unsupported risk policy type: %s", riskType)
+		return fmt.Errorf("unsupported risk policy type: %s", policy.Type)
 	}
 
-	return policy, nil
+	return nil
 }
 
 func (r *postgresSymbolQuoteRepo) InsertSnapshot(ctx context.Context, snapshot *pb.SymbolQuoteSnapshot) error {
@@ -1075,7 +1281,8 @@ func (r *postgresSymbolQuoteRepo) GetLatestSnapshot(ctx context.Context, account
 func (r *postgresSymbolRepo) GetAccountMapping(ctx context.Context, accountID string) (map[string]*domain.AccountSymbolInfo, error) {
 	query := `
 		SELECT canonical_symbol, broker_symbol,
-		       digits, point, tick_size, min_lot, max_lot, lot_step, stop_level, contract_size
+		       digits, point, tick_size, min_lot, max_lot, lot_step, stop_level, contract_size,
+		       base_currency, profit_currency, source, confidence
 		FROM echo.account_symbol_map
 		WHERE account_id = $1
 	`
@@ -1087,9 +1294,9 @@ func (r *postgresSymbolRepo) GetAccountMapping(ctx context.Context, accountID st
 
 	result := make(map[string]*domain.AccountSymbolInfo)
 	for rows.Next() {
-		var canonical, broker string
+		var canonical, broker, baseCurrency, profitCurrency, source string
 		var digits, stopLevel int32
-		var point, tickSize, minLot, maxLot, lotStep float64
+		var point, tickSize, minLot, maxLot, lotStep, confidence float64
 		var contractSize sql.NullFloat64
 
 		err := rows.Scan(
@@ -1103,6 +1310,10 @@ func (r *postgresSymbolRepo) GetAccountMapping(ctx context.Context, accountID st
 			&lotStep,
 			&stopLevel,
 			&contractSize,
+			&baseCurrency,
+			&profitCurrency,
+			&source,
+			&confidence,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan symbol mapping: %w", err)
@@ -1124,6 +1335,10 @@ func (r *postgresSymbolRepo) GetAccountMapping(ctx context.Context, accountID st
 			LotStep:         lotStep,
 			StopLevel:       stopLevel,
 			ContractSize:    contractSizePtr,
+			BaseCurrency:    baseCurrency,
+			ProfitCurrency:  profitCurrency,
+			Source:          domain.SymbolMappingSource(source),
+			Confidence:      confidence,
 		}
 	}
 
@@ -1142,3 +1357,11 @@ func (r *postgresSymbolRepo) InvalidateAccount(ctx context.Context, accountID st
 	}
 	return nil
 }
+
+// nullableString convierte strings vacíos en NULL para columnas opcionales.
+func nullableString(value string) sql.NullString {
+	if value == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: value, Valid: true}
+}
@@ -0,0 +1,290 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Historial append-only de políticas de riesgo (i7).
+//
+// La versión vigente es la revisión con mayor effective_from <= now; las revisiones
+// con effective_from futuro quedan programadas y se activan sin intervención.
+
+const riskPolicyHistoryColumns = `
+	version, risk_type, lot_size, config, risk_currency, risk_amount,
+	effective_from, valid_until, author, reason, recorded_at
+`
+
+// getEffectiveRevision retorna la revisión vigente en at y si el par tiene historial.
+//
+// Si la revisión vigente expiró (valid_until <= at) retorna (nil, true, nil).
+func (r *postgresRiskPolicyRepo) getEffectiveRevision(ctx context.Context, accountID, strategyID string, at time.Time) (*domain.RiskPolicyRevision, bool, error) {
+	query := `
+		SELECT ` + riskPolicyHistoryColumns + `
+		FROM echo.account_strategy_risk_policy_history
+		WHERE account_id = $1 AND strategy_id = $2 AND effective_from <= $3
+		ORDER BY effective_from DESC, version DESC
+		LIMIT 1
+	`
+
+	revisions, err := r.queryRevisions(ctx, accountID, strategyID, query, accountID, strategyID, at)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(revisions) == 0 {
+		// Sin revisión vigente: distinguir "solo programadas" de "sin historial"
+		var exists bool
+		existsQuery := `
+			SELECT EXISTS (
+				SELECT 1 FROM echo.account_strategy_risk_policy_history
+				WHERE account_id = $1 AND strategy_id = $2
+			)
+		`
+		if err := r.db.QueryRowContext(ctx, existsQuery, accountID, strategyID).Scan(&exists); err != nil {
+			return nil, false, fmt.Errorf("failed to check risk policy history: %w", err)
+		}
+		return nil, exists, nil
+	}
+
+	revision := revisions[0]
+	if !revision.IsEffectiveAt(at) {
+		return nil, true, nil
+	}
+	return revision, true, nil
+}
+
+func (r *postgresRiskPolicyRepo) GetVersion(ctx context.Context, accountID, strategyID string, version int64) (*domain.RiskPolicyRevision, error) {
+	query := `
+		SELECT ` + riskPolicyHistoryColumns + `
+		FROM echo.account_strategy_risk_policy_history
+		WHERE account_id = $1 AND strategy_id = $2 AND version = $3
+	`
+
+	revisions, err := r.queryRevisions(ctx, accountID, strategyID, query, accountID, strategyID, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return revisions[0], nil
+}
+
+func (r *postgresRiskPolicyRepo) ListHistory(ctx context.Context, accountID, strategyID string) ([]*domain.RiskPolicyRevision, error) {
+	query := `
+		SELECT ` + riskPolicyHistoryColumns + `
+		FROM echo.account_strategy_risk_policy_history
+		WHERE account_id = $1 AND strategy_id = $2
+		ORDER BY version ASC
+	`
+	return r.queryRevisions(ctx, accountID, strategyID, query, accountID, strategyID)
+}
+
+func (r *postgresRiskPolicyRepo) List(ctx context.Context) ([]*domain.RiskPolicy, error) {
+	query := `
+		SELECT account_id, strategy_id FROM echo.account_strategy_risk_policy_history
+		UNION
+		SELECT account_id, strategy_id FROM echo.account_strategy_risk_policy
+		ORDER BY account_id, strategy_id
+	`
+	return listRiskPolicies(ctx, r.db, query, r.Get)
+}
+
+// listRiskPolicies resuelve la política vigente de cada par retornado por query.
+//
+// Reutiliza Get para respetar la misma precedencia historial → legacy.
+func listRiskPolicies(ctx context.Context, db *sql.DB, query string, get func(ctx context.Context, accountID, strategyID string) (*domain.RiskPolicy, error)) ([]*domain.RiskPolicy, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list risk policy pairs: %w", err)
+	}
+
+	type pair struct{ accountID, strategyID string }
+	var pairs []pair
+	for rows.Next() {
+		var p pair
+		if err := rows.Scan(&p.accountID, &p.strategyID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan risk policy pair: %w", err)
+		}
+		pairs = append(pairs, p)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+	// Cerrar antes de Get: con SQLite el pool tiene una sola conexión
+	rows.Close()
+
+	var policies []*domain.RiskPolicy
+	for _, p := range pairs {
+		policy, err := get(ctx, p.accountID, p.strategyID)
+		if err != nil {
+			return nil, err
+		}
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+func (r *postgresRiskPolicyRepo) AppendRevision(ctx context.Context, revision *domain.RiskPolicyRevision) (int64, error) {
+	if err := domain.ValidateRiskPolicyRevision(revision); err != nil {
+		return 0, err
+	}
+
+	policy := &revision.Policy
+
+	var (
+		lotSize      sql.NullFloat64
+		configJSON   = []byte("{}")
+		riskCurrency sql.NullString
+		riskAmount   sql.NullFloat64
+	)
+	switch policy.Type {
+	case domain.RiskPolicyTypeFixedLot:
+		lotSize = sql.NullFloat64{Float64: policy.FixedLot.LotSize, Valid: true}
+	case domain.RiskPolicyTypeFixedRisk:
+		raw, err := domain.MarshalFixedRiskConfig(policy.FixedRisk)
+		if err != nil {
+			return 0, err
+		}
+		configJSON = raw
+		riskCurrency = sql.NullString{String: policy.FixedRisk.Currency, Valid: true}
+		riskAmount = sql.NullFloat64{Float64: policy.FixedRisk.Amount, Valid: true}
+	}
+
+	var effectiveFrom interface{}
+	if !policy.EffectiveFrom.IsZero() {
+		effectiveFrom = policy.EffectiveFrom
+	}
+	var validUntil interface{}
+	if policy.ValidUntil != nil {
+		validUntil = *policy.ValidUntil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// Serializar escritores concurrentes del mismo par cuenta × estrategia
+	if _, err = tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1 || ':' || $2))`, policy.AccountID, policy.StrategyID); err != nil {
+		return 0, fmt.Errorf("failed to lock risk policy history: %w", err)
+	}
+
+	var version int64
+	nextVersionQuery := `
+		SELECT COALESCE(MAX(version), 0) + 1
+		FROM echo.account_strategy_risk_policy_history
+		WHERE account_id = $1 AND strategy_id = $2
+	`
+	if err = tx.QueryRowContext(ctx, nextVersionQuery, policy.AccountID, policy.StrategyID).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to compute risk policy version: %w", err)
+	}
+
+	insert := `
+		INSERT INTO echo.account_strategy_risk_policy_history (
+			account_id, strategy_id, version, risk_type, lot_size, config,
+			risk_currency, risk_amount, effective_from, valid_until, author, reason
+		) VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7, $8, COALESCE($9, NOW()), $10, $11, $12)
+		RETURNING effective_from, recorded_at
+	`
+	var effectiveAt, recordedAt time.Time
+	if err = tx.QueryRowContext(ctx, insert,
+		policy.AccountID,
+		policy.StrategyID,
+		version,
+		string(policy.Type),
+		lotSize,
+		string(configJSON),
+		riskCurrency,
+		riskAmount,
+		effectiveFrom,
+		validUntil,
+		strings.TrimSpace(revision.Author),
+		revision.Reason,
+	).Scan(&effectiveAt, &recordedAt); err != nil {
+		return 0, fmt.Errorf("failed to append risk policy revision: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+
+	policy.Version = version
+	policy.EffectiveFrom = effectiveAt
+	policy.UpdatedAt = recordedAt
+	revision.RecordedAt = recordedAt
+
+	return version, nil
+}
+
+func (r *postgresRiskPolicyRepo) queryRevisions(ctx context.Context, accountID, strategyID, query string, args ...interface{}) ([]*domain.RiskPolicyRevision, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query risk policy history: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*domain.RiskPolicyRevision
+	for rows.Next() {
+		var (
+			version       int64
+			riskType      string
+			lotSize       sql.NullFloat64
+			configRaw     sql.NullString
+			riskCurrency  sql.NullString
+			riskAmount    sql.NullFloat64
+			effectiveFrom time.Time
+			validUntil    sql.NullTime
+			author        string
+			reason        string
+			recordedAt    time.Time
+		)
+		if err := rows.Scan(&version, &riskType, &lotSize, &configRaw, &riskCurrency, &riskAmount,
+			&effectiveFrom, &validUntil, &author, &reason, &recordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan risk policy revision: %w", err)
+		}
+
+		revision := &domain.RiskPolicyRevision{
+			Policy: domain.RiskPolicy{
+				AccountID:     accountID,
+				StrategyID:    strategyID,
+				Type:          domain.RiskPolicyType(riskType),
+				Version:       version,
+				UpdatedAt:     recordedAt,
+				EffectiveFrom: effectiveFrom,
+			},
+			Author:     author,
+			Reason:     reason,
+			RecordedAt: recordedAt,
+		}
+		if validUntil.Valid {
+			revision.Policy.ValidUntil = &validUntil.Time
+		}
+		if err := applyRiskPolicyConfig(&revision.Policy, lotSize, configRaw, riskCurrency, riskAmount); err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return revisions, nil
+}
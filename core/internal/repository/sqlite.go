@@ -0,0 +1,1155 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	_ "modernc.org/sqlite" // Driver SQLite (pure Go)
+)
+
+// OpenSQLite abre (o crea) la base SQLite en path.
+//
+// No aplica el schema: las migraciones las ejecuta core/internal/migrations.
+//
+// Serialización de escrituras: el pool se limita a una conexión, de modo que las
+// goroutines del router (executions, dedupe, closes, símbolos) se encolan en
+// database/sql en lugar de competir por el lock de escritura de SQLite. WAL y
+// busy_timeout cubren a lectores externos (echo-core-cli) sobre el mismo archivo.
+//
+// Uso:
+//
+//	db, err := repository.OpenSQLite(ctx, "/var/lib/echo/echo.db")
+//	factory := repository.NewSQLiteFactory(db, 0)
+func OpenSQLite(ctx context.Context, path string) (*sql.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite path is required")
+	}
+
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", "busy_timeout(5000)")
+	pragmas.Add("_pragma", "journal_mode(WAL)")
+	pragmas.Add("_pragma", "synchronous(NORMAL)")
+	pragmas.Add("_pragma", "foreign_keys(1)")
+
+	db, err := sql.Open("sqlite", "file:"+path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping sqlite database: %w", err)
+	}
+	return db, nil
+}
+
+// SQLiteFactory implementa domain.RepositoryFactory sobre SQLite (despliegues single-host).
+//
+// Las notificaciones LISTEN/NOTIFY se reemplazan por ChangeNotifier: solo se observan
+// escrituras de este proceso; cambios hechos por otros procesos sobre el mismo archivo
+// se reflejan al expirar la caché de cada servicio, salvo las políticas de riesgo
+// (ver WatchRiskPolicyHistory).
+type SQLiteFactory struct {
+	*changeHub
+
+	db        *sql.DB
+	dedupeTTL time.Duration
+	now       func() time.Time
+
+	// Repositorios inicializados lazy
+	tradeRepo       domain.TradeRepository
+	executionRepo   domain.ExecutionRepository
+	dedupeRepo      domain.DedupeRepository
+	closeRepo       domain.CloseRepository
+	modifyRepo      domain.ModifyRepository
+	lifecycleRepo   domain.TradeLifecycleRepository
+	outboxRepo      domain.OutboxRepository
+	livenessRepo    domain.AgentLivenessRepository
+	ownershipRepo   domain.AccountOwnershipRepository
+	copyControlRepo domain.AccountCopyControlRepository
+	deadLetterRepo  domain.NotificationDeadLetterRepository
+	correlationSvc  domain.CorrelationService
+	symbolRepo      domain.SymbolRepository
+	symbolSpecRepo  domain.SymbolSpecRepository
+	symbolQuoteRepo domain.SymbolQuoteRepository
+	overrideRepo    domain.SymbolOverrideRepository
+	proposalRepo    domain.SymbolProposalRepository
+	riskPolicyRepo  domain.RiskPolicyRepository
+	handshakeRepo   domain.HandshakeEvaluationRepository
+}
+
+// NewSQLiteFactory crea un factory de repositorios SQLite.
+//
+// db debe provenir de OpenSQLite. dedupeTTL <= 0 usa DefaultMemoryDedupeTTL (1h,
+// igual que echo.cleanup_dedupe_ttl()).
+func NewSQLiteFactory(db *sql.DB, dedupeTTL time.Duration) *SQLiteFactory {
+	if dedupeTTL <= 0 {
+		dedupeTTL = DefaultMemoryDedupeTTL
+	}
+	return &SQLiteFactory{
+		changeHub: &changeHub{},
+		db:        db,
+		dedupeTTL: dedupeTTL,
+		now:       time.Now,
+	}
+}
+
+// SetClock reemplaza la hora usada por dedupe, outbox y vigencia de políticas.
+//
+// Lo usa el replay del journal; debe llamarse antes de pedir los repositorios.
+func (f *SQLiteFactory) SetClock(now func() time.Time) {
+	f.now = now
+}
+
+// TradeRepository retorna el repositorio de trades.
+func (f *SQLiteFactory) TradeRepository() domain.TradeRepository {
+	if f.tradeRepo == nil {
+		f.tradeRepo = &sqliteTradeRepo{db: f.db}
+	}
+	return f.tradeRepo
+}
+
+// ExecutionRepository retorna el repositorio de executions.
+func (f *SQLiteFactory) ExecutionRepository() domain.ExecutionRepository {
+	if f.executionRepo == nil {
+		f.executionRepo = &sqliteExecutionRepo{db: f.db}
+	}
+	return f.executionRepo
+}
+
+// DedupeRepository retorna el repositorio de dedupe.
+func (f *SQLiteFactory) DedupeRepository() domain.DedupeRepository {
+	if f.dedupeRepo == nil {
+		f.dedupeRepo = &sqliteDedupeRepo{db: f.db, ttl: f.dedupeTTL, now: f.now}
+	}
+	return f.dedupeRepo
+}
+
+// CloseRepository retorna el repositorio de closes.
+func (f *SQLiteFactory) CloseRepository() domain.CloseRepository {
+	if f.closeRepo == nil {
+		f.closeRepo = &sqliteCloseRepo{db: f.db}
+	}
+	return f.closeRepo
+}
+
+// ModifyRepository retorna el repositorio de cambios de SL/TP (i8).
+func (f *SQLiteFactory) ModifyRepository() domain.ModifyRepository {
+	if f.modifyRepo == nil {
+		f.modifyRepo = &sqliteModifyRepo{db: f.db}
+	}
+	return f.modifyRepo
+}
+
+// TradeLifecycleRepository retorna el repositorio de ciclo de vida por trade×slave (i8).
+func (f *SQLiteFactory) TradeLifecycleRepository() domain.TradeLifecycleRepository {
+	if f.lifecycleRepo == nil {
+		f.lifecycleRepo = NewTradeLifecycleRepository(f.ExecutionRepository(), f.ModifyRepository(), f.CloseRepository())
+	}
+	return f.lifecycleRepo
+}
+
+// OutboxRepository retorna el outbox transaccional de comandos (i8).
+func (f *SQLiteFactory) OutboxRepository() domain.OutboxRepository {
+	if f.outboxRepo == nil {
+		f.outboxRepo = &sqliteOutboxRepo{db: f.db, now: f.now}
+	}
+	return f.outboxRepo
+}
+
+// AgentLivenessRepository retorna el estado de liveness de Agents y cuentas (i8).
+func (f *SQLiteFactory) AgentLivenessRepository() domain.AgentLivenessRepository {
+	if f.livenessRepo == nil {
+		f.livenessRepo = &sqliteAgentLivenessRepo{db: f.db}
+	}
+	return f.livenessRepo
+}
+
+// AccountOwnershipRepository retorna el ownership persistido de cuentas (i8).
+func (f *SQLiteFactory) AccountOwnershipRepository() domain.AccountOwnershipRepository {
+	if f.ownershipRepo == nil {
+		f.ownershipRepo = &sqliteAccountOwnershipRepo{db: f.db}
+	}
+	return f.ownershipRepo
+}
+
+// AccountCopyControlRepository retorna la habilitación de copia por cuenta (i8).
+func (f *SQLiteFactory) AccountCopyControlRepository() domain.AccountCopyControlRepository {
+	if f.copyControlRepo == nil {
+		f.copyControlRepo = &sqliteAccountCopyControlRepo{db: f.db}
+	}
+	return f.copyControlRepo
+}
+
+// NotificationDeadLetterRepository retorna los webhooks no entregados (i8).
+func (f *SQLiteFactory) NotificationDeadLetterRepository() domain.NotificationDeadLetterRepository {
+	if f.deadLetterRepo == nil {
+		f.deadLetterRepo = &sqliteNotificationDeadLetterRepo{db: f.db}
+	}
+	return f.deadLetterRepo
+}
+
+// CorrelationService retorna el servicio de correlación.
+func (f *SQLiteFactory) CorrelationService() domain.CorrelationService {
+	if f.correlationSvc == nil {
+		f.correlationSvc = NewCorrelationService(
+			f.ExecutionRepository(),
+			f.DedupeRepository(),
+			f.CloseRepository(),
+		)
+	}
+	return f.correlationSvc
+}
+
+// SymbolRepository retorna el repositorio de símbolos (i3).
+func (f *SQLiteFactory) SymbolRepository() domain.SymbolRepository {
+	if f.symbolRepo == nil {
+		f.symbolRepo = &sqliteSymbolRepo{db: f.db}
+	}
+	return f.symbolRepo
+}
+
+// SymbolSpecRepository retorna el repositorio de especificaciones de símbolos.
+func (f *SQLiteFactory) SymbolSpecRepository() domain.SymbolSpecRepository {
+	if f.symbolSpecRepo == nil {
+		f.symbolSpecRepo = &sqliteSymbolSpecRepo{db: f.db}
+	}
+	return f.symbolSpecRepo
+}
+
+// SymbolQuoteRepository retorna el repositorio de snapshots de precios.
+func (f *SQLiteFactory) SymbolQuoteRepository() domain.SymbolQuoteRepository {
+	if f.symbolQuoteRepo == nil {
+		f.symbolQuoteRepo = &sqliteSymbolQuoteRepo{db: f.db}
+	}
+	return f.symbolQuoteRepo
+}
+
+// SymbolOverrideRepository retorna los overrides manuales de mapeo de símbolos (i8).
+func (f *SQLiteFactory) SymbolOverrideRepository() domain.SymbolOverrideRepository {
+	if f.overrideRepo == nil {
+		f.overrideRepo = &sqliteSymbolOverrideRepo{db: f.db, hub: f.changeHub}
+	}
+	return f.overrideRepo
+}
+
+// SymbolProposalRepository retorna los mapeos sugeridos por el matcher de símbolos (i8).
+func (f *SQLiteFactory) SymbolProposalRepository() domain.SymbolProposalRepository {
+	if f.proposalRepo == nil {
+		f.proposalRepo = &sqliteSymbolProposalRepo{db: f.db}
+	}
+	return f.proposalRepo
+}
+
+// RiskPolicyRepository retorna el repositorio de políticas de riesgo.
+func (f *SQLiteFactory) RiskPolicyRepository() domain.RiskPolicyRepository {
+	if f.riskPolicyRepo == nil {
+		f.riskPolicyRepo = &sqliteRiskPolicyRepo{db: f.db, hub: f.changeHub, now: f.now}
+	}
+	return f.riskPolicyRepo
+}
+
+// HandshakeRepository retorna el repositorio de evaluaciones de handshake.
+func (f *SQLiteFactory) HandshakeRepository() domain.HandshakeEvaluationRepository {
+	if f.handshakeRepo == nil {
+		f.handshakeRepo = &sqliteHandshakeRepo{db: f.db, hub: f.changeHub}
+	}
+	return f.handshakeRepo
+}
+
+// ===========================================================================
+// sqliteTradeRepo
+// ===========================================================================
+
+const sqliteTradeColumns = `
+	trade_id, source_master_id, master_account_id, master_ticket,
+	magic_number, symbol, side, lot_size, price,
+	stop_loss, take_profit, comment,
+	status, attempt, opened_at_ms, created_at, updated_at
+`
+
+type sqliteTradeRepo struct {
+	db sqlConn
+}
+
+func (r *sqliteTradeRepo) Create(ctx context.Context, trade *domain.Trade) error {
+	now := time.Now().UnixMilli()
+	query := `INSERT INTO trades (` + sqliteTradeColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query,
+		trade.TradeID,
+		trade.SourceMasterID,
+		trade.MasterAccountID,
+		trade.MasterTicket,
+		trade.MagicNumber,
+		trade.Symbol,
+		trade.Side,
+		trade.LotSize,
+		trade.Price,
+		trade.StopLoss,
+		trade.TakeProfit,
+		trade.Comment,
+		trade.Status,
+		trade.Attempt,
+		trade.OpenedAtMs,
+		now,
+		now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create trade: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteTradeRepo) GetByID(ctx context.Context, tradeID string) (*domain.Trade, error) {
+	query := `SELECT ` + sqliteTradeColumns + ` FROM trades WHERE trade_id = ?`
+	trades, err := r.queryTrades(ctx, query, tradeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(trades) == 0 {
+		return nil, nil
+	}
+	return trades[0], nil
+}
+
+func (r *sqliteTradeRepo) GetByMasterTicket(ctx context.Context, masterAccountID string, masterTicket int32) (*domain.Trade, error) {
+	query := `
+		SELECT ` + sqliteTradeColumns + `
+		FROM trades
+		WHERE master_account_id = ? AND master_ticket = ?
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT 1
+	`
+	trades, err := r.queryTrades(ctx, query, masterAccountID, masterTicket)
+	if err != nil {
+		return nil, err
+	}
+	if len(trades) == 0 {
+		return nil, nil
+	}
+	return trades[0], nil
+}
+
+func (r *sqliteTradeRepo) UpdateStatus(ctx context.Context, tradeID string, status domain.OrderStatus) error {
+	query := `UPDATE trades SET status = ?, updated_at = ? WHERE trade_id = ?`
+	result, err := r.db.ExecContext(ctx, query, status, time.Now().UnixMilli(), tradeID)
+	if err != nil {
+		return fmt.Errorf("failed to update trade status: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("trade not found: %s", tradeID)
+	}
+	return nil
+}
+
+func (r *sqliteTradeRepo) List(ctx context.Context, limit, offset int) ([]*domain.Trade, error) {
+	query := `
+		SELECT ` + sqliteTradeColumns + `
+		FROM trades
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT ? OFFSET ?
+	`
+	return r.queryTrades(ctx, query, limit, offset)
+}
+
+func (r *sqliteTradeRepo) ListByStatus(ctx context.Context, status domain.OrderStatus, limit, offset int) ([]*domain.Trade, error) {
+	query := `
+		SELECT ` + sqliteTradeColumns + `
+		FROM trades
+		WHERE status = ?
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT ? OFFSET ?
+	`
+	return r.queryTrades(ctx, query, status, limit, offset)
+}
+
+func (r *sqliteTradeRepo) ListFiltered(ctx context.Context, filter domain.TradeFilter, limit, offset int) ([]*domain.Trade, error) {
+	where := tradeFilterWhere(filter, false)
+	query := `
+		SELECT ` + sqliteTradeColumns + `
+		FROM trades
+		` + where.clause() + `
+		ORDER BY created_at DESC, rowid DESC
+		` + where.limitOffset(limit, offset)
+	return r.queryTrades(ctx, query, where.args...)
+}
+
+func (r *sqliteTradeRepo) queryTrades(ctx context.Context, query string, args ...interface{}) ([]*domain.Trade, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %w", err)
+	}
+	defer rows.Close()
+
+	var trades []*domain.Trade
+	for rows.Next() {
+		var trade domain.Trade
+		var createdAt, updatedAt int64
+		err := rows.Scan(
+			&trade.TradeID,
+			&trade.SourceMasterID,
+			&trade.MasterAccountID,
+			&trade.MasterTicket,
+			&trade.MagicNumber,
+			&trade.Symbol,
+			&trade.Side,
+			&trade.LotSize,
+			&trade.Price,
+			&trade.StopLoss,
+			&trade.TakeProfit,
+			&trade.Comment,
+			&trade.Status,
+			&trade.Attempt,
+			&trade.OpenedAtMs,
+			&createdAt,
+			&updatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade: %w", err)
+		}
+		trade.CreatedAt = time.UnixMilli(createdAt)
+		trade.UpdatedAt = time.UnixMilli(updatedAt)
+		trades = append(trades, &trade)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return trades, nil
+}
+
+// ===========================================================================
+// sqliteExecutionRepo
+// ===========================================================================
+
+const sqliteExecutionColumns = `
+	execution_id, trade_id, slave_account_id, agent_id,
+	slave_ticket, executed_price, success, error_code, error_message,
+	timestamps_ms, strategy_id, risk_policy_type, risk_policy_version, created_at
+`
+
+type sqliteExecutionRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteExecutionRepo) Create(ctx context.Context, exec *domain.Execution) error {
+	// Serializar timestamps_ms a JSON
+	timestampsJSON, err := json.Marshal(exec.TimestampsMs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal timestamps: %w", err)
+	}
+
+	query := `INSERT INTO executions (` + sqliteExecutionColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err = r.db.ExecContext(ctx, query,
+		exec.ExecutionID,
+		exec.TradeID,
+		exec.SlaveAccountID,
+		exec.AgentID,
+		exec.SlaveTicket,
+		exec.ExecutedPrice,
+		exec.Success,
+		exec.ErrorCode,
+		exec.ErrorMessage,
+		string(timestampsJSON),
+		nullableString(exec.StrategyID),
+		nullableString(exec.RiskPolicyType),
+		exec.RiskPolicyVersion,
+		time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create execution: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteExecutionRepo) GetByID(ctx context.Context, executionID string) (*domain.Execution, error) {
+	query := `SELECT ` + sqliteExecutionColumns + ` FROM executions WHERE execution_id = ?`
+	execs, err := r.queryExecutions(ctx, query, executionID)
+	if err != nil {
+		return nil, err
+	}
+	if len(execs) == 0 {
+		return nil, nil
+	}
+	return execs[0], nil
+}
+
+func (r *sqliteExecutionRepo) GetByTradeID(ctx context.Context, tradeID string) ([]*domain.Execution, error) {
+	query := `
+		SELECT ` + sqliteExecutionColumns + `
+		FROM executions
+		WHERE trade_id = ?
+		ORDER BY created_at ASC, rowid ASC
+	`
+	return r.queryExecutions(ctx, query, tradeID)
+}
+
+func (r *sqliteExecutionRepo) GetByTradeAndSlave(ctx context.Context, tradeID, slaveAccountID string) (*domain.Execution, error) {
+	query := `
+		SELECT ` + sqliteExecutionColumns + `
+		FROM executions
+		WHERE trade_id = ? AND slave_account_id = ?
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT 1
+	`
+	execs, err := r.queryExecutions(ctx, query, tradeID, slaveAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(execs) == 0 {
+		return nil, nil
+	}
+	return execs[0], nil
+}
+
+func (r *sqliteExecutionRepo) GetTicketByTradeAndSlave(ctx context.Context, tradeID, slaveAccountID string) (int32, error) {
+	query := `
+		SELECT slave_ticket
+		FROM executions
+		WHERE trade_id = ? AND slave_account_id = ? AND success = 1 AND slave_ticket != 0
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT 1
+	`
+	var ticket int32
+	err := r.db.QueryRowContext(ctx, query, tradeID, slaveAccountID).Scan(&ticket)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get ticket: %w", err)
+	}
+	return ticket, nil
+}
+
+func (r *sqliteExecutionRepo) List(ctx context.Context, limit, offset int) ([]*domain.Execution, error) {
+	query := `
+		SELECT ` + sqliteExecutionColumns + `
+		FROM executions
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT ? OFFSET ?
+	`
+	return r.queryExecutions(ctx, query, limit, offset)
+}
+
+func (r *sqliteExecutionRepo) ListBySuccess(ctx context.Context, success bool, limit, offset int) ([]*domain.Execution, error) {
+	query := `
+		SELECT ` + sqliteExecutionColumns + `
+		FROM executions
+		WHERE success = ?
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT ? OFFSET ?
+	`
+	return r.queryExecutions(ctx, query, success, limit, offset)
+}
+
+func (r *sqliteExecutionRepo) ListFiltered(ctx context.Context, filter domain.ExecutionFilter, limit, offset int) ([]*domain.Execution, error) {
+	where := executionFilterWhere(filter, false)
+	query := `
+		SELECT ` + sqliteExecutionColumns + `
+		FROM executions
+		` + where.clause() + `
+		ORDER BY created_at DESC, rowid DESC
+		` + where.limitOffset(limit, offset)
+	return r.queryExecutions(ctx, query, where.args...)
+}
+
+func (r *sqliteExecutionRepo) queryExecutions(ctx context.Context, query string, args ...interface{}) ([]*domain.Execution, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query executions: %w", err)
+	}
+	defer rows.Close()
+
+	var execs []*domain.Execution
+	for rows.Next() {
+		var exec domain.Execution
+		var timestampsJSON string
+		var strategyID, riskPolicyType sql.NullString
+		var createdAt int64
+		err := rows.Scan(
+			&exec.ExecutionID,
+			&exec.TradeID,
+			&exec.SlaveAccountID,
+			&exec.AgentID,
+			&exec.SlaveTicket,
+			&exec.ExecutedPrice,
+			&exec.Success,
+			&exec.ErrorCode,
+			&exec.ErrorMessage,
+			&timestampsJSON,
+			&strategyID,
+			&riskPolicyType,
+			&exec.RiskPolicyVersion,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan execution: %w", err)
+		}
+
+		if err := json.Unmarshal([]byte(timestampsJSON), &exec.TimestampsMs); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal timestamps: %w", err)
+		}
+		exec.StrategyID = strategyID.String
+		exec.RiskPolicyType = riskPolicyType.String
+		exec.CreatedAt = time.UnixMilli(createdAt)
+
+		execs = append(execs, &exec)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return execs, nil
+}
+
+// ===========================================================================
+// sqliteDedupeRepo
+// ===========================================================================
+
+type sqliteDedupeRepo struct {
+	db  sqlConn
+	ttl time.Duration
+	now func() time.Time
+}
+
+func (r *sqliteDedupeRepo) Upsert(ctx context.Context, entry *domain.DedupeEntry) error {
+	now := r.now().UnixMilli()
+	query := `
+		INSERT INTO dedupe (trade_id, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (trade_id) DO UPDATE
+		SET status = excluded.status, updated_at = excluded.updated_at
+	`
+	_, err := r.db.ExecContext(ctx, query, entry.TradeID, entry.Status, now, now)
+	if err != nil {
+		return fmt.Errorf("failed to upsert dedupe entry: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteDedupeRepo) Get(ctx context.Context, tradeID string) (*domain.DedupeEntry, error) {
+	query := `SELECT trade_id, status, created_at, updated_at FROM dedupe WHERE trade_id = ?`
+	var entry domain.DedupeEntry
+	var createdAt, updatedAt int64
+	err := r.db.QueryRowContext(ctx, query, tradeID).Scan(
+		&entry.TradeID,
+		&entry.Status,
+		&createdAt,
+		&updatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dedupe entry: %w", err)
+	}
+	entry.CreatedAt = time.UnixMilli(createdAt)
+	entry.UpdatedAt = time.UnixMilli(updatedAt)
+	return &entry, nil
+}
+
+func (r *sqliteDedupeRepo) Exists(ctx context.Context, tradeID string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM dedupe WHERE trade_id = ?)`
+	var exists bool
+	err := r.db.QueryRowContext(ctx, query, tradeID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check dedupe existence: %w", err)
+	}
+	return exists, nil
+}
+
+func (r *sqliteDedupeRepo) UpdateStatus(ctx context.Context, tradeID string, status domain.OrderStatus) error {
+	query := `UPDATE dedupe SET status = ?, updated_at = ? WHERE trade_id = ?`
+	result, err := r.db.ExecContext(ctx, query, status, r.now().UnixMilli(), tradeID)
+	if err != nil {
+		return fmt.Errorf("failed to update dedupe status: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("dedupe entry not found: %s", tradeID)
+	}
+	return nil
+}
+
+func (r *sqliteDedupeRepo) CleanupTTL(ctx context.Context) (int, error) {
+	// Equivalente a echo.cleanup_dedupe_ttl()
+	query := `
+		DELETE FROM dedupe
+		WHERE status IN ('FILLED', 'REJECTED', 'CANCELLED')
+		  AND updated_at < ?
+	`
+	result, err := r.db.ExecContext(ctx, query, r.now().Add(-r.ttl).UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup dedupe TTL: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// ===========================================================================
+// sqliteCloseRepo
+// ===========================================================================
+
+const sqliteCloseColumns = `
+	close_id, trade_id, slave_account_id, slave_ticket,
+	close_price, success, error_code, error_message,
+	profit, commission, swap, closed_volume, remaining_volume,
+	closed_at_ms, created_at
+`
+
+type sqliteCloseRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteCloseRepo) Create(ctx context.Context, close *domain.Close) error {
+	query := `INSERT INTO closes (` + sqliteCloseColumns + `) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	_, err := r.db.ExecContext(ctx, query,
+		close.CloseID,
+		close.TradeID,
+		close.SlaveAccountID,
+		close.SlaveTicket,
+		close.ClosePrice,
+		close.Success,
+		close.ErrorCode,
+		close.ErrorMessage,
+		close.Profit,
+		close.Commission,
+		close.Swap,
+		close.ClosedVolume,
+		close.RemainingVolume,
+		close.ClosedAtMs,
+		time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create close: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteCloseRepo) GetByID(ctx context.Context, closeID string) (*domain.Close, error) {
+	query := `SELECT ` + sqliteCloseColumns + ` FROM closes WHERE close_id = ?`
+	closes, err := r.queryCloses(ctx, query, closeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(closes) == 0 {
+		return nil, nil
+	}
+	return closes[0], nil
+}
+
+func (r *sqliteCloseRepo) GetByTradeID(ctx context.Context, tradeID string) ([]*domain.Close, error) {
+	query := `
+		SELECT ` + sqliteCloseColumns + `
+		FROM closes
+		WHERE trade_id = ?
+		ORDER BY created_at ASC, rowid ASC
+	`
+	return r.queryCloses(ctx, query, tradeID)
+}
+
+func (r *sqliteCloseRepo) GetByTradeAndSlave(ctx context.Context, tradeID, slaveAccountID string) (*domain.Close, error) {
+	query := `
+		SELECT ` + sqliteCloseColumns + `
+		FROM closes
+		WHERE trade_id = ? AND slave_account_id = ?
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT 1
+	`
+	closes, err := r.queryCloses(ctx, query, tradeID, slaveAccountID)
+	if err != nil {
+		return nil, err
+	}
+	if len(closes) == 0 {
+		return nil, nil
+	}
+	return closes[0], nil
+}
+
+func (r *sqliteCloseRepo) List(ctx context.Context, limit, offset int) ([]*domain.Close, error) {
+	query := `
+		SELECT ` + sqliteCloseColumns + `
+		FROM closes
+		ORDER BY created_at DESC, rowid DESC
+		LIMIT ? OFFSET ?
+	`
+	return r.queryCloses(ctx, query, limit, offset)
+}
+
+func (r *sqliteCloseRepo) queryCloses(ctx context.Context, query string, args ...interface{}) ([]*domain.Close, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query closes: %w", err)
+	}
+	defer rows.Close()
+
+	var closes []*domain.Close
+	for rows.Next() {
+		var close domain.Close
+		var createdAt int64
+		err := rows.Scan(
+			&close.CloseID,
+			&close.TradeID,
+			&close.SlaveAccountID,
+			&close.SlaveTicket,
+			&close.ClosePrice,
+			&close.Success,
+			&close.ErrorCode,
+			&close.ErrorMessage,
+			&close.Profit,
+			&close.Commission,
+			&close.Swap,
+			&close.ClosedVolume,
+			&close.RemainingVolume,
+			&close.ClosedAtMs,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan close: %w", err)
+		}
+		close.CreatedAt = time.UnixMilli(createdAt)
+		closes = append(closes, &close)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return closes, nil
+}
+
+// ===========================================================================
+// sqliteSymbolRepo (i3)
+// ===========================================================================
+
+type sqliteSymbolRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteSymbolRepo) UpsertAccountMapping(ctx context.Context, accountID string, mappings []*domain.SymbolMapping, reportedAtMs int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := upsertSQLiteSymbolMappings(ctx, tx, accountID, mappings, reportedAtMs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// upsertSQLiteSymbolMappings escribe los mapeos dentro de tx (i8: compartido con
+// sqliteSymbolOverrideRepo.Pin).
+func upsertSQLiteSymbolMappings(ctx context.Context, tx *sql.Tx, accountID string, mappings []*domain.SymbolMapping, reportedAtMs int64) error {
+	query := `
+		INSERT INTO account_symbol_map (
+			account_id, canonical_symbol, broker_symbol,
+			digits, point, tick_size, min_lot, max_lot, lot_step, stop_level,
+			contract_size, base_currency, profit_currency, source, confidence,
+			reported_at_ms, updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (account_id, canonical_symbol)
+		DO UPDATE SET
+			broker_symbol = excluded.broker_symbol,
+			digits = excluded.digits,
+			point = excluded.point,
+			tick_size = excluded.tick_size,
+			min_lot = excluded.min_lot,
+			max_lot = excluded.max_lot,
+			lot_step = excluded.lot_step,
+			stop_level = excluded.stop_level,
+			contract_size = excluded.contract_size,
+			base_currency = excluded.base_currency,
+			profit_currency = excluded.profit_currency,
+			source = excluded.source,
+			confidence = excluded.confidence,
+			reported_at_ms = excluded.reported_at_ms,
+			updated_at = excluded.updated_at
+		WHERE excluded.reported_at_ms >= account_symbol_map.reported_at_ms
+	`
+	now := time.Now().UnixMilli()
+	for _, m := range mappings {
+		if m == nil {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, query,
+			accountID,
+			m.CanonicalSymbol,
+			m.BrokerSymbol,
+			m.Digits,
+			m.Point,
+			m.TickSize,
+			m.MinLot,
+			m.MaxLot,
+			m.LotStep,
+			m.StopLevel,
+			m.ContractSize,
+			m.BaseCurrency,
+			m.ProfitCurrency,
+			string(m.Source),
+			m.Confidence,
+			reportedAtMs,
+			now,
+		); err != nil {
+			return fmt.Errorf("failed to upsert symbol mapping: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *sqliteSymbolRepo) GetAccountMapping(ctx context.Context, accountID string) (map[string]*domain.AccountSymbolInfo, error) {
+	query := `
+		SELECT canonical_symbol, broker_symbol,
+		       digits, point, tick_size, min_lot, max_lot, lot_step, stop_level, contract_size,
+		       base_currency, profit_currency, source, confidence
+		FROM account_symbol_map
+		WHERE account_id = ?
+	`
+	rows, err := r.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query account mapping: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]*domain.AccountSymbolInfo)
+	for rows.Next() {
+		var canonical, broker, baseCurrency, profitCurrency, source string
+		var digits, stopLevel sql.NullInt32
+		var point, tickSize, minLot, maxLot, lotStep, contractSize sql.NullFloat64
+		var confidence float64
+		if err := rows.Scan(&canonical, &broker, &digits, &point, &tickSize, &minLot, &maxLot, &lotStep, &stopLevel, &contractSize,
+			&baseCurrency, &profitCurrency, &source, &confidence); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol mapping: %w", err)
+		}
+
+		info := &domain.AccountSymbolInfo{
+			BrokerSymbol:    broker,
+			CanonicalSymbol: canonical,
+			Digits:          digits.Int32,
+			Point:           point.Float64,
+			TickSize:        tickSize.Float64,
+			MinLot:          minLot.Float64,
+			MaxLot:          maxLot.Float64,
+			LotStep:         lotStep.Float64,
+			StopLevel:       stopLevel.Int32,
+			BaseCurrency:    baseCurrency,
+			ProfitCurrency:  profitCurrency,
+			Source:          domain.SymbolMappingSource(source),
+			Confidence:      confidence,
+		}
+		if contractSize.Valid {
+			info.ContractSize = &contractSize.Float64
+		}
+		result[canonical] = info
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *sqliteSymbolRepo) InvalidateAccount(ctx context.Context, accountID string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM account_symbol_map WHERE account_id = ?`, accountID); err != nil {
+		return fmt.Errorf("failed to invalidate account mappings: %w", err)
+	}
+	return nil
+}
+
+// ==========================================================================
+// sqliteSymbolSpecRepo
+// ==========================================================================
+
+type sqliteSymbolSpecRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteSymbolSpecRepo) UpsertSpecifications(ctx context.Context, accountID string, specs []*pb.SymbolSpecification, reportedAtMs int64) error {
+	if len(specs) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO account_symbol_spec (
+			account_id, canonical_symbol, broker_symbol, payload, reported_at_ms, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (account_id, canonical_symbol)
+		DO UPDATE SET
+			broker_symbol  = excluded.broker_symbol,
+			payload        = excluded.payload,
+			reported_at_ms = excluded.reported_at_ms,
+			updated_at     = excluded.updated_at
+		WHERE excluded.reported_at_ms >= account_symbol_spec.reported_at_ms
+	`
+
+	now := time.Now().UnixMilli()
+	for _, spec := range specs {
+		if spec == nil {
+			continue
+		}
+
+		payload, err := protojson.Marshal(spec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal symbol specification: %w", err)
+		}
+
+		if _, err := r.db.ExecContext(ctx, query, accountID, spec.CanonicalSymbol, spec.BrokerSymbol, string(payload), reportedAtMs, now); err != nil {
+			return fmt.Errorf("failed to upsert symbol specification (canonical=%s): %w", spec.CanonicalSymbol, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *sqliteSymbolSpecRepo) GetSpecifications(ctx context.Context, accountID string) (map[string]*domain.AccountSymbolSpec, error) {
+	query := `
+		SELECT canonical_symbol, payload, reported_at_ms
+		FROM account_symbol_spec
+		WHERE account_id = ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbol specifications: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]*domain.AccountSymbolSpec)
+	for rows.Next() {
+		var canonical, payload string
+		var reportedAtMs int64
+		if err := rows.Scan(&canonical, &payload, &reportedAtMs); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol specification: %w", err)
+		}
+
+		var spec pb.SymbolSpecification
+		if err := protojson.Unmarshal([]byte(payload), &spec); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal symbol specification: %w", err)
+		}
+
+		result[canonical] = &domain.AccountSymbolSpec{
+			CanonicalSymbol: canonical,
+			Specification:   &spec,
+			ReportedAtMs:    reportedAtMs,
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error while reading symbol specifications: %w", err)
+	}
+
+	return result, nil
+}
+
+// ==========================================================================
+// sqliteSymbolQuoteRepo
+// ==========================================================================
+
+type sqliteSymbolQuoteRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteSymbolQuoteRepo) InsertSnapshot(ctx context.Context, snapshot *pb.SymbolQuoteSnapshot) error {
+	if snapshot == nil {
+		return fmt.Errorf("snapshot is nil")
+	}
+
+	query := `
+		INSERT INTO symbol_quote_latest (
+			account_id, canonical_symbol, broker_symbol,
+			bid, ask, spread_points, timestamp_ms, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (account_id, canonical_symbol)
+		DO UPDATE SET
+			broker_symbol = excluded.broker_symbol,
+			bid           = excluded.bid,
+			ask           = excluded.ask,
+			spread_points = excluded.spread_points,
+			timestamp_ms  = excluded.timestamp_ms,
+			updated_at    = excluded.updated_at
+		WHERE excluded.timestamp_ms >= symbol_quote_latest.timestamp_ms
+	`
+
+	if _, err := r.db.ExecContext(ctx, query,
+		snapshot.AccountId,
+		snapshot.CanonicalSymbol,
+		snapshot.BrokerSymbol,
+		snapshot.Bid,
+		snapshot.Ask,
+		snapshot.SpreadPoints,
+		snapshot.TimestampMs,
+		time.Now().UnixMilli(),
+	); err != nil {
+		return fmt.Errorf("failed to upsert symbol quote snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func (r *sqliteSymbolQuoteRepo) GetLatestSnapshot(ctx context.Context, accountID, canonicalSymbol string) (*pb.SymbolQuoteSnapshot, error) {
+	query := `
+		SELECT broker_symbol, bid, ask, spread_points, timestamp_ms
+		FROM symbol_quote_latest
+		WHERE account_id = ? AND canonical_symbol = ?
+	`
+
+	snapshot := &pb.SymbolQuoteSnapshot{
+		AccountId:       accountID,
+		CanonicalSymbol: canonicalSymbol,
+	}
+	err := r.db.QueryRowContext(ctx, query, accountID, canonicalSymbol).Scan(
+		&snapshot.BrokerSymbol,
+		&snapshot.Bid,
+		&snapshot.Ask,
+		&snapshot.SpreadPoints,
+		&snapshot.TimestampMs,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest snapshot: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// nullableTimeMs convierte un *time.Time opcional en Unix ms (NULL si nil).
+func nullableTimeMs(value *time.Time) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: value.UnixMilli(), Valid: true}
+}
+
+var (
+	_ domain.RepositoryFactory = (*SQLiteFactory)(nil)
+	_ ChangeNotifier           = (*SQLiteFactory)(nil)
+)
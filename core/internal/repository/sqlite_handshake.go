@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain/handshake"
+	"github.com/xKoRx/echo/sdk/utils"
+)
+
+type sqliteHandshakeRepo struct {
+	db  *sql.DB
+	hub *changeHub
+}
+
+func (r *sqliteHandshakeRepo) CreateEvaluation(ctx context.Context, evaluation *handshake.Evaluation) error {
+	if evaluation == nil {
+		return fmt.Errorf("nil evaluation")
+	}
+
+	if evaluation.EvaluationID == "" {
+		evaluation.EvaluationID = utils.GenerateUUIDv7()
+	}
+	if evaluation.EvaluatedAtMs == 0 {
+		evaluation.EvaluatedAtMs = time.Now().UnixMilli()
+	}
+
+	errorsJSON, err := marshalIssues(evaluation.Errors)
+	if err != nil {
+		return fmt.Errorf("marshal global errors: %w", err)
+	}
+	warningsJSON, err := marshalIssues(evaluation.Warnings)
+	if err != nil {
+		return fmt.Errorf("marshal global warnings: %w", err)
+	}
+	requiredJSON, err := marshalFeatures(evaluation.RequiredFeatures)
+	if err != nil {
+		return fmt.Errorf("marshal required features: %w", err)
+	}
+	optionalJSON, err := marshalFeatures(evaluation.OptionalFeatures)
+	if err != nil {
+		return fmt.Errorf("marshal optional features: %w", err)
+	}
+	capabilitiesJSON, err := json.Marshal(map[string]interface{}{
+		"features": evaluation.Capabilities.Features,
+		"metrics":  evaluation.Capabilities.Metrics,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal capabilities: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	insertEval := `
+		INSERT INTO account_symbol_registration_eval (
+			evaluation_id, account_id, pipe_role, status,
+			protocol_version, client_semver, global_errors,
+			global_warnings, required_features, optional_features,
+			capabilities, evaluated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err = tx.ExecContext(ctx, insertEval,
+		evaluation.EvaluationID,
+		evaluation.AccountID,
+		evaluation.PipeRole,
+		statusToString(evaluation.Status),
+		evaluation.ProtocolVersion,
+		evaluation.ClientSemver,
+		string(errorsJSON),
+		string(warningsJSON),
+		string(requiredJSON),
+		string(optionalJSON),
+		string(capabilitiesJSON),
+		evaluation.EvaluatedAtMs,
+	); err != nil {
+		return fmt.Errorf("insert evaluation: %w", err)
+	}
+
+	insertEntry := `
+		INSERT INTO account_symbol_registration (
+			evaluation_id, canonical_symbol, broker_symbol,
+			status, warnings, errors, spec_age_ms, evaluated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, entry := range evaluation.Entries {
+		warnJSON, errMarshal := marshalIssues(entry.Warnings)
+		if errMarshal != nil {
+			err = fmt.Errorf("marshal entry warnings: %w", errMarshal)
+			return err
+		}
+		errJSON, errMarshal := marshalIssues(entry.Errors)
+		if errMarshal != nil {
+			err = fmt.Errorf("marshal entry errors: %w", errMarshal)
+			return err
+		}
+		entryEvaluatedAt := evaluation.EvaluatedAtMs
+		if entry.EvaluatedAtMs > 0 {
+			entryEvaluatedAt = entry.EvaluatedAtMs
+		}
+		if _, err = tx.ExecContext(ctx, insertEntry,
+			evaluation.EvaluationID,
+			entry.CanonicalSymbol,
+			entry.BrokerSymbol,
+			statusToString(entry.Status),
+			string(warnJSON),
+			string(errJSON),
+			entry.SpecAgeMs,
+			entryEvaluatedAt,
+		); err != nil {
+			return fmt.Errorf("insert entry %s: %w", entry.CanonicalSymbol, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit evaluation: %w", err)
+	}
+
+	// Reemplazo en proceso de pg_notify('echo_handshake_result', ...)
+	r.hub.handshakeEvaluated(evaluation.AccountID, evaluation.EvaluationID)
+
+	return nil
+}
+
+func (r *sqliteHandshakeRepo) GetLatestByAccount(ctx context.Context, accountID string) (*handshake.Evaluation, error) {
+	queryEval := `
+		SELECT evaluation_id, pipe_role, status, protocol_version, client_semver,
+		       global_errors, global_warnings, required_features, optional_features,
+		       capabilities, evaluated_at
+		FROM account_symbol_registration_eval
+		WHERE account_id = ?
+		ORDER BY evaluated_at DESC, rowid DESC
+		LIMIT 1
+	`
+
+	var (
+		evalID           string
+		pipeRole         string
+		statusStr        string
+		protocolVersion  int
+		clientSemver     string
+		errorsJSON       string
+		warningsJSON     string
+		requiredJSON     string
+		optionalJSON     string
+		capabilitiesJSON string
+		evaluatedAtMs    int64
+	)
+	err := r.db.QueryRowContext(ctx, queryEval, accountID).Scan(
+		&evalID,
+		&pipeRole,
+		&statusStr,
+		&protocolVersion,
+		&clientSemver,
+		&errorsJSON,
+		&warningsJSON,
+		&requiredJSON,
+		&optionalJSON,
+		&capabilitiesJSON,
+		&evaluatedAtMs,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("select evaluation: %w", err)
+	}
+
+	errorsIssues, err := unmarshalIssues([]byte(errorsJSON))
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal global errors: %w", err)
+	}
+	warningsIssues, err := unmarshalIssues([]byte(warningsJSON))
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal global warnings: %w", err)
+	}
+
+	evaluation := &handshake.Evaluation{
+		EvaluationID:    evalID,
+		AccountID:       accountID,
+		PipeRole:        pipeRole,
+		ProtocolVersion: protocolVersion,
+		ClientSemver:    clientSemver,
+		Status:          stringToStatus(statusStr),
+		Errors:          errorsIssues,
+		Warnings:        warningsIssues,
+		EvaluatedAtMs:   evaluatedAtMs,
+	}
+
+	if err := json.Unmarshal([]byte(requiredJSON), &evaluation.RequiredFeatures); err != nil {
+		return nil, fmt.Errorf("unmarshal required features: %w", err)
+	}
+	if err := json.Unmarshal([]byte(optionalJSON), &evaluation.OptionalFeatures); err != nil {
+		return nil, fmt.Errorf("unmarshal optional features: %w", err)
+	}
+	var capabilitiesPayload map[string][]string
+	if err := json.Unmarshal([]byte(capabilitiesJSON), &capabilitiesPayload); err == nil {
+		evaluation.Capabilities = handshake.CapabilitySet{
+			Features: capabilitiesPayload["features"],
+			Metrics:  capabilitiesPayload["metrics"],
+		}
+	}
+
+	queryEntries := `
+		SELECT canonical_symbol, broker_symbol, status,
+		       warnings, errors, spec_age_ms, evaluated_at
+		FROM account_symbol_registration
+		WHERE evaluation_id = ?
+	`
+	rows, err := r.db.QueryContext(ctx, queryEntries, evalID)
+	if err != nil {
+		return nil, fmt.Errorf("select entries: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			canonical   string
+			broker      string
+			status      string
+			warnJSON    string
+			errJSON     string
+			specAge     int64
+			entryEvalMs int64
+		)
+		if err = rows.Scan(&canonical, &broker, &status, &warnJSON, &errJSON, &specAge, &entryEvalMs); err != nil {
+			return nil, fmt.Errorf("scan entry: %w", err)
+		}
+		warnings, errWarn := unmarshalIssues([]byte(warnJSON))
+		if errWarn != nil {
+			return nil, fmt.Errorf("unmarshal entry warnings: %w", errWarn)
+		}
+		errorsIssues, errErr := unmarshalIssues([]byte(errJSON))
+		if errErr != nil {
+			return nil, fmt.Errorf("unmarshal entry errors: %w", errErr)
+		}
+
+		evaluation.Entries = append(evaluation.Entries, handshake.Entry{
+			CanonicalSymbol: canonical,
+			BrokerSymbol:    broker,
+			Status:          stringToStatus(status),
+			Warnings:        warnings,
+			Errors:          errorsIssues,
+			SpecAgeMs:       specAge,
+			EvaluatedAtMs:   entryEvalMs,
+		})
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return evaluation, nil
+}
+
+// marshalFeatures serializa features como array JSON ("[]" en lugar de "null").
+func marshalFeatures(features []string) ([]byte, error) {
+	if features == nil {
+		features = []string{}
+	}
+	return json.Marshal(features)
+}
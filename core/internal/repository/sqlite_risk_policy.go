@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ==========================================================================
+// sqliteRiskPolicyRepo
+// ==========================================================================
+
+// Mismo modelo que PostgreSQL (i7): el historial append-only es la fuente de verdad
+// y la tabla legacy solo aplica a pares sin historial.
+
+type sqliteRiskPolicyRepo struct {
+	db  *sql.DB
+	hub *changeHub
+	now func() time.Time
+}
+
+func (r *sqliteRiskPolicyRepo) Get(ctx context.Context, accountID, strategyID string) (*domain.RiskPolicy, error) {
+	at := r.now()
+
+	query := `
+		SELECT ` + riskPolicyHistoryColumns + `
+		FROM account_strategy_risk_policy_history
+		WHERE account_id = ? AND strategy_id = ? AND effective_from <= ?
+		ORDER BY effective_from DESC, version DESC
+		LIMIT 1
+	`
+	revisions, err := r.queryRevisions(ctx, accountID, strategyID, query, accountID, strategyID, at.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) > 0 {
+		if !revisions[0].IsEffectiveAt(at) {
+			return nil, nil
+		}
+		return &revisions[0].Policy, nil
+	}
+
+	var hasHistory bool
+	existsQuery := `SELECT EXISTS (SELECT 1 FROM account_strategy_risk_policy_history WHERE account_id = ? AND strategy_id = ?)`
+	if err := r.db.QueryRowContext(ctx, existsQuery, accountID, strategyID).Scan(&hasHistory); err != nil {
+		return nil, fmt.Errorf("failed to check risk policy history: %w", err)
+	}
+	if hasHistory {
+		// Solo revisiones programadas: aún no hay política vigente
+		return nil, nil
+	}
+
+	return r.getLegacy(ctx, accountID, strategyID)
+}
+
+func (r *sqliteRiskPolicyRepo) getLegacy(ctx context.Context, accountID, strategyID string) (*domain.RiskPolicy, error) {
+	query := `
+		SELECT risk_type, lot_size, config, risk_currency, risk_amount, version, updated_at, valid_until
+		FROM account_strategy_risk_policy
+		WHERE account_id = ? AND strategy_id = ?
+	`
+
+	var (
+		riskType     string
+		lotSize      sql.NullFloat64
+		configRaw    sql.NullString
+		riskCurrency sql.NullString
+		riskAmount   sql.NullFloat64
+		version      int64
+		updatedAt    int64
+		validUntil   sql.NullInt64
+	)
+	err := r.db.QueryRowContext(ctx, query, accountID, strategyID).Scan(&riskType, &lotSize, &configRaw, &riskCurrency, &riskAmount, &version, &updatedAt, &validUntil)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get risk policy: %w", err)
+	}
+
+	policy := &domain.RiskPolicy{
+		AccountID:     accountID,
+		StrategyID:    strategyID,
+		Type:          domain.RiskPolicyType(riskType),
+		Version:       version,
+		UpdatedAt:     time.UnixMilli(updatedAt),
+		EffectiveFrom: time.UnixMilli(updatedAt),
+	}
+	if validUntil.Valid {
+		until := time.UnixMilli(validUntil.Int64)
+		policy.ValidUntil = &until
+	}
+
+	if err := applyRiskPolicyConfig(policy, lotSize, configRaw, riskCurrency, riskAmount); err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+func (r *sqliteRiskPolicyRepo) GetVersion(ctx context.Context, accountID, strategyID string, version int64) (*domain.RiskPolicyRevision, error) {
+	query := `
+		SELECT ` + riskPolicyHistoryColumns + `
+		FROM account_strategy_risk_policy_history
+		WHERE account_id = ? AND strategy_id = ? AND version = ?
+	`
+	revisions, err := r.queryRevisions(ctx, accountID, strategyID, query, accountID, strategyID, version)
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return revisions[0], nil
+}
+
+func (r *sqliteRiskPolicyRepo) ListHistory(ctx context.Context, accountID, strategyID string) ([]*domain.RiskPolicyRevision, error) {
+	query := `
+		SELECT ` + riskPolicyHistoryColumns + `
+		FROM account_strategy_risk_policy_history
+		WHERE account_id = ? AND strategy_id = ?
+		ORDER BY version ASC
+	`
+	return r.queryRevisions(ctx, accountID, strategyID, query, accountID, strategyID)
+}
+
+func (r *sqliteRiskPolicyRepo) List(ctx context.Context) ([]*domain.RiskPolicy, error) {
+	query := `
+		SELECT account_id, strategy_id FROM account_strategy_risk_policy_history
+		UNION
+		SELECT account_id, strategy_id FROM account_strategy_risk_policy
+		ORDER BY account_id, strategy_id
+	`
+	return listRiskPolicies(ctx, r.db, query, r.Get)
+}
+
+func (r *sqliteRiskPolicyRepo) AppendRevision(ctx context.Context, revision *domain.RiskPolicyRevision) (int64, error) {
+	if err := domain.ValidateRiskPolicyRevision(revision); err != nil {
+		return 0, err
+	}
+
+	policy := &revision.Policy
+
+	var (
+		lotSize      sql.NullFloat64
+		configJSON   = "{}"
+		riskCurrency sql.NullString
+		riskAmount   sql.NullFloat64
+	)
+	switch policy.Type {
+	case domain.RiskPolicyTypeFixedLot:
+		lotSize = sql.NullFloat64{Float64: policy.FixedLot.LotSize, Valid: true}
+	case domain.RiskPolicyTypeFixedRisk:
+		raw, err := domain.MarshalFixedRiskConfig(policy.FixedRisk)
+		if err != nil {
+			return 0, err
+		}
+		configJSON = string(raw)
+		riskCurrency = sql.NullString{String: policy.FixedRisk.Currency, Valid: true}
+		riskAmount = sql.NullFloat64{Float64: policy.FixedRisk.Amount, Valid: true}
+	}
+
+	recordedAt := r.now()
+	effectiveFrom := policy.EffectiveFrom
+	if effectiveFrom.IsZero() {
+		effectiveFrom = recordedAt
+	}
+
+	// Con una sola conexión en el pool la transacción ya excluye a otros escritores
+	// del proceso; busy_timeout cubre a escritores externos (echo-core-cli).
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	var version int64
+	nextVersionQuery := `
+		SELECT COALESCE(MAX(version), 0) + 1
+		FROM account_strategy_risk_policy_history
+		WHERE account_id = ? AND strategy_id = ?
+	`
+	if err = tx.QueryRowContext(ctx, nextVersionQuery, policy.AccountID, policy.StrategyID).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to compute risk policy version: %w", err)
+	}
+
+	insert := `
+		INSERT INTO account_strategy_risk_policy_history (
+			account_id, strategy_id, version, risk_type, lot_size, config,
+			risk_currency, risk_amount, effective_from, valid_until, author, reason, recorded_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	if _, err = tx.ExecContext(ctx, insert,
+		policy.AccountID,
+		policy.StrategyID,
+		version,
+		string(policy.Type),
+		lotSize,
+		configJSON,
+		riskCurrency,
+		riskAmount,
+		effectiveFrom.UnixMilli(),
+		nullableTimeMs(policy.ValidUntil),
+		strings.TrimSpace(revision.Author),
+		revision.Reason,
+		recordedAt.UnixMilli(),
+	); err != nil {
+		return 0, fmt.Errorf("failed to append risk policy revision: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit tx: %w", err)
+	}
+
+	policy.Version = version
+	policy.EffectiveFrom = time.UnixMilli(effectiveFrom.UnixMilli())
+	policy.UpdatedAt = time.UnixMilli(recordedAt.UnixMilli())
+	revision.RecordedAt = policy.UpdatedAt
+
+	// Reemplazo en proceso del trigger trg_risk_policy_history_changed
+	r.hub.riskPolicyChanged(policy.AccountID, policy.StrategyID)
+
+	return version, nil
+}
+
+func (r *sqliteRiskPolicyRepo) queryRevisions(ctx context.Context, accountID, strategyID, query string, args ...interface{}) ([]*domain.RiskPolicyRevision, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query risk policy history: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []*domain.RiskPolicyRevision
+	for rows.Next() {
+		var (
+			version       int64
+			riskType      string
+			lotSize       sql.NullFloat64
+			configRaw     sql.NullString
+			riskCurrency  sql.NullString
+			riskAmount    sql.NullFloat64
+			effectiveFrom int64
+			validUntil    sql.NullInt64
+			author        string
+			reason        string
+			recordedAt    int64
+		)
+		if err := rows.Scan(&version, &riskType, &lotSize, &configRaw, &riskCurrency, &riskAmount,
+			&effectiveFrom, &validUntil, &author, &reason, &recordedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan risk policy revision: %w", err)
+		}
+
+		revision := &domain.RiskPolicyRevision{
+			Policy: domain.RiskPolicy{
+				AccountID:     accountID,
+				StrategyID:    strategyID,
+				Type:          domain.RiskPolicyType(riskType),
+				Version:       version,
+				UpdatedAt:     time.UnixMilli(recordedAt),
+				EffectiveFrom: time.UnixMilli(effectiveFrom),
+			},
+			Author:     author,
+			Reason:     reason,
+			RecordedAt: time.UnixMilli(recordedAt),
+		}
+		if validUntil.Valid {
+			until := time.UnixMilli(validUntil.Int64)
+			revision.Policy.ValidUntil = &until
+		}
+		if err := applyRiskPolicyConfig(&revision.Policy, lotSize, configRaw, riskCurrency, riskAmount); err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// WatchRiskPolicyHistory detecta revisiones agregadas por otros procesos sobre el
+// mismo archivo (echo-core-cli policy) y las notifica vía OnRiskPolicyChanged (i8).
+//
+// Reemplaza cross-proceso al canal echo_risk_policy_updated: cada interval lee las
+// filas nuevas del historial (rowid monótono, la tabla es append-only). Las escrituras
+// de este proceso también se observan; invalidar dos veces es inocuo.
+func (f *SQLiteFactory) WatchRiskPolicyHistory(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("risk policy poll interval must be positive")
+	}
+
+	var lastRowID int64
+	if err := f.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(rowid), 0) FROM account_strategy_risk_policy_history`).Scan(&lastRowID); err != nil {
+		return fmt.Errorf("failed to read risk policy history watermark: %w", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// Ante error se reintenta en el próximo tick desde el mismo watermark
+			if next, err := f.pollRiskPolicyHistory(ctx, lastRowID); err == nil {
+				lastRowID = next
+			}
+		}
+	}()
+	return nil
+}
+
+// pollRiskPolicyHistory notifica los pares con revisiones posteriores a afterRowID y
+// retorna el nuevo watermark.
+func (f *SQLiteFactory) pollRiskPolicyHistory(ctx context.Context, afterRowID int64) (int64, error) {
+	rows, err := f.db.QueryContext(ctx, `
+		SELECT rowid, account_id, strategy_id
+		FROM account_strategy_risk_policy_history
+		WHERE rowid > ?
+		ORDER BY rowid ASC
+	`, afterRowID)
+	if err != nil {
+		return afterRowID, fmt.Errorf("failed to poll risk policy history: %w", err)
+	}
+
+	type pair struct{ accountID, strategyID string }
+	var changed []pair
+	lastRowID := afterRowID
+	for rows.Next() {
+		var (
+			rowID int64
+			p     pair
+		)
+		if err := rows.Scan(&rowID, &p.accountID, &p.strategyID); err != nil {
+			rows.Close()
+			return afterRowID, fmt.Errorf("failed to scan risk policy history row: %w", err)
+		}
+		lastRowID = rowID
+		changed = append(changed, p)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return afterRowID, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close()
+
+	// Callbacks fuera del cursor: pueden releer políticas por la única conexión del pool
+	for _, p := range changed {
+		f.riskPolicyChanged(p.accountID, p.strategyID)
+	}
+	return lastRowID, nil
+}
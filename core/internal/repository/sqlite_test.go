@@ -0,0 +1,559 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/core/internal/migrations"
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/domain/handshake"
+)
+
+func newTestSQLiteFactory(t *testing.T) *SQLiteFactory {
+	t.Helper()
+	db, err := OpenSQLite(context.Background(), filepath.Join(t.TempDir(), "echo.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	runner, err := migrations.NewRunner(db, migrations.DialectSQLite)
+	require.NoError(t, err)
+	_, err = runner.Migrate(context.Background())
+	require.NoError(t, err)
+	return NewSQLiteFactory(db, 0)
+}
+
+func TestSQLiteTradeAndExecutionConstraints(t *testing.T) {
+	ctx := context.Background()
+	factory := newTestSQLiteFactory(t)
+	execRepo := factory.ExecutionRepository()
+
+	// FK a trades
+	assert.Error(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e0", TradeID: "missing", SlaveAccountID: "s1"}))
+
+	require.NoError(t, factory.TradeRepository().Create(ctx, &domain.Trade{TradeID: "t1", SourceMasterID: "m", MasterAccountID: "m", MasterTicket: 10, MagicNumber: 1, Symbol: "XAUUSD", Side: domain.OrderSideBuy, LotSize: 0.1, Price: 2000, Status: domain.OrderStatusPending}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+	assert.Error(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e2", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+
+	ticket, err := execRepo.GetTicketByTradeAndSlave(ctx, "t1", "s1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(555), ticket)
+}
+
+func TestSQLiteClosePersistsPnL(t *testing.T) {
+	ctx := context.Background()
+	factory := newTestSQLiteFactory(t)
+	f := func(v float64) *float64 { return &v }
+
+	require.NoError(t, factory.TradeRepository().Create(ctx, &domain.Trade{TradeID: "t1", SourceMasterID: "m", MasterAccountID: "m", MasterTicket: 10, MagicNumber: 1, Symbol: "XAUUSD", Side: domain.OrderSideBuy, LotSize: 0.1, Price: 2000, Status: domain.OrderStatusFilled}))
+	require.NoError(t, factory.ExecutionRepository().Create(ctx, &domain.Execution{ExecutionID: "e1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+	require.NoError(t, factory.CorrelationService().RecordClose(ctx, &domain.Close{CloseID: "c1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true,
+		ClosePrice: f(2010.5), ClosedVolume: f(0.1), RemainingVolume: f(0), Profit: f(105), Commission: f(-0.7), Swap: f(0), ClosedAtMs: time.Now().UnixMilli()}))
+	require.NoError(t, factory.CloseRepository().Create(ctx, &domain.Close{CloseID: "c0", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: false, ErrorCode: "ERR_REQUOTE", ClosedAtMs: 1}))
+	require.NoError(t, factory.ModifyRepository().Create(ctx, &domain.Modify{ModifyID: "m2", TradeID: "t1", MasterTicket: 10, NewStopLoss: f(1995), NewTakeProfit: f(2020), ModifiedAtMs: 20}))
+	require.NoError(t, factory.ModifyRepository().Create(ctx, &domain.Modify{ModifyID: "m1", TradeID: "t1", MasterTicket: 10, NewStopLoss: f(1990), ModifiedAtMs: 10}))
+	assert.Error(t, factory.ModifyRepository().Create(ctx, &domain.Modify{ModifyID: "m3", TradeID: "t9", MasterTicket: 11, ModifiedAtMs: 30}))
+
+	close, err := factory.CloseRepository().GetByID(ctx, "c1")
+	require.NoError(t, err)
+	require.NotNil(t, close)
+	require.NotNil(t, close.Profit)
+	assert.Equal(t, 105.0, *close.Profit)
+	assert.Equal(t, -0.7, *close.Commission)
+	require.NotNil(t, close.Swap)
+	assert.Equal(t, 0.0, *close.Swap)
+	assert.False(t, close.IsPartial())
+
+	rejected, err := factory.CloseRepository().GetByID(ctx, "c0")
+	require.NoError(t, err)
+	assert.Nil(t, rejected.Profit)
+	assert.Nil(t, rejected.ClosePrice)
+
+	lifecycle, err := factory.TradeLifecycleRepository().GetLifecycle(ctx, "t1", "s1")
+	require.NoError(t, err)
+	require.NotNil(t, lifecycle)
+	assert.Equal(t, domain.TradeLifecycleStatusClosed, lifecycle.Status)
+	require.Len(t, lifecycle.Closes, 2)
+	assert.Equal(t, "c0", lifecycle.Closes[0].CloseID)
+	assert.InDelta(t, 104.3, lifecycle.NetProfit(), 1e-9)
+	require.Len(t, lifecycle.Modifies, 2)
+	assert.Equal(t, "m1", lifecycle.Modifies[0].ModifyID)
+	assert.Nil(t, lifecycle.Modifies[0].NewTakeProfit)
+	assert.Equal(t, 1995.0, *lifecycle.Modifies[1].NewStopLoss)
+	assert.Equal(t, 2020.0, *lifecycle.Modifies[1].NewTakeProfit)
+	assert.Equal(t, int64(20), lifecycle.Modifies[1].ModifiedAtMs)
+}
+
+func TestSQLiteRiskPolicyHistoryNotifies(t *testing.T) {
+	ctx := context.Background()
+	factory := newTestSQLiteFactory(t)
+	repo := factory.RiskPolicyRepository()
+
+	var notified []string
+	factory.OnRiskPolicyChanged(func(accountID, strategyID string) {
+		notified = append(notified, accountID+":"+strategyID)
+	})
+
+	_, err := repo.AppendRevision(ctx, &domain.RiskPolicyRevision{
+		Policy: domain.RiskPolicy{AccountID: "acc", StrategyID: "s", Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.1}},
+		Author: "ops",
+	})
+	require.NoError(t, err)
+	v2, err := repo.AppendRevision(ctx, &domain.RiskPolicyRevision{
+		Policy: domain.RiskPolicy{AccountID: "acc", StrategyID: "s", Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.5}, EffectiveFrom: time.Now().Add(time.Hour)},
+		Author: "ops",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v2)
+
+	current, err := repo.Get(ctx, "acc", "s")
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	assert.Equal(t, int64(1), current.Version)
+
+	history, err := repo.ListHistory(ctx, "acc", "s")
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, []string{"acc:s", "acc:s"}, notified)
+}
+
+func TestSQLiteRiskPolicyListAndExternalChanges(t *testing.T) {
+	ctx := context.Background()
+	core := newTestSQLiteFactory(t)
+	// Segundo factory sobre la misma base: simula echo-core-cli en otro proceso
+	cli := NewSQLiteFactory(core.db, 0)
+
+	var notified []string
+	core.OnRiskPolicyChanged(func(accountID, strategyID string) {
+		notified = append(notified, accountID+":"+strategyID)
+	})
+	watermark, err := core.pollRiskPolicyHistory(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, watermark)
+
+	_, err = core.db.ExecContext(ctx, `INSERT INTO account_strategy_risk_policy (account_id, strategy_id, risk_type, lot_size, updated_at) VALUES ('legacy', 's', 'FIXED_LOT', 0.2, ?)`, time.Now().UnixMilli())
+	require.NoError(t, err)
+	for _, accountID := range []string{"b", "a"} {
+		_, err = cli.RiskPolicyRepository().AppendRevision(ctx, &domain.RiskPolicyRevision{
+			Policy: domain.RiskPolicy{AccountID: accountID, StrategyID: "s", Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.1}},
+			Author: "cli:ops",
+		})
+		require.NoError(t, err)
+	}
+	_, err = cli.RiskPolicyRepository().AppendRevision(ctx, &domain.RiskPolicyRevision{
+		Policy: domain.RiskPolicy{AccountID: "scheduled", StrategyID: "s", Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.1}, EffectiveFrom: time.Now().Add(time.Hour)},
+		Author: "cli:ops",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, notified, "el hub en proceso no ve escrituras de otro factory")
+
+	watermark, err = core.pollRiskPolicyHistory(ctx, watermark)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b:s", "a:s", "scheduled:s"}, notified)
+	_, err = core.pollRiskPolicyHistory(ctx, watermark)
+	require.NoError(t, err)
+	assert.Len(t, notified, 3, "sin revisiones nuevas no hay notificaciones")
+
+	policies, err := core.RiskPolicyRepository().List(ctx)
+	require.NoError(t, err)
+	var pairs []string
+	for _, policy := range policies {
+		pairs = append(pairs, policy.AccountID+":"+policy.StrategyID)
+	}
+	assert.Equal(t, []string{"a:s", "b:s", "legacy:s"}, pairs, "ordenado y sin pares solo programados")
+}
+
+func TestSQLiteHandshakeRepoNotifiesEvaluation(t *testing.T) {
+	ctx := context.Background()
+	factory := newTestSQLiteFactory(t)
+	repo := factory.HandshakeRepository()
+
+	var notified []string
+	factory.OnHandshakeEvaluated(func(accountID, _ string) {
+		notified = append(notified, accountID)
+	})
+
+	require.NoError(t, repo.CreateEvaluation(ctx, &handshake.Evaluation{
+		AccountID:     "acc",
+		PipeRole:      "slave",
+		EvaluatedAtMs: 200,
+		Status:        handshake.RegistrationStatusAccepted,
+		Entries: []handshake.Entry{
+			{CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.m", Status: handshake.RegistrationStatusAccepted},
+		},
+	}))
+	require.NoError(t, repo.CreateEvaluation(ctx, &handshake.Evaluation{AccountID: "acc", PipeRole: "slave", EvaluatedAtMs: 100, Status: handshake.RegistrationStatusRejected}))
+
+	latest, err := repo.GetLatestByAccount(ctx, "acc")
+	require.NoError(t, err)
+	require.NotNil(t, latest)
+	assert.Equal(t, int64(200), latest.EvaluatedAtMs)
+	require.Len(t, latest.Entries, 1)
+	assert.Equal(t, "XAUUSD.m", latest.Entries[0].BrokerSymbol)
+	assert.Equal(t, []string{"acc", "acc"}, notified)
+}
+
+func TestSQLiteOutboxEnqueueIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	factory := newTestSQLiteFactory(t)
+	outbox := factory.OutboxRepository()
+
+	trade := &domain.Trade{TradeID: "t1", SourceMasterID: "m", MasterAccountID: "m", MasterTicket: 10, MagicNumber: 1, Symbol: "XAUUSD", Side: domain.OrderSideBuy, LotSize: 0.1, Price: 2000, Status: domain.OrderStatusPending}
+	command := &domain.OutboxCommand{CommandID: "c1", TradeID: "t1", TargetAccountID: "s1", CommandType: "execute_order", Payload: []byte{0x0a, 0x02}, CreatedAtMs: 1000}
+
+	// command_id duplicado: rollback de trade y dedupe
+	assert.Error(t, outbox.EnqueueTrade(ctx, trade, &domain.DedupeEntry{TradeID: "t1", Status: domain.OrderStatusPending}, []*domain.OutboxCommand{command, command}))
+	stored, err := factory.TradeRepository().GetByID(ctx, "t1")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+	exists, err := factory.DedupeRepository().Exists(ctx, "t1")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, outbox.EnqueueTrade(ctx, trade, &domain.DedupeEntry{TradeID: "t1", Status: domain.OrderStatusPending}, []*domain.OutboxCommand{command}))
+	stored, err = factory.TradeRepository().GetByID(ctx, "t1")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+
+	deliverable, err := outbox.ListDeliverable(ctx, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, deliverable, 1)
+	assert.Equal(t, []byte{0x0a, 0x02}, deliverable[0].Payload)
+	assert.Equal(t, domain.OutboxStatusPending, deliverable[0].Status)
+
+	require.NoError(t, outbox.MarkDispatched(ctx, "c1", "agent-1", 2000))
+	expired, err := outbox.ExpireOlderThan(ctx, 1500, 3000)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, domain.OutboxStatusExpired, expired[0].Status)
+	assert.Equal(t, 1, expired[0].Attempts)
+
+	// Un ack tardío confirma igualmente la entrega
+	acked, err := outbox.MarkAcked(ctx, "c1", 4000)
+	require.NoError(t, err)
+	assert.True(t, acked)
+
+	deleted, err := outbox.PurgeCompleted(ctx, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestSQLiteOutboxKeepsAuditAndDispatch(t *testing.T) {
+	ctx := context.Background()
+	outbox := newTestSQLiteFactory(t).OutboxRepository()
+
+	trade := &domain.Trade{TradeID: "t1", SourceMasterID: "m", MasterAccountID: "m", MasterTicket: 10, MagicNumber: 1, Symbol: "XAUUSD", Side: domain.OrderSideBuy, LotSize: 0.1, Price: 2000, Status: domain.OrderStatusPending}
+	commands := []*domain.OutboxCommand{
+		{CommandID: "c1", TradeID: "t1", TargetAccountID: "s1", CommandType: "execute_order", Payload: []byte{0x0a}, CreatedAtMs: 1000,
+			Status: domain.OutboxStatusDispatched, Attempts: 1, LastAgentID: "agent-1", DispatchedAtMs: 1000,
+			StrategyID: "scalper", RiskPolicyType: "FIXED_LOT", RiskPolicyVersion: 3, Symbol: "XAUUSD", ReferencePrice: 2000.5, Point: 0.01},
+		// Un estado distinto de DISPATCHED se encola PENDING sin datos de entrega
+		{CommandID: "c2", TradeID: "t1", TargetAccountID: "s2", CommandType: "execute_order", Payload: []byte{0x0a}, CreatedAtMs: 1000,
+			Status: domain.OutboxStatusAcked, Attempts: 5, LastAgentID: "agent-2"},
+	}
+	require.NoError(t, outbox.EnqueueTrade(ctx, trade, &domain.DedupeEntry{TradeID: "t1", Status: domain.OrderStatusPending}, commands))
+
+	dispatched, err := outbox.GetByID(ctx, "c1")
+	require.NoError(t, err)
+	require.NotNil(t, dispatched)
+	assert.Equal(t, domain.OutboxStatusDispatched, dispatched.Status)
+	assert.Equal(t, 1, dispatched.Attempts)
+	assert.Equal(t, "agent-1", dispatched.LastAgentID)
+	assert.Equal(t, int64(1000), dispatched.DispatchedAtMs)
+	assert.Equal(t, "scalper", dispatched.StrategyID)
+	assert.Equal(t, "FIXED_LOT", dispatched.RiskPolicyType)
+	assert.Equal(t, int64(3), dispatched.RiskPolicyVersion)
+	assert.Equal(t, "XAUUSD", dispatched.Symbol)
+	assert.Equal(t, 2000.5, dispatched.ReferencePrice)
+	assert.Equal(t, 0.01, dispatched.Point)
+
+	// La fila ya reclamada no es entregable hasta RedeliverAfter
+	deliverable, err := outbox.ListDeliverable(ctx, 999, 10)
+	require.NoError(t, err)
+	require.Len(t, deliverable, 1)
+	assert.Equal(t, "c2", deliverable[0].CommandID)
+	assert.Equal(t, domain.OutboxStatusPending, deliverable[0].Status)
+	assert.Zero(t, deliverable[0].Attempts)
+	assert.Empty(t, deliverable[0].LastAgentID)
+}
+
+func TestSQLiteAgentLivenessReplacesAccounts(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestSQLiteFactory(t).AgentLivenessRepository()
+
+	require.NoError(t, repo.Upsert(ctx, &domain.AgentLiveness{AgentID: "agent-1", Status: domain.LivenessStatusAlive, LastSeenAtMs: 1000, HeartbeatIntervalMs: 5000, PendingCommands: 2, UpdatedAtMs: 1000,
+		Accounts: []*domain.AccountLiveness{
+			{PipeName: "echo_slave_s1", AccountID: "s1", PipeRole: "slave", Status: domain.LivenessStatusAlive, Codec: "json", LastSeenAtMs: 1000},
+			{PipeName: "echo_master_m1", AccountID: "m1", PipeRole: "master", Status: domain.LivenessStatusAlive, Codec: "json", LastSeenAtMs: 1000},
+		}}))
+
+	// El siguiente heartbeat reemplaza las cuentas del Agent
+	require.NoError(t, repo.Upsert(ctx, &domain.AgentLiveness{AgentID: "agent-1", Status: domain.LivenessStatusStale, LastSeenAtMs: 2000, HeartbeatIntervalMs: 5000, StaleSinceMs: 20000, UpdatedAtMs: 20000,
+		Accounts: []*domain.AccountLiveness{
+			{PipeName: "echo_slave_s1", AccountID: "s1", PipeRole: "slave", Status: domain.LivenessStatusStale, Codec: "framed_protobuf", LastSeenAtMs: 2000},
+		}}))
+	require.NoError(t, repo.Upsert(ctx, &domain.AgentLiveness{AgentID: "agent-0", Status: domain.LivenessStatusDisconnected, LastSeenAtMs: 500, UpdatedAtMs: 600}))
+
+	agents, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, agents, 2)
+	assert.Equal(t, "agent-0", agents[0].AgentID)
+	assert.Len(t, agents[0].Accounts, 0)
+	assert.Equal(t, domain.LivenessStatusStale, agents[1].Status)
+	assert.Equal(t, int64(20000), agents[1].StaleSinceMs)
+	require.Len(t, agents[1].Accounts, 1)
+	assert.Equal(t, "agent-1", agents[1].Accounts[0].AgentID)
+	assert.Equal(t, "framed_protobuf", agents[1].Accounts[0].Codec)
+
+	deleted, err := repo.PurgeOlderThan(ctx, 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	agents, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, agents, 1)
+	assert.Equal(t, "agent-1", agents[0].AgentID)
+}
+
+func TestSQLiteAccountOwnershipPreservesForcedTakeover(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestSQLiteFactory(t).AccountOwnershipRepository()
+
+	require.NoError(t, repo.Upsert(ctx, &domain.AccountOwnership{AccountID: "s1", AgentID: "agent-1", PipeRole: "slave", Status: domain.OwnershipStatusActive, RegisteredAtMs: 1000, UpdatedAtMs: 1000}))
+
+	// Takeover de operador sobre cuenta existente y sobre cuenta nueva
+	require.NoError(t, repo.RequestTakeover(ctx, "s1", "agent-2", 2000))
+	require.NoError(t, repo.RequestTakeover(ctx, "s0", "agent-3", 2000))
+
+	// El Core sigue escribiendo el owner sin pisar el pedido del operador
+	require.NoError(t, repo.Upsert(ctx, &domain.AccountOwnership{AccountID: "s1", AgentID: "agent-1", PipeRole: "slave", Status: domain.OwnershipStatusActive, RegisteredAtMs: 1000, UpdatedAtMs: 3000, ClaimantAgentID: "agent-2", ConflictAtMs: 2500}))
+
+	accounts, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "s0", accounts[0].AccountID)
+	assert.Equal(t, domain.OwnershipStatusDetached, accounts[0].Status)
+	assert.Equal(t, "agent-3", accounts[0].ForcedAgentID)
+	assert.Equal(t, "agent-2", accounts[1].ForcedAgentID)
+	assert.Equal(t, "agent-2", accounts[1].ClaimantAgentID)
+	assert.Equal(t, int64(3000), accounts[1].UpdatedAtMs)
+
+	// Solo se limpia si el pedido no cambió
+	require.NoError(t, repo.ClearTakeover(ctx, "s1", "agent-9"))
+	require.NoError(t, repo.ClearTakeover(ctx, "s0", "agent-3"))
+	require.NoError(t, repo.Delete(ctx, "missing"))
+
+	accounts, err = repo.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "", accounts[0].ForcedAgentID)
+	assert.Equal(t, "agent-2", accounts[1].ForcedAgentID)
+
+	require.NoError(t, repo.Delete(ctx, "s0"))
+	accounts, err = repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	assert.Equal(t, "s1", accounts[0].AccountID)
+}
+
+func TestSQLiteAccountCopyControlUpsert(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestSQLiteFactory(t).AccountCopyControlRepository()
+
+	require.NoError(t, repo.Upsert(ctx, &domain.AccountCopyControl{AccountID: "s2", CopyEnabled: false, Reason: "maintenance", UpdatedBy: "ops", UpdatedAtMs: 1000}))
+	require.NoError(t, repo.Upsert(ctx, &domain.AccountCopyControl{AccountID: "s1", CopyEnabled: false, UpdatedAtMs: 1000}))
+	require.NoError(t, repo.Upsert(ctx, &domain.AccountCopyControl{AccountID: "s1", CopyEnabled: true, UpdatedBy: "ops", UpdatedAtMs: 2000}))
+
+	controls, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, controls, 2)
+	assert.Equal(t, "s1", controls[0].AccountID)
+	assert.True(t, controls[0].CopyEnabled)
+	assert.Equal(t, int64(2000), controls[0].UpdatedAtMs)
+	assert.False(t, controls[1].CopyEnabled)
+	assert.Equal(t, "maintenance", controls[1].Reason)
+}
+
+func TestSQLiteSymbolOverrides(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestSQLiteFactory(t).SymbolOverrideRepository()
+
+	require.NoError(t, repo.Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD", UpdatedBy: "cli:ops", UpdatedAtMs: 1000}))
+	require.NoError(t, repo.Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "EURUSD", BrokerSymbol: "EURUSD.m", UpdatedAtMs: 1000}))
+	require.NoError(t, repo.Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.pro", Reason: "GOLD sin liquidez", UpdatedBy: "cli:ops", UpdatedAtMs: 2000}))
+	require.NoError(t, repo.Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s2", CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD", UpdatedAtMs: 1000}))
+
+	overrides, err := repo.ListByAccount(ctx, "s1")
+	require.NoError(t, err)
+	require.Len(t, overrides, 2)
+	assert.Equal(t, "EURUSD", overrides[0].CanonicalSymbol)
+	assert.Equal(t, "XAUUSD.pro", overrides[1].BrokerSymbol)
+	assert.Equal(t, "GOLD sin liquidez", overrides[1].Reason)
+	assert.Equal(t, int64(2000), overrides[1].UpdatedAtMs)
+
+	deleted, err := repo.Delete(ctx, "s1", "XAUUSD")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = repo.Delete(ctx, "s1", "XAUUSD")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	overrides, err = repo.ListByAccount(ctx, "s1")
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	overrides, err = repo.ListByAccount(ctx, "s2")
+	require.NoError(t, err)
+	require.Len(t, overrides, 1, "otras cuentas no se ven afectadas")
+}
+
+func TestSQLiteSymbolPinAndExternalChanges(t *testing.T) {
+	ctx := context.Background()
+	core := newTestSQLiteFactory(t)
+	// Segundo factory sobre la misma base: simula echo-core-cli en otro proceso
+	cli := NewSQLiteFactory(core.db, 0)
+
+	var notified []string
+	core.OnSymbolMappingChanged(func(accountID string) {
+		notified = append(notified, accountID)
+	})
+	watermark, err := core.pollSymbolOverrideChanges(ctx, 0)
+	require.NoError(t, err)
+	assert.Zero(t, watermark)
+
+	// El EA reportó GOLD a las 3000; el pin (posterior) lo reemplaza junto con el override
+	require.NoError(t, cli.SymbolRepository().UpsertAccountMapping(ctx, "s1", []*domain.SymbolMapping{{CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD", Digits: 2}}, 3000))
+	pinned := &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.pro", UpdatedBy: "cli:ops", UpdatedAtMs: 4000}
+	require.NoError(t, cli.SymbolOverrideRepository().Pin(ctx, pinned, &domain.SymbolMapping{CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.pro", Digits: 2, Source: domain.SymbolMappingSourceOverride, Confidence: 1}))
+	require.NoError(t, cli.SymbolOverrideRepository().Upsert(ctx, &domain.SymbolMappingOverride{AccountID: "s1", CanonicalSymbol: "EURUSD", BrokerSymbol: "EURUSD.m", UpdatedAtMs: 4000}))
+	deleted, err := cli.SymbolOverrideRepository().Delete(ctx, "s2", "XAUUSD")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	assert.Empty(t, notified, "el hub en proceso no ve escrituras de otro factory")
+
+	mappings, err := core.SymbolRepository().GetAccountMapping(ctx, "s1")
+	require.NoError(t, err)
+	require.Contains(t, mappings, "XAUUSD")
+	assert.Equal(t, "XAUUSD.pro", mappings["XAUUSD"].BrokerSymbol)
+
+	watermark, err = core.pollSymbolOverrideChanges(ctx, watermark)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1"}, notified, "una notificación por cuenta")
+
+	deleted, err = cli.SymbolOverrideRepository().Delete(ctx, "s1", "EURUSD")
+	require.NoError(t, err)
+	require.True(t, deleted)
+	watermark, err = core.pollSymbolOverrideChanges(ctx, watermark)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"s1", "s1"}, notified)
+	_, err = core.pollSymbolOverrideChanges(ctx, watermark)
+	require.NoError(t, err)
+	assert.Len(t, notified, 2, "sin cambios nuevos no hay notificaciones")
+}
+
+func TestSQLiteSymbolProposals(t *testing.T) {
+	ctx := context.Background()
+	factory := newTestSQLiteFactory(t)
+	repo := factory.SymbolProposalRepository()
+
+	require.NoError(t, repo.ReplaceForAccount(ctx, "s1", []*domain.SymbolMatchProposal{
+		{BrokerSymbol: "GOLD", CanonicalSymbol: "XAUUSD", Confidence: 0.8, Rule: "alias", ProposedAtMs: 1000},
+		{BrokerSymbol: "EURUSD.x", CanonicalSymbol: "EURUSD", Confidence: 0.65, Rule: "strip", Checks: []string{"digits 3≠5", "contract_size ok"}, ProposedAtMs: 1000},
+	}))
+	require.NoError(t, repo.ReplaceForAccount(ctx, "s2", []*domain.SymbolMatchProposal{
+		{BrokerSymbol: "GOLD", CanonicalSymbol: "XAUUSD", Confidence: 0.8, Rule: "alias", ProposedAtMs: 1000},
+	}))
+
+	proposals, err := repo.ListByAccount(ctx, "s1")
+	require.NoError(t, err)
+	require.Len(t, proposals, 2)
+	assert.Equal(t, "EURUSD.x", proposals[0].BrokerSymbol)
+	assert.Equal(t, "s1", proposals[0].AccountID)
+	assert.Equal(t, 0.65, proposals[0].Confidence)
+	assert.Equal(t, []string{"digits 3≠5", "contract_size ok"}, proposals[0].Checks)
+	assert.Equal(t, []string{}, proposals[1].Checks)
+
+	require.NoError(t, repo.ReplaceForAccount(ctx, "s1", nil))
+	proposals, err = repo.ListByAccount(ctx, "s1")
+	require.NoError(t, err)
+	assert.Empty(t, proposals)
+	proposals, err = repo.ListByAccount(ctx, "s2")
+	require.NoError(t, err)
+	require.Len(t, proposals, 1, "otras cuentas no se ven afectadas")
+
+	// Origen y confianza del mapeo persisten junto al resto de parámetros
+	contract := 100.0
+	symbols := factory.SymbolRepository()
+	require.NoError(t, symbols.UpsertAccountMapping(ctx, "s1", []*domain.SymbolMapping{
+		{CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD", Digits: 2, ContractSize: &contract, BaseCurrency: "XAU", ProfitCurrency: "USD", Source: domain.SymbolMappingSourceMatcher, Confidence: 0.9},
+	}, 1000))
+	infos, err := symbols.GetAccountMapping(ctx, "s1")
+	require.NoError(t, err)
+	require.Contains(t, infos, "XAUUSD")
+	assert.Equal(t, domain.SymbolMappingSourceMatcher, infos["XAUUSD"].Source)
+	assert.Equal(t, 0.9, infos["XAUUSD"].Confidence)
+	assert.Equal(t, "XAU", infos["XAUUSD"].BaseCurrency)
+}
+
+func TestSQLiteListFiltered(t *testing.T) {
+	ctx := context.Background()
+	factory := newTestSQLiteFactory(t)
+	tradeRepo := factory.TradeRepository()
+	execRepo := factory.ExecutionRepository()
+
+	require.NoError(t, tradeRepo.Create(ctx, &domain.Trade{TradeID: "t1", SourceMasterID: "m1", MasterAccountID: "m1", MasterTicket: 10, MagicNumber: 1, Symbol: "XAUUSD", Side: domain.OrderSideBuy, LotSize: 0.1, Price: 2000, Status: domain.OrderStatusFilled}))
+	require.NoError(t, tradeRepo.Create(ctx, &domain.Trade{TradeID: "t2", SourceMasterID: "m2", MasterAccountID: "m2", MasterTicket: 11, MagicNumber: 1, Symbol: "EURUSD", Side: domain.OrderSideSell, LotSize: 0.2, Price: 1.1, Status: domain.OrderStatusPending}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+	require.NoError(t, execRepo.Create(ctx, &domain.Execution{ExecutionID: "e2", TradeID: "t1", SlaveAccountID: "s2", Success: false, ErrorCode: "ERR_NO_MONEY"}))
+
+	trades, err := tradeRepo.ListFiltered(ctx, domain.TradeFilter{}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, trades, 2)
+	assert.Equal(t, "t2", trades[0].TradeID)
+
+	trades, err = tradeRepo.ListFiltered(ctx, domain.TradeFilter{Status: domain.OrderStatusFilled, MasterAccountID: "m1", Symbol: "XAUUSD"}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	assert.Equal(t, "t1", trades[0].TradeID)
+
+	now := time.Now()
+	trades, err = tradeRepo.ListFiltered(ctx, domain.TradeFilter{CreatedFromMs: now.Add(-time.Hour).UnixMilli(), CreatedToMs: now.Add(time.Hour).UnixMilli()}, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	assert.Equal(t, "t1", trades[0].TradeID)
+
+	trades, err = tradeRepo.ListFiltered(ctx, domain.TradeFilter{CreatedFromMs: now.Add(time.Hour).UnixMilli()}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, trades)
+
+	failed := false
+	execs, err := execRepo.ListFiltered(ctx, domain.ExecutionFilter{TradeID: "t1", Success: &failed}, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, execs, 1)
+	assert.Equal(t, "s2", execs[0].SlaveAccountID)
+
+	execs, err = execRepo.ListFiltered(ctx, domain.ExecutionFilter{SlaveAccountID: "s1", CreatedToMs: now.Add(-time.Hour).UnixMilli()}, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, execs)
+}
+
+func TestSQLiteNotificationDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestSQLiteFactory(t).NotificationDeadLetterRepository()
+
+	require.NoError(t, repo.Create(ctx, &domain.NotificationDeadLetter{ID: "d1", Rule: "rejections", URL: "http://hook", EventType: "risk_rejected", EventSeq: 7, Payload: `{"a":1}`, Attempts: 5, LastStatus: 503, LastError: "503 Service Unavailable", CreatedAtMs: 1000}))
+	require.NoError(t, repo.Create(ctx, &domain.NotificationDeadLetter{ID: "d2", Rule: "disconnects", URL: "http://hook", EventType: "agent_disconnected", Payload: `{}`, Attempts: 1, CreatedAtMs: 2000}))
+	assert.Error(t, repo.Create(ctx, &domain.NotificationDeadLetter{ID: "d1", Rule: "rejections", URL: "http://hook", EventType: "risk_rejected", Payload: `{}`, CreatedAtMs: 3000}))
+
+	letters, err := repo.List(ctx, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.Equal(t, "d2", letters[0].ID)
+	assert.Equal(t, uint64(7), letters[1].EventSeq)
+	assert.Equal(t, 503, letters[1].LastStatus)
+	assert.Equal(t, `{"a":1}`, letters[1].Payload)
+
+	letters, err = repo.List(ctx, 1, 1)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, "d1", letters[0].ID)
+}
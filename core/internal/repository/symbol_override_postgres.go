@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Overrides manuales de mapeo de símbolos por cuenta (i8).
+
+const symbolOverrideColumns = `account_id, canonical_symbol, broker_symbol, reason, updated_by, updated_at_ms`
+
+type postgresSymbolOverrideRepo struct {
+	db *sql.DB
+}
+
+// El trigger trg_symbol_override_changed (migración 0019) notifica
+// echo_symbol_mapping_updated al confirmar cada escritura.
+const postgresUpsertSymbolOverride = `
+	INSERT INTO echo.account_symbol_overrides (` + symbolOverrideColumns + `)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (account_id, canonical_symbol) DO UPDATE SET
+		broker_symbol = EXCLUDED.broker_symbol,
+		reason = EXCLUDED.reason,
+		updated_by = EXCLUDED.updated_by,
+		updated_at_ms = EXCLUDED.updated_at_ms
+`
+
+func (r *postgresSymbolOverrideRepo) Upsert(ctx context.Context, override *domain.SymbolMappingOverride) error {
+	return upsertSymbolOverride(ctx, r.db, postgresUpsertSymbolOverride, override)
+}
+
+func (r *postgresSymbolOverrideRepo) Pin(ctx context.Context, override *domain.SymbolMappingOverride, mapping *domain.SymbolMapping) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := upsertSymbolOverride(ctx, tx, postgresUpsertSymbolOverride, override); err != nil {
+		return err
+	}
+	if err := upsertPostgresSymbolMappings(ctx, tx, override.AccountID, []*domain.SymbolMapping{mapping}, override.UpdatedAtMs); err != nil {
+		return fmt.Errorf("failed to persist pinned mapping: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresSymbolOverrideRepo) Delete(ctx context.Context, accountID, canonicalSymbol string) (bool, error) {
+	return deleteSymbolOverride(ctx, r.db,
+		`DELETE FROM echo.account_symbol_overrides WHERE account_id = $1 AND canonical_symbol = $2`,
+		accountID, canonicalSymbol,
+	)
+}
+
+func (r *postgresSymbolOverrideRepo) ListByAccount(ctx context.Context, accountID string) ([]*domain.SymbolMappingOverride, error) {
+	return listSymbolOverrides(ctx, r.db,
+		`SELECT `+symbolOverrideColumns+` FROM echo.account_symbol_overrides WHERE account_id = $1 ORDER BY canonical_symbol ASC`,
+		accountID,
+	)
+}
+
+// upsertSymbolOverride es común a PostgreSQL y SQLite.
+func upsertSymbolOverride(ctx context.Context, conn sqlConn, query string, override *domain.SymbolMappingOverride) error {
+	if _, err := conn.ExecContext(ctx, query,
+		override.AccountID,
+		override.CanonicalSymbol,
+		override.BrokerSymbol,
+		override.Reason,
+		override.UpdatedBy,
+		override.UpdatedAtMs,
+	); err != nil {
+		return fmt.Errorf("failed to upsert symbol override %s/%s: %w", override.AccountID, override.CanonicalSymbol, err)
+	}
+	return nil
+}
+
+// deleteSymbolOverride es común a PostgreSQL y SQLite.
+func deleteSymbolOverride(ctx context.Context, db *sql.DB, query, accountID, canonicalSymbol string) (bool, error) {
+	result, err := db.ExecContext(ctx, query, accountID, canonicalSymbol)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete symbol override %s/%s: %w", accountID, canonicalSymbol, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to read deleted symbol overrides: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// listSymbolOverrides es común a PostgreSQL y SQLite.
+func listSymbolOverrides(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*domain.SymbolMappingOverride, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbol overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*domain.SymbolMappingOverride
+	for rows.Next() {
+		var override domain.SymbolMappingOverride
+		if err := rows.Scan(
+			&override.AccountID,
+			&override.CanonicalSymbol,
+			&override.BrokerSymbol,
+			&override.Reason,
+			&override.UpdatedBy,
+			&override.UpdatedAtMs,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol override: %w", err)
+		}
+		overrides = append(overrides, &override)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate symbol overrides: %w", err)
+	}
+	return overrides, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// sqliteSymbolOverrideRepo (i8)
+// ===========================================================================
+
+// Los triggers de la migración 0012 registran cada escritura en
+// account_symbol_override_changes (ver WatchSymbolOverrideChanges).
+type sqliteSymbolOverrideRepo struct {
+	db  *sql.DB
+	hub *changeHub
+}
+
+const sqliteUpsertSymbolOverride = `
+	INSERT INTO account_symbol_overrides (` + symbolOverrideColumns + `)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT (account_id, canonical_symbol) DO UPDATE SET
+		broker_symbol = excluded.broker_symbol,
+		reason = excluded.reason,
+		updated_by = excluded.updated_by,
+		updated_at_ms = excluded.updated_at_ms
+`
+
+func (r *sqliteSymbolOverrideRepo) Upsert(ctx context.Context, override *domain.SymbolMappingOverride) error {
+	if err := upsertSymbolOverride(ctx, r.db, sqliteUpsertSymbolOverride, override); err != nil {
+		return err
+	}
+	r.hub.symbolMappingChanged(override.AccountID)
+	return nil
+}
+
+func (r *sqliteSymbolOverrideRepo) Pin(ctx context.Context, override *domain.SymbolMappingOverride, mapping *domain.SymbolMapping) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := upsertSymbolOverride(ctx, tx, sqliteUpsertSymbolOverride, override); err != nil {
+		return err
+	}
+	if err := upsertSQLiteSymbolMappings(ctx, tx, override.AccountID, []*domain.SymbolMapping{mapping}, override.UpdatedAtMs); err != nil {
+		return fmt.Errorf("failed to persist pinned mapping: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	r.hub.symbolMappingChanged(override.AccountID)
+	return nil
+}
+
+func (r *sqliteSymbolOverrideRepo) Delete(ctx context.Context, accountID, canonicalSymbol string) (bool, error) {
+	deleted, err := deleteSymbolOverride(ctx, r.db,
+		`DELETE FROM account_symbol_overrides WHERE account_id = ? AND canonical_symbol = ?`,
+		accountID, canonicalSymbol,
+	)
+	if deleted {
+		r.hub.symbolMappingChanged(accountID)
+	}
+	return deleted, err
+}
+
+func (r *sqliteSymbolOverrideRepo) ListByAccount(ctx context.Context, accountID string) ([]*domain.SymbolMappingOverride, error) {
+	return listSymbolOverrides(ctx, r.db,
+		`SELECT `+symbolOverrideColumns+` FROM account_symbol_overrides WHERE account_id = ? ORDER BY canonical_symbol ASC`,
+		accountID,
+	)
+}
+
+// WatchSymbolOverrideChanges detecta overrides escritos por otros procesos sobre el
+// mismo archivo (echo-core-cli symbols pin/unpin) y los notifica vía
+// OnSymbolMappingChanged (i8).
+//
+// Reemplaza cross-proceso al canal echo_symbol_mapping_updated: cada interval lee las
+// filas nuevas de account_symbol_override_changes (rowid monótono, la escriben los
+// triggers de account_symbol_overrides). Las escrituras de este proceso también se
+// observan; invalidar dos veces es inocuo.
+func (f *SQLiteFactory) WatchSymbolOverrideChanges(ctx context.Context, interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("symbol override poll interval must be positive")
+	}
+
+	var lastRowID int64
+	if err := f.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(rowid), 0) FROM account_symbol_override_changes`).Scan(&lastRowID); err != nil {
+		return fmt.Errorf("failed to read symbol override changes watermark: %w", err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// Ante error se reintenta en el próximo tick desde el mismo watermark
+			if next, err := f.pollSymbolOverrideChanges(ctx, lastRowID); err == nil {
+				lastRowID = next
+			}
+		}
+	}()
+	return nil
+}
+
+// pollSymbolOverrideChanges notifica las cuentas con cambios posteriores a afterRowID
+// (una vez por cuenta) y retorna el nuevo watermark.
+func (f *SQLiteFactory) pollSymbolOverrideChanges(ctx context.Context, afterRowID int64) (int64, error) {
+	rows, err := f.db.QueryContext(ctx, `
+		SELECT rowid, account_id
+		FROM account_symbol_override_changes
+		WHERE rowid > ?
+		ORDER BY rowid ASC
+	`, afterRowID)
+	if err != nil {
+		return afterRowID, fmt.Errorf("failed to poll symbol override changes: %w", err)
+	}
+
+	var changed []string
+	seen := make(map[string]bool)
+	lastRowID := afterRowID
+	for rows.Next() {
+		var (
+			rowID     int64
+			accountID string
+		)
+		if err := rows.Scan(&rowID, &accountID); err != nil {
+			rows.Close()
+			return afterRowID, fmt.Errorf("failed to scan symbol override change: %w", err)
+		}
+		lastRowID = rowID
+		if !seen[accountID] {
+			seen[accountID] = true
+			changed = append(changed, accountID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return afterRowID, fmt.Errorf("rows error: %w", err)
+	}
+	rows.Close()
+
+	// Callbacks fuera del cursor: releen mapeos por la única conexión del pool
+	for _, accountID := range changed {
+		f.symbolMappingChanged(accountID)
+	}
+	return lastRowID, nil
+}
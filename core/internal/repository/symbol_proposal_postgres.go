@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Mapeos sugeridos por el matcher de símbolos del Core (i8).
+
+const symbolProposalColumns = `account_id, broker_symbol, canonical_symbol, confidence, rule, checks, proposed_at_ms`
+
+type postgresSymbolProposalRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresSymbolProposalRepo) ReplaceForAccount(ctx context.Context, accountID string, proposals []*domain.SymbolMatchProposal) error {
+	return replaceSymbolProposals(ctx, r.db,
+		`DELETE FROM echo.account_symbol_proposals WHERE account_id = $1`,
+		`INSERT INTO echo.account_symbol_proposals (`+symbolProposalColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		accountID, proposals,
+	)
+}
+
+func (r *postgresSymbolProposalRepo) ListByAccount(ctx context.Context, accountID string) ([]*domain.SymbolMatchProposal, error) {
+	return listSymbolProposals(ctx, r.db,
+		`SELECT `+symbolProposalColumns+` FROM echo.account_symbol_proposals WHERE account_id = $1 ORDER BY broker_symbol ASC`,
+		accountID,
+	)
+}
+
+// replaceSymbolProposals es común a PostgreSQL y SQLite (checks se guarda como JSON).
+func replaceSymbolProposals(ctx context.Context, db *sql.DB, deleteQuery, insertQuery, accountID string, proposals []*domain.SymbolMatchProposal) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, deleteQuery, accountID); err != nil {
+		return fmt.Errorf("failed to delete symbol proposals %s: %w", accountID, err)
+	}
+	for _, proposal := range proposals {
+		checks := proposal.Checks
+		if checks == nil {
+			checks = []string{}
+		}
+		payload, err := json.Marshal(checks)
+		if err != nil {
+			return fmt.Errorf("failed to marshal symbol proposal checks: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, insertQuery,
+			accountID,
+			proposal.BrokerSymbol,
+			proposal.CanonicalSymbol,
+			proposal.Confidence,
+			proposal.Rule,
+			string(payload),
+			proposal.ProposedAtMs,
+		); err != nil {
+			return fmt.Errorf("failed to insert symbol proposal %s/%s: %w", accountID, proposal.BrokerSymbol, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// listSymbolProposals es común a PostgreSQL y SQLite.
+func listSymbolProposals(ctx context.Context, db *sql.DB, query, accountID string) ([]*domain.SymbolMatchProposal, error) {
+	rows, err := db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query symbol proposals: %w", err)
+	}
+	defer rows.Close()
+
+	var proposals []*domain.SymbolMatchProposal
+	for rows.Next() {
+		var proposal domain.SymbolMatchProposal
+		var checks []byte
+		if err := rows.Scan(
+			&proposal.AccountID,
+			&proposal.BrokerSymbol,
+			&proposal.CanonicalSymbol,
+			&proposal.Confidence,
+			&proposal.Rule,
+			&checks,
+			&proposal.ProposedAtMs,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol proposal: %w", err)
+		}
+		if err := json.Unmarshal(checks, &proposal.Checks); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal symbol proposal checks: %w", err)
+		}
+		proposals = append(proposals, &proposal)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate symbol proposals: %w", err)
+	}
+	return proposals, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// sqliteSymbolProposalRepo (i8)
+// ===========================================================================
+
+type sqliteSymbolProposalRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteSymbolProposalRepo) ReplaceForAccount(ctx context.Context, accountID string, proposals []*domain.SymbolMatchProposal) error {
+	return replaceSymbolProposals(ctx, r.db,
+		`DELETE FROM account_symbol_proposals WHERE account_id = ?`,
+		`INSERT INTO account_symbol_proposals (`+symbolProposalColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		accountID, proposals,
+	)
+}
+
+func (r *sqliteSymbolProposalRepo) ListByAccount(ctx context.Context, accountID string) ([]*domain.SymbolMatchProposal, error) {
+	return listSymbolProposals(ctx, r.db,
+		`SELECT `+symbolProposalColumns+` FROM account_symbol_proposals WHERE account_id = ? ORDER BY broker_symbol ASC`,
+		accountID,
+	)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// postgresModifyRepo (i8)
+// ===========================================================================
+
+type postgresModifyRepo struct {
+	db *sql.DB
+}
+
+func (r *postgresModifyRepo) Create(ctx context.Context, modify *domain.Modify) error {
+	query := `
+		INSERT INTO echo.trade_modifies (
+			modify_id, trade_id, master_ticket, new_stop_loss, new_take_profit, modified_at_ms
+		) VALUES ($1, $2, $3, $4, $5, $6)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		modify.ModifyID,
+		modify.TradeID,
+		modify.MasterTicket,
+		modify.NewStopLoss,
+		modify.NewTakeProfit,
+		modify.ModifiedAtMs,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create modify: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresModifyRepo) GetByTradeID(ctx context.Context, tradeID string) ([]*domain.Modify, error) {
+	query := `
+		SELECT modify_id, trade_id, master_ticket, new_stop_loss, new_take_profit,
+		       modified_at_ms, created_at
+		FROM echo.trade_modifies
+		WHERE trade_id = $1
+		ORDER BY modified_at_ms ASC, created_at ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query modifies: %w", err)
+	}
+	defer rows.Close()
+
+	var modifies []*domain.Modify
+	for rows.Next() {
+		var modify domain.Modify
+		err := rows.Scan(
+			&modify.ModifyID,
+			&modify.TradeID,
+			&modify.MasterTicket,
+			&modify.NewStopLoss,
+			&modify.NewTakeProfit,
+			&modify.ModifiedAtMs,
+			&modify.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan modify: %w", err)
+		}
+		modifies = append(modifies, &modify)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return modifies, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// ===========================================================================
+// sqliteModifyRepo (i8)
+// ===========================================================================
+
+type sqliteModifyRepo struct {
+	db *sql.DB
+}
+
+func (r *sqliteModifyRepo) Create(ctx context.Context, modify *domain.Modify) error {
+	query := `
+		INSERT INTO trade_modifies (
+			modify_id, trade_id, master_ticket, new_stop_loss, new_take_profit, modified_at_ms, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.ExecContext(ctx, query,
+		modify.ModifyID,
+		modify.TradeID,
+		modify.MasterTicket,
+		modify.NewStopLoss,
+		modify.NewTakeProfit,
+		modify.ModifiedAtMs,
+		time.Now().UnixMilli(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create modify: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteModifyRepo) GetByTradeID(ctx context.Context, tradeID string) ([]*domain.Modify, error) {
+	query := `
+		SELECT modify_id, trade_id, master_ticket, new_stop_loss, new_take_profit,
+		       modified_at_ms, created_at
+		FROM trade_modifies
+		WHERE trade_id = ?
+		ORDER BY modified_at_ms ASC, rowid ASC
+	`
+	rows, err := r.db.QueryContext(ctx, query, tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query modifies: %w", err)
+	}
+	defer rows.Close()
+
+	var modifies []*domain.Modify
+	for rows.Next() {
+		var modify domain.Modify
+		var createdAt int64
+		err := rows.Scan(
+			&modify.ModifyID,
+			&modify.TradeID,
+			&modify.MasterTicket,
+			&modify.NewStopLoss,
+			&modify.NewTakeProfit,
+			&modify.ModifiedAtMs,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan modify: %w", err)
+		}
+		modify.CreatedAt = time.UnixMilli(createdAt)
+		modifies = append(modifies, &modify)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows error: %w", err)
+	}
+
+	return modifies, nil
+}
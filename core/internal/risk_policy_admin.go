@@ -0,0 +1,530 @@
+package internal
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	"gopkg.in/yaml.v3"
+)
+
+// Administración de políticas de riesgo para echo-core-cli policy (i8).
+//
+// Toda escritura agrega una revisión al historial append-only (ninguna versión se
+// edita ni se borra) y Core invalida su caché de inmediato: en PostgreSQL el trigger
+// trg_risk_policy_history_changed emite echo_risk_policy_updated en la misma
+// transacción; en SQLite Core lo detecta con WatchRiskPolicyHistory.
+
+// RiskPolicyFormat formato de import/export de políticas.
+type RiskPolicyFormat string
+
+const (
+	RiskPolicyFormatYAML RiskPolicyFormat = "yaml"
+	RiskPolicyFormatCSV  RiskPolicyFormat = "csv"
+)
+
+// ParseRiskPolicyFormat resuelve el formato explícito o, si value está vacío, por la
+// extensión de path (.yaml/.yml/.csv).
+func ParseRiskPolicyFormat(value, path string) (RiskPolicyFormat, error) {
+	if value == "" {
+		value = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yaml", "yml":
+		return RiskPolicyFormatYAML, nil
+	case "csv":
+		return RiskPolicyFormatCSV, nil
+	case "":
+		return "", fmt.Errorf("format is required when it cannot be inferred from the file extension")
+	default:
+		return "", fmt.Errorf("unsupported format %q (use yaml or csv)", value)
+	}
+}
+
+// RiskPolicyRecord representación plana de una política para import/export y salida JSON.
+//
+// Version solo se completa al exportar; al importar se ignora (el historial asigna la
+// siguiente). EffectiveFrom pasado o vacío aplica la revisión de inmediato; futuro la
+// deja programada.
+type RiskPolicyRecord struct {
+	AccountID        string     `json:"account_id" yaml:"account_id"`
+	StrategyID       string     `json:"strategy_id" yaml:"strategy_id"`
+	Type             string     `json:"type" yaml:"type"`
+	LotSize          *float64   `json:"lot_size,omitempty" yaml:"lot_size,omitempty"`
+	Amount           *float64   `json:"amount,omitempty" yaml:"amount,omitempty"`
+	Currency         string     `json:"currency,omitempty" yaml:"currency,omitempty"`
+	MinLotOverride   *float64   `json:"min_lot_override,omitempty" yaml:"min_lot_override,omitempty"`
+	MaxLotOverride   *float64   `json:"max_lot_override,omitempty" yaml:"max_lot_override,omitempty"`
+	CommissionPerLot *float64   `json:"commission_per_lot,omitempty" yaml:"commission_per_lot,omitempty"`
+	CommissionRate   *float64   `json:"commission_rate,omitempty" yaml:"commission_rate,omitempty"`
+	EffectiveFrom    *time.Time `json:"effective_from,omitempty" yaml:"effective_from,omitempty"`
+	ValidUntil       *time.Time `json:"valid_until,omitempty" yaml:"valid_until,omitempty"`
+	Version          int64      `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// NewRiskPolicyRecord convierte una política persistida a su representación plana.
+func NewRiskPolicyRecord(policy *domain.RiskPolicy) RiskPolicyRecord {
+	record := RiskPolicyRecord{
+		AccountID:  policy.AccountID,
+		StrategyID: policy.StrategyID,
+		Type:       string(policy.Type),
+		ValidUntil: policy.ValidUntil,
+		Version:    policy.Version,
+	}
+	if !policy.EffectiveFrom.IsZero() {
+		effectiveFrom := policy.EffectiveFrom.UTC()
+		record.EffectiveFrom = &effectiveFrom
+	}
+	if policy.FixedLot != nil {
+		lot := policy.FixedLot.LotSize
+		record.LotSize = &lot
+	}
+	if cfg := policy.FixedRisk; cfg != nil {
+		amount := cfg.Amount
+		record.Amount = &amount
+		record.Currency = cfg.Currency
+		record.MinLotOverride = cfg.MinLotOverride
+		record.MaxLotOverride = cfg.MaxLotOverride
+		record.CommissionPerLot = cfg.CommissionPerLot
+		record.CommissionRate = cfg.CommissionRate
+	}
+	return record
+}
+
+// Revision construye la revisión a agregar y la valida con domain.ValidateRiskPolicyRevision.
+func (r RiskPolicyRecord) Revision(author, reason string, now time.Time) (*domain.RiskPolicyRevision, error) {
+	policy := domain.RiskPolicy{
+		AccountID:  strings.TrimSpace(r.AccountID),
+		StrategyID: strings.TrimSpace(r.StrategyID),
+		Type:       domain.RiskPolicyType(strings.ToUpper(strings.TrimSpace(r.Type))),
+	}
+
+	hasFixedRisk := r.Amount != nil || r.Currency != "" || r.MinLotOverride != nil ||
+		r.MaxLotOverride != nil || r.CommissionPerLot != nil || r.CommissionRate != nil
+	switch policy.Type {
+	case domain.RiskPolicyTypeFixedLot:
+		if hasFixedRisk {
+			return nil, fmt.Errorf("%s/%s: FIXED_LOT only accepts lot_size", policy.AccountID, policy.StrategyID)
+		}
+		if r.LotSize != nil {
+			policy.FixedLot = &domain.FixedLotConfig{LotSize: *r.LotSize}
+		}
+	case domain.RiskPolicyTypeFixedRisk:
+		if r.LotSize != nil {
+			return nil, fmt.Errorf("%s/%s: FIXED_RISK does not accept lot_size", policy.AccountID, policy.StrategyID)
+		}
+		policy.FixedRisk = &domain.FixedRiskConfig{
+			Currency:         r.Currency,
+			MinLotOverride:   r.MinLotOverride,
+			MaxLotOverride:   r.MaxLotOverride,
+			CommissionPerLot: r.CommissionPerLot,
+			CommissionRate:   r.CommissionRate,
+		}
+		if r.Amount != nil {
+			policy.FixedRisk.Amount = *r.Amount
+		}
+	default:
+		return nil, fmt.Errorf("%s/%s: unsupported type %q (use FIXED_LOT or FIXED_RISK)", policy.AccountID, policy.StrategyID, r.Type)
+	}
+
+	// Una fecha pasada (ej: la de un export) no reescribe el orden del historial
+	if r.EffectiveFrom != nil && r.EffectiveFrom.After(now) {
+		policy.EffectiveFrom = *r.EffectiveFrom
+	}
+	if r.ValidUntil != nil {
+		if !r.ValidUntil.After(now) {
+			return nil, fmt.Errorf("%s/%s: valid_until must be in the future", policy.AccountID, policy.StrategyID)
+		}
+		validUntil := *r.ValidUntil
+		policy.ValidUntil = &validUntil
+	}
+
+	revision := &domain.RiskPolicyRevision{Policy: policy, Author: author, Reason: reason}
+	if err := domain.ValidateRiskPolicyRevision(revision); err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", policy.AccountID, policy.StrategyID, err)
+	}
+	return revision, nil
+}
+
+// riskPolicyDocument raíz del formato YAML.
+type riskPolicyDocument struct {
+	Policies []RiskPolicyRecord `yaml:"policies"`
+}
+
+// riskPolicyCSVHeader columnas del formato CSV (el orden al importar es libre).
+var riskPolicyCSVHeader = []string{
+	"account_id", "strategy_id", "type", "lot_size", "amount", "currency",
+	"min_lot_override", "max_lot_override", "commission_per_lot", "commission_rate",
+	"effective_from", "valid_until", "version",
+}
+
+// EncodeRiskPolicies escribe records en el formato indicado.
+func EncodeRiskPolicies(w io.Writer, format RiskPolicyFormat, records []RiskPolicyRecord) error {
+	switch format {
+	case RiskPolicyFormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(riskPolicyDocument{Policies: records}); err != nil {
+			return fmt.Errorf("failed to encode yaml: %w", err)
+		}
+		return encoder.Close()
+	case RiskPolicyFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(riskPolicyCSVHeader); err != nil {
+			return fmt.Errorf("failed to write csv: %w", err)
+		}
+		for _, record := range records {
+			row := []string{
+				record.AccountID, record.StrategyID, record.Type,
+				formatOptionalFloat(record.LotSize), formatOptionalFloat(record.Amount), record.Currency,
+				formatOptionalFloat(record.MinLotOverride), formatOptionalFloat(record.MaxLotOverride),
+				formatOptionalFloat(record.CommissionPerLot), formatOptionalFloat(record.CommissionRate),
+				formatOptionalTime(record.EffectiveFrom), formatOptionalTime(record.ValidUntil), "",
+			}
+			if record.Version > 0 {
+				row[12] = strconv.FormatInt(record.Version, 10)
+			}
+			if err := writer.Write(row); err != nil {
+				return fmt.Errorf("failed to write csv: %w", err)
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// DecodeRiskPolicies lee records en el formato indicado (sin validar contenido).
+func DecodeRiskPolicies(r io.Reader, format RiskPolicyFormat) ([]RiskPolicyRecord, error) {
+	switch format {
+	case RiskPolicyFormatYAML:
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		var document riskPolicyDocument
+		if err := decoder.Decode(&document); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to decode yaml: %w", err)
+		}
+		return document.Policies, nil
+	case RiskPolicyFormatCSV:
+		return decodeRiskPoliciesCSV(r)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+func decodeRiskPoliciesCSV(r io.Reader) ([]RiskPolicyRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+
+	known := make(map[string]bool, len(riskPolicyCSVHeader))
+	for _, column := range riskPolicyCSVHeader {
+		known[column] = true
+	}
+	for i, column := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(column))
+		if !known[header[i]] {
+			return nil, fmt.Errorf("unknown csv column %q", column)
+		}
+	}
+
+	var records []RiskPolicyRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read csv: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		var record RiskPolicyRecord
+		for i, value := range row {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if err := setRiskPolicyCSVField(&record, header[i], value); err != nil {
+				return nil, fmt.Errorf("csv line %d: %s: %w", line, header[i], err)
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+func setRiskPolicyCSVField(record *RiskPolicyRecord, column, value string) error {
+	switch column {
+	case "account_id":
+		record.AccountID = value
+	case "strategy_id":
+		record.StrategyID = value
+	case "type":
+		record.Type = value
+	case "currency":
+		record.Currency = value
+	case "effective_from", "valid_until":
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return err
+		}
+		if column == "effective_from" {
+			record.EffectiveFrom = &at
+		} else {
+			record.ValidUntil = &at
+		}
+	case "version":
+		version, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		record.Version = version
+	default:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		switch column {
+		case "lot_size":
+			record.LotSize = &number
+		case "amount":
+			record.Amount = &number
+		case "min_lot_override":
+			record.MinLotOverride = &number
+		case "max_lot_override":
+			record.MaxLotOverride = &number
+		case "commission_per_lot":
+			record.CommissionPerLot = &number
+		case "commission_rate":
+			record.CommissionRate = &number
+		}
+	}
+	return nil
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func formatOptionalTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
+
+// RiskPolicyApplyResult resultado de agregar una revisión.
+type RiskPolicyApplyResult struct {
+	AccountID  string `json:"account_id"`
+	StrategyID string `json:"strategy_id"`
+	// Version asignada; 0 si la revisión era idéntica a la vigente y se omitió.
+	Version   int64 `json:"version"`
+	Unchanged bool  `json:"unchanged"`
+}
+
+// ListRiskPolicies carga la configuración desde ETCD y retorna la política vigente de
+// cada par cuenta × estrategia (i8).
+//
+// Lo usa echo-core-cli policy sin inicializar Core.
+func ListRiskPolicies(ctx context.Context) ([]*domain.RiskPolicy, error) {
+	repo, closeRepo, err := openRiskPolicyRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepo()
+
+	policies, err := repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list risk policies: %w", err)
+	}
+	return policies, nil
+}
+
+// GetRiskPolicy retorna la política vigente (nil si no hay) y el historial completo
+// del par (i8).
+func GetRiskPolicy(ctx context.Context, accountID, strategyID string) (*domain.RiskPolicy, []*domain.RiskPolicyRevision, error) {
+	if accountID == "" || strategyID == "" {
+		return nil, nil, fmt.Errorf("account_id and strategy_id are required")
+	}
+
+	repo, closeRepo, err := openRiskPolicyRepository(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closeRepo()
+
+	policy, err := repo.Get(ctx, accountID, strategyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get risk policy: %w", err)
+	}
+	history, err := repo.ListHistory(ctx, accountID, strategyID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list risk policy history: %w", err)
+	}
+	return policy, history, nil
+}
+
+// ApplyRiskPolicies agrega una revisión por par (i8).
+//
+// Todas las revisiones se validan antes de abrir la base: un import con un solo
+// registro inválido no escribe nada. Las revisiones idénticas a la política vigente
+// se omiten, de modo que re-importar un export no crea versiones nuevas.
+func ApplyRiskPolicies(ctx context.Context, revisions []*domain.RiskPolicyRevision) ([]RiskPolicyApplyResult, error) {
+	if err := ValidateRiskPolicyRevisions(revisions); err != nil {
+		return nil, err
+	}
+
+	repo, closeRepo, err := openRiskPolicyRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepo()
+
+	results := make([]RiskPolicyApplyResult, 0, len(revisions))
+	for _, revision := range revisions {
+		policy := &revision.Policy
+		result := RiskPolicyApplyResult{AccountID: policy.AccountID, StrategyID: policy.StrategyID}
+
+		current, err := repo.Get(ctx, policy.AccountID, policy.StrategyID)
+		if err != nil {
+			return results, fmt.Errorf("failed to get risk policy %s/%s: %w", policy.AccountID, policy.StrategyID, err)
+		}
+		if policy.EffectiveFrom.IsZero() && policy.ValidUntil == nil && current != nil && current.ValidUntil == nil && sameRiskPolicyConfig(current, policy) {
+			result.Unchanged = true
+			results = append(results, result)
+			continue
+		}
+
+		version, err := repo.AppendRevision(ctx, revision)
+		if err != nil {
+			return results, fmt.Errorf("failed to write risk policy %s/%s: %w", policy.AccountID, policy.StrategyID, err)
+		}
+		result.Version = version
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// ValidateRiskPolicyRevisions valida cada revisión con domain.ValidateRiskPolicyRevision
+// y rechaza pares repetidos.
+func ValidateRiskPolicyRevisions(revisions []*domain.RiskPolicyRevision) error {
+	seen := make(map[string]bool, len(revisions))
+	for _, revision := range revisions {
+		if err := domain.ValidateRiskPolicyRevision(revision); err != nil {
+			return err
+		}
+		key := policyCacheKey(revision.Policy.AccountID, revision.Policy.StrategyID)
+		if seen[key] {
+			return fmt.Errorf("duplicate risk policy for %s/%s", revision.Policy.AccountID, revision.Policy.StrategyID)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// DeleteRiskPolicy retira la política vigente del par agregando una revisión que
+// expira ahora (el historial es append-only) y retorna su versión (i8).
+//
+// Las revisiones programadas a futuro se mantienen y se activan en su effective_from.
+func DeleteRiskPolicy(ctx context.Context, accountID, strategyID, author, reason string) (int64, error) {
+	if accountID == "" || strategyID == "" {
+		return 0, fmt.Errorf("account_id and strategy_id are required")
+	}
+
+	repo, closeRepo, err := openRiskPolicyRepository(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer closeRepo()
+
+	current, err := repo.Get(ctx, accountID, strategyID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get risk policy: %w", err)
+	}
+	if current == nil {
+		return 0, fmt.Errorf("no effective risk policy for %s/%s", accountID, strategyID)
+	}
+
+	revision := newRiskPolicyRetirement(current, author, reason, time.Now())
+	if err := domain.ValidateRiskPolicyRevision(revision); err != nil {
+		return 0, err
+	}
+	version, err := repo.AppendRevision(ctx, revision)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retire risk policy: %w", err)
+	}
+	return version, nil
+}
+
+// newRiskPolicyRetirement copia la política vigente con valid_until = now.
+//
+// Conserva su effective_from: con mayor versión desplaza a la vigente en la resolución
+// (effective_from DESC, version DESC) y la deja expirada.
+func newRiskPolicyRetirement(current *domain.RiskPolicy, author, reason string, now time.Time) *domain.RiskPolicyRevision {
+	policy := *current
+	policy.Version = 0
+	policy.UpdatedAt = time.Time{}
+	policy.ValidUntil = &now
+	return &domain.RiskPolicyRevision{Policy: policy, Author: author, Reason: reason}
+}
+
+// sameRiskPolicyConfig compara tipo y parámetros de dimensionamiento.
+func sameRiskPolicyConfig(a, b *domain.RiskPolicy) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	switch a.Type {
+	case domain.RiskPolicyTypeFixedLot:
+		return a.FixedLot != nil && b.FixedLot != nil && a.FixedLot.LotSize == b.FixedLot.LotSize
+	case domain.RiskPolicyTypeFixedRisk:
+		x, y := a.FixedRisk, b.FixedRisk
+		return x != nil && y != nil &&
+			x.Amount == y.Amount &&
+			x.Currency == y.Currency &&
+			sameOptionalFloat(x.MinLotOverride, y.MinLotOverride) &&
+			sameOptionalFloat(x.MaxLotOverride, y.MaxLotOverride) &&
+			sameOptionalFloat(x.CommissionPerLot, y.CommissionPerLot) &&
+			sameOptionalFloat(x.CommissionRate, y.CommissionRate)
+	}
+	return false
+}
+
+func sameOptionalFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// openRiskPolicyRepository abre el repositorio de políticas del storage configurado.
+func openRiskPolicyRepository(ctx context.Context) (domain.RiskPolicyRepository, func(), error) {
+	config, err := LoadConfig(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config from ETCD: %w", err)
+	}
+	if config.StorageBackend == StorageBackendMemory {
+		return nil, nil, fmt.Errorf("storage/backend %q does not persist risk policies", config.StorageBackend)
+	}
+
+	db, factory, err := newRepositoryFactory(ctx, config, time.Now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return factory.RiskPolicyRepository(), func() { closeDB(db) }, nil
+}
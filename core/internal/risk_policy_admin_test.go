@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+func TestRiskPolicyRecordRevision(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	f := func(v float64) *float64 { return &v }
+	past := now.Add(-24 * time.Hour)
+	future := now.Add(time.Hour)
+
+	revision, err := RiskPolicyRecord{
+		AccountID: "acc", StrategyID: "s", Type: "fixed_risk",
+		Amount: f(100), Currency: "usd", MaxLotOverride: f(2), CommissionPerLot: f(7),
+		EffectiveFrom: &past,
+	}.Revision("cli:ops", "alta", now)
+	require.NoError(t, err)
+	assert.Equal(t, domain.RiskPolicyTypeFixedRisk, revision.Policy.Type)
+	assert.Equal(t, "USD", revision.Policy.FixedRisk.Currency, "normalizada por domain")
+	assert.True(t, revision.Policy.EffectiveFrom.IsZero(), "effective_from pasado aplica de inmediato")
+
+	revision, err = RiskPolicyRecord{AccountID: "acc", StrategyID: "s", Type: "FIXED_LOT", LotSize: f(0.1), EffectiveFrom: &future}.Revision("cli:ops", "", now)
+	require.NoError(t, err)
+	assert.Equal(t, future, revision.Policy.EffectiveFrom)
+
+	for _, invalid := range []RiskPolicyRecord{
+		{AccountID: "acc", StrategyID: "s", Type: "FIXED_LOT"},
+		{AccountID: "acc", StrategyID: "s", Type: "FIXED_LOT", LotSize: f(0.1), Amount: f(100)},
+		{AccountID: "acc", StrategyID: "s", Type: "FIXED_RISK", Amount: f(100), Currency: "USD", LotSize: f(0.1)},
+		{AccountID: "acc", StrategyID: "s", Type: "FIXED_RISK", Amount: f(100), Currency: "DOLLARS"},
+		{AccountID: "acc", StrategyID: "s", Type: "FIXED_RISK", Amount: f(100), Currency: "USD", MinLotOverride: f(2), MaxLotOverride: f(1)},
+		{AccountID: "acc", StrategyID: "s", Type: "MARTINGALE", LotSize: f(0.1)},
+		{AccountID: "", StrategyID: "s", Type: "FIXED_LOT", LotSize: f(0.1)},
+		{AccountID: "acc", StrategyID: "s", Type: "FIXED_LOT", LotSize: f(0.1), ValidUntil: &past},
+	} {
+		_, err := invalid.Revision("cli:ops", "", now)
+		assert.Error(t, err, "%+v", invalid)
+	}
+	_, err = RiskPolicyRecord{AccountID: "acc", StrategyID: "s", Type: "FIXED_LOT", LotSize: f(0.1)}.Revision("", "", now)
+	assert.Error(t, err, "author es requerido")
+
+	one, _ := RiskPolicyRecord{AccountID: "acc", StrategyID: "s", Type: "FIXED_LOT", LotSize: f(0.1)}.Revision("cli:ops", "", now)
+	two, _ := RiskPolicyRecord{AccountID: "acc", StrategyID: "s", Type: "FIXED_LOT", LotSize: f(0.2)}.Revision("cli:ops", "", now)
+	assert.Error(t, ValidateRiskPolicyRevisions([]*domain.RiskPolicyRevision{one, two}), "par duplicado")
+}
+
+func TestRiskPolicyRecordsRoundTrip(t *testing.T) {
+	effective := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	rate := 0.0005
+	policies := []*domain.RiskPolicy{
+		{AccountID: "acc", StrategyID: "lot", Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.25}, Version: 3, EffectiveFrom: effective},
+		{AccountID: "acc", StrategyID: "risk", Type: domain.RiskPolicyTypeFixedRisk, FixedRisk: &domain.FixedRiskConfig{Amount: 150, Currency: "EUR", CommissionRate: &rate}, Version: 1, EffectiveFrom: effective},
+	}
+	records := make([]RiskPolicyRecord, 0, len(policies))
+	for _, policy := range policies {
+		records = append(records, NewRiskPolicyRecord(policy))
+	}
+
+	for _, format := range []RiskPolicyFormat{RiskPolicyFormatYAML, RiskPolicyFormatCSV} {
+		var buf bytes.Buffer
+		require.NoError(t, EncodeRiskPolicies(&buf, format, records), format)
+		decoded, err := DecodeRiskPolicies(&buf, format)
+		require.NoError(t, err, format)
+		assert.Equal(t, records, decoded, format)
+	}
+
+	csvInput := "strategy_id,account_id,type,amount,currency\ns,acc,FIXED_RISK,100,USD\n"
+	decoded, err := DecodeRiskPolicies(strings.NewReader(csvInput), RiskPolicyFormatCSV)
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	assert.Equal(t, "acc", decoded[0].AccountID)
+	assert.Equal(t, 100.0, *decoded[0].Amount)
+	assert.Nil(t, decoded[0].LotSize)
+
+	_, err = DecodeRiskPolicies(strings.NewReader("account_id,leverage\nacc,100\n"), RiskPolicyFormatCSV)
+	assert.Error(t, err, "columna desconocida")
+	_, err = DecodeRiskPolicies(strings.NewReader("account_id,amount\nacc,cien\n"), RiskPolicyFormatCSV)
+	assert.Error(t, err)
+	_, err = DecodeRiskPolicies(strings.NewReader("policies:\n  - account_id: acc\n    leverage: 100\n"), RiskPolicyFormatYAML)
+	assert.Error(t, err, "campo YAML desconocido")
+
+	format, err := ParseRiskPolicyFormat("", "policies.YML")
+	require.NoError(t, err)
+	assert.Equal(t, RiskPolicyFormatYAML, format)
+	_, err = ParseRiskPolicyFormat("", "policies.txt")
+	assert.Error(t, err)
+}
+
+func TestRiskPolicyRetirementAndComparison(t *testing.T) {
+	now := time.Now()
+	current := &domain.RiskPolicy{
+		AccountID: "acc", StrategyID: "s", Type: domain.RiskPolicyTypeFixedLot,
+		FixedLot: &domain.FixedLotConfig{LotSize: 0.1}, Version: 4, EffectiveFrom: now.Add(-time.Hour),
+	}
+
+	retirement := newRiskPolicyRetirement(current, "cli:ops", "cuenta cerrada", now)
+	require.NoError(t, domain.ValidateRiskPolicyRevision(retirement))
+	assert.Equal(t, current.EffectiveFrom, retirement.Policy.EffectiveFrom)
+	require.NotNil(t, retirement.Policy.ValidUntil)
+	assert.False(t, retirement.IsEffectiveAt(now))
+	assert.Nil(t, current.ValidUntil, "no muta la política vigente")
+
+	same := &domain.RiskPolicy{Type: domain.RiskPolicyTypeFixedLot, FixedLot: &domain.FixedLotConfig{LotSize: 0.1}}
+	assert.True(t, sameRiskPolicyConfig(current, same))
+	same.FixedLot.LotSize = 0.2
+	assert.False(t, sameRiskPolicyConfig(current, same))
+
+	fee := 7.0
+	a := &domain.RiskPolicy{Type: domain.RiskPolicyTypeFixedRisk, FixedRisk: &domain.FixedRiskConfig{Amount: 100, Currency: "USD", CommissionPerLot: &fee}}
+	b := &domain.RiskPolicy{Type: domain.RiskPolicyTypeFixedRisk, FixedRisk: &domain.FixedRiskConfig{Amount: 100, Currency: "USD"}}
+	assert.False(t, sameRiskPolicyConfig(a, b))
+	b.FixedRisk.CommissionPerLot = &fee
+	assert.True(t, sameRiskPolicyConfig(a, b))
+}
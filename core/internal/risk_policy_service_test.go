@@ -25,6 +25,22 @@ func (s *stubRiskPolicyRepo) Get(ctx context.Context, accountID, strategyID stri
 	return s.policy, nil
 }
 
+func (s *stubRiskPolicyRepo) GetVersion(ctx context.Context, accountID, strategyID string, version int64) (*domain.RiskPolicyRevision, error) {
+	return nil, nil
+}
+
+func (s *stubRiskPolicyRepo) ListHistory(ctx context.Context, accountID, strategyID string) ([]*domain.RiskPolicyRevision, error) {
+	return nil, nil
+}
+
+func (s *stubRiskPolicyRepo) List(ctx context.Context) ([]*domain.RiskPolicy, error) {
+	return nil, nil
+}
+
+func (s *stubRiskPolicyRepo) AppendRevision(ctx context.Context, revision *domain.RiskPolicyRevision) (int64, error) {
+	return 0, nil
+}
+
 func TestRiskPolicyServiceCache(t *testing.T) {
 	repo := &stubRiskPolicyRepo{policy: &domain.RiskPolicy{
 		AccountID:  "acc",
@@ -0,0 +1,229 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xKoRx/echo/core/internal/riskengine"
+	"github.com/xKoRx/echo/core/internal/volumeguard"
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+// RiskSimulationRequest describe un escenario what-if de dimensionamiento (i7).
+type RiskSimulationRequest struct {
+	AccountID  string
+	StrategyID string
+	Symbol     string
+	Side       pb.OrderSide
+	Entry      float64
+	StopLoss   float64
+
+	// AccountCurrency fuerza la divisa de la cuenta cuando no hay StateSnapshot en memoria.
+	// Vacío usa la divisa de la política.
+	AccountCurrency string
+
+	// IgnoreStaleness desactiva los límites de edad de quotes y specs persistidos.
+	IgnoreStaleness bool
+}
+
+// RiskSimulationResult resume la decisión que tomaría el Core para el escenario.
+type RiskSimulationResult struct {
+	AccountID       string  `json:"account_id"`
+	StrategyID      string  `json:"strategy_id"`
+	CanonicalSymbol string  `json:"canonical_symbol"`
+	BrokerSymbol    string  `json:"broker_symbol,omitempty"`
+	Side            string  `json:"side"`
+	Entry           float64 `json:"entry"`
+	StopLoss        float64 `json:"stop_loss"`
+
+	PolicyType    string `json:"policy_type,omitempty"`
+	PolicyVersion int64  `json:"policy_version,omitempty"`
+
+	Decision     string  `json:"decision"`
+	Reason       string  `json:"reason"`
+	Error        string  `json:"error,omitempty"`
+	RequestedLot float64 `json:"requested_lot,omitempty"`
+	Lot          float64 `json:"lot"`
+	ExpectedLoss float64 `json:"expected_loss"`
+
+	CommissionFixedPerLot float64 `json:"commission_fixed_per_lot"`
+	CommissionRate        float64 `json:"commission_rate"`
+	CommissionPerLot      float64 `json:"commission_per_lot"`
+	CommissionTotal       float64 `json:"commission_total"`
+
+	VolumeGuardDecision string `json:"volume_guard_decision,omitempty"`
+
+	AccountCurrency       string `json:"account_currency,omitempty"`
+	AccountCurrencySource string `json:"account_currency_source,omitempty"` // snapshot | override | policy
+	QuoteAgeMs            int64  `json:"quote_age_ms,omitempty"`
+	SpecAgeMs             int64  `json:"spec_age_ms,omitempty"`
+}
+
+// SimulateRiskSizing ejecuta el dimensionamiento de lote con specs, quotes y política
+// persistidos, sin emitir órdenes ni registrar métricas.
+func (c *Core) SimulateRiskSizing(ctx context.Context, req RiskSimulationRequest) (*RiskSimulationResult, error) {
+	if req.AccountID == "" || req.StrategyID == "" || req.Symbol == "" {
+		return nil, fmt.Errorf("account, strategy y symbol son requeridos")
+	}
+	if req.Side != pb.OrderSide_ORDER_SIDE_BUY && req.Side != pb.OrderSide_ORDER_SIDE_SELL {
+		return nil, fmt.Errorf("side inválido: %s", req.Side.String())
+	}
+	if ctx == nil {
+		ctx = c.ctx
+	}
+
+	canonical, err := c.canonicalValidator.Normalize(req.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("normalizar símbolo: %w", err)
+	}
+
+	result := &RiskSimulationResult{
+		AccountID:       req.AccountID,
+		StrategyID:      req.StrategyID,
+		CanonicalSymbol: canonical,
+		Side:            orderSideToString(req.Side),
+		Entry:           req.Entry,
+		StopLoss:        req.StopLoss,
+		Decision:        string(riskengine.DecisionReject),
+	}
+
+	policy, err := c.riskPolicyService.Get(ctx, req.AccountID, req.StrategyID)
+	if err != nil {
+		return nil, fmt.Errorf("obtener política de riesgo: %w", err)
+	}
+	if policy == nil {
+		result.Reason = "policy_missing"
+		return result, nil
+	}
+	result.PolicyType = string(policy.Type)
+	result.PolicyVersion = policy.Version
+
+	// Hidratar cachés desde PostgreSQL (el CLI no recibe reportes de Agents)
+	c.symbolSpecService.WarmAccount(ctx, req.AccountID)
+	c.symbolQuoteService.Warm(ctx, req.AccountID, canonical)
+	if brokerSymbol, _, found := c.symbolResolver.ResolveForAccount(ctx, req.AccountID, canonical); found {
+		result.BrokerSymbol = brokerSymbol
+	}
+	if age, ok := c.symbolSpecService.SpecAge(req.AccountID, canonical); ok {
+		result.SpecAgeMs = age.Milliseconds()
+	}
+	if quote, ok := c.symbolQuoteService.Get(req.AccountID, canonical); ok {
+		result.QuoteAgeMs = time.Since(time.UnixMilli(quote.TimestampMs)).Milliseconds()
+	}
+
+	guardPolicy := c.config.VolumeGuard
+	engineCfg := newRiskEngineConfig(c.config)
+	if req.IgnoreStaleness {
+		engineCfg.MaxQuoteAge = 0
+		if guardPolicy != nil {
+			relaxed := *guardPolicy
+			relaxed.MaxSpecAge = 0
+			guardPolicy = &relaxed
+		}
+	}
+	// Sin telemetría ni métricas: la simulación no debe contaminar dashboards de producción
+	guard := volumeguard.New(c.symbolSpecService, guardPolicy, nil, nil)
+
+	switch policy.Type {
+	case domain.RiskPolicyTypeFixedRisk:
+		if policy.FixedRisk == nil {
+			result.Reason = "config_missing"
+			return result, nil
+		}
+
+		accounts := &simulatedAccountState{
+			base:     c.accountStateService,
+			override: strings.ToUpper(strings.TrimSpace(req.AccountCurrency)),
+			fallback: strings.ToUpper(strings.TrimSpace(policy.FixedRisk.Currency)),
+		}
+		engine := riskengine.NewFixedRiskEngine(c.symbolSpecService, c.symbolQuoteService, accounts, &symbolInfoAdapter{resolver: c.symbolResolver}, guard, engineCfg, nil, nil)
+
+		stop := req.StopLoss
+		intent := &pb.TradeIntent{
+			Symbol:     canonical,
+			Side:       req.Side,
+			Price:      req.Entry,
+			StopLoss:   &stop,
+			StrategyId: req.StrategyID,
+		}
+
+		riskResult, err := engine.ComputeLot(ctx, req.AccountID, req.StrategyID, canonical, intent, policy.FixedRisk)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.AccountCurrency, result.AccountCurrencySource = accounts.resolved(req.AccountID)
+		result.Decision = string(riskResult.Decision)
+		result.Reason = riskResult.Reason
+		result.Lot = riskResult.Lot
+		result.ExpectedLoss = riskResult.ExpectedLoss
+		result.CommissionFixedPerLot = riskResult.CommissionFixedPerLot
+		result.CommissionRate = riskResult.CommissionRate
+		result.CommissionPerLot = riskResult.CommissionPerLot
+		result.CommissionTotal = riskResult.CommissionTotal
+		result.VolumeGuardDecision = string(riskResult.GuardDecision)
+
+	case domain.RiskPolicyTypeFixedLot:
+		if policy.FixedLot == nil || policy.FixedLot.LotSize <= 0 {
+			result.Reason = "risk_policy_missing"
+			return result, nil
+		}
+
+		result.RequestedLot = policy.FixedLot.LotSize
+		lot, decision, err := guard.Execute(ctx, req.AccountID, canonical, req.StrategyID, policy.FixedLot.LotSize)
+		result.VolumeGuardDecision = string(decision)
+		if err != nil {
+			result.Error = err.Error()
+			result.Reason = "volume_guard_error"
+			return result, nil
+		}
+		if decision == volumeguard.DecisionReject {
+			result.Reason = "volume_guard_reject"
+			return result, nil
+		}
+		result.Decision = string(riskengine.DecisionProceed)
+		result.Reason = "ok"
+		result.Lot = lot
+
+	default:
+		result.Reason = "unsupported_type"
+	}
+
+	return result, nil
+}
+
+// simulatedAccountState completa la divisa de la cuenta cuando el CLI no dispone de StateSnapshot.
+type simulatedAccountState struct {
+	base     riskengine.AccountStateProvider
+	override string
+	fallback string
+}
+
+func (s *simulatedAccountState) Get(accountID string) (*pb.AccountInfo, bool) {
+	currency, source := s.resolved(accountID)
+	if source == "snapshot" && s.base != nil {
+		return s.base.Get(accountID)
+	}
+	if currency == "" {
+		return nil, false
+	}
+	return &pb.AccountInfo{AccountId: accountID, Currency: currency}, true
+}
+
+// resolved retorna la divisa efectiva y su origen (snapshot | override | policy).
+func (s *simulatedAccountState) resolved(accountID string) (string, string) {
+	if s.override != "" {
+		return s.override, "override"
+	}
+	if s.base != nil {
+		if info, ok := s.base.Get(accountID); ok && info != nil && info.Currency != "" {
+			return strings.ToUpper(info.Currency), "snapshot"
+		}
+	}
+	if s.fallback != "" {
+		return s.fallback, "policy"
+	}
+	return "", ""
+}
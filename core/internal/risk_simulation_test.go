@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/core/internal/riskengine"
+	"github.com/xKoRx/echo/core/internal/volumeguard"
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+func floatRef(v float64) *float64 { return &v }
+
+// newRiskSimulationCore arma un Core con cachés precargadas para XAUUSD en "acc":
+// digits 2, tick 0.01 con tick_value 1 y volumen [min, max] con step 0.01.
+func newRiskSimulationCore(t *testing.T, policy *domain.RiskPolicy, minVolume, maxVolume float64, quoteAge time.Duration) *Core {
+	t.Helper()
+
+	echoMetrics, err := metricbundle.NewEchoMetrics(noop.NewMeterProvider().Meter("test"))
+	require.NoError(t, err)
+	tel := &telemetry.Client{}
+	now := time.Now()
+
+	specs := NewSymbolSpecService(nil, tel, echoMetrics, []string{"XAUUSD"})
+	specs.specs["acc"] = map[string]*specCacheEntry{
+		"XAUUSD": {
+			spec: &pb.SymbolSpecification{
+				CanonicalSymbol: "XAUUSD",
+				General:         &pb.SymbolGeneral{Digits: 2, TickValue: 1, ContractSize: 100},
+				Volume:          &pb.VolumeSpec{MinVolume: minVolume, MaxVolume: maxVolume, VolumeStep: 0.01},
+			},
+			reportedAtMs: now.UnixMilli(),
+		},
+	}
+
+	quotes := NewSymbolQuoteService(nil, tel)
+	quoteMs := now.Add(-quoteAge).UnixMilli()
+	quotes.quotes["acc"] = map[string]*quoteCacheEntry{
+		"XAUUSD": {quote: &pb.SymbolQuoteSnapshot{Bid: 4000, Ask: 4000, TimestampMs: quoteMs}, timestampMs: quoteMs},
+	}
+
+	resolver := NewAccountSymbolResolver(context.Background(), nil, tel, echoMetrics, 1)
+	resolver.cache["acc"] = map[string]*domain.AccountSymbolInfo{
+		"XAUUSD": {CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.m", TickSize: 0.01},
+	}
+
+	return &Core{
+		config: &Config{
+			VolumeGuard: &domain.VolumeGuardPolicy{
+				OnMissingSpec: domain.VolumeGuardMissingSpecReject,
+				MaxSpecAge:    10 * time.Second,
+				DefaultLot:    0.1,
+			},
+			Risk: RiskConfig{Engine: FixedRiskEngineConfig{
+				QuoteMaxAge:              750 * time.Millisecond,
+				MinDistancePoints:        5,
+				MaxRiskDrift:             0.02,
+				DefaultCurrency:          "USD",
+				RejectOnMissingTickValue: true,
+			}},
+		},
+		canonicalValidator:  NewCanonicalValidator([]string{"XAUUSD"}, UnknownActionReject, nil, nil),
+		riskPolicyService:   &stubRiskPolicyService{policy: policy},
+		symbolSpecService:   specs,
+		symbolQuoteService:  quotes,
+		symbolResolver:      resolver,
+		accountStateService: NewAccountStateService(tel),
+		telemetry:           tel,
+		echoMetrics:         echoMetrics,
+		ctx:                 context.Background(),
+	}
+}
+
+func TestSimulateRiskSizing(t *testing.T) {
+	fixedLot := func(lot float64) *domain.RiskPolicy {
+		return &domain.RiskPolicy{AccountID: "acc", StrategyID: "s1", Type: domain.RiskPolicyTypeFixedLot, Version: 3, FixedLot: &domain.FixedLotConfig{LotSize: lot}}
+	}
+	fixedRisk := func(config domain.FixedRiskConfig) *domain.RiskPolicy {
+		config.Currency = "USD"
+		return &domain.RiskPolicy{AccountID: "acc", StrategyID: "s1", Type: domain.RiskPolicyTypeFixedRisk, Version: 4, FixedRisk: &config}
+	}
+
+	cases := []struct {
+		name       string
+		policy     *domain.RiskPolicy
+		minVolume  float64
+		maxVolume  float64
+		quoteAge   time.Duration
+		req        RiskSimulationRequest
+		decision   riskengine.Decision
+		reason     string
+		lot        float64
+		loss       float64
+		guard      volumeguard.Decision
+		currencyOf string
+	}{
+		{
+			name: "fixed lot dentro de límites", policy: fixedLot(0.3), minVolume: 0.01, maxVolume: 50,
+			decision: riskengine.DecisionProceed, reason: "ok", lot: 0.3, guard: volumeguard.DecisionPassThrough,
+		},
+		{
+			name: "fixed lot bajo el mínimo se ajusta a min_volume", policy: fixedLot(0.005), minVolume: 0.01, maxVolume: 50,
+			decision: riskengine.DecisionProceed, reason: "ok", lot: 0.01, guard: volumeguard.DecisionClamp,
+		},
+		{
+			name: "fixed lot sobre el máximo se ajusta a max_volume", policy: fixedLot(80), minVolume: 0.01, maxVolume: 50,
+			decision: riskengine.DecisionProceed, reason: "ok", lot: 50, guard: volumeguard.DecisionClamp,
+		},
+		{
+			name: "fixed risk 100 USD a 50.00 de distancia", policy: fixedRisk(domain.FixedRiskConfig{Amount: 100}), minVolume: 0.01, maxVolume: 50,
+			decision: riskengine.DecisionProceed, reason: "ok", lot: 0.02, loss: 100, guard: volumeguard.DecisionPassThrough, currencyOf: "policy",
+		},
+		{
+			name: "fixed risk recortado por max_volume", policy: fixedRisk(domain.FixedRiskConfig{Amount: 100}), minVolume: 0.01, maxVolume: 0.01,
+			decision: riskengine.DecisionProceed, reason: "ok", lot: 0.01, loss: 50, guard: volumeguard.DecisionClamp, currencyOf: "policy",
+		},
+		{
+			name: "fixed risk con min_lot_override", policy: fixedRisk(domain.FixedRiskConfig{Amount: 100, MinLotOverride: floatRef(0.05)}), minVolume: 0.01, maxVolume: 50,
+			decision: riskengine.DecisionProceed, reason: "ok", lot: 0.05, loss: 250, guard: volumeguard.DecisionPassThrough, currencyOf: "policy",
+		},
+		{
+			name: "fixed risk con max_lot_override", policy: fixedRisk(domain.FixedRiskConfig{Amount: 100, MaxLotOverride: floatRef(0.01)}), minVolume: 0.01, maxVolume: 50,
+			decision: riskengine.DecisionProceed, reason: "ok", lot: 0.01, loss: 50, guard: volumeguard.DecisionPassThrough, currencyOf: "policy",
+		},
+		{
+			name: "fixed risk con divisa de cuenta distinta", policy: fixedRisk(domain.FixedRiskConfig{Amount: 100}), minVolume: 0.01, maxVolume: 50,
+			req:      RiskSimulationRequest{AccountCurrency: "eur"},
+			decision: riskengine.DecisionReject, reason: "currency_mismatch", currencyOf: "override",
+		},
+		{
+			name: "fixed risk con quote vencida", policy: fixedRisk(domain.FixedRiskConfig{Amount: 100}), minVolume: 0.01, maxVolume: 50, quoteAge: time.Minute,
+			decision: riskengine.DecisionReject, reason: "quote_stale", currencyOf: "policy",
+		},
+		{
+			name: "fixed risk con quote vencida ignorando antigüedad", policy: fixedRisk(domain.FixedRiskConfig{Amount: 100}), minVolume: 0.01, maxVolume: 50, quoteAge: time.Minute,
+			req:      RiskSimulationRequest{IgnoreStaleness: true},
+			decision: riskengine.DecisionProceed, reason: "ok", lot: 0.02, loss: 100, guard: volumeguard.DecisionPassThrough, currencyOf: "policy",
+		},
+		{
+			name: "sin política", minVolume: 0.01, maxVolume: 50,
+			decision: riskengine.DecisionReject, reason: "policy_missing",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newRiskSimulationCore(t, tc.policy, tc.minVolume, tc.maxVolume, tc.quoteAge)
+
+			req := tc.req
+			req.AccountID = "acc"
+			req.StrategyID = "s1"
+			req.Symbol = "xauusd"
+			req.Side = pb.OrderSide_ORDER_SIDE_BUY
+			req.Entry = 4000
+			req.StopLoss = 3950
+
+			result, err := c.SimulateRiskSizing(context.Background(), req)
+			require.NoError(t, err)
+			assert.Equal(t, "XAUUSD", result.CanonicalSymbol)
+			assert.Equal(t, string(tc.decision), result.Decision)
+			assert.Equal(t, tc.reason, result.Reason)
+			assert.InDelta(t, tc.lot, result.Lot, 1e-9)
+			assert.InDelta(t, tc.loss, result.ExpectedLoss, 1e-6)
+			assert.Equal(t, string(tc.guard), result.VolumeGuardDecision)
+			if tc.policy == nil {
+				return
+			}
+			assert.Equal(t, "XAUUSD.m", result.BrokerSymbol)
+			assert.Equal(t, tc.policy.Version, result.PolicyVersion)
+			if tc.policy.Type == domain.RiskPolicyTypeFixedLot {
+				assert.Equal(t, tc.policy.FixedLot.LotSize, result.RequestedLot)
+				return
+			}
+			assert.Equal(t, tc.currencyOf, result.AccountCurrencySource)
+		})
+	}
+}
+
+func TestSimulateRiskSizingRejectsInvalidRequest(t *testing.T) {
+	c := newRiskSimulationCore(t, nil, 0.01, 50, 0)
+
+	_, err := c.SimulateRiskSizing(context.Background(), RiskSimulationRequest{AccountID: "acc", Symbol: "XAUUSD", Side: pb.OrderSide_ORDER_SIDE_BUY})
+	assert.Error(t, err, "strategy requerida")
+
+	_, err = c.SimulateRiskSizing(context.Background(), RiskSimulationRequest{AccountID: "acc", StrategyID: "s1", Symbol: "XAUUSD"})
+	assert.Error(t, err, "side requerido")
+}
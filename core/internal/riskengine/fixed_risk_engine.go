@@ -36,6 +36,9 @@ type Config struct {
 	DefaultCurrency          string
 	EnableCurrencyFallback   bool
 	RejectOnMissingTickValue bool
+
+	// Now mide la edad de las quotes; nil usa time.Now (el replay usa la hora del registro).
+	Now func() time.Time
 }
 
 // Result encapsula el resultado de un cálculo de riesgo fijo.
@@ -97,6 +100,13 @@ func NewFixedRiskEngine(specs SymbolSpecProvider, quotes QuoteProvider, accounts
 	}
 }
 
+func (e *FixedRiskEngine) now() time.Time {
+	if e.config.Now == nil {
+		return time.Now()
+	}
+	return e.config.Now()
+}
+
 // ComputeLot ejecuta el cálculo de riesgo fijo retornando el lote sugerido y métricas asociadas.
 func (e *FixedRiskEngine) ComputeLot(ctx context.Context, accountID, strategyID, canonicalSymbol string, intent *pb.TradeIntent, policy *domain.FixedRiskConfig) (Result, error) {
 	result := Result{Decision: DecisionReject, Reason: "unknown"}
@@ -252,7 +262,7 @@ func (e *FixedRiskEngine) ComputeLot(ctx context.Context, accountID, strategyID,
 	}
 
 	if e.config.MaxQuoteAge > 0 {
-		age := time.Since(time.UnixMilli(quote.TimestampMs))
+		age := e.now().Sub(time.UnixMilli(quote.TimestampMs))
 		if age > e.config.MaxQuoteAge {
 			result.Reason = "quote_stale"
 			err := fmt.Errorf("quote age %s exceeds max quote age %s", age, e.config.MaxQuoteAge)
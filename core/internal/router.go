@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,7 +16,6 @@ import (
 	pb "github.com/xKoRx/echo/sdk/pb/v1"
 	"github.com/xKoRx/echo/sdk/telemetry"
 	"github.com/xKoRx/echo/sdk/telemetry/semconv"
-	"github.com/xKoRx/echo/sdk/utils"
 	"go.opentelemetry.io/otel/attribute"
 	"google.golang.org/protobuf/proto"
 )
@@ -62,6 +62,22 @@ type CommandContext struct {
 	SlaveAccountID string
 	CommandType    string // "execute_order" | "close_order"
 	CreatedAtMs    int64
+
+	// i7: Política de riesgo que dimensionó el ExecuteOrder (auditoría por ejecución)
+	StrategyID        string
+	RiskPolicyType    domain.RiskPolicyType
+	RiskPolicyVersion int64
+
+	// i8: Símbolo canónico del trade (filtros de WatchEvents; vacío si se reconstruyó del outbox)
+	Symbol string
+
+	// i8: Precio del master y point del slave para calcular slippage (0 si no se conocen)
+	ReferencePrice float64
+	Point          float64
+
+	// i8: Timestamps t0..t3 del ExecuteOrder; solo en contextos recuperados del outbox,
+	// para completar resultados de un Agent que no los conserva tras el reinicio
+	MasterTimestamps *pb.TimestampMetadata
 }
 
 // routerMessage mensaje interno del router.
@@ -163,6 +179,14 @@ func (r *Router) processMessage(msg *routerMessage) {
 		// i1: ExecutionResult puede venir de execute_order o close_order
 		// Determinamos el tipo por el command_id (buscando en el índice)
 		cmdCtx := r.getCommandContext(payload.ExecutionResult.CommandId)
+		if cmdCtx == nil {
+			// i8: tras un reinicio el índice está vacío; el outbox conserva la cuenta destino
+			cmdCtx = r.recoverCommandContext(msg.ctx, payload.ExecutionResult.CommandId)
+		}
+		// i8: Solo el Agent de la cuenta destino puede reportar el resultado del comando
+		if cmdCtx != nil && !r.authorizeAccountReport(msg.ctx, msg.agentID, cmdCtx.SlaveAccountID, payload) {
+			return
+		}
 		if cmdCtx != nil && cmdCtx.CommandType == "close_order" {
 			r.handleCloseResult(msg.ctx, msg.agentID, payload.ExecutionResult)
 		} else {
@@ -172,6 +196,9 @@ func (r *Router) processMessage(msg *routerMessage) {
 	case *pb.AgentMessage_TradeClose:
 		r.handleTradeClose(msg.ctx, msg.agentID, payload.TradeClose)
 
+	case *pb.AgentMessage_TradeModify:
+		r.handleTradeModify(msg.ctx, msg.agentID, payload.TradeModify)
+
 	case *pb.AgentMessage_StateSnapshot:
 		r.handleStateSnapshot(msg.ctx, msg.agentID, payload.StateSnapshot)
 
@@ -188,9 +215,9 @@ func (r *Router) processMessage(msg *routerMessage) {
 //  1. Agregar timestamp t2
 //  2. Validar símbolo (whitelist)
 //  3. Dedupe (rechazar duplicados)
-//  4. Transformar → ExecuteOrder (lot size hardcoded)
-//  5. Broadcast a todos los Agents (i0: sin routing inteligente)
-//  6. Actualizar dedupe status
+//  4. Transformar → ExecuteOrder
+//  5. Persistir trade + dedupe + ExecuteOrders en el outbox (una transacción, i8)
+//  6. Entrega inmediata al Agent owner; lo no entregado queda para el outbox dispatcher
 //  7. Métricas
 func (r *Router) handleTradeIntent(ctx context.Context, agentID string, intent *pb.TradeIntent) {
 	// i1: Normalizar trade_id a minúsculas DIRECTAMENTE en el protobuf (Master EA envía en mayúsculas)
@@ -199,7 +226,7 @@ func (r *Router) handleTradeIntent(ctx context.Context, agentID string, intent *
 
 	// Issue #M2: Agregar timestamp t2 (Core recibe)
 	if intent.Timestamps != nil {
-		intent.Timestamps.T2CoreRecvMs = utils.NowUnixMilli()
+		intent.Timestamps.T2CoreRecvMs = r.core.nowMs()
 	}
 
 	strategyID := intent.GetStrategyId()
@@ -225,6 +252,7 @@ func (r *Router) handleTradeIntent(ctx context.Context, agentID string, intent *
 		attribute.Int("ticket", int(intent.Ticket)),
 		attribute.String("strategy_id", strategyID),
 	)
+	r.publishIntentReceived(agentID, strategyID, intent)
 
 	// 2. Validar símbolo canónico (i3)
 	if err := r.core.canonicalValidator.Validate(ctx, intent.Symbol); err != nil {
@@ -233,22 +261,30 @@ func (r *Router) handleTradeIntent(ctx context.Context, agentID string, intent *
 		return
 	}
 
-	// 3. Dedupe persistente (i1)
-	if err := r.core.dedupeService.Add(ctx, tradeID, domain.OrderStatusPending); err != nil {
-		if dedupeErr, ok := err.(*DedupeError); ok {
-			r.core.telemetry.Warn(ctx, "Duplicate TradeIntent rejected (i1)",
-				attribute.String("existing_status", dedupeErr.ExistingStatus.String()),
-			)
-			return
-		}
+	// 2a. Copia deshabilitada para el master (i8, AdminService)
+	if !r.core.copyControl.Enabled(intent.ClientId) {
+		r.core.telemetry.Warn(ctx, "TradeIntent not copied: copy disabled for master account (i8)",
+			attribute.String("account_id", intent.ClientId),
+		)
+		return
+	}
 
+	// 3. Dedupe persistente (i1). i8: el upsert se escribe junto al outbox
+	exists, existingStatus, err := r.core.dedupeService.Check(ctx, tradeID)
+	if err != nil {
 		r.core.telemetry.Error(ctx, "Dedupe check failed (i1)", err,
 			attribute.String("error", err.Error()),
 		)
 		return
 	}
+	if exists && !existingStatus.IsTerminal() {
+		r.core.telemetry.Warn(ctx, "Duplicate TradeIntent rejected (i1)",
+			attribute.String("existing_status", string(existingStatus)),
+		)
+		return
+	}
 
-	// 3a. Persistir trade en BD (i1)
+	// 3a. Trade a persistir (i1). i8: se escribe en la transacción del outbox
 	attempt := int32(0)
 	if intent.Attempt != nil {
 		attempt = *intent.Attempt
@@ -272,17 +308,6 @@ func (r *Router) handleTradeIntent(ctx context.Context, agentID string, intent *
 		OpenedAtMs:      intent.TimestampMs,
 	}
 
-	if err := r.core.repoFactory.TradeRepository().Create(ctx, trade); err != nil {
-		r.core.telemetry.Error(ctx, "Failed to persist trade (i1)", err,
-			attribute.String("error", err.Error()),
-		)
-		// Continuar aunque falle persistencia (no es bloqueante en i1)
-	} else {
-		r.core.telemetry.Info(ctx, "Trade persisted successfully (i1)",
-			attribute.String("trade_id", tradeID),
-		)
-	}
-
 	// 4. Transformar TradeIntent → ExecuteOrder (usando SDK)
 	// i1: Pasar tradeID normalizado a createExecuteOrders
 	orders := r.createExecuteOrders(ctx, intent, tradeID, strategyID)
@@ -292,71 +317,104 @@ func (r *Router) handleTradeIntent(ctx context.Context, agentID string, intent *
 		attribute.String("trade_id", tradeID),
 	)
 
-	// 5. Routing selectivo (i2) en lugar de broadcast
+	// 5. Outbox transaccional (i8): si Core cae antes de entregar, el dispatcher reintenta
+	dedupeStatus := domain.OrderStatusPending
+	if len(orders) == 0 {
+		dedupeStatus = domain.OrderStatusRejected
+	}
+	outboxed := false
+	commands, err := r.newOutboxCommands(orders, r.core.nowMs())
+	if err == nil {
+		err = r.core.repoFactory.OutboxRepository().EnqueueTrade(ctx, trade,
+			&domain.DedupeEntry{TradeID: tradeID, Status: dedupeStatus}, commands)
+	}
+	if err != nil {
+		r.core.telemetry.Error(ctx, "Failed to persist trade outbox (i8)", err,
+			attribute.String("error", err.Error()),
+		)
+		// Sin outbox: trade y dedupe best-effort y entrega directa (comportamiento previo a i8)
+		if err := r.core.repoFactory.TradeRepository().Create(ctx, trade); err != nil {
+			r.core.telemetry.Error(ctx, "Failed to persist trade (i1)", err,
+				attribute.String("error", err.Error()),
+			)
+		}
+		if err := r.core.dedupeService.UpdateStatus(ctx, tradeID, dedupeStatus); err != nil {
+			r.core.telemetry.Error(ctx, "Failed to update dedupe status (i8)", err,
+				attribute.String("trade_id", tradeID),
+			)
+		}
+	} else {
+		outboxed = true
+		r.core.telemetry.Info(ctx, "Trade persisted with command outbox (i8)",
+			attribute.String("trade_id", tradeID),
+			attribute.Int("num_commands", len(commands)),
+		)
+	}
+
+	// 6. Routing selectivo (i2). i8: sin owner conectado el comando queda en el outbox.
+	// Las filas PENDING son del dispatcher; solo se entregan aquí las insertadas DISPATCHED,
+	// así un ciclo del dispatcher entre el commit y el envío no las duplica
 	sentCount := 0
 	broadcastCount := 0
 	selectiveCount := 0
+	pendingCount := 0
 
-	for _, order := range orders {
-		// Issue #M2: Agregar timestamp t3 (Core envía)
-		if order.Timestamps != nil {
-			order.Timestamps.T3CoreSendMs = utils.NowUnixMilli()
+	for i, order := range orders {
+		if outboxed && commands[i].Status != domain.OutboxStatusDispatched {
+			pendingCount++
+			r.recordRoutingMetric(ctx, "outbox_pending", false, order)
+			r.publishOrderDispatched("", "outbox_pending", false, order)
+			continue
 		}
 
-		msg := &pb.CoreMessage{
-			Payload: &pb.CoreMessage_ExecuteOrder{ExecuteOrder: order},
+		ownerAgentID, sent := r.deliverExecuteOrder(ctx, order)
+		if sent {
+			sentCount++
+			selectiveCount++
+			r.recordRoutingMetric(ctx, "selective", true, order)
+			r.publishOrderDispatched(ownerAgentID, "selective", true, order)
+			// El owner cambió entre el commit y el envío: registrar quién lo recibió
+			if outboxed && ownerAgentID != commands[i].LastAgentID {
+				if err := r.core.repoFactory.OutboxRepository().MarkDispatched(ctx, order.CommandId, ownerAgentID, r.core.nowMs()); err != nil {
+					r.core.telemetry.Warn(ctx, "Failed to mark outbox command dispatched (i8)",
+						attribute.String("command_id", order.CommandId),
+						attribute.String("error", err.Error()),
+					)
+				}
+			}
+			continue
 		}
 
-		// i2: Lookup owner en registry
-		targetAccountID := order.TargetAccountId
-		ownerAgentID, found := r.core.accountRegistry.GetOwner(targetAccountID)
-
-		// Registrar métrica de lookup
-		if found {
-			r.core.echoMetrics.RecordAccountLookup(ctx, "hit",
-				attribute.String("target_account_id", targetAccountID),
-			)
-		} else {
-			r.core.echoMetrics.RecordAccountLookup(ctx, "miss",
-				attribute.String("target_account_id", targetAccountID),
-			)
+		if outboxed {
+			// Sigue DISPATCHED: el dispatcher lo reentrega tras RedeliverAfter
+			pendingCount++
+			r.recordRoutingMetric(ctx, "outbox_pending", false, order)
+			r.publishOrderDispatched("", "outbox_pending", false, order)
+			continue
 		}
 
-		if found {
-			// Routing selectivo
-			agent, agentExists := r.getAgent(ownerAgentID)
-			if agentExists {
-				if r.sendToAgent(ctx, agent, msg, order) {
-					sentCount++
-					selectiveCount++
-					r.recordRoutingMetric(ctx, "selective", true, order)
-				}
-			} else {
-				// Owner registrado pero desconectado → fallback broadcast
-				r.core.telemetry.Warn(ctx, "Owner agent not connected, falling back to broadcast (i2)",
-					attribute.String("target_account_id", targetAccountID),
-					attribute.String("owner_agent_id", ownerAgentID),
-				)
-				if r.broadcastOrder(ctx, msg, order) > 0 {
-					sentCount++
-					broadcastCount++
-					r.recordRoutingMetric(ctx, "fallback_broadcast", false, order)
-				}
-			}
-		} else {
-			// No hay owner registrado → fallback broadcast
-			r.core.telemetry.Warn(ctx, "No owner registered for account, falling back to broadcast (i2)",
-				attribute.String("target_account_id", targetAccountID),
-			)
-			if r.broadcastOrder(ctx, msg, order) > 0 {
-				sentCount++
-				broadcastCount++
-				r.recordRoutingMetric(ctx, "fallback_broadcast", false, order)
-			}
+		// Sin outbox no hay reintento: fallback broadcast (i2)
+		if order.Timestamps != nil {
+			order.Timestamps.T3CoreSendMs = r.core.nowMs()
+		}
+		msg := &pb.CoreMessage{
+			Payload: &pb.CoreMessage_ExecuteOrder{ExecuteOrder: order},
+		}
+		if r.broadcastOrder(ctx, msg, order) > 0 {
+			sentCount++
+			broadcastCount++
+			r.recordRoutingMetric(ctx, "fallback_broadcast", false, order)
+			r.publishOrderDispatched("", "fallback_broadcast", true, order)
 		}
 	}
 
-	// 6. Métricas
+	if pendingCount > 0 {
+		r.core.telemetry.Info(ctx, "ExecuteOrders kept in outbox until owner connects (i8)",
+			attribute.Int("pending_count", pendingCount),
+		)
+	}
+
+	// 7. Métricas
 	r.core.echoMetrics.RecordOrderCreated(ctx,
 		semconv.Echo.TradeID.String(tradeID),
 		semconv.Echo.Symbol.String(intent.Symbol),
@@ -387,6 +445,14 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 	canonicalSymbol := intent.Symbol
 
 	for _, slaveAccountID := range r.core.config.SlaveAccounts {
+		if !r.core.copyControl.Enabled(slaveAccountID) {
+			r.core.telemetry.Warn(ctx, "Skipping account: copy disabled (i8)",
+				attribute.String("account_id", slaveAccountID),
+				attribute.String("trade_id", tradeID),
+			)
+			continue
+		}
+
 		handshakeStatus := r.core.handshakeRegistry.Status(slaveAccountID)
 		if handshakeStatus == handshake.RegistrationStatusRejected || handshakeStatus == handshake.RegistrationStatusUnspecified {
 			r.core.telemetry.Warn(ctx, "Skipping account due to handshake status",
@@ -429,9 +495,12 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 			r.core.echoMetrics.RecordRiskPolicyRejected(ctx, "missing",
 				policyAttrs...,
 			)
+			r.publishRiskRejected(intent, slaveAccountID, strategyID, "missing", "")
 			continue
 		}
 
+		policyAttrs = append(policyAttrs, attribute.Int64("policy_version", policy.Version))
+
 		ctxPolicy := telemetry.AppendEventAttrs(ctx, semconv.Echo.PolicyType.String(string(policy.Type)))
 		ctxPolicy = telemetry.AppendMetricAttrs(ctxPolicy, semconv.Echo.PolicyType.String(string(policy.Type)))
 
@@ -452,6 +521,7 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 					attribute.String("trade_id", tradeID),
 				)
 				r.core.echoMetrics.RecordRiskPolicyRejected(ctxPolicy, "config_missing", policyAttrs...)
+				r.publishRiskRejected(intent, slaveAccountID, strategyID, "config_missing", policy.Type)
 				continue
 			}
 
@@ -486,6 +556,7 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 					attribute.String("trade_id", tradeID),
 				)
 				r.core.echoMetrics.RecordRiskPolicyRejected(ctxPolicy, "engine_not_available", policyAttrs...)
+				r.publishRiskRejected(intent, slaveAccountID, strategyID, "engine_not_available", policy.Type)
 				continue
 			}
 
@@ -504,6 +575,7 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 					attribute.String("trade_id", tradeID),
 					attribute.String("error", err.Error()),
 				)
+				r.publishRiskRejected(intent, slaveAccountID, strategyID, "calculation_failed", policy.Type)
 				continue
 			}
 			r.core.telemetry.Info(ctxPolicy, "Fixed risk engine decision",
@@ -528,6 +600,7 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 					attribute.String("reason", riskResult.Reason),
 				)
 				r.core.echoMetrics.RecordRiskPolicyRejected(ctxPolicy, riskResult.Reason, policyAttrs...)
+				r.publishRiskRejected(intent, slaveAccountID, strategyID, riskResult.Reason, policy.Type)
 				continue
 			}
 
@@ -567,6 +640,7 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 				r.core.echoMetrics.RecordVolumeGuardDecision(ctxPolicy, string(volumeguard.DecisionReject),
 					append(policyAttrs, attribute.String("reason", "risk_policy_missing"))...,
 				)
+				r.publishRiskRejected(intent, slaveAccountID, strategyID, "risk_policy_missing", policy.Type)
 				continue
 			}
 
@@ -604,9 +678,15 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 						attribute.String("canonical_symbol", canonicalSymbol),
 					)
 				}
+				reason := "volume_guard_error"
+				if decision == volumeguard.DecisionReject {
+					reason = "volume_guard_reject"
+				}
+				r.publishRiskRejected(intent, slaveAccountID, strategyID, reason, policy.Type)
 				continue
 			}
 			if decision == volumeguard.DecisionReject {
+				r.publishRiskRejected(intent, slaveAccountID, strategyID, "volume_guard_reject", policy.Type)
 				continue
 			}
 			r.core.echoMetrics.RecordVolumeGuardDecision(ctxPolicy, string(decision),
@@ -619,10 +699,11 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 				attribute.String("policy_type", string(policy.Type)),
 			)
 			r.core.echoMetrics.RecordRiskPolicyRejected(ctxPolicy, "unsupported_type", policyAttrs...)
+			r.publishRiskRejected(intent, slaveAccountID, strategyID, "unsupported_type", policy.Type)
 			continue
 		}
 
-		commandID := utils.GenerateUUIDv7()
+		commandID := r.core.newID()
 		if r.isCommandIDDuplicate(commandID) {
 			r.core.telemetry.Warn(ctxPolicy, "Duplicate command_id detected, skipping",
 				attribute.String("command_id", commandID),
@@ -633,6 +714,8 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 
 		r.registerCommandID(commandID)
 		r.registerCommandContext(commandID, tradeID, slaveAccountID, "execute_order")
+		r.attachRiskPolicy(commandID, strategyID, policy)
+		r.attachSymbol(commandID, canonicalSymbol)
 
 		opts := &domain.TransformOptions{
 			LotSize:   lotSize,
@@ -660,6 +743,9 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 			)
 		} else {
 			order.Symbol = brokerSymbol
+			if info != nil {
+				r.attachSlippageReference(commandID, intent.Price, info.Point)
+			}
 			r.core.telemetry.Debug(ctxForOrder, "Symbol mapping applied (i3)",
 				attribute.String("account_id", slaveAccountID),
 				attribute.String("canonical", canonicalSymbol),
@@ -734,15 +820,11 @@ func (r *Router) createExecuteOrders(ctx context.Context, intent *pb.TradeIntent
 	}
 
 	if len(orders) == 0 {
+		// i8: handleTradeIntent persiste el dedupe como REJECTED junto al trade
 		r.core.telemetry.Warn(ctx, "No ExecuteOrders generated after guard",
 			attribute.String("trade_id", tradeID),
 			attribute.String("strategy_id", strategyID),
 		)
-		if err := r.core.dedupeService.UpdateStatus(ctx, tradeID, domain.OrderStatusRejected); err != nil {
-			r.core.telemetry.Error(ctx, "Failed to update dedupe status after rejection", err,
-				attribute.String("trade_id", tradeID),
-			)
-		}
 	}
 
 	return orders
@@ -923,6 +1005,13 @@ func (r *Router) handleExecutionResult(ctx context.Context, agentID string, resu
 
 	// 2. Resolver slave_account_id y trade_id desde el índice de correlación (i1)
 	cmdCtx := r.getCommandContext(commandID)
+	if cmdCtx == nil {
+		// i8: tras un reinicio el índice está vacío; el outbox conserva trade, cuenta destino y auditoría
+		cmdCtx = r.recoverCommandContext(ctx, commandID)
+	}
+	if cmdCtx != nil {
+		backfillMasterTimestamps(timestampsMap, cmdCtx.MasterTimestamps)
+	}
 	if cmdCtx == nil {
 		r.core.telemetry.Warn(ctx, "CommandContext not found for ExecutionResult (i1)",
 			attribute.String("command_id", commandID),
@@ -973,6 +1062,13 @@ func (r *Router) handleExecutionResult(ctx context.Context, agentID string, resu
 		ErrorMessage:   errMsg,
 		TimestampsMs:   timestampsMap,
 	}
+	if cmdCtx != nil && cmdCtx.RiskPolicyVersion > 0 {
+		// i7: Persistir la versión de política para reconstruir el dimensionamiento
+		version := cmdCtx.RiskPolicyVersion
+		execution.StrategyID = cmdCtx.StrategyID
+		execution.RiskPolicyType = string(cmdCtx.RiskPolicyType)
+		execution.RiskPolicyVersion = &version
+	}
 
 	// Persistir usando CorrelationService (también actualiza dedupe)
 	if err := r.core.correlationSvc.RecordExecution(ctx, execution); err != nil {
@@ -989,6 +1085,18 @@ func (r *Router) handleExecutionResult(ctx context.Context, agentID string, resu
 		)
 	}
 
+	// i8: El resultado (éxito o rechazo del broker) confirma la entrega del comando
+	if acked, err := r.core.repoFactory.OutboxRepository().MarkAcked(ctx, commandID, r.core.nowMs()); err != nil {
+		r.core.telemetry.Warn(ctx, "Failed to ack outbox command (i8)",
+			attribute.String("command_id", commandID),
+			attribute.String("error", err.Error()),
+		)
+	} else if !acked {
+		r.core.telemetry.Debug(ctx, "ExecutionResult without pending outbox command (i8)",
+			attribute.String("command_id", commandID),
+		)
+	}
+
 	// 3. Log según resultado
 	if result.Success {
 		r.core.telemetry.Info(ctx, "Order filled successfully (i1)",
@@ -1019,6 +1127,104 @@ func (r *Router) handleExecutionResult(ctx context.Context, agentID string, resu
 		semconv.Echo.Status.String(statusToString(result.Success)),
 		semconv.Echo.ErrorCode.String(result.ErrorCode.String()),
 	)
+
+	r.publishExecutionResult(agentID, finalTradeID, slaveAccountID, cmdCtx, result, errorCode, timestampsMap)
+}
+
+// handleTradeModify registra un cambio de SL/TP del Master en el ciclo de vida del trade (i8).
+//
+// Solo persiste el evento: los slaves conservan sus niveles (ModifyOrder no se replica).
+func (r *Router) handleTradeModify(ctx context.Context, agentID string, modify *pb.TradeModify) {
+	modify.TradeId = strings.ToLower(modify.TradeId)
+	tradeID := modify.TradeId
+
+	ctx = telemetry.AppendEventAttrs(ctx,
+		semconv.Echo.TradeID.String(tradeID),
+	)
+
+	// i8: El modify debe venir del Agent dueño de la cuenta master del trade
+	trade, err := r.core.repoFactory.TradeRepository().GetByID(ctx, tradeID)
+	if err != nil {
+		r.core.telemetry.Warn(ctx, "Failed to load trade for TradeModify (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+	if trade == nil {
+		r.core.telemetry.Warn(ctx, "TradeModify for unknown trade (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.Int("ticket", int(modify.Ticket)),
+		)
+		return
+	}
+	if !r.authorizeAccountReport(ctx, agentID, trade.MasterAccountID, &pb.AgentMessage_TradeModify{TradeModify: modify}) {
+		return
+	}
+
+	record := &domain.Modify{
+		ModifyID:      r.core.newID(),
+		TradeID:       tradeID,
+		MasterTicket:  modify.Ticket,
+		NewStopLoss:   modify.NewStopLoss,
+		NewTakeProfit: modify.NewTakeProfit,
+		ModifiedAtMs:  modify.TimestampMs,
+	}
+	if record.ModifiedAtMs == 0 {
+		record.ModifiedAtMs = r.core.nowMs()
+	}
+
+	if err := r.core.repoFactory.ModifyRepository().Create(ctx, record); err != nil {
+		r.core.telemetry.Warn(ctx, "Failed to record trade modify (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.Int("ticket", int(modify.Ticket)),
+			attribute.String("error", err.Error()),
+		)
+		return
+	}
+
+	r.core.telemetry.Info(ctx, "TradeModify recorded (i8)",
+		attribute.String("modify_id", record.ModifyID),
+		attribute.Int("ticket", int(modify.Ticket)),
+		attribute.Bool("stop_loss_changed", modify.NewStopLoss != nil),
+		attribute.Bool("take_profit_changed", modify.NewTakeProfit != nil),
+	)
+}
+
+// authorizeAccountReport aplica allowlist y ownership a un mensaje cuya cuenta
+// se resuelve en el Router (ExecutionResult por command_id, TradeModify por trade_id) (i8).
+//
+// Replica los filtros de Core.handleAgentMessage para los payloads que no traen account_id.
+func (r *Router) authorizeAccountReport(ctx context.Context, agentID, accountID string, payload any) bool {
+	if accountID == "" {
+		return true
+	}
+
+	if !r.core.config.Security.AccountAllowed(agentID, accountID) {
+		r.core.telemetry.Warn(ctx, "Agent message dropped: account not allowed for agent (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.String("account_id", accountID),
+			attribute.String("payload_type", fmt.Sprintf("%T", payload)),
+		)
+		r.core.echoMetrics.RecordAgentAuthRejected(ctx, "account_not_allowed",
+			attribute.String("agent_id", agentID),
+		)
+		return false
+	}
+
+	if owner, conflict := r.core.accountRegistry.ConflictingOwner(agentID, accountID); conflict {
+		r.core.telemetry.Warn(ctx, "Agent message dropped: account owned by another Agent (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.String("account_id", accountID),
+			attribute.String("owner_agent_id", owner),
+			attribute.String("payload_type", fmt.Sprintf("%T", payload)),
+		)
+		r.core.echoMetrics.RecordAccountOwnershipEvent(ctx, "dropped",
+			attribute.String("account_id", accountID),
+		)
+		return false
+	}
+	return true
 }
 
 // handleTradeClose procesa un TradeClose del Master.
@@ -1039,12 +1245,17 @@ func (r *Router) handleTradeClose(ctx context.Context, agentID string, close *pb
 		semconv.Echo.TradeID.String(tradeID),
 	)
 
+	// i8: Un cierre parcial del master cierra la misma fracción del volumen abierto en cada slave
+	closeFraction := partialCloseFraction(close)
+
 	r.core.telemetry.Info(ctx, "TradeClose received",
 		attribute.Int("ticket", int(close.Ticket)),
 		attribute.Float64("close_price", close.ClosePrice),
 		attribute.String("symbol", close.Symbol),
 		attribute.Int64("magic_number", close.MagicNumber),
+		attribute.Float64("close_fraction", closeFraction),
 	)
+	r.publishCloseReceived(agentID, close)
 
 	// Obtener tickets por slave usando CorrelationService (i1)
 	ticketsBySlave, err := r.core.correlationSvc.GetTicketsByTrade(ctx, tradeID)
@@ -1065,11 +1276,6 @@ func (r *Router) handleTradeClose(ctx context.Context, agentID string, close *pb
 	totalSent := 0
 
 	for _, slaveAccountID := range r.core.config.SlaveAccounts {
-		closeOrderID := utils.GenerateUUIDv7()
-
-		// i1: Registrar contexto del CloseOrder para correlación
-		r.registerCommandContext(closeOrderID, tradeID, slaveAccountID, "close_order")
-
 		// Resolver ticket exacto del slave (i1 - RFC-003)
 		ticket := ticketsBySlave[slaveAccountID]
 		if ticket == 0 {
@@ -1080,105 +1286,195 @@ func (r *Router) handleTradeClose(ctx context.Context, agentID string, close *pb
 			// Continuar con ticket=0 (fallback a búsqueda por magic+symbol en slave)
 		}
 
-		// i3: Traducir símbolo canónico a broker_symbol por cuenta
-		canonicalSymbol := close.Symbol
-		brokerSymbol, _, found := r.core.symbolResolver.ResolveForAccount(ctx, slaveAccountID, canonicalSymbol)
-		symbolToUse := canonicalSymbol // Fallback a canonical si no hay mapeo
-		if found {
-			symbolToUse = brokerSymbol
-			r.core.telemetry.Debug(ctx, "Symbol mapping applied for CloseOrder (i3)",
-				attribute.String("account_id", slaveAccountID),
-				attribute.String("canonical", canonicalSymbol),
-				attribute.String("broker", brokerSymbol),
-			)
-		} else {
-			// No hay mapeo - aplicar política (pero no rechazar cierre, solo warn)
-			r.core.telemetry.Warn(ctx, "Symbol mapping missing for CloseOrder, using canonical (i3)",
-				attribute.String("account_id", slaveAccountID),
-				attribute.String("canonical_symbol", canonicalSymbol),
-			)
+		if _, ok := r.sendCloseOrder(ctx, tradeID, slaveAccountID, ticket, close.Symbol, close.MagicNumber, closeFraction); !ok {
+			return
 		}
+		totalSent++
+	}
 
-		closeOrder := &pb.CloseOrder{
-			CommandId:   closeOrderID,
-			TradeId:     tradeID,
-			TimestampMs: utils.NowUnixMilli(),
-			// i1: Ticket EXACTO del slave (no 0 si se encontró en BD)
-			Ticket: ticket,
-			// Issue #C3: Llenar campos target_* con datos del TradeClose
-			TargetClientId:  fmt.Sprintf("slave_%s", slaveAccountID),
-			TargetAccountId: slaveAccountID,
-			Symbol:          symbolToUse, // i3: Traducido a broker_symbol si existe mapeo
-			MagicNumber:     close.MagicNumber,
-			// Inicializar timestamps para permitir que el Agent agregue t4
-			Timestamps: &pb.TimestampMetadata{},
-		}
+	r.core.telemetry.Info(ctx, "All CloseOrders sent (i2)",
+		attribute.String("trade_id", tradeID),
+		attribute.Int("total_slaves", len(r.core.config.SlaveAccounts)),
+		attribute.Int("total_sent", totalSent),
+	)
+}
 
-		// Registrar timestamp t3 (Core send) en CloseOrder
-		if closeOrder.Timestamps != nil {
-			closeOrder.Timestamps.T3CoreSendMs = utils.NowUnixMilli()
-		}
+// partialCloseFraction retorna la fracción del volumen que cerró el master (i8).
+//
+// 0 = cierre total: el TradeClose no trae volúmenes o no dejó volumen abierto.
+func partialCloseFraction(close *pb.TradeClose) float64 {
+	closed, remaining := close.GetClosedVolume(), close.GetRemainingVolume()
+	if closed <= 0 || remaining <= 0 {
+		return 0
+	}
+	return closed / (closed + remaining)
+}
 
-		// i2: Routing selectivo para CloseOrder
-		msg := &pb.CoreMessage{
-			Payload: &pb.CoreMessage_CloseOrder{CloseOrder: closeOrder},
-		}
+// sendCloseOrder envía el CloseOrder de tradeID a slaveAccountID.
+//
+// Routing selectivo al owner de la cuenta con fallback a broadcast (i2). closeFraction > 0
+// pide un cierre parcial (i8). Retorna el command_id y false si ctx se canceló antes de entregarlo.
+func (r *Router) sendCloseOrder(ctx context.Context, tradeID, slaveAccountID string, ticket int32, canonicalSymbol string, magicNumber int64, closeFraction float64) (string, bool) {
+	closeOrderID := r.core.newID()
+
+	// i1: Registrar contexto del CloseOrder para correlación
+	r.registerCommandContext(closeOrderID, tradeID, slaveAccountID, "close_order")
+	r.attachSymbol(closeOrderID, canonicalSymbol)
+
+	// i3: Traducir símbolo canónico a broker_symbol por cuenta
+	brokerSymbol, _, found := r.core.symbolResolver.ResolveForAccount(ctx, slaveAccountID, canonicalSymbol)
+	symbolToUse := canonicalSymbol // Fallback a canonical si no hay mapeo
+	if found {
+		symbolToUse = brokerSymbol
+		r.core.telemetry.Debug(ctx, "Symbol mapping applied for CloseOrder (i3)",
+			attribute.String("account_id", slaveAccountID),
+			attribute.String("canonical", canonicalSymbol),
+			attribute.String("broker", brokerSymbol),
+		)
+	} else {
+		// No hay mapeo - aplicar política (pero no rechazar cierre, solo warn)
+		r.core.telemetry.Warn(ctx, "Symbol mapping missing for CloseOrder, using canonical (i3)",
+			attribute.String("account_id", slaveAccountID),
+			attribute.String("canonical_symbol", canonicalSymbol),
+		)
+	}
 
-		ownerAgentID, found := r.core.accountRegistry.GetOwner(slaveAccountID)
-
-		if found {
-			// Routing selectivo
-			agent, agentExists := r.getAgent(ownerAgentID)
-			if agentExists {
-				select {
-				case agent.SendCh <- msg:
-					totalSent++
-					r.core.telemetry.Info(ctx, "CloseOrder sent to Agent (selective i2)",
-						attribute.String("close_order_id", closeOrderID),
-						attribute.String("agent_id", ownerAgentID),
-						attribute.String("target_account_id", slaveAccountID),
-						attribute.String("symbol", close.Symbol),
-						attribute.Int64("magic_number", close.MagicNumber),
-					)
+	closeOrder := &pb.CloseOrder{
+		CommandId:   closeOrderID,
+		TradeId:     tradeID,
+		TimestampMs: r.core.nowMs(),
+		// i1: Ticket EXACTO del slave (no 0 si se encontró en BD)
+		Ticket: ticket,
+		// Issue #C3: Llenar campos target_* con datos del TradeClose
+		TargetClientId:  fmt.Sprintf("slave_%s", slaveAccountID),
+		TargetAccountId: slaveAccountID,
+		Symbol:          symbolToUse, // i3: Traducido a broker_symbol si existe mapeo
+		MagicNumber:     magicNumber,
+		// Inicializar timestamps para permitir que el Agent agregue t4
+		Timestamps: &pb.TimestampMetadata{},
+	}
+	if closeFraction > 0 {
+		// i8: El slave aplica la fracción sobre su propio volumen abierto
+		closeOrder.CloseFraction = &closeFraction
+	}
 
-				case <-ctx.Done():
-					r.core.telemetry.Error(ctx, "Context cancelled while sending CloseOrder", ctx.Err(),
-						attribute.String("close_order_id", closeOrderID),
-						attribute.String("target_account_id", slaveAccountID),
-					)
-					return
-				}
-			} else {
-				// Owner registrado pero desconectado → fallback broadcast
-				r.core.telemetry.Warn(ctx, "Owner agent not connected for CloseOrder, falling back to broadcast (i2)",
+	// Registrar timestamp t3 (Core send) en CloseOrder
+	if closeOrder.Timestamps != nil {
+		closeOrder.Timestamps.T3CoreSendMs = r.core.nowMs()
+	}
+
+	// i2: Routing selectivo para CloseOrder
+	msg := &pb.CoreMessage{
+		Payload: &pb.CoreMessage_CloseOrder{CloseOrder: closeOrder},
+	}
+
+	ownerAgentID, found := r.core.accountRegistry.GetOwner(slaveAccountID)
+
+	if found {
+		// Routing selectivo
+		agent, agentExists := r.getAgent(ownerAgentID)
+		if agentExists {
+			select {
+			case agent.SendCh <- msg:
+				r.core.telemetry.Info(ctx, "CloseOrder sent to Agent (selective i2)",
+					attribute.String("close_order_id", closeOrderID),
+					attribute.String("agent_id", ownerAgentID),
+					attribute.String("target_account_id", slaveAccountID),
+					attribute.String("symbol", canonicalSymbol),
+					attribute.Int64("magic_number", magicNumber),
+				)
+
+			case <-ctx.Done():
+				r.core.telemetry.Error(ctx, "Context cancelled while sending CloseOrder", ctx.Err(),
+					attribute.String("close_order_id", closeOrderID),
 					attribute.String("target_account_id", slaveAccountID),
-					attribute.String("owner_agent_id", ownerAgentID),
 				)
-				r.broadcastCloseOrder(ctx, msg, closeOrder, slaveAccountID)
-				totalSent++
+				return closeOrderID, false
 			}
 		} else {
-			// No hay owner registrado → fallback broadcast
-			r.core.telemetry.Warn(ctx, "No owner registered for account in CloseOrder, falling back to broadcast (i2)",
+			// Owner registrado pero desconectado → fallback broadcast
+			r.core.telemetry.Warn(ctx, "Owner agent not connected for CloseOrder, falling back to broadcast (i2)",
 				attribute.String("target_account_id", slaveAccountID),
+				attribute.String("owner_agent_id", ownerAgentID),
 			)
 			r.broadcastCloseOrder(ctx, msg, closeOrder, slaveAccountID)
-			totalSent++
 		}
+	} else {
+		// No hay owner registrado → fallback broadcast
+		r.core.telemetry.Warn(ctx, "No owner registered for account in CloseOrder, falling back to broadcast (i2)",
+			attribute.String("target_account_id", slaveAccountID),
+		)
+		r.broadcastCloseOrder(ctx, msg, closeOrder, slaveAccountID)
 	}
 
-	r.core.telemetry.Info(ctx, "All CloseOrders sent (i2)",
+	return closeOrderID, true
+}
+
+// ManualCloseTarget resultado de CloseTradeOnSlaves para un slave (i8).
+type ManualCloseTarget struct {
+	SlaveAccountID string
+	Ticket         int32
+	CommandID      string // vacío si no se envió
+	Sent           bool
+	Reason         string // motivo si no se envió
+}
+
+// CloseTradeOnSlaves cierra la copia de tradeID en los slaves indicados (i8, AdminService).
+//
+// slaveAccountIDs vacío = todos los slaves con ticket registrado para el trade. Un slave
+// sin ticket se omite: el cierre manual no usa el fallback magic+symbol de handleTradeClose.
+func (r *Router) CloseTradeOnSlaves(ctx context.Context, tradeID string, slaveAccountIDs []string) ([]ManualCloseTarget, error) {
+	tradeID = strings.ToLower(tradeID)
+	trade, err := r.core.repoFactory.TradeRepository().GetByID(ctx, tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trade %s: %w", tradeID, err)
+	}
+	if trade == nil {
+		return nil, fmt.Errorf("trade %s not found", tradeID)
+	}
+
+	ticketsBySlave, err := r.core.correlationSvc.GetTicketsByTrade(ctx, tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets for trade %s: %w", tradeID, err)
+	}
+	if len(slaveAccountIDs) == 0 {
+		for slaveAccountID := range ticketsBySlave {
+			slaveAccountIDs = append(slaveAccountIDs, slaveAccountID)
+		}
+		sort.Strings(slaveAccountIDs)
+	}
+
+	ctx = telemetry.AppendEventAttrs(ctx, semconv.Echo.TradeID.String(tradeID))
+	targets := make([]ManualCloseTarget, 0, len(slaveAccountIDs))
+	for _, slaveAccountID := range slaveAccountIDs {
+		target := ManualCloseTarget{SlaveAccountID: slaveAccountID, Ticket: ticketsBySlave[slaveAccountID]}
+		if target.Ticket == 0 {
+			target.Reason = "no ticket registered for slave"
+			targets = append(targets, target)
+			continue
+		}
+
+		commandID, ok := r.sendCloseOrder(ctx, tradeID, slaveAccountID, target.Ticket, trade.Symbol, trade.MagicNumber, 0)
+		if !ok {
+			return targets, ctx.Err()
+		}
+		target.CommandID = commandID
+		target.Sent = true
+		targets = append(targets, target)
+	}
+
+	r.core.telemetry.Info(ctx, "Manual CloseOrders sent (i8)",
 		attribute.String("trade_id", tradeID),
-		attribute.Int("total_slaves", len(r.core.config.SlaveAccounts)),
-		attribute.Int("total_sent", totalSent),
+		attribute.StringSlice("slave_account_ids", slaveAccountIDs),
 	)
+	return targets, nil
 }
 
 // handleCloseResult procesa un CloseResult del Slave (i1).
 //
 // Flujo:
 //  1. Resolver slave_account_id y trade_id desde índice
-//  2. Persistir close en echo.closes usando CorrelationService
+//  2. Persistir close en echo.closes usando CorrelationService (precio, P&L y volúmenes i8)
 //  3. Limpiar command_id del índice
 //  4. Métricas y logs
 func (r *Router) handleCloseResult(ctx context.Context, agentID string, result *pb.ExecutionResult) {
@@ -1221,6 +1517,9 @@ func (r *Router) handleCloseResult(ctx context.Context, agentID string, result *
 		attribute.Bool("success", result.Success),
 		attribute.Int("ticket", int(result.Ticket)),
 		attribute.Float64("close_price", closePrice),
+		attribute.Float64("profit", result.GetProfit()),
+		attribute.Float64("closed_volume", result.GetClosedVolume()),
+		attribute.Float64("remaining_volume", result.GetRemainingVolume()),
 	)
 
 	// 2. Extraer error message si existe
@@ -1239,16 +1538,22 @@ func (r *Router) handleCloseResult(ctx context.Context, agentID string, result *
 	}
 
 	// 3. Persistir close usando CorrelationService (i1)
+	// i8: el precio solo se persiste si el slave lo reportó (NULL si fallo)
 	close := &domain.Close{
-		CloseID:        commandID,
-		TradeID:        finalTradeID,
-		SlaveAccountID: slaveAccountID,
-		SlaveTicket:    result.Ticket,
-		ClosePrice:     &closePrice, // puntero para compatibilidad con optional field
-		Success:        result.Success,
-		ErrorCode:      errorCode,
-		ErrorMessage:   errMsg,
-		ClosedAtMs:     utils.NowUnixMilli(),
+		CloseID:         commandID,
+		TradeID:         finalTradeID,
+		SlaveAccountID:  slaveAccountID,
+		SlaveTicket:     result.Ticket,
+		ClosePrice:      result.ExecutedPrice,
+		Success:         result.Success,
+		ErrorCode:       errorCode,
+		ErrorMessage:    errMsg,
+		Profit:          result.Profit,
+		Commission:      result.Commission,
+		Swap:            result.Swap,
+		ClosedVolume:    result.ClosedVolume,
+		RemainingVolume: result.RemainingVolume,
+		ClosedAtMs:      r.core.nowMs(),
 	}
 
 	if err := r.core.correlationSvc.RecordClose(ctx, close); err != nil {
@@ -1262,11 +1567,13 @@ func (r *Router) handleCloseResult(ctx context.Context, agentID string, result *
 			attribute.String("trade_id", finalTradeID),
 			attribute.String("slave_account_id", slaveAccountID),
 			attribute.Bool("success", result.Success),
+			attribute.Bool("partial", close.IsPartial()),
 		)
 	}
 
 	// 4. Limpiar command_id del índice (i1 cleanup)
 	r.deleteCommandContext(commandID)
+	r.publishCloseResult(agentID, cmdCtx, result, errorCode)
 
 	// 5. Log según resultado
 	if result.Success {
@@ -1350,7 +1657,7 @@ func (r *Router) registerCommandID(commandID string) {
 	r.commandDedupeMu.Lock()
 	defer r.commandDedupeMu.Unlock()
 
-	r.commandDedupe[commandID] = utils.NowUnixMilli()
+	r.commandDedupe[commandID] = r.core.nowMs()
 
 	// TODO i1: Cleanup periódico
 	// Si el mapa crece mucho (>10k entries), limpiar entries antiguas (>1h)
@@ -1368,7 +1675,46 @@ func (r *Router) registerCommandContext(commandID, tradeID, slaveAccountID, comm
 		TradeID:        tradeID,
 		SlaveAccountID: slaveAccountID,
 		CommandType:    commandType,
-		CreatedAtMs:    utils.NowUnixMilli(),
+		CreatedAtMs:    r.core.nowMs(),
+	}
+}
+
+// attachRiskPolicy asocia la política aplicada al contexto de un ExecuteOrder (i7).
+func (r *Router) attachRiskPolicy(commandID, strategyID string, policy *domain.RiskPolicy) {
+	if policy == nil {
+		return
+	}
+
+	r.commandContextMu.Lock()
+	defer r.commandContextMu.Unlock()
+
+	cmdCtx, ok := r.commandContext[commandID]
+	if !ok {
+		return
+	}
+	cmdCtx.StrategyID = strategyID
+	cmdCtx.RiskPolicyType = policy.Type
+	cmdCtx.RiskPolicyVersion = policy.Version
+}
+
+// attachSymbol asocia el símbolo canónico al contexto de un comando (i8, WatchEvents).
+func (r *Router) attachSymbol(commandID, canonicalSymbol string) {
+	r.commandContextMu.Lock()
+	defer r.commandContextMu.Unlock()
+
+	if cmdCtx, ok := r.commandContext[commandID]; ok {
+		cmdCtx.Symbol = canonicalSymbol
+	}
+}
+
+// attachSlippageReference asocia precio del master y point del slave a un ExecuteOrder (i8).
+func (r *Router) attachSlippageReference(commandID string, referencePrice, point float64) {
+	r.commandContextMu.Lock()
+	defer r.commandContextMu.Unlock()
+
+	if cmdCtx, ok := r.commandContext[commandID]; ok {
+		cmdCtx.ReferencePrice = referencePrice
+		cmdCtx.Point = point
 	}
 }
 
@@ -1392,6 +1738,120 @@ func (r *Router) deleteCommandContext(commandID string) {
 	delete(r.commandContext, commandID)
 }
 
+// recoverCommandContext reconstruye el contexto de un ExecuteOrder desde el outbox (i8).
+//
+// Retorna nil si el comando no está en el outbox (ya purgado o emitido antes de i8).
+func (r *Router) recoverCommandContext(ctx context.Context, commandID string) *CommandContext {
+	command, err := r.core.repoFactory.OutboxRepository().GetByID(ctx, commandID)
+	if err != nil {
+		r.core.telemetry.Warn(ctx, "Failed to read outbox command (i8)",
+			attribute.String("command_id", commandID),
+			attribute.String("error", err.Error()),
+		)
+		return nil
+	}
+	if command == nil {
+		return nil
+	}
+
+	order := &pb.ExecuteOrder{}
+	if err := proto.Unmarshal(command.Payload, order); err != nil {
+		r.core.telemetry.Warn(ctx, "Failed to decode outbox command (i8)",
+			attribute.String("command_id", commandID),
+			attribute.String("error", err.Error()),
+		)
+		order = nil
+	}
+	return r.restoreCommandContext(command, order)
+}
+
+// restoreCommandContext registra el contexto de un ExecuteOrder del outbox (i8).
+//
+// Restaura la auditoría persistida con el comando (estrategia, política, slippage) y los
+// timestamps del master del payload; order puede ser nil si el payload no se pudo decodificar.
+func (r *Router) restoreCommandContext(command *domain.OutboxCommand, order *pb.ExecuteOrder) *CommandContext {
+	cmdCtx := &CommandContext{
+		TradeID:           command.TradeID,
+		SlaveAccountID:    command.TargetAccountID,
+		CommandType:       command.CommandType,
+		CreatedAtMs:       command.CreatedAtMs,
+		StrategyID:        command.StrategyID,
+		RiskPolicyType:    domain.RiskPolicyType(command.RiskPolicyType),
+		RiskPolicyVersion: command.RiskPolicyVersion,
+		Symbol:            command.Symbol,
+		ReferencePrice:    command.ReferencePrice,
+		Point:             command.Point,
+	}
+	if order != nil && order.Timestamps != nil {
+		cmdCtx.MasterTimestamps = proto.Clone(order.Timestamps).(*pb.TimestampMetadata)
+	}
+
+	r.commandContextMu.Lock()
+	defer r.commandContextMu.Unlock()
+
+	r.commandContext[command.CommandID] = cmdCtx
+	return cmdCtx
+}
+
+// backfillMasterTimestamps completa t0..t3 que el ExecutionResult no trae (i8).
+func backfillMasterTimestamps(timestampsMap map[string]int64, master *pb.TimestampMetadata) {
+	if master == nil {
+		return
+	}
+	for key, value := range map[string]int64{
+		"t0": master.T0MasterEaMs,
+		"t1": master.T1AgentRecvMs,
+		"t2": master.T2CoreRecvMs,
+		"t3": master.T3CoreSendMs,
+	} {
+		if value > 0 && timestampsMap[key] == 0 {
+			timestampsMap[key] = value
+		}
+	}
+}
+
+// newOutboxCommands serializa los ExecuteOrders para el outbox transaccional (i8).
+//
+// Copia la auditoría del CommandContext para restaurarla tras un reinicio. Un comando cuyo
+// owner está conectado se inserta DISPATCHED y lo entrega handleTradeIntent; el resto queda
+// PENDING para el dispatcher, que nunca ve una fila antes de que alguien la reclame.
+func (r *Router) newOutboxCommands(orders []*pb.ExecuteOrder, createdAtMs int64) ([]*domain.OutboxCommand, error) {
+	commands := make([]*domain.OutboxCommand, 0, len(orders))
+	for _, order := range orders {
+		payload, err := proto.Marshal(order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal execute order %s: %w", order.CommandId, err)
+		}
+		command := &domain.OutboxCommand{
+			CommandID:       order.CommandId,
+			TradeID:         order.TradeId,
+			TargetAccountID: order.TargetAccountId,
+			CommandType:     "execute_order",
+			Payload:         payload,
+			Status:          domain.OutboxStatusPending,
+			CreatedAtMs:     createdAtMs,
+		}
+		if cmdCtx := r.getCommandContext(order.CommandId); cmdCtx != nil {
+			command.StrategyID = cmdCtx.StrategyID
+			command.RiskPolicyType = string(cmdCtx.RiskPolicyType)
+			command.RiskPolicyVersion = cmdCtx.RiskPolicyVersion
+			command.Symbol = cmdCtx.Symbol
+			command.ReferencePrice = cmdCtx.ReferencePrice
+			command.Point = cmdCtx.Point
+		}
+		if ownerAgentID, found := r.core.accountRegistry.GetOwner(order.TargetAccountId); found {
+			if _, connected := r.getAgent(ownerAgentID); connected {
+				command.Status = domain.OutboxStatusDispatched
+				command.Attempts = 1
+				command.LastAgentID = ownerAgentID
+				command.DispatchedAtMs = createdAtMs
+			}
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
 // orderSideToDomain convierte pb.OrderSide a domain.OrderSide (i1).
 func orderSideToDomain(side pb.OrderSide) domain.OrderSide {
 	switch side {
@@ -1409,6 +1869,51 @@ func (r *Router) getAgent(agentID string) (*AgentConnection, bool) {
 	return r.core.GetAgent(agentID)
 }
 
+// deliverExecuteOrder entrega un ExecuteOrder al Agent owner de la cuenta destino (i2).
+//
+// Retorna el agent_id owner y si el envío fue exitoso. No hace broadcast: sin owner
+// conectado el comando queda en el outbox (i8).
+func (r *Router) deliverExecuteOrder(ctx context.Context, order *pb.ExecuteOrder) (string, bool) {
+	targetAccountID := order.TargetAccountId
+	ownerAgentID, found := r.core.accountRegistry.GetOwner(targetAccountID)
+
+	// Registrar métrica de lookup
+	if found {
+		r.core.echoMetrics.RecordAccountLookup(ctx, "hit",
+			attribute.String("target_account_id", targetAccountID),
+		)
+	} else {
+		r.core.echoMetrics.RecordAccountLookup(ctx, "miss",
+			attribute.String("target_account_id", targetAccountID),
+		)
+		r.core.telemetry.Warn(ctx, "No owner registered for account (i2)",
+			attribute.String("target_account_id", targetAccountID),
+			attribute.String("command_id", order.CommandId),
+		)
+		return "", false
+	}
+
+	agent, agentExists := r.getAgent(ownerAgentID)
+	if !agentExists {
+		r.core.telemetry.Warn(ctx, "Owner agent not connected (i2)",
+			attribute.String("target_account_id", targetAccountID),
+			attribute.String("owner_agent_id", ownerAgentID),
+			attribute.String("command_id", order.CommandId),
+		)
+		return ownerAgentID, false
+	}
+
+	// Issue #M2: Agregar timestamp t3 (Core envía)
+	if order.Timestamps != nil {
+		order.Timestamps.T3CoreSendMs = r.core.nowMs()
+	}
+
+	msg := &pb.CoreMessage{
+		Payload: &pb.CoreMessage_ExecuteOrder{ExecuteOrder: order},
+	}
+	return ownerAgentID, r.sendToAgent(ctx, agent, msg, order)
+}
+
 // sendToAgent envía un mensaje a un Agent específico (i2b helper con timeout).
 //
 // Retorna true si el envío fue exitoso.
@@ -1502,7 +2007,7 @@ func (r *Router) broadcastOrder(ctx context.Context, msg *pb.CoreMessage, order
 
 // recordRoutingMetric registra métrica de routing (i2).
 //
-// mode: "selective" | "broadcast" | "fallback_broadcast"
+// mode: "selective" | "broadcast" | "fallback_broadcast" | "outbox" | "outbox_redelivery" | "outbox_pending"
 // result: true para "hit", false para "miss"
 func (r *Router) recordRoutingMetric(ctx context.Context, mode string, result bool, order *pb.ExecuteOrder) {
 	resultStr := "miss"
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/domain"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+func TestRouterRecordsTradeModify(t *testing.T) {
+	ctx := context.Background()
+	core, _ := newTestAdminCore(t, SecurityConfig{})
+	core.accountRegistry.RegisterAccount("agent-1", "m1", "master")
+	require.NoError(t, core.repoFactory.TradeRepository().Create(ctx, &domain.Trade{TradeID: "t1", MasterAccountID: "m1", Symbol: "XAUUSD"}))
+
+	stopLoss := 1995.5
+	core.router.handleTradeModify(ctx, "agent-1", &pb.TradeModify{TradeId: "T1", TimestampMs: 1000, Ticket: 42, NewStopLoss: &stopLoss})
+
+	// Solo el Agent dueño de la cuenta master registra modifies
+	takeProfit := 2050.0
+	core.router.handleTradeModify(ctx, "agent-2", &pb.TradeModify{TradeId: "t1", TimestampMs: 2000, Ticket: 42, NewTakeProfit: &takeProfit})
+
+	modifies, err := core.repoFactory.ModifyRepository().GetByTradeID(ctx, "t1")
+	require.NoError(t, err)
+	require.Len(t, modifies, 1)
+	assert.Equal(t, int32(42), modifies[0].MasterTicket)
+	assert.Equal(t, int64(1000), modifies[0].ModifiedAtMs)
+	require.NotNil(t, modifies[0].NewStopLoss)
+	assert.Equal(t, stopLoss, *modifies[0].NewStopLoss)
+	assert.Nil(t, modifies[0].NewTakeProfit)
+}
+
+func TestRouterPartialTradeCloseSendsCloseFraction(t *testing.T) {
+	ctx := context.Background()
+	core, conn := newTestAdminCore(t, SecurityConfig{})
+	core.config.SlaveAccounts = []string{"s1"}
+	require.NoError(t, core.repoFactory.TradeRepository().Create(ctx, &domain.Trade{TradeID: "t1", Symbol: "XAUUSD", MagicNumber: 7}))
+	require.NoError(t, core.repoFactory.ExecutionRepository().Create(ctx, &domain.Execution{ExecutionID: "e1", TradeID: "t1", SlaveAccountID: "s1", SlaveTicket: 555, Success: true}))
+
+	closed, remaining := 0.4, 0.6
+	core.router.handleTradeClose(ctx, "agent-1", &pb.TradeClose{TradeId: "t1", Ticket: 42, Symbol: "XAUUSD", MagicNumber: 7, ClosePrice: 2001.5,
+		ClosedVolume: &closed, RemainingVolume: &remaining})
+
+	require.Len(t, conn.SendCh, 1)
+	closeOrder := (<-conn.SendCh).GetCloseOrder()
+	require.NotNil(t, closeOrder)
+	assert.Equal(t, int32(555), closeOrder.Ticket)
+	require.NotNil(t, closeOrder.CloseFraction)
+	assert.InDelta(t, 0.4, *closeOrder.CloseFraction, 1e-9)
+	assert.Nil(t, closeOrder.LotSize)
+
+	// El cierre del remanente (o un EA sin volúmenes) cierra todo
+	closed, remaining = 0.6, 0
+	core.router.handleTradeClose(ctx, "agent-1", &pb.TradeClose{TradeId: "t1", Ticket: 43, Symbol: "XAUUSD", MagicNumber: 7, ClosePrice: 2002,
+		ClosedVolume: &closed, RemainingVolume: &remaining})
+	core.router.handleTradeClose(ctx, "agent-1", &pb.TradeClose{TradeId: "t1", Ticket: 43, Symbol: "XAUUSD", MagicNumber: 7, ClosePrice: 2002})
+
+	require.Len(t, conn.SendCh, 2)
+	for i := 0; i < 2; i++ {
+		closeOrder = (<-conn.SendCh).GetCloseOrder()
+		require.NotNil(t, closeOrder)
+		assert.Nil(t, closeOrder.CloseFraction)
+	}
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/xKoRx/echo/core/internal/migrations"
+	"github.com/xKoRx/echo/sdk/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// MigrationRunner expone el runner de migraciones con la base configurada en ETCD.
+//
+// Lo usa echo-core-cli db para operar el schema sin inicializar Core.
+type MigrationRunner struct {
+	*migrations.Runner
+
+	db *sql.DB
+}
+
+// Close libera la conexión a la base.
+func (m *MigrationRunner) Close() error {
+	return m.db.Close()
+}
+
+// OpenMigrationRunner carga la configuración desde ETCD y abre la base de storage/backend.
+//
+// Example:
+//
+//	runner, err := internal.OpenMigrationRunner(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	defer runner.Close()
+//	status, err := runner.Status(ctx)
+func OpenMigrationRunner(ctx context.Context) (*MigrationRunner, error) {
+	config, err := LoadConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config from ETCD: %w", err)
+	}
+	if config.StorageBackend == StorageBackendMemory {
+		return nil, fmt.Errorf("storage/backend %q has no schema to migrate", config.StorageBackend)
+	}
+
+	db, err := openDatabase(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	runner, err := migrations.NewRunner(db, migrationDialect(config))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &MigrationRunner{Runner: runner, db: db}, nil
+}
+
+// applyMigrations verifica el schema contra las migraciones embebidas.
+//
+// Con storage/migrate_on_start aplica las pendientes; sin él, rechaza iniciar si hay
+// pendientes. En ambos casos rechaza un schema más nuevo que el binario.
+func applyMigrations(ctx context.Context, db *sql.DB, config *Config, tel *telemetry.Client) error {
+	if db == nil {
+		return nil // Backend en memoria: sin schema
+	}
+
+	runner, err := migrations.NewRunner(db, migrationDialect(config))
+	if err != nil {
+		return err
+	}
+
+	if !config.MigrateOnStart {
+		status, err := runner.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to check schema version: %w", err)
+		}
+		if len(status.Pending) > 0 {
+			return fmt.Errorf("%w (current %d, latest %d)", migrations.ErrPendingMigrations, status.CurrentVersion, status.LatestVersion)
+		}
+		return nil
+	}
+
+	applied, err := runner.Migrate(ctx)
+	for _, m := range applied {
+		tel.Info(ctx, "Schema migration applied",
+			attribute.String("storage_backend", config.StorageBackend),
+			attribute.Int("version", m.Version),
+			attribute.String("name", m.Name),
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	return nil
+}
+
+func migrationDialect(config *Config) migrations.Dialect {
+	if config.StorageBackend == StorageBackendSQLite {
+		return migrations.DialectSQLite
+	}
+	return migrations.DialectPostgres
+}
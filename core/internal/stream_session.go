@@ -0,0 +1,189 @@
+package internal
+
+import (
+	"fmt"
+	"time"
+
+	grpcSDK "github.com/xKoRx/echo/sdk/grpc"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/proto"
+)
+
+// streamSession estado de entrega confiable de un Agent (i8).
+//
+// Sobrevive a los resets del stream: si el Agent reconecta con el mismo session_id,
+// el Core confirma lo recibido y reenvía los CoreMessages sin ack.
+type streamSession struct {
+	id       string
+	reliable *grpcSDK.ReliableSession[*pb.CoreMessage]
+	dropped  bool // no reanudable tras un hueco de seq (protegido por streamSessionsMu)
+}
+
+// setCoreMessageSeq escribe el seq asignado por la sesión de entrega confiable (i8).
+func setCoreMessageSeq(msg *pb.CoreMessage, seq uint64) {
+	msg.Seq = seq
+}
+
+// track clona msg, le asigna seq y lo retiene para replay (i8).
+//
+// Clona el mensaje: un broadcast comparte la misma instancia entre Agents, y un
+// CoreMessage re-encolado puede seguir en vuelo en el stream anterior.
+func (s *streamSession) track(msg *pb.CoreMessage) (*pb.CoreMessage, error) {
+	sequenced := proto.Clone(msg).(*pb.CoreMessage)
+	if _, err := s.reliable.Track(sequenced, setCoreMessageSeq); err != nil {
+		return nil, err
+	}
+	return sequenced, nil
+}
+
+// resumeStreamSession resuelve la sesión indicada en el AgentHello.
+//
+// Retorna nil si el Agent no soporta entrega confiable (sin AgentHello o sin
+// session_id); en ese caso el stream sigue siendo at-most-once. Si no puede
+// reanudar, la sesión nueva hereda los CoreMessages sin ack de la anterior con
+// seq nuevos (como el Agent en applyStreamResumed): el caller los reenvía desde Unacked.
+func (c *Core) resumeStreamSession(agentID string, hello *pb.AgentHello) (*streamSession, *pb.StreamResumed) {
+	if hello == nil || hello.SessionId == "" {
+		return nil, nil
+	}
+
+	c.streamSessionsMu.Lock()
+	defer c.streamSessionsMu.Unlock()
+
+	session, ok := c.streamSessions[agentID]
+	if ok && !session.dropped && session.id == hello.SessionId {
+		released := session.reliable.Ack(hello.ResumeAckSeq)
+		pending, rejected := session.reliable.Stats()
+		c.telemetry.Info(c.ctx, "Agent stream resumed (i8)",
+			attribute.String("agent_id", agentID),
+			attribute.String("session_id", session.id),
+			attribute.Int64("resume_ack_seq", int64(hello.ResumeAckSeq)),
+			attribute.Int("released", released),
+			attribute.Int("replay_count", pending),
+			attribute.Int64("rejected_total", int64(rejected)),
+		)
+		return session, &pb.StreamResumed{
+			SessionId: session.id,
+			Resumed:   true,
+			AckSeq:    session.reliable.LastReceived(),
+		}
+	}
+
+	var carried []*pb.CoreMessage
+	if ok {
+		carried = session.reliable.Restart()
+	}
+
+	session = &streamSession{
+		id:       hello.SessionId,
+		reliable: grpcSDK.NewReliableSession[*pb.CoreMessage](c.config.Stream.ReplayBufferSize),
+	}
+	requeued := 0
+	for _, msg := range carried {
+		if _, err := session.track(msg); err != nil {
+			c.telemetry.Warn(c.ctx, "Unacked CoreMessages lost on stream session restart (i8)",
+				attribute.String("agent_id", agentID),
+				attribute.String("session_id", session.id),
+				attribute.Int("lost", len(carried)-requeued),
+				attribute.String("error", err.Error()),
+			)
+			break
+		}
+		requeued++
+	}
+	c.streamSessions[agentID] = session
+	c.telemetry.Info(c.ctx, "Agent stream session started (i8)",
+		attribute.String("agent_id", agentID),
+		attribute.String("session_id", session.id),
+		attribute.Bool("replaced_previous", ok),
+		attribute.Int("requeued", requeued),
+	)
+	return session, &pb.StreamResumed{SessionId: session.id}
+}
+
+// dropStreamSession marca la sesión del Agent como no reanudable si sigue siendo la vigente (i8).
+//
+// El próximo AgentHello con ese session_id inicia una sesión nueva (resumed=false)
+// que hereda sus CoreMessages sin ack.
+func (c *Core) dropStreamSession(agentID string, session *streamSession) {
+	c.streamSessionsMu.Lock()
+	defer c.streamSessionsMu.Unlock()
+
+	if current, ok := c.streamSessions[agentID]; ok && current == session {
+		current.dropped = true
+	}
+}
+
+// acceptAgentMessage aplica ack y dedupe por seq a un AgentMessage entrante (i8).
+//
+// Retorna false si el mensaje no debe procesarse (Ack o duplicado de un replay).
+// Ante un hueco de seq descarta la sesión y retorna error: el stream debe cerrarse
+// para que el Agent reconecte sin resume y reenvíe lo pendiente con seq nuevos.
+func (c *Core) acceptAgentMessage(conn *AgentConnection, msg *pb.AgentMessage) (bool, error) {
+	if ack := msg.GetAck(); ack != nil {
+		if conn.session != nil {
+			conn.session.reliable.Ack(ack.AckSeq)
+		}
+		return false, nil
+	}
+
+	if conn.session == nil || msg.Seq == 0 {
+		return true, nil
+	}
+	accepted, err := conn.session.reliable.Accept(msg.Seq)
+	if err != nil {
+		c.dropStreamSession(conn.AgentID, conn.session)
+		c.telemetry.Warn(c.ctx, "AgentMessage sequence gap, closing stream without resume (i8)",
+			attribute.String("agent_id", conn.AgentID),
+			attribute.String("session_id", conn.session.id),
+			attribute.Int64("seq", int64(msg.Seq)),
+			attribute.Int64("last_received", int64(conn.session.reliable.LastReceived())),
+		)
+		return false, fmt.Errorf("agent %s stream session %s: %w", conn.AgentID, conn.session.id, err)
+	}
+	if !accepted {
+		c.telemetry.Debug(c.ctx, "Duplicate AgentMessage discarded (i8)",
+			attribute.String("agent_id", conn.AgentID),
+			attribute.Int64("seq", int64(msg.Seq)),
+		)
+		return false, nil
+	}
+	return true, nil
+}
+
+// sequenceCoreMessage asigna seq a un CoreMessage y lo retiene para replay (i8).
+//
+// Retorna grpcSDK.ErrReplayBufferFull si hay demasiados CoreMessages sin ack.
+func (c *Core) sequenceCoreMessage(conn *AgentConnection, msg *pb.CoreMessage) (*pb.CoreMessage, error) {
+	if conn.session == nil {
+		return msg, nil
+	}
+	return conn.session.track(msg)
+}
+
+// streamAck construye el ack acumulativo pendiente para el Agent, si corresponde (i8).
+func (c *Core) streamAck(conn *AgentConnection) *pb.CoreMessage {
+	if conn.session == nil {
+		return nil
+	}
+	ackSeq, due := conn.session.reliable.AckDue()
+	if !due {
+		return nil
+	}
+	return &pb.CoreMessage{
+		TimestampMs: utils.NowUnixMilli(),
+		Payload: &pb.CoreMessage_Ack{
+			Ack: &pb.Ack{Success: true, AckSeq: ackSeq},
+		},
+	}
+}
+
+// streamAckInterval retorna el intervalo de acks del stream (i8).
+func (c *Core) streamAckInterval() time.Duration {
+	if c.config.Stream.AckInterval > 0 {
+		return c.config.Stream.AckInterval
+	}
+	return grpcSDK.DefaultAckInterval
+}
@@ -0,0 +1,84 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+	"github.com/xKoRx/echo/sdk/telemetry"
+)
+
+func newTestStreamCore() *Core {
+	return &Core{
+		config:         &Config{},
+		streamSessions: make(map[string]*streamSession),
+		telemetry:      &telemetry.Client{},
+		ctx:            context.Background(),
+	}
+}
+
+func closeOrderMessage(commandID string) *pb.CoreMessage {
+	return &pb.CoreMessage{Payload: &pb.CoreMessage_CloseOrder{CloseOrder: &pb.CloseOrder{CommandId: commandID}}}
+}
+
+func unackedCommandIDs(session *streamSession) ([]string, []uint64) {
+	var commandIDs []string
+	var seqs []uint64
+	for _, msg := range session.reliable.Unacked() {
+		commandIDs = append(commandIDs, msg.GetCloseOrder().GetCommandId())
+		seqs = append(seqs, msg.Seq)
+	}
+	return commandIDs, seqs
+}
+
+func TestStreamSessionResumeReplaysUnacked(t *testing.T) {
+	core := newTestStreamCore()
+	session, resumed := core.resumeStreamSession("agent-1", &pb.AgentHello{SessionId: "s-1"})
+	require.NotNil(t, session)
+	assert.False(t, resumed.Resumed)
+
+	conn := &AgentConnection{AgentID: "agent-1", session: session}
+	for _, commandID := range []string{"c1", "c2", "c3"} {
+		sequenced, err := core.sequenceCoreMessage(conn, closeOrderMessage(commandID))
+		require.NoError(t, err)
+		assert.NotZero(t, sequenced.Seq)
+	}
+
+	// Mismo session_id: se reanuda y solo queda lo no confirmado, con su seq original
+	again, resumed := core.resumeStreamSession("agent-1", &pb.AgentHello{SessionId: "s-1", ResumeAckSeq: 1})
+	assert.Same(t, session, again)
+	assert.True(t, resumed.Resumed)
+	commandIDs, seqs := unackedCommandIDs(again)
+	assert.Equal(t, []string{"c2", "c3"}, commandIDs)
+	assert.Equal(t, []uint64{2, 3}, seqs)
+}
+
+func TestStreamSessionRestartRequeuesUnacked(t *testing.T) {
+	core := newTestStreamCore()
+	session, _ := core.resumeStreamSession("agent-1", &pb.AgentHello{SessionId: "s-1"})
+	conn := &AgentConnection{AgentID: "agent-1", session: session}
+	for _, commandID := range []string{"c1", "c2", "c3"} {
+		_, err := core.sequenceCoreMessage(conn, closeOrderMessage(commandID))
+		require.NoError(t, err)
+	}
+	session.reliable.Ack(1)
+
+	// El Agent se reinició (session_id nuevo): lo pendiente pasa a la sesión nueva con seq desde 1
+	replaced, resumed := core.resumeStreamSession("agent-1", &pb.AgentHello{SessionId: "s-2"})
+	assert.NotSame(t, session, replaced)
+	assert.False(t, resumed.Resumed)
+	commandIDs, seqs := unackedCommandIDs(replaced)
+	assert.Equal(t, []string{"c2", "c3"}, commandIDs)
+	assert.Equal(t, []uint64{1, 2}, seqs)
+
+	// Un hueco descarta la sesión pero no sus pendientes: el mismo session_id no reanuda
+	core.dropStreamSession("agent-1", replaced)
+	restarted, resumed := core.resumeStreamSession("agent-1", &pb.AgentHello{SessionId: "s-2", ResumeAckSeq: 2})
+	assert.NotSame(t, replaced, restarted)
+	assert.False(t, resumed.Resumed)
+	commandIDs, seqs = unackedCommandIDs(restarted)
+	assert.Equal(t, []string{"c2", "c3"}, commandIDs)
+	assert.Equal(t, []uint64{1, 2}, seqs)
+}
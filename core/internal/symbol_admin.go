@@ -0,0 +1,492 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/domain/handshake"
+)
+
+// Administración de mapeos de símbolos para echo-core-cli symbols (i8).
+//
+// Los mapeos canonical ⇄ broker_symbol los completa el EA con cada
+// AccountSymbolsReport. Un override fijado con PinSymbolMapping se guarda aparte y
+// Core lo reaplica sobre cada reporte posterior, por lo que el EA no lo pisa.
+// PinSymbolMapping persiste override y mapeo en una transacción; el Core en ejecución
+// invalida el caché de la cuenta al recibir el cambio (NOTIFY en PostgreSQL, polling
+// en SQLite) y relee el mapeo sin esperar al EA.
+
+// AccountSymbolMappings mapeos persistidos de una cuenta y sus overrides.
+type AccountSymbolMappings struct {
+	AccountID string                          `json:"account_id"`
+	Mappings  []*domain.SymbolMapping         `json:"mappings"`
+	Overrides []*domain.SymbolMappingOverride `json:"overrides"`
+}
+
+// SymbolSpecView resumen plano de la especificación reportada de un símbolo.
+type SymbolSpecView struct {
+	AccountID       string  `json:"account_id"`
+	CanonicalSymbol string  `json:"canonical_symbol"`
+	BrokerSymbol    string  `json:"broker_symbol"`
+	Digits          int32   `json:"digits"`
+	ContractSize    float64 `json:"contract_size"`
+	TickValue       float64 `json:"tick_value"`
+	StopLevel       int32   `json:"stop_level"`
+	MinVolume       float64 `json:"min_volume"`
+	MaxVolume       float64 `json:"max_volume"`
+	VolumeStep      float64 `json:"volume_step"`
+	MarginCurrency  string  `json:"margin_currency"`
+	ReportedAtMs    int64   `json:"reported_at_ms"`
+}
+
+// SymbolSpecFieldDiff compara un campo de la especificación entre dos cuentas.
+type SymbolSpecFieldDiff struct {
+	Field string `json:"field"`
+	Left  string `json:"left"`
+	Right string `json:"right"`
+	Equal bool   `json:"equal"`
+}
+
+// SymbolSpecDiff comparación de un símbolo canónico entre dos cuentas.
+type SymbolSpecDiff struct {
+	CanonicalSymbol string                `json:"canonical_symbol"`
+	Left            *SymbolSpecView       `json:"left"`
+	Right           *SymbolSpecView       `json:"right"`
+	Fields          []SymbolSpecFieldDiff `json:"fields"`
+}
+
+// Differs indica si algún campo comparado difiere.
+func (d *SymbolSpecDiff) Differs() bool {
+	for _, field := range d.Fields {
+		if !field.Equal {
+			return true
+		}
+	}
+	return false
+}
+
+// TradeableSymbol estado operativo de un símbolo canónico en una cuenta.
+type TradeableSymbol struct {
+	CanonicalSymbol string `json:"canonical_symbol"`
+	BrokerSymbol    string `json:"broker_symbol,omitempty"`
+	Pinned          bool   `json:"pinned"`
+	HandshakeStatus string `json:"handshake_status"`
+	Tradeable       bool   `json:"tradeable"`
+	// Reason explica por qué no es operable; vacío si lo es.
+	Reason string `json:"reason,omitempty"`
+}
+
+// AccountTradeableSymbols símbolos canónicos operables por una cuenta.
+type AccountTradeableSymbols struct {
+	AccountID string `json:"account_id"`
+	// HandshakeStatus estado global de la última evaluación ("" si nunca se evaluó).
+	HandshakeStatus string            `json:"handshake_status"`
+	EvaluatedAtMs   int64             `json:"evaluated_at_ms"`
+	Symbols         []TradeableSymbol `json:"symbols"`
+}
+
+// ListSymbolMappings retorna los mapeos persistidos de la cuenta ordenados por
+// canonical_symbol, junto con sus overrides (i8).
+func ListSymbolMappings(ctx context.Context, accountID string) (*AccountSymbolMappings, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("account_id is required")
+	}
+
+	_, factory, closeRepo, err := openSymbolRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepo()
+
+	infos, err := factory.SymbolRepository().GetAccountMapping(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account mapping: %w", err)
+	}
+	overrides, err := factory.SymbolOverrideRepository().ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbol overrides: %w", err)
+	}
+
+	result := &AccountSymbolMappings{AccountID: accountID, Overrides: overrides}
+	for _, info := range infos {
+		if mapping := info.ToSymbolMapping(); mapping != nil {
+			result.Mappings = append(result.Mappings, mapping)
+		}
+	}
+	sort.Slice(result.Mappings, func(i, j int) bool {
+		return result.Mappings[i].CanonicalSymbol < result.Mappings[j].CanonicalSymbol
+	})
+	return result, nil
+}
+
+// ListSymbolSpecs retorna las especificaciones reportadas por la cuenta ordenadas
+// por canonical_symbol (i8).
+func ListSymbolSpecs(ctx context.Context, accountID string) ([]*SymbolSpecView, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("account_id is required")
+	}
+
+	_, factory, closeRepo, err := openSymbolRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepo()
+
+	specs, err := factory.SymbolSpecRepository().GetSpecifications(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol specifications: %w", err)
+	}
+
+	views := make([]*SymbolSpecView, 0, len(specs))
+	for _, spec := range specs {
+		views = append(views, newSymbolSpecView(accountID, spec))
+	}
+	sort.Slice(views, func(i, j int) bool {
+		return views[i].CanonicalSymbol < views[j].CanonicalSymbol
+	})
+	return views, nil
+}
+
+// PinSymbolMapping fija el broker_symbol de un símbolo canónico para la cuenta y
+// retorna el mapeo resultante, ya persistido (i8).
+func PinSymbolMapping(ctx context.Context, override *domain.SymbolMappingOverride) (*domain.SymbolMapping, error) {
+	if override == nil || override.AccountID == "" || override.BrokerSymbol == "" {
+		return nil, fmt.Errorf("account_id and broker_symbol are required")
+	}
+	if override.UpdatedBy == "" {
+		return nil, fmt.Errorf("updated_by is required")
+	}
+
+	config, factory, closeRepo, err := openSymbolRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepo()
+
+	canonical := strings.ToUpper(strings.TrimSpace(override.CanonicalSymbol))
+	if err := domain.ValidateCanonicalSymbol(canonical, config.CanonicalSymbols); err != nil {
+		return nil, err
+	}
+	pinned := *override
+	pinned.CanonicalSymbol = canonical
+	pinned.BrokerSymbol = strings.TrimSpace(override.BrokerSymbol)
+	if pinned.UpdatedAtMs == 0 {
+		pinned.UpdatedAtMs = time.Now().UnixMilli()
+	}
+
+	infos, err := factory.SymbolRepository().GetAccountMapping(ctx, pinned.AccountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account mapping: %w", err)
+	}
+	current := make([]*domain.SymbolMapping, 0, len(infos))
+	for _, info := range infos {
+		if mapping := info.ToSymbolMapping(); mapping != nil {
+			current = append(current, mapping)
+		}
+	}
+
+	var mapping *domain.SymbolMapping
+	for _, m := range overrideSymbolMappings(current, nil, []*domain.SymbolMappingOverride{&pinned}) {
+		if m.CanonicalSymbol == canonical {
+			mapping = m
+		}
+	}
+	if err := factory.SymbolOverrideRepository().Pin(ctx, &pinned, mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// UnpinSymbolMapping elimina el override de la cuenta. Retorna false si no existía.
+//
+// El mapeo persistido se mantiene hasta que el EA reporte el símbolo de nuevo.
+func UnpinSymbolMapping(ctx context.Context, accountID, canonicalSymbol string) (bool, error) {
+	if accountID == "" || canonicalSymbol == "" {
+		return false, fmt.Errorf("account_id and canonical_symbol are required")
+	}
+
+	_, factory, closeRepo, err := openSymbolRepositories(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer closeRepo()
+
+	return factory.SymbolOverrideRepository().Delete(ctx, accountID, strings.ToUpper(strings.TrimSpace(canonicalSymbol)))
+}
+
+// ListSymbolProposals lista los mapeos que el matcher propuso para la cuenta en su
+// último AccountSymbolsReport (i8). Se aceptan con PinSymbolMapping.
+func ListSymbolProposals(ctx context.Context, accountID string) ([]*domain.SymbolMatchProposal, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("account_id is required")
+	}
+
+	_, factory, closeRepo, err := openSymbolRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepo()
+
+	proposals, err := factory.SymbolProposalRepository().ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbol proposals: %w", err)
+	}
+	return proposals, nil
+}
+
+// ListTradeableSymbols evalúa qué símbolos canónicos puede operar la cuenta (i8).
+//
+// Un símbolo es operable si tiene mapeo y especificación persistidos y la última
+// evaluación de handshake no lo rechaza (ni rechaza la cuenta completa).
+func ListTradeableSymbols(ctx context.Context, accountID string) (*AccountTradeableSymbols, error) {
+	if accountID == "" {
+		return nil, fmt.Errorf("account_id is required")
+	}
+
+	config, factory, closeRepo, err := openSymbolRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepo()
+
+	mappings, err := factory.SymbolRepository().GetAccountMapping(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account mapping: %w", err)
+	}
+	specs, err := factory.SymbolSpecRepository().GetSpecifications(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get symbol specifications: %w", err)
+	}
+	overrides, err := factory.SymbolOverrideRepository().ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list symbol overrides: %w", err)
+	}
+	evaluation, err := factory.HandshakeRepository().GetLatestByAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handshake evaluation: %w", err)
+	}
+
+	return evaluateTradeableSymbols(accountID, config.CanonicalSymbols, mappings, specs, overrides, evaluation), nil
+}
+
+// DiffSymbolSpecs compara la especificación de un símbolo canónico entre dos cuentas (i8).
+func DiffSymbolSpecs(ctx context.Context, canonicalSymbol, leftAccountID, rightAccountID string) (*SymbolSpecDiff, error) {
+	if canonicalSymbol == "" || leftAccountID == "" || rightAccountID == "" {
+		return nil, fmt.Errorf("canonical_symbol and both account_ids are required")
+	}
+	canonical := strings.ToUpper(strings.TrimSpace(canonicalSymbol))
+
+	_, factory, closeRepo, err := openSymbolRepositories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRepo()
+
+	views := make([]*SymbolSpecView, 0, 2)
+	for _, accountID := range []string{leftAccountID, rightAccountID} {
+		specs, err := factory.SymbolSpecRepository().GetSpecifications(ctx, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get symbol specifications for %s: %w", accountID, err)
+		}
+		spec, ok := specs[canonical]
+		if !ok {
+			return nil, fmt.Errorf("account %s has not reported a specification for %s", accountID, canonical)
+		}
+		views = append(views, newSymbolSpecView(accountID, spec))
+	}
+	return diffSymbolSpecs(views[0], views[1]), nil
+}
+
+// overrideSymbolMappings aplica los overrides sobre los mapeos reportados por el EA.
+//
+// Los parámetros del mapeo fijado salen del broker_symbol del override si el EA lo
+// reportó (bajo cualquier canónico o sin mapeo); si no, del mapeo reportado para el
+// canónico; y si tampoco existe, el mapeo queda solo con canonical y broker_symbol.
+// No modifica los mapeos recibidos.
+func overrideSymbolMappings(mappings, unmapped []*domain.SymbolMapping, overrides []*domain.SymbolMappingOverride) []*domain.SymbolMapping {
+	if len(overrides) == 0 {
+		return mappings
+	}
+
+	byBroker := make(map[string]*domain.SymbolMapping, len(mappings)+len(unmapped))
+	for _, mapping := range unmapped {
+		byBroker[mapping.BrokerSymbol] = mapping
+	}
+	byCanonical := make(map[string]int, len(mappings))
+	for i, mapping := range mappings {
+		byBroker[mapping.BrokerSymbol] = mapping
+		byCanonical[mapping.CanonicalSymbol] = i
+	}
+
+	result := append([]*domain.SymbolMapping(nil), mappings...)
+	for _, override := range overrides {
+		pinned := &domain.SymbolMapping{}
+		if source, ok := byBroker[override.BrokerSymbol]; ok {
+			*pinned = *source
+		} else if i, ok := byCanonical[override.CanonicalSymbol]; ok {
+			*pinned = *mappings[i]
+		}
+		pinned.CanonicalSymbol = override.CanonicalSymbol
+		pinned.BrokerSymbol = override.BrokerSymbol
+		pinned.Source = domain.SymbolMappingSourceOverride
+		pinned.Confidence = 1
+
+		if i, ok := byCanonical[override.CanonicalSymbol]; ok {
+			result[i] = pinned
+		} else {
+			byCanonical[override.CanonicalSymbol] = len(result)
+			result = append(result, pinned)
+		}
+	}
+	return result
+}
+
+// evaluateTradeableSymbols cruza whitelist, mapeos, specs y handshake de la cuenta.
+//
+// Sin whitelist configurada se evalúan los canónicos mapeados.
+func evaluateTradeableSymbols(
+	accountID string,
+	canonicals []string,
+	mappings map[string]*domain.AccountSymbolInfo,
+	specs map[string]*domain.AccountSymbolSpec,
+	overrides []*domain.SymbolMappingOverride,
+	evaluation *handshake.Evaluation,
+) *AccountTradeableSymbols {
+	result := &AccountTradeableSymbols{AccountID: accountID}
+
+	entries := make(map[string]handshake.Entry)
+	if evaluation != nil {
+		result.HandshakeStatus = registrationStatusString(evaluation.Status)
+		result.EvaluatedAtMs = evaluation.EvaluatedAtMs
+		for _, entry := range evaluation.Entries {
+			entries[entry.CanonicalSymbol] = entry
+		}
+	}
+	pinned := make(map[string]bool, len(overrides))
+	for _, override := range overrides {
+		pinned[override.CanonicalSymbol] = true
+	}
+
+	symbols := make([]string, 0, len(canonicals))
+	seen := make(map[string]bool)
+	for _, canonical := range canonicals {
+		canonical = strings.ToUpper(strings.TrimSpace(canonical))
+		if canonical != "" && !seen[canonical] {
+			seen[canonical] = true
+			symbols = append(symbols, canonical)
+		}
+	}
+	if len(symbols) == 0 {
+		for canonical := range mappings {
+			symbols = append(symbols, canonical)
+		}
+	}
+	sort.Strings(symbols)
+
+	for _, canonical := range symbols {
+		symbol := TradeableSymbol{CanonicalSymbol: canonical, Pinned: pinned[canonical]}
+		mapping, mapped := mappings[canonical]
+		if mapped {
+			symbol.BrokerSymbol = mapping.BrokerSymbol
+		}
+		entry, evaluated := entries[canonical]
+		if evaluated {
+			symbol.HandshakeStatus = registrationStatusString(entry.Status)
+		}
+
+		switch {
+		case !mapped:
+			symbol.Reason = "no broker mapping reported"
+		case specs[canonical] == nil:
+			symbol.Reason = "no symbol specification reported"
+		case evaluation == nil:
+			symbol.Reason = "account has no handshake evaluation"
+		case evaluation.Status == handshake.RegistrationStatusRejected || evaluation.Status == handshake.RegistrationStatusUnspecified:
+			symbol.Reason = "account handshake " + result.HandshakeStatus
+		case evaluated && entry.Status == handshake.RegistrationStatusRejected:
+			symbol.Reason = "symbol rejected by handshake"
+			if len(entry.Errors) > 0 {
+				symbol.Reason += ": " + entry.Errors[0].Message
+			}
+		default:
+			symbol.Tradeable = true
+		}
+		result.Symbols = append(result.Symbols, symbol)
+	}
+	return result
+}
+
+// newSymbolSpecView aplana la especificación persistida.
+func newSymbolSpecView(accountID string, spec *domain.AccountSymbolSpec) *SymbolSpecView {
+	view := &SymbolSpecView{
+		AccountID:       accountID,
+		CanonicalSymbol: spec.CanonicalSymbol,
+		ReportedAtMs:    spec.ReportedAtMs,
+	}
+	if spec.Specification == nil {
+		return view
+	}
+	general := spec.Specification.GetGeneral()
+	volume := spec.Specification.GetVolume()
+	view.BrokerSymbol = spec.Specification.GetBrokerSymbol()
+	view.Digits = general.GetDigits()
+	view.ContractSize = general.GetContractSize()
+	view.TickValue = general.GetTickValue()
+	view.StopLevel = general.GetStopsLevel()
+	view.MarginCurrency = general.GetMarginCurrency()
+	view.MinVolume = volume.GetMinVolume()
+	view.MaxVolume = volume.GetMaxVolume()
+	view.VolumeStep = volume.GetVolumeStep()
+	return view
+}
+
+// diffSymbolSpecs compara campo a campo dos especificaciones del mismo canónico.
+func diffSymbolSpecs(left, right *SymbolSpecView) *SymbolSpecDiff {
+	formatInt := func(v int32) string { return strconv.FormatInt(int64(v), 10) }
+	formatFloat := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+	pairs := []struct{ field, left, right string }{
+		{"broker_symbol", left.BrokerSymbol, right.BrokerSymbol},
+		{"digits", formatInt(left.Digits), formatInt(right.Digits)},
+		{"contract_size", formatFloat(left.ContractSize), formatFloat(right.ContractSize)},
+		{"tick_value", formatFloat(left.TickValue), formatFloat(right.TickValue)},
+		{"stop_level", formatInt(left.StopLevel), formatInt(right.StopLevel)},
+		{"min_volume", formatFloat(left.MinVolume), formatFloat(right.MinVolume)},
+		{"max_volume", formatFloat(left.MaxVolume), formatFloat(right.MaxVolume)},
+		{"volume_step", formatFloat(left.VolumeStep), formatFloat(right.VolumeStep)},
+		{"margin_currency", left.MarginCurrency, right.MarginCurrency},
+	}
+
+	diff := &SymbolSpecDiff{CanonicalSymbol: left.CanonicalSymbol, Left: left, Right: right}
+	for _, pair := range pairs {
+		diff.Fields = append(diff.Fields, SymbolSpecFieldDiff{
+			Field: pair.field,
+			Left:  pair.left,
+			Right: pair.right,
+			Equal: pair.left == pair.right,
+		})
+	}
+	return diff
+}
+
+// openSymbolRepositories abre el storage configurado para administrar símbolos.
+func openSymbolRepositories(ctx context.Context) (*Config, domain.RepositoryFactory, func(), error) {
+	config, err := LoadConfig(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load config from ETCD: %w", err)
+	}
+	if config.StorageBackend == StorageBackendMemory {
+		return nil, nil, nil, fmt.Errorf("storage/backend %q does not persist symbol mappings", config.StorageBackend)
+	}
+
+	db, factory, err := newRepositoryFactory(ctx, config, time.Now)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return config, factory, func() { closeDB(db) }, nil
+}
@@ -0,0 +1,137 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/domain"
+	"github.com/xKoRx/echo/sdk/domain/handshake"
+	pb "github.com/xKoRx/echo/sdk/pb/v1"
+)
+
+func TestOverrideSymbolMappings(t *testing.T) {
+	contract := 100.0
+	reported := []*domain.SymbolMapping{
+		{CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD", Digits: 2, MinLot: 0.01, MaxLot: 50, LotStep: 0.01, ContractSize: &contract},
+		{CanonicalSymbol: "EURUSD", BrokerSymbol: "EURUSD.m", Digits: 5, MinLot: 0.01, MaxLot: 100, LotStep: 0.01},
+		{CanonicalSymbol: "GBPUSD", BrokerSymbol: "GBPUSD.m", Digits: 5, MinLot: 0.1, MaxLot: 10, LotStep: 0.1},
+	}
+
+	assert.Equal(t, reported, overrideSymbolMappings(reported, nil, nil))
+
+	unmapped := []*domain.SymbolMapping{{BrokerSymbol: "USDJPY.m", Digits: 3, MinLot: 0.01, MaxLot: 100, LotStep: 0.01}}
+	result := overrideSymbolMappings(reported, unmapped, []*domain.SymbolMappingOverride{
+		{AccountID: "s1", CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD.pro"},
+		{AccountID: "s1", CanonicalSymbol: "EURUSD", BrokerSymbol: "GBPUSD.m"},
+		{AccountID: "s1", CanonicalSymbol: "US30", BrokerSymbol: "DJ30"},
+		{AccountID: "s1", CanonicalSymbol: "USDJPY", BrokerSymbol: "USDJPY.m"},
+	})
+	require.Len(t, result, 5)
+
+	assert.Equal(t, "XAUUSD.pro", result[0].BrokerSymbol)
+	assert.Equal(t, int32(2), result[0].Digits, "sin reporte del broker_symbol conserva los parámetros del canónico")
+	assert.Equal(t, &contract, result[0].ContractSize)
+
+	assert.Equal(t, "GBPUSD.m", result[1].BrokerSymbol)
+	assert.Equal(t, 0.1, result[1].MinLot, "usa los parámetros reportados para el broker_symbol fijado")
+
+	assert.Equal(t, "GBPUSD.m", result[2].BrokerSymbol, "el resto de los mapeos no cambia")
+
+	assert.Equal(t, &domain.SymbolMapping{CanonicalSymbol: "US30", BrokerSymbol: "DJ30", Source: domain.SymbolMappingSourceOverride, Confidence: 1}, result[3])
+
+	assert.Equal(t, int32(3), result[4].Digits, "toma los parámetros de un broker_symbol reportado sin mapeo")
+	assert.Equal(t, "USDJPY", result[4].CanonicalSymbol)
+	assert.Equal(t, domain.SymbolMappingSourceOverride, result[4].Source)
+
+	assert.Equal(t, "GOLD", reported[0].BrokerSymbol, "no muta los mapeos reportados")
+	assert.Equal(t, "EURUSD.m", reported[1].BrokerSymbol)
+}
+
+func TestEvaluateTradeableSymbols(t *testing.T) {
+	mappings := map[string]*domain.AccountSymbolInfo{
+		"XAUUSD": {CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD"},
+		"EURUSD": {CanonicalSymbol: "EURUSD", BrokerSymbol: "EURUSD.m"},
+		"GBPUSD": {CanonicalSymbol: "GBPUSD", BrokerSymbol: "GBPUSD.m"},
+	}
+	specs := map[string]*domain.AccountSymbolSpec{
+		"XAUUSD": {CanonicalSymbol: "XAUUSD"},
+		"EURUSD": {CanonicalSymbol: "EURUSD"},
+	}
+	overrides := []*domain.SymbolMappingOverride{{AccountID: "s1", CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD"}}
+	evaluation := &handshake.Evaluation{
+		AccountID:     "s1",
+		Status:        handshake.RegistrationStatusWarning,
+		EvaluatedAtMs: 1000,
+		Entries: []handshake.Entry{
+			{CanonicalSymbol: "XAUUSD", BrokerSymbol: "GOLD", Status: handshake.RegistrationStatusWarning},
+			{CanonicalSymbol: "EURUSD", BrokerSymbol: "EURUSD.m", Status: handshake.RegistrationStatusRejected, Errors: []handshake.Issue{{Message: "spec stale"}}},
+		},
+	}
+
+	result := evaluateTradeableSymbols("s1", []string{"xauusd", "EURUSD", "GBPUSD", "USDJPY", "XAUUSD"}, mappings, specs, overrides, evaluation)
+	assert.Equal(t, "WARNING", result.HandshakeStatus)
+	assert.Equal(t, int64(1000), result.EvaluatedAtMs)
+	require.Len(t, result.Symbols, 4)
+
+	bySymbol := make(map[string]TradeableSymbol)
+	for _, symbol := range result.Symbols {
+		bySymbol[symbol.CanonicalSymbol] = symbol
+	}
+	assert.True(t, bySymbol["XAUUSD"].Tradeable, "WARNING sigue siendo operable")
+	assert.True(t, bySymbol["XAUUSD"].Pinned)
+	assert.Equal(t, "GOLD", bySymbol["XAUUSD"].BrokerSymbol)
+	assert.False(t, bySymbol["EURUSD"].Tradeable)
+	assert.Equal(t, "symbol rejected by handshake: spec stale", bySymbol["EURUSD"].Reason)
+	assert.Equal(t, "no symbol specification reported", bySymbol["GBPUSD"].Reason)
+	assert.Equal(t, "no broker mapping reported", bySymbol["USDJPY"].Reason)
+
+	evaluation.Status = handshake.RegistrationStatusRejected
+	result = evaluateTradeableSymbols("s1", nil, mappings, specs, nil, evaluation)
+	require.Len(t, result.Symbols, 3, "sin whitelist evalúa los canónicos mapeados")
+	assert.Equal(t, "EURUSD", result.Symbols[0].CanonicalSymbol)
+	assert.Equal(t, "account handshake REJECTED", result.Symbols[2].Reason)
+
+	result = evaluateTradeableSymbols("s1", []string{"XAUUSD"}, mappings, specs, nil, nil)
+	assert.Equal(t, "", result.HandshakeStatus)
+	assert.Equal(t, "account has no handshake evaluation", result.Symbols[0].Reason)
+}
+
+func TestDiffSymbolSpecs(t *testing.T) {
+	spec := func(broker string, digits int32, contract, tickValue float64, stops int32) *domain.AccountSymbolSpec {
+		return &domain.AccountSymbolSpec{
+			CanonicalSymbol: "XAUUSD",
+			ReportedAtMs:    1000,
+			Specification: &pb.SymbolSpecification{
+				CanonicalSymbol: "XAUUSD",
+				BrokerSymbol:    broker,
+				General:         &pb.SymbolGeneral{Digits: digits, ContractSize: contract, TickValue: tickValue, StopsLevel: stops, MarginCurrency: "USD"},
+				Volume:          &pb.VolumeSpec{MinVolume: 0.01, MaxVolume: 50, VolumeStep: 0.01},
+			},
+		}
+	}
+
+	left := newSymbolSpecView("s1", spec("GOLD", 2, 100, 1, 10))
+	assert.Equal(t, 100.0, left.ContractSize)
+	assert.Equal(t, 0.01, left.VolumeStep)
+
+	diff := diffSymbolSpecs(left, newSymbolSpecView("s2", spec("XAUUSD.pro", 2, 100, 1, 10)))
+	assert.True(t, diff.Differs(), "broker_symbol distinto")
+	for _, field := range diff.Fields {
+		assert.Equal(t, field.Field != "broker_symbol", field.Equal, field.Field)
+	}
+
+	diff = diffSymbolSpecs(left, newSymbolSpecView("s2", spec("GOLD", 3, 10, 0.1, 0)))
+	changed := make([]string, 0)
+	for _, field := range diff.Fields {
+		if !field.Equal {
+			changed = append(changed, field.Field)
+		}
+	}
+	assert.Equal(t, []string{"digits", "contract_size", "tick_value", "stop_level"}, changed)
+	assert.Equal(t, "0.1", diff.Fields[3].Right)
+
+	empty := newSymbolSpecView("s3", &domain.AccountSymbolSpec{CanonicalSymbol: "XAUUSD"})
+	assert.Equal(t, "", empty.BrokerSymbol)
+	assert.Equal(t, "XAUUSD", empty.CanonicalSymbol)
+}
@@ -0,0 +1,279 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+// Reglas del matcher y su confianza base (i8).
+const (
+	symbolMatchRuleExact = "exact" // El broker_symbol ya es un canónico de la whitelist
+	symbolMatchRuleStrip = "strip" // Canónico tras quitar prefijos/sufijos configurados
+	symbolMatchRuleAlias = "alias" // Tabla de alias (GOLD → XAUUSD), con o sin prefijos/sufijos
+
+	symbolMatchConfidenceExact = 1.0
+	symbolMatchConfidenceStrip = 0.9
+	symbolMatchConfidenceAlias = 0.8
+
+	// Ajuste por cada comprobación del fingerprint; sin dato la comprobación no cuenta.
+	symbolMatchCheckBonus   = 0.05
+	symbolMatchCheckPenalty = 0.25
+)
+
+// symbolMatcher deduce el canónico de los broker symbols que el EA reporta sin
+// canonical_symbol (i8).
+//
+// Cada símbolo pasa por las reglas exact, strip y alias (gana la de mayor confianza
+// base) y luego por el fingerprint: base/profit currency contra el nombre del
+// canónico y digits/contract_size contra el mismo canónico en otra cuenta. Con
+// confianza >= auto_accept se agrega como mapeo (source=matcher); con >= min_confidence
+// queda como propuesta para el operador; por debajo se descarta.
+type symbolMatcher struct {
+	canonicals    map[string]struct{}
+	prefixes      []string
+	suffixes      []string
+	aliases       map[string]string
+	autoAccept    float64
+	minConfidence float64
+}
+
+// symbolMatchResult resultado del matcher para un AccountSymbolsReport.
+type symbolMatchResult struct {
+	Accepted  []*domain.SymbolMapping
+	Proposals []*domain.SymbolMatchProposal
+}
+
+type symbolMatchCandidate struct {
+	mapping    *domain.SymbolMapping
+	canonical  string
+	rule       string
+	confidence float64
+	checks     []string
+}
+
+func newSymbolMatcher(config SymbolMatcherConfig, canonicals []string) *symbolMatcher {
+	m := &symbolMatcher{
+		canonicals:    make(map[string]struct{}, len(canonicals)),
+		aliases:       make(map[string]string, len(config.Aliases)),
+		autoAccept:    config.AutoAcceptConfidence,
+		minConfidence: config.MinConfidence,
+	}
+	for _, canonical := range canonicals {
+		m.canonicals[strings.ToUpper(strings.TrimSpace(canonical))] = struct{}{}
+	}
+	for alias, canonical := range config.Aliases {
+		m.aliases[strings.ToUpper(alias)] = strings.ToUpper(canonical)
+	}
+	m.prefixes = normalizeSymbolAffixes(config.Prefixes)
+	m.suffixes = normalizeSymbolAffixes(config.Suffixes)
+	return m
+}
+
+// normalizeSymbolAffixes pasa a mayúsculas y ordena de mayor a menor largo para que
+// ".pro" se pruebe antes que "pro".
+func normalizeSymbolAffixes(affixes []string) []string {
+	normalized := make([]string, 0, len(affixes))
+	for _, affix := range affixes {
+		if affix = strings.ToUpper(strings.TrimSpace(affix)); affix != "" {
+			normalized = append(normalized, affix)
+		}
+	}
+	sort.SliceStable(normalized, func(i, j int) bool {
+		return len(normalized[i]) > len(normalized[j])
+	})
+	return normalized
+}
+
+// Match evalúa los símbolos sin mapeo de la cuenta.
+//
+// Se omiten los canónicos ya mapeados por el EA o fijados por un override, y los
+// broker symbols fijados por un override. Por canónico queda solo el mejor candidato.
+// reference retorna el mapeo del canónico en otra cuenta (nil si no hay).
+func (m *symbolMatcher) Match(
+	accountID string,
+	unmapped, mappings []*domain.SymbolMapping,
+	overrides []*domain.SymbolMappingOverride,
+	reference func(canonical string) *domain.AccountSymbolInfo,
+	nowMs int64,
+) *symbolMatchResult {
+	taken := make(map[string]struct{}, len(mappings)+len(overrides))
+	pinnedBrokers := make(map[string]struct{}, len(overrides))
+	for _, mapping := range mappings {
+		taken[mapping.CanonicalSymbol] = struct{}{}
+	}
+	for _, override := range overrides {
+		taken[override.CanonicalSymbol] = struct{}{}
+		pinnedBrokers[override.BrokerSymbol] = struct{}{}
+	}
+
+	best := make(map[string]*symbolMatchCandidate)
+	for _, mapping := range unmapped {
+		if _, pinned := pinnedBrokers[mapping.BrokerSymbol]; pinned {
+			continue
+		}
+		candidate := m.candidate(mapping)
+		if candidate == nil {
+			continue
+		}
+		if _, ok := taken[candidate.canonical]; ok {
+			continue
+		}
+
+		var ref *domain.AccountSymbolInfo
+		if reference != nil {
+			ref = reference(candidate.canonical)
+		}
+		candidate.checks = fingerprintSymbolMatch(candidate, ref)
+
+		current, ok := best[candidate.canonical]
+		if !ok || candidate.confidence > current.confidence ||
+			(candidate.confidence == current.confidence && candidate.mapping.BrokerSymbol < current.mapping.BrokerSymbol) {
+			best[candidate.canonical] = candidate
+		}
+	}
+
+	canonicals := make([]string, 0, len(best))
+	for canonical := range best {
+		canonicals = append(canonicals, canonical)
+	}
+	sort.Strings(canonicals)
+
+	result := &symbolMatchResult{}
+	for _, canonical := range canonicals {
+		candidate := best[canonical]
+		switch {
+		case candidate.confidence >= m.autoAccept:
+			accepted := *candidate.mapping
+			accepted.CanonicalSymbol = canonical
+			accepted.Source = domain.SymbolMappingSourceMatcher
+			accepted.Confidence = candidate.confidence
+			result.Accepted = append(result.Accepted, &accepted)
+		case candidate.confidence >= m.minConfidence:
+			result.Proposals = append(result.Proposals, &domain.SymbolMatchProposal{
+				AccountID:       accountID,
+				BrokerSymbol:    candidate.mapping.BrokerSymbol,
+				CanonicalSymbol: canonical,
+				Confidence:      candidate.confidence,
+				Rule:            candidate.rule,
+				Checks:          candidate.checks,
+				ProposedAtMs:    nowMs,
+			})
+		}
+	}
+	return result
+}
+
+// candidate aplica las reglas por nombre y retorna la de mayor confianza base.
+func (m *symbolMatcher) candidate(mapping *domain.SymbolMapping) *symbolMatchCandidate {
+	name := strings.ToUpper(strings.TrimSpace(mapping.BrokerSymbol))
+	if name == "" {
+		return nil
+	}
+	if _, ok := m.canonicals[name]; ok {
+		return &symbolMatchCandidate{mapping: mapping, canonical: name, rule: symbolMatchRuleExact, confidence: symbolMatchConfidenceExact}
+	}
+
+	stripped := m.strip(name)
+	for _, base := range stripped {
+		if _, ok := m.canonicals[base]; ok {
+			return &symbolMatchCandidate{mapping: mapping, canonical: base, rule: symbolMatchRuleStrip, confidence: symbolMatchConfidenceStrip}
+		}
+	}
+	for _, base := range append([]string{name}, stripped...) {
+		canonical, ok := m.aliases[base]
+		if !ok {
+			continue
+		}
+		if _, ok := m.canonicals[canonical]; ok {
+			return &symbolMatchCandidate{mapping: mapping, canonical: canonical, rule: symbolMatchRuleAlias, confidence: symbolMatchConfidenceAlias}
+		}
+	}
+	return nil
+}
+
+// strip retorna los nombres que resultan de quitar un prefijo, un sufijo o ambos.
+func (m *symbolMatcher) strip(name string) []string {
+	var names []string
+	seen := map[string]struct{}{name: {}}
+	add := func(candidate string) {
+		if candidate == "" {
+			return
+		}
+		if _, ok := seen[candidate]; ok {
+			return
+		}
+		seen[candidate] = struct{}{}
+		names = append(names, candidate)
+	}
+
+	heads := []string{name}
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			head := strings.TrimPrefix(name, prefix)
+			add(head)
+			heads = append(heads, head)
+		}
+	}
+	for _, head := range heads {
+		for _, suffix := range m.suffixes {
+			if strings.HasSuffix(head, suffix) {
+				add(strings.TrimSuffix(head, suffix))
+			}
+		}
+	}
+	return names
+}
+
+// fingerprintSymbolMatch ajusta la confianza del candidato según su especificación
+// y retorna el detalle de cada comprobación.
+func fingerprintSymbolMatch(candidate *symbolMatchCandidate, reference *domain.AccountSymbolInfo) []string {
+	checks := make([]string, 0, 4)
+	check := func(field, got, want string) {
+		if got == want {
+			candidate.confidence += symbolMatchCheckBonus
+			checks = append(checks, field+" ok")
+			return
+		}
+		candidate.confidence -= symbolMatchCheckPenalty
+		checks = append(checks, fmt.Sprintf("%s %s≠%s", field, got, want))
+	}
+
+	mapping := candidate.mapping
+	if base, profit, ok := symbolPairCurrencies(candidate.canonical); ok {
+		if mapping.BaseCurrency != "" {
+			check("base_currency", strings.ToUpper(mapping.BaseCurrency), base)
+		}
+		if mapping.ProfitCurrency != "" {
+			check("profit_currency", strings.ToUpper(mapping.ProfitCurrency), profit)
+		}
+	}
+	if reference != nil {
+		if mapping.Digits > 0 && reference.Digits > 0 {
+			check("digits", strconv.Itoa(int(mapping.Digits)), strconv.Itoa(int(reference.Digits)))
+		}
+		if mapping.ContractSize != nil && reference.ContractSize != nil && *mapping.ContractSize > 0 && *reference.ContractSize > 0 {
+			check("contract_size", strconv.FormatFloat(*mapping.ContractSize, 'f', -1, 64), strconv.FormatFloat(*reference.ContractSize, 'f', -1, 64))
+		}
+	}
+
+	candidate.confidence = math.Round(math.Max(0, math.Min(1, candidate.confidence))*100) / 100
+	return checks
+}
+
+// symbolPairCurrencies separa un canónico de 6 letras (EURUSD, XAUUSD) en base y profit.
+func symbolPairCurrencies(canonical string) (string, string, bool) {
+	if len(canonical) != 6 {
+		return "", "", false
+	}
+	for _, r := range canonical {
+		if r < 'A' || r > 'Z' {
+			return "", "", false
+		}
+	}
+	return canonical[:3], canonical[3:], true
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xKoRx/echo/sdk/domain"
+)
+
+func newTestSymbolMatcher() *symbolMatcher {
+	return newSymbolMatcher(SymbolMatcherConfig{
+		Prefixes:             []string{"#", "_"},
+		Suffixes:             []string{"pro", ".pro", "m", ".m", ".ecn"},
+		Aliases:              map[string]string{"gold": "xauusd", "US30": "DJ30", "SILVER": "XAGUSD"},
+		AutoAcceptConfidence: 0.9,
+		MinConfidence:        0.5,
+	}, []string{"EURUSD", "XAUUSD", "DJ30", "GBPUSD", "USDJPY"})
+}
+
+func TestSymbolMatcherRules(t *testing.T) {
+	matcher := newTestSymbolMatcher()
+
+	cases := []struct {
+		broker    string
+		canonical string
+		rule      string
+	}{
+		{"gbpusd", "GBPUSD", symbolMatchRuleExact},
+		{"EURUSD.m", "EURUSD", symbolMatchRuleStrip},
+		{"EURUSDpro", "EURUSD", symbolMatchRuleStrip},
+		{"#XAUUSD", "XAUUSD", symbolMatchRuleStrip},
+		{"_USDJPY.ecn", "USDJPY", symbolMatchRuleStrip},
+		{"GOLD", "XAUUSD", symbolMatchRuleAlias},
+		{"#GOLD.m", "XAUUSD", symbolMatchRuleAlias},
+		{"US30", "DJ30", symbolMatchRuleAlias},
+		{"SILVER", "", ""}, // Alias a un canónico fuera de la whitelist
+		{"AUDCAD.m", "", ""},
+		{".m", "", ""},
+	}
+	for _, tc := range cases {
+		candidate := matcher.candidate(&domain.SymbolMapping{BrokerSymbol: tc.broker})
+		if tc.canonical == "" {
+			assert.Nil(t, candidate, tc.broker)
+			continue
+		}
+		require.NotNil(t, candidate, tc.broker)
+		assert.Equal(t, tc.canonical, candidate.canonical, tc.broker)
+		assert.Equal(t, tc.rule, candidate.rule, tc.broker)
+	}
+}
+
+func TestSymbolMatcherMatch(t *testing.T) {
+	matcher := newTestSymbolMatcher()
+	contract := 100.0
+	otherContract := 1.0
+	lotContract := 100000.0
+	references := map[string]*domain.AccountSymbolInfo{
+		"XAUUSD": {CanonicalSymbol: "XAUUSD", BrokerSymbol: "XAUUSD", Digits: 2, ContractSize: &contract},
+		"USDJPY": {CanonicalSymbol: "USDJPY", BrokerSymbol: "USDJPY", Digits: 3, ContractSize: &lotContract},
+	}
+	reference := func(canonical string) *domain.AccountSymbolInfo { return references[canonical] }
+
+	unmapped := []*domain.SymbolMapping{
+		{BrokerSymbol: "GOLD", Digits: 2, ContractSize: &contract, BaseCurrency: "XAU", ProfitCurrency: "USD"},
+		{BrokerSymbol: "#GOLD", Digits: 2},
+		{BrokerSymbol: "USDJPY.m", Digits: 5, ContractSize: &otherContract},
+		{BrokerSymbol: "EURUSD.m", Digits: 5},
+		{BrokerSymbol: "DJ30.pro", BaseCurrency: "EUR", ProfitCurrency: "JPY"},
+		{BrokerSymbol: "GBPUSDpro"},
+	}
+	mappings := []*domain.SymbolMapping{{CanonicalSymbol: "EURUSD", BrokerSymbol: "EURUSD"}}
+	overrides := []*domain.SymbolMappingOverride{{AccountID: "s1", CanonicalSymbol: "USDCHF", BrokerSymbol: "GBPUSDpro"}}
+
+	result := matcher.Match("s1", unmapped, mappings, overrides, reference, 1000)
+
+	require.Len(t, result.Accepted, 2)
+	assert.Equal(t, "DJ30", result.Accepted[0].CanonicalSymbol, "sin fingerprint aplicable conserva la confianza base")
+	assert.Equal(t, 0.9, result.Accepted[0].Confidence)
+	assert.Equal(t, "XAUUSD", result.Accepted[1].CanonicalSymbol)
+	assert.Equal(t, "GOLD", result.Accepted[1].BrokerSymbol, "gana el candidato con mejor fingerprint")
+	assert.Equal(t, 1.0, result.Accepted[1].Confidence)
+	assert.Equal(t, domain.SymbolMappingSourceMatcher, result.Accepted[1].Source)
+	assert.Equal(t, int32(2), result.Accepted[1].Digits, "conserva los parámetros reportados")
+	assert.Equal(t, "", unmapped[0].CanonicalSymbol, "no modifica los símbolos recibidos")
+
+	require.Len(t, result.Proposals, 0, "USDJPY.m queda por debajo de min_confidence")
+
+	matcher.minConfidence = 0.3
+	result = matcher.Match("s1", unmapped, mappings, overrides, reference, 1000)
+	require.Len(t, result.Proposals, 1)
+	proposal := result.Proposals[0]
+	assert.Equal(t, "USDJPY.m", proposal.BrokerSymbol)
+	assert.Equal(t, "USDJPY", proposal.CanonicalSymbol)
+	assert.Equal(t, symbolMatchRuleStrip, proposal.Rule)
+	assert.Equal(t, 0.4, proposal.Confidence)
+	assert.Equal(t, []string{"digits 5≠3", "contract_size 1≠100000"}, proposal.Checks)
+	assert.Equal(t, int64(1000), proposal.ProposedAtMs)
+	assert.Equal(t, "s1", proposal.AccountID)
+}
+
+func TestFingerprintSymbolMatch(t *testing.T) {
+	contract := 100000.0
+	candidate := &symbolMatchCandidate{
+		mapping:    &domain.SymbolMapping{BrokerSymbol: "GBPUSD.m", Digits: 5, ContractSize: &contract, BaseCurrency: "gbp", ProfitCurrency: "EUR"},
+		canonical:  "GBPUSD",
+		confidence: symbolMatchConfidenceStrip,
+	}
+	checks := fingerprintSymbolMatch(candidate, &domain.AccountSymbolInfo{Digits: 5, ContractSize: &contract})
+	assert.Equal(t, []string{"base_currency ok", "profit_currency EUR≠USD", "digits ok", "contract_size ok"}, checks)
+	assert.Equal(t, 0.8, candidate.confidence)
+
+	candidate = &symbolMatchCandidate{mapping: &domain.SymbolMapping{BrokerSymbol: "DJ30", BaseCurrency: "USD"}, canonical: "DJ30", confidence: symbolMatchConfidenceExact}
+	assert.Empty(t, fingerprintSymbolMatch(candidate, nil), "sin divisas para canónicos que no son pares")
+	assert.Equal(t, 1.0, candidate.confidence)
+}
@@ -91,6 +91,47 @@ func (s *SymbolQuoteService) Get(accountID, canonical string) (*pb.SymbolQuoteSn
 	return protoCloneQuote(entry.quote), true
 }
 
+// Warm hidrata el caché con el último snapshot persistido si no existe en memoria.
+//
+// Retorna true si el snapshot queda disponible en caché.
+func (s *SymbolQuoteService) Warm(ctx context.Context, accountID, canonical string) bool {
+	if _, ok := s.Get(accountID, canonical); ok {
+		return true
+	}
+	if s.repo == nil || accountID == "" || canonical == "" {
+		return false
+	}
+
+	snapshot, err := s.repo.GetLatestSnapshot(ctx, accountID, canonical)
+	if err != nil {
+		if s.telemetry != nil {
+			s.telemetry.Warn(ctx, "Failed to warm symbol quote snapshot",
+				attribute.String("account_id", accountID),
+				attribute.String("canonical_symbol", canonical),
+				attribute.String("error", err.Error()),
+			)
+		}
+		return false
+	}
+	if snapshot == nil {
+		return false
+	}
+
+	s.mu.Lock()
+	if s.quotes[accountID] == nil {
+		s.quotes[accountID] = make(map[string]*quoteCacheEntry)
+	}
+	if entry := s.quotes[accountID][canonical]; entry == nil || entry.timestampMs <= snapshot.TimestampMs {
+		s.quotes[accountID][canonical] = &quoteCacheEntry{
+			quote:       protoCloneQuote(snapshot),
+			timestampMs: snapshot.TimestampMs,
+		}
+	}
+	s.mu.Unlock()
+
+	return true
+}
+
 // Invalidate limpia el caché para la cuenta (persistencia se mantiene).
 func (s *SymbolQuoteService) Invalidate(accountID string) {
 	s.mu.Lock()
@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/xKoRx/echo/sdk/domain"
 	"github.com/xKoRx/echo/sdk/telemetry"
 	"github.com/xKoRx/echo/sdk/telemetry/metricbundle"
@@ -72,6 +73,40 @@ func (r *AccountSymbolResolver) Stop() {
 	r.wg.Wait()
 }
 
+// StartListener escucha echo_symbol_mapping_updated (PostgreSQL) e invalida el caché
+// de la cuenta notificada: overrides escritos por echo-core-cli symbols pin/unpin (i8).
+//
+// El listener se detiene con Stop.
+func (r *AccountSymbolResolver) StartListener(connStr string) error {
+	if connStr == "" {
+		return nil
+	}
+
+	listener := pq.NewListener(connStr, 5*time.Second, time.Minute, nil)
+	if err := listener.Listen("echo_symbol_mapping_updated"); err != nil {
+		listener.Close()
+		return err
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer listener.Close()
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case notification := <-listener.Notify:
+				if notification == nil || notification.Extra == "" {
+					continue
+				}
+				_ = r.InvalidateAccount(r.ctx, notification.Extra)
+			}
+		}
+	}()
+	return nil
+}
+
 // persistWorker procesa solicitudes de persistencia de forma async (i3).
 func (r *AccountSymbolResolver) persistWorker() {
 	defer r.wg.Done()
@@ -262,3 +297,30 @@ func (r *AccountSymbolResolver) ListMappings(ctx context.Context, accountID stri
 	return result, nil
 }
 
+// ReferenceMapping retorna el mapeo del canónico en otra cuenta en caché (i8).
+//
+// Sirve de huella de referencia (digits, contract_size) al matcher de símbolos. Se
+// ignoran mapeos deducidos por el propio matcher y la cuenta excludeAccountID; con
+// varias candidatas gana la de account_id menor para que el resultado sea estable.
+func (r *AccountSymbolResolver) ReferenceMapping(canonical, excludeAccountID string) *domain.AccountSymbolInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		reference   *domain.AccountSymbolInfo
+		referenceID string
+	)
+	for accountID, accountMap := range r.cache {
+		if accountID == excludeAccountID {
+			continue
+		}
+		info, ok := accountMap[canonical]
+		if !ok || info.Source == domain.SymbolMappingSourceMatcher {
+			continue
+		}
+		if reference == nil || accountID < referenceID {
+			reference, referenceID = info, accountID
+		}
+	}
+	return reference
+}
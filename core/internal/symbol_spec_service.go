@@ -27,6 +27,7 @@ type SymbolSpecService struct {
 	metrics   *metricbundle.EchoMetrics
 	whitelist []string
 	onChange  func(string)
+	clock     func() time.Time // Edad de las specs; nil usa time.Now (el replay usa la hora del registro)
 }
 
 func NewSymbolSpecService(repo domain.SymbolSpecRepository, tel *telemetry.Client, metrics *metricbundle.EchoMetrics, whitelist []string) *SymbolSpecService {
@@ -36,6 +37,7 @@ func NewSymbolSpecService(repo domain.SymbolSpecRepository, tel *telemetry.Clien
 		telemetry: tel,
 		metrics:   metrics,
 		whitelist: whitelist,
+		clock:     time.Now,
 	}
 }
 
@@ -150,6 +152,13 @@ func (s *SymbolSpecService) GetVolumeSpec(ctx context.Context, accountID, canoni
 	return proto.Clone(spec.Volume).(*pb.VolumeSpec), reportedAt, true
 }
 
+func (s *SymbolSpecService) now() time.Time {
+	if s.clock == nil {
+		return time.Now()
+	}
+	return s.clock()
+}
+
 // IsStale indica si la especificación está vencida según el maxAge provisto.
 func (s *SymbolSpecService) IsStale(accountID, canonical string, maxAge time.Duration) bool {
 	if maxAge <= 0 {
@@ -169,7 +178,7 @@ func (s *SymbolSpecService) IsStale(accountID, canonical string, maxAge time.Dur
 	}
 
 	reportedAt := time.UnixMilli(entry.reportedAtMs)
-	age := time.Since(reportedAt)
+	age := s.now().Sub(reportedAt)
 	return age > maxAge
 }
 
@@ -187,7 +196,7 @@ func (s *SymbolSpecService) SpecAge(accountID, canonical string) (time.Duration,
 		return 0, false
 	}
 
-	return time.Since(time.UnixMilli(entry.reportedAtMs)), true
+	return s.now().Sub(time.UnixMilli(entry.reportedAtMs)), true
 }
 
 // Invalidate elimina especificaciones en caché para la cuenta (mantiene persistencia).
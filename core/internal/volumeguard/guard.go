@@ -41,7 +41,12 @@ type guard struct {
 
 // New crea un guardián de volumen.
 func New(specs SpecProvider, policy *domain.VolumeGuardPolicy, tel *telemetry.Client, metrics *metricbundle.EchoMetrics) Guard {
-	clk := time.Now
+	return NewWithClock(specs, policy, tel, metrics, time.Now)
+}
+
+// NewWithClock crea un guardián de volumen que mide la edad de las specs con clk
+// (el replay del journal usa la hora de llegada de cada registro).
+func NewWithClock(specs SpecProvider, policy *domain.VolumeGuardPolicy, tel *telemetry.Client, metrics *metricbundle.EchoMetrics, clk func() time.Time) Guard {
 	return &guard{
 		specs:     specs,
 		policy:    policy,
@@ -415,12 +415,14 @@ func parseHandshakeSymbols(value interface{}) []*pb.SymbolMapping {
 			continue
 		}
 		canonical := strings.TrimSpace(utils.ExtractString(entryMap, "canonical_symbol"))
-		if canonical == "" {
+		broker := strings.TrimSpace(utils.ExtractString(entryMap, "broker_symbol"))
+		// i8: sin canonical_symbol se reenvía igual; el matcher del Core lo resuelve
+		if canonical == "" && broker == "" {
 			continue
 		}
 		mapping := &pb.SymbolMapping{
 			CanonicalSymbol: canonical,
-			BrokerSymbol:    strings.TrimSpace(utils.ExtractString(entryMap, "broker_symbol")),
+			BrokerSymbol:    broker,
 			Digits:          int32(utils.ExtractInt64(entryMap, "digits")),
 			Point:           utils.ExtractFloat64(entryMap, "point"),
 			TickSize:        utils.ExtractFloat64(entryMap, "tick_size"),
@@ -428,6 +430,8 @@ func parseHandshakeSymbols(value interface{}) []*pb.SymbolMapping {
 			MaxLot:          utils.ExtractFloat64(entryMap, "max_lot"),
 			LotStep:         utils.ExtractFloat64(entryMap, "lot_step"),
 			StopLevel:       int32(utils.ExtractInt64(entryMap, "stop_level")),
+			BaseCurrency:    strings.TrimSpace(utils.ExtractString(entryMap, "base_currency")),
+			ProfitCurrency:  strings.TrimSpace(utils.ExtractString(entryMap, "profit_currency")),
 		}
 		if contract, ok := entryMap["contract_size"].(float64); ok {
 			mapping.ContractSize = &contract
@@ -37,6 +37,16 @@ func TestNormalizeHandshakePayload_V2Success(t *testing.T) {
 					"stop_level":       float64(10),
 					"contract_size":    float64(100),
 				},
+				map[string]interface{}{
+					"broker_symbol":   "EURUSDpro",
+					"digits":          float64(5),
+					"min_lot":         float64(0.01),
+					"base_currency":   "EUR",
+					"profit_currency": "USD",
+				},
+				map[string]interface{}{
+					"digits": float64(5),
+				},
 			},
 		},
 	}
@@ -54,10 +64,14 @@ func TestNormalizeHandshakePayload_V2Success(t *testing.T) {
 	require.Equal(t, 1415, got.TerminalBuild)
 	require.Equal(t, "slave", got.PipeRole)
 	require.Equal(t, int64(1730851200123), got.TimestampMs)
-	require.Len(t, got.Symbols, 1)
+	require.Len(t, got.Symbols, 2, "sin canonical ni broker se descarta")
 	require.Equal(t, "XAUUSD", got.Symbols[0].CanonicalSymbol)
 	require.NotNil(t, got.Symbols[0].ContractSize)
 	require.Equal(t, 100.0, *got.Symbols[0].ContractSize)
+	require.Equal(t, "", got.Symbols[1].CanonicalSymbol, "sin mapeo lo resuelve el matcher del Core")
+	require.Equal(t, "EURUSDpro", got.Symbols[1].BrokerSymbol)
+	require.Equal(t, "EUR", got.Symbols[1].BaseCurrency)
+	require.Equal(t, "USD", got.Symbols[1].ProfitCurrency)
 
 	meta := got.ToProtoMetadata()
 	require.NotNil(t, meta)
@@ -78,6 +78,11 @@ type Execution struct {
 	// Latencia E2E (timestamps t0..t7)
 	TimestampsMs map[string]int64 `json:"timestamps_ms" db:"timestamps_ms"` // JSONB con t0..t7
 
+	// Política de riesgo que dimensionó la orden (i7)
+	StrategyID        string `json:"strategy_id,omitempty" db:"strategy_id"`                 // Estrategia del intent
+	RiskPolicyType    string `json:"risk_policy_type,omitempty" db:"risk_policy_type"`       // FIXED_LOT/FIXED_RISK
+	RiskPolicyVersion *int64 `json:"risk_policy_version,omitempty" db:"risk_policy_version"` // Versión del historial
+
 	// Timestamps
 	CreatedAt time.Time `json:"created_at" db:"created_at"` // Timestamp de creación
 }
@@ -108,11 +113,241 @@ type Close struct {
 	ErrorCode    string   `json:"error_code" db:"error_code"`             // Código de error
 	ErrorMessage string   `json:"error_message" db:"error_message"`       // Mensaje de error
 
+	// P&L reportado por el slave (i8). Montos en moneda de la cuenta; NULL si no se reportó.
+	// MT4 reporta commission y swap con signo (negativo = costo).
+	Profit          *float64 `json:"profit,omitempty" db:"profit"`                     // Profit bruto del volumen cerrado
+	Commission      *float64 `json:"commission,omitempty" db:"commission"`             // Comisión del volumen cerrado
+	Swap            *float64 `json:"swap,omitempty" db:"swap"`                         // Swap acumulado del volumen cerrado
+	ClosedVolume    *float64 `json:"closed_volume,omitempty" db:"closed_volume"`       // Lotes cerrados
+	RemainingVolume *float64 `json:"remaining_volume,omitempty" db:"remaining_volume"` // Lotes abiertos tras el cierre
+
 	// Timestamps
 	ClosedAtMs int64     `json:"closed_at_ms" db:"closed_at_ms"` // Timestamp de cierre
 	CreatedAt  time.Time `json:"created_at" db:"created_at"`     // Timestamp de creación
 }
 
+// IsPartial indica si el cierre dejó volumen abierto en el slave (i8).
+func (c *Close) IsPartial() bool {
+	return c.Success && c.RemainingVolume != nil && *c.RemainingVolume > 0
+}
+
+// Modify representa un cambio de SL/TP de un trade reportado por el master (i8).
+// Corresponde a la tabla `echo.trade_modifies` en PostgreSQL.
+type Modify struct {
+	// Identidad
+	ModifyID string `json:"modify_id" db:"modify_id"` // UUID generado por el Core
+	TradeID  string `json:"trade_id" db:"trade_id"`   // FK a trades
+
+	// Master info
+	MasterTicket int32 `json:"master_ticket" db:"master_ticket"` // Ticket modificado en el master
+
+	// Niveles nuevos (NULL = sin cambio reportado)
+	NewStopLoss   *float64 `json:"new_stop_loss,omitempty" db:"new_stop_loss"`
+	NewTakeProfit *float64 `json:"new_take_profit,omitempty" db:"new_take_profit"`
+
+	// Timestamps
+	ModifiedAtMs int64     `json:"modified_at_ms" db:"modified_at_ms"` // timestamp_ms del TradeModify
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`         // Timestamp de creación
+}
+
+// TradeLifecycleStatus estado del ciclo de vida de un trade en un slave (i8).
+type TradeLifecycleStatus string
+
+const (
+	// TradeLifecycleStatusPending sin fill de apertura registrado.
+	TradeLifecycleStatusPending TradeLifecycleStatus = "PENDING"
+	// TradeLifecycleStatusRejected apertura rechazada por el slave.
+	TradeLifecycleStatusRejected TradeLifecycleStatus = "REJECTED"
+	// TradeLifecycleStatusOpen abierta, sin cierres exitosos.
+	TradeLifecycleStatusOpen TradeLifecycleStatus = "OPEN"
+	// TradeLifecycleStatusPartiallyClosed con cierres parciales y volumen abierto.
+	TradeLifecycleStatusPartiallyClosed TradeLifecycleStatus = "PARTIALLY_CLOSED"
+	// TradeLifecycleStatusClosed cierre final registrado.
+	TradeLifecycleStatusClosed TradeLifecycleStatus = "CLOSED"
+)
+
+// TradeLifecycle vista del ciclo de vida de un trade en un slave (i8).
+//
+// Se compone desde echo.executions (fill de apertura), echo.trade_modifies (cambios
+// de SL/TP del master) y echo.closes (cierres parciales y cierre final). Los
+// acumulados solo consideran cierres exitosos.
+type TradeLifecycle struct {
+	TradeID        string               `json:"trade_id"`
+	SlaveAccountID string               `json:"slave_account_id"`
+	Status         TradeLifecycleStatus `json:"status"`
+
+	// Open fill de apertura (nil si no hay ejecución registrada).
+	Open *Execution `json:"open,omitempty"`
+
+	// Modifies cambios de SL/TP del trade ordenados por modified_at_ms ASC.
+	Modifies []*Modify `json:"modifies,omitempty"`
+
+	// Closes intentos de cierre ordenados por closed_at_ms ASC (incluye fallidos).
+	Closes []*Close `json:"closes,omitempty"`
+
+	// Acumulados de cierres exitosos
+	ClosedVolume float64 `json:"closed_volume"`
+	Profit       float64 `json:"profit"`
+	Commission   float64 `json:"commission"`
+	Swap         float64 `json:"swap"`
+
+	// HoldingTimeMs desde el fill de apertura hasta el cierre final (0 si sigue abierta).
+	HoldingTimeMs int64 `json:"holding_time_ms"`
+}
+
+// NetProfit retorna profit + commission + swap realizados.
+func (l *TradeLifecycle) NetProfit() float64 {
+	return l.Profit + l.Commission + l.Swap
+}
+
+// OutboxStatus estado de un comando en el outbox transaccional (i8).
+type OutboxStatus string
+
+const (
+	// OutboxStatusPending persistido, aún no entregado a un Agent.
+	OutboxStatusPending OutboxStatus = "PENDING"
+	// OutboxStatusDispatched encolado al Agent owner, esperando ack.
+	OutboxStatusDispatched OutboxStatus = "DISPATCHED"
+	// OutboxStatusAcked resultado recibido del slave.
+	OutboxStatusAcked OutboxStatus = "ACKED"
+	// OutboxStatusExpired superó la antigüedad máxima sin ack.
+	OutboxStatusExpired OutboxStatus = "EXPIRED"
+)
+
+// IsTerminal retorna true si el comando ya no se entrega.
+func (s OutboxStatus) IsTerminal() bool {
+	return s == OutboxStatusAcked || s == OutboxStatusExpired
+}
+
+// OutboxCommand representa un comando hacia un Agent persistido en el outbox (i8).
+// Corresponde a la tabla `echo.command_outbox` en PostgreSQL.
+type OutboxCommand struct {
+	// Identidad
+	CommandID string `json:"command_id" db:"command_id"` // UUID del comando
+	TradeID   string `json:"trade_id" db:"trade_id"`     // FK a trades
+
+	// Destino y contenido
+	TargetAccountID string `json:"target_account_id" db:"target_account_id"` // Cuenta slave destino
+	CommandType     string `json:"command_type" db:"command_type"`           // execute_order
+	Payload         []byte `json:"payload" db:"payload"`                     // Comando protobuf serializado
+
+	// Auditoría del ExecuteOrder: restaura el CommandContext tras un reinicio
+	StrategyID        string  `json:"strategy_id,omitempty" db:"strategy_id"`                 // Estrategia del intent
+	RiskPolicyType    string  `json:"risk_policy_type,omitempty" db:"risk_policy_type"`       // FIXED_LOT/FIXED_RISK
+	RiskPolicyVersion int64   `json:"risk_policy_version,omitempty" db:"risk_policy_version"` // 0 = sin política
+	Symbol            string  `json:"symbol,omitempty" db:"symbol"`                           // Símbolo canónico del trade
+	ReferencePrice    float64 `json:"reference_price,omitempty" db:"reference_price"`         // Precio del master (slippage)
+	Point             float64 `json:"point,omitempty" db:"point"`                             // Point del slave (slippage)
+
+	// Entrega
+	Status         OutboxStatus `json:"status" db:"status"`
+	Attempts       int          `json:"attempts" db:"attempts"`                 // Entregas al Agent
+	LastAgentID    string       `json:"last_agent_id" db:"last_agent_id"`       // Último Agent que lo recibió
+	CreatedAtMs    int64        `json:"created_at_ms" db:"created_at_ms"`       // Alta en el outbox
+	DispatchedAtMs int64        `json:"dispatched_at_ms" db:"dispatched_at_ms"` // Última entrega (0 si nunca)
+	CompletedAtMs  int64        `json:"completed_at_ms" db:"completed_at_ms"`   // Ack o expiración (0 si pendiente)
+}
+
+// LivenessStatus estado de liveness de un Agent o de una cuenta (i8).
+type LivenessStatus string
+
+const (
+	// LivenessStatusAlive heartbeats dentro del plazo (cuenta: EA conectado).
+	LivenessStatusAlive LivenessStatus = "ALIVE"
+	// LivenessStatusStale sin heartbeat tras core/liveness/missed_beats intervalos.
+	LivenessStatusStale LivenessStatus = "STALE"
+	// LivenessStatusDisconnected stream cerrado (cuenta: EA desconectado del pipe).
+	LivenessStatusDisconnected LivenessStatus = "DISCONNECTED"
+)
+
+// AgentLiveness último estado de liveness de un Agent según sus heartbeats (i8).
+// Corresponde a la tabla `echo.agent_liveness` en PostgreSQL.
+type AgentLiveness struct {
+	AgentID             string         `json:"agent_id" db:"agent_id"`
+	Status              LivenessStatus `json:"status" db:"status"`
+	LastSeenAtMs        int64          `json:"last_seen_at_ms" db:"last_seen_at_ms"`             // Último heartbeat recibido
+	HeartbeatIntervalMs int64          `json:"heartbeat_interval_ms" db:"heartbeat_interval_ms"` // Intervalo reportado por el Agent
+	PendingCommands     int            `json:"pending_commands" db:"pending_commands"`           // Store-and-forward del Agent
+	PendingBytes        int64          `json:"pending_bytes" db:"pending_bytes"`
+	StaleSinceMs        int64          `json:"stale_since_ms" db:"stale_since_ms"` // 0 si no está stale
+	UpdatedAtMs         int64          `json:"updated_at_ms" db:"updated_at_ms"`
+
+	// Pipes reportados en el último heartbeat (tabla `echo.account_liveness`)
+	Accounts []*AccountLiveness `json:"accounts"`
+}
+
+// AccountLiveness estado de un pipe de cuenta según el último heartbeat de su Agent (i8).
+// Corresponde a la tabla `echo.account_liveness` en PostgreSQL.
+type AccountLiveness struct {
+	AgentID      string         `json:"agent_id" db:"agent_id"`
+	PipeName     string         `json:"pipe_name" db:"pipe_name"`
+	AccountID    string         `json:"account_id" db:"account_id"`
+	PipeRole     string         `json:"pipe_role" db:"pipe_role"`
+	Status       LivenessStatus `json:"status" db:"status"`
+	Codec        string         `json:"codec" db:"codec"`
+	LastSeenAtMs int64          `json:"last_seen_at_ms" db:"last_seen_at_ms"` // Último heartbeat con el EA conectado (0 si nunca)
+}
+
+// OwnershipStatus estado del owner de una cuenta (i8).
+type OwnershipStatus string
+
+const (
+	// OwnershipStatusActive el stream del Agent owner está abierto.
+	OwnershipStatusActive OwnershipStatus = "ACTIVE"
+	// OwnershipStatusDetached stream del owner cerrado (o Core reiniciado): la cuenta
+	// no se rutea y otro Agent puede tomarla tras core/ownership/takeover_grace_ms.
+	OwnershipStatusDetached OwnershipStatus = "DETACHED"
+)
+
+// AccountOwnership owner persistido de una cuenta (i8).
+// Corresponde a la tabla `echo.account_ownership` en PostgreSQL.
+type AccountOwnership struct {
+	AccountID      string          `json:"account_id" db:"account_id"`
+	AgentID        string          `json:"agent_id" db:"agent_id"`
+	PipeRole       string          `json:"pipe_role" db:"pipe_role"`
+	Status         OwnershipStatus `json:"status" db:"status"`
+	RegisteredAtMs int64           `json:"registered_at_ms" db:"registered_at_ms"` // Alta del owner actual
+	UpdatedAtMs    int64           `json:"updated_at_ms" db:"updated_at_ms"`
+
+	// Conflicto: Agent que reclamó la cuenta mientras el owner seguía vivo (en cuarentena)
+	ClaimantAgentID string `json:"claimant_agent_id" db:"claimant_agent_id"` // "" sin conflicto
+	ConflictAtMs    int64  `json:"conflict_at_ms" db:"conflict_at_ms"`
+
+	// Takeover pedido por un operador (echo-core-cli accounts takeover); el Core lo aplica y lo limpia
+	ForcedAgentID string `json:"forced_agent_id" db:"forced_agent_id"`
+}
+
+// AccountCopyControl habilitación de copia de una cuenta (i8).
+// Corresponde a la tabla `echo.account_copy_control` en PostgreSQL.
+//
+// Una cuenta sin fila copia normalmente. Master deshabilitado: sus TradeIntents
+// no se copian. Slave deshabilitado: no recibe ExecuteOrders nuevos. Los cierres
+// se siguen propagando para no dejar posiciones copiadas huérfanas.
+type AccountCopyControl struct {
+	AccountID   string `json:"account_id" db:"account_id"`
+	CopyEnabled bool   `json:"copy_enabled" db:"copy_enabled"`
+	Reason      string `json:"reason" db:"reason"`         // Motivo informado por el operador
+	UpdatedBy   string `json:"updated_by" db:"updated_by"` // Identidad que hizo el cambio
+	UpdatedAtMs int64  `json:"updated_at_ms" db:"updated_at_ms"`
+}
+
+// NotificationDeadLetter webhook que agotó sus reintentos (i8).
+// Corresponde a la tabla `echo.notification_dead_letters` en PostgreSQL.
+//
+// Payload es el JSON ya renderizado: se puede reenviar tal cual al URL.
+type NotificationDeadLetter struct {
+	ID          string `json:"id" db:"id"` // UUIDv7
+	Rule        string `json:"rule" db:"rule"`
+	URL         string `json:"url" db:"url"`
+	EventType   string `json:"event_type" db:"event_type"`
+	EventSeq    uint64 `json:"event_seq" db:"event_seq"`
+	Payload     string `json:"payload" db:"payload"`
+	Attempts    int    `json:"attempts" db:"attempts"`
+	LastStatus  int    `json:"last_status" db:"last_status"` // Último HTTP status (0 = error de red)
+	LastError   string `json:"last_error" db:"last_error"`
+	CreatedAtMs int64  `json:"created_at_ms" db:"created_at_ms"`
+}
+
 // LatencyMetrics representa métricas de latencia E2E calculadas desde timestamps.
 type LatencyMetrics struct {
 	// Latencias por hop (en milisegundos)
@@ -213,8 +448,26 @@ type SymbolMapping struct {
 	LotStep         float64  `json:"lot_step" db:"lot_step"`
 	StopLevel       int32    `json:"stop_level" db:"stop_level"`
 	ContractSize    *float64 `json:"contract_size,omitempty" db:"contract_size"`
+
+	// i8: Fingerprint y origen del mapeo (matcher por reglas)
+	BaseCurrency   string              `json:"base_currency,omitempty" db:"base_currency"`
+	ProfitCurrency string              `json:"profit_currency,omitempty" db:"profit_currency"`
+	Source         SymbolMappingSource `json:"source" db:"source"`
+	Confidence     float64             `json:"confidence" db:"confidence"`
 }
 
+// SymbolMappingSource origen de un mapeo canonical → broker_symbol (i8).
+type SymbolMappingSource string
+
+const (
+	// SymbolMappingSourceReport el EA reportó el canonical_symbol explícitamente.
+	SymbolMappingSourceReport SymbolMappingSource = "ea_report"
+	// SymbolMappingSourceOverride un operador lo fijó (echo-core-cli symbols pin).
+	SymbolMappingSourceOverride SymbolMappingSource = "override"
+	// SymbolMappingSourceMatcher el matcher del Core lo aceptó automáticamente.
+	SymbolMappingSourceMatcher SymbolMappingSource = "matcher"
+)
+
 // AccountSymbolInfo representa información completa de un símbolo por cuenta (i3).
 //
 // Utilizado en caché del AccountSymbolResolver.
@@ -230,6 +483,38 @@ type AccountSymbolInfo struct {
 	LotStep         float64
 	StopLevel       int32
 	ContractSize    *float64
+	BaseCurrency    string
+	ProfitCurrency  string
+	Source          SymbolMappingSource
+	Confidence      float64
+}
+
+// SymbolMappingOverride fija manualmente el broker_symbol de un símbolo canónico
+// para una cuenta (i8). Corresponde a la tabla `echo.account_symbol_overrides`.
+//
+// Prevalece sobre lo que reporte el EA en AccountSymbolsReport: el Core lo
+// reaplica en cada reporte hasta que el operador lo elimine.
+type SymbolMappingOverride struct {
+	AccountID       string `json:"account_id" db:"account_id"`
+	CanonicalSymbol string `json:"canonical_symbol" db:"canonical_symbol"`
+	BrokerSymbol    string `json:"broker_symbol" db:"broker_symbol"`
+	Reason          string `json:"reason" db:"reason"`         // Motivo informado por el operador
+	UpdatedBy       string `json:"updated_by" db:"updated_by"` // Identidad que hizo el cambio
+	UpdatedAtMs     int64  `json:"updated_at_ms" db:"updated_at_ms"`
+}
+
+// SymbolMatchProposal mapeo sugerido por el matcher del Core por debajo del umbral
+// de auto-aceptación (i8). Corresponde a la tabla `echo.account_symbol_proposals`.
+//
+// No se usa para rutear; un operador lo acepta con echo-core-cli symbols pin.
+type SymbolMatchProposal struct {
+	AccountID       string   `json:"account_id" db:"account_id"`
+	BrokerSymbol    string   `json:"broker_symbol" db:"broker_symbol"`
+	CanonicalSymbol string   `json:"canonical_symbol" db:"canonical_symbol"`
+	Confidence      float64  `json:"confidence" db:"confidence"`
+	Rule            string   `json:"rule" db:"rule"`     // exact | strip | alias
+	Checks          []string `json:"checks" db:"checks"` // Resultado del fingerprint (ej: "digits ok", "contract_size 100≠1")
+	ProposedAtMs    int64    `json:"proposed_at_ms" db:"proposed_at_ms"`
 }
 
 // AccountSymbolSpec encapsula la especificación persistida de un símbolo por cuenta.
@@ -252,6 +537,10 @@ func (m *SymbolMapping) ToAccountSymbolInfo() *AccountSymbolInfo {
 		LotStep:         m.LotStep,
 		StopLevel:       m.StopLevel,
 		ContractSize:    m.ContractSize,
+		BaseCurrency:    m.BaseCurrency,
+		ProfitCurrency:  m.ProfitCurrency,
+		Source:          m.Source,
+		Confidence:      m.Confidence,
 	}
 }
 
@@ -270,6 +559,10 @@ func (i *AccountSymbolInfo) ToSymbolMapping() *SymbolMapping {
 		MaxLot:          i.MaxLot,
 		LotStep:         i.LotStep,
 		StopLevel:       i.StopLevel,
+		BaseCurrency:    i.BaseCurrency,
+		ProfitCurrency:  i.ProfitCurrency,
+		Source:          i.Source,
+		Confidence:      i.Confidence,
 	}
 	mapping.ContractSize = i.ContractSize
 	return mapping
@@ -39,6 +39,19 @@ type TradeRepository interface {
 
 	// ListByStatus obtiene trades por estado.
 	ListByStatus(ctx context.Context, status OrderStatus, limit, offset int) ([]*Trade, error)
+
+	// ListFiltered obtiene trades que cumplen filter (i8).
+	// Retorna slice ordenado por created_at DESC.
+	ListFiltered(ctx context.Context, filter TradeFilter, limit, offset int) ([]*Trade, error)
+}
+
+// TradeFilter filtros de TradeRepository.ListFiltered (i8). Campos cero = sin filtro.
+type TradeFilter struct {
+	Status          OrderStatus
+	MasterAccountID string
+	Symbol          string
+	CreatedFromMs   int64 // created_at >= CreatedFromMs
+	CreatedToMs     int64 // created_at < CreatedToMs
 }
 
 // ExecutionRepository define operaciones de persistencia para Execution.
@@ -67,6 +80,19 @@ type ExecutionRepository interface {
 
 	// ListBySuccess obtiene ejecuciones exitosas o fallidas.
 	ListBySuccess(ctx context.Context, success bool, limit, offset int) ([]*Execution, error)
+
+	// ListFiltered obtiene ejecuciones que cumplen filter (i8).
+	// Retorna slice ordenado por created_at DESC.
+	ListFiltered(ctx context.Context, filter ExecutionFilter, limit, offset int) ([]*Execution, error)
+}
+
+// ExecutionFilter filtros de ExecutionRepository.ListFiltered (i8). Campos cero = sin filtro.
+type ExecutionFilter struct {
+	TradeID        string
+	SlaveAccountID string
+	Success        *bool
+	CreatedFromMs  int64 // created_at >= CreatedFromMs
+	CreatedToMs    int64 // created_at < CreatedToMs
 }
 
 // DedupeRepository define operaciones de persistencia para deduplicación.
@@ -110,6 +136,120 @@ type CloseRepository interface {
 	List(ctx context.Context, limit, offset int) ([]*Close, error)
 }
 
+// ModifyRepository define operaciones de persistencia para Modify (i8).
+type ModifyRepository interface {
+	// Create inserta un cambio de SL/TP.
+	// Retorna error si el trade no existe.
+	Create(ctx context.Context, modify *Modify) error
+
+	// GetByTradeID obtiene los cambios de SL/TP de un trade.
+	// Retorna slice ordenado por modified_at_ms ASC.
+	GetByTradeID(ctx context.Context, tradeID string) ([]*Modify, error)
+}
+
+// TradeLifecycleRepository expone el ciclo de vida por trade×slave (i8): fill de
+// apertura, cambios de SL/TP, cierres parciales y cierre final con su P&L.
+type TradeLifecycleRepository interface {
+	// GetLifecycle obtiene el ciclo de vida de un trade en un slave.
+	// Retorna nil si no hay ejecuciones ni cierres registrados.
+	GetLifecycle(ctx context.Context, tradeID, slaveAccountID string) (*TradeLifecycle, error)
+
+	// ListByTrade obtiene el ciclo de vida de un trade en cada slave.
+	// Retorna slice ordenado por slave_account_id ASC.
+	ListByTrade(ctx context.Context, tradeID string) ([]*TradeLifecycle, error)
+}
+
+// OutboxRepository define el outbox transaccional de comandos hacia Agents (i8).
+//
+// Los comandos se escriben en la misma transacción que el trade y su entrada de
+// dedupe; un dispatcher los entrega cuando el Agent owner está conectado.
+type OutboxRepository interface {
+	// EnqueueTrade persiste trade, entrada de dedupe y comandos en una única transacción.
+	// Un comando con Status DISPATCHED (entrega inmediata al owner conectado) conserva
+	// attempts, last_agent_id y dispatched_at_ms; el resto se encola PENDING.
+	// Si falla no se persiste nada.
+	EnqueueTrade(ctx context.Context, trade *Trade, dedupe *DedupeEntry, commands []*OutboxCommand) error
+
+	// GetByID obtiene un comando por command_id.
+	// Retorna nil si no existe.
+	GetByID(ctx context.Context, commandID string) (*OutboxCommand, error)
+
+	// ListDeliverable obtiene comandos PENDING y DISPATCHED con última entrega anterior
+	// a redeliverBeforeMs. Retorna slice ordenado por created_at_ms ASC.
+	ListDeliverable(ctx context.Context, redeliverBeforeMs int64, limit int) ([]*OutboxCommand, error)
+
+	// MarkDispatched registra una entrega al Agent (status DISPATCHED, attempts+1).
+	// No-op si el comando es terminal.
+	MarkDispatched(ctx context.Context, commandID, agentID string, dispatchedAtMs int64) error
+
+	// MarkAcked marca el comando como ACKED.
+	// Retorna false si no existe o ya estaba ACKED.
+	MarkAcked(ctx context.Context, commandID string, ackedAtMs int64) (bool, error)
+
+	// ExpireOlderThan marca EXPIRED los comandos no terminales creados antes de cutoffMs.
+	// Retorna los comandos expirados.
+	ExpireOlderThan(ctx context.Context, cutoffMs, expiredAtMs int64) ([]*OutboxCommand, error)
+
+	// PurgeCompleted elimina comandos terminales completados antes de cutoffMs.
+	// Retorna el número de comandos eliminados.
+	PurgeCompleted(ctx context.Context, cutoffMs int64) (int, error)
+}
+
+// AgentLivenessRepository persiste el último estado de liveness de Agents y sus
+// cuentas (i8). Lo escribe el monitor de heartbeats del Core y lo lee echo-core-cli.
+type AgentLivenessRepository interface {
+	// Upsert reemplaza el estado del Agent y el de sus cuentas (liveness.Accounts).
+	Upsert(ctx context.Context, liveness *AgentLiveness) error
+
+	// List obtiene todos los Agents con sus cuentas.
+	// Retorna slice ordenado por agent_id ASC (cuentas por pipe_name ASC).
+	List(ctx context.Context) ([]*AgentLiveness, error)
+
+	// PurgeOlderThan elimina Agents (y sus cuentas) sin heartbeat desde cutoffMs.
+	// Retorna el número de Agents eliminados.
+	PurgeOlderThan(ctx context.Context, cutoffMs int64) (int, error)
+}
+
+// AccountOwnershipRepository persiste qué Agent es dueño de cada cuenta (i8).
+// Lo escribe el AccountRegistry del Core; echo-core-cli lo lee y pide takeovers.
+type AccountOwnershipRepository interface {
+	// Upsert guarda owner, estado y conflicto de la cuenta. No modifica ForcedAgentID.
+	Upsert(ctx context.Context, ownership *AccountOwnership) error
+
+	// Delete elimina la cuenta (liberada por su owner). No falla si no existe.
+	Delete(ctx context.Context, accountID string) error
+
+	// List obtiene todas las cuentas ordenadas por account_id ASC.
+	List(ctx context.Context) ([]*AccountOwnership, error)
+
+	// RequestTakeover pide asignar accountID a agentID (operador). Crea la fila
+	// DETACHED si la cuenta no existe; el Core la aplica en su próximo ciclo.
+	RequestTakeover(ctx context.Context, accountID, agentID string, nowMs int64) error
+
+	// ClearTakeover limpia ForcedAgentID si sigue siendo agentID (takeover aplicado).
+	ClearTakeover(ctx context.Context, accountID, agentID string) error
+}
+
+// AccountCopyControlRepository persiste la habilitación de copia por cuenta (i8).
+// La escribe AdminService (SetCopyEnabled); el Core la carga al arrancar.
+type AccountCopyControlRepository interface {
+	// Upsert guarda el estado de copia de la cuenta.
+	Upsert(ctx context.Context, control *AccountCopyControl) error
+
+	// List obtiene todas las cuentas con estado explícito ordenadas por account_id ASC.
+	List(ctx context.Context) ([]*AccountCopyControl, error)
+}
+
+// NotificationDeadLetterRepository persiste los webhooks no entregados (i8).
+// Los escribe el notifier del Core tras agotar reintentos.
+type NotificationDeadLetterRepository interface {
+	// Create inserta el registro. Retorna error si el id ya existe.
+	Create(ctx context.Context, letter *NotificationDeadLetter) error
+
+	// List obtiene registros con paginación ordenados por created_at_ms DESC.
+	List(ctx context.Context, limit, offset int) ([]*NotificationDeadLetter, error)
+}
+
 // CorrelationService define operaciones para correlación trade_id ↔ tickets.
 //
 // Este servicio encapsula la lógica de correlación determinística:
@@ -158,6 +298,34 @@ type SymbolSpecRepository interface {
 	GetSpecifications(ctx context.Context, accountID string) (map[string]*AccountSymbolSpec, error)
 }
 
+// SymbolOverrideRepository persiste overrides manuales de mapeo de símbolos (i8).
+// Los escribe echo-core-cli symbols pin/unpin; el Core los aplica sobre cada
+// AccountSymbolsReport. Cada cambio se notifica al Core en ejecución
+// (echo_symbol_mapping_updated o ChangeNotifier) para invalidar su caché de mapeos.
+type SymbolOverrideRepository interface {
+	// Upsert guarda el override de la cuenta para el símbolo canónico.
+	Upsert(ctx context.Context, override *SymbolMappingOverride) error
+
+	// Pin guarda el override y el mapeo resultante (account_symbol_map, con
+	// reported_at_ms = UpdatedAtMs) en una única transacción.
+	Pin(ctx context.Context, override *SymbolMappingOverride, mapping *SymbolMapping) error
+
+	// Delete elimina el override. Retorna false si no existía.
+	Delete(ctx context.Context, accountID, canonicalSymbol string) (bool, error)
+
+	// ListByAccount obtiene los overrides de la cuenta ordenados por canonical_symbol ASC.
+	ListByAccount(ctx context.Context, accountID string) ([]*SymbolMappingOverride, error)
+}
+
+// SymbolProposalRepository persiste los mapeos sugeridos por el matcher del Core (i8).
+type SymbolProposalRepository interface {
+	// ReplaceForAccount reemplaza las propuestas de la cuenta por las del último reporte.
+	ReplaceForAccount(ctx context.Context, accountID string, proposals []*SymbolMatchProposal) error
+
+	// ListByAccount obtiene las propuestas de la cuenta ordenadas por broker_symbol ASC.
+	ListByAccount(ctx context.Context, accountID string) ([]*SymbolMatchProposal, error)
+}
+
 // SymbolQuoteRepository define operaciones para snapshots de precios.
 type SymbolQuoteRepository interface {
 	InsertSnapshot(ctx context.Context, snapshot *pb.SymbolQuoteSnapshot) error
@@ -166,7 +334,24 @@ type SymbolQuoteRepository interface {
 
 // RiskPolicyRepository define operaciones para políticas de riesgo.
 type RiskPolicyRepository interface {
+	// Get obtiene la política vigente (effective_from <= now y no expirada).
+	// Retorna nil si no existe política vigente.
 	Get(ctx context.Context, accountID, strategyID string) (*RiskPolicy, error)
+
+	// GetVersion obtiene una versión específica del historial (i7).
+	// Retorna nil si la versión no existe.
+	GetVersion(ctx context.Context, accountID, strategyID string, version int64) (*RiskPolicyRevision, error)
+
+	// ListHistory obtiene el historial completo ordenado por versión ASC (i7).
+	ListHistory(ctx context.Context, accountID, strategyID string) ([]*RiskPolicyRevision, error)
+
+	// List obtiene la política vigente de cada par cuenta × estrategia conocido,
+	// ordenada por account_id y strategy_id (i8). Omite pares sin política vigente.
+	List(ctx context.Context) ([]*RiskPolicy, error)
+
+	// AppendRevision agrega una revisión al historial (append-only) y asigna la versión (i7).
+	// Retorna la versión asignada.
+	AppendRevision(ctx context.Context, revision *RiskPolicyRevision) (int64, error)
 }
 
 // RepositoryFactory crea instancias de repositorios.
@@ -181,10 +366,19 @@ type RepositoryFactory interface {
 	ExecutionRepository() ExecutionRepository
 	DedupeRepository() DedupeRepository
 	CloseRepository() CloseRepository
+	ModifyRepository() ModifyRepository
+	TradeLifecycleRepository() TradeLifecycleRepository
+	OutboxRepository() OutboxRepository
+	AgentLivenessRepository() AgentLivenessRepository
+	AccountOwnershipRepository() AccountOwnershipRepository
+	AccountCopyControlRepository() AccountCopyControlRepository
+	NotificationDeadLetterRepository() NotificationDeadLetterRepository
 	CorrelationService() CorrelationService
 	SymbolRepository() SymbolRepository // NEW i3
 	SymbolSpecRepository() SymbolSpecRepository
 	SymbolQuoteRepository() SymbolQuoteRepository
+	SymbolOverrideRepository() SymbolOverrideRepository
+	SymbolProposalRepository() SymbolProposalRepository
 	RiskPolicyRepository() RiskPolicyRepository
 	HandshakeRepository() HandshakeEvaluationRepository
 }
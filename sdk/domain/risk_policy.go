@@ -41,6 +41,33 @@ type RiskPolicy struct {
 	Version    int64
 	UpdatedAt  time.Time
 	ValidUntil *time.Time
+	// EffectiveFrom indica desde cuándo la versión entra en vigor (iteración 7).
+	EffectiveFrom time.Time
+}
+
+// RiskPolicyRevision representa una entrada inmutable del historial de políticas (iteración 7).
+//
+// Cada cambio de política agrega una revisión nueva con versión incremental; las
+// revisiones con EffectiveFrom futuro quedan programadas hasta su activación.
+type RiskPolicyRevision struct {
+	Policy     RiskPolicy
+	Author     string
+	Reason     string
+	RecordedAt time.Time
+}
+
+// IsEffectiveAt indica si la revisión está vigente en el instante indicado.
+func (r *RiskPolicyRevision) IsEffectiveAt(at time.Time) bool {
+	if r == nil {
+		return false
+	}
+	if r.Policy.EffectiveFrom.After(at) {
+		return false
+	}
+	if r.Policy.ValidUntil != nil && !r.Policy.ValidUntil.After(at) {
+		return false
+	}
+	return true
 }
 
 // RiskPolicyService encapsula la lógica de caché y lectura de políticas.
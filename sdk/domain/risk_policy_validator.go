@@ -113,3 +113,66 @@ func ValidateFixedRiskConfig(cfg *FixedRiskConfig) error {
 
 	return nil
 }
+
+// MarshalFixedRiskConfig serializa la configuración FIXED_RISK al esquema JSONB persistido.
+func MarshalFixedRiskConfig(cfg *FixedRiskConfig) (json.RawMessage, error) {
+	if cfg == nil {
+		return nil, NewError(ErrMissingRequiredField, "fixed risk config is nil")
+	}
+
+	amount := cfg.Amount
+	dto := fixedRiskConfigDTO{
+		Amount:           &amount,
+		Currency:         cfg.Currency,
+		MinLotOverride:   cfg.MinLotOverride,
+		MaxLotOverride:   cfg.MaxLotOverride,
+		CommissionPerLot: cfg.CommissionPerLot,
+		CommissionRate:   cfg.CommissionRate,
+	}
+
+	raw, err := json.Marshal(dto)
+	if err != nil {
+		return nil, WrapError(ErrPolicyViolation, "failed to marshal fixed risk config", err)
+	}
+	return raw, nil
+}
+
+// ValidateRiskPolicyRevision valida una revisión antes de agregarla al historial (iteración 7).
+func ValidateRiskPolicyRevision(rev *RiskPolicyRevision) error {
+	if rev == nil {
+		return NewError(ErrMissingRequiredField, "risk policy revision is nil")
+	}
+
+	policy := &rev.Policy
+	if strings.TrimSpace(policy.AccountID) == "" {
+		return NewValidationError("account_id", policy.AccountID, "account_id is required")
+	}
+	if strings.TrimSpace(policy.StrategyID) == "" {
+		return NewValidationError("strategy_id", policy.StrategyID, "strategy_id is required")
+	}
+	if strings.TrimSpace(rev.Author) == "" {
+		return NewValidationError("author", rev.Author, "author is required")
+	}
+
+	if policy.ValidUntil != nil && !policy.EffectiveFrom.IsZero() && !policy.ValidUntil.After(policy.EffectiveFrom) {
+		return NewValidationError("valid_until", *policy.ValidUntil, "valid_until must be after effective_from")
+	}
+
+	switch policy.Type {
+	case RiskPolicyTypeFixedLot:
+		if policy.FixedLot == nil || policy.FixedLot.LotSize <= 0 {
+			return NewValidationError("lot_size", nil, "FIXED_LOT requires lot_size greater than zero")
+		}
+	case RiskPolicyTypeFixedRisk:
+		if err := NormalizeFixedRiskConfig(policy.FixedRisk); err != nil {
+			return err
+		}
+		if err := ValidateFixedRiskConfig(policy.FixedRisk); err != nil {
+			return err
+		}
+	default:
+		return NewValidationError("type", policy.Type, "unsupported risk policy type")
+	}
+
+	return nil
+}
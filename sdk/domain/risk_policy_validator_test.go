@@ -3,6 +3,7 @@ package domain
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -95,3 +96,65 @@ func TestValidateFixedRiskConfig(t *testing.T) {
 	cfg.Amount = 0
 	assert.Error(t, ValidateFixedRiskConfig(cfg))
 }
+
+func TestMarshalFixedRiskConfig_RoundTrip(t *testing.T) {
+	minLot := 0.05
+	rate := 0.25
+	cfg := &FixedRiskConfig{Amount: 120, Currency: "USD", MinLotOverride: &minLot, CommissionRate: &rate}
+
+	raw, err := MarshalFixedRiskConfig(cfg)
+	require.NoError(t, err)
+
+	parsed, err := ParseFixedRiskConfig(raw)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.Amount, parsed.Amount)
+	assert.Equal(t, cfg.Currency, parsed.Currency)
+	if assert.NotNil(t, parsed.MinLotOverride) {
+		assert.InDelta(t, minLot, *parsed.MinLotOverride, 1e-9)
+	}
+	assert.Nil(t, parsed.MaxLotOverride)
+	if assert.NotNil(t, parsed.CommissionRate) {
+		assert.InDelta(t, rate, *parsed.CommissionRate, 1e-9)
+	}
+}
+
+func TestValidateRiskPolicyRevision(t *testing.T) {
+	now := time.Now()
+	rev := &RiskPolicyRevision{
+		Policy: RiskPolicy{
+			AccountID:     "acc",
+			StrategyID:    "strat",
+			Type:          RiskPolicyTypeFixedRisk,
+			FixedRisk:     &FixedRiskConfig{Amount: 100, Currency: "usd"},
+			EffectiveFrom: now,
+		},
+		Author: "ops",
+		Reason: "raise risk",
+	}
+	require.NoError(t, ValidateRiskPolicyRevision(rev))
+	assert.Equal(t, "USD", rev.Policy.FixedRisk.Currency)
+
+	rev.Author = ""
+	assert.Error(t, ValidateRiskPolicyRevision(rev))
+	rev.Author = "ops"
+
+	past := now.Add(-time.Minute)
+	rev.Policy.ValidUntil = &past
+	assert.Error(t, ValidateRiskPolicyRevision(rev))
+	rev.Policy.ValidUntil = nil
+
+	rev.Policy.Type = RiskPolicyTypeFixedLot
+	assert.Error(t, ValidateRiskPolicyRevision(rev))
+	rev.Policy.FixedLot = &FixedLotConfig{LotSize: 0.1}
+	assert.NoError(t, ValidateRiskPolicyRevision(rev))
+}
+
+func TestRiskPolicyRevisionIsEffectiveAt(t *testing.T) {
+	now := time.Now()
+	until := now.Add(time.Hour)
+	rev := &RiskPolicyRevision{Policy: RiskPolicy{EffectiveFrom: now.Add(time.Minute), ValidUntil: &until}}
+
+	assert.False(t, rev.IsEffectiveAt(now))
+	assert.True(t, rev.IsEffectiveAt(now.Add(2*time.Minute)))
+	assert.False(t, rev.IsEffectiveAt(until))
+}
@@ -384,6 +384,7 @@ func JSONToExecutionResult(m map[string]interface{}) (*pb.ExecutionResult, error
 //   - error_code (string) → ExecutionResult.error_code (enum)
 //   - error_message → ExecutionResult.error_message
 //   - close_price → ExecutionResult.executed_price
+//   - profit/commission/swap/closed_volume/remaining_volume (i8, opcionales) → mismos campos
 //   - timestamp_ms (nivel raíz) → ExecutionResult.execution_time_ms
 func JSONToCloseResult(m map[string]interface{}) (*pb.ExecutionResult, error) {
 	payload, ok := m["payload"].(map[string]interface{})
@@ -414,6 +415,23 @@ func JSONToCloseResult(m map[string]interface{}) (*pb.ExecutionResult, error) {
 		result.ExecutedPrice = &closePrice
 	}
 
+	// i8: P&L y volúmenes reportados por el slave (0 es un valor válido)
+	if profit, ok := extractOptionalFloat(payload, "profit"); ok {
+		result.Profit = &profit
+	}
+	if commission, ok := extractOptionalFloat(payload, "commission"); ok {
+		result.Commission = &commission
+	}
+	if swap, ok := extractOptionalFloat(payload, "swap"); ok {
+		result.Swap = &swap
+	}
+	if closedVolume, ok := extractOptionalFloat(payload, "closed_volume"); ok {
+		result.ClosedVolume = &closedVolume
+	}
+	if remainingVolume, ok := extractOptionalFloat(payload, "remaining_volume"); ok {
+		result.RemainingVolume = &remainingVolume
+	}
+
 	// timestamp_ms del mensaje como execution_time_ms
 	if ts := utils.ExtractInt64(m, "timestamp_ms"); ts != 0 {
 		result.ExecutionTimeMs = &ts
@@ -454,6 +472,21 @@ func ExecutionResultToJSON(result *pb.ExecutionResult) (map[string]interface{},
 	if result.ExecutionTimeMs != nil {
 		payload["execution_time_ms"] = *result.ExecutionTimeMs
 	}
+	if result.Profit != nil {
+		payload["profit"] = *result.Profit
+	}
+	if result.Commission != nil {
+		payload["commission"] = *result.Commission
+	}
+	if result.Swap != nil {
+		payload["swap"] = *result.Swap
+	}
+	if result.ClosedVolume != nil {
+		payload["closed_volume"] = *result.ClosedVolume
+	}
+	if result.RemainingVolume != nil {
+		payload["remaining_volume"] = *result.RemainingVolume
+	}
 
 	// Timestamps (Issue #C1)
 	if tsMap := timestampsToMap(result.Timestamps); tsMap != nil {
@@ -492,6 +525,14 @@ func JSONToTradeClose(m map[string]interface{}) (*pb.TradeClose, error) {
 		close.Reason = &reason
 	}
 
+	// i8: Volúmenes del cierre en el master (remaining_volume > 0 = cierre parcial)
+	if closedVolume, ok := extractOptionalFloat(payload, "closed_volume"); ok {
+		close.ClosedVolume = &closedVolume
+	}
+	if remainingVolume, ok := extractOptionalFloat(payload, "remaining_volume"); ok {
+		close.RemainingVolume = &remainingVolume
+	}
+
 	// Validar antes de retornar (Issue #A1)
 	if err := ValidateTradeClose(close); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -523,6 +564,12 @@ func TradeCloseToJSON(close *pb.TradeClose) (map[string]interface{}, error) {
 	if close.Reason != nil {
 		payload["reason"] = *close.Reason
 	}
+	if close.ClosedVolume != nil {
+		payload["closed_volume"] = *close.ClosedVolume
+	}
+	if close.RemainingVolume != nil {
+		payload["remaining_volume"] = *close.RemainingVolume
+	}
 
 	return map[string]interface{}{
 		"type":         "trade_close",
@@ -531,6 +578,35 @@ func TradeCloseToJSON(close *pb.TradeClose) (map[string]interface{}, error) {
 	}, nil
 }
 
+// JSONToTradeModify convierte un map JSON a TradeModify proto (i8).
+//
+// El Master EA solo incluye los niveles que cambiaron; 0 = nivel removido.
+func JSONToTradeModify(m map[string]interface{}) (*pb.TradeModify, error) {
+	payload, ok := m["payload"].(map[string]interface{})
+	if !ok {
+		return nil, NewError(ErrMissingRequiredField, "payload not found")
+	}
+
+	modify := &pb.TradeModify{
+		TradeId:     utils.ExtractString(payload, "trade_id"),
+		TimestampMs: utils.ExtractInt64(m, "timestamp_ms"),
+		Ticket:      int32(utils.ExtractInt64(payload, "ticket")),
+	}
+
+	if stopLoss, ok := extractOptionalFloat(payload, "new_stop_loss"); ok {
+		modify.NewStopLoss = &stopLoss
+	}
+	if takeProfit, ok := extractOptionalFloat(payload, "new_take_profit"); ok {
+		modify.NewTakeProfit = &takeProfit
+	}
+
+	if err := ValidateTradeModify(modify); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	return modify, nil
+}
+
 // CloseOrderToJSON convierte un CloseOrder proto a JSON map.
 //
 // Formato de salida para Agent → Slave EA.
@@ -554,6 +630,10 @@ func CloseOrderToJSON(order *pb.CloseOrder) (map[string]interface{}, error) {
 	if order.LotSize != nil {
 		payload["lot_size"] = *order.LotSize
 	}
+	// i8: Cierre parcial proporcional al del master
+	if order.CloseFraction != nil {
+		payload["close_fraction"] = *order.CloseFraction
+	}
 
 	// Timestamps
 	if tsMap := timestampsToMap(order.Timestamps); tsMap != nil {
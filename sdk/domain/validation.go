@@ -546,6 +546,46 @@ func ValidateTradeClose(close *pb.TradeClose) error {
 		return err
 	}
 
+	// i8: Volúmenes opcionales del cierre parcial
+	if close.ClosedVolume != nil && *close.ClosedVolume < 0 {
+		return NewValidationError("closed_volume", *close.ClosedVolume, "closed volume cannot be negative")
+	}
+	if close.RemainingVolume != nil && *close.RemainingVolume < 0 {
+		return NewValidationError("remaining_volume", *close.RemainingVolume, "remaining volume cannot be negative")
+	}
+
+	return nil
+}
+
+// ValidateTradeModify valida un TradeModify del Master (i8).
+//
+// Reglas:
+// - trade_id obligatorio
+// - ticket debe ser positivo
+// - al menos un nivel (SL o TP); 0 = nivel removido, negativo es inválido
+func ValidateTradeModify(modify *pb.TradeModify) error {
+	if modify == nil {
+		return NewError(ErrMissingRequiredField, "TradeModify is nil")
+	}
+
+	if err := ValidateTradeID(modify.TradeId); err != nil {
+		return err
+	}
+
+	if err := ValidateTicket(modify.Ticket); err != nil {
+		return err
+	}
+
+	if modify.NewStopLoss == nil && modify.NewTakeProfit == nil {
+		return NewValidationError("new_stop_loss", nil, "trade_modify requires new_stop_loss or new_take_profit")
+	}
+	if modify.NewStopLoss != nil && *modify.NewStopLoss < 0 {
+		return NewValidationError("new_stop_loss", *modify.NewStopLoss, "stop loss cannot be negative")
+	}
+	if modify.NewTakeProfit != nil && *modify.NewTakeProfit < 0 {
+		return NewValidationError("new_take_profit", *modify.NewTakeProfit, "take profit cannot be negative")
+	}
+
 	return nil
 }
 
@@ -661,6 +701,27 @@ func ValidateSymbolMapping(mapping *pb.SymbolMapping, allowedCanonicals []string
 		return fmt.Errorf("canonical_symbol %s not in whitelist (normalized: %s): %w", mapping.CanonicalSymbol, normalized, err)
 	}
 
+	return validateSymbolMappingParams(mapping)
+}
+
+// ValidateUnmappedSymbol valida un símbolo del broker reportado sin canonical_symbol (i8).
+//
+// Aplica las mismas reglas de volumen, precio y stops que ValidateSymbolMapping; el
+// canónico lo asigna después el matcher del Core.
+func ValidateUnmappedSymbol(mapping *pb.SymbolMapping) error {
+	if mapping == nil {
+		return NewError(ErrMissingRequiredField, "SymbolMapping is nil")
+	}
+
+	if mapping.BrokerSymbol == "" {
+		return NewValidationError("broker_symbol", "", "broker_symbol is required")
+	}
+
+	return validateSymbolMappingParams(mapping)
+}
+
+// validateSymbolMappingParams valida volúmenes, precio, stops y contract_size.
+func validateSymbolMappingParams(mapping *pb.SymbolMapping) error {
 	// Validar volúmenes
 	if mapping.MinLot <= 0 {
 		return NewValidationError("min_lot", mapping.MinLot, "min_lot must be > 0")
@@ -720,6 +781,13 @@ func ValidateAccountSymbolsReport(report *pb.AccountSymbolsReport, allowedCanoni
 
 	// Validar cada mapping
 	for i, mapping := range report.Symbols {
+		// i8: sin canonical_symbol el Core lo resuelve con su matcher
+		if mapping != nil && mapping.CanonicalSymbol == "" {
+			if err := ValidateUnmappedSymbol(mapping); err != nil {
+				return fmt.Errorf("invalid unmapped symbol at index %d: %w", i, err)
+			}
+			continue
+		}
 		if err := ValidateSymbolMapping(mapping, allowedCanonicals); err != nil {
 			return fmt.Errorf("invalid symbol mapping at index %d: %w", i, err)
 		}
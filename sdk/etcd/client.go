@@ -208,6 +208,24 @@ func (c *Client) GetVarWithDefault(ctx context.Context, key, defaultValue string
 	return value, nil
 }
 
+// GetVarsWithPrefix obtiene todas las variables bajo prefix.
+// Las claves del mapa son relativas al namespace (mismo formato que GetVar).
+func (c *Client) GetVarsWithPrefix(ctx context.Context, prefix string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	resp, err := c.kv.Get(ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prefix %s: %w", prefix, err)
+	}
+
+	vars := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		vars[string(kv.Key)] = string(kv.Value)
+	}
+	return vars, nil
+}
+
 // GetVarInt obtiene una variable como entero
 func (c *Client) GetVarInt(ctx context.Context, key string) (int, error) {
 	value, err := c.GetVar(ctx, key)
@@ -7,6 +7,7 @@ import (
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
 )
@@ -25,6 +26,9 @@ type ClientConfig struct {
 	// Insecure usar conexión sin TLS (true en i0)
 	Insecure bool
 
+	// TLS CA del servidor y certificado de cliente para mTLS (requerido si Insecure=false)
+	TLS *TLSConfig
+
 	// MaxRetries número máximo de reintentos de conexión
 	MaxRetries int
 
@@ -96,6 +100,15 @@ func NewClient(ctx context.Context, config *ClientConfig) (*Client, error) {
 	// Credentials
 	if config.Insecure {
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	} else {
+		if config.TLS == nil {
+			return nil, fmt.Errorf("TLS config required when Insecure is false")
+		}
+		tlsConfig, err := config.TLS.ClientTLS()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build client TLS: %w", err)
+		}
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	}
 
 	// KeepAlive
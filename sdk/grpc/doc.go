@@ -66,6 +66,34 @@
 //	    return nil
 //	}
 //
+// # Entrega Confiable (i8)
+//
+// ReliableSession convierte el stream en at-least-once con receptores idempotentes:
+// seq monótono por sesión, ack acumulativo y replay acotado tras un reset:
+//
+//	session := grpc.NewReliableSession[*pb.AgentMessage](grpc.DefaultReplayBufferSize)
+//
+//	// Al reconectar: el peer informa su último seq recibido
+//	session.Ack(resumed.AckSeq)
+//	for _, msg := range session.Unacked() {
+//	    stream.Send(msg) // mismo seq; el peer descarta duplicados
+//	}
+//
+// # Seguridad (i8)
+//
+// TLSConfig habilita TLS en servidor y cliente; con ClientCAFile el servidor exige
+// certificado de cliente (mTLS). Los interceptors de identidad ligan el agent-id
+// al CommonName del certificado o a un agent-token firmado con SignAgentToken:
+//
+//	config := grpc.DefaultServerConfig(50051)
+//	config.TLS = &grpc.TLSConfig{CertFile: "core.pem", KeyFile: "core-key.pem", ClientCAFile: "agents-ca.pem"}
+//	config.StreamInterceptors = []grpc.StreamServerInterceptor{
+//	    grpc.AgentIdentityStreamServerInterceptor(grpc.IdentityConfig{Require: true, TokenSecret: secret}),
+//	}
+//
+//	// En el handler
+//	identity, ok := grpc.IdentityFromContext(stream.Context())
+//
 // # Interceptors
 //
 // Agregar telemetría y tracing:
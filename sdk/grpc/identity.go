@@ -0,0 +1,175 @@
+package grpc
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// AgentIDMetadataKey metadata gRPC con el agent_id declarado por el Agent.
+const AgentIDMetadataKey = "agent-id"
+
+// Origen de la identidad autenticada de un Agent (i8).
+const (
+	IdentitySourceCertificate = "certificate" // certificado de cliente mTLS
+	IdentitySourceToken       = "token"       // token firmado (agent-token)
+)
+
+// IdentityConfig configuración de los interceptors de identidad de Agents (i8).
+type IdentityConfig struct {
+	// Require rechaza llamadas sin certificado de cliente ni token válido.
+	// En false se admiten Agents legacy (la identidad queda vacía).
+	Require bool
+
+	// TokenSecret secreto HMAC de los agent-token (vacío = tokens deshabilitados)
+	TokenSecret []byte
+
+	// OnReject se invoca con cada llamada rechazada (logging/métricas; opcional)
+	OnReject func(ctx context.Context, method, reason string)
+
+	// Now reloj para vencimiento de tokens (default time.Now)
+	Now func() time.Time
+}
+
+// AgentIdentity identidad autenticada de un Agent.
+type AgentIdentity struct {
+	AgentID string
+	Source  string // IdentitySourceCertificate | IdentitySourceToken
+}
+
+type identityContextKey struct{}
+
+// IdentityFromContext retorna la identidad autenticada por los interceptors.
+func IdentityFromContext(ctx context.Context) (AgentIdentity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(AgentIdentity)
+	return identity, ok && identity.AgentID != ""
+}
+
+// PeerCertificateIdentity retorna la identidad del certificado de cliente verificado.
+//
+// Usa el CommonName; si está vacío, el primer SAN (URI, luego DNS).
+func PeerCertificateIdentity(ctx context.Context) (string, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return "", false
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return certificateIdentity(tlsInfo.State.VerifiedChains[0][0])
+}
+
+func certificateIdentity(cert *x509.Certificate) (string, bool) {
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName, true
+	}
+	if len(cert.URIs) > 0 {
+		return cert.URIs[0].String(), true
+	}
+	if len(cert.DNSNames) > 0 {
+		return cert.DNSNames[0], true
+	}
+	return "", false
+}
+
+// authenticateAgent resuelve la identidad de la llamada y la valida contra agent-id.
+//
+// El certificado prevalece sobre el token. Un agent-id declarado distinto de la
+// identidad autenticada se rechaza (PermissionDenied).
+func (c IdentityConfig) authenticateAgent(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	claimed := firstMetadata(md, AgentIDMetadataKey)
+
+	identity := AgentIdentity{}
+	if agentID, ok := PeerCertificateIdentity(ctx); ok {
+		identity = AgentIdentity{AgentID: agentID, Source: IdentitySourceCertificate}
+	} else if token := firstMetadata(md, AgentTokenMetadataKey); token != "" && len(c.TokenSecret) > 0 {
+		now := time.Now
+		if c.Now != nil {
+			now = c.Now
+		}
+		agentID, err := VerifyAgentToken(c.TokenSecret, token, now())
+		if err != nil {
+			return nil, c.reject(ctx, method, codes.Unauthenticated, err.Error())
+		}
+		identity = AgentIdentity{AgentID: agentID, Source: IdentitySourceToken}
+	}
+
+	if identity.AgentID == "" {
+		if c.Require {
+			return nil, c.reject(ctx, method, codes.Unauthenticated, "agent identity required")
+		}
+		return ctx, nil
+	}
+	if claimed != "" && claimed != identity.AgentID {
+		return nil, c.reject(ctx, method, codes.PermissionDenied, "agent-id does not match authenticated identity")
+	}
+
+	return context.WithValue(ctx, identityContextKey{}, identity), nil
+}
+
+func (c IdentityConfig) reject(ctx context.Context, method string, code codes.Code, reason string) error {
+	if c.OnReject != nil {
+		c.OnReject(ctx, method, reason)
+	}
+	return status.Error(code, reason)
+}
+
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// AgentIdentityUnaryServerInterceptor autentica Agents en llamadas unary (i8).
+func AgentIdentityUnaryServerInterceptor(config IdentityConfig) grpc.UnaryServerInterceptor {
+	return func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		authCtx, err := config.authenticateAgent(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(authCtx, req)
+	}
+}
+
+// AgentIdentityStreamServerInterceptor autentica Agents al abrir streams (i8).
+//
+// El handler obtiene la identidad con IdentityFromContext(stream.Context()).
+func AgentIdentityStreamServerInterceptor(config IdentityConfig) grpc.StreamServerInterceptor {
+	return func(
+		srv interface{},
+		ss grpc.ServerStream,
+		info *grpc.StreamServerInfo,
+		handler grpc.StreamHandler,
+	) error {
+		authCtx, err := config.authenticateAgent(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityServerStream{ServerStream: ss, ctx: authCtx})
+	}
+}
+
+// identityServerStream expone el contexto con la identidad autenticada.
+type identityServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,197 @@
+package grpc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Defaults de entrega confiable sobre el stream bidi (i8).
+const (
+	DefaultReplayBufferSize = 1024
+	DefaultAckInterval      = 200 * time.Millisecond
+)
+
+var (
+	// ErrReplayBufferFull el buffer de replay está lleno: el emisor debe esperar el
+	// próximo ack antes de enviar más (backpressure).
+	ErrReplayBufferFull = errors.New("replay buffer full")
+
+	// ErrSequenceGap se recibió un seq posterior al siguiente esperado: la sesión
+	// perdió mensajes y no puede reanudarse.
+	ErrSequenceGap = errors.New("sequence gap")
+)
+
+// ReliableSession mantiene el estado de entrega at-least-once de una sesión de stream.
+//
+// Una sesión sobrevive a los resets del stream: los números de secuencia son
+// monótonos dentro de la sesión y, al reconectar, cada lado reenvía lo que el otro
+// no confirmó. Lado emisor: asigna seq y retiene los mensajes hasta el ack
+// acumulativo, en un buffer acotado (al llenarse rechaza nuevos mensajes hasta el
+// próximo ack). Lado receptor: descarta duplicados por seq, detecta huecos y
+// calcula el ack a enviar. Ante un hueco la sesión debe descartarse y reiniciarse
+// sin resume (el peer reenvía lo pendiente con seq nuevos).
+//
+// Seq 0 se reserva para mensajes sin secuencia (acks, handshake de resume).
+//
+// Example:
+//
+//	session := grpc.NewReliableSession[*pb.AgentMessage](grpc.DefaultReplayBufferSize)
+//
+//	// Enviar
+//	_, err := session.Track(msg, func(m *pb.AgentMessage, seq uint64) { m.Seq = seq })
+//	if errors.Is(err, grpc.ErrReplayBufferFull) {
+//	    return // reintentar tras el próximo ack
+//	}
+//	stream.Send(msg)
+//
+//	// Recibir
+//	if in.Seq > 0 {
+//	    ok, err := session.Accept(in.Seq)
+//	    if err != nil {
+//	        return err // hueco: cerrar el stream y reiniciar la sesión sin resume
+//	    }
+//	    if !ok {
+//	        return // duplicado
+//	    }
+//	}
+type ReliableSession[T any] struct {
+	mu       sync.Mutex
+	capacity int
+
+	// Emisor
+	lastSeq  uint64
+	pending  []reliableEntry[T] // ordenados por seq
+	rejected uint64
+
+	// Receptor
+	lastReceived uint64
+	lastAcked    uint64
+}
+
+type reliableEntry[T any] struct {
+	seq uint64
+	msg T
+}
+
+// NewReliableSession crea una sesión con un buffer de replay de capacity mensajes.
+func NewReliableSession[T any](capacity int) *ReliableSession[T] {
+	if capacity <= 0 {
+		capacity = DefaultReplayBufferSize
+	}
+	return &ReliableSession[T]{capacity: capacity}
+}
+
+// Track asigna el siguiente seq a msg y lo retiene hasta su ack.
+//
+// setSeq (opcional) escribe el seq en msg bajo el lock de la sesión, antes de que
+// Unacked o Restart puedan exponerlo a otra goroutine.
+//
+// Retorna ErrReplayBufferFull sin asignar seq si hay capacity mensajes sin ack:
+// descartar uno ya enviado dejaría un hueco que el peer no puede recuperar.
+func (s *ReliableSession[T]) Track(msg T, setSeq func(T, uint64)) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) >= s.capacity {
+		s.rejected++
+		return 0, ErrReplayBufferFull
+	}
+	s.lastSeq++
+	if setSeq != nil {
+		setSeq(msg, s.lastSeq)
+	}
+	s.pending = append(s.pending, reliableEntry[T]{seq: s.lastSeq, msg: msg})
+	return s.lastSeq, nil
+}
+
+// Ack libera los mensajes con seq <= ackSeq (ack acumulativo).
+//
+// Retorna la cantidad de mensajes liberados.
+func (s *ReliableSession[T]) Ack(ackSeq uint64) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	released := 0
+	for released < len(s.pending) && s.pending[released].seq <= ackSeq {
+		released++
+	}
+	s.pending = s.pending[released:]
+	return released
+}
+
+// Unacked retorna los mensajes pendientes de ack en orden de seq (para replay).
+func (s *ReliableSession[T]) Unacked() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := make([]T, len(s.pending))
+	for i, entry := range s.pending {
+		msgs[i] = entry.msg
+	}
+	return msgs
+}
+
+// Restart inicia una sesión nueva (el peer no conocía la anterior).
+//
+// Reinicia las secuencias de ambos lados y retorna los mensajes que seguían sin
+// ack, para reenviarlos con seq nuevos.
+func (s *ReliableSession[T]) Restart() []T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msgs := make([]T, len(s.pending))
+	for i, entry := range s.pending {
+		msgs[i] = entry.msg
+	}
+	s.lastSeq = 0
+	s.pending = nil
+	s.lastReceived = 0
+	s.lastAcked = 0
+	return msgs
+}
+
+// Accept registra la recepción de seq.
+//
+// Retorna false si seq ya fue recibido (duplicado de un replay) y ErrSequenceGap
+// si no es el siguiente al último recibido; en ese caso no registra seq.
+func (s *ReliableSession[T]) Accept(seq uint64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if seq <= s.lastReceived {
+		return false, nil
+	}
+	if seq != s.lastReceived+1 {
+		return false, fmt.Errorf("%w: expected seq %d, got %d", ErrSequenceGap, s.lastReceived+1, seq)
+	}
+	s.lastReceived = seq
+	return true, nil
+}
+
+// LastReceived retorna el mayor seq recibido (ack acumulativo a informar al peer).
+func (s *ReliableSession[T]) LastReceived() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastReceived
+}
+
+// AckDue retorna el ack a enviar si hubo recepciones desde el último ack enviado.
+func (s *ReliableSession[T]) AckDue() (uint64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.lastReceived <= s.lastAcked {
+		return 0, false
+	}
+	s.lastAcked = s.lastReceived
+	return s.lastReceived, true
+}
+
+// Stats retorna mensajes pendientes de ack y Track rechazados por buffer lleno.
+func (s *ReliableSession[T]) Stats() (pending int, rejected uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending), s.rejected
+}
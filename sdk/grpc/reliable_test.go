@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReliableSessionReplayAndAck(t *testing.T) {
+	session := NewReliableSession[string](3)
+
+	for _, msg := range []string{"a", "b", "c"} {
+		_, err := session.Track(msg, nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, session.Ack(1))
+	assert.Equal(t, []string{"b", "c"}, session.Unacked())
+
+	seq, err := session.Track("d", nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), seq)
+
+	// Buffer lleno: se rechaza sin consumir seq ni descartar pendientes
+	seq, err = session.Track("e", nil)
+	assert.ErrorIs(t, err, ErrReplayBufferFull)
+	assert.Equal(t, uint64(0), seq)
+	assert.Equal(t, []string{"b", "c", "d"}, session.Unacked())
+
+	pending, rejected := session.Stats()
+	assert.Equal(t, 3, pending)
+	assert.Equal(t, uint64(1), rejected)
+
+	// Tras el ack hay lugar y el seq continúa sin huecos
+	assert.Equal(t, 1, session.Ack(2))
+	seq, err = session.Track("e", nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), seq)
+
+	// Ack acumulativo fuera de orden o repetido no libera de más
+	assert.Equal(t, 2, session.Ack(4))
+	assert.Equal(t, 0, session.Ack(2))
+	assert.Equal(t, []string{"e"}, session.Unacked())
+}
+
+func TestReliableSessionDedupeAndRestart(t *testing.T) {
+	session := NewReliableSession[string](10)
+
+	for _, seq := range []uint64{1, 2} {
+		ok, err := session.Accept(seq)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	}
+	for _, seq := range []uint64{2, 1} {
+		ok, err := session.Accept(seq)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	}
+
+	ack, due := session.AckDue()
+	require.True(t, due)
+	assert.Equal(t, uint64(2), ack)
+	_, due = session.AckDue()
+	assert.False(t, due)
+
+	_, err := session.Track("x", nil)
+	require.NoError(t, err)
+	_, err = session.Track("y", nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x", "y"}, session.Restart())
+	assert.Equal(t, uint64(0), session.LastReceived())
+	ok, err := session.Accept(1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	seq, err := session.Track("x", nil)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), seq)
+}
+
+func TestReliableSessionDetectsGap(t *testing.T) {
+	session := NewReliableSession[string](10)
+
+	ok, err := session.Accept(1)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = session.Accept(3)
+	assert.ErrorIs(t, err, ErrSequenceGap)
+	assert.False(t, ok)
+	assert.Equal(t, uint64(1), session.LastReceived(), "el hueco no avanza el ack")
+
+	// El siguiente esperado sigue siendo aceptado
+	ok, err = session.Accept(2)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestReliableSessionTrackSetsSeq(t *testing.T) {
+	type message struct{ seq uint64 }
+	session := NewReliableSession[*message](2)
+	setSeq := func(m *message, seq uint64) { m.seq = seq }
+
+	first, second := &message{}, &message{}
+	_, err := session.Track(first, setSeq)
+	require.NoError(t, err)
+	_, err = session.Track(second, setSeq)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), first.seq)
+	assert.Equal(t, uint64(2), second.seq)
+
+	// Rechazado por buffer lleno: el mensaje queda sin seq
+	rejected := &message{}
+	_, err = session.Track(rejected, setSeq)
+	assert.ErrorIs(t, err, ErrReplayBufferFull)
+	assert.Zero(t, rejected.seq)
+
+	// Re-track tras Restart: seq nuevos desde 1
+	for _, msg := range session.Restart() {
+		_, err := session.Track(msg, setSeq)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, uint64(1), first.seq)
+	assert.Equal(t, uint64(2), second.seq)
+	assert.Equal(t, []*message{first, second}, session.Unacked())
+}
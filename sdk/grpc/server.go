@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/keepalive"
 )
 
@@ -21,6 +22,9 @@ type ServerConfig struct {
 	// KeepAlive configuración de keepalive
 	KeepAlive *ServerKeepAliveConfig
 
+	// TLS certificado del servidor y, con ClientCAFile, mTLS (nil = sin TLS)
+	TLS *TLSConfig
+
 	// MaxConnections número máximo de conexiones concurrentes (0 = sin límite)
 	MaxConnections int
 
@@ -106,6 +110,15 @@ func NewServer(config *ServerConfig) (*Server, error) {
 	// Construir server options
 	opts := []grpc.ServerOption{}
 
+	// TLS / mTLS (i8)
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.ServerTLS()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build server TLS: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
 	// KeepAlive
 	if config.KeepAlive != nil {
 		kaParams := keepalive.ServerParameters{
@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig configuración TLS/mTLS del canal gRPC (i8).
+//
+// Servidor: CertFile/KeyFile obligatorios; con ClientCAFile exige y verifica el
+// certificado de cada cliente (mTLS). Cliente: CAFile verifica al servidor y
+// CertFile/KeyFile presentan el certificado de cliente.
+type TLSConfig struct {
+	// CertFile certificado propio en PEM
+	CertFile string
+
+	// KeyFile clave privada del certificado en PEM
+	KeyFile string
+
+	// CAFile CA que firmó el certificado del servidor (cliente; vacío = CAs del sistema)
+	CAFile string
+
+	// ClientCAFile CA que firma los certificados de cliente (servidor; vacío = sin mTLS)
+	ClientCAFile string
+
+	// ServerName nombre esperado en el certificado del servidor (cliente; vacío = host del target)
+	ServerName string
+}
+
+// ServerTLS construye el tls.Config del servidor.
+func (c *TLSConfig) ServerTLS() (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, fmt.Errorf("server TLS requires cert_file and key_file")
+	}
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.ClientCAFile != "" {
+		pool, err := loadCertPool(c.ClientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// ClientTLS construye el tls.Config del cliente.
+func (c *TLSConfig) ClientTLS() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: c.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if c.CAFile != "" {
+		pool, err := loadCertPool(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file %s: %w", path, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,78 @@
+package grpc
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AgentTokenMetadataKey metadata gRPC con el token firmado del Agent.
+const AgentTokenMetadataKey = "agent-token"
+
+var (
+	// ErrTokenMalformed el token no tiene el formato <agent>.<exp>.<firma>.
+	ErrTokenMalformed = errors.New("agent token malformed")
+	// ErrTokenSignature la firma no corresponde al secreto del Core.
+	ErrTokenSignature = errors.New("agent token signature mismatch")
+	// ErrTokenExpired el token venció.
+	ErrTokenExpired = errors.New("agent token expired")
+)
+
+// SignAgentToken firma un token que identifica a agentID hasta expiresAt.
+//
+// Formato: base64url(agent_id) "." unix(expiresAt) "." base64url(HMAC-SHA256).
+// Alternativa a mTLS cuando el Agent no puede presentar certificado de cliente.
+//
+// Example:
+//
+//	token, err := grpc.SignAgentToken(secret, "agent_host1", time.Now().Add(30*24*time.Hour))
+func SignAgentToken(secret []byte, agentID string, expiresAt time.Time) (string, error) {
+	if len(secret) == 0 {
+		return "", fmt.Errorf("token secret cannot be empty")
+	}
+	if agentID == "" {
+		return "", fmt.Errorf("agent_id cannot be empty")
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(agentID)) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + signTokenPayload(secret, payload), nil
+}
+
+// VerifyAgentToken valida firma y vencimiento y retorna el agent_id del token.
+func VerifyAgentToken(secret []byte, token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrTokenMalformed
+	}
+
+	payload := parts[0] + "." + parts[1]
+	expected := signTokenPayload(secret, payload)
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return "", ErrTokenSignature
+	}
+
+	agentID, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(agentID) == 0 {
+		return "", ErrTokenMalformed
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrTokenMalformed
+	}
+	if now.Unix() >= expiresAt {
+		return "", ErrTokenExpired
+	}
+
+	return string(agentID), nil
+}
+
+func signTokenPayload(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentTokenRoundTrip(t *testing.T) {
+	secret := []byte("core-secret")
+	now := time.Unix(1_700_000_000, 0)
+
+	token, err := SignAgentToken(secret, "agent_host1", now.Add(time.Hour))
+	require.NoError(t, err)
+
+	agentID, err := VerifyAgentToken(secret, token, now)
+	require.NoError(t, err)
+	assert.Equal(t, "agent_host1", agentID)
+
+	_, err = VerifyAgentToken(secret, token, now.Add(time.Hour))
+	assert.ErrorIs(t, err, ErrTokenExpired)
+
+	_, err = VerifyAgentToken([]byte("other-secret"), token, now)
+	assert.ErrorIs(t, err, ErrTokenSignature)
+}
+
+func TestAgentTokenRejectsTampering(t *testing.T) {
+	secret := []byte("core-secret")
+	now := time.Unix(1_700_000_000, 0)
+
+	token, err := SignAgentToken(secret, "agent_host1", now.Add(time.Hour))
+	require.NoError(t, err)
+	forged, err := SignAgentToken([]byte("attacker"), "agent_host2", now.Add(time.Hour))
+	require.NoError(t, err)
+
+	// Payload de otro Agent con la firma original
+	parts := strings.Split(forged, ".")
+	_, err = VerifyAgentToken(secret, parts[0]+"."+parts[1]+"."+strings.Split(token, ".")[2], now)
+	assert.ErrorIs(t, err, ErrTokenSignature)
+
+	_, err = VerifyAgentToken(secret, "not-a-token", now)
+	assert.ErrorIs(t, err, ErrTokenMalformed)
+
+	_, err = SignAgentToken(nil, "agent_host1", now)
+	assert.Error(t, err)
+}
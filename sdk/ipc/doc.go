@@ -185,11 +185,36 @@
 //
 // # Plataforma
 //
-// Este paquete está diseñado específicamente para Windows.
-// El build tag `// +build windows` asegura que solo se compile en Windows.
+// En Windows se usan Named Pipes (windows_pipe.go, build tag `windows`).
+// En Linux/macOS se usan Unix domain sockets (unix_socket.go, build tag `!windows`)
+// con la misma interfaz, deadlines y semántica de reconexión; el socket se crea en
+// PipeConfig.SocketDir (default os.TempDir()) como <name>.sock.
 //
-// Para soporte futuro de otras plataformas (Linux/Mac), se puede
-// implementar un wrapper sobre Unix Domain Sockets con la misma interfaz.
+// NewPipeServer/NewPipeClient eligen la implementación de la plataforma:
+//
+//	server, err := ipc.NewPipeServer("echo_master_12345")
+//	// Windows: \\.\pipe\echo_master_12345
+//	// Linux:   /tmp/echo_master_12345.sock
+//
+// # Transporte TCP/TLS
+//
+// TCPPipeServer implementa PipeServer sobre TCP (TLS opcional) para EAs en otro
+// host: un listener por cuenta, mismo framing JSON line-delimited y un token
+// pre-compartido que el EA envía en payload.auth_token del primer mensaje
+// (handshake). Un token inválido cierra la conexión con ErrUnauthorized.
+//
+// # Codec Framed Protobuf
+//
+// Alternativa a JSON negociada en el handshake: el EA anuncia la feature
+// FeatureFramedProtobuf ("ipc/framed_protobuf") y, si el Agent la acepta, responde
+// con la línea {"type":"codec_selected","payload":{"codec":"framed_protobuf"}}.
+// Desde ahí ambos lados escriben frames:
+//
+//	0xEC | uint32 big-endian (largo) | pb.PipeMessage serializado
+//
+// MessageReader acepta líneas JSON y frames en el mismo stream (el primer byte
+// distingue '{' de FrameMagic), así que el cambio no pierde mensajes en vuelo y
+// los EAs sin la feature siguen en JSON. FrameWriter escribe frames.
 //
 // # Referencias
 //
@@ -0,0 +1,225 @@
+package ipc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FeatureFramedProtobuf feature de handshake con la que un EA anuncia que acepta
+// frames protobuf length-prefixed además de JSON line-delimited.
+const FeatureFramedProtobuf = "ipc/framed_protobuf"
+
+// FrameMagic primer byte de un frame binario.
+//
+// Un mensaje JSON siempre empieza con '{', por lo que el lector distingue el
+// codec mensaje a mensaje y ambos pueden convivir en el mismo pipe mientras se
+// negocia el cambio.
+const FrameMagic byte = 0xEC
+
+// frameHeaderLen magic (1 byte) + largo del payload (uint32 big-endian).
+const frameHeaderLen = 5
+
+// MaxMessageBytes límite de un mensaje (frame o línea JSON), igual que LineReader.
+const MaxMessageBytes = 1024 * 1024
+
+// ErrInvalidFrame indica un frame o línea que no se puede delimitar (largo
+// excesivo). El stream queda desincronizado: la sesión debe cerrarse.
+var ErrInvalidFrame = errors.New("invalid pipe frame")
+
+// MessageReader lee mensajes de un Pipe aceptando ambos codecs.
+//
+// Cada mensaje es una línea JSON (terminada en \n) o un frame binario
+// (FrameMagic + uint32 big-endian + payload). A diferencia de LineReader, debe
+// reutilizarse durante toda la sesión: un timeout a mitad de mensaje conserva lo
+// leído y la siguiente llamada continúa donde quedó.
+type MessageReader struct {
+	pipe    Pipe
+	reader  *bufio.Reader
+	timeout time.Duration
+
+	partial  []byte // mensaje incompleto por un timeout
+	inFrame  bool   // partial es un frame binario (header ya consumido)
+	frameLen int
+}
+
+// NewMessageReader crea un MessageReader para una sesión del pipe.
+//
+// Example:
+//
+//	reader := ipc.NewMessageReader(pipe)
+//	data, framed, err := reader.Next()
+//	if framed {
+//	    // data es un pb.PipeMessage serializado
+//	} else {
+//	    msg, err := ipc.ParseJSONLine(data)
+//	}
+func NewMessageReader(pipe Pipe) *MessageReader {
+	return &MessageReader{
+		pipe:    pipe,
+		reader:  bufio.NewReaderSize(pipe, 64*1024),
+		timeout: 5 * time.Second,
+	}
+}
+
+// Next lee el siguiente mensaje.
+//
+// Retorna el payload del frame (framed=true) o la línea JSON sin \n
+// (framed=false). Los errores de lectura (EOF, "i/o timeout") se retornan sin
+// envolver.
+func (r *MessageReader) Next() (data []byte, framed bool, err error) {
+	if r.timeout > 0 {
+		if err := r.pipe.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if !r.inFrame && len(r.partial) == 0 {
+		first, err := r.reader.Peek(1)
+		if err != nil {
+			return nil, false, err
+		}
+		if first[0] == FrameMagic {
+			if err := r.startFrame(); err != nil {
+				return nil, true, err
+			}
+		}
+	}
+
+	if r.inFrame {
+		return r.readFrame()
+	}
+	return r.readLine()
+}
+
+// startFrame consume el header y prepara el buffer del payload.
+func (r *MessageReader) startFrame() error {
+	header, err := r.reader.Peek(frameHeaderLen)
+	if err != nil {
+		// Nada consumido: la siguiente llamada reintenta el header completo
+		return err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxMessageBytes {
+		return fmt.Errorf("%w: frame of %d bytes exceeds %d", ErrInvalidFrame, size, MaxMessageBytes)
+	}
+	if _, err := r.reader.Discard(frameHeaderLen); err != nil {
+		return err
+	}
+
+	r.inFrame = true
+	r.frameLen = int(size)
+	r.partial = make([]byte, 0, size)
+	return nil
+}
+
+// readFrame completa el payload del frame en curso.
+func (r *MessageReader) readFrame() ([]byte, bool, error) {
+	for len(r.partial) < r.frameLen {
+		n, err := r.reader.Read(r.partial[len(r.partial):r.frameLen])
+		r.partial = r.partial[:len(r.partial)+n]
+		if err != nil {
+			return nil, true, err
+		}
+	}
+
+	frame := r.partial
+	r.partial = nil
+	r.inFrame = false
+	r.frameLen = 0
+	return frame, true, nil
+}
+
+// readLine completa la línea JSON en curso.
+func (r *MessageReader) readLine() ([]byte, bool, error) {
+	for {
+		chunk, err := r.reader.ReadSlice('\n')
+		r.partial = append(r.partial, chunk...)
+		if len(r.partial) > MaxMessageBytes {
+			r.partial = nil
+			return nil, false, fmt.Errorf("%w: line exceeds %d bytes", ErrInvalidFrame, MaxMessageBytes)
+		}
+		if err == nil {
+			line := bytes.TrimRight(r.partial, "\r\n")
+			r.partial = nil
+			return line, false, nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, false, err
+		}
+	}
+}
+
+// SetTimeout establece el timeout de cada Next.
+func (r *MessageReader) SetTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// FrameWriter escribe frames binarios length-prefixed a un Pipe.
+//
+// El contenido del frame es opaco para ipc (el Agent usa pb.PipeMessage).
+// Header y payload van en un único Write para no intercalarse con otros writers.
+type FrameWriter struct {
+	pipe    Pipe
+	mu      sync.Mutex
+	timeout time.Duration
+}
+
+// NewFrameWriter crea un FrameWriter para un pipe.
+//
+// Example:
+//
+//	data, _ := proto.Marshal(&pb.PipeMessage{...})
+//	if err := ipc.NewFrameWriter(pipe).WriteFrame(data); err != nil {
+//	    // Handle error
+//	}
+func NewFrameWriter(pipe Pipe) *FrameWriter {
+	return &FrameWriter{
+		pipe:    pipe,
+		timeout: 5 * time.Second,
+	}
+}
+
+// WriteFrame escribe payload como un frame.
+func (w *FrameWriter) WriteFrame(payload []byte) error {
+	if len(payload) > MaxMessageBytes {
+		return fmt.Errorf("%w: frame of %d bytes exceeds %d", ErrInvalidFrame, len(payload), MaxMessageBytes)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	data := AppendFrame(make([]byte, 0, frameHeaderLen+len(payload)), payload)
+
+	if w.timeout > 0 {
+		if err := w.pipe.SetWriteDeadline(time.Now().Add(w.timeout)); err != nil {
+			return err
+		}
+	}
+
+	n, err := w.pipe.Write(data)
+	if err != nil {
+		return fmt.Errorf("write failed: %w", err)
+	}
+	if n != len(data) {
+		return fmt.Errorf("incomplete write: wrote %d of %d bytes", n, len(data))
+	}
+
+	return nil
+}
+
+// SetTimeout establece el timeout para operaciones de escritura.
+func (w *FrameWriter) SetTimeout(timeout time.Duration) {
+	w.timeout = timeout
+}
+
+// AppendFrame agrega a dst el frame de payload (header + payload).
+func AppendFrame(dst, payload []byte) []byte {
+	dst = append(dst, FrameMagic)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(payload)))
+	return append(dst, payload...)
+}
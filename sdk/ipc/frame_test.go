@@ -0,0 +1,117 @@
+package ipc
+
+import (
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestConnPair(t *testing.T) (net.Conn, net.Conn) {
+	t.Helper()
+	server, client := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		client.Close()
+	})
+	return server, client
+}
+
+func writeAsync(conn net.Conn, data []byte) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := conn.Write(data)
+		errCh <- err
+	}()
+	return errCh
+}
+
+func TestMessageReaderMixesJSONLinesAndFrames(t *testing.T) {
+	server, client := newTestConnPair(t)
+
+	// Tras el handshake JSON el EA cambia a frames sin esperar: ambos en el mismo stream
+	var stream []byte
+	stream = append(stream, []byte(`{"type":"handshake"}`+"\r\n")...)
+	stream = AppendFrame(stream, []byte{0x0a, 0x01, 0xEC, '\n'})
+	stream = AppendFrame(stream, nil)
+	stream = append(stream, []byte(`{"type":"ping"}`+"\n")...)
+	written := writeAsync(client, stream)
+
+	reader := NewMessageReader(server)
+
+	data, framed, err := reader.Next()
+	require.NoError(t, err)
+	assert.False(t, framed)
+	assert.Equal(t, `{"type":"handshake"}`, string(data))
+
+	data, framed, err = reader.Next()
+	require.NoError(t, err)
+	assert.True(t, framed)
+	assert.Equal(t, []byte{0x0a, 0x01, 0xEC, '\n'}, data)
+
+	data, framed, err = reader.Next()
+	require.NoError(t, err)
+	assert.True(t, framed)
+	assert.Equal(t, 0, len(data))
+
+	data, framed, err = reader.Next()
+	require.NoError(t, err)
+	assert.False(t, framed)
+	assert.Equal(t, `{"type":"ping"}`, string(data))
+
+	require.NoError(t, <-written)
+}
+
+func TestMessageReaderResumesFrameAfterTimeout(t *testing.T) {
+	server, client := newTestConnPair(t)
+
+	payload := []byte("0123456789")
+	frame := AppendFrame(nil, payload)
+
+	reader := NewMessageReader(server)
+	reader.SetTimeout(20 * time.Millisecond)
+
+	written := writeAsync(client, frame[:frameHeaderLen+4])
+	_, _, err := reader.Next()
+	require.Error(t, err)
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	require.NoError(t, <-written)
+
+	// Lo leído antes del timeout no se pierde
+	written = writeAsync(client, frame[frameHeaderLen+4:])
+	data, framed, err := reader.Next()
+	require.NoError(t, err)
+	assert.True(t, framed)
+	assert.Equal(t, payload, data)
+	require.NoError(t, <-written)
+}
+
+func TestMessageReaderRejectsOversizedFrame(t *testing.T) {
+	server, client := newTestConnPair(t)
+
+	header := []byte{FrameMagic, 0xFF, 0xFF, 0xFF, 0xFF}
+	written := writeAsync(client, header)
+
+	_, framed, err := NewMessageReader(server).Next()
+	assert.True(t, framed)
+	assert.ErrorIs(t, err, ErrInvalidFrame)
+	require.NoError(t, <-written)
+}
+
+func TestFrameWriterWritesHeaderAndPayload(t *testing.T) {
+	server, client := newTestConnPair(t)
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- NewFrameWriter(server).WriteFrame([]byte("abc")) }()
+
+	data, framed, err := NewMessageReader(client).Next()
+	require.NoError(t, err)
+	assert.True(t, framed)
+	assert.Equal(t, "abc", string(data))
+	require.NoError(t, <-errCh)
+
+	assert.ErrorIs(t, NewFrameWriter(server).WriteFrame(make([]byte, MaxMessageBytes+1)), ErrInvalidFrame)
+}
@@ -56,13 +56,16 @@ package ipc
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net"
+	"os"
 	"time"
 )
 
 // Pipe define la interfaz para un Named Pipe bidireccional.
 //
-// Compatible con Windows Named Pipes. Puede extenderse a Unix Domain Sockets.
+// Implementada por Windows Named Pipes y, en Linux/macOS, Unix domain sockets.
 type Pipe interface {
 	// Read lee datos del pipe.
 	//
@@ -119,6 +122,9 @@ type PipeConfig struct {
 
 	// MaxConnections número máximo de conexiones simultáneas (solo server)
 	MaxConnections int
+
+	// SocketDir directorio de los sockets (solo Unix; vacío = os.TempDir())
+	SocketDir string
 }
 
 // DefaultPipeConfig retorna una configuración por defecto.
@@ -185,3 +191,20 @@ func NewErrInvalidMessage(reason string, data []byte) error {
 		Data:   data,
 	}
 }
+
+// normalizeConnError traduce errores de net.Conn a los que produce go-winio.
+//
+// El PipeManager distingue timeouts ("i/o timeout") y desconexiones (io.EOF,
+// ErrPipeClosed) por valor; net.OpError agrega prefijos con la dirección.
+func normalizeConnError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return os.ErrDeadlineExceeded
+	case errors.Is(err, net.ErrClosed):
+		return ErrPipeClosed
+	default:
+		return err
+	}
+}
@@ -0,0 +1,344 @@
+package ipc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/xKoRx/echo/sdk/utils"
+)
+
+// DefaultHandshakeTimeout espera por defecto del primer mensaje de un cliente TCP.
+const DefaultHandshakeTimeout = 5 * time.Second
+
+// maxHandshakeLineBytes limita el primer mensaje (mismo límite que LineReader).
+const maxHandshakeLineBytes = 1024 * 1024
+
+// ErrUnauthorized indica que el cliente no presentó el token de la cuenta.
+var ErrUnauthorized = errors.New("unauthorized pipe client")
+
+// TCPServerConfig configuración de un TCPPipeServer.
+type TCPServerConfig struct {
+	// Name nombre lógico del pipe (ej: "echo_master_12345")
+	Name string
+
+	// Address dirección de escucha (host:port)
+	Address string
+
+	// Token pre-compartido de la cuenta; el EA lo envía en payload.auth_token
+	// del primer mensaje (handshake). Requerido.
+	Token string
+
+	// TLSConfig habilita TLS (nil = TCP plano)
+	TLSConfig *tls.Config
+
+	// HandshakeTimeout espera del handshake TLS y del primer mensaje (0 = DefaultHandshakeTimeout)
+	HandshakeTimeout time.Duration
+}
+
+// TCPPipeServer implementa PipeServer sobre TCP (opcionalmente TLS) para EAs en
+// otro host o VM.
+//
+// Igual que un Named Pipe: un listener por cuenta, una conexión activa a la vez y
+// framing JSON line-delimited. Antes de entregar la conexión valida el token de la
+// cuenta en el primer mensaje (type "handshake", payload.auth_token); el mensaje se
+// entrega luego al lector sin el token, como primera línea de la sesión.
+type TCPPipeServer struct {
+	mu          sync.Mutex
+	listener    *net.TCPListener
+	config      TCPServerConfig
+	currentConn net.Conn
+	pending     []byte // handshake validado (y bytes ya leídos), entregado antes que el socket
+}
+
+// NewTCPPipeServer crea un servidor TCP para una cuenta.
+//
+// Example:
+//
+//	server, err := ipc.NewTCPPipeServer(ipc.TCPServerConfig{
+//	    Name:    "echo_slave_67890",
+//	    Address: "0.0.0.0:7010",
+//	    Token:   token,
+//	})
+func NewTCPPipeServer(config TCPServerConfig) (PipeServer, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("tcp address is required")
+	}
+	if config.Token == "" {
+		return nil, fmt.Errorf("tcp token is required for %s", config.Name)
+	}
+	if config.HandshakeTimeout <= 0 {
+		config.HandshakeTimeout = DefaultHandshakeTimeout
+	}
+
+	addr, err := net.ResolveTCPAddr("tcp", config.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tcp address: %w", err)
+	}
+	listener, err := net.ListenTCP("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tcp listener: %w", err)
+	}
+
+	server := &TCPPipeServer{
+		listener: listener,
+		config:   config,
+	}
+
+	return server, nil
+}
+
+// WaitForConnection espera a un cliente autenticado.
+//
+// Bloquea hasta que un cliente se conecta o el contexto se cancela. Un cliente
+// con token inválido se desconecta y se retorna ErrUnauthorized.
+func (s *TCPPipeServer) WaitForConnection(ctx context.Context) error {
+	s.mu.Lock()
+	listener := s.listener
+	s.mu.Unlock()
+	if listener == nil {
+		return ErrPipeClosed
+	}
+
+	// Limpiar un deadline de una cancelación anterior
+	if err := listener.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("accept failed: %w", err)
+	}
+
+	// Desbloquear Accept si el contexto se cancela
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = listener.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	tcpConn, err := listener.AcceptTCP()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("accept failed: %w", normalizeConnError(err))
+	}
+	_ = tcpConn.SetKeepAlive(true)
+	_ = tcpConn.SetNoDelay(true)
+
+	var conn net.Conn = tcpConn
+	if s.config.TLSConfig != nil {
+		conn = tls.Server(tcpConn, s.config.TLSConfig)
+	}
+
+	pending, err := s.authenticate(ctx, conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		conn.Close()
+		return ErrPipeClosed
+	}
+	if s.currentConn != nil {
+		s.currentConn.Close()
+	}
+	s.currentConn = conn
+	s.pending = pending
+	return nil
+}
+
+// authenticate lee el primer mensaje y valida el token de la cuenta.
+//
+// Retorna los bytes a entregar al lector: el handshake sin auth_token seguido de
+// lo que el buffer haya leído de más.
+func (s *TCPPipeServer) authenticate(ctx context.Context, conn net.Conn) ([]byte, error) {
+	remote := conn.RemoteAddr().String()
+	if err := conn.SetDeadline(time.Now().Add(s.config.HandshakeTimeout)); err != nil {
+		return nil, fmt.Errorf("failed to set handshake deadline: %w", err)
+	}
+
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return nil, fmt.Errorf("tls handshake failed from %s: %w", remote, err)
+		}
+	}
+
+	reader := bufio.NewReaderSize(conn, 64*1024)
+	line, err := readLimitedLine(reader, maxHandshakeLineBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read handshake from %s: %w", remote, normalizeConnError(err))
+	}
+
+	msg, err := ParseJSONLine(line)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid handshake from %s", ErrUnauthorized, remote)
+	}
+	if utils.ExtractString(msg, "type") != "handshake" {
+		return nil, fmt.Errorf("%w: first message from %s is not a handshake", ErrUnauthorized, remote)
+	}
+	payload, _ := msg["payload"].(map[string]interface{})
+	token, _ := payload["auth_token"].(string)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.Token)) != 1 {
+		return nil, fmt.Errorf("%w: invalid token from %s for %s", ErrUnauthorized, remote, s.config.Name)
+	}
+
+	// El token no sale del transporte (logs, Core)
+	delete(payload, "auth_token")
+	clean, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode handshake: %w", err)
+	}
+
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, fmt.Errorf("failed to clear handshake deadline: %w", err)
+	}
+
+	pending := append(clean, '\n')
+	if buffered := reader.Buffered(); buffered > 0 {
+		rest, _ := reader.Peek(buffered)
+		pending = append(pending, rest...)
+	}
+	return pending, nil
+}
+
+// readLimitedLine lee hasta \n (excluido) sin superar max bytes.
+func readLimitedLine(reader *bufio.Reader, max int) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > max {
+			return nil, fmt.Errorf("handshake exceeds %d bytes", max)
+		}
+		if err == nil {
+			return bytes.TrimRight(line, "\r\n"), nil
+		}
+		if !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, err
+		}
+	}
+}
+
+// conn retorna la conexión activa.
+func (s *TCPPipeServer) conn() net.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.currentConn
+}
+
+// Read implementa io.Reader.
+//
+// Entrega primero el handshake validado y luego lee del socket.
+func (s *TCPPipeServer) Read(p []byte) (n int, err error) {
+	s.mu.Lock()
+	conn := s.currentConn
+	if conn != nil && len(s.pending) > 0 {
+		n = copy(p, s.pending)
+		s.pending = s.pending[n:]
+		s.mu.Unlock()
+		return n, nil
+	}
+	s.mu.Unlock()
+
+	if conn == nil {
+		return 0, ErrPipeClosed
+	}
+
+	n, err = conn.Read(p)
+	return n, normalizeConnError(err)
+}
+
+// Write implementa io.Writer.
+func (s *TCPPipeServer) Write(p []byte) (n int, err error) {
+	conn := s.conn()
+	if conn == nil {
+		return 0, ErrPipeClosed
+	}
+
+	n, err = conn.Write(p)
+	return n, normalizeConnError(err)
+}
+
+// DisconnectClient cierra solo la conexión actual sin cerrar el listener.
+//
+// Esto permite que el servidor continúe aceptando nuevas conexiones después
+// de que un cliente se desconecte. Útil para reconexión automática.
+func (s *TCPPipeServer) DisconnectClient() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pending = nil
+	if s.currentConn != nil {
+		err := s.currentConn.Close()
+		s.currentConn = nil
+		return err
+	}
+	return nil
+}
+
+// Close cierra el servidor y la conexión activa.
+func (s *TCPPipeServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	s.pending = nil
+	if s.currentConn != nil {
+		err = s.currentConn.Close()
+		s.currentConn = nil
+	}
+
+	if s.listener != nil {
+		if lerr := s.listener.Close(); lerr != nil && err == nil {
+			err = lerr
+		}
+		s.listener = nil
+	}
+
+	return err
+}
+
+// SetReadDeadline establece el deadline para lecturas.
+func (s *TCPPipeServer) SetReadDeadline(t time.Time) error {
+	conn := s.conn()
+	if conn == nil {
+		return ErrPipeClosed
+	}
+	return conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline establece el deadline para escrituras.
+func (s *TCPPipeServer) SetWriteDeadline(t time.Time) error {
+	conn := s.conn()
+	if conn == nil {
+		return ErrPipeClosed
+	}
+	return conn.SetWriteDeadline(t)
+}
+
+// Name retorna el nombre lógico del pipe.
+func (s *TCPPipeServer) Name() string {
+	return s.config.Name
+}
+
+// Addr retorna la dirección de escucha efectiva (útil con puerto 0).
+func (s *TCPPipeServer) Addr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return s.config.Address
+	}
+	return s.listener.Addr().String()
+}
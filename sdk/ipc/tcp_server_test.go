@@ -0,0 +1,139 @@
+package ipc
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTCPServer(t *testing.T, tlsConfig *tls.Config) *TCPPipeServer {
+	t.Helper()
+	server, err := NewTCPPipeServer(TCPServerConfig{
+		Name:             "echo_slave_67890",
+		Address:          "127.0.0.1:0",
+		Token:            "secret",
+		TLSConfig:        tlsConfig,
+		HandshakeTimeout: time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { server.Close() })
+	return server.(*TCPPipeServer)
+}
+
+func waitAsync(server PipeServer) <-chan error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.WaitForConnection(context.Background()) }()
+	return errCh
+}
+
+func TestTCPPipeServerAuthenticatesAndStripsToken(t *testing.T) {
+	server := newTestTCPServer(t, nil)
+
+	// Token inválido: se rechaza y el listener sigue aceptando
+	accepted := waitAsync(server)
+	bad, err := net.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	_, err = bad.Write([]byte(`{"type":"handshake","payload":{"auth_token":"wrong"}}` + "\n"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, <-accepted, ErrUnauthorized)
+	bad.Close()
+
+	accepted = waitAsync(server)
+	client, err := net.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+	// Handshake y el siguiente mensaje en el mismo write: nada se pierde en el buffer
+	_, err = client.Write([]byte(`{"type":"handshake","payload":{"account_id":"67890","auth_token":"secret"}}` + "\n" + `{"type":"ping"}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, <-accepted)
+
+	reader := NewJSONReader(server)
+	msg, err := reader.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "handshake", msg["type"])
+	payload := msg["payload"].(map[string]interface{})
+	assert.Equal(t, "67890", payload["account_id"])
+	_, hasToken := payload["auth_token"]
+	assert.False(t, hasToken)
+
+	msg, err = reader.ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "ping", msg["type"])
+
+	// Agent → EA
+	require.NoError(t, NewJSONWriter(server).WriteMessage(map[string]interface{}{"type": "pong"}))
+	line, err := bufio.NewReader(client).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, `{"type":"pong"}`+"\n", line)
+}
+
+func TestTCPPipeServerRejectsNonHandshakeFirstMessage(t *testing.T) {
+	server := newTestTCPServer(t, nil)
+
+	accepted := waitAsync(server)
+	client, err := net.Dial("tcp", server.Addr())
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write([]byte(`{"type":"trade_intent","payload":{"auth_token":"secret"}}` + "\n"))
+	require.NoError(t, err)
+	assert.ErrorIs(t, <-accepted, ErrUnauthorized)
+}
+
+func TestTCPPipeServerWithTLS(t *testing.T) {
+	cert := newTestCertificate(t)
+	server := newTestTCPServer(t, &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12})
+
+	accepted := waitAsync(server)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert.Leaf)
+	client, err := tls.Dial("tcp", server.Addr(), &tls.Config{RootCAs: pool, ServerName: "localhost"})
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Write([]byte(`{"type":"handshake","payload":{"auth_token":"secret"}}` + "\n"))
+	require.NoError(t, err)
+	require.NoError(t, <-accepted)
+
+	msg, err := NewJSONReader(server).ReadMessage()
+	require.NoError(t, err)
+	assert.Equal(t, "handshake", msg["type"])
+}
+
+func TestTCPPipeServerRequiresToken(t *testing.T) {
+	_, err := NewTCPPipeServer(TCPServerConfig{Name: "echo_slave_1", Address: "127.0.0.1:0"})
+	assert.Error(t, err)
+}
+
+func newTestCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
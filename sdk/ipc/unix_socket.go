@@ -0,0 +1,367 @@
+//go:build !windows
+// +build !windows
+
+package ipc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// maxSocketPathLen límite de sun_path (108 en Linux, 104 en macOS/BSD).
+const maxSocketPathLen = 104
+
+// UnixSocketPipe implementa Pipe sobre un Unix domain socket (lado cliente).
+type UnixSocketPipe struct {
+	conn net.Conn
+	name string
+	path string
+}
+
+// UnixSocketServer implementa PipeServer sobre Unix domain sockets.
+//
+// Equivalente a WindowsPipeServer para Linux/macOS (terminales bajo Wine, CI):
+// un listener por nombre de pipe y una conexión activa a la vez. Los errores de
+// lectura/escritura se normalizan a los de Named Pipes (io.EOF, "i/o timeout",
+// ErrPipeClosed) para que el PipeManager se comporte igual en ambas plataformas.
+type UnixSocketServer struct {
+	mu          sync.Mutex
+	listener    *net.UnixListener
+	name        string
+	path        string
+	config      *PipeConfig
+	currentConn net.Conn
+}
+
+// SocketPath retorna la ruta del socket para config.
+//
+// Formato: <SocketDir>/<Name>.sock (SocketDir vacío = os.TempDir()).
+func SocketPath(config *PipeConfig) string {
+	dir := config.SocketDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, config.Name+".sock")
+}
+
+// NewPipeServer crea el PipeServer de la plataforma (Unix domain socket).
+func NewPipeServer(name string) (PipeServer, error) {
+	return NewUnixSocketServerWithConfig(DefaultPipeConfig(name))
+}
+
+// NewPipeServerWithConfig crea el PipeServer de la plataforma con configuración custom.
+func NewPipeServerWithConfig(config *PipeConfig) (PipeServer, error) {
+	return NewUnixSocketServerWithConfig(config)
+}
+
+// NewUnixSocketServer crea un servidor sobre Unix domain socket.
+//
+// Example:
+//
+//	server, err := ipc.NewUnixSocketServer("echo_master_12345")
+//	// Socket real: /tmp/echo_master_12345.sock
+func NewUnixSocketServer(name string) (PipeServer, error) {
+	return NewUnixSocketServerWithConfig(DefaultPipeConfig(name))
+}
+
+// NewUnixSocketServerWithConfig crea un servidor con configuración custom.
+//
+// Un socket huérfano de una ejecución anterior (sin servidor escuchando) se
+// elimina; si otro proceso sigue escuchando en la ruta, retorna error.
+func NewUnixSocketServerWithConfig(config *PipeConfig) (PipeServer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	path := SocketPath(config)
+	if len(path) > maxSocketPathLen {
+		return nil, fmt.Errorf("socket path too long (%d > %d): %s", len(path), maxSocketPathLen, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create socket dir: %w", err)
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket listener: %w", err)
+	}
+	listener.SetUnlinkOnClose(true)
+
+	// Solo el usuario del Agent (y los terminales que corre) puede conectarse
+	if err := os.Chmod(path, 0o600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	server := &UnixSocketServer{
+		listener: listener,
+		name:     config.Name,
+		path:     path,
+		config:   config,
+	}
+
+	return server, nil
+}
+
+// removeStaleSocket elimina path si es un socket sin servidor escuchando.
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat socket path: %w", err)
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("socket path exists and is not a socket: %s", path)
+	}
+
+	conn, err := net.DialTimeout("unix", path, 100*time.Millisecond)
+	if err == nil {
+		conn.Close()
+		return fmt.Errorf("socket already in use: %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
+}
+
+// WaitForConnection espera a que un cliente se conecte.
+//
+// Bloquea hasta que un cliente se conecta o el contexto se cancela.
+func (s *UnixSocketServer) WaitForConnection(ctx context.Context) error {
+	s.mu.Lock()
+	listener := s.listener
+	s.mu.Unlock()
+	if listener == nil {
+		return ErrPipeClosed
+	}
+
+	// Limpiar un deadline de una cancelación anterior
+	if err := listener.SetDeadline(time.Time{}); err != nil {
+		return fmt.Errorf("accept failed: %w", err)
+	}
+
+	// Desbloquear Accept si el contexto se cancela
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = listener.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	conn, err := listener.AcceptUnix()
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("accept failed: %w", normalizeConnError(err))
+	}
+
+	if s.config.BufferSize > 0 {
+		_ = conn.SetReadBuffer(s.config.BufferSize)
+		_ = conn.SetWriteBuffer(s.config.BufferSize)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		conn.Close()
+		return ErrPipeClosed
+	}
+	if s.currentConn != nil {
+		s.currentConn.Close()
+	}
+	s.currentConn = conn
+	return nil
+}
+
+// conn retorna la conexión activa.
+func (s *UnixSocketServer) conn() net.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.currentConn
+}
+
+// Read implementa io.Reader.
+func (s *UnixSocketServer) Read(p []byte) (n int, err error) {
+	conn := s.conn()
+	if conn == nil {
+		return 0, ErrPipeClosed
+	}
+
+	n, err = conn.Read(p)
+	return n, normalizeConnError(err)
+}
+
+// Write implementa io.Writer.
+func (s *UnixSocketServer) Write(p []byte) (n int, err error) {
+	conn := s.conn()
+	if conn == nil {
+		return 0, ErrPipeClosed
+	}
+
+	n, err = conn.Write(p)
+	return n, normalizeConnError(err)
+}
+
+// DisconnectClient cierra solo la conexión actual sin cerrar el listener.
+//
+// Esto permite que el servidor continúe aceptando nuevas conexiones después
+// de que un cliente se desconecte. Útil para reconexión automática.
+func (s *UnixSocketServer) DisconnectClient() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.currentConn != nil {
+		err := s.currentConn.Close()
+		s.currentConn = nil
+		return err
+	}
+	return nil
+}
+
+// Close cierra el servidor y la conexión activa, y elimina el socket.
+func (s *UnixSocketServer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err error
+	if s.currentConn != nil {
+		err = s.currentConn.Close()
+		s.currentConn = nil
+	}
+
+	if s.listener != nil {
+		if lerr := s.listener.Close(); lerr != nil && err == nil {
+			err = lerr
+		}
+		s.listener = nil
+	}
+
+	return err
+}
+
+// SetReadDeadline establece el deadline para lecturas.
+func (s *UnixSocketServer) SetReadDeadline(t time.Time) error {
+	conn := s.conn()
+	if conn == nil {
+		return ErrPipeClosed
+	}
+	return conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline establece el deadline para escrituras.
+func (s *UnixSocketServer) SetWriteDeadline(t time.Time) error {
+	conn := s.conn()
+	if conn == nil {
+		return ErrPipeClosed
+	}
+	return conn.SetWriteDeadline(t)
+}
+
+// Name retorna el nombre del pipe (sin directorio ni extensión).
+func (s *UnixSocketServer) Name() string {
+	return s.name
+}
+
+// Path retorna la ruta del socket en el filesystem.
+func (s *UnixSocketServer) Path() string {
+	return s.path
+}
+
+// NewPipeClient crea el cliente de la plataforma (Unix domain socket).
+func NewPipeClient(name string) (Pipe, error) {
+	return NewUnixSocketClientWithConfig(DefaultPipeConfig(name))
+}
+
+// NewUnixSocketClient crea un cliente que se conecta a un socket existente.
+//
+// Example:
+//
+//	client, err := ipc.NewUnixSocketClient("echo_master_12345")
+func NewUnixSocketClient(name string) (Pipe, error) {
+	return NewUnixSocketClientWithConfig(DefaultPipeConfig(name))
+}
+
+// NewUnixSocketClientWithConfig crea un cliente con configuración custom.
+func NewUnixSocketClientWithConfig(config *PipeConfig) (Pipe, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	path := SocketPath(config)
+	conn, err := net.DialTimeout("unix", path, config.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to socket: %w", err)
+	}
+
+	pipe := &UnixSocketPipe{
+		conn: conn,
+		name: config.Name,
+		path: path,
+	}
+
+	return pipe, nil
+}
+
+// Read implementa io.Reader.
+func (p *UnixSocketPipe) Read(b []byte) (n int, err error) {
+	if p.conn == nil {
+		return 0, ErrPipeClosed
+	}
+
+	n, err = p.conn.Read(b)
+	return n, normalizeConnError(err)
+}
+
+// Write implementa io.Writer.
+func (p *UnixSocketPipe) Write(b []byte) (n int, err error) {
+	if p.conn == nil {
+		return 0, ErrPipeClosed
+	}
+
+	n, err = p.conn.Write(b)
+	return n, normalizeConnError(err)
+}
+
+// Close cierra la conexión.
+func (p *UnixSocketPipe) Close() error {
+	if p.conn == nil {
+		return nil
+	}
+
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+// SetReadDeadline establece el deadline para lecturas.
+func (p *UnixSocketPipe) SetReadDeadline(t time.Time) error {
+	if p.conn == nil {
+		return ErrPipeClosed
+	}
+	return p.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline establece el deadline para escrituras.
+func (p *UnixSocketPipe) SetWriteDeadline(t time.Time) error {
+	if p.conn == nil {
+		return ErrPipeClosed
+	}
+	return p.conn.SetWriteDeadline(t)
+}
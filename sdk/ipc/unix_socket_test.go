@@ -0,0 +1,133 @@
+//go:build !windows
+// +build !windows
+
+package ipc
+
+import (
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSocketConfig(t *testing.T, name string) *PipeConfig {
+	t.Helper()
+	config := DefaultPipeConfig(name)
+	// t.TempDir() puede exceder el límite de sun_path en macOS
+	dir, err := os.MkdirTemp("", "echo-ipc")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	config.SocketDir = dir
+	config.Timeout = time.Second
+	return config
+}
+
+func acceptAsync(server PipeServer) <-chan error {
+	errCh := make(chan error, 1)
+	go func() { errCh <- server.WaitForConnection(context.Background()) }()
+	return errCh
+}
+
+func TestUnixSocketServerExchangesLinesAndReconnects(t *testing.T) {
+	config := newTestSocketConfig(t, "echo_master_1")
+	server, err := NewUnixSocketServerWithConfig(config)
+	require.NoError(t, err)
+	defer server.Close()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		accepted := acceptAsync(server)
+
+		client, err := NewUnixSocketClientWithConfig(config)
+		require.NoError(t, err)
+		require.NoError(t, <-accepted)
+
+		// EA → Agent
+		require.NoError(t, NewJSONWriter(client).WriteMessage(map[string]interface{}{"type": "ping"}))
+		msg, err := NewJSONReader(server).ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "ping", msg["type"])
+
+		// Agent → EA
+		require.NoError(t, NewJSONWriter(server).WriteMessage(map[string]interface{}{"type": "pong"}))
+		msg, err = NewJSONReader(client).ReadMessage()
+		require.NoError(t, err)
+		assert.Equal(t, "pong", msg["type"])
+
+		// Desconexión del EA: EOF, y el listener sigue aceptando
+		require.NoError(t, client.Close())
+		_, err = NewLineReader(server).ReadLine()
+		assert.Equal(t, io.EOF, err)
+		require.NoError(t, server.DisconnectClient())
+	}
+}
+
+func TestUnixSocketServerNormalizesTimeoutAndClosedErrors(t *testing.T) {
+	config := newTestSocketConfig(t, "echo_slave_1")
+	server, err := NewUnixSocketServerWithConfig(config)
+	require.NoError(t, err)
+	defer server.Close()
+
+	accepted := acceptAsync(server)
+	client, err := NewUnixSocketClientWithConfig(config)
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, <-accepted)
+
+	// Mismo texto que go-winio: el PipeManager trata "i/o timeout" como lectura vacía
+	reader := NewLineReader(server)
+	reader.SetTimeout(20 * time.Millisecond)
+	_, err = reader.ReadLine()
+	require.Error(t, err)
+	assert.Equal(t, "i/o timeout", err.Error())
+
+	require.NoError(t, server.DisconnectClient())
+	_, err = server.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrPipeClosed)
+}
+
+func TestUnixSocketServerWaitForConnectionHonorsContext(t *testing.T) {
+	config := newTestSocketConfig(t, "echo_master_2")
+	server, err := NewUnixSocketServerWithConfig(config)
+	require.NoError(t, err)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, server.WaitForConnection(ctx), context.DeadlineExceeded)
+
+	// El listener sigue usable tras la cancelación
+	accepted := acceptAsync(server)
+	client, err := NewUnixSocketClientWithConfig(config)
+	require.NoError(t, err)
+	defer client.Close()
+	require.NoError(t, <-accepted)
+}
+
+func TestUnixSocketServerReplacesStaleSocket(t *testing.T) {
+	config := newTestSocketConfig(t, "echo_master_3")
+	path := SocketPath(config)
+
+	// Socket huérfano (crash del Agent): existe en disco pero nadie escucha
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	require.NoError(t, err)
+	stale.SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	server, err := NewUnixSocketServerWithConfig(config)
+	require.NoError(t, err)
+
+	// Un segundo servidor sobre la misma ruta activa falla
+	_, err = NewUnixSocketServerWithConfig(config)
+	assert.Error(t, err)
+
+	require.NoError(t, server.Close())
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -31,6 +31,16 @@ type WindowsPipeServer struct {
 	writeTimeout time.Duration
 }
 
+// NewPipeServer crea el PipeServer de la plataforma (Named Pipe).
+func NewPipeServer(name string) (PipeServer, error) {
+	return NewWindowsPipeServer(name)
+}
+
+// NewPipeServerWithConfig crea el PipeServer de la plataforma con configuración custom.
+func NewPipeServerWithConfig(config *PipeConfig) (PipeServer, error) {
+	return NewWindowsPipeServerWithConfig(config)
+}
+
 // NewWindowsPipeServer crea un servidor de Named Pipe.
 //
 // El nombre del pipe se auto-completa con el prefijo \\.\pipe\.
@@ -181,6 +191,11 @@ func (s *WindowsPipeServer) Name() string {
 	return s.name
 }
 
+// NewPipeClient crea el cliente de la plataforma (Named Pipe).
+func NewPipeClient(name string) (Pipe, error) {
+	return NewWindowsPipeClient(name)
+}
+
 // NewWindowsPipeClient crea un cliente que se conecta a un Named Pipe existente.
 //
 // Example:
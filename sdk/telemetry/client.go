@@ -22,10 +22,11 @@ import (
 
 // Client es el cliente unificado de telemetría para echo
 type Client struct {
-	config Config
-	logger *slog.Logger
-	tracer trace.Tracer
-	meter  metric.Meter
+	config   Config
+	logger   *slog.Logger
+	logLevel *slog.LevelVar // ajustable en caliente con SetLogLevel
+	tracer   trace.Tracer
+	meter    metric.Meter
 	
 	// Providers (para shutdown)
 	tracerProvider *sdktrace.TracerProvider
@@ -89,23 +90,16 @@ func New(ctx context.Context, serviceName, environment string, opts ...Option) (
 
 func (c *Client) initLogs() {
 	// Parsear nivel de log desde config
-	var level slog.Level
-	switch c.config.LogLevel {
-	case "DEBUG":
-		level = slog.LevelDebug
-	case "INFO":
-		level = slog.LevelInfo
-	case "WARN":
-		level = slog.LevelWarn
-	case "ERROR":
-		level = slog.LevelError
-	default:
+	level, ok := parseLogLevel(c.config.LogLevel)
+	if !ok {
 		level = slog.LevelInfo // Default
 	}
+	c.logLevel = new(slog.LevelVar)
+	c.logLevel.Set(level)
 	
 	// Usar slog estándar con JSON handler
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
+		Level: c.logLevel,
 	})
 	c.logger = slog.New(handler)
 }
@@ -2,6 +2,7 @@ package telemetry
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -50,6 +51,35 @@ func (c *Client) Debug(ctx context.Context, msg string, attrs ...attribute.KeyVa
 	c.logger.DebugContext(ctx, msg, args...)
 }
 
+// SetLogLevel cambia el nivel de log sin recrear el cliente (INFO, DEBUG, WARN, ERROR)
+func (c *Client) SetLogLevel(level string) error {
+	parsed, ok := parseLogLevel(level)
+	if !ok {
+		return fmt.Errorf("invalid log level %q", level)
+	}
+	if c.logLevel == nil {
+		return fmt.Errorf("logs not initialized")
+	}
+	c.logLevel.Set(parsed)
+	return nil
+}
+
+// parseLogLevel traduce el nivel de config a slog.Level
+func parseLogLevel(level string) (slog.Level, bool) {
+	switch level {
+	case "DEBUG":
+		return slog.LevelDebug, true
+	case "INFO":
+		return slog.LevelInfo, true
+	case "WARN":
+		return slog.LevelWarn, true
+	case "ERROR":
+		return slog.LevelError, true
+	default:
+		return slog.LevelInfo, false
+	}
+}
+
 // convertAttrsToSlogArgs convierte atributos OTEL a argumentos slog
 func (c *Client) convertAttrsToSlogArgs(attrs []attribute.KeyValue) []any {
 	args := make([]any, 0, len(attrs)*2)
@@ -95,6 +95,29 @@ type EchoMetrics struct {
 	SymbolsReported metric.Int64Counter // echo.symbols.reported
 	SymbolsValidate metric.Int64Counter // echo.symbols.validate (ok/reject)
 	SymbolsLoaded   metric.Int64Counter // echo.symbols.loaded (source=etcd/postgres/agent_report)
+
+	// i8: Liveness de Agents
+	AgentHeartbeatReceived  metric.Int64Counter     // echo.agent.heartbeat.received_total
+	AgentLivenessTransition metric.Int64Counter     // echo.agent.liveness.transition_total (state=alive/stale)
+	AgentLastSeenAge        metric.Float64Histogram // echo.agent.liveness.last_seen_age_ms
+
+	// i8: Configuración publicada por el Core
+	AgentConfigUpdate metric.Int64Counter // echo.agent.config.update_total (result=sent/applied/restart_required/rejected)
+
+	// i8: Autenticación de Agents
+	AgentAuthRejected metric.Int64Counter // echo.agent.auth.rejected_total (reason)
+
+	// i8: Conflictos de ownership de cuentas
+	AccountOwnershipEvent metric.Int64Counter // echo.account.ownership.event_total (event=quarantined/takeover/forced/dropped)
+
+	// i8: AdminService
+	AdminRequest metric.Int64Counter // echo.admin.request_total (method, result=ok/error/denied)
+
+	// i8: Stream de eventos WatchEvents
+	EventsDropped metric.Int64Counter // echo.events.dropped_total (type)
+
+	// i8: Webhooks del notifier
+	NotifierDelivery metric.Int64Counter // echo.notifier.delivery_total (rule, result=delivered/retry/dead_letter/rate_limited/dropped)
 }
 
 // NewEchoMetrics crea un nuevo bundle de métricas Echo.
@@ -427,6 +450,88 @@ func NewEchoMetrics(meter metric.Meter) (*EchoMetrics, error) {
 		return nil, err
 	}
 
+	// i8: Liveness de Agents
+	agentHeartbeatReceived, err := meter.Int64Counter(
+		"echo.agent.heartbeat.received_total",
+		metric.WithDescription("Heartbeats de Agents recibidos por Core"),
+		metric.WithUnit("{heartbeat}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	agentLivenessTransition, err := meter.Int64Counter(
+		"echo.agent.liveness.transition_total",
+		metric.WithDescription("Cambios de estado de liveness de Agents y cuentas (alive, stale)"),
+		metric.WithUnit("{transition}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	agentLastSeenAge, err := meter.Float64Histogram(
+		"echo.agent.liveness.last_seen_age_ms",
+		metric.WithDescription("Tiempo desde el último heartbeat de cada Agent (muestreado por el monitor)"),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	agentConfigUpdate, err := meter.Int64Counter(
+		"echo.agent.config.update_total",
+		metric.WithDescription("ConfigUpdate enviados a Agents y resultado reportado en ConfigApplied"),
+		metric.WithUnit("{update}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	agentAuthRejected, err := meter.Int64Counter(
+		"echo.agent.auth.rejected_total",
+		metric.WithDescription("Streams o mensajes de Agents rechazados por identidad o allowlist de cuentas"),
+		metric.WithUnit("{rejection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	accountOwnershipEvent, err := meter.Int64Counter(
+		"echo.account.ownership.event_total",
+		metric.WithDescription("Conflictos de ownership de cuentas entre Agents y takeovers"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	adminRequest, err := meter.Int64Counter(
+		"echo.admin.request_total",
+		metric.WithDescription("Llamadas a AdminService por método y resultado"),
+		metric.WithUnit("{request}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	eventsDropped, err := meter.Int64Counter(
+		"echo.events.dropped_total",
+		metric.WithDescription("Eventos WatchEvents descartados por suscriptores lentos (drop-oldest)"),
+		metric.WithUnit("{event}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	notifierDelivery, err := meter.Int64Counter(
+		"echo.notifier.delivery_total",
+		metric.WithDescription("Envíos de webhooks del notifier por regla y resultado"),
+		metric.WithUnit("{notification}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return &EchoMetrics{
 		IntentReceived:             intentReceived,
 		IntentForwarded:            intentForwarded,
@@ -464,6 +569,15 @@ func NewEchoMetrics(meter metric.Meter) (*EchoMetrics, error) {
 		SymbolsReported:            symbolsReported, // i3
 		SymbolsValidate:            symbolsValidate, // i3
 		SymbolsLoaded:              symbolsLoaded,   // i3
+		AgentHeartbeatReceived:     agentHeartbeatReceived,
+		AgentLivenessTransition:    agentLivenessTransition,
+		AgentLastSeenAge:           agentLastSeenAge,
+		AgentConfigUpdate:          agentConfigUpdate,
+		AgentAuthRejected:          agentAuthRejected,
+		AccountOwnershipEvent:      accountOwnershipEvent,
+		AdminRequest:               adminRequest,
+		EventsDropped:              eventsDropped,
+		NotifierDelivery:           notifierDelivery,
 	}, nil
 }
 
@@ -757,3 +871,116 @@ func (m *EchoMetrics) RecordHandshakeReconcileSkipped(ctx context.Context, attrs
 func (m *EchoMetrics) RecordHandshakeFeedbackLatency(ctx context.Context, latencyMs float64, attrs ...attribute.KeyValue) {
 	m.HandshakeFeedbackLatency.Record(ctx, latencyMs, metric.WithAttributes(attrs...))
 }
+
+// RecordAgentHeartbeat registra un heartbeat recibido de un Agent (i8).
+func (m *EchoMetrics) RecordAgentHeartbeat(ctx context.Context, agentID string, attrs ...attribute.KeyValue) {
+	if m.AgentHeartbeatReceived == nil {
+		return
+	}
+	baseAttrs := []attribute.KeyValue{
+		attribute.String("agent_id", agentID),
+	}
+	baseAttrs = append(baseAttrs, attrs...)
+	m.AgentHeartbeatReceived.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
+}
+
+// RecordAgentLivenessTransition registra un cambio de liveness (i8).
+// state: alive | stale. scope: agent | account
+func (m *EchoMetrics) RecordAgentLivenessTransition(ctx context.Context, state, scope string, attrs ...attribute.KeyValue) {
+	if m.AgentLivenessTransition == nil {
+		return
+	}
+	baseAttrs := []attribute.KeyValue{
+		attribute.String("state", state),
+		attribute.String("scope", scope),
+	}
+	baseAttrs = append(baseAttrs, attrs...)
+	m.AgentLivenessTransition.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
+}
+
+// RecordAgentLastSeenAge registra el tiempo desde el último heartbeat de un Agent (i8).
+func (m *EchoMetrics) RecordAgentLastSeenAge(ctx context.Context, ageMs float64, attrs ...attribute.KeyValue) {
+	if m.AgentLastSeenAge == nil {
+		return
+	}
+	m.AgentLastSeenAge.Record(ctx, ageMs, metric.WithAttributes(attrs...))
+}
+
+// RecordAgentConfigUpdate registra un ConfigUpdate enviado o su resultado (i8).
+// result: sent, applied, restart_required, rejected.
+func (m *EchoMetrics) RecordAgentConfigUpdate(ctx context.Context, result string, attrs ...attribute.KeyValue) {
+	if m.AgentConfigUpdate == nil {
+		return
+	}
+	baseAttrs := []attribute.KeyValue{
+		attribute.String("result", result),
+	}
+	baseAttrs = append(baseAttrs, attrs...)
+	m.AgentConfigUpdate.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
+}
+
+// RecordAgentAuthRejected registra un rechazo de autenticación o autorización de Agent (i8).
+// reason: identity, account_not_allowed.
+func (m *EchoMetrics) RecordAgentAuthRejected(ctx context.Context, reason string, attrs ...attribute.KeyValue) {
+	if m.AgentAuthRejected == nil {
+		return
+	}
+	baseAttrs := []attribute.KeyValue{
+		attribute.String("reason", reason),
+	}
+	baseAttrs = append(baseAttrs, attrs...)
+	m.AgentAuthRejected.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
+}
+
+// RecordAccountOwnershipEvent registra un conflicto o cambio forzado de ownership (i8).
+// event: quarantined, takeover, forced, dropped.
+func (m *EchoMetrics) RecordAccountOwnershipEvent(ctx context.Context, event string, attrs ...attribute.KeyValue) {
+	if m.AccountOwnershipEvent == nil {
+		return
+	}
+	baseAttrs := []attribute.KeyValue{
+		attribute.String("event", event),
+	}
+	baseAttrs = append(baseAttrs, attrs...)
+	m.AccountOwnershipEvent.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
+}
+
+// RecordAdminRequest registra una llamada a AdminService (i8).
+// result: ok, error, denied.
+func (m *EchoMetrics) RecordAdminRequest(ctx context.Context, method, result string, attrs ...attribute.KeyValue) {
+	if m.AdminRequest == nil {
+		return
+	}
+	baseAttrs := []attribute.KeyValue{
+		attribute.String("method", method),
+		attribute.String("result", result),
+	}
+	baseAttrs = append(baseAttrs, attrs...)
+	m.AdminRequest.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
+}
+
+// RecordEventsDropped registra eventos descartados por un suscriptor lento de WatchEvents (i8).
+func (m *EchoMetrics) RecordEventsDropped(ctx context.Context, count int64, eventType string, attrs ...attribute.KeyValue) {
+	if m.EventsDropped == nil {
+		return
+	}
+	baseAttrs := []attribute.KeyValue{
+		attribute.String("type", eventType),
+	}
+	baseAttrs = append(baseAttrs, attrs...)
+	m.EventsDropped.Add(ctx, count, metric.WithAttributes(baseAttrs...))
+}
+
+// RecordNotifierDelivery registra un envío de webhook del notifier (i8).
+// result: delivered, retry, dead_letter, rate_limited, dropped.
+func (m *EchoMetrics) RecordNotifierDelivery(ctx context.Context, rule, result string, attrs ...attribute.KeyValue) {
+	if m.NotifierDelivery == nil {
+		return
+	}
+	baseAttrs := []attribute.KeyValue{
+		attribute.String("rule", rule),
+		attribute.String("result", result),
+	}
+	baseAttrs = append(baseAttrs, attrs...)
+	m.NotifierDelivery.Add(ctx, 1, metric.WithAttributes(baseAttrs...))
+}